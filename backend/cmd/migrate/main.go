@@ -2,26 +2,37 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"log"
+	"os"
+	"path/filepath"
+	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/Srivathsav-max/lumen/backend/config"
 	"github.com/Srivathsav-max/lumen/backend/db"
 	"github.com/golang-migrate/migrate/v4"
-	"github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
 	_ "github.com/lib/pq"
 )
 
 func main() {
 	var (
-		action  = flag.String("action", "", "Migration action: up, down, force, status")
+		action  = flag.String("action", "", "Migration action: up, down, force, status, drift, create")
 		version = flag.String("version", "", "Version to migrate to (for down/force)")
+		name    = flag.String("name", "", "Name of the new migration (for create)")
+		dir     = flag.String("dir", "db/migrations", "Migrations directory (for create)")
 	)
 	flag.Parse()
 
 	if *action == "" {
-		log.Fatal("Please specify an action: -action=up|down|force|status")
+		log.Fatal("Please specify an action: -action=up|down|force|status|drift|create")
+	}
+
+	// Scaffolding only touches the filesystem, so it runs without a database
+	if *action == "create" {
+		createMigration(*dir, *name)
+		return
 	}
 
 	// Load environment variables from .env file
@@ -40,18 +51,9 @@ func main() {
 	}
 	defer database.Close()
 
-	driver, err := postgres.WithInstance(database.DB, &postgres.Config{})
+	m, err := db.NewMigrator(database, cfg.Database.DBName)
 	if err != nil {
-		log.Fatalf("Could not create migration driver: %v", err)
-	}
-
-	m, err := migrate.NewWithDatabaseInstance(
-		"file://../../db/migrations",
-		cfg.Database.DBName,
-		driver,
-	)
-	if err != nil {
-		log.Fatalf("Could not create migration instance: %v", err)
+		log.Fatalf("%v", err)
 	}
 
 	switch *action {
@@ -63,6 +65,8 @@ func main() {
 		runDown(m, *version)
 	case "force":
 		forceVersion(m, *version)
+	case "drift":
+		checkDrift(m)
 	default:
 		log.Fatalf("Unknown action: %s", *action)
 	}
@@ -86,6 +90,83 @@ func showStatus(m *migrate.Migrate) {
 	log.Printf("Current migration version: %d (status: %s)", version, status)
 }
 
+// checkDrift compares the database version with the newest embedded
+// migration without applying anything. It exits non-zero on drift so it can
+// gate deployments.
+func checkDrift(m *migrate.Migrate) {
+	latest, err := db.LatestMigrationVersion()
+	if err != nil {
+		log.Fatalf("Could not read embedded migrations: %v", err)
+	}
+
+	version, dirty, err := m.Version()
+	if err != nil && err != migrate.ErrNilVersion {
+		log.Fatalf("Could not get migration version: %v", err)
+	}
+
+	switch {
+	case dirty:
+		log.Fatalf("Drift: database is dirty at version %d (latest embedded: %d)", version, latest)
+	case version < latest:
+		log.Fatalf("Drift: database is at version %d, %d pending up to %d", version, latest-version, latest)
+	case version > latest:
+		log.Fatalf("Drift: database is at version %d, ahead of the latest embedded migration %d", version, latest)
+	}
+
+	log.Printf("No drift: database is at the latest migration version %d", latest)
+}
+
+var (
+	migrationNamePattern = regexp.MustCompile(`^[a-z0-9]+(_[a-z0-9]+)*$`)
+	migrationFilePattern = regexp.MustCompile(`^(\d+)_.+\.(up|down)\.sql$`)
+)
+
+// createMigration writes an empty up/down pair numbered one past the highest
+// existing migration in dir
+func createMigration(dir, name string) {
+	if name == "" {
+		log.Fatal("Please specify a migration name: -name=add_comments_index")
+	}
+	name = strings.ToLower(name)
+	if !migrationNamePattern.MatchString(name) {
+		log.Fatalf("Invalid migration name %q: use lowercase letters, digits and underscores", name)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		log.Fatalf("Could not read migrations directory: %v", err)
+	}
+
+	var latest uint64
+	for _, entry := range entries {
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		v, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			log.Fatalf("Invalid migration version in %s: %v", entry.Name(), err)
+		}
+		if v > latest {
+			latest = v
+		}
+	}
+
+	base := fmt.Sprintf("%06d_%s", latest+1, name)
+	for _, direction := range []string{"up", "down"} {
+		path := filepath.Join(dir, base+"."+direction+".sql")
+		// O_EXCL guards against clobbering a file created concurrently
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			log.Fatalf("Could not create migration file: %v", err)
+		}
+		if err := file.Close(); err != nil {
+			log.Fatalf("Could not create migration file: %v", err)
+		}
+		log.Printf("Created %s", path)
+	}
+}
+
 func runUp(m *migrate.Migrate) {
 	log.Println("Running migrations up...")
 	if err := m.Up(); err != nil {
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/handlers"
+)
+
+// Writes the OpenAPI document without starting the server, so CI can check
+// that the spec still builds and publish it for client generation.
+func main() {
+	out := flag.String("out", "", "File to write the spec to (default: stdout)")
+	flag.Parse()
+
+	doc, err := handlers.OpenAPIDocument()
+	if err != nil {
+		log.Fatalf("Failed to build OpenAPI document: %v", err)
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		log.Fatalf("Failed to encode OpenAPI document: %v", err)
+	}
+	data = append(data, '\n')
+
+	if *out == "" {
+		if _, err := os.Stdout.Write(data); err != nil {
+			log.Fatalf("Failed to write OpenAPI document: %v", err)
+		}
+		return
+	}
+
+	if err := os.WriteFile(*out, data, 0o644); err != nil {
+		log.Fatalf("Failed to write OpenAPI document: %v", err)
+	}
+	log.Printf("Wrote %s", *out)
+}
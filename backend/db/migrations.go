@@ -1,31 +1,78 @@
 package db
 
 import (
+	"embed"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
 
 	"github.com/Srivathsav-max/lumen/backend/config"
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
 	_ "github.com/lib/pq"
 )
 
-func RunMigrations(db *DB, cfg *config.DatabaseConfig) error {
-	log.Println("Running database migrations...")
+// Migrations ship inside the binary so they run regardless of the working
+// directory or whether the source tree is present
+//
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+const migrationsDir = "migrations"
+
+// NewMigrator returns a migrate instance reading the embedded migrations
+func NewMigrator(db *DB, dbName string) (*migrate.Migrate, error) {
+	source, err := iofs.New(migrationsFS, migrationsDir)
+	if err != nil {
+		return nil, fmt.Errorf("could not load embedded migrations: %w", err)
+	}
 
 	driver, err := postgres.WithInstance(db.DB, &postgres.Config{})
 	if err != nil {
-		return fmt.Errorf("could not create migration driver: %w", err)
+		return nil, fmt.Errorf("could not create migration driver: %w", err)
 	}
 
-	m, err := migrate.NewWithDatabaseInstance(
-		"file://db/migrations",
-		cfg.DBName,
-		driver,
-	)
+	m, err := migrate.NewWithInstance("iofs", source, dbName, driver)
+	if err != nil {
+		return nil, fmt.Errorf("could not create migration instance: %w", err)
+	}
+
+	return m, nil
+}
+
+// LatestMigrationVersion returns the highest version among the embedded migrations
+func LatestMigrationVersion() (uint, error) {
+	source, err := iofs.New(migrationsFS, migrationsDir)
+	if err != nil {
+		return 0, fmt.Errorf("could not load embedded migrations: %w", err)
+	}
+	defer source.Close()
+
+	version, err := source.First()
+	if err != nil {
+		return 0, fmt.Errorf("could not read first migration: %w", err)
+	}
+
+	for {
+		next, err := source.Next(version)
+		if errors.Is(err, fs.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("could not read migration after %d: %w", version, err)
+		}
+		version = next
+	}
+}
+
+func RunMigrations(db *DB, cfg *config.DatabaseConfig) error {
+	log.Println("Running database migrations...")
+
+	m, err := NewMigrator(db, cfg.DBName)
 	if err != nil {
-		return fmt.Errorf("could not create migration instance: %w", err)
+		return err
 	}
 
 	if err := fixDirtyMigration(m); err != nil {
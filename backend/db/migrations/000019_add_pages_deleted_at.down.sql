@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_pages_deleted_at;
+ALTER TABLE public.pages DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,4 @@
+-- Soft-delete support: deleted pages stay in the trash until purged
+ALTER TABLE public.pages ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_pages_deleted_at ON public.pages(deleted_at) WHERE deleted_at IS NOT NULL;
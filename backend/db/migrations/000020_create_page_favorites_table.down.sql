@@ -0,0 +1,3 @@
+-- Drop page favorites table
+DROP INDEX IF EXISTS idx_page_favorites_page_id;
+DROP TABLE IF EXISTS public.page_favorites;
@@ -0,0 +1,10 @@
+-- Create page favorites table for per-user starred pages
+CREATE TABLE public.page_favorites (
+    user_id INTEGER NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    page_id UUID NOT NULL REFERENCES public.pages(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, page_id)
+);
+
+-- Add indexes for performance
+CREATE INDEX idx_page_favorites_page_id ON public.page_favorites(page_id);
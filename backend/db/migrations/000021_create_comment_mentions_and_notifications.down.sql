@@ -0,0 +1,6 @@
+-- Drop notifications and comment mentions tables
+DROP INDEX IF EXISTS idx_notifications_unread;
+DROP INDEX IF EXISTS idx_notifications_user_id_created_at;
+DROP INDEX IF EXISTS idx_comment_mentions_user_id;
+DROP TABLE IF EXISTS public.notifications;
+DROP TABLE IF EXISTS public.comment_mentions;
@@ -0,0 +1,25 @@
+-- Create comment mentions table for @username references in comments
+CREATE TABLE public.comment_mentions (
+    comment_id UUID NOT NULL REFERENCES public.comments(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (comment_id, user_id)
+);
+
+-- Create notifications table
+CREATE TABLE public.notifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id INTEGER NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    actor_id INTEGER REFERENCES public.users(id) ON DELETE SET NULL,
+    page_id UUID REFERENCES public.pages(id) ON DELETE CASCADE,
+    comment_id UUID REFERENCES public.comments(id) ON DELETE CASCADE,
+    is_read BOOLEAN NOT NULL DEFAULT FALSE,
+    read_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Add indexes for performance
+CREATE INDEX idx_comment_mentions_user_id ON public.comment_mentions(user_id);
+CREATE INDEX idx_notifications_user_id_created_at ON public.notifications(user_id, created_at DESC);
+CREATE INDEX idx_notifications_unread ON public.notifications(user_id) WHERE is_read = FALSE;
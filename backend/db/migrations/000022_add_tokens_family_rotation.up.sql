@@ -0,0 +1,10 @@
+-- Track refresh token lineage so replayed, already-rotated tokens can be detected
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS family_id UUID;
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS rotated_at TIMESTAMP;
+
+-- Existing tokens each start their own family
+UPDATE tokens SET family_id = gen_random_uuid() WHERE family_id IS NULL;
+ALTER TABLE tokens ALTER COLUMN family_id SET NOT NULL;
+
+-- Add indexes for performance
+CREATE INDEX IF NOT EXISTS idx_tokens_family_id ON tokens(family_id);
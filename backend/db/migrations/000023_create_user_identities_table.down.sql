@@ -0,0 +1,3 @@
+-- Drop user identities table
+DROP INDEX IF EXISTS idx_user_identities_user_id;
+DROP TABLE IF EXISTS public.user_identities;
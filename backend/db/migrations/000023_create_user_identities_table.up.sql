@@ -0,0 +1,13 @@
+-- Create user identities table linking external login providers to users
+CREATE TABLE public.user_identities (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    provider VARCHAR(50) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (provider, subject)
+);
+
+-- Add indexes for performance
+CREATE INDEX idx_user_identities_user_id ON public.user_identities(user_id);
@@ -0,0 +1,2 @@
+-- Drop AI usage table
+DROP TABLE IF EXISTS public.ai_usage;
@@ -0,0 +1,3 @@
+-- Drop workspace invitations table
+DROP INDEX IF EXISTS idx_workspace_invitations_workspace_email;
+DROP TABLE IF EXISTS public.workspace_invitations;
@@ -0,0 +1,16 @@
+-- Create workspace invitations table for inviting people by email
+CREATE TABLE public.workspace_invitations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id INTEGER NOT NULL REFERENCES public.workspaces(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('member', 'admin')),
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    invited_by INTEGER NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    accepted_by INTEGER REFERENCES public.users(id) ON DELETE SET NULL,
+    accepted_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Add indexes for performance
+CREATE INDEX idx_workspace_invitations_workspace_email ON public.workspace_invitations(workspace_id, LOWER(email));
@@ -0,0 +1,2 @@
+-- Remove workspace default page permission
+ALTER TABLE public.workspaces DROP COLUMN IF EXISTS default_page_permission;
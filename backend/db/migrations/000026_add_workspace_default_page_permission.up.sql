@@ -0,0 +1,3 @@
+-- Access level workspace members get on pages without an explicit permission
+ALTER TABLE public.workspaces ADD COLUMN default_page_permission VARCHAR(20) NOT NULL DEFAULT 'view'
+    CHECK (default_page_permission IN ('none', 'view', 'comment', 'edit'));
@@ -0,0 +1,3 @@
+-- Drop workspace activity table
+DROP INDEX IF EXISTS idx_workspace_activity_workspace_created;
+DROP TABLE IF EXISTS public.workspace_activity;
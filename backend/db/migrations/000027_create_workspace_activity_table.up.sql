@@ -0,0 +1,14 @@
+-- Create workspace activity table recording who did what in a workspace
+CREATE TABLE public.workspace_activity (
+    id BIGSERIAL PRIMARY KEY,
+    workspace_id INTEGER NOT NULL REFERENCES public.workspaces(id) ON DELETE CASCADE,
+    actor_id INTEGER REFERENCES public.users(id) ON DELETE SET NULL,
+    action VARCHAR(50) NOT NULL,
+    target_type VARCHAR(20) NOT NULL,
+    target_id VARCHAR(255) NOT NULL,
+    metadata JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Add indexes for performance
+CREATE INDEX idx_workspace_activity_workspace_created ON public.workspace_activity(workspace_id, created_at DESC, id DESC);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_pages_archived_with;
+ALTER TABLE public.pages DROP COLUMN IF EXISTS archived_with;
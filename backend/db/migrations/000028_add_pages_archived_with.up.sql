@@ -0,0 +1,5 @@
+-- Pages archived because an ancestor was archived with cascade point at that
+-- ancestor, so restoring it brings back only what its archive took down
+ALTER TABLE public.pages ADD COLUMN archived_with UUID REFERENCES public.pages(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_pages_archived_with ON public.pages(archived_with) WHERE archived_with IS NOT NULL;
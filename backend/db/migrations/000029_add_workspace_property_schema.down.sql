@@ -0,0 +1,2 @@
+-- Remove workspace property schema
+ALTER TABLE public.workspaces DROP COLUMN IF EXISTS property_schema;
@@ -0,0 +1,3 @@
+-- Optional JSON schema that page properties in the workspace must satisfy;
+-- NULL keeps properties free-form
+ALTER TABLE public.workspaces ADD COLUMN property_schema JSONB;
@@ -0,0 +1,3 @@
+-- Remove property query support
+DROP FUNCTION IF EXISTS public.try_timestamptz(TEXT);
+DROP INDEX IF EXISTS public.idx_pages_properties;
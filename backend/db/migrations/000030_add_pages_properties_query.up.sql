@@ -0,0 +1,13 @@
+-- Back property queries: containment (@>) filters use the GIN index, and
+-- range filters on dates go through a cast that yields NULL instead of failing
+-- on values that are not timestamps
+CREATE INDEX idx_pages_properties ON public.pages USING gin(properties);
+
+CREATE OR REPLACE FUNCTION public.try_timestamptz(value TEXT) RETURNS TIMESTAMPTZ
+LANGUAGE plpgsql STABLE AS $$
+BEGIN
+    RETURN value::timestamptz;
+EXCEPTION WHEN others THEN
+    RETURN NULL;
+END;
+$$;
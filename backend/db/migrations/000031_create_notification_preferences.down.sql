@@ -0,0 +1,2 @@
+-- Drop notification preferences table
+DROP TABLE IF EXISTS public.notification_preferences;
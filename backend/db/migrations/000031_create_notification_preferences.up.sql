@@ -0,0 +1,8 @@
+-- Per-user switches for optional notification emails. Users without a row
+-- get the column defaults.
+CREATE TABLE public.notification_preferences (
+    user_id INTEGER PRIMARY KEY REFERENCES public.users(id) ON DELETE CASCADE,
+    email_page_access_granted BOOLEAN NOT NULL DEFAULT TRUE,
+    email_page_access_revoked BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,3 @@
+-- Drop page share links table
+DROP INDEX IF EXISTS idx_page_share_links_page_id;
+DROP TABLE IF EXISTS public.page_share_links;
@@ -0,0 +1,15 @@
+-- Public read-only links to a page. Only a hash of each token is stored, and
+-- an optional password is kept as a bcrypt hash.
+CREATE TABLE public.page_share_links (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    page_id UUID NOT NULL REFERENCES public.pages(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    password_hash VARCHAR(255),
+    created_by INTEGER REFERENCES public.users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Add indexes for performance
+CREATE INDEX idx_page_share_links_page_id ON public.page_share_links(page_id);
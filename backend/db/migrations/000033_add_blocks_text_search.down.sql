@@ -0,0 +1,2 @@
+-- Remove block text search index
+DROP INDEX IF EXISTS idx_blocks_text_gin;
@@ -0,0 +1,3 @@
+-- Full-text index over the string values of block data, so page search can
+-- match block content and point at the matching block
+CREATE INDEX idx_blocks_text_gin ON public.blocks USING gin(jsonb_to_tsvector('english', block_data, '["string"]'));
@@ -0,0 +1,2 @@
+-- Drop refresh token client IP
+ALTER TABLE tokens DROP COLUMN IF EXISTS ip_address;
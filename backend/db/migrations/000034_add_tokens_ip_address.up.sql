@@ -0,0 +1,2 @@
+-- Record the client IP a refresh token was issued to, for session listings
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS ip_address VARCHAR(45);
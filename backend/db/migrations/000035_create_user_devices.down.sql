@@ -0,0 +1,3 @@
+-- Drop sign-in device tracking
+ALTER TABLE public.notification_preferences DROP COLUMN IF EXISTS email_new_device_login;
+DROP TABLE IF EXISTS public.user_devices;
@@ -0,0 +1,14 @@
+-- Devices each user has signed in from, so sign-ins from new ones can be
+-- reported. A device is identified by its parsed User-Agent label.
+CREATE TABLE public.user_devices (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    device_label VARCHAR(512) NOT NULL,
+    last_ip_address VARCHAR(45),
+    first_seen_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, device_label)
+);
+
+ALTER TABLE public.notification_preferences
+    ADD COLUMN IF NOT EXISTS email_new_device_login BOOLEAN NOT NULL DEFAULT TRUE;
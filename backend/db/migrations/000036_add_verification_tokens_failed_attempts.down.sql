@@ -0,0 +1,2 @@
+-- Drop per-code failed attempt counts
+ALTER TABLE verification_tokens DROP COLUMN IF EXISTS failed_attempts;
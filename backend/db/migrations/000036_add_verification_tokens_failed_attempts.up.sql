@@ -0,0 +1,2 @@
+-- Count wrong guesses per one-time code so a code can be invalidated after too many
+ALTER TABLE verification_tokens ADD COLUMN IF NOT EXISTS failed_attempts INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,137 @@
+// Package authz answers role questions about the authenticated user. Roles
+// come from the access token, which the auth middleware stores on the
+// request context; nothing here touches the database.
+package authz
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/constants"
+	"github.com/Srivathsav-max/lumen/backend/internal/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// hierarchy lists the roles each role implies. Implication is transitive,
+// so an admin also passes checks for developer and user.
+var hierarchy = map[string][]string{
+	constants.RoleAdmin:     {constants.RoleDeveloper},
+	constants.RoleDeveloper: {constants.RoleUser},
+	constants.RoleFree:      {constants.RoleUser},
+}
+
+// Roles returns the roles stored on the request by the auth middleware
+func Roles(c *gin.Context) []string {
+	// The JWT middleware sets both keys, the legacy auth middleware only userRoles
+	for _, key := range []string{"userRoles", string(constants.ContextKeyUserRoles)} {
+		if value, exists := c.Get(key); exists {
+			if roles, ok := value.([]string); ok {
+				return roles
+			}
+		}
+	}
+	return nil
+}
+
+// RolesGrant reports whether the given roles, directly or through the
+// hierarchy, include role
+func RolesGrant(roles []string, role string) bool {
+	seen := make(map[string]bool)
+	pending := append([]string(nil), roles...)
+	for len(pending) > 0 {
+		current := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if seen[current] {
+			continue
+		}
+		if current == role {
+			return true
+		}
+		seen[current] = true
+		pending = append(pending, hierarchy[current]...)
+	}
+	return false
+}
+
+// HasRole reports whether the authenticated user holds role
+func HasRole(c *gin.Context, role string) bool {
+	return RolesGrant(Roles(c), role)
+}
+
+// HasAnyRole reports whether the authenticated user holds at least one of roles
+func HasAnyRole(c *gin.Context, roles ...string) bool {
+	held := Roles(c)
+	for _, role := range roles {
+		if RolesGrant(held, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireRole lets a request through only if the user holds one of roles
+func RequireRole(logger *slog.Logger, roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !authorize(c, logger, roles) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// Policy maps route templates, as returned by gin's FullPath, to the roles
+// allowed to call them. An entry also covers every route below it, and the
+// longest matching entry wins; routes without an entry are left alone.
+type Policy map[string][]string
+
+// RoutePolicy is the role policy for the authenticated API
+var RoutePolicy = Policy{
+	"/api/v1/admin":    {constants.RoleAdmin},
+	"/api/v1/waitlist": {constants.RoleAdmin},
+}
+
+// Enforce applies policy to every route of the group it is installed on.
+// It must run after the auth middleware.
+func Enforce(logger *slog.Logger, policy Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if roles, ok := policy.RolesFor(c.FullPath()); ok && !authorize(c, logger, roles) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// RolesFor returns the roles allowed on a route template
+func (p Policy) RolesFor(route string) ([]string, bool) {
+	var matched string
+	var roles []string
+	found := false
+	for prefix, allowed := range p {
+		if route != prefix && !strings.HasPrefix(route, strings.TrimSuffix(prefix, "/")+"/") {
+			continue
+		}
+		if !found || len(prefix) > len(matched) {
+			matched, roles, found = prefix, allowed, true
+		}
+	}
+	return roles, found
+}
+
+func authorize(c *gin.Context, logger *slog.Logger, roles []string) bool {
+	if HasAnyRole(c, roles...) {
+		return true
+	}
+
+	userID, _ := c.Get("userID")
+	logger.Warn("Insufficient permissions",
+		"user_id", userID,
+		"user_roles", Roles(c),
+		"required_roles", roles,
+		"path", c.Request.URL.Path,
+		"method", c.Request.Method,
+		"ip", c.ClientIP(),
+	)
+	c.Error(errors.NewAuthorizationError("Insufficient permissions"))
+	c.Abort()
+	return false
+}
@@ -0,0 +1,133 @@
+package authz
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/constants"
+	"github.com/Srivathsav-max/lumen/backend/internal/errors"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRolesGrantFollowsHierarchy(t *testing.T) {
+	tests := []struct {
+		held []string
+		role string
+		want bool
+	}{
+		{[]string{constants.RoleAdmin}, constants.RoleAdmin, true},
+		{[]string{constants.RoleAdmin}, constants.RoleDeveloper, true},
+		{[]string{constants.RoleAdmin}, constants.RoleUser, true},
+		{[]string{constants.RoleAdmin}, constants.RoleFree, false},
+		{[]string{constants.RoleDeveloper}, constants.RoleUser, true},
+		{[]string{constants.RoleDeveloper}, constants.RoleAdmin, false},
+		{[]string{constants.RoleFree}, constants.RoleUser, true},
+		{[]string{constants.RoleFree}, constants.RoleDeveloper, false},
+		{[]string{constants.RoleUser}, constants.RoleFree, false},
+		{[]string{constants.RoleUser, constants.RoleDeveloper}, constants.RoleDeveloper, true},
+		{nil, constants.RoleUser, false},
+	}
+
+	for _, tt := range tests {
+		if got := RolesGrant(tt.held, tt.role); got != tt.want {
+			t.Errorf("RolesGrant(%v, %s) = %v, want %v", tt.held, tt.role, got, tt.want)
+		}
+	}
+}
+
+func testContext(key string, roles []string) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	if key != "" {
+		c.Set(key, roles)
+	}
+	return c
+}
+
+func TestRolesReadsEitherContextKey(t *testing.T) {
+	for _, key := range []string{"userRoles", string(constants.ContextKeyUserRoles)} {
+		c := testContext(key, []string{constants.RoleDeveloper})
+		if !HasRole(c, constants.RoleUser) || HasRole(c, constants.RoleAdmin) {
+			t.Errorf("roles under %q were not read", key)
+		}
+		if !HasAnyRole(c, constants.RoleAdmin, constants.RoleDeveloper) {
+			t.Errorf("HasAnyRole() under %q = false, want true", key)
+		}
+	}
+
+	if c := testContext("", nil); HasRole(c, constants.RoleUser) || Roles(c) != nil {
+		t.Error("a request without roles was granted a role")
+	}
+}
+
+func TestPolicyRolesFor(t *testing.T) {
+	policy := Policy{
+		"/api/v1/admin":       {constants.RoleAdmin},
+		"/api/v1/admin/stats": {constants.RoleDeveloper},
+		"/api/v1/tools/":      {constants.RoleDeveloper},
+	}
+
+	tests := []struct {
+		route string
+		want  string
+		found bool
+	}{
+		{"/api/v1/admin", constants.RoleAdmin, true},
+		{"/api/v1/admin/users/:id", constants.RoleAdmin, true},
+		{"/api/v1/admin/stats", constants.RoleDeveloper, true},
+		{"/api/v1/admin/stats/daily", constants.RoleDeveloper, true},
+		{"/api/v1/tools/run", constants.RoleDeveloper, true},
+		{"/api/v1/administrators", "", false},
+		{"/api/v1/pages", "", false},
+	}
+
+	for _, tt := range tests {
+		roles, found := policy.RolesFor(tt.route)
+		if found != tt.found || (found && roles[0] != tt.want) {
+			t.Errorf("RolesFor(%s) = %v, %v; want [%s], %v", tt.route, roles, found, tt.want, tt.found)
+		}
+	}
+}
+
+func TestEnforce(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	// serve returns the status and, as seen once the chain has run, whether
+	// the request was aborted and the errors it collected
+	serve := func(path string, roles []string) (int, bool, []*gin.Error) {
+		var aborted bool
+		var errs []*gin.Error
+		engine := gin.New()
+		engine.Use(func(c *gin.Context) {
+			c.Set("userRoles", roles)
+			c.Next()
+			aborted, errs = c.IsAborted(), c.Errors
+		}, Enforce(logger, RoutePolicy))
+		engine.GET("/api/v1/admin/users", func(c *gin.Context) { c.Status(http.StatusOK) })
+		engine.GET("/api/v1/pages", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Code, aborted, errs
+	}
+
+	if code, aborted, _ := serve("/api/v1/admin/users", []string{constants.RoleAdmin}); code != http.StatusOK || aborted {
+		t.Errorf("admin on an admin route: status = %d, aborted = %v; want 200", code, aborted)
+	}
+	if code, aborted, _ := serve("/api/v1/pages", []string{constants.RoleFree}); code != http.StatusOK || aborted {
+		t.Errorf("free user on an unlisted route: status = %d, aborted = %v; want 200", code, aborted)
+	}
+
+	_, aborted, errs := serve("/api/v1/admin/users", []string{constants.RoleDeveloper})
+	if !aborted || len(errs) != 1 {
+		t.Fatalf("developer on an admin route was not rejected")
+	}
+	if appErr, ok := errors.AsAppError(errs[0].Err); !ok || appErr.Code != errors.AuthorizationError {
+		t.Errorf("rejection error = %v, want an authorization error", errs[0].Err)
+	}
+}
@@ -1,10 +1,12 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Srivathsav-max/lumen/backend/internal/constants"
 	"github.com/Srivathsav-max/lumen/backend/internal/logger"
@@ -18,11 +20,23 @@ type Config struct {
 	Email    EmailConfig    `validate:"required"`
 	Logging  logger.Config  `validate:"required"`
 	AI       AIConfig       `validate:"required"`
+	// Onboarding is optional; without domain rules registration behaves as before
+	Onboarding     OnboardingConfig
+	PasswordPolicy PasswordPolicyConfig
+	// OAuth providers are disabled unless their client ID is set
+	OAuth OAuthConfig
+	// Metrics are off unless enabled; see MetricsConfig
+	Metrics   MetricsConfig
+	AccessLog AccessLogConfig
+	RateLimit RateLimitConfig
+	Editor    EditorConfig
 }
 
 type ServerConfig struct {
 	Port int
 	Env  string `validate:"required,oneof=development staging production"`
+	// RequestTimeout bounds each request's context; zero disables the deadline
+	RequestTimeout time.Duration `validate:"min=0"`
 }
 
 type DatabaseConfig struct {
@@ -40,9 +54,12 @@ type DatabaseConfig struct {
 }
 
 type JWTConfig struct {
-	Secret               string `validate:"required,min=32"`
-	AccessTokenDuration  int    `validate:"required,min=1"`
-	RefreshTokenDuration int    `validate:"required,min=1"`
+	Secret               string        `validate:"required,min=32"`
+	AccessTokenDuration  time.Duration `validate:"required,min=1s"`
+	RefreshTokenDuration time.Duration `validate:"required,min=1s"`
+	// FingerprintSalt keys the token fingerprint hash; it must be shared by
+	// every instance that validates tokens
+	FingerprintSalt string
 }
 
 type EmailConfig struct {
@@ -58,6 +75,104 @@ type EmailConfig struct {
 type AIConfig struct {
 	GeminiAPIKey string `validate:"required"`
 	GeminiModel  string `validate:"required"`
+	// MaxRetries is how many times transient Gemini failures are retried
+	MaxRetries int `validate:"min=0"`
+	// MonthlyTokenQuotas maps role names to monthly token allowances; 0 means unlimited
+	MonthlyTokenQuotas map[string]int64
+	// Conversation retention; a zero value disables the corresponding rule
+	ConversationRetentionDays    int `validate:"min=0"`
+	MaxConversationsPerUser      int `validate:"min=0"`
+	ConversationPruneIntervalMin int `validate:"min=1"`
+}
+
+// PasswordPolicyConfig holds the rules new passwords must satisfy
+type PasswordPolicyConfig struct {
+	MinLength int `validate:"min=1"`
+	// MaxLength counts bytes and is capped at the 72 bcrypt can hash
+	MaxLength     int `validate:"gtefield=MinLength"`
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	RejectCommon  bool
+}
+
+// MetricsConfig controls the Prometheus /metrics endpoint. With an empty Addr
+// it is served by the main server; otherwise it gets its own listener so it
+// can be bound to an internal interface, e.g. 127.0.0.1:9090.
+type MetricsConfig struct {
+	Enabled bool
+	Addr    string
+}
+
+// AccessLogConfig controls the per-request access log
+type AccessLogConfig struct {
+	Enabled bool
+	// SlowRequestThresholdMs bumps slower requests to Warn; 0 disables it
+	SlowRequestThresholdMs int `validate:"min=0"`
+}
+
+// RateLimitConfig selects how requests are attributed to rate limit buckets.
+// Keying on the user keeps users behind a shared NAT or proxy from
+// throttling each other.
+type RateLimitConfig struct {
+	KeyStrategy string `validate:"oneof=user ip"`
+	// Distributed shares counters across instances through Redis at RedisURL,
+	// e.g. redis://:password@localhost:6379/0
+	Distributed bool
+	RedisURL    string `validate:"required_if=Distributed true,omitempty,url"`
+}
+
+// EditorConfig adjusts the block types accepted when page content is saved.
+// ExtraBlockTypes maps a new type to the data fields it requires.
+type EditorConfig struct {
+	ExtraBlockTypes    map[string][]string
+	DisabledBlockTypes []string
+}
+
+type OAuthConfig struct {
+	Google GoogleOAuthConfig
+}
+
+type GoogleOAuthConfig struct {
+	ClientID     string
+	ClientSecret string `validate:"required_with=ClientID"`
+	RedirectURL  string `validate:"required_with=ClientID,omitempty,url"`
+	// SuccessRedirectURL is where the browser is sent once the callback finishes
+	SuccessRedirectURL string
+}
+
+// Enabled reports whether Google login is configured
+func (c *GoogleOAuthConfig) Enabled() bool {
+	return c.ClientID != ""
+}
+
+// OnboardingConfig maps email domains to the role and workspace new users get on registration
+type OnboardingConfig struct {
+	DomainRules []EmailDomainRule `validate:"dive"`
+}
+
+type EmailDomainRule struct {
+	Domain        string `json:"domain" validate:"required,fqdn"`
+	Role          string `json:"role" validate:"required"`
+	WorkspaceID   int64  `json:"workspace_id,omitempty" validate:"min=0"`
+	WorkspaceRole string `json:"workspace_role,omitempty" validate:"omitempty,oneof=member admin"`
+}
+
+// RuleForEmail returns the domain rule matching the email address, if any
+func (c *OnboardingConfig) RuleForEmail(email string) *EmailDomainRule {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return nil
+	}
+
+	domain := strings.ToLower(email[at+1:])
+	for i := range c.DomainRules {
+		if strings.ToLower(c.DomainRules[i].Domain) == domain {
+			return &c.DomainRules[i]
+		}
+	}
+	return nil
 }
 
 type ConfigLoader interface {
@@ -88,8 +203,9 @@ func (l *EnvConfigLoader) Load() (*Config, error) {
 	}
 
 	config.Server = ServerConfig{
-		Port: serverPort,
-		Env:  getRequiredEnv("ENV"),
+		Port:           serverPort,
+		Env:            getRequiredEnv("ENV"),
+		RequestTimeout: getEnvDuration("SERVER_REQUEST_TIMEOUT", time.Second, constants.DefaultRequestTimeout),
 	}
 
 	databaseURL := os.Getenv("DATABASE_URL")
@@ -123,8 +239,13 @@ func (l *EnvConfigLoader) Load() (*Config, error) {
 
 	config.JWT = JWTConfig{
 		Secret:               getRequiredEnv("JWT_SECRET"),
-		AccessTokenDuration:  getEnvInt("JWT_ACCESS_TOKEN_DURATION", constants.DefaultJWTAccessTokenDuration),
-		RefreshTokenDuration: getEnvInt("JWT_REFRESH_TOKEN_DURATION", constants.DefaultJWTRefreshTokenDuration),
+		AccessTokenDuration:  getEnvDuration("JWT_ACCESS_TOKEN_DURATION", time.Minute, constants.DefaultJWTAccessTokenDuration),
+		RefreshTokenDuration: getEnvDuration("JWT_REFRESH_TOKEN_DURATION", time.Hour, constants.DefaultJWTRefreshTokenDuration),
+		FingerprintSalt:      getEnv("JWT_FINGERPRINT_SALT", ""),
+	}
+	if config.JWT.FingerprintSalt == "" {
+		// Derived rather than random so fingerprints survive restarts
+		config.JWT.FingerprintSalt = config.JWT.Secret + ":fingerprint"
 	}
 
 	config.Email = EmailConfig{
@@ -140,6 +261,83 @@ func (l *EnvConfigLoader) Load() (*Config, error) {
 	config.AI = AIConfig{
 		GeminiAPIKey: getRequiredEnv("GEMINI_API_KEY"),
 		GeminiModel:  getEnv("GEMINI_MODEL", "gemini-2.5-flash"),
+		MaxRetries:   getEnvInt("AI_MAX_RETRIES", constants.DefaultAIMaxRetries),
+
+		ConversationRetentionDays:    getEnvInt("AI_CONVERSATION_RETENTION_DAYS", constants.DefaultAIConversationRetentionDays),
+		MaxConversationsPerUser:      getEnvInt("AI_MAX_CONVERSATIONS_PER_USER", constants.DefaultAIMaxConversationsPerUser),
+		ConversationPruneIntervalMin: getEnvInt("AI_CONVERSATION_PRUNE_INTERVAL", constants.DefaultAIConversationPruneIntervalMin),
+
+		MonthlyTokenQuotas: map[string]int64{
+			constants.RoleFree:      constants.DefaultAIMonthlyTokenQuotaFree,
+			constants.RoleUser:      constants.DefaultAIMonthlyTokenQuotaUser,
+			constants.RoleDeveloper: 0,
+			constants.RoleAdmin:     0,
+		},
+	}
+
+	// AI_MONTHLY_TOKEN_QUOTAS is a JSON object overriding per-role quotas, e.g.
+	// {"free":100000,"pro":5000000}
+	if quotas := os.Getenv("AI_MONTHLY_TOKEN_QUOTAS"); quotas != "" {
+		if err := json.Unmarshal([]byte(quotas), &config.AI.MonthlyTokenQuotas); err != nil {
+			return nil, fmt.Errorf("invalid AI_MONTHLY_TOKEN_QUOTAS: %w", err)
+		}
+	}
+
+	// EMAIL_DOMAIN_RULES is a JSON array, e.g.
+	// [{"domain":"company.com","role":"user","workspace_id":1,"workspace_role":"member"}]
+	if rules := os.Getenv("EMAIL_DOMAIN_RULES"); rules != "" {
+		if err := json.Unmarshal([]byte(rules), &config.Onboarding.DomainRules); err != nil {
+			return nil, fmt.Errorf("invalid EMAIL_DOMAIN_RULES: %w", err)
+		}
+	}
+
+	// EDITOR_EXTRA_BLOCK_TYPES is a JSON object of block type to required data
+	// fields, e.g. {"embed":["service","source"]}; EDITOR_DISABLED_BLOCK_TYPES
+	// is a JSON array of built-in types to reject, e.g. ["chart"]
+	if extra := os.Getenv("EDITOR_EXTRA_BLOCK_TYPES"); extra != "" {
+		if err := json.Unmarshal([]byte(extra), &config.Editor.ExtraBlockTypes); err != nil {
+			return nil, fmt.Errorf("invalid EDITOR_EXTRA_BLOCK_TYPES: %w", err)
+		}
+	}
+	if disabled := os.Getenv("EDITOR_DISABLED_BLOCK_TYPES"); disabled != "" {
+		if err := json.Unmarshal([]byte(disabled), &config.Editor.DisabledBlockTypes); err != nil {
+			return nil, fmt.Errorf("invalid EDITOR_DISABLED_BLOCK_TYPES: %w", err)
+		}
+	}
+
+	config.PasswordPolicy = PasswordPolicyConfig{
+		MinLength:     getEnvInt("PASSWORD_MIN_LENGTH", constants.MinPasswordLength),
+		MaxLength:     getEnvInt("PASSWORD_MAX_LENGTH", constants.MaxPasswordLength),
+		RequireUpper:  getEnvBool("PASSWORD_REQUIRE_UPPER", true),
+		RequireLower:  getEnvBool("PASSWORD_REQUIRE_LOWER", true),
+		RequireDigit:  getEnvBool("PASSWORD_REQUIRE_DIGIT", true),
+		RequireSymbol: getEnvBool("PASSWORD_REQUIRE_SYMBOL", false),
+		RejectCommon:  getEnvBool("PASSWORD_REJECT_COMMON", true),
+	}
+
+	config.OAuth = OAuthConfig{
+		Google: GoogleOAuthConfig{
+			ClientID:           os.Getenv("GOOGLE_OAUTH_CLIENT_ID"),
+			ClientSecret:       os.Getenv("GOOGLE_OAUTH_CLIENT_SECRET"),
+			RedirectURL:        os.Getenv("GOOGLE_OAUTH_REDIRECT_URL"),
+			SuccessRedirectURL: getEnv("OAUTH_SUCCESS_REDIRECT_URL", "/"),
+		},
+	}
+
+	config.Metrics = MetricsConfig{
+		Enabled: getEnvBool("METRICS_ENABLED", false),
+		Addr:    os.Getenv("METRICS_ADDR"),
+	}
+
+	config.AccessLog = AccessLogConfig{
+		Enabled:                getEnvBool("ACCESS_LOG_ENABLED", true),
+		SlowRequestThresholdMs: getEnvInt("ACCESS_LOG_SLOW_THRESHOLD_MS", constants.DefaultSlowRequestThresholdMs),
+	}
+
+	config.RateLimit = RateLimitConfig{
+		KeyStrategy: strings.ToLower(getEnv("RATE_LIMIT_KEY_STRATEGY", constants.DefaultRateLimitKeyStrategy)),
+		Distributed: getEnvBool("RATE_LIMIT_DISTRIBUTED", false),
+		RedisURL:    os.Getenv("RATE_LIMIT_REDIS_URL"),
 	}
 
 	config.Logging = logger.Config{
@@ -209,6 +407,30 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvDuration accepts Go duration strings such as "90s". Plain integers
+// are read as a count of unit, which keeps older minute and hour based
+// settings working.
+func getEnvDuration(key string, unit, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if intValue, err := strconv.Atoi(value); err == nil {
+			return time.Duration(intValue) * unit
+		}
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration
+		}
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
 func (c *Config) IsDevelopment() bool {
 	return strings.ToLower(c.Server.Env) == constants.EnvDevelopment
 }
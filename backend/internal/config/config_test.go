@@ -0,0 +1,31 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetEnvDuration(t *testing.T) {
+	const key = "LUMEN_TEST_DURATION"
+	const fallback = 5 * time.Minute
+
+	tests := []struct {
+		value string
+		unit  time.Duration
+		want  time.Duration
+	}{
+		{"", time.Minute, fallback},
+		{"15", time.Minute, 15 * time.Minute},
+		{"168", time.Hour, 168 * time.Hour},
+		{"90s", time.Minute, 90 * time.Second},
+		{"1h30m", time.Hour, 90 * time.Minute},
+		{"soon", time.Minute, fallback},
+	}
+
+	for _, tt := range tests {
+		t.Setenv(key, tt.value)
+		if got := getEnvDuration(key, tt.unit, fallback); got != tt.want {
+			t.Errorf("getEnvDuration(%q, %s) = %s, want %s", tt.value, tt.unit, got, tt.want)
+		}
+	}
+}
@@ -52,11 +52,14 @@ const (
 	BearerPrefix           = "Bearer "
 	CSRFTokenHeaderName    = "X-CSRF-Token"
 	CSRFTokenFieldName     = "csrf_token"
+	CSRFSessionCookieName  = "csrf_session"
 	AccessTokenCookieName  = "access_token"
 	RefreshTokenCookieName = "refresh_token"
 	SessionIDCookieName    = "session_id"
 	UserIDCookieName       = "user_id"
 	UserRolesCookieName    = "user_roles"
+	OAuthStateCookieName   = "oauth_state"
+	FingerprintCookieName  = "fingerprint"
 )
 
 // Log Levels
@@ -83,8 +86,50 @@ const (
 
 // JWT Configuration Defaults
 const (
-	DefaultJWTAccessTokenDuration  = 15 // minutes
-	DefaultJWTRefreshTokenDuration = 24 // hours
+	DefaultJWTAccessTokenDuration  = 15 * time.Minute
+	DefaultJWTRefreshTokenDuration = 24 * time.Hour
+)
+
+// AI Chat Retention Defaults
+// Pruning deletes user history, so both rules are off unless configured
+const (
+	DefaultAIConversationRetentionDays    = 0  // days; 0 disables age-based pruning
+	DefaultAIMaxConversationsPerUser      = 0  // 0 disables count-based pruning
+	DefaultAIConversationPruneIntervalMin = 60 // minutes
+)
+
+// AI Upstream Defaults
+const (
+	DefaultAIMaxRetries = 3
+)
+
+// AI Usage Quota Defaults (tokens per month)
+const (
+	DefaultAIMonthlyTokenQuotaFree = 200000
+	DefaultAIMonthlyTokenQuotaUser = 2000000
+)
+
+// Page Trash Defaults
+const (
+	DefaultTrashRetentionDays    = 30 // days before trashed pages are purged
+	DefaultTrashPurgeIntervalMin = 60 // minutes
+)
+
+// Health Check Defaults
+const (
+	HealthCheckDatabaseTimeout = 2 * time.Second
+	HealthCheckAITimeout       = 3 * time.Second
+	HealthCheckAICacheTTL      = time.Minute // upstream checks are cached to avoid hammering the API
+)
+
+// Access Log Defaults
+const (
+	DefaultSlowRequestThresholdMs = 1000 // requests at least this slow are logged at Warn
+)
+
+// Request Timeout Defaults
+const (
+	DefaultRequestTimeout = 30 * time.Second // deadline for a request's context; 0 disables it
 )
 
 // Email Configuration Defaults
@@ -103,8 +148,22 @@ const (
 	AccessTokenDuration      = 15 * time.Minute
 	RefreshTokenDuration     = 7 * 24 * time.Hour
 	RateLimitWindow          = time.Minute
+	// RefreshTokenReuseGrace is how long a just-rotated refresh token may
+	// still be exchanged, so concurrent refreshes from one client succeed
+	RefreshTokenReuseGrace = 10 * time.Second
+)
+
+// Password Change OTP
+const (
+	PasswordChangeOTPLength      = 6
+	PasswordChangeOTPTTL         = 10 * time.Minute
+	PasswordChangeOTPMaxRequests = 3
+	PasswordChangeOTPWindow      = 15 * time.Minute
 )
 
+// OTPMaxFailedAttempts is how many wrong guesses invalidate a one-time code
+const OTPMaxFailedAttempts = 5
+
 // Rate Limiting Defaults
 const (
 	DefaultGlobalRPM = 500
@@ -112,6 +171,12 @@ const (
 	DefaultAuthRPM   = 20
 	DefaultAPIRPM    = 200
 	DefaultBurst     = 50
+
+	// Rate limit key strategies: "user" keys authenticated requests on the
+	// user ID and falls back to the client IP; "ip" always uses the IP
+	RateLimitKeyUser            = "user"
+	RateLimitKeyIP              = "ip"
+	DefaultRateLimitKeyStrategy = RateLimitKeyUser
 )
 
 // CORS Headers
@@ -198,7 +263,7 @@ const (
 // Validation Rules
 const (
 	MinPasswordLength = 8
-	MaxPasswordLength = 128
+	MaxPasswordLength = 72 // bytes; bcrypt rejects longer passwords
 	MinUsernameLength = 3
 	MaxUsernameLength = 50
 	MaxEmailLength    = 255
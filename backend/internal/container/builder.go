@@ -1,9 +1,7 @@
 package container
 
 import (
-	"crypto/rand"
 	"database/sql"
-	"encoding/hex"
 	"fmt"
 	"time"
 
@@ -75,6 +73,7 @@ func (b *Builder) WithRepositories() (*Builder, error) {
 	dbManager := database.NewPostgresManager(b.container.DB, b.container.Logger)
 
 	userRepo := postgres.NewUserRepository(dbManager, b.container.Logger)
+	userIdentityRepo := postgres.NewUserIdentityRepository(dbManager, b.container.Logger)
 	roleRepo := postgres.NewRoleRepository(dbManager, b.container.Logger)
 	tokenRepo := postgres.NewTokenRepository(dbManager, b.container.Logger)
 	verificationTokenRepo := postgres.NewVerificationTokenRepository(dbManager, b.container.Logger)
@@ -83,13 +82,21 @@ func (b *Builder) WithRepositories() (*Builder, error) {
 
 	// Notes System Repositories
 	workspaceRepo := postgres.NewWorkspaceRepository(dbManager, b.container.Logger)
+	workspaceInvitationRepo := postgres.NewWorkspaceInvitationRepository(dbManager, b.container.Logger)
+	activityRepo := postgres.NewActivityRepository(dbManager, b.container.Logger)
 	pageRepo := postgres.NewPageRepository(dbManager, b.container.Logger)
 	blockRepo := postgres.NewBlockRepository(dbManager, b.container.Logger)
 	commentRepo := postgres.NewCommentRepository(dbManager, b.container.Logger)
+	notificationRepo := postgres.NewNotificationRepository(dbManager, b.container.Logger)
+	notificationPreferenceRepo := postgres.NewNotificationPreferenceRepository(dbManager, b.container.Logger)
+	pageShareLinkRepo := postgres.NewPageShareLinkRepository(dbManager, b.container.Logger)
+	userDeviceRepo := postgres.NewUserDeviceRepository(dbManager, b.container.Logger)
 	aiConvRepo := postgres.NewAIConversationRepository(dbManager, b.container.Logger)
 	aiMsgRepo := postgres.NewAIMessageRepository(dbManager, b.container.Logger)
+	aiUsageRepo := postgres.NewAIUsageRepository(dbManager, b.container.Logger)
 
 	b.container.SetUserRepository(userRepo)
+	b.container.SetUserIdentityRepository(userIdentityRepo)
 	b.container.SetRoleRepository(roleRepo)
 	b.container.SetTokenRepository(tokenRepo)
 	b.container.SetVerificationTokenRepository(verificationTokenRepo)
@@ -98,11 +105,18 @@ func (b *Builder) WithRepositories() (*Builder, error) {
 
 	// Set Notes System Repositories
 	b.container.SetWorkspaceRepository(workspaceRepo)
+	b.container.SetWorkspaceInvitationRepository(workspaceInvitationRepo)
+	b.container.SetActivityRepository(activityRepo)
 	b.container.SetPageRepository(pageRepo)
 	b.container.SetBlockRepository(blockRepo)
 	b.container.SetCommentRepository(commentRepo)
+	b.container.SetNotificationRepository(notificationRepo)
+	b.container.SetNotificationPreferenceRepository(notificationPreferenceRepo)
+	b.container.SetPageShareLinkRepository(pageShareLinkRepo)
+	b.container.SetUserDeviceRepository(userDeviceRepo)
 	b.container.SetAIConversationRepository(aiConvRepo)
 	b.container.SetAIMessageRepository(aiMsgRepo)
+	b.container.SetAIUsageRepository(aiUsageRepo)
 
 	return b, nil
 }
@@ -135,7 +149,11 @@ func (b *Builder) WithServices() (*Builder, error) {
 	userService := services.NewUserService(
 		b.container.UserRepository,
 		b.container.RoleRepository,
+		b.container.WorkspaceRepository,
 		emailService,
+		verificationTokenService,
+		&b.container.Config.Onboarding,
+		services.NewPasswordPolicy(b.container.Config.PasswordPolicy),
 		b.container.Logger,
 	)
 
@@ -146,6 +164,18 @@ func (b *Builder) WithServices() (*Builder, error) {
 		b.container.RoleRepository,
 		verificationTokenService,
 		emailService,
+		b.container.UserDeviceRepository,
+		b.container.NotificationPreferenceRepository,
+		b.container.Logger,
+	)
+
+	oauthService := services.NewOAuthService(
+		b.container.Config.OAuth.Google,
+		b.container.UserRepository,
+		b.container.RoleRepository,
+		b.container.UserIdentityRepository,
+		b.container.TokenRepository,
+		userService,
 		b.container.Logger,
 	)
 
@@ -166,9 +196,19 @@ func (b *Builder) WithServices() (*Builder, error) {
 	)
 
 	// Notes System Services
+	activityService := services.NewActivityService(
+		b.container.ActivityRepository,
+		b.container.WorkspaceRepository,
+		b.container.UserRepository,
+		b.container.Logger,
+	)
+
 	workspaceService := services.NewWorkspaceService(
 		b.container.WorkspaceRepository,
 		b.container.UserRepository,
+		b.container.WorkspaceInvitationRepository,
+		emailService,
+		activityService,
 		b.container.Logger,
 	)
 
@@ -177,24 +217,62 @@ func (b *Builder) WithServices() (*Builder, error) {
 		b.container.BlockRepository,
 		b.container.WorkspaceRepository,
 		b.container.UserRepository,
+		activityService,
+		emailService,
+		b.container.NotificationPreferenceRepository,
+		services.NewBlockTypeRegistry(b.container.Config.Editor.ExtraBlockTypes, b.container.Config.Editor.DisabledBlockTypes),
+		security.NewXSSService(security.DefaultXSSConfig(), b.container.Logger),
+		b.container.Logger,
+	)
+
+	viewerTokenService := services.NewViewerTokenService(b.container.PageRepository, b.container.Config.JWT.Secret, b.container.Logger)
+
+	shareLinkService := services.NewShareLinkService(
+		b.container.PageShareLinkRepository,
+		b.container.PageRepository,
+		b.container.BlockRepository,
+		b.container.Logger,
+	)
+
+	commentService := services.NewCommentService(
+		b.container.CommentRepository,
+		b.container.PageRepository,
+		b.container.BlockRepository,
+		b.container.UserRepository,
+		b.container.NotificationRepository,
+		b.container.Logger,
+	)
+
+	notificationService := services.NewNotificationService(
+		b.container.NotificationRepository,
+		b.container.NotificationPreferenceRepository,
+		b.container.UserRepository,
 		b.container.Logger,
 	)
 
 	aiService := services.NewAIService(&b.container.Config.AI, pageService, b.container.Logger)
-	aiChatService := services.NewAIChatService(b.container.GetAIConversationRepository(), b.container.GetAIMessageRepository(), b.container.Logger)
+	aiUsageService := services.NewAIUsageService(b.container.AIUsageRepository, b.container.RoleRepository, &b.container.Config.AI, b.container.Logger)
+	aiChatService := services.NewAIChatService(b.container.GetAIConversationRepository(), b.container.GetAIMessageRepository(), aiService, &b.container.Config.AI, b.container.Logger)
 
 	b.container.SetEmailService(emailService)
 	b.container.SetVerificationTokenService(verificationTokenService)
 	b.container.SetUserService(userService)
 	b.container.SetAuthService(authService)
+	b.container.SetOAuthService(oauthService)
 	b.container.SetRoleService(roleService)
 	b.container.SetWaitlistService(waitlistService)
 	b.container.SetSystemSettingsService(systemSettingsService)
 
+	b.container.SetActivityService(activityService)
 	b.container.SetWorkspaceService(workspaceService)
 	b.container.SetPageService(pageService)
+	b.container.SetViewerTokenService(viewerTokenService)
+	b.container.SetShareLinkService(shareLinkService)
+	b.container.SetCommentService(commentService)
+	b.container.SetNotificationService(notificationService)
 	b.container.SetAIService(aiService)
 	b.container.AIChatService = aiChatService
+	b.container.SetAIUsageService(aiUsageService)
 
 	return b, nil
 }
@@ -217,6 +295,7 @@ func (b *Builder) WithSecurity() (*Builder, error) {
 		"csrf_enabled", securityConfig.CSRF.Enabled,
 		"csp_enabled", securityConfig.CSP.Enabled,
 		"rate_limit_enabled", securityConfig.RateLimit.Enabled,
+		"rate_limit_distributed", securityConfig.RateLimit.Distributed,
 	)
 
 	return b, nil
@@ -284,13 +363,13 @@ func (b *Builder) createSecurityConfig() *security.SecurityConfig {
 	return &security.SecurityConfig{
 		JWT: security.JWTSecurityConfig{
 			Secret:               cfg.JWT.Secret,
-			AccessTokenDuration:  time.Duration(cfg.JWT.AccessTokenDuration) * time.Minute,
-			RefreshTokenDuration: time.Duration(cfg.JWT.RefreshTokenDuration) * time.Hour,
+			AccessTokenDuration:  cfg.JWT.AccessTokenDuration,
+			RefreshTokenDuration: cfg.JWT.RefreshTokenDuration,
 			Algorithm:            constants.JWTAlgorithmHS256,
 			Issuer:               "lumen-backend",
 			Audience:             []string{"lumen-frontend"},
 			EnableFingerprinting: !cfg.IsDevelopment(),
-			FingerprintSalt:      b.generateFingerprintSalt(),
+			FingerprintSalt:      cfg.JWT.FingerprintSalt,
 		},
 		CSRF: security.CSRFConfig{
 			Enabled:         cfg.IsProduction(),
@@ -301,6 +380,7 @@ func (b *Builder) createSecurityConfig() *security.SecurityConfig {
 			SecureCookie:    cfg.IsProduction(),
 			SameSite:        "strict",
 			TrustedOrigins:  b.getAllowedOrigins(),
+			SigningKey:      cfg.JWT.Secret,
 		},
 		Session: security.SessionConfig{
 			SessionIDLength: 32,
@@ -319,13 +399,15 @@ func (b *Builder) createSecurityConfig() *security.SecurityConfig {
 			APIRPM:      200,
 			Burst:       50,
 			Window:      time.Minute,
-			Distributed: false,
+			KeyStrategy: cfg.RateLimit.KeyStrategy,
+			Distributed: cfg.RateLimit.Distributed,
+			RedisURL:    cfg.RateLimit.RedisURL,
 		},
 		CORS: security.CORSConfig{
 			AllowedOrigins:   b.getAllowedOrigins(),
 			AllowedMethods:   []string{constants.HTTPMethodGET, constants.HTTPMethodPOST, constants.HTTPMethodPUT, constants.HTTPMethodDELETE, constants.HTTPMethodPATCH, constants.HTTPMethodOPTIONS},
 			AllowedHeaders:   []string{constants.HeaderContentType, constants.HeaderAuthorization, constants.HeaderCSRFToken, constants.HeaderRequestedWith, constants.HeaderRequestID, constants.HeaderBrowserFingerprint},
-			ExposedHeaders:   []string{"X-Request-ID", "X-RateLimit-Limit", "X-RateLimit-Remaining"},
+			ExposedHeaders:   []string{"X-Request-ID", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"},
 			AllowCredentials: true,
 			MaxAge:           86400, // 24 hours
 		},
@@ -387,9 +469,3 @@ func (b *Builder) getDomain() string {
 
 	return "moxium.tech"
 }
-
-func (b *Builder) generateFingerprintSalt() string {
-	salt := make([]byte, 32)
-	rand.Read(salt)
-	return hex.EncodeToString(salt)
-}
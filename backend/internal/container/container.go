@@ -17,6 +17,7 @@ type Container struct {
 	SecurityConfig *security.SecurityConfig
 
 	UserRepository              repository.UserRepository
+	UserIdentityRepository      repository.UserIdentityRepository
 	RoleRepository              repository.RoleRepository
 	TokenRepository             repository.TokenRepository
 	VerificationTokenRepository repository.VerificationTokenRepository
@@ -24,15 +25,23 @@ type Container struct {
 	SystemSettingsRepository    repository.SystemSettingsRepository
 
 	// Notes System Repositories
-	WorkspaceRepository      repository.WorkspaceRepository
-	PageRepository           repository.PageRepository
-	BlockRepository          repository.BlockRepository
-	CommentRepository        repository.CommentRepository
-	AIConversationRepository repository.AIConversationRepository
-	AIMessageRepository      repository.AIMessageRepository
+	WorkspaceRepository              repository.WorkspaceRepository
+	WorkspaceInvitationRepository    repository.WorkspaceInvitationRepository
+	ActivityRepository               repository.ActivityRepository
+	PageRepository                   repository.PageRepository
+	BlockRepository                  repository.BlockRepository
+	CommentRepository                repository.CommentRepository
+	NotificationRepository           repository.NotificationRepository
+	NotificationPreferenceRepository repository.NotificationPreferenceRepository
+	PageShareLinkRepository          repository.PageShareLinkRepository
+	UserDeviceRepository             repository.UserDeviceRepository
+	AIConversationRepository         repository.AIConversationRepository
+	AIMessageRepository              repository.AIMessageRepository
+	AIUsageRepository                repository.AIUsageRepository
 
 	UserService              services.UserService
 	AuthService              services.AuthService
+	OAuthService             services.OAuthService
 	EmailService             services.EmailService
 	VerificationTokenService services.VerificationTokenService
 	RoleService              services.RoleService
@@ -40,9 +49,15 @@ type Container struct {
 	SystemSettingsService    services.SystemSettingsService
 
 	// Notes System Services
-	WorkspaceService services.WorkspaceService
-	PageService      services.PageService
-	AIChatService    services.AIChatService
+	WorkspaceService    services.WorkspaceService
+	ActivityService     services.ActivityService
+	PageService         services.PageService
+	ViewerTokenService  services.ViewerTokenService
+	ShareLinkService    services.ShareLinkService
+	CommentService      services.CommentService
+	NotificationService services.NotificationService
+	AIChatService       services.AIChatService
+	AIUsageService      services.AIUsageService
 
 	// AI Service
 	AIService services.AIService
@@ -98,6 +113,10 @@ func (c *Container) SetUserRepository(repo repository.UserRepository) {
 	c.UserRepository = repo
 }
 
+func (c *Container) SetUserIdentityRepository(repo repository.UserIdentityRepository) {
+	c.UserIdentityRepository = repo
+}
+
 func (c *Container) SetRoleRepository(repo repository.RoleRepository) {
 	c.RoleRepository = repo
 }
@@ -126,6 +145,10 @@ func (c *Container) SetAuthService(service services.AuthService) {
 	c.AuthService = service
 }
 
+func (c *Container) SetOAuthService(service services.OAuthService) {
+	c.OAuthService = service
+}
+
 func (c *Container) SetEmailService(service services.EmailService) {
 	c.EmailService = service
 }
@@ -151,6 +174,14 @@ func (c *Container) SetWorkspaceRepository(repo repository.WorkspaceRepository)
 	c.WorkspaceRepository = repo
 }
 
+func (c *Container) SetActivityRepository(repo repository.ActivityRepository) {
+	c.ActivityRepository = repo
+}
+
+func (c *Container) SetWorkspaceInvitationRepository(repo repository.WorkspaceInvitationRepository) {
+	c.WorkspaceInvitationRepository = repo
+}
+
 func (c *Container) SetPageRepository(repo repository.PageRepository) {
 	c.PageRepository = repo
 }
@@ -163,6 +194,22 @@ func (c *Container) SetCommentRepository(repo repository.CommentRepository) {
 	c.CommentRepository = repo
 }
 
+func (c *Container) SetNotificationRepository(repo repository.NotificationRepository) {
+	c.NotificationRepository = repo
+}
+
+func (c *Container) SetNotificationPreferenceRepository(repo repository.NotificationPreferenceRepository) {
+	c.NotificationPreferenceRepository = repo
+}
+
+func (c *Container) SetPageShareLinkRepository(repo repository.PageShareLinkRepository) {
+	c.PageShareLinkRepository = repo
+}
+
+func (c *Container) SetUserDeviceRepository(repo repository.UserDeviceRepository) {
+	c.UserDeviceRepository = repo
+}
+
 func (c *Container) SetAIConversationRepository(repo repository.AIConversationRepository) {
 	c.AIConversationRepository = repo
 }
@@ -171,7 +218,15 @@ func (c *Container) SetAIMessageRepository(repo repository.AIMessageRepository)
 	c.AIMessageRepository = repo
 }
 
+func (c *Container) SetAIUsageRepository(repo repository.AIUsageRepository) {
+	c.AIUsageRepository = repo
+}
+
 // Notes System Service Setters
+func (c *Container) SetActivityService(service services.ActivityService) {
+	c.ActivityService = service
+}
+
 func (c *Container) SetWorkspaceService(service services.WorkspaceService) {
 	c.WorkspaceService = service
 }
@@ -180,14 +235,38 @@ func (c *Container) SetPageService(service services.PageService) {
 	c.PageService = service
 }
 
+func (c *Container) SetViewerTokenService(service services.ViewerTokenService) {
+	c.ViewerTokenService = service
+}
+
+func (c *Container) SetShareLinkService(service services.ShareLinkService) {
+	c.ShareLinkService = service
+}
+
+func (c *Container) SetCommentService(service services.CommentService) {
+	c.CommentService = service
+}
+
+func (c *Container) SetNotificationService(service services.NotificationService) {
+	c.NotificationService = service
+}
+
 func (c *Container) SetAIService(service services.AIService) {
 	c.AIService = service
 }
 
+func (c *Container) SetAIUsageService(service services.AIUsageService) {
+	c.AIUsageService = service
+}
+
 func (c *Container) GetUserRepository() repository.UserRepository {
 	return c.UserRepository
 }
 
+func (c *Container) GetUserIdentityRepository() repository.UserIdentityRepository {
+	return c.UserIdentityRepository
+}
+
 func (c *Container) GetRoleRepository() repository.RoleRepository {
 	return c.RoleRepository
 }
@@ -216,6 +295,10 @@ func (c *Container) GetAuthService() services.AuthService {
 	return c.AuthService
 }
 
+func (c *Container) GetOAuthService() services.OAuthService {
+	return c.OAuthService
+}
+
 func (c *Container) GetEmailService() services.EmailService {
 	return c.EmailService
 }
@@ -237,6 +320,14 @@ func (c *Container) GetWorkspaceRepository() repository.WorkspaceRepository {
 	return c.WorkspaceRepository
 }
 
+func (c *Container) GetActivityRepository() repository.ActivityRepository {
+	return c.ActivityRepository
+}
+
+func (c *Container) GetWorkspaceInvitationRepository() repository.WorkspaceInvitationRepository {
+	return c.WorkspaceInvitationRepository
+}
+
 func (c *Container) GetPageRepository() repository.PageRepository {
 	return c.PageRepository
 }
@@ -249,6 +340,22 @@ func (c *Container) GetCommentRepository() repository.CommentRepository {
 	return c.CommentRepository
 }
 
+func (c *Container) GetNotificationRepository() repository.NotificationRepository {
+	return c.NotificationRepository
+}
+
+func (c *Container) GetNotificationPreferenceRepository() repository.NotificationPreferenceRepository {
+	return c.NotificationPreferenceRepository
+}
+
+func (c *Container) GetPageShareLinkRepository() repository.PageShareLinkRepository {
+	return c.PageShareLinkRepository
+}
+
+func (c *Container) GetUserDeviceRepository() repository.UserDeviceRepository {
+	return c.UserDeviceRepository
+}
+
 func (c *Container) GetAIConversationRepository() repository.AIConversationRepository {
 	return c.AIConversationRepository
 }
@@ -257,7 +364,15 @@ func (c *Container) GetAIMessageRepository() repository.AIMessageRepository {
 	return c.AIMessageRepository
 }
 
+func (c *Container) GetAIUsageRepository() repository.AIUsageRepository {
+	return c.AIUsageRepository
+}
+
 // Notes System Service Getters
+func (c *Container) GetActivityService() services.ActivityService {
+	return c.ActivityService
+}
+
 func (c *Container) GetWorkspaceService() services.WorkspaceService {
 	return c.WorkspaceService
 }
@@ -266,10 +381,30 @@ func (c *Container) GetPageService() services.PageService {
 	return c.PageService
 }
 
+func (c *Container) GetViewerTokenService() services.ViewerTokenService {
+	return c.ViewerTokenService
+}
+
+func (c *Container) GetShareLinkService() services.ShareLinkService {
+	return c.ShareLinkService
+}
+
+func (c *Container) GetCommentService() services.CommentService {
+	return c.CommentService
+}
+
+func (c *Container) GetNotificationService() services.NotificationService {
+	return c.NotificationService
+}
+
 func (c *Container) GetAIService() services.AIService {
 	return c.AIService
 }
 
+func (c *Container) GetAIUsageService() services.AIUsageService {
+	return c.AIUsageService
+}
+
 func (c *Container) Validate() error {
 	if c.Config == nil {
 		return ErrMissingDependency("config")
@@ -2,8 +2,10 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"log/slog"
+	"time"
 )
 
 type HealthChecker struct {
@@ -54,6 +56,41 @@ func (h *HealthChecker) DetailedCheck(ctx context.Context) map[string]interface{
 	return result
 }
 
+// ReadinessCheck pings the database within ctx's deadline and reports the
+// latency and applied migration version. It returns an error only when the
+// database is unreachable; a missing migrations table is reported, not fatal.
+func (h *HealthChecker) ReadinessCheck(ctx context.Context) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	db := h.manager.GetDB()
+
+	start := time.Now()
+	if err := db.PingContext(ctx); err != nil {
+		h.logger.Error("Database readiness check failed", "error", err)
+		result["status"] = "unhealthy"
+		result["error"] = "database unreachable"
+		return result, err
+	}
+	result["status"] = "healthy"
+	result["latency_ms"] = time.Since(start).Milliseconds()
+
+	// golang-migrate keeps a single row with the current version
+	var version int64
+	var dirty bool
+	err := db.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	switch {
+	case err == nil:
+		result["migration_version"] = version
+		result["migration_dirty"] = dirty
+	case err == sql.ErrNoRows:
+		result["migration_version"] = nil
+	default:
+		h.logger.Warn("Failed to read migration version", "error", err)
+		result["migration_version"] = nil
+	}
+
+	return result, nil
+}
+
 func (h *HealthChecker) CheckWithTimeout(ctx context.Context) error {
 	select {
 	case <-ctx.Done():
@@ -1,11 +1,14 @@
 package handlers
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/Srivathsav-max/lumen/backend/internal/errors"
 	"github.com/Srivathsav-max/lumen/backend/internal/services"
 )
 
@@ -13,6 +16,7 @@ type AIHandlers struct {
 	ai     services.AIService
 	logger *slog.Logger
 	chat   services.AIChatService
+	usage  services.AIUsageService
 }
 
 func NewAIHandlers(ai services.AIService, logger *slog.Logger) *AIHandlers {
@@ -32,12 +36,17 @@ func (h *AIHandlers) GenerateNoteContent(c *gin.Context) {
 		}
 	}
 
+	if !h.checkQuota(c, spec.UserID) {
+		return
+	}
+
 	resp, err := h.ai.GenerateContent(c.Request.Context(), &spec)
 	if err != nil {
 		h.logger.Error("AI generation failed", "error", err)
 		c.JSON(http.StatusBadGateway, gin.H{"error": "AI generation failed"})
 		return
 	}
+	h.recordUsage(c.Request.Context(), spec.UserID, resp.Usage)
 
 	c.JSON(http.StatusOK, gin.H{"data": resp})
 }
@@ -70,6 +79,86 @@ func (h *AIHandlers) SaveExchange(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"ok": true, "conversation_id": convID})
 }
 
+type streamChatRequest struct {
+	Type   string  `json:"type" binding:"required"`
+	PageID *string `json:"page_id"`
+	Query  string  `json:"query" binding:"required"`
+	Format string  `json:"format"`
+}
+
+// StreamChat streams the model's answer as server-sent events and saves the
+// exchange once the stream ends. Each "chunk" event carries a piece of text;
+// a final "done" or "error" event closes the stream.
+func (h *AIHandlers) StreamChat(c *gin.Context) {
+	var req streamChatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	userIDVal, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	userID, _ := userIDVal.(int64)
+	if h.chat == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Chat service not available"})
+		return
+	}
+	if !h.checkQuota(c, userID) {
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	// The request context is cancelled when the client disconnects, which
+	// also aborts the upstream request
+	ctx := c.Request.Context()
+	spec := &services.AISpec{Query: req.Query, Format: req.Format, UserID: userID}
+	if req.PageID != nil {
+		spec.PageID = *req.PageID
+	}
+
+	resp, streamErr := h.ai.StreamContent(ctx, spec, func(text string) error {
+		c.SSEvent("chunk", gin.H{"text": text})
+		c.Writer.Flush()
+		return ctx.Err()
+	})
+	var answer string
+	if resp != nil {
+		answer = resp.Text
+		h.recordUsage(ctx, userID, resp.Usage)
+	}
+	truncated := streamErr != nil
+	if truncated && ctx.Err() == nil {
+		h.logger.Error("AI chat stream failed", "error", streamErr, "user_id", userID)
+	}
+
+	// Save whatever was generated, even if the client has gone away
+	var convID string
+	if answer != "" || !truncated {
+		var err error
+		convID, err = h.chat.SaveStreamedExchange(context.WithoutCancel(ctx), userID, req.Type, req.PageID, req.Query, answer, truncated)
+		if err != nil {
+			h.logger.Error("Failed to save streamed exchange", "error", err, "user_id", userID)
+		}
+	}
+
+	if ctx.Err() != nil {
+		return
+	}
+	if streamErr != nil {
+		c.SSEvent("error", gin.H{"error": "AI generation failed", "conversation_id": convID})
+	} else {
+		c.SSEvent("done", gin.H{"conversation_id": convID})
+	}
+	c.Writer.Flush()
+}
+
 func (h *AIHandlers) GetHistory(c *gin.Context) {
 	chatType := c.Query("type")
 	if chatType == "" {
@@ -92,3 +181,140 @@ func (h *AIHandlers) GetHistory(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, gin.H{"data": msgs})
 }
+
+func (h *AIHandlers) ClearConversations(c *gin.Context) {
+	userIDVal, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	userID, _ := userIDVal.(int64)
+	if h.chat == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Chat service not available"})
+		return
+	}
+	deleted, err := h.chat.ClearConversations(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear conversations"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true, "deleted": deleted})
+}
+
+func (h *AIHandlers) ListConversations(c *gin.Context) {
+	userIDVal, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	userID, _ := userIDVal.(int64)
+	if h.chat == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Chat service not available"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	convs, err := h.chat.ListConversations(c.Request.Context(), userID, limit, offset)
+	if err != nil {
+		h.respondChatError(c, err, "Failed to list conversations")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": convs})
+}
+
+func (h *AIHandlers) DeleteConversation(c *gin.Context) {
+	userIDVal, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	userID, _ := userIDVal.(int64)
+	if h.chat == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Chat service not available"})
+		return
+	}
+	if err := h.chat.DeleteConversation(c.Request.Context(), userID, c.Param("id")); err != nil {
+		h.respondChatError(c, err, "Failed to delete conversation")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+type renameConversationRequest struct {
+	Title string `json:"title" binding:"required"`
+}
+
+func (h *AIHandlers) RenameConversation(c *gin.Context) {
+	var req renameConversationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	userIDVal, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	userID, _ := userIDVal.(int64)
+	if h.chat == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Chat service not available"})
+		return
+	}
+	conv, err := h.chat.RenameConversation(c.Request.Context(), userID, c.Param("id"), req.Title)
+	if err != nil {
+		h.respondChatError(c, err, "Failed to rename conversation")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": conv})
+}
+
+// respondChatError reports service errors with their status, hiding internal details
+func (h *AIHandlers) respondChatError(c *gin.Context, err error, fallback string) {
+	if appErr, ok := errors.AsAppError(err); ok && appErr.StatusCode < http.StatusInternalServerError {
+		c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+		return
+	}
+	h.logger.Error(fallback, "error", err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+}
+
+func (h *AIHandlers) GetUsage(c *gin.Context) {
+	userIDVal, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	userID, _ := userIDVal.(int64)
+	if h.usage == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Usage tracking not available"})
+		return
+	}
+	usage, err := h.usage.GetUsage(c.Request.Context(), userID)
+	if err != nil {
+		h.respondChatError(c, err, "Failed to get AI usage")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": usage})
+}
+
+// checkQuota writes an error response and returns false when the user may not
+// call the model
+func (h *AIHandlers) checkQuota(c *gin.Context, userID int64) bool {
+	if h.usage == nil || userID == 0 {
+		return true
+	}
+	if err := h.usage.CheckQuota(c.Request.Context(), userID); err != nil {
+		h.respondChatError(c, err, "Failed to check AI usage")
+		return false
+	}
+	return true
+}
+
+// recordUsage is detached from the request's cancellation so usage is kept
+// even when the client disconnects
+func (h *AIHandlers) recordUsage(ctx context.Context, userID int64, usage *services.AITokenUsage) {
+	if h.usage == nil || userID == 0 || usage == nil {
+		return
+	}
+	_ = h.usage.RecordUsage(context.WithoutCancel(ctx), userID, usage)
+}
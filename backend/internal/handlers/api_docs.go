@@ -0,0 +1,348 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/middleware"
+	"github.com/Srivathsav-max/lumen/backend/internal/openapi"
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+	"github.com/Srivathsav-max/lumen/backend/internal/services"
+)
+
+// The operations below annotate the /api/v1 routes for the OpenAPI spec. Each
+// entry names the DTOs its handler binds and returns; keep them in step with
+// the router, which warns at startup about routes missing from this list.
+
+const apiV1 = "/api/v1"
+
+var (
+	limitParam            = openapi.Param{Name: "limit", Type: "integer", Description: "Page size, 1 to 100"}
+	offsetParam           = openapi.Param{Name: "offset", Type: "integer", Description: "Number of items to skip"}
+	fieldsParam           = openapi.Param{Name: "fields", Description: "Comma-separated list of fields to return"}
+	includeArchivedParam  = openapi.Param{Name: "include_archived", Type: "boolean"}
+	includeTemplatesParam = openapi.Param{Name: "include_templates", Type: "boolean", Description: "Also list template pages"}
+	previewParam          = openapi.Param{Name: "preview", Type: "boolean", Description: "Attach a short text preview to each page"}
+	cursorParam           = openapi.Param{Name: "cursor", Description: "Opaque next_cursor from the previous response"}
+	paginationParam       = openapi.Param{Name: "pagination", Description: "Set to cursor for cursor pagination"}
+)
+
+// OpenAPIDocument builds the spec served at /api/v1/openapi.json
+func OpenAPIDocument() (*openapi.Document, error) {
+	return openapi.Build(openapi.Info{
+		Title:       "Lumen API",
+		Description: "Authentication, notes, AI and administration endpoints.",
+		Version:     "1.0.0",
+	}, APIOperations(), middleware.ErrorResponse{})
+}
+
+// APIOperations lists every documented /api/v1 route
+func APIOperations() []openapi.Operation {
+	var ops []openapi.Operation
+	ops = append(ops, authOperations()...)
+	ops = append(ops, userOperations()...)
+	ops = append(ops, waitlistOperations()...)
+	ops = append(ops, workspaceOperations()...)
+	ops = append(ops, pageOperations()...)
+	ops = append(ops, templateOperations()...)
+	ops = append(ops, aiOperations()...)
+	ops = append(ops, adminOperations()...)
+	return ops
+}
+
+func authOperations() []openapi.Operation {
+	return []openapi.Operation{
+		{ID: "register", Method: http.MethodPost, Path: apiV1 + "/register", Tag: "auth", Summary: "Create an account and sign in",
+			Request: services.RegisterRequest{}, Response: openapi.Object{}, Status: http.StatusCreated},
+		{ID: "login", Method: http.MethodPost, Path: apiV1 + "/login", Tag: "auth", Summary: "Sign in with email and password",
+			Request: services.LoginRequest{}, Response: openapi.Object{}},
+		{ID: "forgotPassword", Method: http.MethodPost, Path: apiV1 + "/auth/forgot-password", Tag: "auth", Summary: "Email password reset instructions",
+			Request: forgotPasswordRequest{}},
+		{ID: "verifyEmail", Method: http.MethodPost, Path: apiV1 + "/auth/verify-email", Tag: "auth", Summary: "Verify an email address with the emailed token",
+			Request: verifyEmailRequest{}},
+		{ID: "requestEmailVerification", Method: http.MethodPost, Path: apiV1 + "/auth/request-verification", Tag: "auth", Summary: "Email a new verification link",
+			Request: requestVerificationRequest{}},
+		{ID: "resetPassword", Method: http.MethodPost, Path: apiV1 + "/auth/reset-password", Tag: "auth", Summary: "Reset a password with a reset token",
+			Request: services.ResetPasswordRequest{}},
+		{ID: "validateToken", Method: http.MethodGet, Path: apiV1 + "/auth/validate", Tag: "auth", Summary: "Validate the current access token",
+			RawResponse: openapi.Object{}},
+		{ID: "refreshToken", Method: http.MethodPost, Path: apiV1 + "/auth/refresh", Tag: "auth", Summary: "Rotate the refresh token and issue a new access token",
+			Request: refreshTokenRequest{}, Response: openapi.Object{}},
+		{ID: "googleOAuthStart", Method: http.MethodGet, Path: apiV1 + "/auth/oauth/google", Tag: "auth", Summary: "Redirect to Google sign-in",
+			Status: http.StatusFound},
+		{ID: "googleOAuthCallback", Method: http.MethodGet, Path: apiV1 + "/auth/oauth/google/callback", Tag: "auth", Summary: "Complete Google sign-in",
+			Query:  []openapi.Param{{Name: "code"}, {Name: "state"}, {Name: "error"}},
+			Status: http.StatusFound},
+		{ID: "logout", Method: http.MethodPost, Path: apiV1 + "/auth/logout", Tag: "auth", Summary: "Sign out of the current session", Auth: true},
+		{ID: "revokeToken", Method: http.MethodPost, Path: apiV1 + "/auth/revoke", Tag: "auth", Summary: "Revoke an access or refresh token", Auth: true,
+			Request: revokeTokenRequest{}},
+		{ID: "changePasswordAuth", Method: http.MethodPost, Path: apiV1 + "/auth/change-password", Tag: "auth", Summary: "Change the password and end other sessions", Auth: true,
+			Request: services.ChangePasswordRequest{}},
+		{ID: "listSessions", Method: http.MethodGet, Path: apiV1 + "/auth/sessions", Tag: "auth", Summary: "List active sessions", Auth: true,
+			Response: []services.SessionInfo{}},
+		{ID: "revokeSession", Method: http.MethodDelete, Path: apiV1 + "/auth/sessions/:id", Tag: "auth", Summary: "Revoke a session", Auth: true},
+		{ID: "getCSRFToken", Method: http.MethodPost, Path: apiV1 + "/security/csrf-token", Tag: "auth", Summary: "Issue a CSRF token",
+			RawResponse: openapi.Object{}},
+		{ID: "cspReport", Method: http.MethodPost, Path: apiV1 + "/security/csp-report", Tag: "auth", Summary: "Receive a Content-Security-Policy violation report",
+			Request: openapi.Object{}, RawResponse: openapi.Object{}},
+	}
+}
+
+func userOperations() []openapi.Operation {
+	return []openapi.Operation{
+		{ID: "getProfile", Method: http.MethodGet, Path: apiV1 + "/profile", Tag: "users", Summary: "Get the signed-in user's profile", Auth: true,
+			Response: openapi.Object{}},
+		{ID: "updateProfile", Method: http.MethodPut, Path: apiV1 + "/profile", Tag: "users", Summary: "Update the signed-in user's profile", Auth: true,
+			Request: services.UpdateProfileRequest{}, Response: services.UserResponse{}},
+		{ID: "checkEmailVerification", Method: http.MethodGet, Path: apiV1 + "/profile/email-verification", Tag: "users", Summary: "Check whether the email address is verified", Auth: true,
+			Response: struct {
+				EmailVerified bool `json:"email_verified"`
+			}{}},
+		{ID: "requestPasswordChangeOTP", Method: http.MethodPost, Path: apiV1 + "/profile/request-password-change-otp", Tag: "users", Summary: "Email a one-time code for changing the password", Auth: true},
+		{ID: "changePassword", Method: http.MethodPost, Path: apiV1 + "/profile/change-password", Tag: "users", Summary: "Change the password with a one-time code", Auth: true,
+			Request: struct {
+				CurrentPassword string `json:"current_password" binding:"required"`
+				NewPassword     string `json:"new_password" binding:"required"`
+				OTP             string `json:"otp" binding:"required"`
+			}{}},
+		{ID: "getUser", Method: http.MethodGet, Path: apiV1 + "/users/:id", Tag: "users", Summary: "Get a user by ID", Auth: true,
+			Response: openapi.Object{}},
+	}
+}
+
+func waitlistOperations() []openapi.Operation {
+	return []openapi.Operation{
+		{ID: "joinWaitlist", Method: http.MethodPost, Path: apiV1 + "/waitlist", Tag: "waitlist", Summary: "Join the waitlist",
+			Request: services.WaitlistRequest{}, Status: http.StatusCreated},
+		{ID: "getWaitlistPosition", Method: http.MethodGet, Path: apiV1 + "/waitlist/position", Tag: "waitlist", Summary: "Get a waitlist position",
+			Query: []openapi.Param{{Name: "email", Required: true}}, Response: services.WaitlistPositionResponse{}},
+		{ID: "listWaitlist", Method: http.MethodGet, Path: apiV1 + "/waitlist", Tag: "waitlist", Summary: "List waitlist entries (admin)", Auth: true,
+			Query:    []openapi.Param{{Name: "page", Type: "integer"}, {Name: "page_size", Type: "integer"}, {Name: "status"}, {Name: "search"}},
+			Response: services.WaitlistListResponse{}},
+		{ID: "approveWaitlistEntry", Method: http.MethodPost, Path: apiV1 + "/waitlist/approve", Tag: "waitlist", Summary: "Approve a waitlist entry (admin)", Auth: true,
+			Request: struct {
+				Email string `json:"email" binding:"required,email"`
+			}{}},
+		{ID: "removeFromWaitlist", Method: http.MethodDelete, Path: apiV1 + "/waitlist/:email", Tag: "waitlist", Summary: "Remove a waitlist entry (admin)", Auth: true},
+		{ID: "updateWaitlistStatus", Method: http.MethodPut, Path: apiV1 + "/waitlist/:id", Tag: "waitlist", Summary: "Change a waitlist entry's status (admin)", Auth: true,
+			Request: struct {
+				Status string `json:"status" binding:"required,oneof=pending approved rejected"`
+			}{}},
+	}
+}
+
+func workspaceOperations() []openapi.Operation {
+	const base = apiV1 + "/notes/workspaces"
+	return []openapi.Operation{
+		{ID: "createWorkspace", Method: http.MethodPost, Path: base, Tag: "workspaces", Summary: "Create a workspace", Auth: true,
+			Request: services.CreateWorkspaceRequest{}, Response: services.WorkspaceResponse{}, Status: http.StatusCreated},
+		{ID: "listWorkspaces", Method: http.MethodGet, Path: base, Tag: "workspaces", Summary: "List the user's workspaces", Auth: true,
+			Query: []openapi.Param{fieldsParam}, Response: []services.WorkspaceResponse{}},
+		{ID: "getWorkspace", Method: http.MethodGet, Path: base + "/:workspace_id", Tag: "workspaces", Summary: "Get a workspace", Auth: true,
+			Query: []openapi.Param{fieldsParam}, Response: services.WorkspaceResponse{}},
+		{ID: "updateWorkspace", Method: http.MethodPut, Path: base + "/:workspace_id", Tag: "workspaces", Summary: "Update a workspace", Auth: true,
+			Request: services.UpdateWorkspaceRequest{}, Response: services.WorkspaceResponse{}},
+		{ID: "getPropertySchema", Method: http.MethodGet, Path: base + "/:workspace_id/property-schema", Tag: "workspaces", Summary: "Get the page property schema", Auth: true,
+			Response: services.PropertySchemaResponse{}},
+		{ID: "updatePropertySchema", Method: http.MethodPut, Path: base + "/:workspace_id/property-schema", Tag: "workspaces", Summary: "Set or remove the page property schema (admins)", Auth: true,
+			Request: services.UpdatePropertySchemaRequest{}, Response: services.PropertySchemaResponse{}},
+		{ID: "deleteWorkspace", Method: http.MethodDelete, Path: base + "/:workspace_id", Tag: "workspaces", Summary: "Delete a workspace", Auth: true},
+		{ID: "addWorkspaceMember", Method: http.MethodPost, Path: base + "/:workspace_id/members", Tag: "workspaces", Summary: "Add a member", Auth: true,
+			Request: services.AddWorkspaceMemberRequest{}, Response: services.WorkspaceMemberResponse{}, Status: http.StatusCreated},
+		{ID: "listWorkspaceMembers", Method: http.MethodGet, Path: base + "/:workspace_id/members", Tag: "workspaces", Summary: "List members", Auth: true,
+			Query: []openapi.Param{limitParam, offsetParam, fieldsParam}, Response: []services.WorkspaceMemberResponse{}},
+		{ID: "removeWorkspaceMember", Method: http.MethodDelete, Path: base + "/:workspace_id/members/:user_id", Tag: "workspaces", Summary: "Remove a member", Auth: true},
+		{ID: "updateMemberRole", Method: http.MethodPut, Path: base + "/:workspace_id/members/:user_id/role", Tag: "workspaces", Summary: "Change a member's role", Auth: true,
+			Request: updateMemberRoleRequest{}},
+		{ID: "inviteWorkspaceMember", Method: http.MethodPost, Path: base + "/:workspace_id/invitations", Tag: "workspaces", Summary: "Invite someone by email", Auth: true,
+			Request: services.InviteWorkspaceMemberRequest{}, Response: services.WorkspaceInvitationResponse{}, Status: http.StatusCreated},
+		{ID: "leaveWorkspace", Method: http.MethodPost, Path: base + "/:workspace_id/leave", Tag: "workspaces", Summary: "Leave a workspace", Auth: true},
+		{ID: "getWorkspaceActivity", Method: http.MethodGet, Path: base + "/:workspace_id/activity", Tag: "workspaces", Summary: "List the activity log (admins)", Auth: true,
+			Query: []openapi.Param{limitParam, offsetParam}, Response: []services.ActivityResponse{}},
+		{ID: "listWorkspacePages", Method: http.MethodGet, Path: base + "/:workspace_id/pages", Tag: "workspaces", Summary: "List a workspace's pages", Auth: true,
+			Query:    []openapi.Param{includeArchivedParam, includeTemplatesParam, previewParam, fieldsParam, paginationParam, cursorParam, limitParam},
+			Response: []services.PageResponse{}, NextCursor: true},
+		{ID: "listRootPages", Method: http.MethodGet, Path: base + "/:workspace_id/pages/root", Tag: "workspaces", Summary: "List top-level pages", Auth: true,
+			Query: []openapi.Param{includeArchivedParam, includeTemplatesParam, previewParam, fieldsParam}, Response: []services.PageResponse{}},
+		{ID: "listWorkspaceTemplates", Method: http.MethodGet, Path: base + "/:workspace_id/templates", Tag: "templates", Summary: "List a workspace's templates", Auth: true,
+			Query: []openapi.Param{fieldsParam}, Response: []services.PageResponse{}},
+		{ID: "queryPages", Method: http.MethodPost, Path: base + "/:workspace_id/query", Tag: "pages", Summary: "Filter and sort pages by property values", Auth: true,
+			Request: services.QueryPagesRequest{}, Response: services.QueryPagesResponse{}},
+		{ID: "getPageTree", Method: http.MethodGet, Path: base + "/:workspace_id/tree", Tag: "workspaces", Summary: "Get the page hierarchy", Auth: true,
+			Query:    []openapi.Param{{Name: "max_depth", Type: "integer"}, includeArchivedParam, includeTemplatesParam},
+			Response: []services.PageTreeNode{}},
+		{ID: "listOrphanedPages", Method: http.MethodGet, Path: base + "/:workspace_id/orphans", Tag: "workspaces", Summary: "List pages whose parent is gone (admins)", Auth: true,
+			Response: []services.PageResponse{}},
+		{ID: "repairOrphanedPages", Method: http.MethodPost, Path: base + "/:workspace_id/orphans/repair", Tag: "workspaces", Summary: "Move orphaned pages to a valid parent (admins)", Auth: true,
+			Request: services.RepairOrphanedPagesRequest{}, Response: services.RepairOrphanedPagesResponse{}},
+		{ID: "listTrash", Method: http.MethodGet, Path: base + "/:workspace_id/trash", Tag: "workspaces", Summary: "List trashed pages", Auth: true,
+			Response: []services.PageResponse{}},
+	}
+}
+
+func pageOperations() []openapi.Operation {
+	const notes = apiV1 + "/notes"
+	const base = notes + "/pages"
+	return []openapi.Operation{
+		{ID: "createPage", Method: http.MethodPost, Path: base, Tag: "pages", Summary: "Create a page", Auth: true,
+			Request: services.CreatePageRequest{}, Response: services.PageResponse{}, Status: http.StatusCreated},
+		{ID: "importMarkdown", Method: http.MethodPost, Path: base + "/import", Tag: "pages", Summary: "Create a page from Markdown", Auth: true,
+			Request: services.ImportMarkdownRequest{}, Response: services.PageResponse{}, Status: http.StatusCreated},
+		{ID: "bulkArchivePages", Method: http.MethodPost, Path: base + "/bulk-archive", Tag: "pages", Summary: "Archive several pages, optionally with their descendants", Auth: true,
+			Request: services.BulkPagesRequest{}, Response: services.BulkPagesResponse{}},
+		{ID: "bulkDeletePages", Method: http.MethodPost, Path: base + "/bulk-delete", Tag: "pages", Summary: "Move several pages and their descendants to the trash", Auth: true,
+			Request: services.BulkPagesRequest{}, Response: services.BulkPagesResponse{}},
+		{ID: "getPage", Method: http.MethodGet, Path: base + "/:page_id", Tag: "pages", Summary: "Get a page", Auth: true,
+			Query: []openapi.Param{{Name: "include_blocks", Type: "boolean"}, fieldsParam}, Response: services.PageResponse{}},
+		{ID: "updatePage", Method: http.MethodPut, Path: base + "/:page_id", Tag: "pages", Summary: "Update page metadata", Auth: true,
+			Request: services.UpdatePageRequest{}, Response: services.PageResponse{}},
+		{ID: "savePageContent", Method: http.MethodPost, Path: base + "/:page_id/content", Tag: "pages", Summary: "Replace a page's blocks", Auth: true,
+			Request: services.SavePageContentRequest{}, Response: services.PageResponse{}},
+		{ID: "reorderBlocks", Method: http.MethodPatch, Path: base + "/:page_id/blocks/reorder", Tag: "pages", Summary: "Reorder blocks", Auth: true,
+			Request: services.ReorderBlocksRequest{}, Response: []services.BlockResponse{}},
+		{ID: "deletePage", Method: http.MethodDelete, Path: base + "/:page_id", Tag: "pages", Summary: "Move a page to the trash", Auth: true},
+		{ID: "archivePage", Method: http.MethodPost, Path: base + "/:page_id/archive", Tag: "pages", Summary: "Archive a page and, by default, its descendants", Auth: true,
+			Request: services.ArchivePageRequest{}},
+		{ID: "restorePage", Method: http.MethodPost, Path: base + "/:page_id/restore", Tag: "pages", Summary: "Unarchive a page and, by default, the descendants archived with it", Auth: true,
+			Request: services.RestorePageRequest{}},
+		{ID: "restorePageFromTrash", Method: http.MethodPost, Path: base + "/:page_id/restore-from-trash", Tag: "pages", Summary: "Restore a trashed page", Auth: true},
+		{ID: "purgePage", Method: http.MethodPost, Path: base + "/:page_id/purge", Tag: "pages", Summary: "Permanently delete a trashed page", Auth: true},
+		{ID: "movePage", Method: http.MethodPost, Path: base + "/:page_id/move", Tag: "pages", Summary: "Move a page under a new parent", Auth: true,
+			Request: services.MovePageRequest{}, Response: services.PageResponse{}},
+		{ID: "duplicatePage", Method: http.MethodPost, Path: base + "/:page_id/duplicate", Tag: "pages", Summary: "Duplicate a page", Auth: true,
+			Request: services.DuplicatePageRequest{}, Response: services.PageResponse{}, Status: http.StatusCreated},
+		{ID: "addFavorite", Method: http.MethodPost, Path: base + "/:page_id/favorite", Tag: "pages", Summary: "Favorite a page", Auth: true},
+		{ID: "removeFavorite", Method: http.MethodDelete, Path: base + "/:page_id/favorite", Tag: "pages", Summary: "Unfavorite a page", Auth: true},
+		{ID: "listChildPages", Method: http.MethodGet, Path: base + "/:page_id/children", Tag: "pages", Summary: "List child pages", Auth: true,
+			Query: []openapi.Param{includeArchivedParam, includeTemplatesParam, previewParam, fieldsParam}, Response: []services.PageResponse{}},
+		{ID: "listPageAncestors", Method: http.MethodGet, Path: base + "/:page_id/ancestors", Tag: "pages", Summary: "List a page's ancestors, root first", Auth: true,
+			Response: []services.PageResponse{}},
+		{ID: "exportPage", Method: http.MethodGet, Path: base + "/:page_id/export", Tag: "pages", Summary: "Export a page as Markdown", Auth: true,
+			Query: []openapi.Param{{Name: "format", Description: "Only markdown is supported"}}, RawResponse: "", ContentType: "text/markdown"},
+		{ID: "grantPagePermission", Method: http.MethodPost, Path: base + "/:page_id/permissions", Tag: "pages", Summary: "Grant a user access to a page", Auth: true,
+			Request: services.GrantPagePermissionRequest{}, Response: services.PagePermissionResponse{}, Status: http.StatusCreated},
+		{ID: "listPagePermissions", Method: http.MethodGet, Path: base + "/:page_id/permissions", Tag: "pages", Summary: "List explicit page permissions", Auth: true,
+			Query: []openapi.Param{limitParam, offsetParam}, Response: []services.PagePermissionResponse{}},
+		{ID: "revokePagePermission", Method: http.MethodDelete, Path: base + "/:page_id/permissions/:user_id", Tag: "pages", Summary: "Revoke a user's page permission", Auth: true},
+		{ID: "getPageAccess", Method: http.MethodGet, Path: base + "/:page_id/access", Tag: "pages", Summary: "Explain who can access a page and why", Auth: true,
+			Response: []services.EffectiveAccessResponse{}},
+		{ID: "listPageVersions", Method: http.MethodGet, Path: base + "/:page_id/versions", Tag: "pages", Summary: "List page versions", Auth: true,
+			Query: []openapi.Param{limitParam, offsetParam}, Response: []services.PageVersionResponse{}},
+		{ID: "getPageVersion", Method: http.MethodGet, Path: base + "/:page_id/versions/:version_number", Tag: "pages", Summary: "Get a page version", Auth: true,
+			Response: services.PageVersionResponse{}},
+		{ID: "createViewerToken", Method: http.MethodPost, Path: base + "/:page_id/viewer-tokens", Tag: "pages", Summary: "Create a read-only embed token", Auth: true,
+			Request: services.CreateViewerTokenRequest{}, Response: services.ViewerTokenResponse{}, Status: http.StatusCreated},
+		{ID: "getEmbeddedPage", Method: http.MethodGet, Path: apiV1 + "/embed/pages/:page_id", Tag: "pages", Summary: "Get a page with a viewer token",
+			Query: []openapi.Param{{Name: "token", Description: "Viewer token", Required: true}}, Response: services.PageResponse{}},
+		{ID: "createShareLink", Method: http.MethodPost, Path: base + "/:page_id/share-links", Tag: "pages", Summary: "Create a public read-only link", Auth: true,
+			Request: services.ShareLinkRequest{}, Response: services.ShareLinkResponse{}, Status: http.StatusCreated},
+		{ID: "listShareLinks", Method: http.MethodGet, Path: base + "/:page_id/share-links", Tag: "pages", Summary: "List a page's active share links", Auth: true,
+			Response: []services.ShareLinkResponse{}},
+		{ID: "revokeShareLink", Method: http.MethodDelete, Path: base + "/:page_id/share-links/:link_id", Tag: "pages", Summary: "Revoke a share link", Auth: true},
+		{ID: "getSharedPage", Method: http.MethodGet, Path: apiV1 + "/public/shared/:token", Tag: "pages", Summary: "Get a shared page; protected links take the X-Share-Password header",
+			Response: services.SharedPageResponse{}},
+		{ID: "createComment", Method: http.MethodPost, Path: base + "/:page_id/comments", Tag: "comments", Summary: "Comment on a page or block", Auth: true,
+			Request: services.CreateCommentRequest{}, Response: services.CommentResponse{}, Status: http.StatusCreated},
+		{ID: "listPageComments", Method: http.MethodGet, Path: base + "/:page_id/comments", Tag: "comments", Summary: "List a page's comments", Auth: true,
+			Query: []openapi.Param{{Name: "block_id", Description: "Only comments on this block"}}, Response: []services.CommentResponse{}},
+		{ID: "updateComment", Method: http.MethodPut, Path: base + "/:page_id/comments/:comment_id", Tag: "comments", Summary: "Edit a comment", Auth: true,
+			Request: services.UpdateCommentRequest{}, Response: services.CommentResponse{}},
+		{ID: "deleteComment", Method: http.MethodDelete, Path: base + "/:page_id/comments/:comment_id", Tag: "comments", Summary: "Delete a comment", Auth: true},
+		{ID: "replyToComment", Method: http.MethodPost, Path: base + "/:page_id/comments/:comment_id/replies", Tag: "comments", Summary: "Reply to a comment", Auth: true,
+			Request: services.UpdateCommentRequest{}, Response: services.CommentResponse{}, Status: http.StatusCreated},
+		{ID: "resolveComment", Method: http.MethodPost, Path: base + "/:page_id/comments/:comment_id/resolve", Tag: "comments", Summary: "Resolve a comment thread", Auth: true,
+			Response: services.CommentResponse{}},
+		{ID: "unresolveComment", Method: http.MethodPost, Path: base + "/:page_id/comments/:comment_id/unresolve", Tag: "comments", Summary: "Reopen a comment thread", Auth: true,
+			Response: services.CommentResponse{}},
+		{ID: "searchPages", Method: http.MethodPost, Path: notes + "/search", Tag: "pages", Summary: "Search pages", Auth: true,
+			Request: services.SearchPagesRequest{}, Response: services.SearchPagesResponse{}},
+		{ID: "listRecentPages", Method: http.MethodGet, Path: notes + "/recent", Tag: "pages", Summary: "List recently updated pages", Auth: true,
+			Query: []openapi.Param{limitParam, fieldsParam, paginationParam, cursorParam}, Response: []services.PageResponse{}, NextCursor: true},
+		{ID: "listFavoritePages", Method: http.MethodGet, Path: notes + "/favorites", Tag: "pages", Summary: "List favorite pages", Auth: true,
+			Query: []openapi.Param{fieldsParam}, Response: []services.PageResponse{}},
+		{ID: "listNotifications", Method: http.MethodGet, Path: notes + "/notifications", Tag: "notifications", Summary: "List notifications", Auth: true,
+			Query: []openapi.Param{{Name: "unread", Type: "boolean"}, limitParam, offsetParam}, Response: []services.NotificationResponse{}},
+		{ID: "markNotificationRead", Method: http.MethodPost, Path: notes + "/notifications/:id/read", Tag: "notifications", Summary: "Mark a notification as read", Auth: true},
+		{ID: "getNotificationPreferences", Method: http.MethodGet, Path: notes + "/notifications/preferences", Tag: "notifications", Summary: "Get notification email preferences", Auth: true,
+			Response: services.NotificationPreferencesResponse{}},
+		{ID: "updateNotificationPreferences", Method: http.MethodPut, Path: notes + "/notifications/preferences", Tag: "notifications", Summary: "Update notification email preferences", Auth: true,
+			Request: services.UpdateNotificationPreferencesRequest{}, Response: services.NotificationPreferencesResponse{}},
+		{ID: "acceptWorkspaceInvitation", Method: http.MethodPost, Path: notes + "/invitations/accept", Tag: "workspaces", Summary: "Accept a workspace invitation", Auth: true,
+			Request: services.AcceptWorkspaceInvitationRequest{}, Response: services.WorkspaceResponse{}},
+		{ID: "validateContent", Method: http.MethodPost, Path: notes + "/validate-content", Tag: "pages", Summary: "Validate editor content without saving", Auth: true,
+			Request: services.SavePageContentRequest{}, Response: services.ValidateContentResponse{}},
+	}
+}
+
+func templateOperations() []openapi.Operation {
+	const base = apiV1 + "/notes/templates"
+	return []openapi.Operation{
+		{ID: "instantiateTemplate", Method: http.MethodPost, Path: base + "/:page_id/instantiate", Tag: "templates", Summary: "Create a page from a template", Auth: true,
+			Request: services.InstantiateTemplateRequest{}, Response: services.PageResponse{}, Status: http.StatusCreated},
+	}
+}
+
+func aiOperations() []openapi.Operation {
+	const base = apiV1 + "/ai"
+	return []openapi.Operation{
+		{ID: "generateNoteContent", Method: http.MethodPost, Path: base + "/generate", Tag: "ai", Summary: "Generate note content", Auth: true,
+			Request: services.AISpec{}, Response: services.AIResponse{}},
+		{ID: "saveChatExchange", Method: http.MethodPost, Path: base + "/chat/exchange", Tag: "ai", Summary: "Save a chat exchange", Auth: true,
+			Request: saveExchangeRequest{}, RawResponse: struct {
+				OK             bool   `json:"ok"`
+				ConversationID string `json:"conversation_id"`
+			}{}},
+		{ID: "streamChat", Method: http.MethodPost, Path: base + "/chat/stream", Tag: "ai", Summary: "Stream a chat answer as server-sent events", Auth: true,
+			Request: streamChatRequest{}, RawResponse: "", ContentType: "text/event-stream"},
+		{ID: "getChatHistory", Method: http.MethodGet, Path: base + "/chat/history", Tag: "ai", Summary: "Get chat history", Auth: true,
+			Query:    []openapi.Param{{Name: "type", Required: true}, {Name: "page_id"}, limitParam, offsetParam},
+			Response: []repository.AIMessage{}},
+		{ID: "clearConversations", Method: http.MethodDelete, Path: base + "/chat/conversations", Tag: "ai", Summary: "Delete all conversations", Auth: true,
+			RawResponse: struct {
+				OK      bool  `json:"ok"`
+				Deleted int64 `json:"deleted"`
+			}{}},
+		{ID: "listConversations", Method: http.MethodGet, Path: base + "/conversations", Tag: "ai", Summary: "List conversations", Auth: true,
+			Query: []openapi.Param{limitParam, offsetParam}, Response: []*repository.AIConversationSummary{}},
+		{ID: "deleteConversation", Method: http.MethodDelete, Path: base + "/conversations/:id", Tag: "ai", Summary: "Delete a conversation", Auth: true,
+			RawResponse: struct {
+				OK bool `json:"ok"`
+			}{}},
+		{ID: "renameConversation", Method: http.MethodPatch, Path: base + "/conversations/:id/title", Tag: "ai", Summary: "Rename a conversation", Auth: true,
+			Request: renameConversationRequest{}, Response: repository.AIConversation{}},
+		{ID: "getAIUsage", Method: http.MethodGet, Path: base + "/usage", Tag: "ai", Summary: "Get this month's token usage", Auth: true,
+			Response: services.AIUsageResponse{}},
+	}
+}
+
+func adminOperations() []openapi.Operation {
+	const base = apiV1 + "/admin"
+	return []openapi.Operation{
+		{ID: "getMaintenanceStatus", Method: http.MethodGet, Path: apiV1 + "/system/maintenance", Tag: "system", Summary: "Get maintenance mode status",
+			RawResponse: struct {
+				MaintenanceMode bool `json:"maintenance_mode"`
+			}{}},
+		{ID: "getRegistrationStatus", Method: http.MethodGet, Path: apiV1 + "/system/registration", Tag: "system", Summary: "Get whether registration is open",
+			RawResponse: struct {
+				RegistrationEnabled bool `json:"registration_enabled"`
+			}{}},
+		{ID: "listSettings", Method: http.MethodGet, Path: base + "/settings", Tag: "admin", Summary: "List system settings", Auth: true,
+			RawResponse: struct {
+				Settings []*services.SettingResponse `json:"settings"`
+			}{}},
+		{ID: "getSetting", Method: http.MethodGet, Path: base + "/settings/:key", Tag: "admin", Summary: "Get a system setting", Auth: true,
+			Response: services.SettingResponse{}},
+		{ID: "updateSetting", Method: http.MethodPut, Path: base + "/settings/:key", Tag: "admin", Summary: "Update a system setting", Auth: true,
+			Request: UpdateSystemSettingRequest{}, RawResponse: openapi.Object{}},
+		{ID: "enableMaintenanceMode", Method: http.MethodPost, Path: base + "/system/maintenance/enable", Tag: "admin", Summary: "Enable maintenance mode", Auth: true,
+			Request: struct {
+				Message string `json:"message"`
+			}{}},
+		{ID: "disableMaintenanceMode", Method: http.MethodPost, Path: base + "/system/maintenance/disable", Tag: "admin", Summary: "Disable maintenance mode", Auth: true},
+		{ID: "toggleRegistration", Method: http.MethodPut, Path: base + "/system/registration/toggle", Tag: "admin", Summary: "Open or close registration", Auth: true,
+			Request: ToggleRegistrationStatusRequest{}, RawResponse: openapi.Object{}},
+		{ID: "listUsersByRole", Method: http.MethodGet, Path: base + "/users/role/:role", Tag: "admin", Summary: "List users with a role", Auth: true,
+			Response: []services.UserResponse{}},
+		{ID: "sendTestEmail", Method: http.MethodPost, Path: base + "/email/test", Tag: "admin", Summary: "Send a test email", Auth: true,
+			Request: TestEmailRequest{}},
+	}
+}
@@ -1,13 +1,15 @@
 package handlers
 
 import (
-	"context"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/Srivathsav-max/lumen/backend/internal/authz"
 	"github.com/Srivathsav-max/lumen/backend/internal/constants"
 	"github.com/Srivathsav-max/lumen/backend/internal/errors"
 	"github.com/Srivathsav-max/lumen/backend/internal/security"
@@ -19,6 +21,7 @@ import (
 type AuthHandlers struct {
 	authService    services.AuthService
 	userService    services.UserService
+	oauthService   services.OAuthService
 	jwtService     *security.JWTService
 	csrfService    *security.CSRFService
 	xssService     *security.XSSService
@@ -26,7 +29,7 @@ type AuthHandlers struct {
 	logger         *slog.Logger
 }
 
-func NewAuthHandlers(authService services.AuthService, userService services.UserService, securityConfig *security.SecurityConfig, logger *slog.Logger) *AuthHandlers {
+func NewAuthHandlers(authService services.AuthService, userService services.UserService, oauthService services.OAuthService, securityConfig *security.SecurityConfig, logger *slog.Logger) *AuthHandlers {
 	jwtService := security.NewJWTService(&securityConfig.JWT, logger)
 	csrfService := security.NewCSRFService(&securityConfig.CSRF, logger)
 	xssService := security.NewXSSService(security.DefaultXSSConfig(), logger)
@@ -34,6 +37,7 @@ func NewAuthHandlers(authService services.AuthService, userService services.User
 	return &AuthHandlers{
 		authService:    authService,
 		userService:    userService,
+		oauthService:   oauthService,
 		jwtService:     jwtService,
 		csrfService:    csrfService,
 		xssService:     xssService,
@@ -49,21 +53,21 @@ func (h *AuthHandlers) Register(c *gin.Context) {
 		return
 	}
 
-	req.Email = h.xssService.SanitizeInput(req.Email).Sanitized
-	req.Username = h.xssService.SanitizeInput(req.Username).Sanitized
-	req.FirstName = h.xssService.SanitizeInput(req.FirstName).Sanitized
-	req.LastName = h.xssService.SanitizeInput(req.LastName).Sanitized
-
-	if threats := h.validateRegistrationInput(&req); len(threats) > 0 {
-		h.logger.Warn("Registration attempt with suspicious input",
-			"threats", threats,
-			"ip", c.ClientIP(),
-		)
-		c.Error(errors.NewValidationError("Invalid input detected", "Please check your input and try again"))
+	// Fields are checked, never rewritten: a name like "Smith & Sons" is
+	// stored as typed and escaped wherever it is rendered
+	if violations := h.validateRegistrationInput(&req); len(violations) > 0 {
+		for _, violation := range violations {
+			h.logger.Warn("Registration attempt with suspicious input",
+				"field", violation.Field,
+				"threats", violation.Threats,
+				"ip", c.ClientIP(),
+			)
+		}
+		c.Error(errors.NewValidationError("Invalid input detected", "").WithDetails(violations))
 		return
 	}
 
-	ctx := context.Background()
+	ctx := services.WithDeviceInfo(c.Request.Context(), c.Request.UserAgent(), c.ClientIP())
 
 	userResponse, err := h.userService.Register(ctx, &req)
 	if err != nil {
@@ -89,7 +93,7 @@ func (h *AuthHandlers) Register(c *gin.Context) {
 		return
 	}
 
-	regClaims, err := h.jwtService.ValidateToken(tokenPair.AccessToken, c.Request)
+	regClaims, err := h.jwtService.ParseIssuedToken(tokenPair.AccessToken)
 	if err != nil {
 		h.logger.Error("Failed to parse generated token", "error", err)
 		c.Error(errors.NewInternalError("Registration failed"))
@@ -106,7 +110,7 @@ func (h *AuthHandlers) Register(c *gin.Context) {
 			h.logger.Debug("CSRF protection is disabled, skipping CSRF token generation", "user_id", userResponse.ID)
 			csrfTokenValue = constants.DevCSRFTokenDisabled
 			csrfEnabled = false
-			h.setRefreshTokenCookies(c, tokenPair.AccessToken, tokenPair.RefreshToken)
+			h.setRefreshTokenCookies(c, tokenPair)
 		} else {
 			h.logger.Error("Failed to generate CSRF token", "error", err, "user_id", userResponse.ID)
 			c.Error(errors.NewInternalError("Registration failed"))
@@ -114,7 +118,7 @@ func (h *AuthHandlers) Register(c *gin.Context) {
 		}
 	} else {
 		csrfTokenValue = csrfToken.Token
-		h.setRefreshTokenCookiesWithCSRF(c, tokenPair.AccessToken, tokenPair.RefreshToken, csrfToken)
+		h.setRefreshTokenCookiesWithCSRF(c, tokenPair, csrfToken)
 	}
 
 	secureAuthResponse := gin.H{
@@ -125,7 +129,7 @@ func (h *AuthHandlers) Register(c *gin.Context) {
 			"first_name": userResponse.FirstName,
 			"last_name":  userResponse.LastName,
 			"roles":      regClaims.Roles,
-			"is_admin":   contains(regClaims.Roles, constants.RoleAdmin),
+			"is_admin":   authz.RolesGrant(regClaims.Roles, constants.RoleAdmin),
 			"created_at": userResponse.CreatedAt,
 		},
 		"expires_in":   tokenPair.ExpiresIn,
@@ -153,9 +157,12 @@ func (h *AuthHandlers) Login(c *gin.Context) {
 		return
 	}
 
-	req.Email = h.xssService.SanitizeInput(req.Email).Sanitized
+	if violation := h.xssService.CheckField("email", req.Email); violation != nil {
+		c.Error(errors.NewValidationError("Invalid input detected", "").WithDetails([]security.FieldViolation{*violation}))
+		return
+	}
 
-	ctx := context.Background()
+	ctx := services.WithDeviceInfo(c.Request.Context(), c.Request.UserAgent(), c.ClientIP())
 
 	authResponse, err := h.userService.Login(ctx, &req)
 	if err != nil {
@@ -201,7 +208,7 @@ func (h *AuthHandlers) Login(c *gin.Context) {
 			h.logger.Debug("CSRF protection is disabled, skipping CSRF token generation", "user_id", authResponse.User.ID)
 			csrfTokenValue = constants.DevCSRFTokenDisabled
 			csrfEnabled = false
-			h.setRefreshTokenCookies(c, tokenPair.AccessToken, tokenPair.RefreshToken)
+			h.setRefreshTokenCookies(c, tokenPair)
 		} else {
 			h.logger.Error("Failed to generate CSRF token", "error", err, "user_id", authResponse.User.ID)
 			c.Error(errors.NewInternalError("Authentication failed"))
@@ -209,7 +216,7 @@ func (h *AuthHandlers) Login(c *gin.Context) {
 		}
 	} else {
 		csrfTokenValue = csrfToken.Token
-		h.setRefreshTokenCookiesWithCSRF(c, tokenPair.AccessToken, tokenPair.RefreshToken, csrfToken)
+		h.setRefreshTokenCookiesWithCSRF(c, tokenPair, csrfToken)
 	}
 
 	secureAuthResponse := gin.H{
@@ -220,7 +227,7 @@ func (h *AuthHandlers) Login(c *gin.Context) {
 			"first_name": authResponse.User.FirstName,
 			"last_name":  authResponse.User.LastName,
 			"roles":      userRoles,
-			"is_admin":   contains(userRoles, constants.RoleAdmin),
+			"is_admin":   authz.RolesGrant(userRoles, constants.RoleAdmin),
 			"created_at": authResponse.User.CreatedAt,
 		},
 		"expires_in":   tokenPair.ExpiresIn,
@@ -241,6 +248,105 @@ func (h *AuthHandlers) Login(c *gin.Context) {
 	})
 }
 
+// GoogleOAuthStart redirects the browser to Google's consent screen
+func (h *AuthHandlers) GoogleOAuthStart(c *gin.Context) {
+	if !h.oauthService.GoogleEnabled() {
+		c.Error(errors.NewNotFoundError("Google login"))
+		return
+	}
+
+	state, err := services.NewOAuthState()
+	if err != nil {
+		h.logger.Error("Failed to generate OAuth state", "error", err)
+		c.Error(errors.NewInternalError("Google sign-in failed"))
+		return
+	}
+
+	isSecure := h.securityConfig.Session.SecureCookie && (c.Request.Header.Get("X-Forwarded-Proto") == "https" || c.Request.TLS != nil)
+	c.SetCookie(constants.OAuthStateCookieName, state, int((10 * time.Minute).Seconds()), "/", "", isSecure, true)
+
+	c.Redirect(http.StatusFound, h.oauthService.GoogleAuthURL(state))
+}
+
+// GoogleOAuthCallback finishes the Google login, sets the usual auth cookies
+// and sends the browser back to the frontend
+func (h *AuthHandlers) GoogleOAuthCallback(c *gin.Context) {
+	isSecure := h.securityConfig.Session.SecureCookie && (c.Request.Header.Get("X-Forwarded-Proto") == "https" || c.Request.TLS != nil)
+
+	expectedState, _ := c.Cookie(constants.OAuthStateCookieName)
+	c.SetCookie(constants.OAuthStateCookieName, "", -1, "/", "", isSecure, true)
+
+	if providerErr := c.Query("error"); providerErr != "" {
+		h.logger.Warn("Google sign-in was not completed", "error", providerErr, "ip", c.ClientIP())
+		h.redirectOAuthFailure(c)
+		return
+	}
+
+	if expectedState == "" || c.Query("state") != expectedState {
+		h.logger.Warn("OAuth state mismatch", "ip", c.ClientIP())
+		h.redirectOAuthFailure(c)
+		return
+	}
+
+	ctx := services.WithDeviceInfo(c.Request.Context(), c.Request.UserAgent(), c.ClientIP())
+
+	user, err := h.oauthService.LoginWithGoogle(ctx, c.Query("code"))
+	if err != nil {
+		h.logger.Warn("Google sign-in failed", "error", err, "ip", c.ClientIP())
+		h.redirectOAuthFailure(c)
+		return
+	}
+
+	tokenPair, err := h.authService.GenerateTokenPair(ctx, user.ID)
+	if err != nil {
+		h.logger.Error("Failed to generate token pair", "error", err, "user_id", user.ID)
+		h.redirectOAuthFailure(c)
+		return
+	}
+
+	claims, err := h.jwtService.ParseIssuedToken(tokenPair.AccessToken)
+	var sessionID string
+	if err == nil {
+		sessionID = claims.SessionID
+	} else {
+		sessionID = fmt.Sprintf("session_%d_%d", user.ID, time.Now().Unix())
+	}
+
+	csrfToken, err := h.csrfService.GenerateToken(sessionID, user.ID, c.Request)
+	if err != nil {
+		if err.Error() != constants.ErrMsgCSRFDisabled {
+			h.logger.Error("Failed to generate CSRF token", "error", err, "user_id", user.ID)
+			h.redirectOAuthFailure(c)
+			return
+		}
+		h.setRefreshTokenCookies(c, tokenPair)
+	} else {
+		h.setRefreshTokenCookiesWithCSRF(c, tokenPair, csrfToken)
+	}
+
+	h.logger.Info("User logged in with Google",
+		"user_id", user.ID,
+		"session_id", sessionID,
+		"ip", c.ClientIP(),
+	)
+
+	c.Redirect(http.StatusFound, h.oauthService.SuccessRedirectURL())
+}
+
+// redirectOAuthFailure sends the browser back to the frontend with an error flag
+func (h *AuthHandlers) redirectOAuthFailure(c *gin.Context) {
+	target, err := url.Parse(h.oauthService.SuccessRedirectURL())
+	if err != nil {
+		target = &url.URL{Path: "/"}
+	}
+
+	query := target.Query()
+	query.Set("error", "oauth_failed")
+	target.RawQuery = query.Encode()
+
+	c.Redirect(http.StatusFound, target.String())
+}
+
 func (h *AuthHandlers) ValidateToken(c *gin.Context) {
 	token := h.extractToken(c)
 	if token == "" {
@@ -248,7 +354,7 @@ func (h *AuthHandlers) ValidateToken(c *gin.Context) {
 		return
 	}
 
-	ctx := context.Background()
+	ctx := c.Request.Context()
 
 	claims, err := h.jwtService.ValidateToken(token, c.Request)
 	if err != nil {
@@ -304,7 +410,7 @@ func (h *AuthHandlers) ValidateToken(c *gin.Context) {
 				"first_name": user.FirstName,
 				"last_name":  user.LastName,
 				"roles":      currentRoles,
-				"is_admin":   contains(currentRoles, "admin"),
+				"is_admin":   authz.RolesGrant(currentRoles, constants.RoleAdmin),
 			},
 			"session_id": claims.SessionID,
 			"expires_at": time.Now().Add(time.Duration(h.securityConfig.JWT.AccessTokenDuration)).Unix(),
@@ -321,12 +427,16 @@ func (h *AuthHandlers) ValidateToken(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// refreshTokenRequest is the body fallback for clients that cannot send the
+// refresh token cookie
+type refreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
 func (h *AuthHandlers) RefreshToken(c *gin.Context) {
 	refreshToken, err := c.Cookie(constants.RefreshTokenCookieName)
 	if err != nil {
-		var req struct {
-			RefreshToken string `json:"refresh_token"`
-		}
+		var req refreshTokenRequest
 		if bindErr := c.ShouldBindJSON(&req); bindErr == nil && req.RefreshToken != "" {
 			refreshToken = req.RefreshToken
 		}
@@ -338,7 +448,7 @@ func (h *AuthHandlers) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	ctx := context.Background()
+	ctx := services.WithDeviceInfo(c.Request.Context(), c.Request.UserAgent(), c.ClientIP())
 
 	tokenPair, err := h.authService.RefreshTokens(ctx, refreshToken)
 	if err != nil {
@@ -351,7 +461,7 @@ func (h *AuthHandlers) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	claims, err := h.jwtService.ValidateToken(tokenPair.AccessToken, c.Request)
+	claims, err := h.jwtService.ParseIssuedToken(tokenPair.AccessToken)
 	if err != nil {
 		h.logger.Error("Failed to parse new access token", "error", err)
 		c.Error(errors.NewInternalError("Token refresh failed"))
@@ -367,7 +477,7 @@ func (h *AuthHandlers) RefreshToken(c *gin.Context) {
 			h.logger.Debug("CSRF protection is disabled during token refresh", "user_id", claims.UserID)
 			csrfTokenValue = constants.DevCSRFTokenDisabled
 			csrfEnabled = false
-			h.setRefreshTokenCookies(c, tokenPair.AccessToken, tokenPair.RefreshToken)
+			h.setRefreshTokenCookies(c, tokenPair)
 		} else {
 			h.logger.Error("Failed to generate CSRF token during refresh",
 				"error", err,
@@ -378,7 +488,7 @@ func (h *AuthHandlers) RefreshToken(c *gin.Context) {
 		}
 	} else {
 		csrfTokenValue = csrfToken.Token
-		h.setRefreshTokenCookiesWithCSRF(c, tokenPair.AccessToken, tokenPair.RefreshToken, csrfToken)
+		h.setRefreshTokenCookiesWithCSRF(c, tokenPair, csrfToken)
 	}
 
 	h.logger.Info("Token refreshed successfully",
@@ -398,10 +508,12 @@ func (h *AuthHandlers) RefreshToken(c *gin.Context) {
 	})
 }
 
+type revokeTokenRequest struct {
+	Token string `json:"token"`
+}
+
 func (h *AuthHandlers) RevokeToken(c *gin.Context) {
-	var req struct {
-		Token string `json:"token"`
-	}
+	var req revokeTokenRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		req.Token = h.extractToken(c)
@@ -411,7 +523,7 @@ func (h *AuthHandlers) RevokeToken(c *gin.Context) {
 		}
 	}
 
-	ctx := context.Background()
+	ctx := c.Request.Context()
 
 	if err := h.authService.RevokeToken(ctx, req.Token); err != nil {
 		c.Error(err)
@@ -434,7 +546,7 @@ func (h *AuthHandlers) Logout(c *gin.Context) {
 
 	sessionID, _ := c.Get("session_id")
 
-	ctx := context.Background()
+	ctx := c.Request.Context()
 
 	if err := h.authService.InvalidateAllSessions(ctx, userID.(int64)); err != nil {
 		h.logger.Error("Failed to invalidate user sessions during logout",
@@ -469,7 +581,7 @@ func (h *AuthHandlers) ChangePassword(c *gin.Context) {
 		return
 	}
 
-	ctx := context.Background()
+	ctx := c.Request.Context()
 
 	if err := h.authService.ChangePassword(ctx, userID.(int64), &req); err != nil {
 		c.Error(err)
@@ -481,17 +593,91 @@ func (h *AuthHandlers) ChangePassword(c *gin.Context) {
 	})
 }
 
-func (h *AuthHandlers) InitiatePasswordReset(c *gin.Context) {
-	var req struct {
-		Email string `json:"email" binding:"required,email"`
+func (h *AuthHandlers) ListSessions(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.Error(errors.NewAuthenticationError("User not authenticated"))
+		return
+	}
+
+	// The refresh token cookie identifies which session is making the request
+	currentRefreshToken, _ := c.Cookie(constants.RefreshTokenCookieName)
+
+	ctx := c.Request.Context()
+
+	sessions, err := h.authService.ListSessions(ctx, userID.(int64), currentRefreshToken)
+	if err != nil {
+		c.Error(err)
+		return
 	}
 
+	c.JSON(http.StatusOK, gin.H{
+		"data": sessions,
+	})
+}
+
+func (h *AuthHandlers) RevokeSession(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.Error(errors.NewAuthenticationError("User not authenticated"))
+		return
+	}
+
+	sessionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(errors.NewValidationError("Invalid session ID", ""))
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	currentRefreshToken, _ := c.Cookie(constants.RefreshTokenCookieName)
+	isCurrent := false
+	if currentRefreshToken != "" {
+		sessions, err := h.authService.ListSessions(ctx, userID.(int64), currentRefreshToken)
+		if err == nil {
+			for _, session := range sessions {
+				if session.ID == sessionID && session.Current {
+					isCurrent = true
+					break
+				}
+			}
+		}
+	}
+
+	if err := h.authService.RevokeSession(ctx, userID.(int64), sessionID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if isCurrent {
+		h.clearSecureAuthCookies(c)
+	}
+
+	h.logger.Info("Session revoked",
+		"user_id", userID,
+		"session_id", sessionID,
+		"ip", c.ClientIP(),
+	)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Session revoked successfully",
+	})
+}
+
+type forgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+func (h *AuthHandlers) InitiatePasswordReset(c *gin.Context) {
+	var req forgotPasswordRequest
+
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.Error(errors.NewValidationError("Invalid request format", err.Error()))
 		return
 	}
 
-	ctx := context.Background()
+	ctx := c.Request.Context()
 
 	if err := h.authService.InitiatePasswordReset(ctx, req.Email); err != nil {
 		c.Error(err)
@@ -510,7 +696,7 @@ func (h *AuthHandlers) ResetPassword(c *gin.Context) {
 		return
 	}
 
-	ctx := context.Background()
+	ctx := c.Request.Context()
 
 	if err := h.authService.ResetPassword(ctx, &req); err != nil {
 		c.Error(err)
@@ -522,6 +708,53 @@ func (h *AuthHandlers) ResetPassword(c *gin.Context) {
 	})
 }
 
+type verifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+func (h *AuthHandlers) VerifyEmail(c *gin.Context) {
+	var req verifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.NewValidationError("Invalid request format", err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	if err := h.userService.VerifyEmail(ctx, req.Token); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": constants.MsgEmailVerified,
+	})
+}
+
+type requestVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+func (h *AuthHandlers) RequestEmailVerification(c *gin.Context) {
+	var req requestVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.NewValidationError("Invalid request format", err.Error()))
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	if err := h.userService.RequestEmailVerification(ctx, req.Email); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "If the account exists and is unverified, a verification email has been sent",
+	})
+}
+
 func (h *AuthHandlers) extractToken(c *gin.Context) string {
 	if token, err := c.Cookie(constants.AccessTokenCookieName); err == nil && token != "" {
 		return token
@@ -549,60 +782,31 @@ func (h *AuthHandlers) extractToken(c *gin.Context) string {
 	return parts[1]
 }
 
-func (h *AuthHandlers) validateRegistrationInput(req *services.RegisterRequest) []string {
-	var threats []string
-
-	if result := h.xssService.SanitizeInput(req.Email); len(result.Threats) > 0 {
-		threats = append(threats, result.Threats...)
-	}
-
-	if result := h.xssService.SanitizeInput(req.Username); len(result.Threats) > 0 {
-		threats = append(threats, result.Threats...)
-	}
-
-	if result := h.xssService.SanitizeInput(req.FirstName); len(result.Threats) > 0 {
-		threats = append(threats, result.Threats...)
-	}
-
-	if result := h.xssService.SanitizeInput(req.LastName); len(result.Threats) > 0 {
-		threats = append(threats, result.Threats...)
-	}
-
-	return threats
-}
-
-func (h *AuthHandlers) GetCSRFToken(c *gin.Context) {
-	sessionID, err := c.Cookie("session_id")
-	if err != nil || sessionID == "" {
-		sessionID = fmt.Sprintf("temp_%d", time.Now().UnixNano())
-	}
-
-	csrfToken, err := h.csrfService.GenerateToken(sessionID, 0, c.Request)
-	if err != nil {
-		if err.Error() == constants.ErrMsgCSRFDisabled {
-			c.JSON(http.StatusOK, gin.H{
-				"csrf_token":   constants.DevCSRFTokenDisabled,
-				"expires_at":   nil,
-				"csrf_enabled": false,
-			})
-			return
+// validateRegistrationInput applies each field's XSS policy without
+// changing any values
+func (h *AuthHandlers) validateRegistrationInput(req *services.RegisterRequest) []security.FieldViolation {
+	fields := []struct {
+		name  string
+		value string
+	}{
+		{"email", req.Email},
+		{"username", req.Username},
+		{"first_name", req.FirstName},
+		{"last_name", req.LastName},
+	}
+
+	var violations []security.FieldViolation
+	for _, field := range fields {
+		if violation := h.xssService.CheckField(field.name, field.value); violation != nil {
+			violations = append(violations, *violation)
 		}
-		h.logger.Error("Failed to generate CSRF token", "error", err)
-		c.Error(errors.NewInternalError("Failed to generate CSRF token"))
-		return
 	}
 
-	h.csrfService.SetCSRFCookie(c.Writer, csrfToken)
-
-	c.JSON(http.StatusOK, gin.H{
-		"csrf_token":   csrfToken.Token,
-		"expires_at":   csrfToken.ExpiresAt.Unix(),
-		"csrf_enabled": true,
-	})
+	return violations
 }
 
 func (h *AuthHandlers) setSecureAuthCookies(c *gin.Context, accessToken string, csrfToken *security.CSRFToken) {
-	claims, err := h.jwtService.ValidateToken(accessToken, c.Request)
+	claims, err := h.jwtService.ParseIssuedToken(accessToken)
 	if err == nil {
 		rolesJson := strings.Join(claims.Roles, ",")
 		c.SetCookie(
@@ -649,7 +853,7 @@ func (h *AuthHandlers) setSecureAuthCookies(c *gin.Context, accessToken string,
 }
 
 func (h *AuthHandlers) setSecureAuthCookiesWithoutCSRF(c *gin.Context, accessToken string) {
-	claims, err := h.jwtService.ValidateToken(accessToken, c.Request)
+	claims, err := h.jwtService.ParseIssuedToken(accessToken)
 	if err == nil {
 		rolesJson := strings.Join(claims.Roles, ",")
 		c.SetCookie(
@@ -694,11 +898,13 @@ func (h *AuthHandlers) setSecureAuthCookiesWithoutCSRF(c *gin.Context, accessTok
 	)
 }
 
-func (h *AuthHandlers) setRefreshTokenCookies(c *gin.Context, accessToken string, refreshToken string) {
+func (h *AuthHandlers) setRefreshTokenCookies(c *gin.Context, tokenPair *services.TokenPair) {
+	accessToken, refreshToken := tokenPair.AccessToken, tokenPair.RefreshToken
+
 	// For local development, don't require secure cookies
 	isSecure := h.securityConfig.Session.SecureCookie && (c.Request.Header.Get("X-Forwarded-Proto") == "https" || c.Request.TLS != nil)
 
-	claims, err := h.jwtService.ValidateToken(accessToken, c.Request)
+	claims, err := h.jwtService.ParseIssuedToken(accessToken)
 	if err == nil {
 		rolesJson := strings.Join(claims.Roles, ",")
 		c.SetCookie(
@@ -751,10 +957,23 @@ func (h *AuthHandlers) setRefreshTokenCookies(c *gin.Context, accessToken string
 		isSecure,
 		true,
 	)
+
+	// Set directly rather than through gin so SameSite applies to this cookie only
+	if tokenPair.Fingerprint != "" {
+		http.SetCookie(c.Writer, &http.Cookie{
+			Name:     constants.FingerprintCookieName,
+			Value:    tokenPair.Fingerprint,
+			MaxAge:   int(h.securityConfig.JWT.AccessTokenDuration.Seconds()),
+			Path:     "/",
+			Secure:   isSecure,
+			HttpOnly: true,
+			SameSite: http.SameSiteStrictMode,
+		})
+	}
 }
 
-func (h *AuthHandlers) setRefreshTokenCookiesWithCSRF(c *gin.Context, accessToken string, refreshToken string, csrfToken *security.CSRFToken) {
-	h.setRefreshTokenCookies(c, accessToken, refreshToken)
+func (h *AuthHandlers) setRefreshTokenCookiesWithCSRF(c *gin.Context, tokenPair *services.TokenPair, csrfToken *security.CSRFToken) {
+	h.setRefreshTokenCookies(c, tokenPair)
 
 	h.csrfService.SetCSRFCookie(c.Writer, csrfToken)
 }
@@ -768,20 +987,11 @@ func (h *AuthHandlers) clearSecureAuthCookies(c *gin.Context) {
 	c.SetCookie(constants.SessionIDCookieName, "", -1, "/", "", isSecure, true)
 	c.SetCookie(constants.UserRolesCookieName, "", -1, "/", "", isSecure, false)
 	c.SetCookie(constants.UserIDCookieName, "", -1, "/", "", isSecure, false)
-	c.SetCookie("fingerprint", "", -1, "/", "", isSecure, true)
+	c.SetCookie(constants.FingerprintCookieName, "", -1, "/", "", isSecure, true)
 
 	h.csrfService.ClearCSRFCookie(c.Writer)
 }
 
-func contains(roles []string, role string) bool {
-	for _, r := range roles {
-		if r == role {
-			return true
-		}
-	}
-	return false
-}
-
 func (h *AuthHandlers) rolesEqual(roles1, roles2 []string) bool {
 	if len(roles1) != len(roles2) {
 		return false
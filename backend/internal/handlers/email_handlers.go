@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"context"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -27,7 +26,7 @@ type TestEmailRequest struct {
 }
 
 func (h *EmailHandlers) SendTestEmail(c *gin.Context) {
-	ctx := context.Background()
+	ctx := c.Request.Context()
 	logger := h.container.GetLogger()
 	emailService := h.container.GetEmailService()
 
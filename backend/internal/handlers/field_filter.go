@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fieldSet is the set of response fields requested with ?fields=a,b,c
+type fieldSet map[string]struct{}
+
+// parseFieldSet reads the fields query parameter. A nil result means the
+// client did not ask for a sparse response. "id" is always included.
+func parseFieldSet(c *gin.Context) fieldSet {
+	raw := strings.TrimSpace(c.Query("fields"))
+	if raw == "" {
+		return nil
+	}
+
+	fields := fieldSet{"id": {}}
+	for _, name := range strings.Split(raw, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			fields[name] = struct{}{}
+		}
+	}
+	return fields
+}
+
+// apply keeps only the requested top-level fields of an object or of each
+// object in a list. Unknown field names are ignored.
+func (f fieldSet) apply(data interface{}) (interface{}, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// UseNumber keeps int64 IDs intact instead of converting them to float64
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+
+	switch v := generic.(type) {
+	case map[string]interface{}:
+		return f.filterObject(v), nil
+	case []interface{}:
+		for i, item := range v {
+			if obj, ok := item.(map[string]interface{}); ok {
+				v[i] = f.filterObject(obj)
+			}
+		}
+		return v, nil
+	default:
+		return v, nil
+	}
+}
+
+func (f fieldSet) filterObject(obj map[string]interface{}) map[string]interface{} {
+	filtered := make(map[string]interface{}, len(f))
+	for key, value := range obj {
+		if _, ok := f[key]; ok {
+			filtered[key] = value
+		}
+	}
+	return filtered
+}
+
+// respondWithFields writes a {"data": ...} response, honouring ?fields= when present
+func respondWithFields(c *gin.Context, data interface{}) {
+	filtered, ok := filterFields(c, data)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": filtered})
+}
+
+// respondWithFieldsAndCursor is respondWithFields for cursor-paginated lists.
+// next_cursor is null once there are no more results.
+func respondWithFieldsAndCursor(c *gin.Context, data interface{}, nextCursor string) {
+	filtered, ok := filterFields(c, data)
+	if !ok {
+		return
+	}
+
+	var cursor interface{}
+	if nextCursor != "" {
+		cursor = nextCursor
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": filtered, "next_cursor": cursor})
+}
+
+// filterFields applies ?fields= to data. On failure it writes an error
+// response and returns false.
+func filterFields(c *gin.Context, data interface{}) (interface{}, bool) {
+	fields := parseFieldSet(c)
+	if fields == nil {
+		return data, true
+	}
+
+	filtered, err := fields.apply(data)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return nil, false
+	}
+
+	return filtered, true
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type filterTestItem struct {
+	ID      int64  `json:"id"`
+	Title   string `json:"title"`
+	Content string `json:"content"`
+	OwnerID int64  `json:"owner_id"`
+}
+
+// serveWithFields runs respond against a request for target and returns the
+// response body
+func serveWithFields(t *testing.T, target string, respond func(c *gin.Context)) string {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	engine := gin.New()
+	engine.GET("/", respond)
+
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	return rec.Body.String()
+}
+
+func TestRespondWithFields(t *testing.T) {
+	item := filterTestItem{ID: 9007199254740993, Title: "Notes", Content: "body", OwnerID: 4}
+
+	tests := []struct {
+		name   string
+		target string
+		data   interface{}
+		want   string
+	}{
+		{"no fields", "/", item, `{"data":{"id":9007199254740993,"title":"Notes","content":"body","owner_id":4}}`},
+		{"object", "/?fields=title", item, `{"data":{"id":9007199254740993,"title":"Notes"}}`},
+		{"list", "/?fields=%20owner_id%20,,unknown", []filterTestItem{item, {ID: 2}}, `{"data":[{"id":9007199254740993,"owner_id":4},{"id":2,"owner_id":0}]}`},
+		{"blank fields", "/?fields=%20", item, `{"data":{"id":9007199254740993,"title":"Notes","content":"body","owner_id":4}}`},
+		{"scalar", "/?fields=title", 42, `{"data":42}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := serveWithFields(t, tt.target, func(c *gin.Context) { respondWithFields(c, tt.data) })
+			if got != tt.want {
+				t.Errorf("body = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRespondWithFieldsAndCursor(t *testing.T) {
+	items := []filterTestItem{{ID: 1, Title: "A", Content: "x"}}
+
+	got := serveWithFields(t, "/?fields=title", func(c *gin.Context) { respondWithFieldsAndCursor(c, items, "abc") })
+	if want := `{"data":[{"id":1,"title":"A"}],"next_cursor":"abc"}`; got != want {
+		t.Errorf("body = %s, want %s", got, want)
+	}
+
+	got = serveWithFields(t, "/", func(c *gin.Context) { respondWithFieldsAndCursor(c, items, "") })
+	if want := `{"data":[{"id":1,"title":"A","content":"x","owner_id":0}],"next_cursor":null}`; got != want {
+		t.Errorf("body = %s, want %s", got, want)
+	}
+}
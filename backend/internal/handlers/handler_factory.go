@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"github.com/Srivathsav-max/lumen/backend/internal/container"
+	"github.com/Srivathsav-max/lumen/backend/internal/database"
 )
 
 type HandlerFactory struct {
@@ -18,6 +19,7 @@ func (f *HandlerFactory) CreateAuthHandlers() *AuthHandlers {
 	return NewAuthHandlers(
 		f.container.GetAuthService(),
 		f.container.GetUserService(),
+		f.container.GetOAuthService(),
 		f.container.GetSecurityConfig(),
 		f.container.GetLogger(),
 	)
@@ -39,8 +41,15 @@ func (f *HandlerFactory) CreateWaitlistHandlers() *WaitlistHandlers {
 }
 
 func (f *HandlerFactory) CreateSystemHandlers() *SystemHandlers {
+	var dbHealth *database.HealthChecker
+	if db := f.container.GetDB(); db != nil {
+		dbHealth = database.NewHealthChecker(database.NewPostgresManager(db, f.container.GetLogger()), f.container.GetLogger())
+	}
+
 	return NewSystemHandlers(
 		f.container.GetSystemSettingsService(),
+		dbHealth,
+		f.container.GetAIService(),
 	)
 }
 
@@ -65,6 +74,11 @@ func (f *HandlerFactory) CreateNotesHandlers() *NotesHandlers {
 	return NewNotesHandlers(
 		f.container.GetWorkspaceService(),
 		f.container.GetPageService(),
+		f.container.GetViewerTokenService(),
+		f.container.GetShareLinkService(),
+		f.container.GetCommentService(),
+		f.container.GetNotificationService(),
+		f.container.GetActivityService(),
 		f.container.GetLogger(),
 	)
 }
@@ -76,6 +90,7 @@ func (f *HandlerFactory) CreateAIHandlers() *AIHandlers {
 	)
 	// Inject chat service via exported field for simplicity
 	h.chat = f.container.AIChatService
+	h.usage = f.container.GetAIUsageService()
 	return h
 }
 
@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"context"
 	"net/http"
 
 	"github.com/Srivathsav-max/lumen/backend/internal/container"
@@ -19,7 +18,7 @@ func NewMaintenanceHandlers(container *container.Container) *MaintenanceHandlers
 }
 
 func (h *MaintenanceHandlers) GetMaintenanceStatus(c *gin.Context) {
-	ctx := context.Background()
+	ctx := c.Request.Context()
 	logger := h.container.GetLogger()
 	systemSettingsService := h.container.GetSystemSettingsService()
 
@@ -37,7 +36,7 @@ func (h *MaintenanceHandlers) GetMaintenanceStatus(c *gin.Context) {
 }
 
 func (h *MaintenanceHandlers) EnableMaintenanceMode(c *gin.Context) {
-	ctx := context.Background()
+	ctx := c.Request.Context()
 	logger := h.container.GetLogger()
 	systemSettingsService := h.container.GetSystemSettingsService()
 
@@ -67,7 +66,7 @@ func (h *MaintenanceHandlers) EnableMaintenanceMode(c *gin.Context) {
 }
 
 func (h *MaintenanceHandlers) DisableMaintenanceMode(c *gin.Context) {
-	ctx := context.Background()
+	ctx := c.Request.Context()
 	logger := h.container.GetLogger()
 	systemSettingsService := h.container.GetSystemSettingsService()
 
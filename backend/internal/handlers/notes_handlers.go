@@ -3,6 +3,9 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
+	"unicode"
 
 	"log/slog"
 
@@ -13,20 +16,35 @@ import (
 )
 
 type NotesHandlers struct {
-	workspaceService services.WorkspaceService
-	pageService      services.PageService
-	logger           *slog.Logger
+	workspaceService    services.WorkspaceService
+	pageService         services.PageService
+	viewerTokenService  services.ViewerTokenService
+	shareLinkService    services.ShareLinkService
+	commentService      services.CommentService
+	notificationService services.NotificationService
+	activityService     services.ActivityService
+	logger              *slog.Logger
 }
 
 func NewNotesHandlers(
 	workspaceService services.WorkspaceService,
 	pageService services.PageService,
+	viewerTokenService services.ViewerTokenService,
+	shareLinkService services.ShareLinkService,
+	commentService services.CommentService,
+	notificationService services.NotificationService,
+	activityService services.ActivityService,
 	logger *slog.Logger,
 ) *NotesHandlers {
 	return &NotesHandlers{
-		workspaceService: workspaceService,
-		pageService:      pageService,
-		logger:           logger,
+		workspaceService:    workspaceService,
+		pageService:         pageService,
+		viewerTokenService:  viewerTokenService,
+		shareLinkService:    shareLinkService,
+		commentService:      commentService,
+		notificationService: notificationService,
+		activityService:     activityService,
+		logger:              logger,
 	}
 }
 
@@ -74,7 +92,7 @@ func (h *NotesHandlers) GetWorkspace(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": workspace})
+	respondWithFields(c, workspace)
 }
 
 func (h *NotesHandlers) GetUserWorkspaces(c *gin.Context) {
@@ -90,7 +108,7 @@ func (h *NotesHandlers) GetUserWorkspaces(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": workspaces})
+	respondWithFields(c, workspaces)
 }
 
 func (h *NotesHandlers) UpdateWorkspace(c *gin.Context) {
@@ -122,6 +140,58 @@ func (h *NotesHandlers) UpdateWorkspace(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"data": workspace})
 }
 
+func (h *NotesHandlers) GetPropertySchema(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	workspaceIDStr := c.Param("workspace_id")
+	workspaceID, err := strconv.ParseInt(workspaceIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	schema, err := h.workspaceService.GetPropertySchema(c.Request.Context(), userID.(int64), workspaceID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": schema})
+}
+
+func (h *NotesHandlers) UpdatePropertySchema(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	workspaceIDStr := c.Param("workspace_id")
+	workspaceID, err := strconv.ParseInt(workspaceIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	var req services.UpdatePropertySchemaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	schema, err := h.workspaceService.UpdatePropertySchema(c.Request.Context(), userID.(int64), workspaceID, &req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": schema})
+}
+
 func (h *NotesHandlers) DeleteWorkspace(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
@@ -174,6 +244,79 @@ func (h *NotesHandlers) AddWorkspaceMember(c *gin.Context) {
 	c.JSON(http.StatusCreated, gin.H{"data": member})
 }
 
+func (h *NotesHandlers) InviteWorkspaceMember(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	workspaceIDStr := c.Param("workspace_id")
+	workspaceID, err := strconv.ParseInt(workspaceIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	var req services.InviteWorkspaceMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	invitation, err := h.workspaceService.InviteMember(c.Request.Context(), userID.(int64), workspaceID, &req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": invitation})
+}
+
+func (h *NotesHandlers) AcceptWorkspaceInvitation(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req services.AcceptWorkspaceInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	workspace, err := h.workspaceService.AcceptInvitation(c.Request.Context(), userID.(int64), &req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": workspace})
+}
+
+func (h *NotesHandlers) LeaveWorkspace(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	workspaceIDStr := c.Param("workspace_id")
+	workspaceID, err := strconv.ParseInt(workspaceIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	if err := h.workspaceService.LeaveWorkspace(c.Request.Context(), userID.(int64), workspaceID); err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Left workspace successfully"})
+}
+
 func (h *NotesHandlers) RemoveWorkspaceMember(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
@@ -218,13 +361,60 @@ func (h *NotesHandlers) GetWorkspaceMembers(c *gin.Context) {
 		return
 	}
 
-	members, err := h.workspaceService.GetMembers(c.Request.Context(), userID.(int64), workspaceID)
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 50
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	members, err := h.workspaceService.GetMembers(c.Request.Context(), userID.(int64), workspaceID, limit, offset)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	respondWithFields(c, members)
+}
+
+func (h *NotesHandlers) GetWorkspaceActivity(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	workspaceIDStr := c.Param("workspace_id")
+	workspaceID, err := strconv.ParseInt(workspaceIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 50
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	activity, err := h.activityService.ListWorkspaceActivity(c.Request.Context(), userID.(int64), workspaceID, limit, offset)
 	if err != nil {
 		h.handleServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": members})
+	c.JSON(http.StatusOK, gin.H{"data": activity})
+}
+
+type updateMemberRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=member admin"`
 }
 
 func (h *NotesHandlers) UpdateMemberRole(c *gin.Context) {
@@ -248,9 +438,7 @@ func (h *NotesHandlers) UpdateMemberRole(c *gin.Context) {
 		return
 	}
 
-	var req struct {
-		Role string `json:"role" validate:"required,oneof=member admin"`
-	}
+	var req updateMemberRoleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
 		return
@@ -313,7 +501,7 @@ func (h *NotesHandlers) GetPage(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": page})
+	respondWithFields(c, page)
 }
 
 func (h *NotesHandlers) GetWorkspacePages(c *gin.Context) {
@@ -331,14 +519,33 @@ func (h *NotesHandlers) GetWorkspacePages(c *gin.Context) {
 	}
 
 	includeArchived := c.Query("include_archived") == "true"
+	includeTemplates := c.Query("include_templates") == "true"
 
-	pages, err := h.pageService.GetWorkspacePages(c.Request.Context(), userID.(int64), workspaceID, includeArchived)
+	var pages []services.PageResponse
+	var nextCursor string
+	cursorMode := useCursorPagination(c)
+	if cursorMode {
+		pages, nextCursor, err = h.pageService.ListWorkspacePages(c.Request.Context(), userID.(int64), workspaceID, includeArchived, includeTemplates, c.Query("cursor"), parseCursorLimit(c))
+	} else {
+		pages, err = h.pageService.GetWorkspacePages(c.Request.Context(), userID.(int64), workspaceID, includeArchived, includeTemplates)
+	}
 	if err != nil {
 		h.handleServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": pages})
+	if c.Query("preview") == "true" {
+		if err := h.pageService.AttachPreviews(c.Request.Context(), pages); err != nil {
+			h.handleServiceError(c, err)
+			return
+		}
+	}
+
+	if cursorMode {
+		respondWithFieldsAndCursor(c, pages, nextCursor)
+		return
+	}
+	respondWithFields(c, pages)
 }
 
 func (h *NotesHandlers) GetRootPages(c *gin.Context) {
@@ -356,120 +563,164 @@ func (h *NotesHandlers) GetRootPages(c *gin.Context) {
 	}
 
 	includeArchived := c.Query("include_archived") == "true"
+	includeTemplates := c.Query("include_templates") == "true"
 
-	pages, err := h.pageService.GetRootPages(c.Request.Context(), userID.(int64), workspaceID, includeArchived)
+	pages, err := h.pageService.GetRootPages(c.Request.Context(), userID.(int64), workspaceID, includeArchived, includeTemplates)
 	if err != nil {
 		h.handleServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": pages})
+	if c.Query("preview") == "true" {
+		if err := h.pageService.AttachPreviews(c.Request.Context(), pages); err != nil {
+			h.handleServiceError(c, err)
+			return
+		}
+	}
+
+	respondWithFields(c, pages)
 }
 
-func (h *NotesHandlers) GetChildPages(c *gin.Context) {
+func (h *NotesHandlers) GetWorkspaceTemplates(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
 	}
 
-	parentPageID := c.Param("page_id")
-	includeArchived := c.Query("include_archived") == "true"
+	workspaceIDStr := c.Param("workspace_id")
+	workspaceID, err := strconv.ParseInt(workspaceIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
 
-	pages, err := h.pageService.GetChildPages(c.Request.Context(), userID.(int64), parentPageID, includeArchived)
+	templates, err := h.pageService.GetWorkspaceTemplates(c.Request.Context(), userID.(int64), workspaceID)
 	if err != nil {
 		h.handleServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": pages})
+	respondWithFields(c, templates)
 }
 
-func (h *NotesHandlers) UpdatePage(c *gin.Context) {
+func (h *NotesHandlers) GetPageTree(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
 	}
 
-	pageID := c.Param("page_id")
-
-	var req services.UpdatePageRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+	workspaceIDStr := c.Param("workspace_id")
+	workspaceID, err := strconv.ParseInt(workspaceIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
 		return
 	}
 
-	page, err := h.pageService.UpdatePage(c.Request.Context(), userID.(int64), pageID, &req)
+	maxDepth := 0
+	if maxDepthStr := c.Query("max_depth"); maxDepthStr != "" {
+		maxDepth, err = strconv.Atoi(maxDepthStr)
+		if err != nil || maxDepth < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid max_depth"})
+			return
+		}
+	}
+
+	includeArchived := c.Query("include_archived") == "true"
+	includeTemplates := c.Query("include_templates") == "true"
+
+	tree, err := h.pageService.GetPageTree(c.Request.Context(), userID.(int64), workspaceID, maxDepth, includeArchived, includeTemplates)
 	if err != nil {
 		h.handleServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": page})
+	c.JSON(http.StatusOK, gin.H{"data": tree})
 }
 
-func (h *NotesHandlers) SavePageContent(c *gin.Context) {
+func (h *NotesHandlers) GetOrphanedPages(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
 	}
 
-	pageID := c.Param("page_id")
-
-	var req services.SavePageContentRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+	workspaceIDStr := c.Param("workspace_id")
+	workspaceID, err := strconv.ParseInt(workspaceIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
 		return
 	}
 
-	page, err := h.pageService.SavePageContent(c.Request.Context(), userID.(int64), pageID, &req)
+	pages, err := h.pageService.GetOrphanedPages(c.Request.Context(), userID.(int64), workspaceID)
 	if err != nil {
 		h.handleServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": page})
+	c.JSON(http.StatusOK, gin.H{"data": pages})
 }
 
-func (h *NotesHandlers) DeletePage(c *gin.Context) {
+func (h *NotesHandlers) RepairOrphanedPages(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
 	}
 
-	pageID := c.Param("page_id")
+	workspaceIDStr := c.Param("workspace_id")
+	workspaceID, err := strconv.ParseInt(workspaceIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
 
-	err := h.pageService.DeletePage(c.Request.Context(), userID.(int64), pageID)
+	var req services.RepairOrphanedPagesRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+			return
+		}
+	}
+
+	result, err := h.pageService.RepairOrphanedPages(c.Request.Context(), userID.(int64), workspaceID, &req)
 	if err != nil {
 		h.handleServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Page deleted successfully"})
+	c.JSON(http.StatusOK, gin.H{"data": result})
 }
 
-func (h *NotesHandlers) ArchivePage(c *gin.Context) {
+func (h *NotesHandlers) GetChildPages(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
 	}
 
-	pageID := c.Param("page_id")
+	parentPageID := c.Param("page_id")
+	includeArchived := c.Query("include_archived") == "true"
+	includeTemplates := c.Query("include_templates") == "true"
 
-	err := h.pageService.ArchivePage(c.Request.Context(), userID.(int64), pageID)
+	pages, err := h.pageService.GetChildPages(c.Request.Context(), userID.(int64), parentPageID, includeArchived, includeTemplates)
 	if err != nil {
 		h.handleServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Page archived successfully"})
+	if c.Query("preview") == "true" {
+		if err := h.pageService.AttachPreviews(c.Request.Context(), pages); err != nil {
+			h.handleServiceError(c, err)
+			return
+		}
+	}
+
+	respondWithFields(c, pages)
 }
 
-func (h *NotesHandlers) RestorePage(c *gin.Context) {
+func (h *NotesHandlers) GetPageAncestors(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
@@ -478,60 +729,62 @@ func (h *NotesHandlers) RestorePage(c *gin.Context) {
 
 	pageID := c.Param("page_id")
 
-	err := h.pageService.RestorePage(c.Request.Context(), userID.(int64), pageID)
+	pages, err := h.pageService.GetPageAncestors(c.Request.Context(), userID.(int64), pageID)
 	if err != nil {
 		h.handleServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Page restored successfully"})
+	c.JSON(http.StatusOK, gin.H{"data": pages})
 }
 
-func (h *NotesHandlers) SearchPages(c *gin.Context) {
+func (h *NotesHandlers) ReorderBlocks(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
 	}
 
-	var req services.SearchPagesRequest
+	pageID := c.Param("page_id")
+
+	var req services.ReorderBlocksRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
 		return
 	}
 
-	result, err := h.pageService.SearchPages(c.Request.Context(), userID.(int64), &req)
+	blocks, err := h.pageService.ReorderBlocks(c.Request.Context(), userID.(int64), pageID, req.BlockOrders)
 	if err != nil {
 		h.handleServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": result})
+	c.JSON(http.StatusOK, gin.H{"data": blocks})
 }
 
-func (h *NotesHandlers) GetRecentPages(c *gin.Context) {
+func (h *NotesHandlers) ImportMarkdown(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
 		return
 	}
 
-	limitStr := c.DefaultQuery("limit", "20")
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit < 1 || limit > 100 {
-		limit = 20
+	var req services.ImportMarkdownRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
 	}
 
-	pages, err := h.pageService.GetRecentPages(c.Request.Context(), userID.(int64), limit)
+	page, err := h.pageService.ImportMarkdown(c.Request.Context(), userID.(int64), &req)
 	if err != nil {
 		h.handleServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": pages})
+	c.JSON(http.StatusCreated, gin.H{"data": page})
 }
 
-func (h *NotesHandlers) GetPageVersions(c *gin.Context) {
+func (h *NotesHandlers) ExportPage(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
@@ -540,25 +793,537 @@ func (h *NotesHandlers) GetPageVersions(c *gin.Context) {
 
 	pageID := c.Param("page_id")
 
-	limitStr := c.DefaultQuery("limit", "20")
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit < 1 || limit > 100 {
-		limit = 20
+	format := c.DefaultQuery("format", "markdown")
+	if format != "markdown" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported export format"})
+		return
 	}
 
-	offsetStr := c.DefaultQuery("offset", "0")
-	offset, err := strconv.Atoi(offsetStr)
-	if err != nil || offset < 0 {
-		offset = 0
+	markdown, err := h.pageService.ExportPageMarkdown(c.Request.Context(), userID.(int64), pageID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
 	}
 
-	versions, err := h.pageService.GetPageVersions(c.Request.Context(), userID.(int64), pageID, limit, offset)
+	page, err := h.pageService.GetPage(c.Request.Context(), userID.(int64), pageID)
 	if err != nil {
 		h.handleServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": versions})
+	c.Header("Content-Disposition", `attachment; filename="`+exportFilename(page.Title)+`.md"`)
+	c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(markdown))
+}
+
+// exportFilename turns a page title into a safe download file name
+func exportFilename(title string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)), r == '-', r == '_':
+			return r
+		case unicode.IsSpace(r):
+			return '-'
+		default:
+			return -1
+		}
+	}, strings.TrimSpace(title))
+
+	if name == "" {
+		return "page"
+	}
+	return name
+}
+
+func (h *NotesHandlers) UpdatePage(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	pageID := c.Param("page_id")
+
+	var req services.UpdatePageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	page, err := h.pageService.UpdatePage(c.Request.Context(), userID.(int64), pageID, &req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": page})
+}
+
+func (h *NotesHandlers) MovePage(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	pageID := c.Param("page_id")
+
+	var req services.MovePageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	page, err := h.pageService.MovePage(c.Request.Context(), userID.(int64), pageID, &req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": page})
+}
+
+func (h *NotesHandlers) DuplicatePage(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	pageID := c.Param("page_id")
+
+	var req services.DuplicatePageRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+			return
+		}
+	}
+
+	page, err := h.pageService.DuplicatePage(c.Request.Context(), userID.(int64), pageID, &req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": page})
+}
+
+func (h *NotesHandlers) InstantiateTemplate(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	pageID := c.Param("page_id")
+
+	var req services.InstantiateTemplateRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+			return
+		}
+	}
+
+	page, err := h.pageService.CreateFromTemplate(c.Request.Context(), userID.(int64), pageID, &req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": page})
+}
+
+func (h *NotesHandlers) SavePageContent(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	pageID := c.Param("page_id")
+
+	var req services.SavePageContentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	page, err := h.pageService.SavePageContent(c.Request.Context(), userID.(int64), pageID, &req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": page})
+}
+
+func (h *NotesHandlers) ValidateContent(c *gin.Context) {
+	if _, exists := c.Get("userID"); !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req services.SavePageContentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	result, err := h.pageService.ValidateContent(c.Request.Context(), &req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+func (h *NotesHandlers) DeletePage(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	pageID := c.Param("page_id")
+
+	err := h.pageService.DeletePage(c.Request.Context(), userID.(int64), pageID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Page moved to trash"})
+}
+
+func (h *NotesHandlers) GetTrash(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	workspaceIDStr := c.Param("workspace_id")
+	workspaceID, err := strconv.ParseInt(workspaceIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	pages, err := h.pageService.ListTrash(c.Request.Context(), userID.(int64), workspaceID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": pages})
+}
+
+func (h *NotesHandlers) RestorePageFromTrash(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	pageID := c.Param("page_id")
+
+	err := h.pageService.RestoreFromTrash(c.Request.Context(), userID.(int64), pageID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Page restored from trash successfully"})
+}
+
+func (h *NotesHandlers) PurgePage(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	pageID := c.Param("page_id")
+
+	err := h.pageService.PurgePage(c.Request.Context(), userID.(int64), pageID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Page permanently deleted"})
+}
+
+func (h *NotesHandlers) ArchivePage(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	pageID := c.Param("page_id")
+
+	var req services.ArchivePageRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+			return
+		}
+	}
+	cascade := req.Cascade == nil || *req.Cascade
+
+	err := h.pageService.ArchivePage(c.Request.Context(), userID.(int64), pageID, cascade)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Page archived successfully"})
+}
+
+func (h *NotesHandlers) BulkArchivePages(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req services.BulkPagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	result, err := h.pageService.BulkArchivePages(c.Request.Context(), userID.(int64), req.PageIDs, req.IncludeDescendants)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+func (h *NotesHandlers) BulkDeletePages(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req services.BulkPagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	result, err := h.pageService.BulkDeletePages(c.Request.Context(), userID.(int64), req.PageIDs)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+func (h *NotesHandlers) RestorePage(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	pageID := c.Param("page_id")
+
+	var req services.RestorePageRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+			return
+		}
+	}
+	cascade := req.Cascade == nil || *req.Cascade
+
+	err := h.pageService.RestorePage(c.Request.Context(), userID.(int64), pageID, cascade)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Page restored successfully"})
+}
+
+func (h *NotesHandlers) AddFavorite(c *gin.Context) {
+	h.toggleFavorite(c, true, "Page added to favorites")
+}
+
+func (h *NotesHandlers) RemoveFavorite(c *gin.Context) {
+	h.toggleFavorite(c, false, "Page removed from favorites")
+}
+
+func (h *NotesHandlers) toggleFavorite(c *gin.Context, favorite bool, message string) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	pageID := c.Param("page_id")
+
+	err := h.pageService.ToggleFavorite(c.Request.Context(), userID.(int64), pageID, favorite)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": message})
+}
+
+func (h *NotesHandlers) GetFavoritePages(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	pages, err := h.pageService.GetFavoritePages(c.Request.Context(), userID.(int64))
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	respondWithFields(c, pages)
+}
+
+func (h *NotesHandlers) SearchPages(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req services.SearchPagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	result, err := h.pageService.SearchPages(c.Request.Context(), userID.(int64), &req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+func (h *NotesHandlers) QueryPages(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	workspaceIDStr := c.Param("workspace_id")
+	workspaceID, err := strconv.ParseInt(workspaceIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workspace ID"})
+		return
+	}
+
+	var req services.QueryPagesRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+			return
+		}
+	}
+
+	result, err := h.pageService.QueryPages(c.Request.Context(), userID.(int64), workspaceID, &req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+func (h *NotesHandlers) GetRecentPages(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	if useCursorPagination(c) {
+		pages, nextCursor, err := h.pageService.ListRecentPages(c.Request.Context(), userID.(int64), c.Query("cursor"), parseCursorLimit(c))
+		if err != nil {
+			h.handleServiceError(c, err)
+			return
+		}
+
+		respondWithFieldsAndCursor(c, pages, nextCursor)
+		return
+	}
+
+	limitStr := c.DefaultQuery("limit", "20")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	pages, err := h.pageService.GetRecentPages(c.Request.Context(), userID.(int64), limit)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	respondWithFields(c, pages)
+}
+
+// useCursorPagination reports whether the client opted into cursor pagination
+// with ?pagination=cursor. Passing a cursor implies it.
+func useCursorPagination(c *gin.Context) bool {
+	return c.Query("pagination") == "cursor" || c.Query("cursor") != ""
+}
+
+func parseCursorLimit(c *gin.Context) int {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		return 20
+	}
+	return limit
+}
+
+func (h *NotesHandlers) GetPageVersions(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	pageID := c.Param("page_id")
+
+	limitStr := c.DefaultQuery("limit", "20")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	offsetStr := c.DefaultQuery("offset", "0")
+	offset, err := strconv.Atoi(offsetStr)
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	versions, err := h.pageService.GetPageVersions(c.Request.Context(), userID.(int64), pageID, limit, offset)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": versions})
 }
 
 func (h *NotesHandlers) GetPageVersion(c *gin.Context) {
@@ -569,24 +1334,145 @@ func (h *NotesHandlers) GetPageVersion(c *gin.Context) {
 	}
 
 	pageID := c.Param("page_id")
-	versionNumberStr := c.Param("version_number")
+	versionNumberStr := c.Param("version_number")
+
+	versionNumber, err := strconv.Atoi(versionNumberStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid version number"})
+		return
+	}
+
+	version, err := h.pageService.GetPageVersion(c.Request.Context(), userID.(int64), pageID, versionNumber)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": version})
+}
+
+func (h *NotesHandlers) GrantPagePermission(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	pageID := c.Param("page_id")
+
+	var req services.GrantPagePermissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	permission, err := h.pageService.GrantPermission(c.Request.Context(), userID.(int64), pageID, &req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": permission})
+}
+
+func (h *NotesHandlers) GetPageAccess(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	pageID := c.Param("page_id")
+
+	access, err := h.pageService.GetEffectiveAccess(c.Request.Context(), userID.(int64), pageID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": access})
+}
+
+func (h *NotesHandlers) RevokePagePermission(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	pageID := c.Param("page_id")
+	targetUserIDStr := c.Param("user_id")
+
+	targetUserID, err := strconv.ParseInt(targetUserIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	err = h.pageService.RevokePermission(c.Request.Context(), userID.(int64), pageID, targetUserID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Permission revoked successfully"})
+}
+
+func (h *NotesHandlers) GetPagePermissions(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	pageID := c.Param("page_id")
 
-	versionNumber, err := strconv.Atoi(versionNumberStr)
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 50
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	permissions, err := h.pageService.GetPagePermissions(c.Request.Context(), userID.(int64), pageID, limit, offset)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid version number"})
+		h.handleServiceError(c, err)
 		return
 	}
 
-	version, err := h.pageService.GetPageVersion(c.Request.Context(), userID.(int64), pageID, versionNumber)
+	c.JSON(http.StatusOK, gin.H{"data": permissions})
+}
+
+// Comment Handlers
+
+func (h *NotesHandlers) CreateComment(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req services.CreateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	// The page always comes from the URL
+	req.PageID = c.Param("page_id")
+
+	comment, err := h.commentService.CreateComment(c.Request.Context(), userID.(int64), &req)
 	if err != nil {
 		h.handleServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": version})
+	c.JSON(http.StatusCreated, gin.H{"data": comment})
 }
 
-func (h *NotesHandlers) GrantPagePermission(c *gin.Context) {
+func (h *NotesHandlers) GetPageComments(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
@@ -595,22 +1481,49 @@ func (h *NotesHandlers) GrantPagePermission(c *gin.Context) {
 
 	pageID := c.Param("page_id")
 
-	var req services.GrantPagePermissionRequest
+	var (
+		comments []services.CommentResponse
+		err      error
+	)
+	if blockID := c.Query("block_id"); blockID != "" {
+		comments, err = h.commentService.GetBlockComments(c.Request.Context(), userID.(int64), pageID, blockID)
+	} else {
+		comments, err = h.commentService.GetPageComments(c.Request.Context(), userID.(int64), pageID)
+	}
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": comments})
+}
+
+func (h *NotesHandlers) ReplyToComment(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	pageID := c.Param("page_id")
+	commentID := c.Param("comment_id")
+
+	var req services.UpdateCommentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
 		return
 	}
 
-	permission, err := h.pageService.GrantPermission(c.Request.Context(), userID.(int64), pageID, &req)
+	comment, err := h.commentService.ReplyToComment(c.Request.Context(), userID.(int64), pageID, commentID, &req)
 	if err != nil {
 		h.handleServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"data": permission})
+	c.JSON(http.StatusCreated, gin.H{"data": comment})
 }
 
-func (h *NotesHandlers) RevokePagePermission(c *gin.Context) {
+func (h *NotesHandlers) UpdateComment(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
@@ -618,24 +1531,43 @@ func (h *NotesHandlers) RevokePagePermission(c *gin.Context) {
 	}
 
 	pageID := c.Param("page_id")
-	targetUserIDStr := c.Param("user_id")
+	commentID := c.Param("comment_id")
 
-	targetUserID, err := strconv.ParseInt(targetUserIDStr, 10, 64)
+	var req services.UpdateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	comment, err := h.commentService.UpdateComment(c.Request.Context(), userID.(int64), pageID, commentID, &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		h.handleServiceError(c, err)
 		return
 	}
 
-	err = h.pageService.RevokePermission(c.Request.Context(), userID.(int64), pageID, targetUserID)
+	c.JSON(http.StatusOK, gin.H{"data": comment})
+}
+
+func (h *NotesHandlers) ResolveComment(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	pageID := c.Param("page_id")
+	commentID := c.Param("comment_id")
+
+	comment, err := h.commentService.ResolveComment(c.Request.Context(), userID.(int64), pageID, commentID)
 	if err != nil {
 		h.handleServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Permission revoked successfully"})
+	c.JSON(http.StatusOK, gin.H{"data": comment})
 }
 
-func (h *NotesHandlers) GetPagePermissions(c *gin.Context) {
+func (h *NotesHandlers) UnresolveComment(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
@@ -643,17 +1575,243 @@ func (h *NotesHandlers) GetPagePermissions(c *gin.Context) {
 	}
 
 	pageID := c.Param("page_id")
+	commentID := c.Param("comment_id")
 
-	permissions, err := h.pageService.GetPagePermissions(c.Request.Context(), userID.(int64), pageID)
+	comment, err := h.commentService.UnresolveComment(c.Request.Context(), userID.(int64), pageID, commentID)
 	if err != nil {
 		h.handleServiceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": permissions})
+	c.JSON(http.StatusOK, gin.H{"data": comment})
+}
+
+func (h *NotesHandlers) DeleteComment(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	pageID := c.Param("page_id")
+	commentID := c.Param("comment_id")
+
+	if err := h.commentService.DeleteComment(c.Request.Context(), userID.(int64), pageID, commentID); err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted successfully"})
+}
+
+// Notification Handlers
+
+func (h *NotesHandlers) GetNotifications(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	unreadOnly := c.Query("unread") == "true"
+
+	notifications, err := h.notificationService.GetNotifications(c.Request.Context(), userID.(int64), unreadOnly, limit, offset)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": notifications})
+}
+
+func (h *NotesHandlers) MarkNotificationRead(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	notificationID := c.Param("id")
+
+	if err := h.notificationService.MarkNotificationRead(c.Request.Context(), userID.(int64), notificationID); err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notification marked as read"})
+}
+
+func (h *NotesHandlers) GetNotificationPreferences(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	preferences, err := h.notificationService.GetPreferences(c.Request.Context(), userID.(int64))
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": preferences})
+}
+
+func (h *NotesHandlers) UpdateNotificationPreferences(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req services.UpdateNotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	preferences, err := h.notificationService.UpdatePreferences(c.Request.Context(), userID.(int64), &req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": preferences})
 }
 
 // Helper method to handle service errors
+// Embedding Handlers
+
+func (h *NotesHandlers) CreateViewerToken(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	pageID := c.Param("page_id")
+
+	var req services.CreateViewerTokenRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+			return
+		}
+	}
+
+	token, err := h.viewerTokenService.CreateViewerToken(c.Request.Context(), userID.(int64), pageID, time.Duration(req.TTLSeconds)*time.Second)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": token})
+}
+
+// GetEmbeddedPage serves a page read-only; ViewerTokenMiddleware has already
+// checked that the request carries a valid token for this page
+func (h *NotesHandlers) GetEmbeddedPage(c *gin.Context) {
+	pageID := c.Param("page_id")
+
+	page, err := h.pageService.GetPageForViewer(c.Request.Context(), pageID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": page})
+}
+
+// Share Link Handlers
+
+func (h *NotesHandlers) CreateShareLink(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	pageID := c.Param("page_id")
+
+	var req services.ShareLinkRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+			return
+		}
+	}
+
+	link, err := h.shareLinkService.CreateShareLink(c.Request.Context(), userID.(int64), pageID, &req)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": link})
+}
+
+func (h *NotesHandlers) GetShareLinks(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	pageID := c.Param("page_id")
+
+	links, err := h.shareLinkService.ListShareLinks(c.Request.Context(), userID.(int64), pageID)
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": links})
+}
+
+func (h *NotesHandlers) RevokeShareLink(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	pageID := c.Param("page_id")
+	linkID := c.Param("link_id")
+
+	if err := h.shareLinkService.RevokeShareLink(c.Request.Context(), userID.(int64), pageID, linkID); err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Share link revoked successfully"})
+}
+
+// GetSharedPage serves a page to anyone holding a valid share link token.
+// Password-protected links take the password in the X-Share-Password header
+// so it stays out of URLs and access logs.
+func (h *NotesHandlers) GetSharedPage(c *gin.Context) {
+	token := c.Param("token")
+
+	page, err := h.shareLinkService.GetSharedPage(c.Request.Context(), token, c.GetHeader("X-Share-Password"))
+	if err != nil {
+		h.handleServiceError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{"data": page})
+}
+
 func (h *NotesHandlers) handleServiceError(c *gin.Context, err error) {
 	if appErr, ok := errors.AsAppError(err); ok {
 		switch appErr.Code {
@@ -670,7 +1828,11 @@ func (h *NotesHandlers) handleServiceError(c *gin.Context, err error) {
 		case errors.AuthorizationError:
 			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
 		case errors.ConflictError:
-			c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+			if appErr.Details != nil && appErr.Details != "" {
+				c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message, "details": appErr.Details})
+			} else {
+				c.JSON(appErr.StatusCode, gin.H{"error": appErr.Message})
+			}
 		default:
 			h.logger.Error("Internal server error", "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
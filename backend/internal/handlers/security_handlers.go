@@ -1,9 +1,10 @@
 package handlers
 
 import (
-	"fmt"
 	"net/http"
+	"strings"
 
+	"github.com/Srivathsav-max/lumen/backend/internal/constants"
 	"github.com/Srivathsav-max/lumen/backend/internal/security"
 	"github.com/gin-gonic/gin"
 )
@@ -18,22 +19,34 @@ func NewSecurityHandlers(csrfService *security.CSRFService) *SecurityHandlers {
 	}
 }
 
+// GetCSRFToken issues a CSRF token bound to the caller's session. Signed-in
+// callers get one tied to the session in their access token; anyone else
+// gets an anonymous session cookie, and tokens for it are rejected on
+// authenticated requests.
 func (h *SecurityHandlers) GetCSRFToken(c *gin.Context) {
-	sessionID := c.GetString("session_id")
-
+	var sessionID string
 	var userID int64
-	if userIDValue, exists := c.Get("userID"); exists {
-		if uid, ok := userIDValue.(int64); ok {
-			userID = uid
+	if claims, exists := c.Get("token_claims"); exists {
+		if jwtClaims, ok := claims.(*security.SecureJWTClaims); ok {
+			sessionID = jwtClaims.SessionID
+			userID = jwtClaims.UserID
 		}
 	}
 
-	if sessionID == "" && userID > 0 {
-		sessionID = fmt.Sprintf("session_%d", userID)
-	}
-
-	if sessionID == "" {
-		sessionID = "anonymous_" + c.ClientIP()
+	anonymous := sessionID == ""
+	if anonymous {
+		if cookie, err := c.Cookie(constants.CSRFSessionCookieName); err == nil && strings.HasPrefix(cookie, security.AnonymousSessionPrefix) {
+			sessionID = cookie
+		} else {
+			newSessionID, err := security.NewAnonymousSessionID()
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to generate CSRF token",
+				})
+				return
+			}
+			sessionID = newSessionID
+		}
 	}
 
 	csrfToken, err := h.csrfService.GenerateToken(sessionID, userID, c.Request)
@@ -54,6 +67,9 @@ func (h *SecurityHandlers) GetCSRFToken(c *gin.Context) {
 		return
 	}
 
+	if anonymous {
+		h.csrfService.SetSessionCookie(c.Writer, sessionID, csrfToken.ExpiresAt)
+	}
 	h.csrfService.SetCSRFCookie(c.Writer, csrfToken)
 
 	c.JSON(http.StatusOK, gin.H{
@@ -3,7 +3,12 @@ package handlers
 import (
 	"context"
 	"net/http"
+	"sync"
+	"time"
 
+	"github.com/Srivathsav-max/lumen/backend/internal/authz"
+	"github.com/Srivathsav-max/lumen/backend/internal/constants"
+	"github.com/Srivathsav-max/lumen/backend/internal/database"
 	"github.com/Srivathsav-max/lumen/backend/internal/errors"
 	"github.com/Srivathsav-max/lumen/backend/internal/services"
 	"github.com/gin-gonic/gin"
@@ -11,21 +16,30 @@ import (
 
 type SystemHandlers struct {
 	systemSettingsService services.SystemSettingsService
+	dbHealth              *database.HealthChecker
+	ai                    services.AIService
+
+	// The AI check calls an external API, so its result is cached
+	aiHealthMu      sync.Mutex
+	aiHealth        gin.H
+	aiHealthChecked time.Time
 }
 
-func NewSystemHandlers(systemSettingsService services.SystemSettingsService) *SystemHandlers {
+func NewSystemHandlers(systemSettingsService services.SystemSettingsService, dbHealth *database.HealthChecker, ai services.AIService) *SystemHandlers {
 	return &SystemHandlers{
 		systemSettingsService: systemSettingsService,
+		dbHealth:              dbHealth,
+		ai:                    ai,
 	}
 }
 
 func (h *SystemHandlers) GetAllSettings(c *gin.Context) {
-	if !h.isAdmin(c) {
+	if !authz.HasRole(c, constants.RoleAdmin) {
 		c.Error(errors.NewAuthorizationError("Admin access required"))
 		return
 	}
 
-	ctx := context.Background()
+	ctx := c.Request.Context()
 
 	settings, err := h.systemSettingsService.GetAllSettings(ctx)
 	if err != nil {
@@ -46,7 +60,7 @@ func (h *SystemHandlers) GetSetting(c *gin.Context) {
 		return
 	}
 
-	ctx := context.Background()
+	ctx := c.Request.Context()
 
 	setting, err := h.systemSettingsService.GetSetting(ctx, key)
 	if err != nil {
@@ -61,7 +75,7 @@ func (h *SystemHandlers) GetSetting(c *gin.Context) {
 }
 
 func (h *SystemHandlers) UpdateSetting(c *gin.Context) {
-	if !h.isAdmin(c) {
+	if !authz.HasRole(c, constants.RoleAdmin) {
 		c.Error(errors.NewAuthorizationError("Admin access required"))
 		return
 	}
@@ -81,7 +95,7 @@ func (h *SystemHandlers) UpdateSetting(c *gin.Context) {
 		return
 	}
 
-	ctx := context.Background()
+	ctx := c.Request.Context()
 
 	setReq := &services.SetSettingRequest{
 		Key:   key,
@@ -99,7 +113,7 @@ func (h *SystemHandlers) UpdateSetting(c *gin.Context) {
 }
 
 func (h *SystemHandlers) GetMaintenanceStatus(c *gin.Context) {
-	ctx := context.Background()
+	ctx := c.Request.Context()
 
 	isEnabled, err := h.systemSettingsService.IsMaintenanceModeEnabled(ctx)
 	if err != nil {
@@ -116,7 +130,7 @@ func (h *SystemHandlers) GetMaintenanceStatus(c *gin.Context) {
 }
 
 func (h *SystemHandlers) EnableMaintenanceMode(c *gin.Context) {
-	if !h.isAdmin(c) {
+	if !authz.HasRole(c, constants.RoleAdmin) {
 		c.Error(errors.NewAuthorizationError("Admin access required"))
 		return
 	}
@@ -127,7 +141,7 @@ func (h *SystemHandlers) EnableMaintenanceMode(c *gin.Context) {
 
 	c.ShouldBindJSON(&req)
 
-	ctx := context.Background()
+	ctx := c.Request.Context()
 
 	if err := h.systemSettingsService.EnableMaintenanceMode(ctx, req.Message); err != nil {
 		c.Error(err)
@@ -140,12 +154,12 @@ func (h *SystemHandlers) EnableMaintenanceMode(c *gin.Context) {
 }
 
 func (h *SystemHandlers) DisableMaintenanceMode(c *gin.Context) {
-	if !h.isAdmin(c) {
+	if !authz.HasRole(c, constants.RoleAdmin) {
 		c.Error(errors.NewAuthorizationError("Admin access required"))
 		return
 	}
 
-	ctx := context.Background()
+	ctx := c.Request.Context()
 
 	if err := h.systemSettingsService.DisableMaintenanceMode(ctx); err != nil {
 		c.Error(err)
@@ -158,7 +172,7 @@ func (h *SystemHandlers) DisableMaintenanceMode(c *gin.Context) {
 }
 
 func (h *SystemHandlers) GetRegistrationStatus(c *gin.Context) {
-	ctx := context.Background()
+	ctx := c.Request.Context()
 
 	setting, err := h.systemSettingsService.GetSetting(ctx, "registration_enabled")
 	if err != nil {
@@ -182,7 +196,7 @@ func (h *SystemHandlers) GetRegistrationStatus(c *gin.Context) {
 }
 
 func (h *SystemHandlers) ToggleRegistrationStatus(c *gin.Context) {
-	if !h.isAdmin(c) {
+	if !authz.HasRole(c, constants.RoleAdmin) {
 		c.Error(errors.NewAuthorizationError("Admin access required"))
 		return
 	}
@@ -196,7 +210,7 @@ func (h *SystemHandlers) ToggleRegistrationStatus(c *gin.Context) {
 		return
 	}
 
-	ctx := context.Background()
+	ctx := c.Request.Context()
 
 	setReq := &services.SetSettingRequest{
 		Key:   "registration_enabled",
@@ -221,22 +235,61 @@ func (h *SystemHandlers) ToggleRegistrationStatus(c *gin.Context) {
 	})
 }
 
+// HealthCheck is the readiness probe. It answers 503 when the database is
+// unreachable; the AI provider is reported but is not critical. Liveness is
+// served separately by /ping.
 func (h *SystemHandlers) HealthCheck(c *gin.Context) {
+	checks := gin.H{}
+	healthy := true
+
+	if h.dbHealth != nil {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), constants.HealthCheckDatabaseTimeout)
+		dbResult, err := h.dbHealth.ReadinessCheck(ctx)
+		cancel()
+		checks["database"] = dbResult
+		if err != nil {
+			healthy = false
+		}
+	}
+
+	if h.ai != nil {
+		checks["ai"] = h.checkAI(c.Request.Context())
+	}
+
+	if !healthy {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status":  "unavailable",
+			"message": "A critical dependency is down",
+			"checks":  checks,
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"status":  "ok",
 		"message": "Service is healthy",
+		"checks":  checks,
 	})
 }
 
-func (h *SystemHandlers) isAdmin(c *gin.Context) bool {
-	if roles, exists := c.Get("userRoles"); exists {
-		if roleSlice, ok := roles.([]string); ok {
-			for _, role := range roleSlice {
-				if role == "admin" {
-					return true
-				}
-			}
-		}
+func (h *SystemHandlers) checkAI(ctx context.Context) gin.H {
+	h.aiHealthMu.Lock()
+	defer h.aiHealthMu.Unlock()
+
+	if h.aiHealth != nil && time.Since(h.aiHealthChecked) < constants.HealthCheckAICacheTTL {
+		return h.aiHealth
 	}
-	return false
+
+	ctx, cancel := context.WithTimeout(ctx, constants.HealthCheckAITimeout)
+	defer cancel()
+
+	result := gin.H{"status": "healthy"}
+	if err := h.ai.Ping(ctx); err != nil {
+		result = gin.H{"status": "unhealthy", "error": "AI provider unreachable"}
+	}
+	result["checked_at"] = time.Now().UTC()
+
+	h.aiHealth = result
+	h.aiHealthChecked = time.Now()
+	return result
 }
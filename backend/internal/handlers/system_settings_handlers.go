@@ -1,10 +1,10 @@
 package handlers
 
 import (
-	"context"
 	"net/http"
-	"strconv"
 
+	"github.com/Srivathsav-max/lumen/backend/internal/authz"
+	"github.com/Srivathsav-max/lumen/backend/internal/constants"
 	"github.com/Srivathsav-max/lumen/backend/internal/container"
 	"github.com/Srivathsav-max/lumen/backend/internal/services"
 	"github.com/gin-gonic/gin"
@@ -21,7 +21,7 @@ func NewSystemSettingsHandlers(container *container.Container) *SystemSettingsHa
 }
 
 func (h *SystemSettingsHandlers) GetRegistrationStatus(c *gin.Context) {
-	ctx := context.Background()
+	ctx := c.Request.Context()
 	logger := h.container.GetLogger()
 	systemSettingsService := h.container.GetSystemSettingsService()
 
@@ -51,11 +51,11 @@ type ToggleRegistrationStatusRequest struct {
 }
 
 func (h *SystemSettingsHandlers) ToggleRegistrationStatus(c *gin.Context) {
-	ctx := context.Background()
+	ctx := c.Request.Context()
 	logger := h.container.GetLogger()
 	systemSettingsService := h.container.GetSystemSettingsService()
 
-	if !h.isAdminOrDeveloper(c) {
+	if !authz.HasRole(c, constants.RoleDeveloper) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized: Admin or developer role required"})
 		return
 	}
@@ -90,11 +90,11 @@ func (h *SystemSettingsHandlers) ToggleRegistrationStatus(c *gin.Context) {
 }
 
 func (h *SystemSettingsHandlers) GetAllSystemSettings(c *gin.Context) {
-	ctx := context.Background()
+	ctx := c.Request.Context()
 	logger := h.container.GetLogger()
 	systemSettingsService := h.container.GetSystemSettingsService()
 
-	if !h.isAdminOrDeveloper(c) {
+	if !authz.HasRole(c, constants.RoleDeveloper) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized: Admin or developer role required"})
 		return
 	}
@@ -116,11 +116,11 @@ type UpdateSystemSettingRequest struct {
 }
 
 func (h *SystemSettingsHandlers) UpdateSystemSetting(c *gin.Context) {
-	ctx := context.Background()
+	ctx := c.Request.Context()
 	logger := h.container.GetLogger()
 	systemSettingsService := h.container.GetSystemSettingsService()
 
-	if !h.isAdminOrDeveloper(c) {
+	if !authz.HasRole(c, constants.RoleDeveloper) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized: Admin or developer role required"})
 		return
 	}
@@ -158,54 +158,3 @@ func (h *SystemSettingsHandlers) UpdateSystemSetting(c *gin.Context) {
 		"value":   req.Value,
 	})
 }
-
-func (h *SystemSettingsHandlers) isAdminOrDeveloper(c *gin.Context) bool {
-	userIDInterface, exists := c.Get("userID")
-	if !exists {
-		return false
-	}
-
-	var userID int64
-	switch v := userIDInterface.(type) {
-	case int64:
-		userID = v
-	case int:
-		userID = int64(v)
-	case string:
-		parsedID, err := strconv.ParseInt(v, 10, 64)
-		if err != nil {
-			return false
-		}
-		userID = parsedID
-	default:
-		return false
-	}
-
-	roles, exists := c.Get("userRoles")
-	if exists {
-		roleNames, ok := roles.([]string)
-		if ok {
-			for _, role := range roleNames {
-				if role == "admin" || role == "developer" {
-					return true
-				}
-			}
-		}
-	}
-
-	roleService := h.container.GetRoleService()
-	if roleService != nil {
-		ctx := context.Background()
-		isAdmin, err := roleService.HasRole(ctx, userID, "admin")
-		if err == nil && isAdmin {
-			return true
-		}
-
-		isDeveloper, err := roleService.HasRole(ctx, userID, "developer")
-		if err == nil && isDeveloper {
-			return true
-		}
-	}
-
-	return false
-}
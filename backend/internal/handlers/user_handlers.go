@@ -1,12 +1,10 @@
 package handlers
 
 import (
-	"context"
-	"crypto/rand"
-	"math/big"
 	"net/http"
 	"strconv"
 
+	"github.com/Srivathsav-max/lumen/backend/internal/authz"
 	"github.com/Srivathsav-max/lumen/backend/internal/constants"
 	"github.com/Srivathsav-max/lumen/backend/internal/errors"
 	"github.com/Srivathsav-max/lumen/backend/internal/services"
@@ -34,7 +32,7 @@ func (h *UserHandlers) GetProfile(c *gin.Context) {
 		return
 	}
 
-	ctx := context.Background()
+	ctx := c.Request.Context()
 
 	user, err := h.userService.GetProfile(ctx, userID.(int64))
 	if err != nil {
@@ -72,7 +70,7 @@ func (h *UserHandlers) UpdateProfile(c *gin.Context) {
 		return
 	}
 
-	ctx := context.Background()
+	ctx := c.Request.Context()
 
 	if err := h.userService.UpdateProfile(ctx, userID.(int64), &req); err != nil {
 		c.Error(err)
@@ -99,7 +97,7 @@ func (h *UserHandlers) GetUserByID(c *gin.Context) {
 		return
 	}
 
-	ctx := context.Background()
+	ctx := c.Request.Context()
 
 	user, err := h.userService.GetByID(ctx, userID)
 	if err != nil {
@@ -107,20 +105,8 @@ func (h *UserHandlers) GetUserByID(c *gin.Context) {
 		return
 	}
 
-	isAdmin := false
-	if roles, exists := c.Get("user_roles"); exists {
-		if roleSlice, ok := roles.([]string); ok {
-			for _, role := range roleSlice {
-				if role == constants.RoleAdmin {
-					isAdmin = true
-					break
-				}
-			}
-		}
-	}
-
 	var response interface{}
-	if isAdmin {
+	if authz.HasRole(c, constants.RoleAdmin) {
 		response = user
 	} else {
 		response = gin.H{
@@ -138,25 +124,6 @@ func (h *UserHandlers) GetUserByID(c *gin.Context) {
 	})
 }
 
-func (h *UserHandlers) VerifyEmail(c *gin.Context) {
-	userID, exists := c.Get("userID")
-	if !exists {
-		c.Error(errors.NewAuthenticationError("User not authenticated"))
-		return
-	}
-
-	ctx := context.Background()
-
-	if err := h.userService.VerifyEmail(ctx, userID.(int64)); err != nil {
-		c.Error(err)
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Email verified successfully",
-	})
-}
-
 func (h *UserHandlers) CheckEmailVerification(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
@@ -164,7 +131,7 @@ func (h *UserHandlers) CheckEmailVerification(c *gin.Context) {
 		return
 	}
 
-	ctx := context.Background()
+	ctx := c.Request.Context()
 
 	isVerified, err := h.userService.IsEmailVerified(ctx, userID.(int64))
 	if err != nil {
@@ -181,7 +148,7 @@ func (h *UserHandlers) CheckEmailVerification(c *gin.Context) {
 }
 
 func (h *UserHandlers) GetUsersByRole(c *gin.Context) {
-	if !h.isAdmin(c) {
+	if !authz.HasRole(c, constants.RoleAdmin) {
 		c.Error(errors.NewAuthorizationError("Admin access required"))
 		return
 	}
@@ -195,19 +162,6 @@ func (h *UserHandlers) GetUsersByRole(c *gin.Context) {
 	c.Error(errors.NewInternalError("Feature not implemented"))
 }
 
-func (h *UserHandlers) isAdmin(c *gin.Context) bool {
-	if roles, exists := c.Get("user_roles"); exists {
-		if roleSlice, ok := roles.([]string); ok {
-			for _, role := range roleSlice {
-				if role == constants.RoleAdmin {
-					return true
-				}
-			}
-		}
-	}
-	return false
-}
-
 func (h *UserHandlers) getCurrentUserID(c *gin.Context) (int64, error) {
 	userID, exists := c.Get("userID")
 	if !exists {
@@ -228,27 +182,13 @@ func (h *UserHandlers) RequestPasswordChangeOTP(c *gin.Context) {
 		return
 	}
 
-	ctx := context.Background()
+	ctx := c.Request.Context()
 
-	user, err := h.userService.GetByID(ctx, userID.(int64))
-	if err != nil {
+	if err := h.authService.RequestPasswordChangeOTP(ctx, userID.(int64)); err != nil {
 		c.Error(err)
 		return
 	}
 
-	if user == nil {
-		c.Error(errors.NewNotFoundError("User not found"))
-		return
-	}
-
-	otp, err := generateOTP(6)
-	if err != nil {
-		c.Error(errors.NewInternalError("Failed to generate OTP"))
-		return
-	}
-
-	c.SetCookie("password_change_otp", otp, 600, "/", "", false, true)
-
 	c.JSON(http.StatusOK, gin.H{
 		"message": "OTP has been sent to your email",
 	})
@@ -263,7 +203,7 @@ func (h *UserHandlers) ChangePassword(c *gin.Context) {
 
 	var req struct {
 		CurrentPassword string `json:"current_password" binding:"required"`
-		NewPassword     string `json:"new_password" binding:"required,min=8"`
+		NewPassword     string `json:"new_password" binding:"required"`
 		OTP             string `json:"otp" binding:"required"`
 	}
 
@@ -272,20 +212,7 @@ func (h *UserHandlers) ChangePassword(c *gin.Context) {
 		return
 	}
 
-	cookieOTP, err := c.Cookie("password_change_otp")
-	if err != nil {
-		c.Error(errors.NewValidationError("Invalid or expired OTP", "Please request a new OTP"))
-		return
-	}
-
-	if cookieOTP != req.OTP {
-		c.Error(errors.NewValidationError("Invalid OTP", "Please try again"))
-		return
-	}
-
-	c.SetCookie("password_change_otp", "", -1, "/", "", false, true)
-
-	ctx := context.Background()
+	ctx := c.Request.Context()
 
 	changePasswordReq := services.ChangePasswordRequest{
 		CurrentPassword: req.CurrentPassword,
@@ -293,7 +220,7 @@ func (h *UserHandlers) ChangePassword(c *gin.Context) {
 		ConfirmPassword: req.NewPassword,
 	}
 
-	if err := h.authService.ChangePassword(ctx, userID.(int64), &changePasswordReq); err != nil {
+	if err := h.authService.ChangePasswordWithOTP(ctx, userID.(int64), &changePasswordReq, req.OTP); err != nil {
 		c.Error(err)
 		return
 	}
@@ -302,16 +229,3 @@ func (h *UserHandlers) ChangePassword(c *gin.Context) {
 		"message": "Password changed successfully",
 	})
 }
-
-func generateOTP(length int) (string, error) {
-	digits := "0123456789"
-	otp := make([]byte, length)
-	for i := range otp {
-		num, err := rand.Int(rand.Reader, big.NewInt(int64(len(digits))))
-		if err != nil {
-			return "", err
-		}
-		otp[i] = digits[num.Int64()]
-	}
-	return string(otp), nil
-}
@@ -1,10 +1,10 @@
 package handlers
 
 import (
-	"context"
 	"net/http"
 	"strconv"
 
+	"github.com/Srivathsav-max/lumen/backend/internal/authz"
 	"github.com/Srivathsav-max/lumen/backend/internal/constants"
 	"github.com/Srivathsav-max/lumen/backend/internal/errors"
 	"github.com/Srivathsav-max/lumen/backend/internal/services"
@@ -28,7 +28,7 @@ func (h *WaitlistHandlers) JoinWaitlist(c *gin.Context) {
 		return
 	}
 
-	ctx := context.Background()
+	ctx := c.Request.Context()
 
 	if err := h.waitlistService.AddToWaitlist(ctx, &req); err != nil {
 		c.Error(err)
@@ -47,7 +47,7 @@ func (h *WaitlistHandlers) GetWaitlistPosition(c *gin.Context) {
 		return
 	}
 
-	ctx := context.Background()
+	ctx := c.Request.Context()
 
 	position, err := h.waitlistService.GetWaitlistPosition(ctx, email)
 	if err != nil {
@@ -62,7 +62,7 @@ func (h *WaitlistHandlers) GetWaitlistPosition(c *gin.Context) {
 }
 
 func (h *WaitlistHandlers) GetWaitlistEntries(c *gin.Context) {
-	if !h.isAdmin(c) {
+	if !authz.HasRole(c, constants.RoleAdmin) {
 		c.Error(errors.NewAuthorizationError("Admin access required"))
 		return
 	}
@@ -92,7 +92,7 @@ func (h *WaitlistHandlers) GetWaitlistEntries(c *gin.Context) {
 		req.Search = search
 	}
 
-	ctx := context.Background()
+	ctx := c.Request.Context()
 
 	entries, err := h.waitlistService.GetWaitlistEntries(ctx, req)
 	if err != nil {
@@ -107,7 +107,7 @@ func (h *WaitlistHandlers) GetWaitlistEntries(c *gin.Context) {
 }
 
 func (h *WaitlistHandlers) ApproveWaitlistEntry(c *gin.Context) {
-	if !h.isAdmin(c) {
+	if !authz.HasRole(c, constants.RoleAdmin) {
 		c.Error(errors.NewAuthorizationError("Admin access required"))
 		return
 	}
@@ -121,7 +121,7 @@ func (h *WaitlistHandlers) ApproveWaitlistEntry(c *gin.Context) {
 		return
 	}
 
-	ctx := context.Background()
+	ctx := c.Request.Context()
 
 	if err := h.waitlistService.ApproveWaitlistEntry(ctx, req.Email); err != nil {
 		c.Error(err)
@@ -134,7 +134,7 @@ func (h *WaitlistHandlers) ApproveWaitlistEntry(c *gin.Context) {
 }
 
 func (h *WaitlistHandlers) RemoveFromWaitlist(c *gin.Context) {
-	if !h.isAdmin(c) {
+	if !authz.HasRole(c, constants.RoleAdmin) {
 		c.Error(errors.NewAuthorizationError("Admin access required"))
 		return
 	}
@@ -145,7 +145,7 @@ func (h *WaitlistHandlers) RemoveFromWaitlist(c *gin.Context) {
 		return
 	}
 
-	ctx := context.Background()
+	ctx := c.Request.Context()
 
 	if err := h.waitlistService.RemoveFromWaitlist(ctx, email); err != nil {
 		c.Error(err)
@@ -158,7 +158,7 @@ func (h *WaitlistHandlers) RemoveFromWaitlist(c *gin.Context) {
 }
 
 func (h *WaitlistHandlers) UpdateWaitlistStatus(c *gin.Context) {
-	if !h.isAdmin(c) {
+	if !authz.HasRole(c, constants.RoleAdmin) {
 		c.Error(errors.NewAuthorizationError("Admin access required"))
 		return
 	}
@@ -180,16 +180,3 @@ func (h *WaitlistHandlers) UpdateWaitlistStatus(c *gin.Context) {
 
 	c.Error(errors.NewInternalError("Feature not implemented"))
 }
-
-func (h *WaitlistHandlers) isAdmin(c *gin.Context) bool {
-	if roles, exists := c.Get("userRoles"); exists {
-		if roleSlice, ok := roles.([]string); ok {
-			for _, role := range roleSlice {
-				if role == constants.RoleAdmin {
-					return true
-				}
-			}
-		}
-	}
-	return false
-}
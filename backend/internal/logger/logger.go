@@ -6,6 +6,8 @@ import (
 	"os"
 	"strings"
 	"time"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/constants"
 )
 
 type LogLevel string
@@ -62,6 +64,18 @@ func NewDefault() *slog.Logger {
 	})
 }
 
+// ContextWithRequestID stores the request ID on ctx so code below the HTTP
+// layer can tag its logs with it
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, constants.ContextKeyRequestID, requestID)
+}
+
+// RequestIDFromContext returns the request ID stored on ctx, if any
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(constants.ContextKeyRequestID).(string)
+	return requestID
+}
+
 type ContextLogger struct {
 	*slog.Logger
 	ctx context.Context
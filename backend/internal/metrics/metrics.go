@@ -0,0 +1,237 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are latency buckets in seconds suited to API handlers
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// labelSeparator joins label values into a map key; it cannot appear in UTF-8 text
+const labelSeparator = "\xff"
+
+// Registry holds metrics and renders them in the Prometheus text exposition
+// format. It is a small stand-in for the Prometheus client library covering
+// counters, histograms and gauges read on scrape.
+type Registry struct {
+	mu         sync.Mutex
+	counters   []*CounterVec
+	histograms []*HistogramVec
+	gauges     []*gaugeFunc
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// CounterVec is a monotonically increasing counter partitioned by labels
+type CounterVec struct {
+	name       string
+	help       string
+	labelNames []string
+
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+// HistogramVec counts observations into cumulative buckets, partitioned by labels
+type HistogramVec struct {
+	name       string
+	help       string
+	labelNames []string
+	buckets    []float64
+
+	mu     sync.Mutex
+	values map[string]*histogramValue
+}
+
+type histogramValue struct {
+	counts []uint64 // per bucket, not cumulative
+	sum    float64
+	count  uint64
+}
+
+type gaugeFunc struct {
+	name string
+	help string
+	fn   func() float64
+}
+
+func (r *Registry) NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	c := &CounterVec{name: name, help: help, labelNames: labelNames, values: make(map[string]float64)}
+	r.mu.Lock()
+	r.counters = append(r.counters, c)
+	r.mu.Unlock()
+	return c
+}
+
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+	h := &HistogramVec{name: name, help: help, labelNames: labelNames, buckets: sorted, values: make(map[string]*histogramValue)}
+	r.mu.Lock()
+	r.histograms = append(r.histograms, h)
+	r.mu.Unlock()
+	return h
+}
+
+// NewGaugeFunc registers a gauge whose value is read from fn on every scrape
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64) {
+	r.mu.Lock()
+	r.gauges = append(r.gauges, &gaugeFunc{name: name, help: help, fn: fn})
+	r.mu.Unlock()
+}
+
+// Inc adds one to the series identified by labelValues, given in the order
+// the label names were declared
+func (c *CounterVec) Inc(labelValues ...string) {
+	key := strings.Join(labelValues, labelSeparator)
+	c.mu.Lock()
+	c.values[key]++
+	c.mu.Unlock()
+}
+
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	key := strings.Join(labelValues, labelSeparator)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	v, ok := h.values[key]
+	if !ok {
+		v = &histogramValue{counts: make([]uint64, len(h.buckets))}
+		h.values[key] = v
+	}
+	for i, upper := range h.buckets {
+		if value <= upper {
+			v.counts[i]++
+			break
+		}
+	}
+	v.sum += value
+	v.count++
+}
+
+// Handler serves the registry's metrics
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = r.WriteText(w)
+	})
+}
+
+// WriteText renders all metrics in the Prometheus text format
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	counters := append([]*CounterVec(nil), r.counters...)
+	histograms := append([]*HistogramVec(nil), r.histograms...)
+	gauges := append([]*gaugeFunc(nil), r.gauges...)
+	r.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	for _, c := range counters {
+		c.write(bw)
+	}
+	for _, h := range histograms {
+		h.write(bw)
+	}
+	for _, g := range gauges {
+		writeHeader(bw, g.name, g.help, "gauge")
+		fmt.Fprintf(bw, "%s %s\n", g.name, formatFloat(g.fn()))
+	}
+	return bw.Flush()
+}
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	writeHeader(w, c.name, c.help, "counter")
+	for _, key := range sortedKeys(c.values) {
+		labels := formatLabels(c.labelNames, splitKey(key), "", "")
+		fmt.Fprintf(w, "%s%s %s\n", c.name, labels, formatFloat(c.values[key]))
+	}
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	writeHeader(w, h.name, h.help, "histogram")
+	for _, key := range sortedKeys(h.values) {
+		v := h.values[key]
+		values := splitKey(key)
+
+		var cumulative uint64
+		for i, upper := range h.buckets {
+			cumulative += v.counts[i]
+			labels := formatLabels(h.labelNames, values, "le", formatFloat(upper))
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, labels, cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(h.labelNames, values, "le", "+Inf"), v.count)
+
+		labels := formatLabels(h.labelNames, values, "", "")
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, labels, formatFloat(v.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, labels, v.count)
+	}
+}
+
+func writeHeader(w *bufio.Writer, name, help, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, kind)
+}
+
+// formatLabels renders {a="x",b="y"}, optionally with one extra label such as le
+func formatLabels(names, values []string, extraName, extraValue string) string {
+	var parts []string
+	for i, name := range names {
+		value := ""
+		if i < len(values) {
+			value = values[i]
+		}
+		parts = append(parts, name+`="`+escapeLabelValue(value)+`"`)
+	}
+	if extraName != "" {
+		parts = append(parts, extraName+`="`+extraValue+`"`)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func splitKey(key string) []string {
+	return strings.Split(key, labelSeparator)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"math"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func render(t *testing.T, r *Registry) string {
+	t.Helper()
+	var out strings.Builder
+	if err := r.WriteText(&out); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+	return out.String()
+}
+
+func assertGolden(t *testing.T, got, want string) {
+	t.Helper()
+	want = strings.TrimLeft(want, "\n")
+	if got != want {
+		t.Errorf("exposition mismatch\n--- got ---\n%s--- want ---\n%s", got, want)
+	}
+}
+
+func TestCounterExposition(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounterVec("http_requests_total", "Total HTTP requests.", "method", "status")
+	requests.Inc("POST", "201")
+	requests.Inc("GET", "200")
+	requests.Inc("GET", "200")
+	r.NewCounterVec("jobs_total", "Jobs run.").Inc()
+
+	assertGolden(t, render(t, r), `
+# HELP http_requests_total Total HTTP requests.
+# TYPE http_requests_total counter
+http_requests_total{method="GET",status="200"} 2
+http_requests_total{method="POST",status="201"} 1
+# HELP jobs_total Jobs run.
+# TYPE jobs_total counter
+jobs_total 1
+`)
+}
+
+func TestHistogramExposition(t *testing.T) {
+	r := NewRegistry()
+	latency := r.NewHistogramVec("request_seconds", "Request latency.", []float64{1, 0.1, 0.5}, "route")
+	for _, v := range []float64{0.05, 0.1, 0.3, 2} {
+		latency.Observe(v, "/pages")
+	}
+	r.NewHistogramVec("unlabelled_seconds", "No labels.", []float64{1}).Observe(0.5)
+
+	assertGolden(t, render(t, r), `
+# HELP request_seconds Request latency.
+# TYPE request_seconds histogram
+request_seconds_bucket{route="/pages",le="0.1"} 2
+request_seconds_bucket{route="/pages",le="0.5"} 3
+request_seconds_bucket{route="/pages",le="1"} 3
+request_seconds_bucket{route="/pages",le="+Inf"} 4
+request_seconds_sum{route="/pages"} 2.45
+request_seconds_count{route="/pages"} 4
+# HELP unlabelled_seconds No labels.
+# TYPE unlabelled_seconds histogram
+unlabelled_seconds_bucket{le="1"} 1
+unlabelled_seconds_bucket{le="+Inf"} 1
+unlabelled_seconds_sum 0.5
+unlabelled_seconds_count 1
+`)
+}
+
+func TestLabelAndHelpEscaping(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounterVec("escaped_total", "Backslash \\ and\nnewline.", "path").Inc("C:\\tmp\n\"quoted\"")
+
+	assertGolden(t, render(t, r), `
+# HELP escaped_total Backslash \\ and\nnewline.
+# TYPE escaped_total counter
+escaped_total{path="C:\\tmp\n\"quoted\""} 1
+`)
+}
+
+func TestGaugeFuncExposition(t *testing.T) {
+	r := NewRegistry()
+	r.NewGaugeFunc("open_connections", "Open connections.", func() float64 { return 3 })
+	r.NewGaugeFunc("headroom", "Unbounded.", func() float64 { return math.Inf(1) })
+
+	assertGolden(t, render(t, r), `
+# HELP open_connections Open connections.
+# TYPE open_connections gauge
+open_connections 3
+# HELP headroom Unbounded.
+# TYPE headroom gauge
+headroom +Inf
+`)
+}
+
+func TestHandlerContentType(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounterVec("jobs_total", "Jobs run.").Inc()
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	if got := rec.Header().Get("Content-Type"); got != "text/plain; version=0.0.4; charset=utf-8" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	if !strings.Contains(rec.Body.String(), "jobs_total 1\n") {
+		t.Errorf("body = %q", rec.Body.String())
+	}
+}
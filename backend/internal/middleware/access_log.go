@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccessLogConfig controls the per-request access log
+type AccessLogConfig struct {
+	// Requests taking at least this long are logged at Warn; zero disables it
+	SlowRequestThreshold time.Duration
+	SkipPaths            []string
+}
+
+// DefaultAccessLogSkipPaths are probe and scrape endpoints that would
+// otherwise drown out real traffic
+var DefaultAccessLogSkipPaths = []string{"/health", "/ping", "/metrics"}
+
+// AccessLogMiddleware writes one structured line per request once it has
+// been handled. It must run after RequestIDMiddleware so the request ID is set.
+func AccessLogMiddleware(config AccessLogConfig, logger *slog.Logger) gin.HandlerFunc {
+	skip := make(map[string]struct{}, len(config.SkipPaths))
+	for _, path := range config.SkipPaths {
+		skip[path] = struct{}{}
+	}
+
+	return func(c *gin.Context) {
+		if _, ok := skip[c.Request.URL.Path]; ok {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+
+		c.Next()
+
+		duration := time.Since(start)
+		status := c.Writer.Status()
+
+		// Size is -1 until something is written
+		bytesWritten := c.Writer.Size()
+		if bytesWritten < 0 {
+			bytesWritten = 0
+		}
+
+		attrs := []slog.Attr{
+			slog.String("request_id", getRequestIDFromContext(c)),
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Duration("duration", duration),
+			slog.Int64("duration_ms", duration.Milliseconds()),
+			slog.Int64("request_bytes", max(c.Request.ContentLength, 0)),
+			slog.Int("bytes_written", bytesWritten),
+			slog.String("client_ip", c.ClientIP()),
+		}
+
+		if userID, exists := c.Get("userID"); exists {
+			attrs = append(attrs, slog.Any("user_id", userID))
+		}
+
+		slow := config.SlowRequestThreshold > 0 && duration >= config.SlowRequestThreshold
+
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case slow:
+			level = slog.LevelWarn
+			attrs = append(attrs, slog.Bool("slow", true))
+		}
+
+		logger.LogAttrs(c.Request.Context(), level, "HTTP request", attrs...)
+	}
+}
@@ -2,10 +2,10 @@ package middleware
 
 import (
 	"context"
-	"fmt"
 	"log/slog"
 	"strings"
 
+	"github.com/Srivathsav-max/lumen/backend/internal/authz"
 	"github.com/Srivathsav-max/lumen/backend/internal/constants"
 	"github.com/Srivathsav-max/lumen/backend/internal/errors"
 	"github.com/Srivathsav-max/lumen/backend/internal/services"
@@ -27,7 +27,7 @@ func AuthMiddleware(authService services.AuthService, logger *slog.Logger) gin.H
 			return
 		}
 
-		ctx := context.Background()
+		ctx := withFingerprintCookie(c.Request.Context(), c)
 
 		claims, err := authService.ValidateAccessToken(ctx, token)
 		if err != nil {
@@ -49,14 +49,7 @@ func AuthMiddleware(authService services.AuthService, logger *slog.Logger) gin.H
 
 		c.Set("userRoles", claims.Roles)
 
-		isAdmin := false
-		for _, roleName := range claims.Roles {
-			if roleName == constants.RoleAdmin {
-				isAdmin = true
-				break
-			}
-		}
-		c.Set("isAdmin", isAdmin)
+		c.Set("isAdmin", authz.RolesGrant(claims.Roles, constants.RoleAdmin))
 
 		logger.Debug("User authenticated successfully",
 			"request_id", requestID,
@@ -71,102 +64,6 @@ func AuthMiddleware(authService services.AuthService, logger *slog.Logger) gin.H
 	}
 }
 
-func AdminRequiredMiddleware(logger *slog.Logger) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		requestID := getRequestIDFromContext(c)
-		userID, _ := c.Get("userID")
-
-		isAdmin, exists := c.Get("isAdmin")
-		if !exists || !isAdmin.(bool) {
-			logger.Warn("Admin access denied",
-				"request_id", requestID,
-				"user_id", userID,
-				"path", c.Request.URL.Path,
-				"method", c.Request.Method,
-				"ip", c.ClientIP(),
-			)
-			handleAuthError(c, errors.NewAuthorizationError("Admin access required"))
-			return
-		}
-
-		logger.Debug("Admin access granted",
-			"request_id", requestID,
-			"user_id", userID,
-			"path", c.Request.URL.Path,
-			"method", c.Request.Method,
-		)
-
-		c.Next()
-	}
-}
-
-func RoleRequiredMiddleware(logger *slog.Logger, requiredRoles ...string) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		requestID := getRequestIDFromContext(c)
-		userID, _ := c.Get("userID")
-
-		userRoles, exists := c.Get("userRoles")
-		if !exists {
-			logger.Warn("User roles not found in context",
-				"request_id", requestID,
-				"user_id", userID,
-				"path", c.Request.URL.Path,
-				"method", c.Request.Method,
-			)
-			handleAuthError(c, errors.NewAuthorizationError("User roles not found"))
-			return
-		}
-
-		roleSlice, ok := userRoles.([]string)
-		if !ok {
-			logger.Error("Invalid user roles type in context",
-				"request_id", requestID,
-				"user_id", userID,
-				"roles_type", fmt.Sprintf("%T", userRoles),
-			)
-			handleAuthError(c, errors.NewAuthorizationError("Invalid user roles"))
-			return
-		}
-
-		hasRequiredRole := false
-		for _, userRole := range roleSlice {
-			for _, requiredRole := range requiredRoles {
-				if userRole == requiredRole {
-					hasRequiredRole = true
-					break
-				}
-			}
-			if hasRequiredRole {
-				break
-			}
-		}
-
-		if !hasRequiredRole {
-			logger.Warn("Insufficient permissions",
-				"request_id", requestID,
-				"user_id", userID,
-				"user_roles", roleSlice,
-				"required_roles", requiredRoles,
-				"path", c.Request.URL.Path,
-				"method", c.Request.Method,
-			)
-			handleAuthError(c, errors.NewAuthorizationError("Insufficient permissions"))
-			return
-		}
-
-		logger.Debug("Role authorization successful",
-			"request_id", requestID,
-			"user_id", userID,
-			"user_roles", roleSlice,
-			"required_roles", requiredRoles,
-			"path", c.Request.URL.Path,
-			"method", c.Request.Method,
-		)
-
-		c.Next()
-	}
-}
-
 func OptionalAuthMiddleware(authService services.AuthService, logger *slog.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		token := extractToken(c)
@@ -175,7 +72,7 @@ func OptionalAuthMiddleware(authService services.AuthService, logger *slog.Logge
 			return
 		}
 
-		ctx := context.Background()
+		ctx := withFingerprintCookie(c.Request.Context(), c)
 
 		claims, err := authService.ValidateAccessToken(ctx, token)
 		if err != nil {
@@ -191,14 +88,7 @@ func OptionalAuthMiddleware(authService services.AuthService, logger *slog.Logge
 
 		c.Set("userRoles", claims.Roles)
 
-		isAdmin := false
-		for _, roleName := range claims.Roles {
-			if roleName == constants.RoleAdmin {
-				isAdmin = true
-				break
-			}
-		}
-		c.Set("isAdmin", isAdmin)
+		c.Set("isAdmin", authz.RolesGrant(claims.Roles, constants.RoleAdmin))
 
 		c.Next()
 	}
@@ -222,6 +112,12 @@ func extractToken(c *gin.Context) string {
 	return ""
 }
 
+// withFingerprintCookie passes the fingerprint cookie, if any, on to token validation
+func withFingerprintCookie(ctx context.Context, c *gin.Context) context.Context {
+	fingerprint, _ := c.Cookie(constants.FingerprintCookieName)
+	return services.WithFingerprint(ctx, fingerprint)
+}
+
 func clearAuthCookies(c *gin.Context) {
 	// For local development, don't require secure cookies
 	isSecure := c.Request.Header.Get("X-Forwarded-Proto") == "https" || c.Request.TLS != nil
@@ -231,6 +127,7 @@ func clearAuthCookies(c *gin.Context) {
 	c.SetCookie(constants.SessionIDCookieName, "", -1, "/", "", isSecure, true)
 	c.SetCookie(constants.UserIDCookieName, "", -1, "/", "", isSecure, false)
 	c.SetCookie(constants.UserRolesCookieName, "", -1, "/", "", isSecure, false)
+	c.SetCookie(constants.FingerprintCookieName, "", -1, "/", "", isSecure, true)
 }
 
 func handleAuthError(c *gin.Context, err error) {
@@ -251,28 +148,24 @@ func GetCurrentUserID(c *gin.Context) (int64, error) {
 	return 0, errors.NewInternalError("Invalid user ID in context")
 }
 
-func IsCurrentUserAdmin(c *gin.Context) bool {
-	isAdmin, exists := c.Get("isAdmin")
-	if !exists {
-		return false
-	}
-
-	if admin, ok := isAdmin.(bool); ok {
-		return admin
-	}
-
-	return false
-}
+// ViewerTokenMiddleware authorizes read-only embed requests carrying a viewer
+// token (?token=) that is scoped to the requested :page_id
+func ViewerTokenMiddleware(viewerTokens services.ViewerTokenService, logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		pageID := c.Param("page_id")
 
-func GetCurrentUserRoles(c *gin.Context) []string {
-	roles, exists := c.Get("userRoles")
-	if !exists {
-		return []string{}
-	}
+		if err := viewerTokens.ValidateViewerToken(c.Request.Context(), c.Query("token"), pageID); err != nil {
+			logger.Warn("Viewer token rejected",
+				"request_id", getRequestIDFromContext(c),
+				"page_id", pageID,
+				"error", err,
+				"ip", c.ClientIP(),
+			)
+			handleAuthError(c, err)
+			return
+		}
 
-	if roleSlice, ok := roles.([]string); ok {
-		return roleSlice
+		c.Set("viewerPageID", pageID)
+		c.Next()
 	}
-
-	return []string{}
 }
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/metrics"
+)
+
+// unmatchedRoute labels requests that hit no route, so unknown paths cannot
+// create unbounded label values
+const unmatchedRoute = "unmatched"
+
+// MetricsMiddleware records request counts and latencies per route template
+// (e.g. /api/v1/notes/pages/:page_id) rather than per raw path
+func MetricsMiddleware(registry *metrics.Registry) gin.HandlerFunc {
+	requests := registry.NewCounterVec("http_requests_total",
+		"Total HTTP requests by method, route and status code.", "method", "route", "status")
+	durations := registry.NewHistogramVec("http_request_duration_seconds",
+		"HTTP request latency by method and route.", metrics.DefaultBuckets, "method", "route")
+
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		requests.Inc(c.Request.Method, route, strconv.Itoa(c.Writer.Status()))
+		durations.Observe(time.Since(start).Seconds(), c.Request.Method, route)
+	}
+}
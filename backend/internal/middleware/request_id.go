@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"log/slog"
 
+	applogger "github.com/Srivathsav-max/lumen/backend/internal/logger"
 	"github.com/gin-gonic/gin"
 )
 
@@ -18,6 +19,7 @@ func RequestIDMiddleware(logger *slog.Logger) gin.HandlerFunc {
 
 		c.Set("request_id", requestID)
 		c.Header("X-Request-ID", requestID)
+		c.Request = c.Request.WithContext(applogger.ContextWithRequestID(c.Request.Context(), requestID))
 
 		contextLogger := logger.With("request_id", requestID)
 		c.Set("logger", contextLogger)
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TimeoutConfig controls the deadline put on each request's context
+type TimeoutConfig struct {
+	// Timeout is the deadline for a request; zero disables it
+	Timeout time.Duration
+	// SkipRoutes are route templates, as returned by gin's FullPath, that
+	// manage their own deadlines
+	SkipRoutes []string
+}
+
+// DefaultTimeoutSkipRoutes are the AI routes, which wait on a slow upstream
+// with its own client timeouts and would otherwise be cut off mid-answer
+var DefaultTimeoutSkipRoutes = []string{"/api/v1/ai/generate", "/api/v1/ai/chat/stream"}
+
+// TimeoutMiddleware sets a deadline on the request context. Services and
+// queries running under it are cancelled once it passes, just as they are
+// when the client disconnects.
+func TimeoutMiddleware(config TimeoutConfig, logger *slog.Logger) gin.HandlerFunc {
+	skip := make(map[string]struct{}, len(config.SkipRoutes))
+	for _, route := range config.SkipRoutes {
+		skip[route] = struct{}{}
+	}
+
+	return func(c *gin.Context) {
+		if config.Timeout <= 0 {
+			c.Next()
+			return
+		}
+		if _, ok := skip[c.FullPath()]; ok {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), config.Timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		if ctx.Err() == context.DeadlineExceeded {
+			logger.Warn("Request exceeded its deadline",
+				"request_id", getRequestIDFromContext(c),
+				"method", c.Request.Method,
+				"path", c.Request.URL.Path,
+				"timeout", config.Timeout,
+			)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/constants"
+)
+
+const Version = "3.0.3"
+
+// Operation describes one route. Request and Response are zero values of the
+// Go types the handler binds and returns, so renaming or removing a DTO breaks
+// the build instead of silently leaving the spec stale.
+type Operation struct {
+	ID      string
+	Method  string
+	Path    string // gin syntax, e.g. /api/v1/notes/pages/:page_id
+	Tag     string
+	Summary string
+	// Auth marks routes behind the JWT middleware
+	Auth  bool
+	Query []Param
+	// Request is the JSON body type; nil for routes without a body
+	Request any
+	// Response is the payload returned under "data"; nil for message-only responses
+	Response any
+	// RawResponse replaces the data envelope for handlers with their own shape
+	RawResponse any
+	// NextCursor adds the next_cursor field of cursor-paginated lists
+	NextCursor bool
+	// ContentType of the success response; defaults to application/json
+	ContentType string
+	// Status is the success status code; defaults to 200
+	Status int
+}
+
+// Object documents a free-form JSON object, for handlers that respond with
+// ad hoc maps rather than a DTO
+type Object map[string]any
+
+// Param is a query string parameter
+type Param struct {
+	Name        string
+	Type        string // string, integer or boolean
+	Description string
+	Required    bool
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Document struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       Info                            `json:"info"`
+	Paths      map[string]map[string]*pathItem `json:"paths"`
+	Components components                      `json:"components"`
+}
+
+type components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*securityScheme `json:"securitySchemes"`
+}
+
+type securityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+}
+
+type pathItem struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []parameter           `json:"parameters,omitempty"`
+	RequestBody *requestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema"`
+}
+
+type requestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*mediaType `json:"content"`
+}
+
+type response struct {
+	Description string                `json:"description"`
+	Content     map[string]*mediaType `json:"content,omitempty"`
+}
+
+type mediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Build assembles the document. errorResponse is the error body shared by all
+// failure responses.
+func Build(info Info, operations []Operation, errorResponse any) (*Document, error) {
+	gen := newGenerator()
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   make(map[string]map[string]*pathItem),
+		Components: components{
+			Schemas: gen.schemas,
+			SecuritySchemes: map[string]*securityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+				"cookieAuth": {Type: "apiKey", In: "cookie", Name: constants.AccessTokenCookieName},
+			},
+		},
+	}
+
+	errorSchema := gen.schemaFor(errorResponse)
+	seenIDs := make(map[string]string)
+
+	for _, op := range operations {
+		key := op.Method + " " + op.Path
+		if op.ID == "" {
+			return nil, fmt.Errorf("%s: missing operation ID", key)
+		}
+		if previous, ok := seenIDs[op.ID]; ok {
+			return nil, fmt.Errorf("%s: operation ID %q already used by %s", key, op.ID, previous)
+		}
+		seenIDs[op.ID] = key
+
+		path, pathParams := convertPath(op.Path)
+		method := strings.ToLower(op.Method)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(map[string]*pathItem)
+		}
+		if _, exists := doc.Paths[path][method]; exists {
+			return nil, fmt.Errorf("%s: documented twice", key)
+		}
+
+		item := &pathItem{
+			OperationID: op.ID,
+			Summary:     op.Summary,
+			Responses:   make(map[string]*response),
+		}
+		if op.Tag != "" {
+			item.Tags = []string{op.Tag}
+		}
+		if op.Auth {
+			item.Security = []map[string][]string{{"bearerAuth": {}}, {"cookieAuth": {}}}
+		}
+
+		for _, name := range pathParams {
+			item.Parameters = append(item.Parameters, parameter{
+				Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"},
+			})
+		}
+		for _, q := range op.Query {
+			paramType := q.Type
+			if paramType == "" {
+				paramType = "string"
+			}
+			item.Parameters = append(item.Parameters, parameter{
+				Name: q.Name, In: "query", Description: q.Description, Required: q.Required, Schema: &Schema{Type: paramType},
+			})
+		}
+
+		if op.Request != nil {
+			item.RequestBody = &requestBody{
+				Required: true,
+				Content:  map[string]*mediaType{"application/json": {Schema: gen.schemaFor(op.Request)}},
+			}
+		}
+
+		status := op.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		contentType := op.ContentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		item.Responses[fmt.Sprint(status)] = &response{
+			Description: http.StatusText(status),
+			Content:     map[string]*mediaType{contentType: {Schema: gen.successSchema(op)}},
+		}
+		item.Responses["default"] = &response{
+			Description: "Error",
+			Content:     map[string]*mediaType{"application/json": {Schema: errorSchema}},
+		}
+
+		doc.Paths[path][method] = item
+	}
+
+	return doc, nil
+}
+
+// successSchema wraps the payload in the {"message", "data"} envelope the
+// handlers respond with
+func (g *generator) successSchema(op Operation) *Schema {
+	if op.RawResponse != nil {
+		return g.schemaFor(op.RawResponse)
+	}
+
+	envelope := &Schema{
+		Type:       "object",
+		Properties: map[string]*Schema{"message": {Type: "string"}},
+	}
+	if op.Response != nil {
+		envelope.Properties["data"] = g.schemaFor(op.Response)
+	}
+	if op.NextCursor {
+		envelope.Properties["next_cursor"] = &Schema{Type: "string", Nullable: true}
+	}
+	return envelope
+}
+
+// convertPath turns /pages/:page_id into /pages/{page_id} and returns the
+// parameter names in order
+func convertPath(ginPath string) (string, []string) {
+	segments := strings.Split(ginPath, "/")
+	var params []string
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			name := segment[1:]
+			params = append(params, name)
+			segments[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// Coverage compares registered routes against the documented operations and
+// returns the routes missing from the spec and the operations with no route,
+// each as "METHOD /path"
+func Coverage(operations []Operation, registered [][2]string) (undocumented, unregistered []string) {
+	documented := make(map[string]bool, len(operations))
+	for _, op := range operations {
+		documented[op.Method+" "+op.Path] = true
+	}
+
+	seen := make(map[string]bool, len(registered))
+	for _, route := range registered {
+		key := route[0] + " " + route[1]
+		seen[key] = true
+		if !documented[key] {
+			undocumented = append(undocumented, key)
+		}
+	}
+
+	for key := range documented {
+		if !seen[key] {
+			unregistered = append(unregistered, key)
+		}
+	}
+
+	sort.Strings(undocumented)
+	sort.Strings(unregistered)
+	return undocumented, unregistered
+}
@@ -0,0 +1,50 @@
+package openapi
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"html/template"
+	"net/http"
+)
+
+const uiAssetOrigin = "https://unpkg.com"
+
+var uiTemplate = template.Must(template.New("swagger-ui").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>{{.Title}}</title>
+  <link rel="stylesheet" href="{{.AssetOrigin}}/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="{{.AssetOrigin}}/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+  <script nonce="{{.Nonce}}">
+    window.onload = function () {
+      window.ui = SwaggerUIBundle({ url: {{.SpecURL}}, dom_id: "#swagger-ui" });
+    };
+  </script>
+</body>
+</html>
+`))
+
+// UIHandler serves a Swagger UI page that loads the spec from specURL. The
+// page replaces the API's Content-Security-Policy with one allowing the UI
+// assets and its single inline script.
+func UIHandler(title, specURL string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		nonceBytes := make([]byte, 16)
+		if _, err := rand.Read(nonceBytes); err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		nonce := base64.StdEncoding.EncodeToString(nonceBytes)
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Security-Policy", "default-src 'self'; script-src "+uiAssetOrigin+" 'nonce-"+nonce+"'; "+
+			"style-src "+uiAssetOrigin+"; img-src 'self' data: "+uiAssetOrigin+"; connect-src 'self'")
+		w.Header().Del("Content-Security-Policy-Report-Only")
+
+		_ = uiTemplate.Execute(w, struct{ Title, SpecURL, AssetOrigin, Nonce string }{title, specURL, uiAssetOrigin, nonce})
+	})
+}
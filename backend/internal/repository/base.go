@@ -11,6 +11,7 @@ import (
 
 	"github.com/Srivathsav-max/lumen/backend/internal/database"
 	"github.com/Srivathsav-max/lumen/backend/internal/errors"
+	"github.com/Srivathsav-max/lumen/backend/internal/logger"
 )
 
 type BaseRepository struct {
@@ -61,6 +62,7 @@ func (r *BaseRepository) ExecuteQuery(ctx context.Context, query string, args ..
 			"query", query,
 			"error", err,
 			"table", r.table,
+			"request_id", logger.RequestIDFromContext(ctx),
 		)
 		return nil, errors.NewDatabaseError("Query execution failed", err)
 	}
@@ -97,6 +99,7 @@ func (r *BaseRepository) ExecuteExec(ctx context.Context, query string, args ...
 			"query", query,
 			"error", err,
 			"table", r.table,
+			"request_id", logger.RequestIDFromContext(ctx),
 		)
 		return nil, errors.NewDatabaseError("Exec execution failed", err)
 	}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Cursor marks a position in a list ordered by updated_at DESC, id DESC.
+// Clients only ever see it in its encoded, opaque form.
+type Cursor struct {
+	UpdatedAt time.Time `json:"u"`
+	ID        string    `json:"i"`
+}
+
+// CursorAfter returns the cursor pointing just past the given page
+func CursorAfter(page *Page) *Cursor {
+	return &Cursor{UpdatedAt: page.UpdatedAt, ID: page.ID}
+}
+
+// Encode returns the URL-safe token handed to clients as next_cursor
+func (c *Cursor) Encode() string {
+	// Marshalling a struct of a time and a string cannot fail
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a token produced by Encode. An empty token yields a nil
+// cursor, meaning the first page.
+func DecodeCursor(token string) (*Cursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor encoding: %w", err)
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+
+	if cursor.ID == "" || cursor.UpdatedAt.IsZero() {
+		return nil, fmt.Errorf("invalid cursor: missing position")
+	}
+
+	return &cursor, nil
+}
@@ -18,6 +18,16 @@ type User struct {
 	UpdatedAt     time.Time `db:"updated_at" json:"updated_at"`
 }
 
+// UserIdentity links a user to an account at an external login provider
+type UserIdentity struct {
+	ID        int64     `db:"id" json:"id"`
+	UserID    int64     `db:"user_id" json:"user_id"`
+	Provider  string    `db:"provider" json:"provider"`
+	Subject   string    `db:"subject" json:"subject"`
+	Email     string    `db:"email" json:"email"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
 type Role struct {
 	ID          int64     `db:"id" json:"id"`
 	Name        string    `db:"name" json:"name"`
@@ -27,13 +37,16 @@ type Role struct {
 }
 
 type Token struct {
-	ID         int64     `db:"id" json:"id"`
-	UserID     int64     `db:"user_id" json:"user_id"`
-	Token      string    `db:"refresh_token" json:"refresh_token"`
-	DeviceInfo string    `db:"device_info" json:"device_info"`
-	ExpiresAt  time.Time `db:"expires_at" json:"expires_at"`
-	CreatedAt  time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt  time.Time `db:"updated_at" json:"updated_at"`
+	ID         int64      `db:"id" json:"id"`
+	UserID     int64      `db:"user_id" json:"user_id"`
+	Token      string     `db:"refresh_token" json:"refresh_token"`
+	DeviceInfo string     `db:"device_info" json:"device_info"`
+	IPAddress  string     `db:"ip_address" json:"ip_address,omitempty"`
+	FamilyID   string     `db:"family_id" json:"family_id"`
+	RotatedAt  *time.Time `db:"rotated_at" json:"rotated_at,omitempty"`
+	ExpiresAt  time.Time  `db:"expires_at" json:"expires_at"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time  `db:"updated_at" json:"updated_at"`
 }
 
 type VerificationToken struct {
@@ -78,6 +91,11 @@ type UserRepository interface {
 	Count(ctx context.Context) (int64, error)
 }
 
+type UserIdentityRepository interface {
+	Create(ctx context.Context, identity *UserIdentity) error
+	GetByProviderSubject(ctx context.Context, provider, subject string) (*UserIdentity, error)
+}
+
 type RoleRepository interface {
 	Create(ctx context.Context, role *Role) error
 	GetByID(ctx context.Context, id int64) (*Role, error)
@@ -103,6 +121,11 @@ type TokenRepository interface {
 	StoreRefreshToken(ctx context.Context, userID int64, token string, expiresAt time.Time) error
 	ValidateRefreshToken(ctx context.Context, token string) (int64, error)
 	RevokeRefreshToken(ctx context.Context, token string) error
+	MarkRotated(ctx context.Context, tokenString string) (bool, error)
+	// HasRotatedSuccessor reports whether a token issued after tokenID in the
+	// same family has itself been rotated
+	HasRotatedSuccessor(ctx context.Context, familyID string, tokenID int64) (bool, error)
+	RevokeTokenFamily(ctx context.Context, familyID string) error
 }
 
 type VerificationTokenRepository interface {
@@ -114,6 +137,14 @@ type VerificationTokenRepository interface {
 	MarkAsUsed(ctx context.Context, tokenID int64) error
 	DeleteExpiredTokens(ctx context.Context) error
 	DeleteUserTokensByType(ctx context.Context, userID int64, tokenType string) error
+	// InvalidateUserTokensByType marks outstanding tokens as used but keeps
+	// the rows, so they still count towards issuance limits
+	InvalidateUserTokensByType(ctx context.Context, userID int64, tokenType string) error
+	CountCreatedSince(ctx context.Context, userID int64, tokenType string, since time.Time) (int, error)
+	// RecordFailedAttempt counts a wrong guess against an outstanding token
+	// and returns the new count, invalidating the token once it reaches
+	// maxAttempts
+	RecordFailedAttempt(ctx context.Context, tokenID int64, maxAttempts int) (int, error)
 }
 
 type WaitlistRepository interface {
@@ -145,12 +176,15 @@ type SystemSettingsRepository interface {
 // Notes System Models
 
 type Workspace struct {
-	ID          int64     `db:"id" json:"id"`
-	Name        string    `db:"name" json:"name"`
-	Description *string   `db:"description" json:"description,omitempty"`
-	OwnerID     int64     `db:"owner_id" json:"owner_id"`
-	CreatedAt   time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+	ID          int64   `db:"id" json:"id"`
+	Name        string  `db:"name" json:"name"`
+	Description *string `db:"description" json:"description,omitempty"`
+	OwnerID     int64   `db:"owner_id" json:"owner_id"`
+	// DefaultPagePermission is what workspace members get on pages without an
+	// explicit grant. PermissionNone limits access to owners and explicit grants
+	DefaultPagePermission PermissionLevel `db:"default_page_permission" json:"default_page_permission"`
+	CreatedAt             time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt             time.Time       `db:"updated_at" json:"updated_at"`
 }
 
 type WorkspaceRole string
@@ -171,6 +205,51 @@ type WorkspaceMember struct {
 	UpdatedAt   time.Time     `db:"updated_at" json:"updated_at"`
 }
 
+// WorkspaceInvitation is a pending or accepted invitation sent by email.
+// Only the SHA-256 hash of the invitation token is stored.
+type WorkspaceInvitation struct {
+	ID          string        `db:"id" json:"id"`
+	WorkspaceID int64         `db:"workspace_id" json:"workspace_id"`
+	Email       string        `db:"email" json:"email"`
+	Role        WorkspaceRole `db:"role" json:"role"`
+	TokenHash   string        `db:"token_hash" json:"-"`
+	InvitedBy   int64         `db:"invited_by" json:"invited_by"`
+	ExpiresAt   time.Time     `db:"expires_at" json:"expires_at"`
+	AcceptedBy  *int64        `db:"accepted_by" json:"accepted_by,omitempty"`
+	AcceptedAt  *time.Time    `db:"accepted_at" json:"accepted_at,omitempty"`
+	CreatedAt   time.Time     `db:"created_at" json:"created_at"`
+}
+
+// ActivityAction names an event in a workspace's activity log
+type ActivityAction string
+
+const (
+	ActivityPageCreated       ActivityAction = "page.created"
+	ActivityPageUpdated       ActivityAction = "page.updated"
+	ActivityPageArchived      ActivityAction = "page.archived"
+	ActivityPageDeleted       ActivityAction = "page.deleted"
+	ActivityMemberAdded       ActivityAction = "member.added"
+	ActivityMemberRemoved     ActivityAction = "member.removed"
+	ActivityPermissionGranted ActivityAction = "permission.granted"
+	ActivityPermissionRevoked ActivityAction = "permission.revoked"
+)
+
+const (
+	ActivityTargetPage = "page"
+	ActivityTargetUser = "user"
+)
+
+type WorkspaceActivity struct {
+	ID          int64           `db:"id" json:"id"`
+	WorkspaceID int64           `db:"workspace_id" json:"workspace_id"`
+	ActorID     *int64          `db:"actor_id" json:"actor_id,omitempty"` // nil once the actor is deleted
+	Action      ActivityAction  `db:"action" json:"action"`
+	TargetType  string          `db:"target_type" json:"target_type"`
+	TargetID    string          `db:"target_id" json:"target_id"`
+	Metadata    json.RawMessage `db:"metadata" json:"metadata"`
+	CreatedAt   time.Time       `db:"created_at" json:"created_at"`
+}
+
 type Page struct {
 	ID           string          `db:"id" json:"id"`
 	Title        string          `db:"title" json:"title"`
@@ -185,6 +264,64 @@ type Page struct {
 	CreatedAt    time.Time       `db:"created_at" json:"created_at"`
 	UpdatedAt    time.Time       `db:"updated_at" json:"updated_at"`
 	LastEditedBy *int64          `db:"last_edited_by" json:"last_edited_by,omitempty"`
+	DeletedAt    *time.Time      `db:"deleted_at" json:"deleted_at,omitempty"`
+}
+
+// Property filter operators understood by PageRepository.QueryByProperties
+const (
+	PropertyOpEquals         = "eq"
+	PropertyOpNotEquals      = "neq"
+	PropertyOpContains       = "contains"
+	PropertyOpGreaterThan    = "gt"
+	PropertyOpGreaterOrEqual = "gte"
+	PropertyOpLessThan       = "lt"
+	PropertyOpLessOrEqual    = "lte"
+	PropertyOpExists         = "exists"
+)
+
+// PropertyFilter matches pages on one top-level key of their properties.
+// Value is a JSON document for eq, neq and contains, a float64 or time.Time
+// for the range operators, and unused for exists.
+type PropertyFilter struct {
+	Key   string
+	Op    string
+	Value interface{}
+}
+
+// PropertySort orders query results by a property key. Pages without the key
+// sort last; ties fall back to the most recently updated page.
+type PropertySort struct {
+	Key        string
+	Descending bool
+}
+
+// SearchFilters narrow PageRepository.Search beyond the text query. The zero
+// value leaves out archived pages and matches every other page.
+type SearchFilters struct {
+	IncludeArchived bool
+	IsTemplate      *bool
+	OwnerID         *int64
+	UpdatedAfter    *time.Time // Inclusive
+	UpdatedBefore   *time.Time // Exclusive
+}
+
+// PageShareLink grants read-only access to a page to anyone holding its token
+type PageShareLink struct {
+	ID           string     `db:"id" json:"id"`
+	PageID       string     `db:"page_id" json:"page_id"`
+	TokenHash    string     `db:"token_hash" json:"-"`
+	PasswordHash *string    `db:"password_hash" json:"-"`
+	CreatedBy    *int64     `db:"created_by" json:"created_by,omitempty"`
+	ExpiresAt    *time.Time `db:"expires_at" json:"expires_at,omitempty"`
+	RevokedAt    *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+}
+
+// PageTreeEntry is a page returned by PageRepository.GetTree
+type PageTreeEntry struct {
+	Page
+	Depth       int  `db:"depth" json:"depth"`
+	HasChildren bool `db:"has_children" json:"has_children"`
 }
 
 type Block struct {
@@ -200,15 +337,46 @@ type Block struct {
 	LastEditedBy  *int64          `db:"last_edited_by" json:"last_edited_by,omitempty"`
 }
 
+// Search highlight markers are private-use characters, so they cannot clash
+// with markup stored in block text and survive HTML stripping
+const (
+	SearchHighlightStart = "\ue000"
+	SearchHighlightStop  = "\ue001"
+)
+
+// BlockSearchHit is a page's best matching block for a search. Headline is the
+// block data with every string value cut down to a fragment around the match,
+// matched words wrapped in the search highlight markers.
+type BlockSearchHit struct {
+	PageID    string          `db:"page_id" json:"page_id"`
+	BlockID   string          `db:"block_id" json:"block_id"`
+	BlockType string          `db:"block_type" json:"block_type"`
+	Headline  json.RawMessage `db:"headline" json:"headline"`
+}
+
 type PermissionLevel string
 
 const (
+	// PermissionNone is only valid as a workspace default page permission
+	PermissionNone    PermissionLevel = "none"
 	PermissionView    PermissionLevel = "view"
 	PermissionComment PermissionLevel = "comment"
 	PermissionEdit    PermissionLevel = "edit"
 	PermissionAdmin   PermissionLevel = "admin"
 )
 
+var permissionLevelRank = map[PermissionLevel]int{
+	PermissionView:    1,
+	PermissionComment: 2,
+	PermissionEdit:    3,
+	PermissionAdmin:   4,
+}
+
+// Includes reports whether a user holding l may do what required allows
+func (l PermissionLevel) Includes(required PermissionLevel) bool {
+	return permissionLevelRank[l] > 0 && permissionLevelRank[l] >= permissionLevelRank[required]
+}
+
 type PagePermission struct {
 	ID         int64           `db:"id" json:"id"`
 	PageID     string          `db:"page_id" json:"page_id"`
@@ -244,6 +412,53 @@ type Comment struct {
 	UpdatedAt       time.Time  `db:"updated_at" json:"updated_at"`
 }
 
+// Notification types
+const (
+	NotificationTypeMention = "mention"
+)
+
+type Notification struct {
+	ID        string     `db:"id" json:"id"`
+	UserID    int64      `db:"user_id" json:"user_id"`
+	Type      string     `db:"type" json:"type"`
+	ActorID   *int64     `db:"actor_id" json:"actor_id,omitempty"`
+	PageID    *string    `db:"page_id" json:"page_id,omitempty"`
+	CommentID *string    `db:"comment_id" json:"comment_id,omitempty"`
+	IsRead    bool       `db:"is_read" json:"is_read"`
+	ReadAt    *time.Time `db:"read_at" json:"read_at,omitempty"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+}
+
+// NotificationPreferences are a user's notification email switches
+type NotificationPreferences struct {
+	UserID                 int64     `db:"user_id" json:"user_id"`
+	EmailPageAccessGranted bool      `db:"email_page_access_granted" json:"email_page_access_granted"`
+	EmailPageAccessRevoked bool      `db:"email_page_access_revoked" json:"email_page_access_revoked"`
+	EmailNewDeviceLogin    bool      `db:"email_new_device_login" json:"email_new_device_login"`
+	UpdatedAt              time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// DefaultNotificationPreferences applies to users who never saved any:
+// access grants and new device sign-ins are emailed, revocations are not
+func DefaultNotificationPreferences(userID int64) *NotificationPreferences {
+	return &NotificationPreferences{
+		UserID:                 userID,
+		EmailPageAccessGranted: true,
+		EmailPageAccessRevoked: false,
+		EmailNewDeviceLogin:    true,
+	}
+}
+
+// UserDevice is a device a user has signed in from
+type UserDevice struct {
+	ID            int64     `db:"id" json:"id"`
+	UserID        int64     `db:"user_id" json:"user_id"`
+	DeviceLabel   string    `db:"device_label" json:"device_label"`
+	LastIPAddress string    `db:"last_ip_address" json:"last_ip_address,omitempty"`
+	FirstSeenAt   time.Time `db:"first_seen_at" json:"first_seen_at"`
+	LastSeenAt    time.Time `db:"last_seen_at" json:"last_seen_at"`
+}
+
 // AI Chat models
 type AIConversation struct {
 	ID        string    `db:"id" json:"id"`
@@ -255,6 +470,13 @@ type AIConversation struct {
 	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
 }
 
+// AIConversationSummary is a conversation as shown in the history sidebar
+type AIConversationSummary struct {
+	AIConversation
+	MessageCount  int        `db:"message_count" json:"message_count"`
+	LastMessageAt *time.Time `db:"last_message_at" json:"last_message_at,omitempty"`
+}
+
 type AIMessage struct {
 	ID             string          `db:"id" json:"id"`
 	ConversationID string          `db:"conversation_id" json:"conversation_id"`
@@ -264,14 +486,36 @@ type AIMessage struct {
 	CreatedAt      time.Time       `db:"created_at" json:"created_at"`
 }
 
+// AIUsageTotals sums token usage over a period
+type AIUsageTotals struct {
+	PromptTokens     int64 `db:"prompt_tokens" json:"prompt_tokens"`
+	CompletionTokens int64 `db:"completion_tokens" json:"completion_tokens"`
+	RequestCount     int64 `db:"request_count" json:"request_count"`
+}
+
 type AIConversationRepository interface {
 	UpsertConversation(ctx context.Context, userID int64, chatType string, pageID *string, title *string) (*AIConversation, error)
 	GetConversation(ctx context.Context, userID int64, chatType string, pageID *string) (*AIConversation, error)
+	GetByID(ctx context.Context, id string) (*AIConversation, error)
+	UpdateTitle(ctx context.Context, conversationID string, title string) error
+	ListByUser(ctx context.Context, userID int64, limit, offset int) ([]*AIConversationSummary, error)
+	// Delete removes the conversation and its messages, reporting whether it existed
+	Delete(ctx context.Context, conversationID string) (bool, error)
+	DeleteByUser(ctx context.Context, userID int64) (int64, error)
+	DeleteInactiveSince(ctx context.Context, cutoff time.Time) (int64, error)
+	DeleteExceedingPerUser(ctx context.Context, keep int) (int64, error)
 }
 
 type AIMessageRepository interface {
 	CreateMessage(ctx context.Context, msg *AIMessage) error
 	ListMessages(ctx context.Context, conversationID string, limit, offset int) ([]*AIMessage, error)
+	DeleteByConversation(ctx context.Context, conversationID string) (int64, error)
+}
+
+type AIUsageRepository interface {
+	// AddUsage adds to the user's counters for the given day
+	AddUsage(ctx context.Context, userID int64, day time.Time, promptTokens, completionTokens int64) error
+	GetUsageSince(ctx context.Context, userID int64, since time.Time) (*AIUsageTotals, error)
 }
 
 // Repository Interfaces for Notes System
@@ -286,23 +530,90 @@ type WorkspaceRepository interface {
 	GetUserWorkspaces(ctx context.Context, userID int64) ([]*Workspace, error)
 	AddMember(ctx context.Context, member *WorkspaceMember) error
 	RemoveMember(ctx context.Context, workspaceID, userID int64) error
+	// RemoveMemberAndTransferPages removes a departing member in one
+	// transaction, handing their pages to newOwnerID and dropping their page
+	// grants in the workspace. It returns the number of pages transferred.
+	RemoveMemberAndTransferPages(ctx context.Context, workspaceID, userID, newOwnerID int64) (int64, error)
 	GetMembers(ctx context.Context, workspaceID int64) ([]*WorkspaceMember, error)
+	GetMembersPaginated(ctx context.Context, workspaceID int64, limit, offset int) ([]*WorkspaceMember, error)
+	CountMembers(ctx context.Context, workspaceID int64) (int, error)
+	GetMember(ctx context.Context, workspaceID, userID int64) (*WorkspaceMember, error)
 	UpdateMemberRole(ctx context.Context, workspaceID, userID int64, role WorkspaceRole) error
 	HasAccess(ctx context.Context, workspaceID, userID int64) (bool, error)
+	// GetPropertySchema returns nil when the workspace has no property schema
+	GetPropertySchema(ctx context.Context, workspaceID int64) (json.RawMessage, error)
+	SetPropertySchema(ctx context.Context, workspaceID int64, schema json.RawMessage) error
+}
+
+type WorkspaceInvitationRepository interface {
+	Create(ctx context.Context, invitation *WorkspaceInvitation) error
+	// GetByTokenHash returns nil when no invitation matches
+	GetByTokenHash(ctx context.Context, tokenHash string) (*WorkspaceInvitation, error)
+	// MarkAccepted reports false when the invitation was already accepted
+	MarkAccepted(ctx context.Context, id string, userID int64) (bool, error)
+	// DeletePending removes unaccepted invitations for the email in the workspace
+	DeletePending(ctx context.Context, workspaceID int64, email string) error
+}
+
+type ActivityRepository interface {
+	Create(ctx context.Context, activity *WorkspaceActivity) error
+	// ListByWorkspace returns the newest entries first
+	ListByWorkspace(ctx context.Context, workspaceID int64, limit, offset int) ([]*WorkspaceActivity, error)
 }
 
 type PageRepository interface {
 	Create(ctx context.Context, page *Page) error
 	GetByID(ctx context.Context, id string) (*Page, error)
-	GetByWorkspaceID(ctx context.Context, workspaceID int64, includeArchived bool) ([]*Page, error)
-	GetByParentID(ctx context.Context, parentID string, includeArchived bool) ([]*Page, error)
-	GetRootPages(ctx context.Context, workspaceID int64, includeArchived bool) ([]*Page, error)
+	// The listing methods leave out template pages unless includeTemplates is set
+	GetByWorkspaceID(ctx context.Context, workspaceID int64, includeArchived, includeTemplates bool) ([]*Page, error)
+	GetByParentID(ctx context.Context, parentID string, includeArchived, includeTemplates bool) ([]*Page, error)
+	GetRootPages(ctx context.Context, workspaceID int64, includeArchived, includeTemplates bool) ([]*Page, error)
+	GetAncestors(ctx context.Context, id string) ([]*Page, error)
+	// GetTree lists only the pages userID can view
+	GetTree(ctx context.Context, workspaceID int64, userID int64, maxDepth int, includeArchived, includeTemplates bool) ([]*PageTreeEntry, error)
+	// GetTemplates lists the live template pages of a workspace by title
+	GetTemplates(ctx context.Context, workspaceID int64) ([]*Page, error)
+	IsDescendant(ctx context.Context, ancestorID, candidateID string) (bool, error)
+	// GetDescendantIDs maps each listed page to the IDs of its live
+	// descendants at any depth
+	GetDescendantIDs(ctx context.Context, ids []string) (map[string][]string, error)
 	Update(ctx context.Context, page *Page) error
 	Delete(ctx context.Context, id string) error
-	Archive(ctx context.Context, id string, archivedBy int64) error
-	Restore(ctx context.Context, id string, restoredBy int64) error
-	Search(ctx context.Context, workspaceID int64, query string, limit, offset int) ([]*Page, error)
+	GetTrashedByID(ctx context.Context, id string) (*Page, error)
+	// ListTrash lists only the trashed pages userID holds admin on
+	ListTrash(ctx context.Context, workspaceID int64, userID int64) ([]*Page, error)
+	RestoreFromTrash(ctx context.Context, id string, restoredBy int64) error
+	Purge(ctx context.Context, id string) error
+	PurgeExpired(ctx context.Context, olderThan time.Time) (int64, error)
+	// Archive with cascade also archives live descendants, remembering which
+	// ancestor took them down; Restore with cascade brings back only those
+	Archive(ctx context.Context, id string, cascade bool, archivedBy int64) error
+	Restore(ctx context.Context, id string, cascade bool, restoredBy int64) error
+	// BulkDelete and BulkArchive apply to every listed page atomically, so
+	// either the whole batch is applied or none of it
+	BulkDelete(ctx context.Context, ids []string) (int64, error)
+	BulkArchive(ctx context.Context, ids []string, includeDescendants bool, archivedBy int64) (int64, error)
+	// Search ranks by text relevance, or lists the most recently updated pages
+	// first when query is empty
+	Search(ctx context.Context, workspaceID int64, userID int64, query string, filters SearchFilters, limit, offset int) ([]*Page, error)
+	SearchCount(ctx context.Context, workspaceID int64, userID int64, query string, filters SearchFilters) (int64, error)
+	// QueryByProperties lists live, non-template pages the user can view whose
+	// properties match every filter. A nil sort orders by last update
+	QueryByProperties(ctx context.Context, workspaceID int64, userID int64, filters []PropertyFilter, sort *PropertySort, limit, offset int) ([]*Page, error)
+	CountByProperties(ctx context.Context, workspaceID int64, userID int64, filters []PropertyFilter) (int64, error)
+	HasSiblingWithTitle(ctx context.Context, workspaceID int64, parentID *string, title string) (bool, error)
 	GetRecentPages(ctx context.Context, userID int64, limit int) ([]*Page, error)
+	// The *After variants page through results in updated_at DESC, id DESC
+	// order, starting after the cursor (or at the top when it is nil)
+	GetByWorkspaceIDAfter(ctx context.Context, workspaceID int64, userID int64, includeArchived, includeTemplates bool, after *Cursor, limit int) ([]*Page, error)
+	GetRecentPagesAfter(ctx context.Context, userID int64, after *Cursor, limit int) ([]*Page, error)
+	SearchAfter(ctx context.Context, workspaceID int64, userID int64, query string, filters SearchFilters, after *Cursor, limit int) ([]*Page, error)
+	AddFavorite(ctx context.Context, userID int64, pageID string) error
+	RemoveFavorite(ctx context.Context, userID int64, pageID string) error
+	ListFavorites(ctx context.Context, userID int64) ([]*Page, error)
+	GetOrphanedPages(ctx context.Context, workspaceID int64) ([]*Page, error)
+	ReparentOrphanedPages(ctx context.Context, workspaceID int64, newParentID *string, repairedBy int64) (int64, error)
+	Move(ctx context.Context, id string, newParentID *string, newWorkspaceID int64, movedBy int64) error
 	CreateVersion(ctx context.Context, version *PageVersion) error
 	GetVersions(ctx context.Context, pageID string, limit, offset int) ([]*PageVersion, error)
 	GetVersion(ctx context.Context, pageID string, versionNumber int) (*PageVersion, error)
@@ -310,7 +621,19 @@ type PageRepository interface {
 	GrantPermission(ctx context.Context, permission *PagePermission) error
 	RevokePermission(ctx context.Context, pageID string, userID int64) error
 	ListPermissions(ctx context.Context, pageID string) ([]*PagePermission, error)
+	ListPermissionsPaginated(ctx context.Context, pageID string, limit, offset int) ([]*PagePermission, error)
+	// HasPermission treats trashed pages as gone: nobody has any access to them
 	HasPermission(ctx context.Context, pageID string, userID int64, requiredLevel PermissionLevel) (bool, error)
+	// HasTrashPermission is HasPermission for a page in the trash, for
+	// restoring or purging it
+	HasTrashPermission(ctx context.Context, pageID string, userID int64, requiredLevel PermissionLevel) (bool, error)
+	// GetUserPermissionLevels resolves the user's effective level on each page
+	// the same way HasPermission does. Pages the user cannot access, including
+	// trashed ones, are absent
+	GetUserPermissionLevels(ctx context.Context, userID int64, pageIDs []string) (map[string]PermissionLevel, error)
+	// CountChildren counts live, unarchived children per parent. Parents
+	// without children are absent
+	CountChildren(ctx context.Context, parentIDs []string) (map[string]int, error)
 }
 
 type BlockRepository interface {
@@ -325,6 +648,10 @@ type BlockRepository interface {
 	BulkDelete(ctx context.Context, ids []string) error
 	ReorderBlocks(ctx context.Context, pageID string, blockOrders map[string]int) error
 	GetBlocksByType(ctx context.Context, pageID string, blockType string) ([]*Block, error)
+	GetLeadingBlocks(ctx context.Context, pageIDs []string, limit int) (map[string][]*Block, error)
+	// SearchHits finds the best matching block of each page for a full-text
+	// query. Pages without a matching block are absent
+	SearchHits(ctx context.Context, pageIDs []string, query string) (map[string]*BlockSearchHit, error)
 }
 
 type CommentRepository interface {
@@ -338,4 +665,36 @@ type CommentRepository interface {
 	Resolve(ctx context.Context, id string, resolvedBy int64) error
 	Unresolve(ctx context.Context, id string) error
 	GetUnresolved(ctx context.Context, pageID string) ([]*Comment, error)
+	AddMentions(ctx context.Context, commentID string, userIDs []int64) error
+}
+
+type NotificationRepository interface {
+	Create(ctx context.Context, notification *Notification) error
+	ListByUser(ctx context.Context, userID int64, unreadOnly bool, limit, offset int) ([]*Notification, error)
+	MarkRead(ctx context.Context, id string, userID int64) (bool, error)
+}
+
+type PageShareLinkRepository interface {
+	Create(ctx context.Context, link *PageShareLink) error
+	// GetByTokenHash returns nil when no link has the token, including revoked ones
+	GetByTokenHash(ctx context.Context, tokenHash string) (*PageShareLink, error)
+	// ListByPage returns the page's unrevoked links, newest first
+	ListByPage(ctx context.Context, pageID string) ([]*PageShareLink, error)
+	// Revoke reports false when the link does not exist on the page or was
+	// already revoked
+	Revoke(ctx context.Context, pageID, id string) (bool, error)
+}
+
+type NotificationPreferenceRepository interface {
+	// Get returns DefaultNotificationPreferences for users without saved ones
+	Get(ctx context.Context, userID int64) (*NotificationPreferences, error)
+	Upsert(ctx context.Context, preferences *NotificationPreferences) error
+}
+
+type UserDeviceRepository interface {
+	// HasAny reports whether any device was recorded for the user
+	HasAny(ctx context.Context, userID int64) (bool, error)
+	// Touch records a sign-in from the device and reports whether it had
+	// not been seen before
+	Touch(ctx context.Context, userID int64, deviceLabel, ipAddress string) (bool, error)
 }
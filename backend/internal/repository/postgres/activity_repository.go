@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/database"
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+type ActivityRepository struct {
+	*repository.BaseRepository
+}
+
+func NewActivityRepository(db database.Manager, logger *slog.Logger) repository.ActivityRepository {
+	return &ActivityRepository{
+		BaseRepository: repository.NewBaseRepository(db, logger, "workspace_activity"),
+	}
+}
+
+func (r *ActivityRepository) Create(ctx context.Context, activity *repository.WorkspaceActivity) error {
+	query := `
+		INSERT INTO workspace_activity (workspace_id, actor_id, action, target_type, target_id, metadata, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id`
+
+	activity.CreatedAt = time.Now().UTC()
+	metadata := activity.Metadata
+	if len(metadata) == 0 {
+		metadata = []byte("{}")
+	}
+
+	row := r.ExecuteQueryRow(ctx, query,
+		activity.WorkspaceID,
+		activity.ActorID,
+		activity.Action,
+		activity.TargetType,
+		activity.TargetID,
+		metadata,
+		activity.CreatedAt,
+	)
+
+	if err := row.Scan(&activity.ID); err != nil {
+		return r.HandleSQLError(err, "create workspace activity")
+	}
+
+	return nil
+}
+
+func (r *ActivityRepository) ListByWorkspace(ctx context.Context, workspaceID int64, limit, offset int) ([]*repository.WorkspaceActivity, error) {
+	query := `
+		SELECT id, workspace_id, actor_id, action, target_type, target_id, metadata, created_at
+		FROM workspace_activity
+		WHERE workspace_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.ExecuteQuery(ctx, query, workspaceID, limit, offset)
+	if err != nil {
+		return nil, r.HandleSQLError(err, "list workspace activity")
+	}
+	defer rows.Close()
+
+	var activities []*repository.WorkspaceActivity
+	for rows.Next() {
+		activity := &repository.WorkspaceActivity{}
+		err := rows.Scan(
+			&activity.ID,
+			&activity.WorkspaceID,
+			&activity.ActorID,
+			&activity.Action,
+			&activity.TargetType,
+			&activity.TargetID,
+			&activity.Metadata,
+			&activity.CreatedAt,
+		)
+		if err != nil {
+			return nil, r.HandleSQLError(err, "scan workspace activity")
+		}
+		activities = append(activities, activity)
+	}
+
+	return activities, nil
+}
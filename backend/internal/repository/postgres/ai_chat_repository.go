@@ -27,7 +27,7 @@ func (r *AIConversationRepository) UpsertConversation(ctx context.Context, userI
         INSERT INTO ai_conversations (id, user_id, type, page_id, title, created_at, updated_at)
         VALUES ($1, $2, $3, $4, $5, $6, $6)
         ON CONFLICT (user_id, type, page_id)
-        DO UPDATE SET title = COALESCE($5, ai_conversations.title), updated_at = $6
+        DO UPDATE SET title = COALESCE(ai_conversations.title, $5), updated_at = $6
         RETURNING id, user_id, type, page_id, title, created_at, updated_at`
 
 	id := uuid.New().String()
@@ -86,6 +86,165 @@ func (r *AIConversationRepository) GetConversation(ctx context.Context, userID i
 	return conv, nil
 }
 
+// GetByID returns the conversation, or nil when it does not exist
+func (r *AIConversationRepository) GetByID(ctx context.Context, id string) (*repository.AIConversation, error) {
+	query := `SELECT id, user_id, type, page_id, title, created_at, updated_at FROM ai_conversations WHERE id = $1`
+	row := r.ExecuteQueryRow(ctx, query, id)
+	conv := &repository.AIConversation{}
+	var nullablePageID sql.NullString
+	var nullableTitle sql.NullString
+	if err := row.Scan(&conv.ID, &conv.UserID, &conv.Type, &nullablePageID, &nullableTitle, &conv.CreatedAt, &conv.UpdatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, r.HandleSQLError(err, "get ai conversation by id")
+	}
+	if nullablePageID.Valid {
+		v := nullablePageID.String
+		conv.PageID = &v
+	}
+	if nullableTitle.Valid {
+		v := nullableTitle.String
+		conv.Title = &v
+	}
+	return conv, nil
+}
+
+// UpdateTitle replaces the conversation title without touching updated_at,
+// so renaming does not reorder the conversation list
+func (r *AIConversationRepository) UpdateTitle(ctx context.Context, conversationID string, title string) error {
+	result, err := r.ExecuteCommand(ctx, `UPDATE ai_conversations SET title = $2 WHERE id = $1`, conversationID, title)
+	if err != nil {
+		return r.HandleSQLError(err, "update ai conversation title")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return r.HandleSQLError(err, "get rows affected")
+	}
+	if rowsAffected == 0 {
+		return r.HandleSQLError(sql.ErrNoRows, "update ai conversation title")
+	}
+	return nil
+}
+
+// ListByUser returns the user's conversations, most recently active first
+func (r *AIConversationRepository) ListByUser(ctx context.Context, userID int64, limit, offset int) ([]*repository.AIConversationSummary, error) {
+	query := `
+        SELECT c.id, c.user_id, c.type, c.page_id, c.title, c.created_at, c.updated_at,
+               COUNT(m.id) AS message_count, MAX(m.created_at) AS last_message_at
+        FROM ai_conversations c
+        LEFT JOIN ai_messages m ON m.conversation_id = c.id
+        WHERE c.user_id = $1
+        GROUP BY c.id
+        ORDER BY COALESCE(MAX(m.created_at), c.updated_at) DESC, c.created_at DESC
+        LIMIT $2 OFFSET $3`
+	rows, err := r.ExecuteQuery(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, r.HandleSQLError(err, "list ai conversations")
+	}
+	defer rows.Close()
+
+	out := []*repository.AIConversationSummary{}
+	for rows.Next() {
+		conv := &repository.AIConversationSummary{}
+		var nullablePageID sql.NullString
+		var nullableTitle sql.NullString
+		var lastMessageAt sql.NullTime
+		if err := rows.Scan(&conv.ID, &conv.UserID, &conv.Type, &nullablePageID, &nullableTitle, &conv.CreatedAt, &conv.UpdatedAt, &conv.MessageCount, &lastMessageAt); err != nil {
+			return nil, r.HandleSQLError(err, "scan ai conversation")
+		}
+		if nullablePageID.Valid {
+			v := nullablePageID.String
+			conv.PageID = &v
+		}
+		if nullableTitle.Valid {
+			v := nullableTitle.String
+			conv.Title = &v
+		}
+		if lastMessageAt.Valid {
+			v := lastMessageAt.Time
+			conv.LastMessageAt = &v
+		}
+		out = append(out, conv)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, r.HandleSQLError(err, "iterate ai conversations")
+	}
+	return out, nil
+}
+
+// Delete removes the conversation together with its messages in one transaction
+func (r *AIConversationRepository) Delete(ctx context.Context, conversationID string) (bool, error) {
+	var deleted bool
+	err := r.ExecuteInTransaction(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM ai_messages WHERE conversation_id = $1`, conversationID); err != nil {
+			return err
+		}
+		result, err := tx.ExecContext(ctx, `DELETE FROM ai_conversations WHERE id = $1`, conversationID)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		deleted = rowsAffected > 0
+		return nil
+	})
+	if err != nil {
+		return false, r.HandleSQLError(err, "delete ai conversation")
+	}
+	return deleted, nil
+}
+
+// DeleteByUser removes every conversation of the user; messages cascade
+func (r *AIConversationRepository) DeleteByUser(ctx context.Context, userID int64) (int64, error) {
+	result, err := r.ExecuteCommand(ctx, `DELETE FROM ai_conversations WHERE user_id = $1`, userID)
+	if err != nil {
+		return 0, r.HandleSQLError(err, "delete ai conversations by user")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, r.HandleSQLError(err, "get rows affected")
+	}
+	return rowsAffected, nil
+}
+
+// DeleteInactiveSince removes conversations not touched since the cutoff
+func (r *AIConversationRepository) DeleteInactiveSince(ctx context.Context, cutoff time.Time) (int64, error) {
+	result, err := r.ExecuteCommand(ctx, `DELETE FROM ai_conversations WHERE updated_at < $1`, cutoff)
+	if err != nil {
+		return 0, r.HandleSQLError(err, "delete inactive ai conversations")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, r.HandleSQLError(err, "get rows affected")
+	}
+	return rowsAffected, nil
+}
+
+// DeleteExceedingPerUser keeps only the keep most recently updated conversations of each user
+func (r *AIConversationRepository) DeleteExceedingPerUser(ctx context.Context, keep int) (int64, error) {
+	query := `
+        DELETE FROM ai_conversations
+        WHERE id IN (
+            SELECT id FROM (
+                SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY updated_at DESC, created_at DESC) AS rn
+                FROM ai_conversations
+            ) ranked
+            WHERE rn > $1
+        )`
+	result, err := r.ExecuteCommand(ctx, query, keep)
+	if err != nil {
+		return 0, r.HandleSQLError(err, "delete excess ai conversations")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, r.HandleSQLError(err, "get rows affected")
+	}
+	return rowsAffected, nil
+}
+
 func (r *AIMessageRepository) CreateMessage(ctx context.Context, msg *repository.AIMessage) error {
 	if msg.ID == "" {
 		msg.ID = uuid.New().String()
@@ -119,3 +278,15 @@ func (r *AIMessageRepository) ListMessages(ctx context.Context, conversationID s
 	}
 	return out, nil
 }
+
+func (r *AIMessageRepository) DeleteByConversation(ctx context.Context, conversationID string) (int64, error) {
+	result, err := r.ExecuteCommand(ctx, `DELETE FROM ai_messages WHERE conversation_id = $1`, conversationID)
+	if err != nil {
+		return 0, r.HandleSQLError(err, "delete ai messages by conversation")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, r.HandleSQLError(err, "get rows affected")
+	}
+	return rowsAffected, nil
+}
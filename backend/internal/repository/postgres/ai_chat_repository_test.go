@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/database"
+)
+
+func insertTestConversation(t *testing.T, dbm database.Manager, userID int64, chatType string, updatedAt time.Time) string {
+	t.Helper()
+	var id string
+	err := dbm.GetDB().QueryRow(`
+		INSERT INTO ai_conversations (user_id, type, created_at, updated_at)
+		VALUES ($1, $2, $3, $3)
+		RETURNING id`, userID, chatType, updatedAt).Scan(&id)
+	if err != nil {
+		t.Fatalf("insert conversation: %v", err)
+	}
+	return id
+}
+
+func conversationExists(t *testing.T, dbm database.Manager, id string) bool {
+	t.Helper()
+	var exists bool
+	if err := dbm.GetDB().QueryRow(`SELECT EXISTS (SELECT 1 FROM ai_conversations WHERE id = $1)`, id).Scan(&exists); err != nil {
+		t.Fatalf("check conversation: %v", err)
+	}
+	return exists
+}
+
+func TestDeleteInactiveSinceRemovesOnlyIdleConversations(t *testing.T) {
+	dbm := openTestDB(t)
+	repo := NewAIConversationRepository(dbm, testLogger())
+	ctx := context.Background()
+
+	userID := insertTestUser(t, dbm)
+	now := time.Now().UTC()
+	stale := insertTestConversation(t, dbm, userID, "notes", now.AddDate(0, 0, -40))
+	fresh := insertTestConversation(t, dbm, userID, "chat", now.AddDate(0, 0, -5))
+	mustExec(t, dbm, `INSERT INTO ai_messages (conversation_id, role, content) VALUES ($1, 'user', 'hi')`, stale)
+
+	if _, err := repo.DeleteInactiveSince(ctx, now.AddDate(0, 0, -30)); err != nil {
+		t.Fatalf("DeleteInactiveSince() error = %v", err)
+	}
+
+	if conversationExists(t, dbm, stale) {
+		t.Error("conversation idle past the cutoff was kept")
+	}
+	if !conversationExists(t, dbm, fresh) {
+		t.Error("recently used conversation was deleted")
+	}
+	var messages int
+	if err := dbm.GetDB().QueryRow(`SELECT COUNT(*) FROM ai_messages WHERE conversation_id = $1`, stale).Scan(&messages); err != nil {
+		t.Fatalf("count messages: %v", err)
+	}
+	if messages != 0 {
+		t.Errorf("%d messages of the pruned conversation remain, want 0", messages)
+	}
+}
+
+func TestDeleteExceedingPerUserKeepsMostRecent(t *testing.T) {
+	dbm := openTestDB(t)
+	repo := NewAIConversationRepository(dbm, testLogger())
+	ctx := context.Background()
+
+	busy := insertTestUser(t, dbm)
+	quiet := insertTestUser(t, dbm)
+	now := time.Now().UTC()
+	oldest := insertTestConversation(t, dbm, busy, "a", now.Add(-3*time.Hour))
+	middle := insertTestConversation(t, dbm, busy, "b", now.Add(-2*time.Hour))
+	newest := insertTestConversation(t, dbm, busy, "c", now.Add(-time.Hour))
+	other := insertTestConversation(t, dbm, quiet, "a", now.Add(-4*time.Hour))
+
+	if _, err := repo.DeleteExceedingPerUser(ctx, 2); err != nil {
+		t.Fatalf("DeleteExceedingPerUser() error = %v", err)
+	}
+
+	if conversationExists(t, dbm, oldest) {
+		t.Error("oldest conversation beyond the limit was kept")
+	}
+	for name, id := range map[string]string{"middle": middle, "newest": newest, "other user's": other} {
+		if !conversationExists(t, dbm, id) {
+			t.Errorf("%s conversation was deleted", name)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/database"
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+type AIUsageRepository struct {
+	*repository.BaseRepository
+}
+
+func NewAIUsageRepository(db database.Manager, logger *slog.Logger) repository.AIUsageRepository {
+	return &AIUsageRepository{
+		BaseRepository: repository.NewBaseRepository(db, logger, "ai_usage"),
+	}
+}
+
+func (r *AIUsageRepository) AddUsage(ctx context.Context, userID int64, day time.Time, promptTokens, completionTokens int64) error {
+	query := `
+		INSERT INTO ai_usage (user_id, usage_date, prompt_tokens, completion_tokens, request_count, updated_at)
+		VALUES ($1, $2, $3, $4, 1, NOW())
+		ON CONFLICT (user_id, usage_date) DO UPDATE SET
+			prompt_tokens = ai_usage.prompt_tokens + EXCLUDED.prompt_tokens,
+			completion_tokens = ai_usage.completion_tokens + EXCLUDED.completion_tokens,
+			request_count = ai_usage.request_count + 1,
+			updated_at = NOW()`
+
+	_, err := r.ExecuteCommand(ctx, query, userID, day.UTC().Format("2006-01-02"), promptTokens, completionTokens)
+	if err != nil {
+		return r.HandleSQLError(err, "add ai usage")
+	}
+
+	return nil
+}
+
+// GetUsageSince sums the user's usage from the given day onwards
+func (r *AIUsageRepository) GetUsageSince(ctx context.Context, userID int64, since time.Time) (*repository.AIUsageTotals, error) {
+	query := `
+		SELECT COALESCE(SUM(prompt_tokens), 0), COALESCE(SUM(completion_tokens), 0), COALESCE(SUM(request_count), 0)
+		FROM ai_usage
+		WHERE user_id = $1 AND usage_date >= $2`
+
+	totals := &repository.AIUsageTotals{}
+	err := r.ExecuteQueryRow(ctx, query, userID, since.UTC().Format("2006-01-02")).Scan(
+		&totals.PromptTokens,
+		&totals.CompletionTokens,
+		&totals.RequestCount,
+	)
+	if err != nil {
+		return nil, r.HandleSQLError(err, "get ai usage")
+	}
+
+	return totals, nil
+}
@@ -3,13 +3,16 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"log/slog"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/lib/pq"
 
 	"github.com/Srivathsav-max/lumen/backend/internal/database"
+	"github.com/Srivathsav-max/lumen/backend/internal/errors"
 	"github.com/Srivathsav-max/lumen/backend/internal/repository"
 )
 
@@ -337,11 +340,33 @@ func (r *BlockRepository) BulkDelete(ctx context.Context, ids []string) error {
 	return nil
 }
 
+// ReorderBlocks applies all new positions in one statement. blockOrders must
+// cover exactly the page's blocks with distinct positions; the page's rows are
+// locked first so concurrent reorders of the same page are serialized.
 func (r *BlockRepository) ReorderBlocks(ctx context.Context, pageID string, blockOrders map[string]int) error {
 	if len(blockOrders) == 0 {
 		return nil
 	}
 
+	// Build deterministic, duplicate-free arrays for the update
+	ids := make([]string, 0, len(blockOrders))
+	for id := range blockOrders {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	positions := make([]int64, len(ids))
+	seenPositions := make(map[int]string, len(ids))
+	for i, id := range ids {
+		position := blockOrders[id]
+		if other, ok := seenPositions[position]; ok {
+			return errors.NewValidationError("Invalid block order",
+				fmt.Sprintf("blocks %s and %s both have position %d", other, id, position))
+		}
+		seenPositions[position] = id
+		positions[i] = int64(position)
+	}
+
 	// Begin transaction
 	tx, err := r.GetDB().GetConnection().BeginTx(ctx, nil)
 	if err != nil {
@@ -349,22 +374,46 @@ func (r *BlockRepository) ReorderBlocks(ctx context.Context, pageID string, bloc
 	}
 	defer tx.Rollback()
 
-	query := `UPDATE blocks SET position = $1, updated_at = $2 WHERE id = $3 AND page_id = $4`
-	stmt, err := tx.PrepareContext(ctx, query)
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM blocks WHERE page_id = $1 ORDER BY id FOR UPDATE`, pageID)
 	if err != nil {
-		return r.HandleSQLError(err, "prepare reorder statement")
+		return r.HandleSQLError(err, "lock page blocks")
 	}
-	defer stmt.Close()
 
-	now := time.Now().UTC()
+	existing := make(map[string]struct{})
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return r.HandleSQLError(err, "scan block id")
+		}
+		existing[id] = struct{}{}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return r.HandleSQLError(err, "lock page blocks")
+	}
 
-	for blockID, position := range blockOrders {
-		_, err := stmt.ExecContext(ctx, position, now, blockID, pageID)
-		if err != nil {
-			return r.HandleSQLError(err, "execute reorder")
+	if len(existing) != len(ids) {
+		return errors.NewValidationError("Invalid block order",
+			fmt.Sprintf("page has %d blocks but %d were provided", len(existing), len(ids)))
+	}
+	for _, id := range ids {
+		if _, ok := existing[id]; !ok {
+			return errors.NewValidationError("Invalid block order",
+				fmt.Sprintf("block %s does not belong to page", id))
 		}
 	}
 
+	query := `
+		UPDATE blocks b
+		SET position = v.position, updated_at = $2
+		FROM (SELECT unnest($3::uuid[]) AS id, unnest($4::int[]) AS position) v
+		WHERE b.id = v.id AND b.page_id = $1`
+
+	if _, err := tx.ExecContext(ctx, query, pageID, time.Now().UTC(), pq.Array(ids), pq.Array(positions)); err != nil {
+		return r.HandleSQLError(err, "execute reorder")
+	}
+
 	if err := tx.Commit(); err != nil {
 		return r.HandleSQLError(err, "commit reorder transaction")
 	}
@@ -412,4 +461,90 @@ func (r *BlockRepository) GetBlocksByType(ctx context.Context, pageID string, bl
 	}
 
 	return blocks, nil
-}
\ No newline at end of file
+}
+
+// GetLeadingBlocks returns up to limit top-level blocks per page for the given
+// pages in a single query, keyed by page ID
+func (r *BlockRepository) GetLeadingBlocks(ctx context.Context, pageIDs []string, limit int) (map[string][]*repository.Block, error) {
+	result := make(map[string][]*repository.Block)
+	if len(pageIDs) == 0 {
+		return result, nil
+	}
+
+	query := `
+		SELECT id, page_id, block_type, block_data, position, parent_block_id,
+			   created_at, updated_at, created_by, last_edited_by
+		FROM (
+			SELECT b.*, ROW_NUMBER() OVER (PARTITION BY b.page_id ORDER BY b.position ASC, b.created_at ASC) AS rn
+			FROM blocks b
+			WHERE b.page_id = ANY($1) AND b.parent_block_id IS NULL
+		) ranked
+		WHERE rn <= $2
+		ORDER BY page_id, position ASC, created_at ASC`
+
+	rows, err := r.ExecuteQuery(ctx, query, pq.Array(pageIDs), limit)
+	if err != nil {
+		return nil, r.HandleSQLError(err, "get leading blocks")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		block := &repository.Block{}
+		err := rows.Scan(
+			&block.ID,
+			&block.PageID,
+			&block.BlockType,
+			&block.BlockData,
+			&block.Position,
+			&block.ParentBlockID,
+			&block.CreatedAt,
+			&block.UpdatedAt,
+			&block.CreatedBy,
+			&block.LastEditedBy,
+		)
+		if err != nil {
+			return nil, r.HandleSQLError(err, "scan block")
+		}
+		result[block.PageID] = append(result[block.PageID], block)
+	}
+
+	return result, nil
+}
+
+// blockTextVector is the full-text vector over a block's string values; it
+// matches the idx_blocks_text_gin expression index
+const blockTextVector = `jsonb_to_tsvector('english', b.block_data, '["string"]')`
+
+func (r *BlockRepository) SearchHits(ctx context.Context, pageIDs []string, query string) (map[string]*repository.BlockSearchHit, error) {
+	result := make(map[string]*repository.BlockSearchHit)
+	if len(pageIDs) == 0 || query == "" {
+		return result, nil
+	}
+
+	sqlQuery := `
+		SELECT DISTINCT ON (b.page_id) b.page_id, b.id, b.block_type,
+			   ts_headline('english', b.block_data, plainto_tsquery('english', $2), $3)
+		FROM blocks b
+		WHERE b.page_id = ANY($1)
+		  AND ` + blockTextVector + ` @@ plainto_tsquery('english', $2)
+		ORDER BY b.page_id, ts_rank(` + blockTextVector + `, plainto_tsquery('english', $2)) DESC, b.position ASC`
+
+	options := fmt.Sprintf(`StartSel="%s", StopSel="%s", MaxWords=30, MinWords=10`,
+		repository.SearchHighlightStart, repository.SearchHighlightStop)
+
+	rows, err := r.ExecuteQuery(ctx, sqlQuery, pq.Array(pageIDs), query, options)
+	if err != nil {
+		return nil, r.HandleSQLError(err, "search blocks")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		hit := &repository.BlockSearchHit{}
+		if err := rows.Scan(&hit.PageID, &hit.BlockID, &hit.BlockType, &hit.Headline); err != nil {
+			return nil, r.HandleSQLError(err, "scan block search hit")
+		}
+		result[hit.PageID] = hit
+	}
+
+	return result, nil
+}
@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/database"
+	"github.com/Srivathsav-max/lumen/backend/internal/errors"
+)
+
+func TestGetLeadingBlocks(t *testing.T) {
+	dbm := openTestDB(t)
+	repo := NewBlockRepository(dbm, testLogger())
+
+	ownerID := insertTestUser(t, dbm)
+	workspaceID := insertTestWorkspace(t, dbm, ownerID, "edit")
+	long := insertTestPage(t, dbm, workspaceID, ownerID, "Long", nil)
+	short := insertTestPage(t, dbm, workspaceID, ownerID, "Short", nil)
+	empty := insertTestPage(t, dbm, workspaceID, ownerID, "Empty", nil)
+
+	third := insertTestBlock(t, dbm, long, "paragraph", `{"text":"third"}`, 2, nil, ownerID)
+	first := insertTestBlock(t, dbm, long, "paragraph", `{"text":"first"}`, 0, nil, ownerID)
+	insertTestBlock(t, dbm, long, "paragraph", `{"text":"nested"}`, 1, &first, ownerID)
+	second := insertTestBlock(t, dbm, long, "paragraph", `{"text":"second"}`, 1, nil, ownerID)
+	insertTestBlock(t, dbm, long, "paragraph", `{"text":"fourth"}`, 3, nil, ownerID)
+	only := insertTestBlock(t, dbm, short, "header", `{"text":"only"}`, 0, nil, ownerID)
+
+	blocks, err := repo.GetLeadingBlocks(context.Background(), []string{long, short, empty}, 3)
+	if err != nil {
+		t.Fatalf("GetLeadingBlocks() error = %v", err)
+	}
+
+	want := map[string][]string{long: {first, second, third}, short: {only}}
+	for pageID, wantIDs := range want {
+		got := blocks[pageID]
+		if len(got) != len(wantIDs) {
+			t.Fatalf("page %s got %d blocks, want %d", pageID, len(got), len(wantIDs))
+		}
+		for i, block := range got {
+			if block.ID != wantIDs[i] {
+				t.Errorf("page %s block %d = %s, want %s", pageID, i, block.ID, wantIDs[i])
+			}
+		}
+	}
+	if len(blocks[empty]) != 0 {
+		t.Errorf("empty page got %d blocks", len(blocks[empty]))
+	}
+
+	if none, err := repo.GetLeadingBlocks(context.Background(), nil, 3); err != nil || len(none) != 0 {
+		t.Errorf("GetLeadingBlocks(nil) = %v, %v; want an empty map", none, err)
+	}
+}
+
+func blockPositions(t *testing.T, dbm database.Manager, pageID string) map[string]int {
+	t.Helper()
+	rows, err := dbm.GetDB().Query(`SELECT id, position FROM blocks WHERE page_id = $1`, pageID)
+	if err != nil {
+		t.Fatalf("query block positions: %v", err)
+	}
+	defer rows.Close()
+
+	positions := make(map[string]int)
+	for rows.Next() {
+		var id string
+		var position int
+		if err := rows.Scan(&id, &position); err != nil {
+			t.Fatalf("scan block position: %v", err)
+		}
+		positions[id] = position
+	}
+	return positions
+}
+
+func TestReorderBlocks(t *testing.T) {
+	dbm := openTestDB(t)
+	repo := NewBlockRepository(dbm, testLogger())
+	ctx := context.Background()
+
+	ownerID := insertTestUser(t, dbm)
+	workspaceID := insertTestWorkspace(t, dbm, ownerID, "edit")
+	pageID := insertTestPage(t, dbm, workspaceID, ownerID, "Reorder", nil)
+	otherPage := insertTestPage(t, dbm, workspaceID, ownerID, "Other", nil)
+
+	a := insertTestBlock(t, dbm, pageID, "paragraph", `{"text":"a"}`, 0, nil, ownerID)
+	b := insertTestBlock(t, dbm, pageID, "paragraph", `{"text":"b"}`, 1, nil, ownerID)
+	c := insertTestBlock(t, dbm, pageID, "paragraph", `{"text":"c"}`, 2, nil, ownerID)
+	foreign := insertTestBlock(t, dbm, otherPage, "paragraph", `{"text":"x"}`, 0, nil, ownerID)
+	original := map[string]int{a: 0, b: 1, c: 2}
+
+	rejected := []struct {
+		name  string
+		order map[string]int
+	}{
+		{"duplicate positions", map[string]int{a: 0, b: 0, c: 1}},
+		{"missing block", map[string]int{a: 1, b: 0}},
+		{"block from another page", map[string]int{a: 2, b: 1, foreign: 0}},
+		{"extra block", map[string]int{a: 3, b: 2, c: 1, foreign: 0}},
+	}
+	for _, tt := range rejected {
+		err := repo.ReorderBlocks(ctx, pageID, tt.order)
+		if appErr, ok := errors.AsAppError(err); !ok || appErr.Code != errors.ValidationError {
+			t.Errorf("%s: ReorderBlocks() error = %v, want a validation error", tt.name, err)
+		}
+		if got := blockPositions(t, dbm, pageID); !reflect.DeepEqual(got, original) {
+			t.Errorf("%s: positions = %v after a rejected reorder, want %v", tt.name, got, original)
+		}
+	}
+
+	want := map[string]int{a: 2, b: 0, c: 1}
+	if err := repo.ReorderBlocks(ctx, pageID, want); err != nil {
+		t.Fatalf("ReorderBlocks() error = %v", err)
+	}
+	if got := blockPositions(t, dbm, pageID); !reflect.DeepEqual(got, want) {
+		t.Errorf("positions = %v, want %v", got, want)
+	}
+	if got := blockPositions(t, dbm, otherPage); got[foreign] != 0 {
+		t.Errorf("block on another page moved to %d", got[foreign])
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 
 	"github.com/Srivathsav-max/lumen/backend/internal/database"
 	"github.com/Srivathsav-max/lumen/backend/internal/repository"
@@ -322,4 +323,29 @@ func (r *CommentRepository) GetUnresolved(ctx context.Context, pageID string) ([
 	}
 
 	return comments, nil
-}
\ No newline at end of file
+}
+
+// AddMentions records the users referenced by a comment; users already
+// recorded for the comment are ignored
+func (r *CommentRepository) AddMentions(ctx context.Context, commentID string, userIDs []int64) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO comment_mentions (comment_id, user_id, created_at)
+		SELECT $1, user_id, $3
+		FROM unnest($2::int[]) AS user_id
+		ON CONFLICT (comment_id, user_id) DO NOTHING`
+
+	_, err := r.ExecuteCommand(ctx, query, commentID, pq.Array(userIDs), time.Now().UTC())
+	if err != nil {
+		return r.HandleSQLError(err, "add comment mentions")
+	}
+
+	r.GetLogger().Info("Comment mentions added successfully",
+		"comment_id", commentID,
+		"count", len(userIDs))
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/database"
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+type NotificationPreferenceRepository struct {
+	*repository.BaseRepository
+}
+
+func NewNotificationPreferenceRepository(db database.Manager, logger *slog.Logger) repository.NotificationPreferenceRepository {
+	return &NotificationPreferenceRepository{
+		BaseRepository: repository.NewBaseRepository(db, logger, "notification_preferences"),
+	}
+}
+
+func (r *NotificationPreferenceRepository) Get(ctx context.Context, userID int64) (*repository.NotificationPreferences, error) {
+	query := `
+		SELECT user_id, email_page_access_granted, email_page_access_revoked, email_new_device_login, updated_at
+		FROM notification_preferences
+		WHERE user_id = $1`
+
+	preferences := &repository.NotificationPreferences{}
+	err := r.ExecuteQueryRow(ctx, query, userID).Scan(
+		&preferences.UserID,
+		&preferences.EmailPageAccessGranted,
+		&preferences.EmailPageAccessRevoked,
+		&preferences.EmailNewDeviceLogin,
+		&preferences.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return repository.DefaultNotificationPreferences(userID), nil
+		}
+		return nil, r.HandleSQLError(err, "get notification preferences")
+	}
+
+	return preferences, nil
+}
+
+func (r *NotificationPreferenceRepository) Upsert(ctx context.Context, preferences *repository.NotificationPreferences) error {
+	query := `
+		INSERT INTO notification_preferences (user_id, email_page_access_granted, email_page_access_revoked, email_new_device_login, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id) DO UPDATE
+		SET email_page_access_granted = EXCLUDED.email_page_access_granted,
+			email_page_access_revoked = EXCLUDED.email_page_access_revoked,
+			email_new_device_login = EXCLUDED.email_new_device_login,
+			updated_at = EXCLUDED.updated_at`
+
+	preferences.UpdatedAt = time.Now().UTC()
+
+	_, err := r.ExecuteCommand(ctx, query,
+		preferences.UserID,
+		preferences.EmailPageAccessGranted,
+		preferences.EmailPageAccessRevoked,
+		preferences.EmailNewDeviceLogin,
+		preferences.UpdatedAt,
+	)
+	if err != nil {
+		return r.HandleSQLError(err, "save notification preferences")
+	}
+
+	return nil
+}
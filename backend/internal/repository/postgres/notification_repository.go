@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/database"
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+type NotificationRepository struct {
+	*repository.BaseRepository
+}
+
+func NewNotificationRepository(db database.Manager, logger *slog.Logger) repository.NotificationRepository {
+	return &NotificationRepository{
+		BaseRepository: repository.NewBaseRepository(db, logger, "notifications"),
+	}
+}
+
+func (r *NotificationRepository) Create(ctx context.Context, notification *repository.Notification) error {
+	if notification.ID == "" {
+		notification.ID = uuid.New().String()
+	}
+
+	query := `
+		INSERT INTO notifications (id, user_id, type, actor_id, page_id, comment_id, is_read, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, FALSE, $7)`
+
+	notification.IsRead = false
+	notification.CreatedAt = time.Now().UTC()
+
+	_, err := r.ExecuteCommand(ctx, query,
+		notification.ID,
+		notification.UserID,
+		notification.Type,
+		notification.ActorID,
+		notification.PageID,
+		notification.CommentID,
+		notification.CreatedAt,
+	)
+
+	if err != nil {
+		return r.HandleSQLError(err, "create notification")
+	}
+
+	r.GetLogger().Info("Notification created successfully",
+		"notification_id", notification.ID,
+		"user_id", notification.UserID,
+		"type", notification.Type)
+
+	return nil
+}
+
+// ListByUser returns the user's notifications, newest first
+func (r *NotificationRepository) ListByUser(ctx context.Context, userID int64, unreadOnly bool, limit, offset int) ([]*repository.Notification, error) {
+	query := `
+		SELECT id, user_id, type, actor_id, page_id, comment_id, is_read, read_at, created_at
+		FROM notifications
+		WHERE user_id = $1 AND ($2 = FALSE OR is_read = FALSE)
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4`
+
+	rows, err := r.ExecuteQuery(ctx, query, userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, r.HandleSQLError(err, "list notifications")
+	}
+	defer rows.Close()
+
+	var notifications []*repository.Notification
+	for rows.Next() {
+		notification := &repository.Notification{}
+		err := rows.Scan(
+			&notification.ID,
+			&notification.UserID,
+			&notification.Type,
+			&notification.ActorID,
+			&notification.PageID,
+			&notification.CommentID,
+			&notification.IsRead,
+			&notification.ReadAt,
+			&notification.CreatedAt,
+		)
+		if err != nil {
+			return nil, r.HandleSQLError(err, "scan notification")
+		}
+		notifications = append(notifications, notification)
+	}
+
+	return notifications, nil
+}
+
+// MarkRead marks one of the user's notifications as read. It reports false
+// when the notification does not exist or belongs to someone else.
+func (r *NotificationRepository) MarkRead(ctx context.Context, id string, userID int64) (bool, error) {
+	query := `
+		UPDATE notifications
+		SET is_read = TRUE, read_at = COALESCE(read_at, $1)
+		WHERE id = $2 AND user_id = $3`
+
+	result, err := r.ExecuteCommand(ctx, query, time.Now().UTC(), id, userID)
+	if err != nil {
+		return false, r.HandleSQLError(err, "mark notification read")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, r.HandleSQLError(err, "get rows affected")
+	}
+
+	return rowsAffected > 0, nil
+}
@@ -4,12 +4,16 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 
 	"github.com/Srivathsav-max/lumen/backend/internal/database"
+	"github.com/Srivathsav-max/lumen/backend/internal/errors"
 	"github.com/Srivathsav-max/lumen/backend/internal/repository"
 )
 
@@ -75,7 +79,7 @@ func (r *PageRepository) GetByID(ctx context.Context, id string) (*repository.Pa
 		SELECT id, title, workspace_id, owner_id, parent_id, icon, cover_url,
 			   is_archived, is_template, properties, created_at, updated_at, last_edited_by
 		FROM pages 
-		WHERE id = $1`
+		WHERE id = $1 AND deleted_at IS NULL`
 
 	page := &repository.Page{}
 	row := r.ExecuteQueryRow(ctx, query, id)
@@ -106,22 +110,1038 @@ func (r *PageRepository) GetByID(ctx context.Context, id string) (*repository.Pa
 	return page, nil
 }
 
-func (r *PageRepository) GetByWorkspaceID(ctx context.Context, workspaceID int64, includeArchived bool) ([]*repository.Page, error) {
+func (r *PageRepository) GetByWorkspaceID(ctx context.Context, workspaceID int64, includeArchived, includeTemplates bool) ([]*repository.Page, error) {
 	query := `
 		SELECT id, title, workspace_id, owner_id, parent_id, icon, cover_url,
 			   is_archived, is_template, properties, created_at, updated_at, last_edited_by
 		FROM pages 
-		WHERE workspace_id = $1`
+		WHERE workspace_id = $1 AND deleted_at IS NULL`
 
 	if !includeArchived {
 		query += ` AND is_archived = FALSE`
 	}
 
+	if !includeTemplates {
+		query += ` AND is_template = FALSE`
+	}
+
+	query += ` ORDER BY updated_at DESC`
+
+	rows, err := r.ExecuteQuery(ctx, query, workspaceID)
+	if err != nil {
+		return nil, r.HandleSQLError(err, "get pages by workspace id")
+	}
+	defer rows.Close()
+
+	var pages []*repository.Page
+	for rows.Next() {
+		page := &repository.Page{}
+		err := rows.Scan(
+			&page.ID,
+			&page.Title,
+			&page.WorkspaceID,
+			&page.OwnerID,
+			&page.ParentID,
+			&page.Icon,
+			&page.CoverURL,
+			&page.IsArchived,
+			&page.IsTemplate,
+			&page.Properties,
+			&page.CreatedAt,
+			&page.UpdatedAt,
+			&page.LastEditedBy,
+		)
+		if err != nil {
+			return nil, r.HandleSQLError(err, "scan page")
+		}
+		pages = append(pages, page)
+	}
+
+	return pages, nil
+}
+
+func (r *PageRepository) GetByParentID(ctx context.Context, parentID string, includeArchived, includeTemplates bool) ([]*repository.Page, error) {
+	query := `
+		SELECT id, title, workspace_id, owner_id, parent_id, icon, cover_url,
+			   is_archived, is_template, properties, created_at, updated_at, last_edited_by
+		FROM pages 
+		WHERE parent_id = $1 AND deleted_at IS NULL`
+
+	if !includeArchived {
+		query += ` AND is_archived = FALSE`
+	}
+
+	if !includeTemplates {
+		query += ` AND is_template = FALSE`
+	}
+
 	query += ` ORDER BY updated_at DESC`
 
-	rows, err := r.ExecuteQuery(ctx, query, workspaceID)
+	rows, err := r.ExecuteQuery(ctx, query, parentID)
+	if err != nil {
+		return nil, r.HandleSQLError(err, "get pages by parent id")
+	}
+	defer rows.Close()
+
+	var pages []*repository.Page
+	for rows.Next() {
+		page := &repository.Page{}
+		err := rows.Scan(
+			&page.ID,
+			&page.Title,
+			&page.WorkspaceID,
+			&page.OwnerID,
+			&page.ParentID,
+			&page.Icon,
+			&page.CoverURL,
+			&page.IsArchived,
+			&page.IsTemplate,
+			&page.Properties,
+			&page.CreatedAt,
+			&page.UpdatedAt,
+			&page.LastEditedBy,
+		)
+		if err != nil {
+			return nil, r.HandleSQLError(err, "scan page")
+		}
+		pages = append(pages, page)
+	}
+
+	return pages, nil
+}
+
+func (r *PageRepository) GetRootPages(ctx context.Context, workspaceID int64, includeArchived, includeTemplates bool) ([]*repository.Page, error) {
+	query := `
+		SELECT id, title, workspace_id, owner_id, parent_id, icon, cover_url,
+			   is_archived, is_template, properties, created_at, updated_at, last_edited_by
+		FROM pages 
+		WHERE workspace_id = $1 AND parent_id IS NULL AND deleted_at IS NULL`
+
+	if !includeArchived {
+		query += ` AND is_archived = FALSE`
+	}
+
+	if !includeTemplates {
+		query += ` AND is_template = FALSE`
+	}
+
+	query += ` ORDER BY updated_at DESC`
+
+	rows, err := r.ExecuteQuery(ctx, query, workspaceID)
+	if err != nil {
+		return nil, r.HandleSQLError(err, "get root pages")
+	}
+	defer rows.Close()
+
+	var pages []*repository.Page
+	for rows.Next() {
+		page := &repository.Page{}
+		err := rows.Scan(
+			&page.ID,
+			&page.Title,
+			&page.WorkspaceID,
+			&page.OwnerID,
+			&page.ParentID,
+			&page.Icon,
+			&page.CoverURL,
+			&page.IsArchived,
+			&page.IsTemplate,
+			&page.Properties,
+			&page.CreatedAt,
+			&page.UpdatedAt,
+			&page.LastEditedBy,
+		)
+		if err != nil {
+			return nil, r.HandleSQLError(err, "scan page")
+		}
+		pages = append(pages, page)
+	}
+
+	return pages, nil
+}
+
+// GetTemplates lists the live template pages of a workspace, archived ones
+// included, ordered by title
+func (r *PageRepository) GetTemplates(ctx context.Context, workspaceID int64) ([]*repository.Page, error) {
+	query := `
+		SELECT id, title, workspace_id, owner_id, parent_id, icon, cover_url,
+			   is_archived, is_template, properties, created_at, updated_at, last_edited_by
+		FROM pages
+		WHERE workspace_id = $1 AND is_template = TRUE AND deleted_at IS NULL
+		ORDER BY title ASC, id ASC`
+
+	rows, err := r.ExecuteQuery(ctx, query, workspaceID)
+	if err != nil {
+		return nil, r.HandleSQLError(err, "get templates")
+	}
+	defer rows.Close()
+
+	return r.scanPages(rows)
+}
+
+// GetAncestors returns the chain of pages from the root down to and including
+// the given page. The path array stops the walk if the parent links ever form a cycle.
+func (r *PageRepository) GetAncestors(ctx context.Context, id string) ([]*repository.Page, error) {
+	query := `
+		WITH RECURSIVE ancestors AS (
+			SELECT id, parent_id, 0 AS depth, ARRAY[id] AS path
+			FROM pages
+			WHERE id = $1 AND deleted_at IS NULL
+			UNION ALL
+			SELECT p.id, p.parent_id, a.depth + 1, a.path || p.id
+			FROM pages p
+			INNER JOIN ancestors a ON p.id = a.parent_id
+			WHERE p.deleted_at IS NULL AND NOT p.id = ANY(a.path)
+		)
+		SELECT p.id, p.title, p.workspace_id, p.owner_id, p.parent_id, p.icon, p.cover_url,
+			   p.is_archived, p.is_template, p.properties, p.created_at, p.updated_at, p.last_edited_by
+		FROM pages p
+		INNER JOIN ancestors a ON p.id = a.id
+		ORDER BY a.depth DESC`
+
+	rows, err := r.ExecuteQuery(ctx, query, id)
+	if err != nil {
+		return nil, r.HandleSQLError(err, "get page ancestors")
+	}
+	defer rows.Close()
+
+	var pages []*repository.Page
+	for rows.Next() {
+		page := &repository.Page{}
+		err := rows.Scan(
+			&page.ID,
+			&page.Title,
+			&page.WorkspaceID,
+			&page.OwnerID,
+			&page.ParentID,
+			&page.Icon,
+			&page.CoverURL,
+			&page.IsArchived,
+			&page.IsTemplate,
+			&page.Properties,
+			&page.CreatedAt,
+			&page.UpdatedAt,
+			&page.LastEditedBy,
+		)
+		if err != nil {
+			return nil, r.HandleSQLError(err, "scan page")
+		}
+		pages = append(pages, page)
+	}
+
+	return pages, nil
+}
+
+// GetTree returns the pages of a workspace the user can view, from the roots
+// down to maxDepth levels, parents before children. The walk passes through
+// pages the user cannot view, so a visible page may be returned without its
+// parent. HasChildren tells whether a page has visible children, including
+// ones cut off by the depth limit.
+func (r *PageRepository) GetTree(ctx context.Context, workspaceID int64, userID int64, maxDepth int, includeArchived, includeTemplates bool) ([]*repository.PageTreeEntry, error) {
+	query := `
+		WITH RECURSIVE tree AS (
+			SELECT id, 1 AS depth, ARRAY[id] AS path
+			FROM pages
+			WHERE workspace_id = $1 AND parent_id IS NULL AND deleted_at IS NULL
+			  AND ($3 OR is_archived = FALSE)
+			  AND ($4 OR is_template = FALSE)
+			UNION ALL
+			SELECT p.id, t.depth + 1, t.path || p.id
+			FROM pages p
+			INNER JOIN tree t ON p.parent_id = t.id
+			WHERE t.depth < $2 AND p.deleted_at IS NULL
+			  AND ($3 OR p.is_archived = FALSE)
+			  AND ($4 OR p.is_template = FALSE)
+			  AND NOT p.id = ANY(t.path)
+		)
+		SELECT p.id, p.title, p.workspace_id, p.owner_id, p.parent_id, p.icon, p.cover_url,
+			   p.is_archived, p.is_template, p.properties, p.created_at, p.updated_at, p.last_edited_by,
+			   t.depth,
+			   EXISTS(
+				SELECT 1 FROM pages c
+				WHERE c.parent_id = p.id AND c.deleted_at IS NULL
+				  AND ($3 OR c.is_archived = FALSE)
+				  AND ($4 OR c.is_template = FALSE)` +
+		treeChildVisibilityCondition + `
+			   ) AS has_children
+		FROM tree t
+		INNER JOIN pages p ON p.id = t.id
+		WHERE TRUE` +
+		treeVisibilityCondition + `
+		ORDER BY t.depth ASC, p.updated_at DESC`
+
+	rows, err := r.ExecuteQuery(ctx, query, workspaceID, maxDepth, includeArchived, includeTemplates, userID)
+	if err != nil {
+		return nil, r.HandleSQLError(err, "get page tree")
+	}
+	defer rows.Close()
+
+	var entries []*repository.PageTreeEntry
+	for rows.Next() {
+		entry := &repository.PageTreeEntry{}
+		err := rows.Scan(
+			&entry.ID,
+			&entry.Title,
+			&entry.WorkspaceID,
+			&entry.OwnerID,
+			&entry.ParentID,
+			&entry.Icon,
+			&entry.CoverURL,
+			&entry.IsArchived,
+			&entry.IsTemplate,
+			&entry.Properties,
+			&entry.CreatedAt,
+			&entry.UpdatedAt,
+			&entry.LastEditedBy,
+			&entry.Depth,
+			&entry.HasChildren,
+		)
+		if err != nil {
+			return nil, r.HandleSQLError(err, "scan page tree entry")
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// IsDescendant reports whether candidateID is ancestorID itself or lies
+// anywhere beneath it, walking up the candidate's parent chain
+func (r *PageRepository) IsDescendant(ctx context.Context, ancestorID, candidateID string) (bool, error) {
+	query := `
+		WITH RECURSIVE chain AS (
+			SELECT id, parent_id, ARRAY[id] AS path
+			FROM pages
+			WHERE id = $2
+			UNION ALL
+			SELECT p.id, p.parent_id, c.path || p.id
+			FROM pages p
+			INNER JOIN chain c ON p.id = c.parent_id
+			WHERE NOT p.id = ANY(c.path)
+		)
+		SELECT EXISTS(SELECT 1 FROM chain WHERE id = $1)`
+
+	var isDescendant bool
+	if err := r.ExecuteQueryRow(ctx, query, ancestorID, candidateID).Scan(&isDescendant); err != nil {
+		return false, r.HandleSQLError(err, "check page descendant")
+	}
+
+	return isDescendant, nil
+}
+
+func (r *PageRepository) GetDescendantIDs(ctx context.Context, ids []string) (map[string][]string, error) {
+	// The path guards against a parent_id cycle
+	query := `
+		WITH RECURSIVE descendants AS (
+			SELECT id, parent_id AS root, ARRAY[id] AS path
+			FROM pages
+			WHERE parent_id = ANY($1) AND deleted_at IS NULL
+			UNION ALL
+			SELECT p.id, d.root, d.path || p.id
+			FROM pages p
+			INNER JOIN descendants d ON p.parent_id = d.id
+			WHERE p.deleted_at IS NULL AND NOT p.id = ANY(d.path)
+		)
+		SELECT root, id FROM descendants`
+
+	rows, err := r.ExecuteQuery(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, r.HandleSQLError(err, "get descendant ids")
+	}
+	defer rows.Close()
+
+	descendants := make(map[string][]string, len(ids))
+	for rows.Next() {
+		var rootID, id string
+		if err := rows.Scan(&rootID, &id); err != nil {
+			return nil, r.HandleSQLError(err, "scan descendant id")
+		}
+		descendants[rootID] = append(descendants[rootID], id)
+	}
+
+	return descendants, nil
+}
+
+func (r *PageRepository) Update(ctx context.Context, page *repository.Page) error {
+	query := `
+		UPDATE pages 
+		SET title = $1, icon = $2, cover_url = $3, is_template = $4, 
+			properties = $5, updated_at = $6, last_edited_by = $7
+		WHERE id = $8`
+
+	page.UpdatedAt = time.Now().UTC()
+
+	_, err := r.ExecuteCommand(ctx, query,
+		page.Title,
+		page.Icon,
+		page.CoverURL,
+		page.IsTemplate,
+		page.Properties,
+		page.UpdatedAt,
+		page.LastEditedBy,
+		page.ID,
+	)
+
+	if err != nil {
+		return r.HandleSQLError(err, "update page")
+	}
+
+	r.GetLogger().Info("Page updated successfully", "page_id", page.ID)
+	return nil
+}
+
+// Delete moves a page and all of its descendants to the trash. Every page in
+// the subtree gets the same deleted_at so they can be restored together.
+func (r *PageRepository) Delete(ctx context.Context, id string) error {
+	query := `
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM pages WHERE id = $1 AND deleted_at IS NULL
+			UNION
+			SELECT p.id FROM pages p
+			INNER JOIN subtree s ON p.parent_id = s.id
+			WHERE p.deleted_at IS NULL
+		)
+		UPDATE pages
+		SET deleted_at = $2, updated_at = $2
+		WHERE id IN (SELECT id FROM subtree)`
+
+	now := time.Now().UTC()
+
+	_, err := r.ExecuteCommand(ctx, query, id, now)
+	if err != nil {
+		return r.HandleSQLError(err, "delete page")
+	}
+
+	r.GetLogger().Info("Page moved to trash successfully", "page_id", id)
+	return nil
+}
+
+// GetTrashedByID returns a page that is in the trash, or nil if there is none
+func (r *PageRepository) GetTrashedByID(ctx context.Context, id string) (*repository.Page, error) {
+	query := `
+		SELECT id, title, workspace_id, owner_id, parent_id, icon, cover_url,
+			   is_archived, is_template, properties, created_at, updated_at, last_edited_by, deleted_at
+		FROM pages
+		WHERE id = $1 AND deleted_at IS NOT NULL`
+
+	page := &repository.Page{}
+	err := r.ExecuteQueryRow(ctx, query, id).Scan(
+		&page.ID,
+		&page.Title,
+		&page.WorkspaceID,
+		&page.OwnerID,
+		&page.ParentID,
+		&page.Icon,
+		&page.CoverURL,
+		&page.IsArchived,
+		&page.IsTemplate,
+		&page.Properties,
+		&page.CreatedAt,
+		&page.UpdatedAt,
+		&page.LastEditedBy,
+		&page.DeletedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, r.HandleSQLError(err, "get trashed page by id")
+	}
+
+	return page, nil
+}
+
+// ListTrash returns the trashed pages of a workspace that userID could have
+// deleted. Descendants that were trashed together with their parent are not
+// listed separately. Deleting needs admin, which only ownership or an explicit
+// grant can give: the workspace default never goes above edit.
+func (r *PageRepository) ListTrash(ctx context.Context, workspaceID int64, userID int64) ([]*repository.Page, error) {
+	query := `
+		SELECT p.id, p.title, p.workspace_id, p.owner_id, p.parent_id, p.icon, p.cover_url,
+			   p.is_archived, p.is_template, p.properties, p.created_at, p.updated_at, p.last_edited_by, p.deleted_at
+		FROM pages p
+		WHERE p.workspace_id = $1
+		  AND p.deleted_at IS NOT NULL
+		  AND NOT EXISTS (
+			SELECT 1 FROM pages parent
+			WHERE parent.id = p.parent_id AND parent.deleted_at = p.deleted_at
+		  )
+		  AND (p.owner_id = $2
+		       OR EXISTS(SELECT 1 FROM page_permissions pp
+		                 WHERE pp.page_id = p.id AND pp.user_id = $2 AND pp.permission = 'admin'))
+		ORDER BY p.deleted_at DESC`
+
+	rows, err := r.ExecuteQuery(ctx, query, workspaceID, userID)
+	if err != nil {
+		return nil, r.HandleSQLError(err, "list trash")
+	}
+	defer rows.Close()
+
+	var pages []*repository.Page
+	for rows.Next() {
+		page := &repository.Page{}
+		err := rows.Scan(
+			&page.ID,
+			&page.Title,
+			&page.WorkspaceID,
+			&page.OwnerID,
+			&page.ParentID,
+			&page.Icon,
+			&page.CoverURL,
+			&page.IsArchived,
+			&page.IsTemplate,
+			&page.Properties,
+			&page.CreatedAt,
+			&page.UpdatedAt,
+			&page.LastEditedBy,
+			&page.DeletedAt,
+		)
+		if err != nil {
+			return nil, r.HandleSQLError(err, "scan page")
+		}
+		pages = append(pages, page)
+	}
+
+	return pages, nil
+}
+
+// RestoreFromTrash brings a trashed page back together with the descendants
+// that were trashed with it. If its parent is still in the trash the page is
+// restored to the workspace root.
+func (r *PageRepository) RestoreFromTrash(ctx context.Context, id string, restoredBy int64) error {
+	tx, err := r.GetDB().GetConnection().BeginTx(ctx, nil)
+	if err != nil {
+		return r.HandleSQLError(err, "begin restore from trash transaction")
+	}
+	defer tx.Rollback()
+
+	var deletedAt time.Time
+	err = tx.QueryRowContext(ctx, `SELECT deleted_at FROM pages WHERE id = $1 AND deleted_at IS NOT NULL FOR UPDATE`, id).Scan(&deletedAt)
+	if err != nil {
+		return r.HandleSQLError(err, "lock trashed page")
+	}
+
+	now := time.Now().UTC()
+
+	_, err = tx.ExecContext(ctx, `
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM pages WHERE id = $1
+			UNION
+			SELECT p.id FROM pages p
+			INNER JOIN subtree s ON p.parent_id = s.id
+			WHERE p.deleted_at = $2
+		)
+		UPDATE pages
+		SET deleted_at = NULL, updated_at = $3
+		WHERE id IN (SELECT id FROM subtree)`,
+		id, deletedAt, now)
+	if err != nil {
+		return r.HandleSQLError(err, "restore pages from trash")
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE pages p
+		SET last_edited_by = $2,
+			parent_id = CASE
+				WHEN EXISTS (SELECT 1 FROM pages parent WHERE parent.id = p.parent_id AND parent.deleted_at IS NOT NULL)
+				THEN NULL ELSE p.parent_id END
+		WHERE p.id = $1`,
+		id, restoredBy)
+	if err != nil {
+		return r.HandleSQLError(err, "restore page from trash")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return r.HandleSQLError(err, "commit restore from trash transaction")
+	}
+
+	r.GetLogger().Info("Page restored from trash successfully", "page_id", id, "restored_by", restoredBy)
+	return nil
+}
+
+// Purge permanently deletes a trashed page; its child pages and blocks are removed by cascade
+func (r *PageRepository) Purge(ctx context.Context, id string) error {
+	query := `DELETE FROM pages WHERE id = $1 AND deleted_at IS NOT NULL`
+
+	_, err := r.ExecuteCommand(ctx, query, id)
+	if err != nil {
+		return r.HandleSQLError(err, "purge page")
+	}
+
+	r.GetLogger().Info("Page purged successfully", "page_id", id)
+	return nil
+}
+
+// PurgeExpired permanently deletes pages that were trashed before olderThan
+func (r *PageRepository) PurgeExpired(ctx context.Context, olderThan time.Time) (int64, error) {
+	query := `DELETE FROM pages WHERE deleted_at IS NOT NULL AND deleted_at < $1`
+
+	result, err := r.ExecuteCommand(ctx, query, olderThan)
+	if err != nil {
+		return 0, r.HandleSQLError(err, "purge expired pages")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, r.HandleSQLError(err, "get rows affected")
+	}
+
+	r.GetLogger().Info("Expired trashed pages purged successfully", "count", rowsAffected)
+	return rowsAffected, nil
+}
+
+// Archive archives a page and, with cascade, every live page beneath it
+func (r *PageRepository) Archive(ctx context.Context, id string, cascade bool, archivedBy int64) error {
+	if _, err := r.archivePages(ctx, []string{id}, cascade, archivedBy); err != nil {
+		return err
+	}
+
+	r.GetLogger().Info("Page archived successfully", "page_id", id, "cascade", cascade, "archived_by", archivedBy)
+	return nil
+}
+
+// Restore unarchives a page and, with cascade, the descendants its own
+// archive took down. Descendants archived directly, or by another ancestor,
+// stay archived.
+func (r *PageRepository) Restore(ctx context.Context, id string, cascade bool, restoredBy int64) error {
+	tx, err := r.GetDB().GetConnection().BeginTx(ctx, nil)
+	if err != nil {
+		return r.HandleSQLError(err, "begin restore page transaction")
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE pages
+		SET is_archived = FALSE, archived_with = NULL, updated_at = $1, last_edited_by = $2
+		WHERE id = $3`,
+		now, restoredBy, id)
+	if err != nil {
+		return r.HandleSQLError(err, "restore page")
+	}
+
+	if cascade {
+		// Walk the current subtree so pages moved out since the archive are left alone
+		_, err = tx.ExecContext(ctx, `
+			WITH RECURSIVE descendants AS (
+				SELECT id FROM pages WHERE parent_id = $1 AND deleted_at IS NULL
+				UNION
+				SELECT p.id FROM pages p
+				INNER JOIN descendants d ON p.parent_id = d.id
+				WHERE p.deleted_at IS NULL
+			)
+			UPDATE pages
+			SET is_archived = FALSE, archived_with = NULL, updated_at = $2
+			WHERE id IN (SELECT id FROM descendants) AND archived_with = $1`,
+			id, now)
+		if err != nil {
+			return r.HandleSQLError(err, "restore descendant pages")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return r.HandleSQLError(err, "commit restore page transaction")
+	}
+
+	r.GetLogger().Info("Page restored successfully", "page_id", id, "cascade", cascade, "restored_by", restoredBy)
+	return nil
+}
+
+// BulkDelete moves several pages and their descendants to the trash with one
+// shared deleted_at, so a selection spanning a parent and its child is
+// restored as a single tree
+func (r *PageRepository) BulkDelete(ctx context.Context, ids []string) (int64, error) {
+	query := `
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM pages WHERE id = ANY($1) AND deleted_at IS NULL
+			UNION
+			SELECT p.id FROM pages p
+			INNER JOIN subtree s ON p.parent_id = s.id
+			WHERE p.deleted_at IS NULL
+		)
+		UPDATE pages
+		SET deleted_at = $2, updated_at = $2
+		WHERE id IN (SELECT id FROM subtree)`
+
+	now := time.Now().UTC()
+
+	result, err := r.ExecuteCommand(ctx, query, pq.Array(ids), now)
+	if err != nil {
+		return 0, r.HandleSQLError(err, "bulk delete pages")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, r.HandleSQLError(err, "get rows affected")
+	}
+
+	r.GetLogger().Info("Pages moved to trash successfully", "requested", len(ids), "count", rowsAffected)
+	return rowsAffected, nil
+}
+
+// BulkArchive archives several pages and, when includeDescendants is set,
+// every live page beneath them
+func (r *PageRepository) BulkArchive(ctx context.Context, ids []string, includeDescendants bool, archivedBy int64) (int64, error) {
+	rowsAffected, err := r.archivePages(ctx, ids, includeDescendants, archivedBy)
+	if err != nil {
+		return 0, err
+	}
+
+	r.GetLogger().Info("Pages archived successfully",
+		"requested", len(ids),
+		"count", rowsAffected,
+		"include_descendants", includeDescendants,
+		"archived_by", archivedBy,
+	)
+	return rowsAffected, nil
+}
+
+// archivePages archives the listed pages directly and, with cascade, marks
+// each live descendant that is not yet archived with the nearest listed
+// ancestor in archived_with. Descendants that were already archived keep
+// their state so a later cascading restore leaves them archived.
+func (r *PageRepository) archivePages(ctx context.Context, ids []string, cascade bool, archivedBy int64) (int64, error) {
+	tx, err := r.GetDB().GetConnection().BeginTx(ctx, nil)
+	if err != nil {
+		return 0, r.HandleSQLError(err, "begin archive pages transaction")
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE pages
+		SET is_archived = TRUE, archived_with = NULL, updated_at = $2, last_edited_by = $3
+		WHERE id = ANY($1) AND deleted_at IS NULL`,
+		pq.Array(ids), now, archivedBy)
+	if err != nil {
+		return 0, r.HandleSQLError(err, "archive pages")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, r.HandleSQLError(err, "get rows affected")
+	}
+
+	if cascade {
+		// The walk stops at listed pages, so every descendant has exactly one root
+		result, err = tx.ExecContext(ctx, `
+			WITH RECURSIVE descendants AS (
+				SELECT id, parent_id AS root FROM pages
+				WHERE parent_id = ANY($1) AND NOT id = ANY($1) AND deleted_at IS NULL
+				UNION
+				SELECT p.id, d.root FROM pages p
+				INNER JOIN descendants d ON p.parent_id = d.id
+				WHERE NOT p.id = ANY($1) AND p.deleted_at IS NULL
+			)
+			UPDATE pages p
+			SET is_archived = TRUE, archived_with = d.root, updated_at = $2
+			FROM descendants d
+			WHERE p.id = d.id AND p.is_archived = FALSE`,
+			pq.Array(ids), now)
+		if err != nil {
+			return 0, r.HandleSQLError(err, "archive descendant pages")
+		}
+
+		descendants, err := result.RowsAffected()
+		if err != nil {
+			return 0, r.HandleSQLError(err, "get rows affected")
+		}
+		rowsAffected += descendants
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, r.HandleSQLError(err, "commit archive pages transaction")
+	}
+
+	return rowsAffected, nil
+}
+
+// searchVisibilityCondition restricts search results to pages the user can
+// view, mirroring HasPermission with PermissionView: the owner, anyone with an
+// explicit page permission, and members of the page's workspace unless its
+// default page permission is none
+const searchVisibilityCondition = `
+		  AND (pages.owner_id = $3
+		       OR EXISTS(SELECT 1 FROM page_permissions pp WHERE pp.page_id = pages.id AND pp.user_id = $3)
+		       OR EXISTS(SELECT 1 FROM workspace_members wm
+		                 INNER JOIN workspaces w ON w.id = wm.workspace_id
+		                 WHERE wm.workspace_id = pages.workspace_id AND wm.user_id = $3
+		                   AND w.default_page_permission <> 'none'))`
+
+// recentVisibilityCondition applies the same rules to recent page listings,
+// which already join the user's workspace memberships as wm and the
+// workspace as w, with the user bound to $1
+const recentVisibilityCondition = `
+		  AND (p.owner_id = $1
+		       OR w.default_page_permission <> 'none'
+		       OR EXISTS(SELECT 1 FROM page_permissions pp WHERE pp.page_id = p.id AND pp.user_id = $1))`
+
+// searchCondition matches the pages a search can return, binding the
+// workspace to $1, the text query to $2 and the user to $3. The query matches
+// the title or the text of any block; an empty one matches every page.
+const searchCondition = `
+		WHERE workspace_id = $1 AND deleted_at IS NULL
+		  AND ($2 = ''
+		       OR to_tsvector('english', title) @@ plainto_tsquery('english', $2)
+		       OR EXISTS(SELECT 1 FROM blocks b WHERE b.page_id = pages.id
+		                 AND jsonb_to_tsvector('english', b.block_data, '["string"]') @@ plainto_tsquery('english', $2)))` +
+	searchVisibilityCondition
+
+func (r *PageRepository) Search(ctx context.Context, workspaceID int64, userID int64, query string, filters repository.SearchFilters, limit, offset int) ([]*repository.Page, error) {
+	sqlQuery := `
+		SELECT id, title, workspace_id, owner_id, parent_id, icon, cover_url,
+			   is_archived, is_template, properties, created_at, updated_at, last_edited_by
+		FROM pages` +
+		searchCondition
+	args := []interface{}{workspaceID, query, userID}
+
+	sqlQuery, args = appendSearchFilters(sqlQuery, args, filters)
+	sqlQuery += ` ORDER BY `
+	if query != "" {
+		sqlQuery += `ts_rank(to_tsvector('english', title), plainto_tsquery('english', $2)) DESC, `
+	}
+	sqlQuery += fmt.Sprintf(`updated_at DESC, id DESC LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
+	args = append(args, limit, offset)
+
+	rows, err := r.ExecuteQuery(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, r.HandleSQLError(err, "search pages")
+	}
+	defer rows.Close()
+
+	return r.scanPages(rows)
+}
+
+// SearchCount returns the number of pages Search would match across all
+// result pages, using the same text, filter and visibility predicates
+func (r *PageRepository) SearchCount(ctx context.Context, workspaceID int64, userID int64, query string, filters repository.SearchFilters) (int64, error) {
+	sqlQuery := `
+		SELECT COUNT(*)
+		FROM pages` +
+		searchCondition
+	args := []interface{}{workspaceID, query, userID}
+
+	sqlQuery, args = appendSearchFilters(sqlQuery, args, filters)
+
+	var total int64
+	if err := r.ExecuteQueryRow(ctx, sqlQuery, args...).Scan(&total); err != nil {
+		return 0, r.HandleSQLError(err, "count search results")
+	}
+
+	return total, nil
+}
+
+// appendSearchFilters adds the optional search filters as bound predicates
+func appendSearchFilters(query string, args []interface{}, filters repository.SearchFilters) (string, []interface{}) {
+	if !filters.IncludeArchived {
+		query += ` AND is_archived = FALSE`
+	}
+	if filters.IsTemplate != nil {
+		args = append(args, *filters.IsTemplate)
+		query += fmt.Sprintf(` AND is_template = $%d`, len(args))
+	}
+	if filters.OwnerID != nil {
+		args = append(args, *filters.OwnerID)
+		query += fmt.Sprintf(` AND owner_id = $%d`, len(args))
+	}
+	if filters.UpdatedAfter != nil {
+		args = append(args, *filters.UpdatedAfter)
+		query += fmt.Sprintf(` AND updated_at >= $%d`, len(args))
+	}
+	if filters.UpdatedBefore != nil {
+		args = append(args, *filters.UpdatedBefore)
+		query += fmt.Sprintf(` AND updated_at < $%d`, len(args))
+	}
+	return query, args
+}
+
+// propertyVisibilityCondition is searchVisibilityCondition with the user
+// bound to $2, for queries that have no search term
+var propertyVisibilityCondition = strings.ReplaceAll(searchVisibilityCondition, "$3", "$2")
+
+// treeVisibilityCondition and treeChildVisibilityCondition apply
+// searchVisibilityCondition to the pages aliased p and c in GetTree, with the
+// user bound to $5
+var (
+	treeVisibilityCondition      = strings.NewReplacer("pages.", "p.", "$3", "$5").Replace(searchVisibilityCondition)
+	treeChildVisibilityCondition = strings.NewReplacer("pages.", "c.", "$3", "$5").Replace(searchVisibilityCondition)
+)
+
+// QueryByProperties lists live, non-template pages whose properties match
+// every filter, visible to the user under the same rules as Search
+func (r *PageRepository) QueryByProperties(ctx context.Context, workspaceID int64, userID int64, filters []repository.PropertyFilter, sort *repository.PropertySort, limit, offset int) ([]*repository.Page, error) {
+	sqlQuery := `
+		SELECT id, title, workspace_id, owner_id, parent_id, icon, cover_url,
+			   is_archived, is_template, properties, created_at, updated_at, last_edited_by
+		FROM pages
+		WHERE workspace_id = $1 AND deleted_at IS NULL
+		  AND is_archived = FALSE AND is_template = FALSE` +
+		propertyVisibilityCondition
+	args := []interface{}{workspaceID, userID}
+
+	sqlQuery, args, err := appendPropertyFilters(sqlQuery, args, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlQuery += ` ORDER BY `
+	if sort != nil {
+		direction := "ASC"
+		if sort.Descending {
+			direction = "DESC"
+		}
+		args = append(args, sort.Key)
+		sqlQuery += fmt.Sprintf(`properties -> $%d::text %s NULLS LAST, `, len(args), direction)
+	}
+	sqlQuery += fmt.Sprintf(`updated_at DESC, id DESC LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
+	args = append(args, limit, offset)
+
+	rows, err := r.ExecuteQuery(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, r.HandleSQLError(err, "query pages by properties")
+	}
+	defer rows.Close()
+
+	return r.scanPages(rows)
+}
+
+// CountByProperties returns the number of pages QueryByProperties would
+// match across all result pages
+func (r *PageRepository) CountByProperties(ctx context.Context, workspaceID int64, userID int64, filters []repository.PropertyFilter) (int64, error) {
+	sqlQuery := `
+		SELECT COUNT(*)
+		FROM pages
+		WHERE workspace_id = $1 AND deleted_at IS NULL
+		  AND is_archived = FALSE AND is_template = FALSE` +
+		propertyVisibilityCondition
+	args := []interface{}{workspaceID, userID}
+
+	sqlQuery, args, err := appendPropertyFilters(sqlQuery, args, filters)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	if err := r.ExecuteQueryRow(ctx, sqlQuery, args...).Scan(&total); err != nil {
+		return 0, r.HandleSQLError(err, "count pages by properties")
+	}
+
+	return total, nil
+}
+
+// appendPropertyFilters adds one predicate per filter to a pages query. Keys
+// and values are always bound as parameters. Equality, array membership and
+// key existence use operators the GIN index on properties can serve; range
+// filters only match values of the same kind as the bound one, so a number
+// never compares against a string and unparseable dates are skipped.
+func appendPropertyFilters(query string, args []interface{}, filters []repository.PropertyFilter) (string, []interface{}, error) {
+	bind := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	for _, filter := range filters {
+		switch filter.Op {
+		case repository.PropertyOpEquals, repository.PropertyOpNotEquals, repository.PropertyOpContains:
+			value, ok := filter.Value.(json.RawMessage)
+			if !ok {
+				return "", nil, fmt.Errorf("property filter %s on %q needs a JSON value", filter.Op, filter.Key)
+			}
+
+			switch filter.Op {
+			case repository.PropertyOpEquals:
+				document, err := json.Marshal(map[string]json.RawMessage{filter.Key: value})
+				if err != nil {
+					return "", nil, err
+				}
+				query += fmt.Sprintf(` AND properties @> %s::jsonb`, bind(string(document)))
+			case repository.PropertyOpNotEquals:
+				query += fmt.Sprintf(` AND (properties -> %s::text) IS DISTINCT FROM %s::jsonb`, bind(filter.Key), bind(string(value)))
+			case repository.PropertyOpContains:
+				// Matches arrays holding the value and, for strings, text
+				// containing it case-insensitively
+				document, err := json.Marshal(map[string][]json.RawMessage{filter.Key: {value}})
+				if err != nil {
+					return "", nil, err
+				}
+				condition := fmt.Sprintf(`properties @> %s::jsonb`, bind(string(document)))
+
+				var text string
+				if json.Unmarshal(value, &text) == nil {
+					key := bind(filter.Key)
+					condition += fmt.Sprintf(` OR (jsonb_typeof(properties -> %s::text) = 'string' AND strpos(LOWER(properties ->> %s::text), LOWER(%s)) > 0)`,
+						key, key, bind(text))
+				}
+				query += ` AND (` + condition + `)`
+			}
+		case repository.PropertyOpGreaterThan, repository.PropertyOpGreaterOrEqual, repository.PropertyOpLessThan, repository.PropertyOpLessOrEqual:
+			operator := map[string]string{
+				repository.PropertyOpGreaterThan:    ">",
+				repository.PropertyOpGreaterOrEqual: ">=",
+				repository.PropertyOpLessThan:       "<",
+				repository.PropertyOpLessOrEqual:    "<=",
+			}[filter.Op]
+
+			key := bind(filter.Key)
+			switch value := filter.Value.(type) {
+			case float64:
+				query += fmt.Sprintf(` AND CASE WHEN jsonb_typeof(properties -> %s::text) = 'number' THEN (properties ->> %s::text)::numeric END %s %s::numeric`,
+					key, key, operator, bind(value))
+			case time.Time:
+				query += fmt.Sprintf(` AND CASE WHEN jsonb_typeof(properties -> %s::text) = 'string' THEN try_timestamptz(properties ->> %s::text) END %s %s::timestamptz`,
+					key, key, operator, bind(value))
+			default:
+				return "", nil, fmt.Errorf("property filter %s on %q needs a number or a time", filter.Op, filter.Key)
+			}
+		case repository.PropertyOpExists:
+			query += fmt.Sprintf(` AND properties ? %s::text`, bind(filter.Key))
+		default:
+			return "", nil, fmt.Errorf("unsupported property filter operator %q", filter.Op)
+		}
+	}
+
+	return query, args, nil
+}
+
+// HasSiblingWithTitle reports whether a non-archived page with the same
+// (trimmed, case-insensitive) title already exists under the given parent
+func (r *PageRepository) HasSiblingWithTitle(ctx context.Context, workspaceID int64, parentID *string, title string) (bool, error) {
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM pages
+			WHERE workspace_id = $1
+			  AND parent_id IS NOT DISTINCT FROM $2
+			  AND is_archived = FALSE
+			  AND deleted_at IS NULL
+			  AND LOWER(TRIM(title)) = LOWER(TRIM($3))
+		)`
+
+	var exists bool
+	if err := r.ExecuteQueryRow(ctx, query, workspaceID, parentID, title).Scan(&exists); err != nil {
+		return false, r.HandleSQLError(err, "check sibling title")
+	}
+
+	return exists, nil
+}
+
+func (r *PageRepository) GetRecentPages(ctx context.Context, userID int64, limit int) ([]*repository.Page, error) {
+	query := `
+		SELECT DISTINCT p.id, p.title, p.workspace_id, p.owner_id, p.parent_id, p.icon, p.cover_url,
+			   p.is_archived, p.is_template, p.properties, p.created_at, p.updated_at, p.last_edited_by
+		FROM pages p
+		INNER JOIN workspace_members wm ON p.workspace_id = wm.workspace_id
+		INNER JOIN workspaces w ON w.id = p.workspace_id
+		WHERE wm.user_id = $1 AND p.is_archived = FALSE AND p.deleted_at IS NULL` +
+		recentVisibilityCondition + `
+		ORDER BY p.updated_at DESC
+		LIMIT $2`
+
+	rows, err := r.ExecuteQuery(ctx, query, userID, limit)
 	if err != nil {
-		return nil, r.HandleSQLError(err, "get pages by workspace id")
+		return nil, r.HandleSQLError(err, "get recent pages")
 	}
 	defer rows.Close()
 
@@ -152,71 +1172,100 @@ func (r *PageRepository) GetByWorkspaceID(ctx context.Context, workspaceID int64
 	return pages, nil
 }
 
-func (r *PageRepository) GetByParentID(ctx context.Context, parentID string, includeArchived bool) ([]*repository.Page, error) {
+// GetByWorkspaceIDAfter lists up to limit pages of the workspace the user can
+// view that come after the cursor in updated_at DESC, id DESC order. A nil
+// cursor starts from the most recently updated page.
+func (r *PageRepository) GetByWorkspaceIDAfter(ctx context.Context, workspaceID int64, userID int64, includeArchived, includeTemplates bool, after *repository.Cursor, limit int) ([]*repository.Page, error) {
 	query := `
 		SELECT id, title, workspace_id, owner_id, parent_id, icon, cover_url,
 			   is_archived, is_template, properties, created_at, updated_at, last_edited_by
-		FROM pages 
-		WHERE parent_id = $1`
+		FROM pages
+		WHERE workspace_id = $1 AND deleted_at IS NULL` +
+		propertyVisibilityCondition
+	args := []interface{}{workspaceID, userID}
 
 	if !includeArchived {
 		query += ` AND is_archived = FALSE`
 	}
 
-	query += ` ORDER BY updated_at DESC`
+	if !includeTemplates {
+		query += ` AND is_template = FALSE`
+	}
 
-	rows, err := r.ExecuteQuery(ctx, query, parentID)
+	query, args = appendCursorCondition(query, args, "", after)
+	query += fmt.Sprintf(` ORDER BY updated_at DESC, id DESC LIMIT $%d`, len(args)+1)
+	args = append(args, limit)
+
+	rows, err := r.ExecuteQuery(ctx, query, args...)
 	if err != nil {
-		return nil, r.HandleSQLError(err, "get pages by parent id")
+		return nil, r.HandleSQLError(err, "get pages by workspace id")
 	}
 	defer rows.Close()
 
-	var pages []*repository.Page
-	for rows.Next() {
-		page := &repository.Page{}
-		err := rows.Scan(
-			&page.ID,
-			&page.Title,
-			&page.WorkspaceID,
-			&page.OwnerID,
-			&page.ParentID,
-			&page.Icon,
-			&page.CoverURL,
-			&page.IsArchived,
-			&page.IsTemplate,
-			&page.Properties,
-			&page.CreatedAt,
-			&page.UpdatedAt,
-			&page.LastEditedBy,
-		)
-		if err != nil {
-			return nil, r.HandleSQLError(err, "scan page")
-		}
-		pages = append(pages, page)
+	return r.scanPages(rows)
+}
+
+// GetRecentPagesAfter is the cursor-paginated form of GetRecentPages
+func (r *PageRepository) GetRecentPagesAfter(ctx context.Context, userID int64, after *repository.Cursor, limit int) ([]*repository.Page, error) {
+	query := `
+		SELECT p.id, p.title, p.workspace_id, p.owner_id, p.parent_id, p.icon, p.cover_url,
+			   p.is_archived, p.is_template, p.properties, p.created_at, p.updated_at, p.last_edited_by
+		FROM pages p
+		INNER JOIN workspace_members wm ON p.workspace_id = wm.workspace_id
+		INNER JOIN workspaces w ON w.id = p.workspace_id
+		WHERE wm.user_id = $1 AND p.is_archived = FALSE AND p.deleted_at IS NULL` +
+		recentVisibilityCondition
+	args := []interface{}{userID}
+
+	query, args = appendCursorCondition(query, args, "p.", after)
+	query += fmt.Sprintf(` ORDER BY p.updated_at DESC, p.id DESC LIMIT $%d`, len(args)+1)
+	args = append(args, limit)
+
+	rows, err := r.ExecuteQuery(ctx, query, args...)
+	if err != nil {
+		return nil, r.HandleSQLError(err, "get recent pages")
 	}
+	defer rows.Close()
 
-	return pages, nil
+	return r.scanPages(rows)
 }
 
-func (r *PageRepository) GetRootPages(ctx context.Context, workspaceID int64, includeArchived bool) ([]*repository.Page, error) {
-	query := `
+// SearchAfter is the cursor-paginated form of Search. Results are ordered by
+// updated_at rather than relevance so the cursor stays stable.
+func (r *PageRepository) SearchAfter(ctx context.Context, workspaceID int64, userID int64, query string, filters repository.SearchFilters, after *repository.Cursor, limit int) ([]*repository.Page, error) {
+	sqlQuery := `
 		SELECT id, title, workspace_id, owner_id, parent_id, icon, cover_url,
 			   is_archived, is_template, properties, created_at, updated_at, last_edited_by
-		FROM pages 
-		WHERE workspace_id = $1 AND parent_id IS NULL`
+		FROM pages` +
+		searchCondition
+	args := []interface{}{workspaceID, query, userID}
 
-	if !includeArchived {
-		query += ` AND is_archived = FALSE`
-	}
-
-	query += ` ORDER BY updated_at DESC`
+	sqlQuery, args = appendSearchFilters(sqlQuery, args, filters)
+	sqlQuery, args = appendCursorCondition(sqlQuery, args, "", after)
+	sqlQuery += fmt.Sprintf(` ORDER BY updated_at DESC, id DESC LIMIT $%d`, len(args)+1)
+	args = append(args, limit)
 
-	rows, err := r.ExecuteQuery(ctx, query, workspaceID)
+	rows, err := r.ExecuteQuery(ctx, sqlQuery, args...)
 	if err != nil {
-		return nil, r.HandleSQLError(err, "get root pages")
+		return nil, r.HandleSQLError(err, "search pages")
 	}
 	defer rows.Close()
 
+	return r.scanPages(rows)
+}
+
+// appendCursorCondition restricts an updated_at DESC, id DESC listing to rows
+// after the cursor. prefix is the table alias including the dot, if any.
+func appendCursorCondition(query string, args []interface{}, prefix string, after *repository.Cursor) (string, []interface{}) {
+	if after == nil {
+		return query, args
+	}
+
+	query += fmt.Sprintf(` AND (%supdated_at, %sid) < ($%d, $%d::uuid)`, prefix, prefix, len(args)+1, len(args)+2)
+	return query, append(args, after.UpdatedAt, after.ID)
+}
+
+func (r *PageRepository) scanPages(rows *sql.Rows) ([]*repository.Page, error) {
 	var pages []*repository.Page
 	for rows.Next() {
 		page := &repository.Page{}
@@ -244,92 +1293,47 @@ func (r *PageRepository) GetRootPages(ctx context.Context, workspaceID int64, in
 	return pages, nil
 }
 
-func (r *PageRepository) Update(ctx context.Context, page *repository.Page) error {
+// AddFavorite stars a page for the user; starring it twice is a no-op
+func (r *PageRepository) AddFavorite(ctx context.Context, userID int64, pageID string) error {
 	query := `
-		UPDATE pages 
-		SET title = $1, icon = $2, cover_url = $3, is_template = $4, 
-			properties = $5, updated_at = $6, last_edited_by = $7
-		WHERE id = $8`
-
-	page.UpdatedAt = time.Now().UTC()
-
-	_, err := r.ExecuteCommand(ctx, query,
-		page.Title,
-		page.Icon,
-		page.CoverURL,
-		page.IsTemplate,
-		page.Properties,
-		page.UpdatedAt,
-		page.LastEditedBy,
-		page.ID,
-	)
+		INSERT INTO page_favorites (user_id, page_id, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, page_id) DO NOTHING`
 
+	_, err := r.ExecuteCommand(ctx, query, userID, pageID, time.Now().UTC())
 	if err != nil {
-		return r.HandleSQLError(err, "update page")
-	}
-
-	r.GetLogger().Info("Page updated successfully", "page_id", page.ID)
-	return nil
-}
-
-func (r *PageRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM pages WHERE id = $1`
-
-	_, err := r.ExecuteCommand(ctx, query, id)
-	if err != nil {
-		return r.HandleSQLError(err, "delete page")
+		return r.HandleSQLError(err, "add favorite")
 	}
 
-	r.GetLogger().Info("Page deleted successfully", "page_id", id)
+	r.GetLogger().Info("Page favorite added successfully", "page_id", pageID, "user_id", userID)
 	return nil
 }
 
-func (r *PageRepository) Archive(ctx context.Context, id string, archivedBy int64) error {
-	query := `
-		UPDATE pages 
-		SET is_archived = TRUE, updated_at = $1, last_edited_by = $2
-		WHERE id = $3`
-
-	now := time.Now().UTC()
+func (r *PageRepository) RemoveFavorite(ctx context.Context, userID int64, pageID string) error {
+	query := `DELETE FROM page_favorites WHERE user_id = $1 AND page_id = $2`
 
-	_, err := r.ExecuteCommand(ctx, query, now, archivedBy, id)
+	_, err := r.ExecuteCommand(ctx, query, userID, pageID)
 	if err != nil {
-		return r.HandleSQLError(err, "archive page")
+		return r.HandleSQLError(err, "remove favorite")
 	}
 
-	r.GetLogger().Info("Page archived successfully", "page_id", id, "archived_by", archivedBy)
+	r.GetLogger().Info("Page favorite removed successfully", "page_id", pageID, "user_id", userID)
 	return nil
 }
 
-func (r *PageRepository) Restore(ctx context.Context, id string, restoredBy int64) error {
+// ListFavorites returns the user's starred pages, most recently starred first
+func (r *PageRepository) ListFavorites(ctx context.Context, userID int64) ([]*repository.Page, error) {
 	query := `
-		UPDATE pages 
-		SET is_archived = FALSE, updated_at = $1, last_edited_by = $2
-		WHERE id = $3`
-
-	now := time.Now().UTC()
-
-	_, err := r.ExecuteCommand(ctx, query, now, restoredBy, id)
-	if err != nil {
-		return r.HandleSQLError(err, "restore page")
-	}
-
-	r.GetLogger().Info("Page restored successfully", "page_id", id, "restored_by", restoredBy)
-	return nil
-}
-
-func (r *PageRepository) Search(ctx context.Context, workspaceID int64, query string, limit, offset int) ([]*repository.Page, error) {
-	sqlQuery := `
-		SELECT id, title, workspace_id, owner_id, parent_id, icon, cover_url,
-			   is_archived, is_template, properties, created_at, updated_at, last_edited_by
-		FROM pages 
-		WHERE workspace_id = $1 AND to_tsvector('english', title) @@ plainto_tsquery('english', $2)
-		ORDER BY ts_rank(to_tsvector('english', title), plainto_tsquery('english', $2)) DESC
-		LIMIT $3 OFFSET $4`
+		SELECT p.id, p.title, p.workspace_id, p.owner_id, p.parent_id, p.icon, p.cover_url,
+			   p.is_archived, p.is_template, p.properties, p.created_at, p.updated_at, p.last_edited_by
+		FROM page_favorites f
+		INNER JOIN pages p ON p.id = f.page_id
+		WHERE f.user_id = $1 AND p.deleted_at IS NULL
+		ORDER BY f.created_at DESC`
 
-	rows, err := r.ExecuteQuery(ctx, sqlQuery, workspaceID, query, limit, offset)
+	rows, err := r.ExecuteQuery(ctx, query, userID)
 	if err != nil {
-		return nil, r.HandleSQLError(err, "search pages")
+		return nil, r.HandleSQLError(err, "list favorites")
 	}
 	defer rows.Close()
 
@@ -360,19 +1364,19 @@ func (r *PageRepository) Search(ctx context.Context, workspaceID int64, query st
 	return pages, nil
 }
 
-func (r *PageRepository) GetRecentPages(ctx context.Context, userID int64, limit int) ([]*repository.Page, error) {
+// GetOrphanedPages returns pages whose parent_id points at a page that no longer exists
+func (r *PageRepository) GetOrphanedPages(ctx context.Context, workspaceID int64) ([]*repository.Page, error) {
 	query := `
-		SELECT DISTINCT p.id, p.title, p.workspace_id, p.owner_id, p.parent_id, p.icon, p.cover_url,
+		SELECT p.id, p.title, p.workspace_id, p.owner_id, p.parent_id, p.icon, p.cover_url,
 			   p.is_archived, p.is_template, p.properties, p.created_at, p.updated_at, p.last_edited_by
 		FROM pages p
-		INNER JOIN workspace_members wm ON p.workspace_id = wm.workspace_id
-		WHERE wm.user_id = $1 AND p.is_archived = FALSE
-		ORDER BY p.updated_at DESC
-		LIMIT $2`
+		LEFT JOIN pages parent ON p.parent_id = parent.id
+		WHERE p.workspace_id = $1 AND p.parent_id IS NOT NULL AND parent.id IS NULL AND p.deleted_at IS NULL
+		ORDER BY p.updated_at DESC`
 
-	rows, err := r.ExecuteQuery(ctx, query, userID, limit)
+	rows, err := r.ExecuteQuery(ctx, query, workspaceID)
 	if err != nil {
-		return nil, r.HandleSQLError(err, "get recent pages")
+		return nil, r.HandleSQLError(err, "get orphaned pages")
 	}
 	defer rows.Close()
 
@@ -403,6 +1407,143 @@ func (r *PageRepository) GetRecentPages(ctx context.Context, userID int64, limit
 	return pages, nil
 }
 
+// ReparentOrphanedPages moves every orphaned page in the workspace under
+// newParentID (or to the root when nil) and returns the number of pages moved
+func (r *PageRepository) ReparentOrphanedPages(ctx context.Context, workspaceID int64, newParentID *string, repairedBy int64) (int64, error) {
+	query := `
+		UPDATE pages p
+		SET parent_id = $2, updated_at = NOW(), last_edited_by = $3
+		WHERE p.workspace_id = $1
+		  AND p.deleted_at IS NULL
+		  AND p.parent_id IS NOT NULL
+		  AND NOT EXISTS (SELECT 1 FROM pages parent WHERE parent.id = p.parent_id)`
+
+	result, err := r.ExecuteCommand(ctx, query, workspaceID, newParentID, repairedBy)
+	if err != nil {
+		return 0, r.HandleSQLError(err, "reparent orphaned pages")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, r.HandleSQLError(err, "get rows affected")
+	}
+
+	r.GetLogger().Info("Orphaned pages reparented successfully", "workspace_id", workspaceID, "count", rowsAffected)
+	return rowsAffected, nil
+}
+
+// Move re-parents a page and, when the workspace changes, moves every
+// descendant page into the new workspace within the same transaction
+func (r *PageRepository) Move(ctx context.Context, id string, newParentID *string, newWorkspaceID int64, movedBy int64) error {
+	tx, err := r.GetDB().GetConnection().BeginTx(ctx, nil)
+	if err != nil {
+		return r.HandleSQLError(err, "begin move page transaction")
+	}
+	defer tx.Rollback()
+
+	var currentWorkspaceID int64
+	err = tx.QueryRowContext(ctx, `SELECT workspace_id FROM pages WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, id).Scan(&currentWorkspaceID)
+	if err != nil {
+		return r.HandleSQLError(err, "lock page for move")
+	}
+
+	if newParentID != nil {
+		if err := r.lockParentChain(ctx, tx, id, *newParentID); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now().UTC()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE pages
+		SET parent_id = $1, workspace_id = $2, updated_at = $3, last_edited_by = $4
+		WHERE id = $5`,
+		newParentID, newWorkspaceID, now, movedBy, id)
+	if err != nil {
+		return r.HandleSQLError(err, "move page")
+	}
+
+	if currentWorkspaceID != newWorkspaceID {
+		_, err = tx.ExecContext(ctx, `
+			WITH RECURSIVE descendants AS (
+				SELECT id FROM pages WHERE parent_id = $1
+				UNION
+				SELECT p.id FROM pages p
+				INNER JOIN descendants d ON p.parent_id = d.id
+			)
+			UPDATE pages
+			SET workspace_id = $2, updated_at = $3
+			WHERE id IN (SELECT id FROM descendants)`,
+			id, newWorkspaceID, now)
+		if err != nil {
+			return r.HandleSQLError(err, "move descendant pages")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return r.HandleSQLError(err, "commit move page transaction")
+	}
+
+	r.GetLogger().Info("Page moved successfully", "page_id", id, "workspace_id", newWorkspaceID)
+	return nil
+}
+
+// parentChainQuery walks up from $1 to the root, stopping at a parent_id cycle
+const parentChainQuery = `
+	WITH RECURSIVE chain AS (
+		SELECT id, parent_id, ARRAY[id] AS path
+		FROM pages
+		WHERE id = $1
+		UNION ALL
+		SELECT p.id, p.parent_id, c.path || p.id
+		FROM pages p
+		INNER JOIN chain c ON p.id = c.parent_id
+		WHERE NOT p.id = ANY(c.path)
+	)`
+
+// lockParentChain locks the new parent and every page above it, then checks
+// that the moved page is not among them. Holding the locks keeps a concurrent
+// move from reparenting an ancestor between the check and the update.
+func (r *PageRepository) lockParentChain(ctx context.Context, tx *sql.Tx, id, newParentID string) error {
+	rows, err := tx.QueryContext(ctx, parentChainQuery+`
+		SELECT id FROM pages WHERE id IN (SELECT id FROM chain) ORDER BY id FOR UPDATE`, newParentID)
+	if err != nil {
+		return r.HandleSQLError(err, "lock parent chain")
+	}
+
+	locked := false
+	for rows.Next() {
+		var chainID string
+		if err := rows.Scan(&chainID); err != nil {
+			rows.Close()
+			return r.HandleSQLError(err, "scan parent chain")
+		}
+		if chainID == newParentID {
+			locked = true
+		}
+	}
+	rows.Close()
+
+	if !locked {
+		return errors.NewNotFoundError("Parent page")
+	}
+
+	// Re-read the chain now that no page on it can be moved underneath us
+	var cycle bool
+	err = tx.QueryRowContext(ctx, parentChainQuery+`
+		SELECT EXISTS(SELECT 1 FROM chain WHERE id = $2)`, newParentID, id).Scan(&cycle)
+	if err != nil {
+		return r.HandleSQLError(err, "check page cycle")
+	}
+
+	if cycle {
+		return errors.NewValidationError("Invalid page move", "would create a page cycle")
+	}
+
+	return nil
+}
+
 func (r *PageRepository) CreateVersion(ctx context.Context, version *repository.PageVersion) error {
 	if version.ID == "" {
 		version.ID = uuid.New().String()
@@ -614,9 +1755,57 @@ func (r *PageRepository) ListPermissions(ctx context.Context, pageID string) ([]
 	return permissions, nil
 }
 
+func (r *PageRepository) ListPermissionsPaginated(ctx context.Context, pageID string, limit, offset int) ([]*repository.PagePermission, error) {
+	query := `
+		SELECT id, page_id, user_id, permission, granted_by, created_at, updated_at
+		FROM page_permissions
+		WHERE page_id = $1
+		ORDER BY created_at ASC, id ASC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.ExecuteQuery(ctx, query, pageID, limit, offset)
+	if err != nil {
+		return nil, r.HandleSQLError(err, "list page permissions paginated")
+	}
+	defer rows.Close()
+
+	var permissions []*repository.PagePermission
+	for rows.Next() {
+		permission := &repository.PagePermission{}
+		err := rows.Scan(
+			&permission.ID,
+			&permission.PageID,
+			&permission.UserID,
+			&permission.Permission,
+			&permission.GrantedBy,
+			&permission.CreatedAt,
+			&permission.UpdatedAt,
+		)
+		if err != nil {
+			return nil, r.HandleSQLError(err, "scan page permission")
+		}
+		permissions = append(permissions, permission)
+	}
+
+	return permissions, nil
+}
+
 func (r *PageRepository) HasPermission(ctx context.Context, pageID string, userID int64, requiredLevel repository.PermissionLevel) (bool, error) {
+	return r.hasPermission(ctx, pageID, userID, requiredLevel, false)
+}
+
+func (r *PageRepository) HasTrashPermission(ctx context.Context, pageID string, userID int64, requiredLevel repository.PermissionLevel) (bool, error) {
+	return r.hasPermission(ctx, pageID, userID, requiredLevel, true)
+}
+
+// hasPermission checks access to a live page, or with trashed set, to a page
+// in the trash
+func (r *PageRepository) hasPermission(ctx context.Context, pageID string, userID int64, requiredLevel repository.PermissionLevel, trashed bool) (bool, error) {
 	// First check if user is the page owner
-	pageQuery := `SELECT owner_id FROM pages WHERE id = $1`
+	pageQuery := `SELECT owner_id FROM pages WHERE id = $1 AND deleted_at IS NULL`
+	if trashed {
+		pageQuery = `SELECT owner_id FROM pages WHERE id = $1 AND deleted_at IS NOT NULL`
+	}
 	var ownerID int64
 	if err := r.ExecuteQueryRow(ctx, pageQuery, pageID).Scan(&ownerID); err != nil {
 		if err == sql.ErrNoRows {
@@ -639,24 +1828,27 @@ func (r *PageRepository) HasPermission(ctx context.Context, pageID string, userI
 	err := r.ExecuteQueryRow(ctx, permissionQuery, pageID, userID).Scan(&permission)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			// Check workspace membership for default access
+			// Fall back to the workspace default for members
 			workspaceQuery := `
-				SELECT EXISTS(
-					SELECT 1 FROM pages p
-					INNER JOIN workspace_members wm ON p.workspace_id = wm.workspace_id
-					WHERE p.id = $1 AND wm.user_id = $2
-				)`
-
-			var hasWorkspaceAccess bool
-			if err := r.ExecuteQueryRow(ctx, workspaceQuery, pageID, userID).Scan(&hasWorkspaceAccess); err != nil {
+				SELECT w.default_page_permission
+				FROM pages p
+				INNER JOIN workspaces w ON p.workspace_id = w.id
+				INNER JOIN workspace_members wm ON w.id = wm.workspace_id
+				WHERE p.id = $1 AND wm.user_id = $2`
+
+			var defaultLevel repository.PermissionLevel
+			if err := r.ExecuteQueryRow(ctx, workspaceQuery, pageID, userID).Scan(&defaultLevel); err != nil {
+				if err == sql.ErrNoRows {
+					return false, nil
+				}
 				return false, r.HandleSQLError(err, "check workspace access")
 			}
 
-			if hasWorkspaceAccess && requiredLevel == repository.PermissionView {
-				return true, nil // Default view access for workspace members
+			if defaultLevel == repository.PermissionNone {
+				return false, nil
 			}
 
-			return false, nil
+			return r.hasRequiredPermissionLevel(defaultLevel, requiredLevel), nil
 		}
 		return false, r.HandleSQLError(err, "get page permission")
 	}
@@ -664,6 +1856,76 @@ func (r *PageRepository) HasPermission(ctx context.Context, pageID string, userI
 	return r.hasRequiredPermissionLevel(permission, requiredLevel), nil
 }
 
+func (r *PageRepository) GetUserPermissionLevels(ctx context.Context, userID int64, pageIDs []string) (map[string]repository.PermissionLevel, error) {
+	levels := make(map[string]repository.PermissionLevel, len(pageIDs))
+	if len(pageIDs) == 0 {
+		return levels, nil
+	}
+
+	// Same precedence as HasPermission: owner, explicit grant, workspace default
+	query := `
+		SELECT p.id,
+			CASE
+				WHEN p.owner_id = $1 THEN 'admin'
+				WHEN pp.permission IS NOT NULL THEN pp.permission
+				WHEN wm.user_id IS NOT NULL AND w.default_page_permission <> 'none' THEN w.default_page_permission
+			END AS permission
+		FROM pages p
+		INNER JOIN workspaces w ON p.workspace_id = w.id
+		LEFT JOIN page_permissions pp ON pp.page_id = p.id AND pp.user_id = $1
+		LEFT JOIN workspace_members wm ON wm.workspace_id = p.workspace_id AND wm.user_id = $1
+		WHERE p.id = ANY($2) AND p.deleted_at IS NULL`
+
+	rows, err := r.ExecuteQuery(ctx, query, userID, pq.Array(pageIDs))
+	if err != nil {
+		return nil, r.HandleSQLError(err, "get user permission levels")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pageID string
+		var permission sql.NullString
+		if err := rows.Scan(&pageID, &permission); err != nil {
+			return nil, r.HandleSQLError(err, "scan user permission level")
+		}
+		if permission.Valid {
+			levels[pageID] = repository.PermissionLevel(permission.String)
+		}
+	}
+
+	return levels, nil
+}
+
+func (r *PageRepository) CountChildren(ctx context.Context, parentIDs []string) (map[string]int, error) {
+	counts := make(map[string]int, len(parentIDs))
+	if len(parentIDs) == 0 {
+		return counts, nil
+	}
+
+	query := `
+		SELECT parent_id, COUNT(*)
+		FROM pages
+		WHERE parent_id = ANY($1) AND deleted_at IS NULL AND is_archived = FALSE
+		GROUP BY parent_id`
+
+	rows, err := r.ExecuteQuery(ctx, query, pq.Array(parentIDs))
+	if err != nil {
+		return nil, r.HandleSQLError(err, "count child pages")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var parentID string
+		var count int
+		if err := rows.Scan(&parentID, &count); err != nil {
+			return nil, r.HandleSQLError(err, "scan child page count")
+		}
+		counts[parentID] = count
+	}
+
+	return counts, nil
+}
+
 func (r *PageRepository) hasRequiredPermissionLevel(userLevel, requiredLevel repository.PermissionLevel) bool {
 	permissionHierarchy := map[repository.PermissionLevel]int{
 		repository.PermissionView:    1,
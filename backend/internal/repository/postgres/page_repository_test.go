@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+)
+
+func TestHasSiblingWithTitle(t *testing.T) {
+	dbm := openTestDB(t)
+	repo := NewPageRepository(dbm, testLogger())
+	ctx := context.Background()
+
+	ownerID := insertTestUser(t, dbm)
+	workspaceID := insertTestWorkspace(t, dbm, ownerID, "edit")
+	parent := insertTestPage(t, dbm, workspaceID, ownerID, "Parent", nil)
+	insertTestPage(t, dbm, workspaceID, ownerID, "Meeting notes", nil)
+	archived := insertTestPage(t, dbm, workspaceID, ownerID, "Old plan", nil)
+	mustExec(t, dbm, `UPDATE pages SET is_archived = TRUE WHERE id = $1`, archived)
+	trashed := insertTestPage(t, dbm, workspaceID, ownerID, "Trashed", &parent)
+	mustExec(t, dbm, `UPDATE pages SET deleted_at = NOW() WHERE id = $1`, trashed)
+
+	tests := []struct {
+		name     string
+		parentID *string
+		title    string
+		want     bool
+	}{
+		{name: "case and whitespace differ", title: "  meeting NOTES ", want: true},
+		{name: "different parent", parentID: &parent, title: "Meeting notes"},
+		{name: "archived sibling", title: "Old plan"},
+		{name: "trashed sibling", parentID: &parent, title: "Trashed"},
+		{name: "unique title", title: "Roadmap"},
+	}
+	for _, tt := range tests {
+		got, err := repo.HasSiblingWithTitle(ctx, workspaceID, tt.parentID, tt.title)
+		if err != nil {
+			t.Fatalf("%s: HasSiblingWithTitle() error = %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: HasSiblingWithTitle() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/database"
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+type PageShareLinkRepository struct {
+	*repository.BaseRepository
+}
+
+func NewPageShareLinkRepository(db database.Manager, logger *slog.Logger) repository.PageShareLinkRepository {
+	return &PageShareLinkRepository{
+		BaseRepository: repository.NewBaseRepository(db, logger, "page_share_links"),
+	}
+}
+
+func (r *PageShareLinkRepository) Create(ctx context.Context, link *repository.PageShareLink) error {
+	if link.ID == "" {
+		link.ID = uuid.New().String()
+	}
+
+	query := `
+		INSERT INTO page_share_links (id, page_id, token_hash, password_hash, created_by, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	link.CreatedAt = time.Now().UTC()
+
+	_, err := r.ExecuteCommand(ctx, query,
+		link.ID,
+		link.PageID,
+		link.TokenHash,
+		link.PasswordHash,
+		link.CreatedBy,
+		link.ExpiresAt,
+		link.CreatedAt,
+	)
+
+	if err != nil {
+		return r.HandleSQLError(err, "create page share link")
+	}
+
+	r.GetLogger().Info("Page share link created successfully",
+		"share_link_id", link.ID,
+		"page_id", link.PageID)
+
+	return nil
+}
+
+func (r *PageShareLinkRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*repository.PageShareLink, error) {
+	query := `
+		SELECT id, page_id, token_hash, password_hash, created_by, expires_at, revoked_at, created_at
+		FROM page_share_links
+		WHERE token_hash = $1 AND revoked_at IS NULL`
+
+	link := &repository.PageShareLink{}
+	err := r.ExecuteQueryRow(ctx, query, tokenHash).Scan(
+		&link.ID,
+		&link.PageID,
+		&link.TokenHash,
+		&link.PasswordHash,
+		&link.CreatedBy,
+		&link.ExpiresAt,
+		&link.RevokedAt,
+		&link.CreatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, r.HandleSQLError(err, "get page share link")
+	}
+
+	return link, nil
+}
+
+func (r *PageShareLinkRepository) ListByPage(ctx context.Context, pageID string) ([]*repository.PageShareLink, error) {
+	query := `
+		SELECT id, page_id, token_hash, password_hash, created_by, expires_at, revoked_at, created_at
+		FROM page_share_links
+		WHERE page_id = $1 AND revoked_at IS NULL
+		ORDER BY created_at DESC`
+
+	rows, err := r.ExecuteQuery(ctx, query, pageID)
+	if err != nil {
+		return nil, r.HandleSQLError(err, "list page share links")
+	}
+	defer rows.Close()
+
+	var links []*repository.PageShareLink
+	for rows.Next() {
+		link := &repository.PageShareLink{}
+		err := rows.Scan(
+			&link.ID,
+			&link.PageID,
+			&link.TokenHash,
+			&link.PasswordHash,
+			&link.CreatedBy,
+			&link.ExpiresAt,
+			&link.RevokedAt,
+			&link.CreatedAt,
+		)
+		if err != nil {
+			return nil, r.HandleSQLError(err, "scan page share link")
+		}
+		links = append(links, link)
+	}
+
+	return links, nil
+}
+
+func (r *PageShareLinkRepository) Revoke(ctx context.Context, pageID, id string) (bool, error) {
+	query := `
+		UPDATE page_share_links
+		SET revoked_at = $1
+		WHERE id = $2 AND page_id = $3 AND revoked_at IS NULL`
+
+	result, err := r.ExecuteCommand(ctx, query, time.Now().UTC(), id, pageID)
+	if err != nil {
+		return false, r.HandleSQLError(err, "revoke page share link")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, r.HandleSQLError(err, "get rows affected")
+	}
+
+	return rowsAffected > 0, nil
+}
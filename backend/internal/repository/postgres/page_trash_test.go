@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+func TestTrashedPagesGrantNoAccess(t *testing.T) {
+	dbm := openTestDB(t)
+	repo := NewPageRepository(dbm, testLogger())
+	ctx := context.Background()
+
+	ownerID := insertTestUser(t, dbm)
+	editorID := insertTestUser(t, dbm)
+	workspaceID := insertTestWorkspace(t, dbm, ownerID, "edit")
+	addTestMember(t, dbm, workspaceID, editorID, "member", ownerID)
+
+	pageID := insertTestPage(t, dbm, workspaceID, ownerID, "Trashed", nil)
+	grantTestPermission(t, dbm, pageID, editorID, "admin", ownerID)
+	mustExec(t, dbm, `UPDATE pages SET deleted_at = NOW() WHERE id = $1`, pageID)
+
+	for _, userID := range []int64{ownerID, editorID} {
+		ok, err := repo.HasPermission(ctx, pageID, userID, repository.PermissionView)
+		if err != nil {
+			t.Fatalf("HasPermission() error = %v", err)
+		}
+		if ok {
+			t.Errorf("HasPermission() on a trashed page = true for user %d", userID)
+		}
+
+		ok, err = repo.HasTrashPermission(ctx, pageID, userID, repository.PermissionAdmin)
+		if err != nil {
+			t.Fatalf("HasTrashPermission() error = %v", err)
+		}
+		if !ok {
+			t.Errorf("HasTrashPermission(admin) = false for user %d, want true", userID)
+		}
+	}
+
+	levels, err := repo.GetUserPermissionLevels(ctx, ownerID, []string{pageID})
+	if err != nil {
+		t.Fatalf("GetUserPermissionLevels() error = %v", err)
+	}
+	if level, ok := levels[pageID]; ok {
+		t.Errorf("GetUserPermissionLevels() = %q for a trashed page, want none", level)
+	}
+
+	livePageID := insertTestPage(t, dbm, workspaceID, ownerID, "Live", nil)
+	if ok, err := repo.HasTrashPermission(ctx, livePageID, ownerID, repository.PermissionView); err != nil || ok {
+		t.Errorf("HasTrashPermission() on a live page = %v, %v; want false", ok, err)
+	}
+}
+
+func TestListTrashShowsOnlyPagesTheUserCouldDelete(t *testing.T) {
+	dbm := openTestDB(t)
+	repo := NewPageRepository(dbm, testLogger())
+
+	ownerID := insertTestUser(t, dbm)
+	memberID := insertTestUser(t, dbm)
+	workspaceID := insertTestWorkspace(t, dbm, ownerID, "edit")
+	addTestMember(t, dbm, workspaceID, memberID, "member", ownerID)
+
+	own := insertTestPage(t, dbm, workspaceID, memberID, "Own", nil)
+	granted := insertTestPage(t, dbm, workspaceID, ownerID, "Granted", nil)
+	grantTestPermission(t, dbm, granted, memberID, "admin", ownerID)
+	editable := insertTestPage(t, dbm, workspaceID, ownerID, "Editable", nil)
+	grantTestPermission(t, dbm, editable, memberID, "edit", ownerID)
+	others := insertTestPage(t, dbm, workspaceID, ownerID, "Others", nil)
+	mustExec(t, dbm, `UPDATE pages SET deleted_at = NOW() WHERE id = ANY(ARRAY[$1, $2, $3, $4]::uuid[])`, own, granted, editable, others)
+
+	trash, err := repo.ListTrash(context.Background(), workspaceID, memberID)
+	if err != nil {
+		t.Fatalf("ListTrash() error = %v", err)
+	}
+
+	got := make(map[string]bool, len(trash))
+	for _, page := range trash {
+		got[page.ID] = true
+	}
+	if len(got) != 2 || !got[own] || !got[granted] {
+		t.Errorf("ListTrash() = %v, want only the owned and admin-granted pages", got)
+	}
+
+	trash, err = repo.ListTrash(context.Background(), workspaceID, ownerID)
+	if err != nil {
+		t.Fatalf("ListTrash() error = %v", err)
+	}
+	if len(trash) != 3 {
+		t.Errorf("ListTrash() for the owner returned %d pages, want 3", len(trash))
+	}
+}
@@ -0,0 +1,247 @@
+package postgres
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/database"
+	"github.com/Srivathsav-max/lumen/backend/internal/errors"
+)
+
+func TestGetDescendantIDs(t *testing.T) {
+	dbm := openTestDB(t)
+	repo := NewPageRepository(dbm, testLogger())
+
+	ownerID := insertTestUser(t, dbm)
+	workspaceID := insertTestWorkspace(t, dbm, ownerID, "edit")
+	root := insertTestPage(t, dbm, workspaceID, ownerID, "Root", nil)
+	child := insertTestPage(t, dbm, workspaceID, ownerID, "Child", &root)
+	grandchild := insertTestPage(t, dbm, workspaceID, ownerID, "Grandchild", &child)
+	trashed := insertTestPage(t, dbm, workspaceID, ownerID, "Trashed", &child)
+	mustExec(t, dbm, `UPDATE pages SET deleted_at = NOW() WHERE id = $1`, trashed)
+
+	descendants, err := repo.GetDescendantIDs(context.Background(), []string{root, child})
+	if err != nil {
+		t.Fatalf("GetDescendantIDs() error = %v", err)
+	}
+
+	got := descendants[root]
+	slices.Sort(got)
+	want := []string{child, grandchild}
+	slices.Sort(want)
+	if !slices.Equal(got, want) {
+		t.Errorf("descendants of root = %v, want %v", got, want)
+	}
+	if got := descendants[child]; !slices.Equal(got, []string{grandchild}) {
+		t.Errorf("descendants of child = %v, want [%s]", got, grandchild)
+	}
+}
+
+func TestIsDescendant(t *testing.T) {
+	dbm := openTestDB(t)
+	repo := NewPageRepository(dbm, testLogger())
+
+	ownerID := insertTestUser(t, dbm)
+	workspaceID := insertTestWorkspace(t, dbm, ownerID, "edit")
+	root := insertTestPage(t, dbm, workspaceID, ownerID, "Root", nil)
+	child := insertTestPage(t, dbm, workspaceID, ownerID, "Child", &root)
+	grandchild := insertTestPage(t, dbm, workspaceID, ownerID, "Grandchild", &child)
+	sibling := insertTestPage(t, dbm, workspaceID, ownerID, "Sibling", nil)
+
+	tests := []struct {
+		ancestor, candidate string
+		want                bool
+	}{
+		{root, root, true},
+		{root, child, true},
+		{root, grandchild, true},
+		{child, grandchild, true},
+		{grandchild, root, false},
+		{child, root, false},
+		{root, sibling, false},
+	}
+
+	for _, tt := range tests {
+		got, err := repo.IsDescendant(context.Background(), tt.ancestor, tt.candidate)
+		if err != nil {
+			t.Fatalf("IsDescendant() error = %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("IsDescendant(%s, %s) = %v, want %v", tt.ancestor, tt.candidate, got, tt.want)
+		}
+	}
+}
+
+func TestMoveRejectsCycles(t *testing.T) {
+	dbm := openTestDB(t)
+	repo := NewPageRepository(dbm, testLogger())
+	ctx := context.Background()
+
+	ownerID := insertTestUser(t, dbm)
+	workspaceID := insertTestWorkspace(t, dbm, ownerID, "edit")
+	root := insertTestPage(t, dbm, workspaceID, ownerID, "Root", nil)
+	child := insertTestPage(t, dbm, workspaceID, ownerID, "Child", &root)
+
+	for _, parentID := range []string{root, child} {
+		err := repo.Move(ctx, root, &parentID, workspaceID, ownerID)
+		if appErr, ok := errors.AsAppError(err); !ok || appErr.Code != errors.ValidationError {
+			t.Errorf("Move(root under %s) error = %v, want a validation error", parentID, err)
+		}
+	}
+}
+
+func TestConcurrentMovesCannotFormACycle(t *testing.T) {
+	dbm := openTestDB(t)
+	repo := NewPageRepository(dbm, testLogger())
+	ctx := context.Background()
+
+	ownerID := insertTestUser(t, dbm)
+	workspaceID := insertTestWorkspace(t, dbm, ownerID, "edit")
+	a := insertTestPage(t, dbm, workspaceID, ownerID, "A", nil)
+	b := insertTestPage(t, dbm, workspaceID, ownerID, "B", nil)
+
+	var wg sync.WaitGroup
+	for _, move := range [][2]string{{a, b}, {b, a}} {
+		wg.Add(1)
+		go func(id, parentID string) {
+			defer wg.Done()
+			// One of the moves fails with a cycle or a deadlock error
+			_ = repo.Move(ctx, id, &parentID, workspaceID, ownerID)
+		}(move[0], move[1])
+	}
+	wg.Wait()
+
+	var cycle bool
+	err := dbm.GetDB().QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM pages x JOIN pages y ON x.parent_id = y.id AND y.parent_id = x.id
+			WHERE x.id = $1
+		)`, a).Scan(&cycle)
+	if err != nil {
+		t.Fatalf("check cycle: %v", err)
+	}
+	if cycle {
+		t.Error("concurrent moves left A and B as each other's parent")
+	}
+}
+
+func isArchived(t *testing.T, dbm database.Manager, id string) bool {
+	t.Helper()
+	var archived bool
+	if err := dbm.GetDB().QueryRow(`SELECT is_archived FROM pages WHERE id = $1`, id).Scan(&archived); err != nil {
+		t.Fatalf("read is_archived: %v", err)
+	}
+	return archived
+}
+
+func TestArchiveAndRestoreCascadeThroughThreeLevels(t *testing.T) {
+	dbm := openTestDB(t)
+	repo := NewPageRepository(dbm, testLogger())
+	ctx := context.Background()
+
+	ownerID := insertTestUser(t, dbm)
+	workspaceID := insertTestWorkspace(t, dbm, ownerID, "edit")
+	root := insertTestPage(t, dbm, workspaceID, ownerID, "Root", nil)
+	child := insertTestPage(t, dbm, workspaceID, ownerID, "Child", &root)
+	grandchild := insertTestPage(t, dbm, workspaceID, ownerID, "Grandchild", &child)
+	archivedBefore := insertTestPage(t, dbm, workspaceID, ownerID, "Archived before", &child)
+
+	if err := repo.Archive(ctx, archivedBefore, false, ownerID); err != nil {
+		t.Fatalf("Archive(archivedBefore) error = %v", err)
+	}
+
+	if err := repo.Archive(ctx, root, true, ownerID); err != nil {
+		t.Fatalf("Archive(root) error = %v", err)
+	}
+	for _, id := range []string{root, child, grandchild, archivedBefore} {
+		if !isArchived(t, dbm, id) {
+			t.Errorf("page %s is live after a cascading archive of root", id)
+		}
+	}
+
+	if err := repo.Restore(ctx, root, true, ownerID); err != nil {
+		t.Fatalf("Restore(root) error = %v", err)
+	}
+	for _, id := range []string{root, child, grandchild} {
+		if isArchived(t, dbm, id) {
+			t.Errorf("page %s is still archived after a cascading restore of root", id)
+		}
+	}
+	if !isArchived(t, dbm, archivedBefore) {
+		t.Error("a page archived before its ancestor was restored with it")
+	}
+}
+
+func TestArchiveWithoutCascadeTouchesOnlyThePage(t *testing.T) {
+	dbm := openTestDB(t)
+	repo := NewPageRepository(dbm, testLogger())
+	ctx := context.Background()
+
+	ownerID := insertTestUser(t, dbm)
+	workspaceID := insertTestWorkspace(t, dbm, ownerID, "edit")
+	root := insertTestPage(t, dbm, workspaceID, ownerID, "Root", nil)
+	child := insertTestPage(t, dbm, workspaceID, ownerID, "Child", &root)
+	grandchild := insertTestPage(t, dbm, workspaceID, ownerID, "Grandchild", &child)
+
+	if err := repo.Archive(ctx, root, false, ownerID); err != nil {
+		t.Fatalf("Archive() error = %v", err)
+	}
+	if !isArchived(t, dbm, root) || isArchived(t, dbm, child) || isArchived(t, dbm, grandchild) {
+		t.Error("a non-cascading archive changed descendants")
+	}
+
+	// A cascading archive of the middle page is undone only by restoring that page
+	if err := repo.Archive(ctx, child, true, ownerID); err != nil {
+		t.Fatalf("Archive(child) error = %v", err)
+	}
+	if err := repo.Restore(ctx, root, true, ownerID); err != nil {
+		t.Fatalf("Restore(root) error = %v", err)
+	}
+	if !isArchived(t, dbm, child) || !isArchived(t, dbm, grandchild) {
+		t.Error("restoring root brought back pages archived with its child")
+	}
+
+	if err := repo.Restore(ctx, child, false, ownerID); err != nil {
+		t.Fatalf("Restore(child) error = %v", err)
+	}
+	if isArchived(t, dbm, child) || !isArchived(t, dbm, grandchild) {
+		t.Error("a non-cascading restore changed descendants")
+	}
+}
+
+func TestBulkArchiveMarksDescendantsWithTheNearestListedPage(t *testing.T) {
+	dbm := openTestDB(t)
+	repo := NewPageRepository(dbm, testLogger())
+	ctx := context.Background()
+
+	ownerID := insertTestUser(t, dbm)
+	workspaceID := insertTestWorkspace(t, dbm, ownerID, "edit")
+	root := insertTestPage(t, dbm, workspaceID, ownerID, "Root", nil)
+	child := insertTestPage(t, dbm, workspaceID, ownerID, "Child", &root)
+	grandchild := insertTestPage(t, dbm, workspaceID, ownerID, "Grandchild", &child)
+
+	archived, err := repo.BulkArchive(ctx, []string{root, child}, true, ownerID)
+	if err != nil {
+		t.Fatalf("BulkArchive() error = %v", err)
+	}
+	if archived != 3 {
+		t.Errorf("BulkArchive() = %d, want 3", archived)
+	}
+
+	// grandchild belongs to child, so restoring root alone leaves it archived
+	if err := repo.Restore(ctx, root, true, ownerID); err != nil {
+		t.Fatalf("Restore(root) error = %v", err)
+	}
+	if !isArchived(t, dbm, child) || !isArchived(t, dbm, grandchild) {
+		t.Error("restoring root brought back pages listed separately in the bulk archive")
+	}
+
+	if err := repo.Restore(ctx, child, true, ownerID); err != nil {
+		t.Fatalf("Restore(child) error = %v", err)
+	}
+	if isArchived(t, dbm, grandchild) {
+		t.Error("restoring child left its grandchild archived")
+	}
+}
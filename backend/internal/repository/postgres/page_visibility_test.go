@@ -0,0 +1,249 @@
+package postgres
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+// visibilityFixture is a workspace with one page owned by someone else, one
+// page the member owns and one page shared with the member explicitly
+type visibilityFixture struct {
+	workspaceID int64
+	memberID    int64
+	othersPage  string
+	ownPage     string
+	sharedPage  string
+}
+
+func setupVisibility(t *testing.T, policy string) (repository.PageRepository, visibilityFixture) {
+	t.Helper()
+	dbm := openTestDB(t)
+
+	ownerID := insertTestUser(t, dbm)
+	memberID := insertTestUser(t, dbm)
+	workspaceID := insertTestWorkspace(t, dbm, ownerID, policy)
+	addTestMember(t, dbm, workspaceID, memberID, "member", ownerID)
+
+	f := visibilityFixture{
+		workspaceID: workspaceID,
+		memberID:    memberID,
+		othersPage:  insertTestPage(t, dbm, workspaceID, ownerID, "Roadmap others", nil),
+		ownPage:     insertTestPage(t, dbm, workspaceID, memberID, "Roadmap own", nil),
+		sharedPage:  insertTestPage(t, dbm, workspaceID, ownerID, "Roadmap shared", nil),
+	}
+	grantTestPermission(t, dbm, f.sharedPage, memberID, "view", ownerID)
+
+	return NewPageRepository(dbm, testLogger()), f
+}
+
+func pageIDs(pages []*repository.Page) map[string]bool {
+	ids := make(map[string]bool, len(pages))
+	for _, page := range pages {
+		ids[page.ID] = true
+	}
+	return ids
+}
+
+func TestPageVisibilityFollowsWorkspacePolicy(t *testing.T) {
+	tests := []struct {
+		policy           string
+		othersVisible    bool
+		othersCanComment bool
+		othersCanEdit    bool
+	}{
+		{policy: "none"},
+		{policy: "view", othersVisible: true},
+		{policy: "comment", othersVisible: true, othersCanComment: true},
+		{policy: "edit", othersVisible: true, othersCanComment: true, othersCanEdit: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			repo, f := setupVisibility(t, tt.policy)
+			ctx := context.Background()
+
+			wantTotal := int64(2)
+			if tt.othersVisible {
+				wantTotal = 3
+			}
+
+			checks := []struct {
+				level repository.PermissionLevel
+				want  bool
+			}{
+				{repository.PermissionView, tt.othersVisible},
+				{repository.PermissionComment, tt.othersCanComment},
+				{repository.PermissionEdit, tt.othersCanEdit},
+			}
+			for _, check := range checks {
+				got, err := repo.HasPermission(ctx, f.othersPage, f.memberID, check.level)
+				if err != nil {
+					t.Fatalf("HasPermission(%s) error = %v", check.level, err)
+				}
+				if got != check.want {
+					t.Errorf("HasPermission(%s) on another member's page = %v, want %v", check.level, got, check.want)
+				}
+			}
+
+			for _, pageID := range []string{f.ownPage, f.sharedPage} {
+				if ok, err := repo.HasPermission(ctx, pageID, f.memberID, repository.PermissionView); err != nil || !ok {
+					t.Errorf("HasPermission(view) on own or shared page = %v, %v; want true", ok, err)
+				}
+			}
+
+			levels, err := repo.GetUserPermissionLevels(ctx, f.memberID, []string{f.othersPage, f.ownPage, f.sharedPage})
+			if err != nil {
+				t.Fatalf("GetUserPermissionLevels() error = %v", err)
+			}
+			if _, ok := levels[f.othersPage]; ok != tt.othersVisible {
+				t.Errorf("GetUserPermissionLevels() includes another member's page = %v, want %v", ok, tt.othersVisible)
+			}
+
+			found, err := repo.Search(ctx, f.workspaceID, f.memberID, "roadmap", repository.SearchFilters{}, 10, 0)
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			ids := pageIDs(found)
+			if ids[f.othersPage] != tt.othersVisible || !ids[f.ownPage] || !ids[f.sharedPage] {
+				t.Errorf("Search() returned %v", ids)
+			}
+
+			total, err := repo.SearchCount(ctx, f.workspaceID, f.memberID, "roadmap", repository.SearchFilters{})
+			if err != nil {
+				t.Fatalf("SearchCount() error = %v", err)
+			}
+			if total != wantTotal {
+				t.Errorf("SearchCount() = %d, want %d", total, wantTotal)
+			}
+
+			total, err = repo.CountByProperties(ctx, f.workspaceID, f.memberID, nil)
+			if err != nil {
+				t.Fatalf("CountByProperties() error = %v", err)
+			}
+			if total != wantTotal {
+				t.Errorf("CountByProperties() = %d, want %d", total, wantTotal)
+			}
+
+			listed, err := repo.GetByWorkspaceIDAfter(ctx, f.workspaceID, f.memberID, false, false, nil, 10)
+			if err != nil {
+				t.Fatalf("GetByWorkspaceIDAfter() error = %v", err)
+			}
+			if int64(len(listed)) != wantTotal {
+				t.Errorf("GetByWorkspaceIDAfter() returned %d pages, want %d", len(listed), wantTotal)
+			}
+
+			recent, err := repo.GetRecentPages(ctx, f.memberID, 10)
+			if err != nil {
+				t.Fatalf("GetRecentPages() error = %v", err)
+			}
+			if ids := pageIDs(recent); ids[f.othersPage] != tt.othersVisible || len(ids) != int(wantTotal) {
+				t.Errorf("GetRecentPages() returned %v", ids)
+			}
+
+			tree, err := repo.GetTree(ctx, f.workspaceID, f.memberID, 3, false, false)
+			if err != nil {
+				t.Fatalf("GetTree() error = %v", err)
+			}
+			treeIDs := make(map[string]bool, len(tree))
+			for _, entry := range tree {
+				treeIDs[entry.ID] = true
+			}
+			if treeIDs[f.othersPage] != tt.othersVisible || !treeIDs[f.ownPage] || !treeIDs[f.sharedPage] || int64(len(treeIDs)) != wantTotal {
+				t.Errorf("GetTree() returned %v", treeIDs)
+			}
+
+			recent, err = repo.GetRecentPagesAfter(ctx, f.memberID, nil, 10)
+			if err != nil {
+				t.Fatalf("GetRecentPagesAfter() error = %v", err)
+			}
+			if int64(len(recent)) != wantTotal {
+				t.Errorf("GetRecentPagesAfter() returned %d pages, want %d", len(recent), wantTotal)
+			}
+		})
+	}
+}
+
+func TestGetTreeHidesPagesInANoneWorkspace(t *testing.T) {
+	dbm := openTestDB(t)
+	repo := NewPageRepository(dbm, testLogger())
+
+	ownerID := insertTestUser(t, dbm)
+	memberID := insertTestUser(t, dbm)
+	workspaceID := insertTestWorkspace(t, dbm, ownerID, "none")
+	addTestMember(t, dbm, workspaceID, memberID, "member", ownerID)
+
+	hiddenRoot := insertTestPage(t, dbm, workspaceID, ownerID, "Hidden root", nil)
+	hiddenChild := insertTestPage(t, dbm, workspaceID, ownerID, "Hidden child", &hiddenRoot)
+	sharedChild := insertTestPage(t, dbm, workspaceID, ownerID, "Shared child", &hiddenRoot)
+	grantTestPermission(t, dbm, sharedChild, memberID, "view", ownerID)
+	ownRoot := insertTestPage(t, dbm, workspaceID, memberID, "Own root", nil)
+	insertTestPage(t, dbm, workspaceID, ownerID, "Hidden under own", &ownRoot)
+
+	tree, err := repo.GetTree(context.Background(), workspaceID, memberID, 3, false, false)
+	if err != nil {
+		t.Fatalf("GetTree() error = %v", err)
+	}
+
+	got := make(map[string]bool, len(tree))
+	for _, entry := range tree {
+		got[entry.ID] = entry.HasChildren
+	}
+	// has_children must not reveal the hidden page under the member's own page
+	want := map[string]bool{ownRoot: false, sharedChild: false}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetTree() = %v, want %v; hidden pages are %s and %s", got, want, hiddenRoot, hiddenChild)
+	}
+}
+
+// BenchmarkGetUserPermissionLevels compares the batched lookup used by page
+// lists with resolving each page through HasPermission
+func BenchmarkGetUserPermissionLevels(b *testing.B) {
+	dbm := openTestDB(b)
+	repo := NewPageRepository(dbm, testLogger())
+	ctx := context.Background()
+
+	ownerID := insertTestUser(b, dbm)
+	memberID := insertTestUser(b, dbm)
+	workspaceID := insertTestWorkspace(b, dbm, ownerID, "view")
+	addTestMember(b, dbm, workspaceID, memberID, "member", ownerID)
+
+	// A page list's worth of pages, mixing owned, granted and default access
+	pageIDs := make([]string, 0, 50)
+	for i := 0; i < cap(pageIDs); i++ {
+		switch i % 3 {
+		case 0:
+			pageIDs = append(pageIDs, insertTestPage(b, dbm, workspaceID, memberID, "Own", nil))
+		case 1:
+			page := insertTestPage(b, dbm, workspaceID, ownerID, "Granted", nil)
+			grantTestPermission(b, dbm, page, memberID, "edit", ownerID)
+			pageIDs = append(pageIDs, page)
+		default:
+			pageIDs = append(pageIDs, insertTestPage(b, dbm, workspaceID, ownerID, "Default", nil))
+		}
+	}
+
+	b.Run("batched", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			levels, err := repo.GetUserPermissionLevels(ctx, memberID, pageIDs)
+			if err != nil {
+				b.Fatalf("GetUserPermissionLevels() error = %v", err)
+			}
+			if len(levels) != len(pageIDs) {
+				b.Fatalf("GetUserPermissionLevels() resolved %d pages, want %d", len(levels), len(pageIDs))
+			}
+		}
+	})
+
+	b.Run("per_page", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, pageID := range pageIDs {
+				if _, err := repo.HasPermission(ctx, pageID, memberID, repository.PermissionView); err != nil {
+					b.Fatalf("HasPermission() error = %v", err)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,148 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Srivathsav-max/lumen/backend/db"
+	"github.com/Srivathsav-max/lumen/backend/internal/database"
+	"github.com/golang-migrate/migrate/v4"
+)
+
+// Repository tests run against a real PostgreSQL database named by
+// LUMEN_TEST_DATABASE_URL and are skipped without one. The database is
+// migrated to the latest version on first use and must be disposable: tests
+// add rows with unique names but never clean them up.
+const testDatabaseURLEnv = "LUMEN_TEST_DATABASE_URL"
+
+var (
+	testDBOnce sync.Once
+	testDB     *sql.DB
+	testDBErr  error
+	testSeq    atomic.Int64
+)
+
+func openTestDB(t testing.TB) database.Manager {
+	t.Helper()
+
+	url := os.Getenv(testDatabaseURLEnv)
+	if url == "" {
+		t.Skipf("%s is not set", testDatabaseURLEnv)
+	}
+
+	testDBOnce.Do(func() {
+		conn, err := sql.Open("postgres", url)
+		if err != nil {
+			testDBErr = err
+			return
+		}
+		m, err := db.NewMigrator(&db.DB{DB: conn}, "postgres")
+		if err != nil {
+			testDBErr = err
+			return
+		}
+		if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+			testDBErr = err
+			return
+		}
+		testDB = conn
+	})
+	if testDBErr != nil {
+		t.Fatalf("failed to prepare test database: %v", testDBErr)
+	}
+
+	return database.NewPostgresManager(testDB, testLogger())
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// uniqueName returns a name no other test run has used
+func uniqueName(prefix string) string {
+	return fmt.Sprintf("%s%d%d", prefix, time.Now().UnixNano()%1e9, testSeq.Add(1))
+}
+
+func mustExec(t testing.TB, dbm database.Manager, query string, args ...interface{}) {
+	t.Helper()
+	if _, err := dbm.GetDB().Exec(query, args...); err != nil {
+		t.Fatalf("exec %q: %v", query, err)
+	}
+}
+
+func insertTestUser(t testing.TB, dbm database.Manager) int64 {
+	t.Helper()
+	name := uniqueName("u")
+	var id int64
+	err := dbm.GetDB().QueryRow(`
+		INSERT INTO users (username, email, password_hash, email_verified, created_at, updated_at)
+		VALUES ($1, $2, 'x', TRUE, NOW(), NOW())
+		RETURNING id`, name, name+"@example.test").Scan(&id)
+	if err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	return id
+}
+
+// insertTestWorkspace creates a workspace owned by ownerID with the owner as
+// its first member
+func insertTestWorkspace(t testing.TB, dbm database.Manager, ownerID int64, defaultPermission string) int64 {
+	t.Helper()
+	var id int64
+	err := dbm.GetDB().QueryRow(`
+		INSERT INTO workspaces (name, owner_id, default_page_permission)
+		VALUES ($1, $2, $3)
+		RETURNING id`, uniqueName("ws"), ownerID, defaultPermission).Scan(&id)
+	if err != nil {
+		t.Fatalf("insert workspace: %v", err)
+	}
+	addTestMember(t, dbm, id, ownerID, "owner", ownerID)
+	return id
+}
+
+func addTestMember(t testing.TB, dbm database.Manager, workspaceID, userID int64, role string, addedBy int64) {
+	t.Helper()
+	mustExec(t, dbm, `
+		INSERT INTO workspace_members (workspace_id, user_id, role, added_by)
+		VALUES ($1, $2, $3, $4)`, workspaceID, userID, role, addedBy)
+}
+
+func insertTestPage(t testing.TB, dbm database.Manager, workspaceID, ownerID int64, title string, parentID *string) string {
+	t.Helper()
+	var id string
+	err := dbm.GetDB().QueryRow(`
+		INSERT INTO pages (title, workspace_id, owner_id, parent_id)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id`, title, workspaceID, ownerID, parentID).Scan(&id)
+	if err != nil {
+		t.Fatalf("insert page: %v", err)
+	}
+	return id
+}
+
+func grantTestPermission(t testing.TB, dbm database.Manager, pageID string, userID int64, level string, grantedBy int64) {
+	t.Helper()
+	mustExec(t, dbm, `
+		INSERT INTO page_permissions (page_id, user_id, permission, granted_by)
+		VALUES ($1, $2, $3, $4)`, pageID, userID, level, grantedBy)
+}
+
+func insertTestBlock(t *testing.T, dbm database.Manager, pageID string, blockType, data string, position int, parentBlockID *string, createdBy int64) string {
+	t.Helper()
+	var id string
+	err := dbm.GetDB().QueryRow(`
+		INSERT INTO blocks (page_id, block_type, block_data, position, parent_block_id, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`, pageID, blockType, data, position, parentBlockID, createdBy).Scan(&id)
+	if err != nil {
+		t.Fatalf("insert block: %v", err)
+	}
+	return id
+}
@@ -6,6 +6,8 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/google/uuid"
+
 	"github.com/Srivathsav-max/lumen/backend/internal/database"
 	"github.com/Srivathsav-max/lumen/backend/internal/repository"
 )
@@ -22,10 +24,14 @@ func NewTokenRepository(db database.Manager, logger *slog.Logger) repository.Tok
 
 func (r *TokenRepository) Create(ctx context.Context, token *repository.Token) error {
 	query := `
-		INSERT INTO tokens (user_id, refresh_token, device_info, expires_at, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO tokens (user_id, refresh_token, device_info, ip_address, family_id, expires_at, created_at, updated_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8)
 		RETURNING id`
 
+	if token.FamilyID == "" {
+		token.FamilyID = uuid.New().String()
+	}
+
 	token.CreatedAt = time.Now().UTC()
 
 	token.UpdatedAt = token.CreatedAt
@@ -34,6 +40,8 @@ func (r *TokenRepository) Create(ctx context.Context, token *repository.Token) e
 		token.UserID,
 		token.Token,
 		token.DeviceInfo,
+		token.IPAddress,
+		token.FamilyID,
 		token.ExpiresAt,
 		token.CreatedAt,
 		token.UpdatedAt,
@@ -53,7 +61,7 @@ func (r *TokenRepository) Create(ctx context.Context, token *repository.Token) e
 
 func (r *TokenRepository) GetByToken(ctx context.Context, tokenString string) (*repository.Token, error) {
 	query := `
-		SELECT id, user_id, refresh_token, device_info, expires_at, created_at, updated_at
+		SELECT id, user_id, refresh_token, device_info, COALESCE(ip_address, ''), family_id, rotated_at, expires_at, created_at, updated_at
 		FROM tokens
 		WHERE refresh_token = $1`
 
@@ -65,6 +73,9 @@ func (r *TokenRepository) GetByToken(ctx context.Context, tokenString string) (*
 		&token.UserID,
 		&token.Token,
 		&token.DeviceInfo,
+		&token.IPAddress,
+		&token.FamilyID,
+		&token.RotatedAt,
 		&token.ExpiresAt,
 		&token.CreatedAt,
 		&token.UpdatedAt,
@@ -79,9 +90,9 @@ func (r *TokenRepository) GetByToken(ctx context.Context, tokenString string) (*
 
 func (r *TokenRepository) GetByUserID(ctx context.Context, userID int64, tokenType string) ([]*repository.Token, error) {
 	query := `
-		SELECT id, user_id, refresh_token, device_info, expires_at, created_at, updated_at
+		SELECT id, user_id, refresh_token, device_info, COALESCE(ip_address, ''), family_id, rotated_at, expires_at, created_at, updated_at
 		FROM tokens
-		WHERE user_id = $1
+		WHERE user_id = $1 AND rotated_at IS NULL
 		ORDER BY created_at DESC`
 
 	rows, err := r.ExecuteQuery(ctx, query, userID)
@@ -98,6 +109,9 @@ func (r *TokenRepository) GetByUserID(ctx context.Context, userID int64, tokenTy
 			&token.UserID,
 			&token.Token,
 			&token.DeviceInfo,
+			&token.IPAddress,
+			&token.FamilyID,
+			&token.RotatedAt,
 			&token.ExpiresAt,
 			&token.CreatedAt,
 			&token.UpdatedAt,
@@ -252,7 +266,7 @@ func (r *TokenRepository) ValidateRefreshToken(ctx context.Context, token string
 	query := `
 		SELECT user_id
 		FROM tokens
-		WHERE refresh_token = $1 AND expires_at > $2`
+		WHERE refresh_token = $1 AND expires_at > $2 AND rotated_at IS NULL`
 
 	now := time.Now().UTC()
 	row := r.ExecuteQueryRow(ctx, query, token, now)
@@ -286,3 +300,63 @@ func (r *TokenRepository) RevokeRefreshToken(ctx context.Context, token string)
 	r.GetLogger().Info("Refresh token revoked successfully", "token", token)
 	return nil
 }
+
+// MarkRotated flags a refresh token as exchanged for a new one. The row is
+// kept until it expires so a later replay can be recognised. It reports false
+// when the token was already rotated, which makes concurrent refreshes with
+// the same token detectable as reuse.
+func (r *TokenRepository) MarkRotated(ctx context.Context, tokenString string) (bool, error) {
+	query := `
+		UPDATE tokens
+		SET rotated_at = $1, updated_at = $1
+		WHERE refresh_token = $2 AND rotated_at IS NULL`
+
+	result, err := r.ExecuteExec(ctx, query, time.Now().UTC(), tokenString)
+	if err != nil {
+		return false, r.HandleSQLError(err, "mark token rotated")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, r.HandleSQLError(err, "get rows affected")
+	}
+
+	return rowsAffected > 0, nil
+}
+
+func (r *TokenRepository) HasRotatedSuccessor(ctx context.Context, familyID string, tokenID int64) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM tokens
+			WHERE family_id = $1 AND id > $2 AND rotated_at IS NOT NULL
+		)`
+
+	var exists bool
+	if err := r.ExecuteQueryRow(ctx, query, familyID, tokenID).Scan(&exists); err != nil {
+		return false, r.HandleSQLError(err, "check rotated successor")
+	}
+
+	return exists, nil
+}
+
+// RevokeTokenFamily deletes every refresh token descended from the same login
+func (r *TokenRepository) RevokeTokenFamily(ctx context.Context, familyID string) error {
+	query := `DELETE FROM tokens WHERE family_id = $1`
+
+	result, err := r.ExecuteExec(ctx, query, familyID)
+	if err != nil {
+		return r.HandleSQLError(err, "revoke token family")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return r.HandleSQLError(err, "get rows affected")
+	}
+
+	r.GetLogger().Info("Token family revoked successfully",
+		"family_id", familyID,
+		"tokens_revoked", rowsAffected,
+	)
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+func TestHasRotatedSuccessor(t *testing.T) {
+	dbm := openTestDB(t)
+	repo := NewTokenRepository(dbm, testLogger())
+	ctx := context.Background()
+	userID := insertTestUser(t, dbm)
+
+	issue := func(familyID string) *repository.Token {
+		t.Helper()
+		token := &repository.Token{UserID: userID, Token: uniqueName("rt"), FamilyID: familyID, ExpiresAt: time.Now().Add(time.Hour)}
+		if err := repo.Create(ctx, token); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		return token
+	}
+	rotate := func(token *repository.Token) {
+		t.Helper()
+		if ok, err := repo.MarkRotated(ctx, token.Token); err != nil || !ok {
+			t.Fatalf("MarkRotated() = %v, %v", ok, err)
+		}
+	}
+	check := func(token *repository.Token, want bool) {
+		t.Helper()
+		got, err := repo.HasRotatedSuccessor(ctx, token.FamilyID, token.ID)
+		if err != nil {
+			t.Fatalf("HasRotatedSuccessor() error = %v", err)
+		}
+		if got != want {
+			t.Errorf("HasRotatedSuccessor(token %d) = %v, want %v", token.ID, got, want)
+		}
+	}
+
+	first := issue("")
+	rotate(first)
+	second := issue(first.FamilyID)
+	other := issue("")
+	rotate(other)
+
+	check(first, false)
+
+	rotate(second)
+	issue(first.FamilyID)
+	check(first, true)
+	check(second, false)
+}
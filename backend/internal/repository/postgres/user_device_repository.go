@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/database"
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+type UserDeviceRepository struct {
+	*repository.BaseRepository
+}
+
+func NewUserDeviceRepository(db database.Manager, logger *slog.Logger) repository.UserDeviceRepository {
+	return &UserDeviceRepository{
+		BaseRepository: repository.NewBaseRepository(db, logger, "user_devices"),
+	}
+}
+
+func (r *UserDeviceRepository) HasAny(ctx context.Context, userID int64) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM user_devices WHERE user_id = $1)`
+
+	var exists bool
+	if err := r.ExecuteQueryRow(ctx, query, userID).Scan(&exists); err != nil {
+		return false, r.HandleSQLError(err, "check user devices")
+	}
+
+	return exists, nil
+}
+
+func (r *UserDeviceRepository) Touch(ctx context.Context, userID int64, deviceLabel, ipAddress string) (bool, error) {
+	// xmax is zero only for rows this statement inserted
+	query := `
+		INSERT INTO user_devices (user_id, device_label, last_ip_address, first_seen_at, last_seen_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $4)
+		ON CONFLICT (user_id, device_label) DO UPDATE
+		SET last_ip_address = COALESCE(EXCLUDED.last_ip_address, user_devices.last_ip_address),
+			last_seen_at = EXCLUDED.last_seen_at
+		RETURNING (xmax = 0)`
+
+	var inserted bool
+	err := r.ExecuteQueryRow(ctx, query, userID, deviceLabel, ipAddress, time.Now().UTC()).Scan(&inserted)
+	if err != nil {
+		return false, r.HandleSQLError(err, "record user device")
+	}
+
+	return inserted, nil
+}
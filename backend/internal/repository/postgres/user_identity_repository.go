@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/database"
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+type UserIdentityRepository struct {
+	*repository.BaseRepository
+}
+
+func NewUserIdentityRepository(db database.Manager, logger *slog.Logger) repository.UserIdentityRepository {
+	return &UserIdentityRepository{
+		BaseRepository: repository.NewBaseRepository(db, logger, "user_identities"),
+	}
+}
+
+func (r *UserIdentityRepository) Create(ctx context.Context, identity *repository.UserIdentity) error {
+	query := `
+		INSERT INTO user_identities (user_id, provider, subject, email, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`
+
+	identity.CreatedAt = time.Now().UTC()
+
+	row := r.ExecuteQueryRow(ctx, query,
+		identity.UserID,
+		identity.Provider,
+		identity.Subject,
+		identity.Email,
+		identity.CreatedAt,
+	)
+
+	if err := row.Scan(&identity.ID); err != nil {
+		return r.HandleSQLError(err, "create user identity")
+	}
+
+	r.GetLogger().Info("User identity created successfully",
+		"identity_id", identity.ID,
+		"user_id", identity.UserID,
+		"provider", identity.Provider,
+	)
+
+	return nil
+}
+
+// GetByProviderSubject returns nil, nil when no user is linked to the account
+func (r *UserIdentityRepository) GetByProviderSubject(ctx context.Context, provider, subject string) (*repository.UserIdentity, error) {
+	query := `
+		SELECT id, user_id, provider, subject, COALESCE(email, ''), created_at
+		FROM user_identities
+		WHERE provider = $1 AND subject = $2`
+
+	identity := &repository.UserIdentity{}
+	row := r.ExecuteQueryRow(ctx, query, provider, subject)
+
+	err := row.Scan(
+		&identity.ID,
+		&identity.UserID,
+		&identity.Provider,
+		&identity.Subject,
+		&identity.Email,
+		&identity.CreatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, r.HandleSQLError(err, "get user identity")
+	}
+
+	return identity, nil
+}
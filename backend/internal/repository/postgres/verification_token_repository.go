@@ -173,7 +173,8 @@ func (r *VerificationTokenRepository) Delete(ctx context.Context, id int64) erro
 }
 
 func (r *VerificationTokenRepository) MarkAsUsed(ctx context.Context, tokenID int64) error {
-	query := `UPDATE verification_tokens SET is_used = true WHERE id = $1`
+	// The is_used guard makes concurrent redemptions of one token fail
+	query := `UPDATE verification_tokens SET is_used = true WHERE id = $1 AND is_used = false`
 
 	result, err := r.ExecuteExec(ctx, query, tokenID)
 	if err != nil {
@@ -235,3 +236,44 @@ func (r *VerificationTokenRepository) DeleteUserTokensByType(ctx context.Context
 
 	return nil
 }
+
+func (r *VerificationTokenRepository) InvalidateUserTokensByType(ctx context.Context, userID int64, tokenType string) error {
+	query := `UPDATE verification_tokens SET is_used = true WHERE user_id = $1 AND token_type = $2 AND is_used = false`
+
+	if _, err := r.ExecuteExec(ctx, query, userID, tokenType); err != nil {
+		return r.HandleSQLError(err, "invalidate user tokens by type")
+	}
+
+	return nil
+}
+
+func (r *VerificationTokenRepository) RecordFailedAttempt(ctx context.Context, tokenID int64, maxAttempts int) (int, error) {
+	// One statement, so concurrent guesses cannot both see the old count
+	query := `
+		UPDATE verification_tokens
+		SET failed_attempts = failed_attempts + 1,
+			is_used = failed_attempts + 1 >= $2
+		WHERE id = $1 AND is_used = false
+		RETURNING failed_attempts`
+
+	var attempts int
+	if err := r.ExecuteQueryRow(ctx, query, tokenID, maxAttempts).Scan(&attempts); err != nil {
+		return 0, r.HandleSQLError(err, "record failed verification attempt")
+	}
+
+	return attempts, nil
+}
+
+func (r *VerificationTokenRepository) CountCreatedSince(ctx context.Context, userID int64, tokenType string, since time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM verification_tokens
+		WHERE user_id = $1 AND token_type = $2 AND created_at >= $3`
+
+	var count int
+	if err := r.ExecuteQueryRow(ctx, query, userID, tokenType, since).Scan(&count); err != nil {
+		return 0, r.HandleSQLError(err, "count verification tokens")
+	}
+
+	return count, nil
+}
@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+func TestRecordFailedAttemptInvalidatesAtLimit(t *testing.T) {
+	dbm := openTestDB(t)
+	repo := NewVerificationTokenRepository(dbm, testLogger())
+	ctx := context.Background()
+
+	token := &repository.VerificationToken{
+		UserID:    insertTestUser(t, dbm),
+		Token:     uniqueName("otp"),
+		TokenType: "password_change",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	if err := repo.Create(ctx, token); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	for want := 1; want <= 3; want++ {
+		attempts, err := repo.RecordFailedAttempt(ctx, token.ID, 3)
+		if err != nil {
+			t.Fatalf("RecordFailedAttempt() error = %v", err)
+		}
+		if attempts != want {
+			t.Errorf("RecordFailedAttempt() = %d, want %d", attempts, want)
+		}
+	}
+
+	if _, err := repo.GetByUserID(ctx, token.UserID, token.TokenType); err == nil {
+		t.Error("token is still outstanding after reaching the attempt limit")
+	}
+	if _, err := repo.RecordFailedAttempt(ctx, token.ID, 3); err == nil {
+		t.Error("RecordFailedAttempt() counted a guess against an invalidated token")
+	}
+}
@@ -0,0 +1,118 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/database"
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+type WorkspaceInvitationRepository struct {
+	*repository.BaseRepository
+}
+
+func NewWorkspaceInvitationRepository(db database.Manager, logger *slog.Logger) repository.WorkspaceInvitationRepository {
+	return &WorkspaceInvitationRepository{
+		BaseRepository: repository.NewBaseRepository(db, logger, "workspace_invitations"),
+	}
+}
+
+func (r *WorkspaceInvitationRepository) Create(ctx context.Context, invitation *repository.WorkspaceInvitation) error {
+	if invitation.ID == "" {
+		invitation.ID = uuid.New().String()
+	}
+
+	query := `
+		INSERT INTO workspace_invitations (id, workspace_id, email, role, token_hash, invited_by, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	invitation.CreatedAt = time.Now().UTC()
+
+	_, err := r.ExecuteCommand(ctx, query,
+		invitation.ID,
+		invitation.WorkspaceID,
+		invitation.Email,
+		invitation.Role,
+		invitation.TokenHash,
+		invitation.InvitedBy,
+		invitation.ExpiresAt,
+		invitation.CreatedAt,
+	)
+
+	if err != nil {
+		return r.HandleSQLError(err, "create workspace invitation")
+	}
+
+	r.GetLogger().Info("Workspace invitation created successfully",
+		"invitation_id", invitation.ID,
+		"workspace_id", invitation.WorkspaceID,
+		"invited_by", invitation.InvitedBy)
+
+	return nil
+}
+
+func (r *WorkspaceInvitationRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*repository.WorkspaceInvitation, error) {
+	query := `
+		SELECT id, workspace_id, email, role, token_hash, invited_by, expires_at, accepted_by, accepted_at, created_at
+		FROM workspace_invitations
+		WHERE token_hash = $1`
+
+	invitation := &repository.WorkspaceInvitation{}
+	err := r.ExecuteQueryRow(ctx, query, tokenHash).Scan(
+		&invitation.ID,
+		&invitation.WorkspaceID,
+		&invitation.Email,
+		&invitation.Role,
+		&invitation.TokenHash,
+		&invitation.InvitedBy,
+		&invitation.ExpiresAt,
+		&invitation.AcceptedBy,
+		&invitation.AcceptedAt,
+		&invitation.CreatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, r.HandleSQLError(err, "get workspace invitation")
+	}
+
+	return invitation, nil
+}
+
+func (r *WorkspaceInvitationRepository) MarkAccepted(ctx context.Context, id string, userID int64) (bool, error) {
+	query := `
+		UPDATE workspace_invitations
+		SET accepted_by = $1, accepted_at = $2
+		WHERE id = $3 AND accepted_at IS NULL`
+
+	result, err := r.ExecuteCommand(ctx, query, userID, time.Now().UTC(), id)
+	if err != nil {
+		return false, r.HandleSQLError(err, "accept workspace invitation")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, r.HandleSQLError(err, "get rows affected")
+	}
+
+	return rowsAffected > 0, nil
+}
+
+func (r *WorkspaceInvitationRepository) DeletePending(ctx context.Context, workspaceID int64, email string) error {
+	query := `
+		DELETE FROM workspace_invitations
+		WHERE workspace_id = $1 AND LOWER(email) = LOWER($2) AND accepted_at IS NULL`
+
+	if _, err := r.ExecuteCommand(ctx, query, workspaceID, email); err != nil {
+		return r.HandleSQLError(err, "delete pending workspace invitations")
+	}
+
+	return nil
+}
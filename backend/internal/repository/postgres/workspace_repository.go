@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"time"
@@ -23,18 +24,22 @@ func NewWorkspaceRepository(db database.Manager, logger *slog.Logger) repository
 
 func (r *WorkspaceRepository) Create(ctx context.Context, workspace *repository.Workspace) error {
 	query := `
-		INSERT INTO workspaces (name, description, owner_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO workspaces (name, description, owner_id, default_page_permission, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id`
 
 	now := time.Now().UTC()
 	workspace.CreatedAt = now
 	workspace.UpdatedAt = now
+	if workspace.DefaultPagePermission == "" {
+		workspace.DefaultPagePermission = repository.PermissionView
+	}
 
 	row := r.ExecuteQueryRow(ctx, query,
 		workspace.Name,
 		workspace.Description,
 		workspace.OwnerID,
+		workspace.DefaultPagePermission,
 		workspace.CreatedAt,
 		workspace.UpdatedAt,
 	)
@@ -65,7 +70,7 @@ func (r *WorkspaceRepository) Create(ctx context.Context, workspace *repository.
 
 func (r *WorkspaceRepository) GetByID(ctx context.Context, id int64) (*repository.Workspace, error) {
 	query := `
-		SELECT id, name, description, owner_id, created_at, updated_at
+		SELECT id, name, description, owner_id, default_page_permission, created_at, updated_at
 		FROM workspaces 
 		WHERE id = $1`
 
@@ -77,6 +82,7 @@ func (r *WorkspaceRepository) GetByID(ctx context.Context, id int64) (*repositor
 		&workspace.Name,
 		&workspace.Description,
 		&workspace.OwnerID,
+		&workspace.DefaultPagePermission,
 		&workspace.CreatedAt,
 		&workspace.UpdatedAt,
 	)
@@ -93,7 +99,7 @@ func (r *WorkspaceRepository) GetByID(ctx context.Context, id int64) (*repositor
 
 func (r *WorkspaceRepository) GetByOwnerID(ctx context.Context, ownerID int64) ([]*repository.Workspace, error) {
 	query := `
-		SELECT id, name, description, owner_id, created_at, updated_at
+		SELECT id, name, description, owner_id, default_page_permission, created_at, updated_at
 		FROM workspaces 
 		WHERE owner_id = $1
 		ORDER BY created_at DESC`
@@ -112,6 +118,7 @@ func (r *WorkspaceRepository) GetByOwnerID(ctx context.Context, ownerID int64) (
 			&workspace.Name,
 			&workspace.Description,
 			&workspace.OwnerID,
+			&workspace.DefaultPagePermission,
 			&workspace.CreatedAt,
 			&workspace.UpdatedAt,
 		)
@@ -127,14 +134,15 @@ func (r *WorkspaceRepository) GetByOwnerID(ctx context.Context, ownerID int64) (
 func (r *WorkspaceRepository) Update(ctx context.Context, workspace *repository.Workspace) error {
 	query := `
 		UPDATE workspaces 
-		SET name = $1, description = $2, updated_at = $3
-		WHERE id = $4`
+		SET name = $1, description = $2, default_page_permission = $3, updated_at = $4
+		WHERE id = $5`
 
 	workspace.UpdatedAt = time.Now().UTC()
 
 	_, err := r.ExecuteCommand(ctx, query,
 		workspace.Name,
 		workspace.Description,
+		workspace.DefaultPagePermission,
 		workspace.UpdatedAt,
 		workspace.ID,
 	)
@@ -161,7 +169,7 @@ func (r *WorkspaceRepository) Delete(ctx context.Context, id int64) error {
 
 func (r *WorkspaceRepository) List(ctx context.Context, limit, offset int) ([]*repository.Workspace, error) {
 	query := `
-		SELECT id, name, description, owner_id, created_at, updated_at
+		SELECT id, name, description, owner_id, default_page_permission, created_at, updated_at
 		FROM workspaces 
 		ORDER BY created_at DESC
 		LIMIT $1 OFFSET $2`
@@ -180,6 +188,7 @@ func (r *WorkspaceRepository) List(ctx context.Context, limit, offset int) ([]*r
 			&workspace.Name,
 			&workspace.Description,
 			&workspace.OwnerID,
+			&workspace.DefaultPagePermission,
 			&workspace.CreatedAt,
 			&workspace.UpdatedAt,
 		)
@@ -194,7 +203,7 @@ func (r *WorkspaceRepository) List(ctx context.Context, limit, offset int) ([]*r
 
 func (r *WorkspaceRepository) GetUserWorkspaces(ctx context.Context, userID int64) ([]*repository.Workspace, error) {
 	query := `
-		SELECT DISTINCT w.id, w.name, w.description, w.owner_id, w.created_at, w.updated_at
+		SELECT DISTINCT w.id, w.name, w.description, w.owner_id, w.default_page_permission, w.created_at, w.updated_at
 		FROM workspaces w
 		INNER JOIN workspace_members wm ON w.id = wm.workspace_id
 		WHERE wm.user_id = $1
@@ -214,6 +223,7 @@ func (r *WorkspaceRepository) GetUserWorkspaces(ctx context.Context, userID int6
 			&workspace.Name,
 			&workspace.Description,
 			&workspace.OwnerID,
+			&workspace.DefaultPagePermission,
 			&workspace.CreatedAt,
 			&workspace.UpdatedAt,
 		)
@@ -272,6 +282,45 @@ func (r *WorkspaceRepository) RemoveMember(ctx context.Context, workspaceID, use
 	return nil
 }
 
+func (r *WorkspaceRepository) RemoveMemberAndTransferPages(ctx context.Context, workspaceID, userID, newOwnerID int64) (int64, error) {
+	var transferred int64
+	err := r.ExecuteInTransaction(ctx, func(tx *sql.Tx) error {
+		// Trashed pages are transferred too, so they can still be restored
+		result, err := tx.ExecContext(ctx, `
+			UPDATE pages
+			SET owner_id = $3, updated_at = NOW()
+			WHERE workspace_id = $1 AND owner_id = $2`,
+			workspaceID, userID, newOwnerID)
+		if err != nil {
+			return err
+		}
+		if transferred, err = result.RowsAffected(); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			DELETE FROM page_permissions
+			WHERE user_id = $2 AND page_id IN (SELECT id FROM pages WHERE workspace_id = $1)`,
+			workspaceID, userID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM workspace_members WHERE workspace_id = $1 AND user_id = $2`, workspaceID, userID)
+		return err
+	})
+	if err != nil {
+		return 0, r.HandleSQLError(err, "remove workspace member")
+	}
+
+	r.GetLogger().Info("Workspace member removed successfully",
+		"workspace_id", workspaceID,
+		"user_id", userID,
+		"pages_transferred", transferred)
+
+	return transferred, nil
+}
+
 func (r *WorkspaceRepository) GetMembers(ctx context.Context, workspaceID int64) ([]*repository.WorkspaceMember, error) {
 	query := `
 		SELECT id, workspace_id, user_id, role, added_by, created_at, updated_at
@@ -306,6 +355,78 @@ func (r *WorkspaceRepository) GetMembers(ctx context.Context, workspaceID int64)
 	return members, nil
 }
 
+func (r *WorkspaceRepository) GetMembersPaginated(ctx context.Context, workspaceID int64, limit, offset int) ([]*repository.WorkspaceMember, error) {
+	query := `
+		SELECT id, workspace_id, user_id, role, added_by, created_at, updated_at
+		FROM workspace_members
+		WHERE workspace_id = $1
+		ORDER BY created_at ASC, id ASC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.ExecuteQuery(ctx, query, workspaceID, limit, offset)
+	if err != nil {
+		return nil, r.HandleSQLError(err, "get workspace members paginated")
+	}
+	defer rows.Close()
+
+	var members []*repository.WorkspaceMember
+	for rows.Next() {
+		member := &repository.WorkspaceMember{}
+		err := rows.Scan(
+			&member.ID,
+			&member.WorkspaceID,
+			&member.UserID,
+			&member.Role,
+			&member.AddedBy,
+			&member.CreatedAt,
+			&member.UpdatedAt,
+		)
+		if err != nil {
+			return nil, r.HandleSQLError(err, "scan workspace member")
+		}
+		members = append(members, member)
+	}
+
+	return members, nil
+}
+
+func (r *WorkspaceRepository) CountMembers(ctx context.Context, workspaceID int64) (int, error) {
+	query := `SELECT COUNT(*) FROM workspace_members WHERE workspace_id = $1`
+
+	var count int
+	if err := r.ExecuteQueryRow(ctx, query, workspaceID).Scan(&count); err != nil {
+		return 0, r.HandleSQLError(err, "count workspace members")
+	}
+
+	return count, nil
+}
+
+func (r *WorkspaceRepository) GetMember(ctx context.Context, workspaceID, userID int64) (*repository.WorkspaceMember, error) {
+	query := `
+		SELECT id, workspace_id, user_id, role, added_by, created_at, updated_at
+		FROM workspace_members
+		WHERE workspace_id = $1 AND user_id = $2`
+
+	member := &repository.WorkspaceMember{}
+	err := r.ExecuteQueryRow(ctx, query, workspaceID, userID).Scan(
+		&member.ID,
+		&member.WorkspaceID,
+		&member.UserID,
+		&member.Role,
+		&member.AddedBy,
+		&member.CreatedAt,
+		&member.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, r.HandleSQLError(err, "get workspace member")
+	}
+
+	return member, nil
+}
+
 func (r *WorkspaceRepository) UpdateMemberRole(ctx context.Context, workspaceID, userID int64, role repository.WorkspaceRole) error {
 	query := `
 		UPDATE workspace_members 
@@ -341,4 +462,48 @@ func (r *WorkspaceRepository) HasAccess(ctx context.Context, workspaceID, userID
 	}
 
 	return exists, nil
-}
\ No newline at end of file
+}
+
+// GetPropertySchema returns the workspace's page property schema, or nil
+// when properties are free-form
+func (r *WorkspaceRepository) GetPropertySchema(ctx context.Context, workspaceID int64) (json.RawMessage, error) {
+	query := `SELECT property_schema FROM workspaces WHERE id = $1`
+
+	var schema []byte
+	if err := r.ExecuteQueryRow(ctx, query, workspaceID).Scan(&schema); err != nil {
+		return nil, r.HandleSQLError(err, "get workspace property schema")
+	}
+
+	if schema == nil {
+		return nil, nil
+	}
+	return json.RawMessage(schema), nil
+}
+
+// SetPropertySchema stores the workspace's page property schema; nil
+// removes it
+func (r *WorkspaceRepository) SetPropertySchema(ctx context.Context, workspaceID int64, schema json.RawMessage) error {
+	query := `UPDATE workspaces SET property_schema = $1, updated_at = $2 WHERE id = $3`
+
+	var value interface{}
+	if schema != nil {
+		value = []byte(schema)
+	}
+
+	result, err := r.ExecuteCommand(ctx, query, value, time.Now().UTC(), workspaceID)
+	if err != nil {
+		return r.HandleSQLError(err, "set workspace property schema")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return r.HandleSQLError(err, "get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return r.HandleSQLError(sql.ErrNoRows, "set workspace property schema")
+	}
+
+	r.GetLogger().Info("Workspace property schema updated", "workspace_id", workspaceID, "cleared", schema == nil)
+	return nil
+}
@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/database"
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+func countRows(t *testing.T, dbm database.Manager, query string, args ...interface{}) int {
+	t.Helper()
+	var n int
+	if err := dbm.GetDB().QueryRow(query, args...).Scan(&n); err != nil {
+		t.Fatalf("count %q: %v", query, err)
+	}
+	return n
+}
+
+func TestRemoveMemberAndTransferPages(t *testing.T) {
+	dbm := openTestDB(t)
+	repo := NewWorkspaceRepository(dbm, testLogger())
+
+	ownerID := insertTestUser(t, dbm)
+	memberID := insertTestUser(t, dbm)
+	workspaceID := insertTestWorkspace(t, dbm, ownerID, "view")
+	otherWorkspaceID := insertTestWorkspace(t, dbm, ownerID, "view")
+	addTestMember(t, dbm, workspaceID, memberID, "member", ownerID)
+	addTestMember(t, dbm, otherWorkspaceID, memberID, "member", ownerID)
+
+	ownPage := insertTestPage(t, dbm, workspaceID, memberID, "Member page", nil)
+	trashedPage := insertTestPage(t, dbm, workspaceID, memberID, "Trashed member page", nil)
+	mustExec(t, dbm, `UPDATE pages SET deleted_at = NOW() WHERE id = $1`, trashedPage)
+	sharedPage := insertTestPage(t, dbm, workspaceID, ownerID, "Shared", nil)
+	grantTestPermission(t, dbm, sharedPage, memberID, "edit", ownerID)
+	elsewhere := insertTestPage(t, dbm, otherWorkspaceID, ownerID, "Elsewhere", nil)
+	grantTestPermission(t, dbm, elsewhere, memberID, "edit", ownerID)
+
+	transferred, err := repo.RemoveMemberAndTransferPages(context.Background(), workspaceID, memberID, ownerID)
+	if err != nil {
+		t.Fatalf("RemoveMemberAndTransferPages() error = %v", err)
+	}
+	if transferred != 2 {
+		t.Errorf("transferred = %d, want 2", transferred)
+	}
+
+	if n := countRows(t, dbm, `SELECT COUNT(*) FROM pages WHERE id IN ($1, $2) AND owner_id = $3`, ownPage, trashedPage, ownerID); n != 2 {
+		t.Errorf("%d of the member's pages moved to the owner, want 2", n)
+	}
+	if n := countRows(t, dbm, `SELECT COUNT(*) FROM page_permissions WHERE page_id = $1 AND user_id = $2`, sharedPage, memberID); n != 0 {
+		t.Error("grant on a page in the left workspace was kept")
+	}
+	if n := countRows(t, dbm, `SELECT COUNT(*) FROM page_permissions WHERE page_id = $1 AND user_id = $2`, elsewhere, memberID); n != 1 {
+		t.Error("grant in another workspace was removed")
+	}
+	if n := countRows(t, dbm, `SELECT COUNT(*) FROM workspace_members WHERE workspace_id = $1 AND user_id = $2`, workspaceID, memberID); n != 0 {
+		t.Error("membership was kept")
+	}
+	if n := countRows(t, dbm, `SELECT COUNT(*) FROM workspace_members WHERE workspace_id = $1 AND user_id = $2`, otherWorkspaceID, memberID); n != 1 {
+		t.Error("membership in another workspace was removed")
+	}
+}
+
+func TestGetMember(t *testing.T) {
+	dbm := openTestDB(t)
+	repo := NewWorkspaceRepository(dbm, testLogger())
+	ctx := context.Background()
+
+	ownerID := insertTestUser(t, dbm)
+	memberID := insertTestUser(t, dbm)
+	outsiderID := insertTestUser(t, dbm)
+	workspaceID := insertTestWorkspace(t, dbm, ownerID, "view")
+	addTestMember(t, dbm, workspaceID, memberID, "admin", ownerID)
+
+	member, err := repo.GetMember(ctx, workspaceID, memberID)
+	if err != nil {
+		t.Fatalf("GetMember() error = %v", err)
+	}
+	if member == nil || member.UserID != memberID || member.Role != repository.WorkspaceRoleAdmin || member.AddedBy != ownerID {
+		t.Errorf("GetMember() = %+v, want the admin membership", member)
+	}
+
+	member, err = repo.GetMember(ctx, workspaceID, outsiderID)
+	if err != nil || member != nil {
+		t.Errorf("GetMember() for a non-member = %+v, %v; want nil, nil", member, err)
+	}
+}
@@ -2,11 +2,15 @@ package router
 
 import (
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/Srivathsav-max/lumen/backend/internal/authz"
 	"github.com/Srivathsav-max/lumen/backend/internal/container"
 	"github.com/Srivathsav-max/lumen/backend/internal/handlers"
+	"github.com/Srivathsav-max/lumen/backend/internal/metrics"
 	"github.com/Srivathsav-max/lumen/backend/internal/middleware"
+	"github.com/Srivathsav-max/lumen/backend/internal/openapi"
 	"github.com/gin-gonic/gin"
 )
 
@@ -14,6 +18,7 @@ type Router struct {
 	container *container.Container
 	handlers  *handlers.AllHandlers
 	engine    *gin.Engine
+	metrics   *metrics.Registry // nil when metrics are disabled
 }
 
 // RouterConfig holds router configuration
@@ -40,13 +45,28 @@ func NewRouter(container *container.Container) *Router {
 		engine.SetTrustedProxies([]string{"127.0.0.1", "::1", "localhost"})
 	}
 
+	var registry *metrics.Registry
+	if config.Metrics.Enabled {
+		registry = metrics.NewRegistry()
+	}
+
 	return &Router{
 		container: container,
 		handlers:  allHandlers,
 		engine:    engine,
+		metrics:   registry,
 	}
 }
 
+// MetricsHandler serves the metrics registry, or returns nil when metrics are
+// disabled
+func (r *Router) MetricsHandler() http.Handler {
+	if r.metrics == nil {
+		return nil
+	}
+	return r.metrics.Handler()
+}
+
 func (r *Router) SetupRoutes() *gin.Engine {
 	// Add global middleware
 	r.setupGlobalMiddleware()
@@ -54,6 +74,7 @@ func (r *Router) SetupRoutes() *gin.Engine {
 	// Setup route groups
 	r.setupHealthRoutes()
 	r.setupAPIRoutes()
+	r.setupDocsRoutes()
 
 	return r.engine
 }
@@ -74,6 +95,22 @@ func (r *Router) setupGlobalMiddleware() {
 	}))
 
 	r.engine.Use(middleware.RequestIDMiddleware(logger))
+	r.engine.Use(middleware.TimeoutMiddleware(middleware.TimeoutConfig{
+		Timeout:    config.Server.RequestTimeout,
+		SkipRoutes: middleware.DefaultTimeoutSkipRoutes,
+	}, logger))
+
+	if config.AccessLog.Enabled {
+		r.engine.Use(middleware.AccessLogMiddleware(middleware.AccessLogConfig{
+			SlowRequestThreshold: time.Duration(config.AccessLog.SlowRequestThresholdMs) * time.Millisecond,
+			SkipPaths:            middleware.DefaultAccessLogSkipPaths,
+		}, logger))
+	}
+
+	if r.metrics != nil {
+		r.engine.Use(middleware.MetricsMiddleware(r.metrics))
+		r.registerDatabaseGauges()
+	}
 
 	if securityMiddleware != nil {
 		r.engine.Use(securityMiddleware.SecurityHeadersMiddleware())
@@ -95,12 +132,71 @@ func (r *Router) setupGlobalMiddleware() {
 }
 
 func (r *Router) setupHealthRoutes() {
+	// A dedicated METRICS_ADDR keeps /metrics off the public server
+	if r.metrics != nil && r.container.GetConfig().Metrics.Addr == "" {
+		r.engine.GET("/metrics", gin.WrapH(r.metrics.Handler()))
+	}
+
+	// /health is the readiness probe and checks dependencies; /ping is a
+	// dependency-free liveness probe for load balancers
 	r.engine.GET("/health", r.handlers.System.HealthCheck)
 	r.engine.GET("/ping", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "ok", "timestamp": time.Now().Unix()})
 	})
 }
 
+// setupDocsRoutes serves the OpenAPI spec and Swagger UI. It runs after the
+// API routes are registered so it can report routes missing from the spec.
+func (r *Router) setupDocsRoutes() {
+	logger := r.container.GetLogger()
+
+	doc, err := handlers.OpenAPIDocument()
+	if err != nil {
+		logger.Error("Failed to build OpenAPI document", "error", err)
+		return
+	}
+
+	var registered [][2]string
+	for _, route := range r.engine.Routes() {
+		if strings.HasPrefix(route.Path, "/api/v1/") {
+			registered = append(registered, [2]string{route.Method, route.Path})
+		}
+	}
+	undocumented, unregistered := openapi.Coverage(handlers.APIOperations(), registered)
+	if len(undocumented) > 0 {
+		logger.Warn("Routes missing from the OpenAPI document", "routes", undocumented)
+	}
+	if len(unregistered) > 0 {
+		logger.Warn("OpenAPI document describes unregistered routes", "routes", unregistered)
+	}
+
+	r.engine.GET("/api/v1/openapi.json", func(c *gin.Context) {
+		c.JSON(http.StatusOK, doc)
+	})
+	r.engine.GET("/docs", gin.WrapH(openapi.UIHandler(doc.Info.Title, "/api/v1/openapi.json")))
+}
+
+// registerDatabaseGauges exposes the connection pool statistics
+func (r *Router) registerDatabaseGauges() {
+	db := r.container.GetDB()
+	if db == nil {
+		return
+	}
+
+	r.metrics.NewGaugeFunc("db_open_connections", "Open database connections, in use and idle.", func() float64 {
+		return float64(db.Stats().OpenConnections)
+	})
+	r.metrics.NewGaugeFunc("db_in_use_connections", "Database connections currently in use.", func() float64 {
+		return float64(db.Stats().InUse)
+	})
+	r.metrics.NewGaugeFunc("db_idle_connections", "Idle database connections.", func() float64 {
+		return float64(db.Stats().Idle)
+	})
+	r.metrics.NewGaugeFunc("db_wait_count", "Total connections waited for since startup.", func() float64 {
+		return float64(db.Stats().WaitCount)
+	})
+}
+
 func (r *Router) setupAPIRoutes() {
 	// API v1 routes
 	v1 := r.engine.Group("/api/v1")
@@ -121,6 +217,8 @@ func (r *Router) setupV1PublicRoutes(v1 *gin.RouterGroup) {
 	public.POST("/login", r.handlers.Auth.Login)
 	public.POST("/auth/forgot-password", r.handlers.Auth.InitiatePasswordReset)
 	public.POST("/auth/reset-password", r.handlers.Auth.ResetPassword)
+	public.POST("/auth/verify-email", r.handlers.Auth.VerifyEmail)
+	public.POST("/auth/request-verification", r.handlers.Auth.RequestEmailVerification)
 
 	if securityMiddleware != nil {
 		csrfProtected := public.Group("/")
@@ -137,9 +235,22 @@ func (r *Router) setupV1PublicRoutes(v1 *gin.RouterGroup) {
 	public.GET("/system/maintenance", r.handlers.Maintenance.GetMaintenanceStatus)
 	public.GET("/system/registration", r.handlers.SystemSettings.GetRegistrationStatus)
 
+	public.GET("/public/shared/:token", r.handlers.Notes.GetSharedPage)
+
+	embed := v1.Group("/embed")
+	embed.Use(middleware.ViewerTokenMiddleware(r.container.GetViewerTokenService(), logger))
+	{
+		embed.GET("/pages/:page_id", r.handlers.Notes.GetEmbeddedPage)
+	}
+
 	security := v1.Group("/security")
 	{
-		security.POST("/csrf-token", r.handlers.Security.GetCSRFToken)
+		if securityMiddleware != nil {
+			// Signed-in callers get a token bound to their session
+			security.POST("/csrf-token", securityMiddleware.OptionalJWTAuthMiddleware(), r.handlers.Security.GetCSRFToken)
+		} else {
+			security.POST("/csrf-token", r.handlers.Security.GetCSRFToken)
+		}
 		security.POST("/csp-report", r.handlers.Security.CSPReport)
 	}
 }
@@ -154,6 +265,9 @@ func (r *Router) setupV1AuthRoutes(v1 *gin.RouterGroup) {
 
 		auth.POST("/refresh", r.handlers.Auth.RefreshToken)
 
+		auth.GET("/oauth/google", r.handlers.Auth.GoogleOAuthStart)
+		auth.GET("/oauth/google/callback", r.handlers.Auth.GoogleOAuthCallback)
+
 		authProtected := auth.Group("/")
 		if securityMiddleware != nil {
 			authProtected.Use(securityMiddleware.JWTAuthMiddleware())
@@ -165,6 +279,8 @@ func (r *Router) setupV1AuthRoutes(v1 *gin.RouterGroup) {
 			authProtected.POST("/logout", r.handlers.Auth.Logout)
 			authProtected.POST("/revoke", r.handlers.Auth.RevokeToken)
 			authProtected.POST("/change-password", r.handlers.Auth.ChangePassword)
+			authProtected.GET("/sessions", r.handlers.Auth.ListSessions)
+			authProtected.DELETE("/sessions/:id", r.handlers.Auth.RevokeSession)
 		}
 	}
 }
@@ -181,6 +297,8 @@ func (r *Router) setupV1ProtectedRoutes(v1 *gin.RouterGroup) {
 		authService := r.container.GetAuthService()
 		protected.Use(middleware.AuthMiddleware(authService, logger))
 	}
+	// Role requirements for whole route trees live in authz.RoutePolicy
+	protected.Use(authz.Enforce(logger, authz.RoutePolicy))
 	{
 		r.setupUserRoutes(protected)
 
@@ -199,7 +317,6 @@ func (r *Router) setupUserRoutes(protected *gin.RouterGroup) {
 	{
 		profile.GET("", r.handlers.User.GetProfile)
 		profile.PUT("", r.handlers.User.UpdateProfile)
-		profile.POST("/verify-email", r.handlers.User.VerifyEmail)
 		profile.GET("/email-verification", r.handlers.User.CheckEmailVerification)
 		profile.POST("/request-password-change-otp", r.handlers.User.RequestPasswordChangeOTP)
 		profile.POST("/change-password", r.handlers.User.ChangePassword)
@@ -212,10 +329,7 @@ func (r *Router) setupUserRoutes(protected *gin.RouterGroup) {
 }
 
 func (r *Router) setupWaitlistRoutes(protected *gin.RouterGroup) {
-	logger := r.container.GetLogger()
-
 	waitlist := protected.Group("/waitlist")
-	waitlist.Use(middleware.AdminRequiredMiddleware(logger))
 	{
 		waitlist.GET("", r.handlers.Waitlist.GetWaitlistEntries)
 		waitlist.POST("/approve", r.handlers.Waitlist.ApproveWaitlistEntry)
@@ -234,6 +348,8 @@ func (r *Router) setupNotesRoutes(protected *gin.RouterGroup) {
 			workspaces.GET("", r.handlers.Notes.GetUserWorkspaces)
 			workspaces.GET("/:workspace_id", r.handlers.Notes.GetWorkspace)
 			workspaces.PUT("/:workspace_id", r.handlers.Notes.UpdateWorkspace)
+			workspaces.GET("/:workspace_id/property-schema", r.handlers.Notes.GetPropertySchema)
+			workspaces.PUT("/:workspace_id/property-schema", r.handlers.Notes.UpdatePropertySchema)
 			workspaces.DELETE("/:workspace_id", r.handlers.Notes.DeleteWorkspace)
 
 			// Workspace members
@@ -241,39 +357,100 @@ func (r *Router) setupNotesRoutes(protected *gin.RouterGroup) {
 			workspaces.GET("/:workspace_id/members", r.handlers.Notes.GetWorkspaceMembers)
 			workspaces.DELETE("/:workspace_id/members/:user_id", r.handlers.Notes.RemoveWorkspaceMember)
 			workspaces.PUT("/:workspace_id/members/:user_id/role", r.handlers.Notes.UpdateMemberRole)
+			workspaces.POST("/:workspace_id/invitations", r.handlers.Notes.InviteWorkspaceMember)
+			workspaces.POST("/:workspace_id/leave", r.handlers.Notes.LeaveWorkspace)
+
+			// Workspace activity log (workspace admins)
+			workspaces.GET("/:workspace_id/activity", r.handlers.Notes.GetWorkspaceActivity)
 
 			// Workspace pages
 			workspaces.GET("/:workspace_id/pages", r.handlers.Notes.GetWorkspacePages)
 			workspaces.GET("/:workspace_id/pages/root", r.handlers.Notes.GetRootPages)
+			workspaces.GET("/:workspace_id/templates", r.handlers.Notes.GetWorkspaceTemplates)
+			workspaces.POST("/:workspace_id/query", r.handlers.Notes.QueryPages)
+
+			// Orphaned page cleanup (workspace admins)
+			workspaces.GET("/:workspace_id/tree", r.handlers.Notes.GetPageTree)
+			workspaces.GET("/:workspace_id/orphans", r.handlers.Notes.GetOrphanedPages)
+			workspaces.POST("/:workspace_id/orphans/repair", r.handlers.Notes.RepairOrphanedPages)
+			workspaces.GET("/:workspace_id/trash", r.handlers.Notes.GetTrash)
 		}
 
 		// Page routes
 		pages := notes.Group("/pages")
 		{
 			pages.POST("", r.handlers.Notes.CreatePage)
+			pages.POST("/import", r.handlers.Notes.ImportMarkdown)
+			pages.POST("/bulk-archive", r.handlers.Notes.BulkArchivePages)
+			pages.POST("/bulk-delete", r.handlers.Notes.BulkDeletePages)
 			pages.GET("/:page_id", r.handlers.Notes.GetPage)
 			pages.PUT("/:page_id", r.handlers.Notes.UpdatePage)
 			pages.POST("/:page_id/content", r.handlers.Notes.SavePageContent)
+			pages.PATCH("/:page_id/blocks/reorder", r.handlers.Notes.ReorderBlocks)
 			pages.DELETE("/:page_id", r.handlers.Notes.DeletePage)
 			pages.POST("/:page_id/archive", r.handlers.Notes.ArchivePage)
 			pages.POST("/:page_id/restore", r.handlers.Notes.RestorePage)
+			pages.POST("/:page_id/restore-from-trash", r.handlers.Notes.RestorePageFromTrash)
+			pages.POST("/:page_id/purge", r.handlers.Notes.PurgePage)
+			pages.POST("/:page_id/move", r.handlers.Notes.MovePage)
+			pages.POST("/:page_id/duplicate", r.handlers.Notes.DuplicatePage)
+			pages.POST("/:page_id/favorite", r.handlers.Notes.AddFavorite)
+			pages.DELETE("/:page_id/favorite", r.handlers.Notes.RemoveFavorite)
 
 			// Child pages
 			pages.GET("/:page_id/children", r.handlers.Notes.GetChildPages)
+			pages.GET("/:page_id/ancestors", r.handlers.Notes.GetPageAncestors)
+			pages.GET("/:page_id/export", r.handlers.Notes.ExportPage)
 
 			// Page permissions
 			pages.POST("/:page_id/permissions", r.handlers.Notes.GrantPagePermission)
 			pages.GET("/:page_id/permissions", r.handlers.Notes.GetPagePermissions)
 			pages.DELETE("/:page_id/permissions/:user_id", r.handlers.Notes.RevokePagePermission)
+			pages.GET("/:page_id/access", r.handlers.Notes.GetPageAccess)
 
 			// Page versions
 			pages.GET("/:page_id/versions", r.handlers.Notes.GetPageVersions)
 			pages.GET("/:page_id/versions/:version_number", r.handlers.Notes.GetPageVersion)
+
+			// Embedding
+			pages.POST("/:page_id/viewer-tokens", r.handlers.Notes.CreateViewerToken)
+
+			// Share links
+			pages.POST("/:page_id/share-links", r.handlers.Notes.CreateShareLink)
+			pages.GET("/:page_id/share-links", r.handlers.Notes.GetShareLinks)
+			pages.DELETE("/:page_id/share-links/:link_id", r.handlers.Notes.RevokeShareLink)
+
+			// Page comments
+			pages.POST("/:page_id/comments", r.handlers.Notes.CreateComment)
+			pages.GET("/:page_id/comments", r.handlers.Notes.GetPageComments)
+			pages.PUT("/:page_id/comments/:comment_id", r.handlers.Notes.UpdateComment)
+			pages.DELETE("/:page_id/comments/:comment_id", r.handlers.Notes.DeleteComment)
+			pages.POST("/:page_id/comments/:comment_id/replies", r.handlers.Notes.ReplyToComment)
+			pages.POST("/:page_id/comments/:comment_id/resolve", r.handlers.Notes.ResolveComment)
+			pages.POST("/:page_id/comments/:comment_id/unresolve", r.handlers.Notes.UnresolveComment)
+		}
+
+		// Template routes
+		templates := notes.Group("/templates")
+		{
+			templates.POST("/:page_id/instantiate", r.handlers.Notes.InstantiateTemplate)
 		}
 
 		// Search and recent pages
 		notes.POST("/search", r.handlers.Notes.SearchPages)
 		notes.GET("/recent", r.handlers.Notes.GetRecentPages)
+		notes.GET("/favorites", r.handlers.Notes.GetFavoritePages)
+
+		// Notifications
+		notes.GET("/notifications", r.handlers.Notes.GetNotifications)
+		notes.POST("/notifications/:id/read", r.handlers.Notes.MarkNotificationRead)
+		notes.GET("/notifications/preferences", r.handlers.Notes.GetNotificationPreferences)
+		notes.PUT("/notifications/preferences", r.handlers.Notes.UpdateNotificationPreferences)
+
+		notes.POST("/invitations/accept", r.handlers.Notes.AcceptWorkspaceInvitation)
+
+		// Editor content validation (does not persist)
+		notes.POST("/validate-content", r.handlers.Notes.ValidateContent)
 	}
 }
 
@@ -282,15 +459,18 @@ func (r *Router) setupAIRoutes(protected *gin.RouterGroup) {
 	{
 		ai.POST("/generate", r.handlers.AI.GenerateNoteContent)
 		ai.POST("/chat/exchange", r.handlers.AI.SaveExchange)
+		ai.POST("/chat/stream", r.handlers.AI.StreamChat)
 		ai.GET("/chat/history", r.handlers.AI.GetHistory)
+		ai.DELETE("/chat/conversations", r.handlers.AI.ClearConversations)
+		ai.GET("/conversations", r.handlers.AI.ListConversations)
+		ai.DELETE("/conversations/:id", r.handlers.AI.DeleteConversation)
+		ai.PATCH("/conversations/:id/title", r.handlers.AI.RenameConversation)
+		ai.GET("/usage", r.handlers.AI.GetUsage)
 	}
 }
 
 func (r *Router) setupAdminRoutes(protected *gin.RouterGroup) {
-	logger := r.container.GetLogger()
-
 	admin := protected.Group("/admin")
-	admin.Use(middleware.AdminRequiredMiddleware(logger))
 	{
 		r.setupAdminSystemRoutes(admin)
 
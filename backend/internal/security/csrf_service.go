@@ -11,15 +11,21 @@ import (
 	"log/slog"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/Srivathsav-max/lumen/backend/internal/constants"
 )
 
+// AnonymousSessionPrefix marks CSRF sessions issued to callers without an
+// authenticated session. Tokens for them only pass on unauthenticated requests.
+const AnonymousSessionPrefix = "anonymous_"
+
 type CSRFService struct {
-	config *CSRFConfig
-	logger *slog.Logger
+	config     *CSRFConfig
+	signingKey []byte
+	logger     *slog.Logger
 }
 
 type CSRFToken struct {
@@ -38,16 +44,46 @@ type CSRFValidationResult struct {
 }
 
 func NewCSRFService(config *CSRFConfig, logger *slog.Logger) *CSRFService {
-	return &CSRFService{
+	service := &CSRFService{
 		config: config,
 		logger: logger,
 	}
+
+	// Derive a dedicated key so CSRF signatures never double as JWT ones
+	if config.SigningKey != "" {
+		mac := hmac.New(sha256.New, []byte(config.SigningKey))
+		mac.Write([]byte("csrf-token-signing"))
+		service.signingKey = mac.Sum(nil)
+	} else if config.Enabled {
+		logger.Error("CSRF protection is enabled without a signing key; tokens cannot be issued")
+	}
+
+	return service
+}
+
+// NewAnonymousSessionID returns a random CSRF session ID for a caller that
+// is not signed in
+func NewAnonymousSessionID() (string, error) {
+	sessionBytes := make([]byte, 32)
+	if _, err := rand.Read(sessionBytes); err != nil {
+		return "", fmt.Errorf("failed to generate session ID: %w", err)
+	}
+	return AnonymousSessionPrefix + hex.EncodeToString(sessionBytes), nil
 }
 
 func (s *CSRFService) GenerateToken(sessionID string, userID int64, r *http.Request) (*CSRFToken, error) {
 	if !s.config.Enabled {
 		return nil, fmt.Errorf("CSRF protection is disabled")
 	}
+	if len(s.signingKey) == 0 {
+		return nil, fmt.Errorf("CSRF signing key is not configured")
+	}
+	if sessionID == "" {
+		return nil, fmt.Errorf("CSRF tokens require a session")
+	}
+	if strings.HasPrefix(sessionID, AnonymousSessionPrefix) && userID != 0 {
+		return nil, fmt.Errorf("anonymous CSRF sessions cannot belong to a user")
+	}
 
 	now := time.Now().UTC()
 
@@ -81,7 +117,10 @@ func (s *CSRFService) GenerateToken(sessionID string, userID int64, r *http.Requ
 	return csrfToken, nil
 }
 
-func (s *CSRFService) ValidateToken(token string, sessionID string, r *http.Request) *CSRFValidationResult {
+// ValidateToken checks a CSRF token against the request's session and user.
+// userID is 0 for unauthenticated requests, so tokens issued to a signed-in
+// user and anonymous tokens can never stand in for each other.
+func (s *CSRFService) ValidateToken(token string, sessionID string, userID int64, r *http.Request) *CSRFValidationResult {
 	if !s.config.Enabled {
 		return &CSRFValidationResult{Valid: true, Reason: "CSRF protection disabled"}
 	}
@@ -127,6 +166,19 @@ func (s *CSRFService) ValidateToken(token string, sessionID string, r *http.Requ
 		}
 	}
 
+	if tokenInfo.UserID != userID {
+		s.logger.Warn("CSRF token user mismatch",
+			"expected", userID,
+			"actual", tokenInfo.UserID,
+			"session_id", sessionID,
+		)
+		return &CSRFValidationResult{
+			Valid:     false,
+			Reason:    "User mismatch",
+			TokenInfo: tokenInfo,
+		}
+	}
+
 	if !s.validateOrigin(r) {
 		s.logger.Warn("CSRF origin validation failed",
 			"origin", r.Header.Get("Origin"),
@@ -181,6 +233,22 @@ func (s *CSRFService) SetCSRFCookie(w http.ResponseWriter, token *CSRFToken) {
 	http.SetCookie(w, cookie)
 }
 
+// SetSessionCookie stores an anonymous CSRF session ID. The cookie is
+// HttpOnly; scripts only ever need the token itself.
+func (s *CSRFService) SetSessionCookie(w http.ResponseWriter, sessionID string, expiresAt time.Time) {
+	cookie := &http.Cookie{
+		Name:     constants.CSRFSessionCookieName,
+		Value:    sessionID,
+		Path:     "/",
+		Expires:  expiresAt,
+		Secure:   s.config.SecureCookie,
+		HttpOnly: true,
+		SameSite: s.getSameSiteCookie(),
+	}
+
+	http.SetCookie(w, cookie)
+}
+
 func (s *CSRFService) ClearCSRFCookie(w http.ResponseWriter) {
 	cookie := &http.Cookie{
 		Name:     s.config.TokenFieldName,
@@ -196,14 +264,15 @@ func (s *CSRFService) ClearCSRFCookie(w http.ResponseWriter) {
 }
 
 func (s *CSRFService) signToken(token *CSRFToken) (string, error) {
-	payload := fmt.Sprintf("%s|%s|%d|%d",
+	payload := fmt.Sprintf("%s|%s|%d|%d|%d",
 		token.Token,
 		token.SessionID,
+		token.UserID,
 		token.IssuedAt.Unix(),
 		token.ExpiresAt.Unix(),
 	)
 
-	h := hmac.New(sha256.New, []byte("csrf_signing_key"))
+	h := hmac.New(sha256.New, s.signingKey)
 	h.Write([]byte(payload))
 	signature := hex.EncodeToString(h.Sum(nil))
 
@@ -218,15 +287,19 @@ func (s *CSRFService) verifyAndDecodeToken(signedToken string) (*CSRFToken, erro
 		return nil, fmt.Errorf("invalid token encoding: %w", err)
 	}
 
+	if len(s.signingKey) == 0 {
+		return nil, fmt.Errorf("CSRF signing key is not configured")
+	}
+
 	parts := strings.Split(string(decoded), "|")
-	if len(parts) != 5 {
+	if len(parts) != 6 {
 		return nil, fmt.Errorf("invalid token format")
 	}
 
-	payload := strings.Join(parts[:4], "|")
-	providedSignature := parts[4]
+	payload := strings.Join(parts[:5], "|")
+	providedSignature := parts[5]
 
-	h := hmac.New(sha256.New, []byte("csrf_signing_key"))
+	h := hmac.New(sha256.New, s.signingKey)
 	h.Write([]byte(payload))
 	expectedSignature := hex.EncodeToString(h.Sum(nil))
 
@@ -239,31 +312,32 @@ func (s *CSRFService) verifyAndDecodeToken(signedToken string) (*CSRFToken, erro
 		SessionID: parts[1],
 	}
 
-	if issuedAt, err := parseUnixTime(parts[2]); err == nil {
+	userID, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token user")
+	}
+	token.UserID = userID
+
+	if issuedAt, err := parseUnixTime(parts[3]); err == nil {
 		token.IssuedAt = issuedAt
 	}
 
-	if expiresAt, err := parseUnixTime(parts[3]); err == nil {
+	if expiresAt, err := parseUnixTime(parts[4]); err == nil {
 		token.ExpiresAt = expiresAt
 	}
 
 	return token, nil
 }
 
+// extractTokenFromRequest only reads the token from places a cross-site
+// page cannot fill in on the user's behalf. The CSRF cookie, which browsers
+// attach automatically, and the query string are deliberately ignored.
 func (s *CSRFService) extractTokenFromRequest(r *http.Request, fallbackToken string) string {
 	if token := r.Header.Get(s.config.TokenHeaderName); token != "" {
 		return token
 	}
 
-	if token := r.FormValue(s.config.TokenFieldName); token != "" {
-		return token
-	}
-
-	if cookie, err := r.Cookie(s.config.TokenFieldName); err == nil && cookie.Value != "" {
-		return cookie.Value
-	}
-
-	if token := r.URL.Query().Get(s.config.TokenFieldName); token != "" {
+	if token := r.PostFormValue(s.config.TokenFieldName); token != "" {
 		return token
 	}
 
@@ -4,6 +4,7 @@ import (
 	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/hex"
 	"fmt"
 	"log/slog"
@@ -72,7 +73,14 @@ func (s *JWTService) GenerateTokenPair(userID int64, email string, roles []strin
 		return nil, fmt.Errorf("failed to generate session ID: %w", err)
 	}
 
-	fingerprint := s.generateFingerprint(r)
+	var fingerprint, fingerprintHash string
+	if s.config.EnableFingerprinting {
+		fingerprint, err = NewFingerprint()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate fingerprint: %w", err)
+		}
+		fingerprintHash = HashFingerprint(fingerprint, s.config.FingerprintSalt)
+	}
 
 	deviceID := s.generateDeviceID(r)
 
@@ -91,7 +99,7 @@ func (s *JWTService) GenerateTokenPair(userID int64, email string, roles []strin
 		Roles:       roles,
 		TokenID:     tokenID,
 		TokenType:   "access",
-		Fingerprint: fingerprint,
+		Fingerprint: fingerprintHash,
 		IPAddress:   s.getClientIP(r),
 		UserAgent:   s.hashUserAgent(r.UserAgent()),
 		SessionID:   sessionID,
@@ -125,7 +133,7 @@ func (s *JWTService) GenerateTokenPair(userID int64, email string, roles []strin
 		Email:       email,
 		TokenID:     refreshTokenID,
 		TokenType:   "refresh",
-		Fingerprint: fingerprint,
+		Fingerprint: fingerprintHash,
 		SessionID:   sessionID,
 		DeviceID:    deviceID,
 		LoginTime:   now.Unix(),
@@ -154,6 +162,40 @@ func (s *JWTService) GenerateTokenPair(userID int64, email string, roles []strin
 }
 
 func (s *JWTService) ValidateToken(tokenString string, r *http.Request) (*SecureJWTClaims, error) {
+	claims, err := s.ParseIssuedToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.validateSecurityClaims(claims, r); err != nil {
+		return nil, fmt.Errorf("security claims validation failed: %w", err)
+	}
+
+	s.logger.Debug("Token validated successfully",
+		"user_id", claims.UserID,
+		"token_id", claims.TokenID,
+		"token_type", claims.TokenType,
+	)
+
+	return claims, nil
+}
+
+// ParseIssuedToken verifies the signature and standard claims but not the
+// request binding. It is for reading tokens the server has just issued, before
+// the client holds the matching fingerprint cookie.
+func (s *JWTService) ParseIssuedToken(tokenString string) (*SecureJWTClaims, error) {
+	claims, err := s.parseClaims(tokenString)
+	if err != nil {
+		s.logger.Warn("Token parsing failed", "error", err)
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// parseClaims is ParseIssuedToken without logging, for callers that treat an
+// invalid token as anonymous
+func (s *JWTService) parseClaims(tokenString string) (*SecureJWTClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &SecureJWTClaims{}, func(token *jwt.Token) (interface{}, error) {
 		if token.Method.Alg() != s.config.Algorithm {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
@@ -167,7 +209,6 @@ func (s *JWTService) ValidateToken(tokenString string, r *http.Request) (*Secure
 	})
 
 	if err != nil {
-		s.logger.Warn("Token parsing failed", "error", err)
 		return nil, fmt.Errorf("invalid token: %w", err)
 	}
 
@@ -180,16 +221,6 @@ func (s *JWTService) ValidateToken(tokenString string, r *http.Request) (*Secure
 		return nil, fmt.Errorf("standard claims validation failed: %w", err)
 	}
 
-	if err := s.validateSecurityClaims(claims, r); err != nil {
-		return nil, fmt.Errorf("security claims validation failed: %w", err)
-	}
-
-	s.logger.Debug("Token validated successfully",
-		"user_id", claims.UserID,
-		"token_id", claims.TokenID,
-		"token_type", claims.TokenType,
-	)
-
 	return claims, nil
 }
 
@@ -246,12 +277,15 @@ func (s *JWTService) validateSecurityClaims(claims *SecureJWTClaims, r *http.Req
 		return nil
 	}
 
-	currentFingerprint := s.generateFingerprint(r)
-	if claims.Fingerprint != currentFingerprint {
+	var fingerprint string
+	if cookie, err := r.Cookie(constants.FingerprintCookieName); err == nil {
+		fingerprint = cookie.Value
+	}
+	if !VerifyFingerprint(claims.Fingerprint, fingerprint, s.config.FingerprintSalt) {
 		s.logger.Warn("Fingerprint mismatch detected",
-			"expected", claims.Fingerprint,
-			"actual", currentFingerprint,
 			"user_id", claims.UserID,
+			"token_id", claims.TokenID,
+			"has_cookie", fingerprint != "",
 		)
 		return fmt.Errorf("fingerprint validation failed")
 	}
@@ -270,20 +304,32 @@ func (s *JWTService) validateSecurityClaims(claims *SecureJWTClaims, r *http.Req
 	return nil
 }
 
-func (s *JWTService) generateFingerprint(r *http.Request) string {
-	if !s.config.EnableFingerprinting {
-		return ""
+// NewFingerprint returns a random value for the fingerprint cookie. Tokens
+// carry only its hash, so a token stolen through script access cannot be
+// replayed without the HttpOnly cookie.
+func NewFingerprint() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
 	}
+	return hex.EncodeToString(bytes), nil
+}
 
-	userAgent := r.UserAgent()
-	acceptHeader := r.Header.Get("Accept")
-	acceptLanguage := r.Header.Get("Accept-Language")
-	acceptEncoding := r.Header.Get("Accept-Encoding")
-
-	fingerprintData := fmt.Sprintf("%s|%s|%s|%s",
-		userAgent, acceptHeader, acceptLanguage, acceptEncoding)
+// HashFingerprint returns the form of a fingerprint embedded in token claims
+func HashFingerprint(fingerprint, salt string) string {
+	h := hmac.New(sha256.New, []byte(salt))
+	h.Write([]byte(fingerprint))
+	return hex.EncodeToString(h.Sum(nil))
+}
 
-	return s.hashWithSalt(fingerprintData, s.config.FingerprintSalt)
+// VerifyFingerprint reports whether the presented cookie value matches the
+// hash in a token. Tokens issued without a fingerprint never match.
+func VerifyFingerprint(claimed, fingerprint, salt string) bool {
+	if claimed == "" || fingerprint == "" {
+		return false
+	}
+	expected := HashFingerprint(fingerprint, salt)
+	return subtle.ConstantTimeCompare([]byte(claimed), []byte(expected)) == 1
 }
 
 func (s *JWTService) generateDeviceID(r *http.Request) string {
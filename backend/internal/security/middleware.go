@@ -9,7 +9,9 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/Srivathsav-max/lumen/backend/internal/authz"
 	"github.com/Srivathsav-max/lumen/backend/internal/constants"
 	"github.com/gin-gonic/gin"
 )
@@ -19,6 +21,7 @@ type SecurityMiddleware struct {
 	jwtService  *JWTService
 	csrfService *CSRFService
 	xssService  *XSSService
+	rateLimiter RateLimiter
 	logger      *slog.Logger
 }
 
@@ -32,10 +35,27 @@ func NewSecurityMiddleware(config *SecurityConfig, logger *slog.Logger) *Securit
 		jwtService:  jwtService,
 		csrfService: csrfService,
 		xssService:  xssService,
+		rateLimiter: newRateLimiter(&config.RateLimit, logger),
 		logger:      logger,
 	}
 }
 
+// newRateLimiter uses Redis for distributed limits and falls back to the
+// per-process limiter if the Redis settings are unusable
+func newRateLimiter(config *RateLimitConfig, logger *slog.Logger) RateLimiter {
+	if !config.Distributed {
+		return NewMemoryRateLimiter()
+	}
+
+	limiter, err := NewRedisRateLimiter(config.RedisURL, logger)
+	if err != nil {
+		logger.Error("Failed to configure distributed rate limiter, using in-memory limits", "error", err)
+		return NewMemoryRateLimiter()
+	}
+
+	return limiter
+}
+
 func (sm *SecurityMiddleware) GetCSRFService() *CSRFService {
 	return sm.csrfService
 }
@@ -119,7 +139,7 @@ func (sm *SecurityMiddleware) CSRFMiddleware() gin.HandlerFunc {
 			"referer", c.Request.Header.Get("Referer"),
 		)
 
-		sessionID := sm.getSessionID(c)
+		sessionID, userID := sm.getCSRFSession(c)
 		if sessionID == "" {
 			sm.logger.Warn("CSRF validation failed: no session ID found",
 				"path", c.Request.URL.Path,
@@ -140,7 +160,7 @@ func (sm *SecurityMiddleware) CSRFMiddleware() gin.HandlerFunc {
 			"session_id", sessionID,
 		)
 
-		result := sm.csrfService.ValidateToken(csrfToken, sessionID, c.Request)
+		result := sm.csrfService.ValidateToken(csrfToken, sessionID, userID, c.Request)
 		if !result.Valid {
 			sm.logger.Warn("CSRF validation failed",
 				"reason", result.Reason,
@@ -187,7 +207,23 @@ func (sm *SecurityMiddleware) XSSProtectionMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		sanitizedData := sm.xssService.SanitizeJSON(jsonData)
+		sanitizedData, violations := sm.xssService.SanitizeJSON(jsonData)
+		if len(violations) > 0 {
+			for _, violation := range violations {
+				sm.logger.Warn("Request field rejected by XSS protection",
+					"field", violation.Field,
+					"threats", violation.Threats,
+					"path", c.Request.URL.Path,
+					"ip", c.ClientIP(),
+				)
+			}
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   constants.ErrMsgValidationFailed,
+				"details": violations,
+			})
+			c.Abort()
+			return
+		}
 
 		sanitizedBody, _ := json.Marshal(sanitizedData)
 		if !bytes.Equal(body, sanitizedBody) {
@@ -251,23 +287,7 @@ func (sm *SecurityMiddleware) JWTAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		c.Set("user_id", claims.UserID)
-		c.Set("userID", claims.UserID)
-		c.Set("user_email", claims.Email)
-		c.Set("userEmail", claims.Email)
-		c.Set("user_roles", claims.Roles)
-		c.Set("userRoles", claims.Roles)
-		c.Set("session_id", claims.SessionID)
-		c.Set("token_claims", claims)
-
-		isAdmin := false
-		for _, roleName := range claims.Roles {
-			if roleName == constants.RoleAdmin {
-				isAdmin = true
-				break
-			}
-		}
-		c.Set("isAdmin", isAdmin)
+		sm.setAuthContext(c, claims)
 
 		sm.logger.Debug("JWT authentication successful",
 			"user_id", claims.UserID,
@@ -278,6 +298,41 @@ func (sm *SecurityMiddleware) JWTAuthMiddleware() gin.HandlerFunc {
 	}
 }
 
+// OptionalJWTAuthMiddleware authenticates the request when it carries a
+// valid access token and otherwise lets it through anonymously
+func (sm *SecurityMiddleware) OptionalJWTAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := sm.extractJWTToken(c)
+		if token == "" {
+			c.Next()
+			return
+		}
+
+		claims, err := sm.jwtService.ValidateToken(token, c.Request)
+		if err != nil {
+			sm.logger.Debug("Optional JWT authentication failed", "error", err)
+			c.Next()
+			return
+		}
+
+		sm.setAuthContext(c, claims)
+		c.Next()
+	}
+}
+
+func (sm *SecurityMiddleware) setAuthContext(c *gin.Context, claims *SecureJWTClaims) {
+	c.Set("user_id", claims.UserID)
+	c.Set("userID", claims.UserID)
+	c.Set("user_email", claims.Email)
+	c.Set("userEmail", claims.Email)
+	c.Set("user_roles", claims.Roles)
+	c.Set("userRoles", claims.Roles)
+	c.Set("session_id", claims.SessionID)
+	c.Set("token_claims", claims)
+
+	c.Set("isAdmin", authz.RolesGrant(claims.Roles, constants.RoleAdmin))
+}
+
 func (sm *SecurityMiddleware) RateLimitMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if !sm.config.RateLimit.Enabled {
@@ -287,22 +342,29 @@ func (sm *SecurityMiddleware) RateLimitMiddleware() gin.HandlerFunc {
 
 		clientID := sm.getClientIdentifier(c)
 
-		limit := sm.getRateLimitForEndpoint(c.Request.URL.Path)
+		bucket, limit := sm.getRateLimitForEndpoint(c.Request.URL.Path)
+
+		result := sm.rateLimiter.Allow(bucket+":"+clientID, limit, sm.config.RateLimit.Window)
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("X-RateLimit-Reset", strconv.FormatInt(result.ResetAt.Unix(), 10))
+
+		if !result.Allowed {
+			retryAfter := int(time.Until(result.ResetAt).Seconds()) + 1
 
-		if sm.isRateLimited(clientID, limit) {
 			sm.logger.Warn("Rate limit exceeded",
 				"client_id", clientID,
+				"bucket", bucket,
 				"endpoint", c.Request.URL.Path,
 				"limit", limit,
 			)
 
-			c.Header("X-RateLimit-Limit", strconv.Itoa(limit))
-			c.Header("X-RateLimit-Remaining", "0")
-			c.Header("Retry-After", "60")
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
 
 			c.JSON(http.StatusTooManyRequests, gin.H{
 				"error":       constants.ErrMsgRateLimitExceeded,
-				"retry_after": 60,
+				"retry_after": retryAfter,
 			})
 			c.Abort()
 			return
@@ -388,27 +450,32 @@ func (sm *SecurityMiddleware) buildCSPHeader() string {
 	return strings.Join(directives, "; ")
 }
 
-func (sm *SecurityMiddleware) getSessionID(c *gin.Context) string {
+// getCSRFSession returns the session and user a CSRF token must have been
+// issued to. Authenticated requests are bound to the session in their
+// access token; anonymous ones to the random session cookie set with their
+// token. Requests authenticated some other way have no usable session.
+func (sm *SecurityMiddleware) getCSRFSession(c *gin.Context) (string, int64) {
 	if claims, exists := c.Get("token_claims"); exists {
-		if jwtClaims, ok := claims.(*SecureJWTClaims); ok {
+		if jwtClaims, ok := claims.(*SecureJWTClaims); ok && jwtClaims.SessionID != "" {
 			sm.logger.Debug("Found session ID from JWT claims", "session_id", jwtClaims.SessionID)
-			return jwtClaims.SessionID
+			return jwtClaims.SessionID, jwtClaims.UserID
 		}
+		sm.logger.Warn("Authenticated request without a session ID")
+		return "", 0
 	}
 
-	if cookie, err := c.Cookie("session_id"); err == nil {
-		sm.logger.Debug("Found session ID from cookie", "session_id", cookie)
-		return cookie
+	if _, exists := c.Get("user_id"); exists {
+		sm.logger.Warn("Authenticated request without JWT claims")
+		return "", 0
 	}
 
-	if userID, exists := c.Get("user_id"); exists {
-		sessionID := fmt.Sprintf("session_%v", userID)
-		sm.logger.Debug("Generated session ID for user", "user_id", userID, "session_id", sessionID)
-		return sessionID
+	if cookie, err := c.Cookie(constants.CSRFSessionCookieName); err == nil && strings.HasPrefix(cookie, AnonymousSessionPrefix) {
+		sm.logger.Debug("Found anonymous CSRF session")
+		return cookie, 0
 	}
 
 	sm.logger.Warn("No session ID found in any source")
-	return ""
+	return "", 0
 }
 
 func (sm *SecurityMiddleware) extractCSRFToken(c *gin.Context) string {
@@ -416,14 +483,11 @@ func (sm *SecurityMiddleware) extractCSRFToken(c *gin.Context) string {
 		return token
 	}
 
+	// Never the CSRF cookie: browsers send it on cross-site requests too
 	if token := c.PostForm(sm.config.CSRF.TokenFieldName); token != "" {
 		return token
 	}
 
-	if cookie, err := c.Cookie(sm.config.CSRF.TokenFieldName); err == nil {
-		return cookie
-	}
-
 	return ""
 }
 
@@ -440,28 +504,34 @@ func (sm *SecurityMiddleware) extractJWTToken(c *gin.Context) string {
 	return ""
 }
 
+// getClientIdentifier keys requests on the user when the key strategy allows
+// it. Rate limiting runs before authentication, so the user comes from the
+// access token itself; only a correctly signed, unexpired token counts.
 func (sm *SecurityMiddleware) getClientIdentifier(c *gin.Context) string {
-	if userID, exists := c.Get("user_id"); exists {
-		return fmt.Sprintf("user_%v", userID)
+	if sm.config.RateLimit.KeyStrategy == constants.RateLimitKeyUser {
+		if token := sm.extractJWTToken(c); token != "" {
+			if claims, err := sm.jwtService.parseClaims(token); err == nil && claims.UserID != 0 {
+				return fmt.Sprintf("user_%d", claims.UserID)
+			}
+		}
 	}
 
 	return fmt.Sprintf("ip_%s", c.ClientIP())
 }
 
-func (sm *SecurityMiddleware) getRateLimitForEndpoint(path string) int {
+// getRateLimitForEndpoint returns the bucket a path counts against and its
+// limit. Auth endpoints get their own bucket so signing in is not blocked by
+// regular API use.
+func (sm *SecurityMiddleware) getRateLimitForEndpoint(path string) (string, int) {
 	if strings.Contains(path, "/auth/") {
-		return sm.config.RateLimit.AuthRPM
+		return "auth", sm.config.RateLimit.AuthRPM
 	}
 
 	if strings.Contains(path, "/api/") {
-		return sm.config.RateLimit.APIRPM
+		return "api", sm.config.RateLimit.APIRPM
 	}
 
-	return sm.config.RateLimit.PerIPRPM
-}
-
-func (sm *SecurityMiddleware) isRateLimited(clientID string, limit int) bool {
-	return false
+	return "default", sm.config.RateLimit.PerIPRPM
 }
 
 func (sm *SecurityMiddleware) isOriginAllowed(origin string) bool {
@@ -0,0 +1,92 @@
+package security
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newTestXSSMiddleware() *SecurityMiddleware {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return &SecurityMiddleware{
+		xssService: NewXSSService(DefaultXSSConfig(), logger),
+		logger:     logger,
+	}
+}
+
+// runXSSMiddleware posts body through XSSProtectionMiddleware and returns the
+// status and the body the handler behind it received
+func runXSSMiddleware(t *testing.T, body string) (int, string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	var received string
+	engine := gin.New()
+	engine.Use(newTestXSSMiddleware().XSSProtectionMiddleware())
+	engine.POST("/", func(c *gin.Context) {
+		data, _ := io.ReadAll(c.Request.Body)
+		received = string(data)
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+	return rec.Code, received
+}
+
+func TestXSSMiddlewareLeavesEditorBlocksToTheService(t *testing.T) {
+	body := `{"title":"Notes","content":{"blocks":[
+		{"id":"a","type":"paragraph","data":{"text":"<b>R&D</b> <script>alert(1)</script>"}},
+		{"id":"b","type":"paragraph","data":{"text":"<img src=x onerror=alert(1)>"}},
+		{"id":"c","type":"bookmark","data":{"link":"javascript:alert(1)"}}
+	]}}`
+
+	code, received := runXSSMiddleware(t, body)
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", code, http.StatusOK)
+	}
+
+	var sent, got map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &sent); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(received), &got); err != nil {
+		t.Fatalf("handler received invalid JSON: %v", err)
+	}
+
+	sentBlocks, _ := json.Marshal(sent["content"])
+	gotBlocks, _ := json.Marshal(got["content"])
+	if string(sentBlocks) != string(gotBlocks) {
+		t.Errorf("middleware changed editor content\n got: %s\nwant: %s", gotBlocks, sentBlocks)
+	}
+}
+
+func TestXSSMiddlewareKeepsPlainTextAndPasswords(t *testing.T) {
+	code, received := runXSSMiddleware(t, `{"name":"R&D","password":"a&b<c>"}`)
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", code, http.StatusOK)
+	}
+
+	var got map[string]string
+	if err := json.Unmarshal([]byte(received), &got); err != nil {
+		t.Fatalf("handler received invalid JSON: %v", err)
+	}
+	if got["name"] != "R&D" || got["password"] != "a&b<c>" {
+		t.Errorf("handler received %v, want values unchanged", got)
+	}
+}
+
+func TestXSSMiddlewareRejectsMarkupInPlainText(t *testing.T) {
+	code, _ := runXSSMiddleware(t, `{"name":"<img src=x onerror=alert(1)>"}`)
+	if code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", code, http.StatusBadRequest)
+	}
+}
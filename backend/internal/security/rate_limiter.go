@@ -0,0 +1,72 @@
+package security
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter counts requests per key over a window
+type RateLimiter interface {
+	Allow(key string, limit int, window time.Duration) RateLimitResult
+}
+
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	ResetAt   time.Time
+}
+
+// memoryRateLimiter keeps fixed-window counters in process memory, so each
+// instance enforces the limit separately
+type memoryRateLimiter struct {
+	mu        sync.Mutex
+	windows   map[string]*rateWindow
+	lastSweep time.Time
+}
+
+type rateWindow struct {
+	count   int
+	resetAt time.Time
+}
+
+func NewMemoryRateLimiter() RateLimiter {
+	return &memoryRateLimiter{
+		windows:   make(map[string]*rateWindow),
+		lastSweep: time.Now(),
+	}
+}
+
+func (l *memoryRateLimiter) Allow(key string, limit int, window time.Duration) RateLimitResult {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Expired windows are dropped at most once per window so idle clients do
+	// not accumulate
+	if now.Sub(l.lastSweep) >= window {
+		for k, w := range l.windows {
+			if !now.Before(w.resetAt) {
+				delete(l.windows, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	w, ok := l.windows[key]
+	if !ok || !now.Before(w.resetAt) {
+		w = &rateWindow{resetAt: now.Add(window)}
+		l.windows[key] = w
+	}
+
+	result := RateLimitResult{Limit: limit, ResetAt: w.resetAt}
+	if w.count >= limit {
+		return result
+	}
+
+	w.count++
+	result.Allowed = true
+	result.Remaining = limit - w.count
+	return result
+}
@@ -0,0 +1,183 @@
+package security
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisRateLimitKeyPrefix = "ratelimit:"
+	redisTimeout            = 250 * time.Millisecond
+	redisPoolSize           = 16
+	// While Redis is failing, calls skip it for a backoff that doubles with
+	// each failed probe up to redisMaxBackoff
+	redisMinBackoff = time.Second
+	redisMaxBackoff = 30 * time.Second
+)
+
+// slidingWindowScript records a request in a sorted set scored by Redis
+// server time, so every instance agrees on the window regardless of clock
+// skew. It returns {allowed, count, reset_ms}.
+var slidingWindowScript = redis.NewScript(`
+redis.replicate_commands()
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local window = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', KEYS[1], now, now .. '-' .. ARGV[3])
+	count = count + 1
+	allowed = 1
+end
+redis.call('PEXPIRE', KEYS[1], window)
+local reset = now + window
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window
+end
+return {allowed, count, reset}
+`)
+
+// redisRateLimiter shares sliding-window counters across instances through
+// Redis. When Redis is unreachable it lets requests through rather than
+// blocking all traffic, and stops contacting Redis until a backoff elapses.
+type redisRateLimiter struct {
+	client *redis.Client
+	logger *slog.Logger
+	now    func() time.Time
+	// lastWarning throttles the fail-open warning to one per window
+	lastWarning atomic.Int64
+	// retryAt is when Redis may next be tried, in Unix nanoseconds
+	retryAt atomic.Int64
+	backoff atomic.Int64
+}
+
+// NewRedisRateLimiter accepts redis:// and rediss:// URLs, e.g.
+// redis://:password@localhost:6379/0. It connects lazily, so an unavailable
+// Redis at startup only degrades rate limiting.
+func NewRedisRateLimiter(redisURL string, logger *slog.Logger) (RateLimiter, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis URL: %w", err)
+	}
+
+	opts.DialTimeout = redisTimeout
+	opts.ReadTimeout = redisTimeout
+	opts.WriteTimeout = redisTimeout
+	opts.PoolSize = redisPoolSize
+	// A failed call fails open, so retrying only adds latency
+	opts.MaxRetries = -1
+	opts.DialerRetries = 1
+
+	return newRedisRateLimiter(redis.NewClient(opts), logger), nil
+}
+
+func newRedisRateLimiter(client *redis.Client, logger *slog.Logger) *redisRateLimiter {
+	return &redisRateLimiter{
+		client: client,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+func (l *redisRateLimiter) Allow(key string, limit int, window time.Duration) RateLimitResult {
+	now := l.now()
+
+	probing := false
+	if retryAt := l.retryAt.Load(); retryAt != 0 {
+		// Once the backoff elapses a single request probes Redis; the
+		// rest keep failing open until it succeeds
+		if now.UnixNano() < retryAt || !l.retryAt.CompareAndSwap(retryAt, now.Add(l.nextBackoff(false)).UnixNano()) {
+			return failOpen(limit, window, now)
+		}
+		probing = true
+	}
+
+	member := make([]byte, 8)
+	_, _ = rand.Read(member)
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	reply, err := slidingWindowScript.Run(ctx, l.client, []string{redisRateLimitKeyPrefix + key},
+		strconv.FormatInt(window.Milliseconds(), 10), strconv.Itoa(limit), hex.EncodeToString(member)).Result()
+	if err == nil {
+		var result RateLimitResult
+		if result, err = parseSlidingWindowReply(reply, limit); err == nil {
+			if probing {
+				l.backoff.Store(0)
+				l.retryAt.Store(0)
+				l.logger.Info("Distributed rate limiter recovered")
+			}
+			return result
+		}
+	}
+
+	backoff := l.nextBackoff(true)
+	l.retryAt.Store(now.Add(backoff).UnixNano())
+
+	if last := l.lastWarning.Load(); now.Sub(time.Unix(0, last)) >= window && l.lastWarning.CompareAndSwap(last, now.UnixNano()) {
+		l.logger.Warn("Distributed rate limiter unavailable, allowing requests", "error", err, "retry_in", backoff)
+	}
+
+	return failOpen(limit, window, now)
+}
+
+// nextBackoff returns the delay before the next probe, doubling it first
+// when a call has just failed
+func (l *redisRateLimiter) nextBackoff(failed bool) time.Duration {
+	current := time.Duration(l.backoff.Load())
+	if !failed && current > 0 {
+		return current
+	}
+
+	next := redisMinBackoff
+	if current > 0 {
+		next = min(current*2, redisMaxBackoff)
+	}
+	l.backoff.Store(int64(next))
+	return next
+}
+
+func failOpen(limit int, window time.Duration, now time.Time) RateLimitResult {
+	return RateLimitResult{Allowed: true, Limit: limit, Remaining: limit, ResetAt: now.Add(window)}
+}
+
+func parseSlidingWindowReply(reply any, limit int) (RateLimitResult, error) {
+	values, ok := reply.([]any)
+	if !ok || len(values) != 3 {
+		return RateLimitResult{}, fmt.Errorf("unexpected rate limit reply: %v", reply)
+	}
+
+	ints := make([]int64, len(values))
+	for i, v := range values {
+		n, ok := v.(int64)
+		if !ok {
+			return RateLimitResult{}, fmt.Errorf("unexpected rate limit reply: %v", reply)
+		}
+		ints[i] = n
+	}
+
+	remaining := limit - int(ints[1])
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return RateLimitResult{
+		Allowed:   ints[0] == 1,
+		Limit:     limit,
+		Remaining: remaining,
+		ResetAt:   time.UnixMilli(ints[2]),
+	}, nil
+}
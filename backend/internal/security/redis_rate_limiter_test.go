@@ -0,0 +1,134 @@
+package security
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestRedisLimiter(t *testing.T, m *miniredis.Miniredis) *redisRateLimiter {
+	t.Helper()
+	client := redis.NewClient(&redis.Options{Addr: m.Addr(), MaxRetries: -1, DialerRetries: 1, DialTimeout: redisTimeout})
+	t.Cleanup(func() { client.Close() })
+	return newRedisRateLimiter(client, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestRedisRateLimiterWindowRollover(t *testing.T) {
+	m := miniredis.RunT(t)
+	limiter := newTestRedisLimiter(t, m)
+	start := time.UnixMilli(1_700_000_000_000)
+
+	m.SetTime(start)
+	for i := 0; i < 2; i++ {
+		if result := limiter.Allow("client", 2, time.Second); !result.Allowed || result.Remaining != 1-i {
+			t.Fatalf("request %d = %+v, want allowed with %d remaining", i+1, result, 1-i)
+		}
+	}
+
+	result := limiter.Allow("client", 2, time.Second)
+	if result.Allowed || result.Remaining != 0 {
+		t.Fatalf("request over the limit = %+v, want denied", result)
+	}
+	if want := start.Add(time.Second); !result.ResetAt.Equal(want) {
+		t.Errorf("ResetAt = %v, want %v", result.ResetAt, want)
+	}
+
+	m.SetTime(start.Add(500 * time.Millisecond))
+	if limiter.Allow("client", 2, time.Second).Allowed {
+		t.Error("request inside the window was allowed")
+	}
+
+	m.SetTime(start.Add(time.Second + time.Millisecond))
+	if result := limiter.Allow("client", 2, time.Second); !result.Allowed || result.Remaining != 1 {
+		t.Errorf("request after the window = %+v, want allowed with 1 remaining", result)
+	}
+}
+
+func TestRedisRateLimiterSharesLimitAcrossInstances(t *testing.T) {
+	m := miniredis.RunT(t)
+	first := newTestRedisLimiter(t, m)
+	second := newTestRedisLimiter(t, m)
+
+	for i, limiter := range []*redisRateLimiter{first, second, first} {
+		if !limiter.Allow("client", 3, time.Minute).Allowed {
+			t.Fatalf("request %d under the shared limit was denied", i+1)
+		}
+	}
+
+	for name, limiter := range map[string]*redisRateLimiter{"first": first, "second": second} {
+		if limiter.Allow("client", 3, time.Minute).Allowed {
+			t.Errorf("%s instance allowed a request over the shared limit", name)
+		}
+	}
+
+	if !second.Allow("other-client", 3, time.Minute).Allowed {
+		t.Error("limit leaked to a different key")
+	}
+}
+
+func TestRedisRateLimiterBacksOffWhileRedisIsDown(t *testing.T) {
+	m := miniredis.RunT(t)
+	limiter := newTestRedisLimiter(t, m)
+	now := time.Now()
+	limiter.now = func() time.Time { return now }
+	key := redisRateLimitKeyPrefix + "client"
+
+	m.Close()
+	if !limiter.Allow("client", 1, time.Minute).Allowed {
+		t.Fatal("request was denied while Redis was down")
+	}
+
+	// Redis is back, but the limiter must not contact it before the backoff
+	if err := m.Restart(); err != nil {
+		t.Fatalf("restart miniredis: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if !limiter.Allow("client", 1, time.Minute).Allowed {
+			t.Fatal("request during the backoff was denied")
+		}
+	}
+	if m.Exists(key) {
+		t.Fatal("limiter contacted Redis during the backoff")
+	}
+
+	now = now.Add(redisMinBackoff)
+	if !limiter.Allow("client", 1, time.Minute).Allowed {
+		t.Fatal("probe request was denied")
+	}
+	if !m.Exists(key) {
+		t.Fatal("limiter did not probe Redis after the backoff")
+	}
+	if limiter.Allow("client", 1, time.Minute).Allowed {
+		t.Error("limit not enforced after Redis recovered")
+	}
+}
+
+func TestRedisRateLimiterBackoffGrows(t *testing.T) {
+	m := miniredis.RunT(t)
+	limiter := newTestRedisLimiter(t, m)
+	now := time.Now()
+	limiter.now = func() time.Time { return now }
+	m.Close()
+
+	want := redisMinBackoff
+	for i := 0; i < 8; i++ {
+		limiter.Allow("client", 1, time.Minute)
+		if got := time.Duration(limiter.backoff.Load()); got != want {
+			t.Fatalf("backoff after %d failed probes = %v, want %v", i+1, got, want)
+		}
+
+		// Requests before the backoff elapses neither probe nor extend it
+		now = now.Add(want - time.Millisecond)
+		limiter.Allow("client", 1, time.Minute)
+		if got := time.Duration(limiter.backoff.Load()); got != want {
+			t.Fatalf("backoff changed to %v without a probe", got)
+		}
+
+		now = now.Add(time.Millisecond)
+		want = min(want*2, redisMaxBackoff)
+	}
+}
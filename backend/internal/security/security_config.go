@@ -4,6 +4,8 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"time"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/constants"
 )
 
 type SecurityConfig struct {
@@ -56,6 +58,9 @@ type CSRFConfig struct {
 	SameSite string `json:"same_site" validate:"oneof=Strict Lax None"`
 
 	TrustedOrigins []string `json:"trusted_origins"`
+
+	// SigningKey is the secret CSRF tokens are signed with
+	SigningKey string `json:"-"`
 }
 
 type SessionConfig struct {
@@ -89,7 +94,13 @@ type RateLimitConfig struct {
 
 	Window time.Duration `json:"window"`
 
+	// KeyStrategy is constants.RateLimitKeyUser or constants.RateLimitKeyIP
+	KeyStrategy string `json:"key_strategy"`
+
 	Distributed bool `json:"distributed"`
+
+	// RedisURL locates the shared counters when Distributed is set
+	RedisURL string `json:"-"`
 }
 
 type CORSConfig struct {
@@ -197,6 +208,7 @@ func DefaultSecurityConfig() *SecurityConfig {
 			APIRPM:      200,
 			Burst:       50,
 			Window:      time.Minute,
+			KeyStrategy: constants.RateLimitKeyUser,
 			Distributed: false,
 		},
 		CORS: CORSConfig{
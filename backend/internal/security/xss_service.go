@@ -30,6 +30,42 @@ type XSSConfig struct {
 	StrictMode bool `json:"strict_mode"`
 
 	CustomPatterns []string `json:"custom_patterns"`
+
+	// FieldPolicies decide, by JSON key, how a string field is treated.
+	// Keys are matched case-insensitively at any depth; fields without a
+	// policy use FieldPolicyPlainText, so only keys declared here are ever
+	// rewritten.
+	FieldPolicies map[string]FieldPolicy `json:"field_policies"`
+}
+
+// FieldPolicy is how XSS protection treats the value of one field
+type FieldPolicy string
+
+const (
+	// FieldPolicySanitize strips dangerous markup and entity-encodes the value.
+	// Only use it for fields that are rendered as HTML without escaping.
+	FieldPolicySanitize FieldPolicy = "sanitize"
+	// FieldPolicyReject keeps structured values such as emails and usernames
+	// as sent, and rejects them if any threat pattern matches
+	FieldPolicyReject FieldPolicy = "reject"
+	// FieldPolicyPlainText keeps free text such as names verbatim, so "&" or
+	// "<" survive, and rejects only values that contain markup. Plain text is
+	// escaped when rendered, not when stored.
+	FieldPolicyPlainText FieldPolicy = "plain_text"
+	// FieldPolicyRaw leaves the value, and anything nested in it, untouched
+	FieldPolicyRaw FieldPolicy = "raw"
+	// FieldPolicyRichText marks editor content, which the request middleware
+	// passes through untouched. The service storing it must run it through
+	// SanitizeHTML, so allowed formatting is neither stripped nor
+	// entity-encoded before it gets there.
+	FieldPolicyRichText FieldPolicy = "rich_text"
+)
+
+// FieldViolation explains why a field was rejected. Threats are only logged.
+type FieldViolation struct {
+	Field   string   `json:"field"`
+	Message string   `json:"message"`
+	Threats []string `json:"-"`
 }
 
 type SanitizationResult struct {
@@ -88,6 +124,19 @@ func DefaultXSSConfig() *XSSConfig {
 			`(?i)(union|or|and)\s+\d+\s*=\s*\d+`,
 			`(?i)'\s*(or|and)\s+'`,
 		},
+		FieldPolicies: map[string]FieldPolicy{
+			"email":    FieldPolicyReject,
+			"username": FieldPolicyReject,
+			// Passwords are hashed, never rendered, and must reach bcrypt
+			// exactly as typed
+			"password":         FieldPolicyRaw,
+			"current_password": FieldPolicyRaw,
+			"new_password":     FieldPolicyRaw,
+			"confirm_password": FieldPolicyRaw,
+			// EditorJS documents keep their blocks under "blocks"; page
+			// services sanitize them per block type
+			"blocks": FieldPolicyRichText,
+		},
 	}
 }
 
@@ -149,22 +198,45 @@ func (s *XSSService) SanitizeInput(input string) *SanitizationResult {
 	return result
 }
 
-func (s *XSSService) SanitizeJSON(data interface{}) interface{} {
+// SanitizeJSON applies each string field's policy throughout a decoded JSON
+// document. Fields the policy rejects are returned as violations; their
+// values are left as they were.
+func (s *XSSService) SanitizeJSON(data interface{}) (interface{}, []FieldViolation) {
+	var violations []FieldViolation
+	sanitized := s.sanitizeJSONValue("", data, &violations)
+	return sanitized, violations
+}
+
+func (s *XSSService) sanitizeJSONValue(field string, data interface{}, violations *[]FieldViolation) interface{} {
+	if policy := s.FieldPolicy(field); policy == FieldPolicyRaw || policy == FieldPolicyRichText {
+		return data
+	}
+
 	switch v := data.(type) {
 	case string:
-		result := s.SanitizeInput(v)
-		return result.Sanitized
+		if violation := s.CheckField(field, v); violation != nil {
+			*violations = append(*violations, *violation)
+			return v
+		}
+		if s.FieldPolicy(field) != FieldPolicySanitize {
+			return v
+		}
+		return s.SanitizeInput(v).Sanitized
 	case map[string]interface{}:
 		sanitized := make(map[string]interface{})
 		for key, value := range v {
-			sanitizedKey := s.SanitizeInput(key).Sanitized
-			sanitized[sanitizedKey] = s.SanitizeJSON(value)
+			// Keys are plain text too: kept as sent unless they carry markup
+			if threats := s.detectMarkup(key); s.config.Enabled && len(threats) > 0 {
+				*violations = append(*violations, FieldViolation{Field: key, Message: "Must not contain HTML or script content", Threats: threats})
+			}
+			sanitized[key] = s.sanitizeJSONValue(key, value, violations)
 		}
 		return sanitized
 	case []interface{}:
+		// Array items share the key of their array
 		sanitized := make([]interface{}, len(v))
 		for i, value := range v {
-			sanitized[i] = s.SanitizeJSON(value)
+			sanitized[i] = s.sanitizeJSONValue(field, value, violations)
 		}
 		return sanitized
 	default:
@@ -172,6 +244,172 @@ func (s *XSSService) SanitizeJSON(data interface{}) interface{} {
 	}
 }
 
+// FieldPolicy returns the configured policy for a JSON key
+func (s *XSSService) FieldPolicy(field string) FieldPolicy {
+	if policy, ok := s.config.FieldPolicies[strings.ToLower(field)]; ok {
+		return policy
+	}
+	return FieldPolicyPlainText
+}
+
+// CheckField validates a value against its field's policy without changing
+// it, returning nil when the value is acceptable. Sanitized, rich-text and
+// raw fields are never rejected.
+func (s *XSSService) CheckField(field, value string) *FieldViolation {
+	if !s.config.Enabled {
+		return nil
+	}
+
+	switch s.FieldPolicy(field) {
+	case FieldPolicyReject:
+		if threats := s.detectThreats(value); len(threats) > 0 {
+			return &FieldViolation{Field: field, Message: "Contains characters or patterns that are not allowed", Threats: threats}
+		}
+	case FieldPolicyPlainText:
+		if threats := s.detectMarkup(value); len(threats) > 0 {
+			return &FieldViolation{Field: field, Message: "Must not contain HTML or script content", Threats: threats}
+		}
+	}
+	return nil
+}
+
+var (
+	// Browsers only open a tag when "<" is directly followed by a name, so
+	// "a < b" is plain text
+	markupTagRegex      = regexp.MustCompile(`<[a-zA-Z!/?]`)
+	markupProtocolRegex = regexp.MustCompile(`(?i)(javascript|vbscript)\s*:|data\s*:\s*text/html`)
+)
+
+// detectMarkup finds content that only matters if the value is ever rendered
+// as HTML: tags and script URLs. A lone "<" or "&" is fine.
+func (s *XSSService) detectMarkup(input string) []string {
+	var threats []string
+	if markupTagRegex.MatchString(input) {
+		threats = append(threats, "html_tag")
+	}
+	if markupProtocolRegex.MatchString(input) {
+		threats = append(threats, "script_protocol")
+	}
+	return threats
+}
+
+// SanitizeHTML cleans stored rich text such as editor block content. Unlike
+// SanitizeInput it keeps allowed tags intact and does not entity-encode the
+// result, so legitimate formatting survives repeated saves unchanged.
+func (s *XSSService) SanitizeHTML(input string) *SanitizationResult {
+	result := &SanitizationResult{
+		Original:  input,
+		Sanitized: input,
+	}
+
+	if !s.config.Enabled || !strings.Contains(input, "<") {
+		return result
+	}
+
+	var sanitized string
+	if s.config.StrictMode {
+		sanitized = s.stripAllHTML(input)
+	} else {
+		sanitized = richTextScriptRegex.ReplaceAllString(input, "")
+		sanitized = s.filterRichTextTags(sanitized)
+	}
+
+	result.Sanitized = sanitized
+	result.Modified = sanitized != input
+	if result.Modified {
+		result.Threats = s.detectThreats(input)
+		result.Severity = s.calculateSeverity(result.Threats)
+	}
+
+	return result
+}
+
+// IsSafeURL reports whether a URL stored outside of markup, such as a
+// bookmark link, uses an allowed protocol
+func (s *XSSService) IsSafeURL(rawURL string) bool {
+	if !s.config.Enabled {
+		return true
+	}
+
+	// Browsers ignore control characters and whitespace inside the scheme, so
+	// "java\tscript:" has to be caught as well
+	cleaned := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, html.UnescapeString(rawURL))
+
+	parsedURL, err := url.Parse(cleaned)
+	if err != nil {
+		return false
+	}
+
+	return s.isProtocolAllowed(parsedURL.Scheme)
+}
+
+var (
+	richTextScriptRegex    = regexp.MustCompile(`(?is)<(script|style)\b[^>]*>.*?</(script|style)\s*>`)
+	richTextTagRegex       = regexp.MustCompile(`(?i)<(/?)([a-zA-Z][a-zA-Z0-9]*)([^>]*)>`)
+	richTextAttributeRegex = regexp.MustCompile(`([^\s"'>/=]+)(?:\s*=\s*("[^"]*"|'[^']*'|[^\s"'>]+))?`)
+)
+
+// filterRichTextTags drops tags outside the allowlist and, on allowed tags,
+// every attribute outside the allowlist or pointing at a disallowed protocol.
+// Event handlers and style attributes are never allowlisted, so they go too.
+func (s *XSSService) filterRichTextTags(input string) string {
+	return richTextTagRegex.ReplaceAllStringFunc(input, func(match string) string {
+		parts := richTextTagRegex.FindStringSubmatch(match)
+		closing, tagName, attributes := parts[1], strings.ToLower(parts[2]), parts[3]
+
+		if !containsFold(s.config.AllowedTags, tagName) {
+			return ""
+		}
+		if closing != "" {
+			return "</" + tagName + ">"
+		}
+
+		var kept strings.Builder
+		for _, attr := range richTextAttributeRegex.FindAllStringSubmatch(attributes, -1) {
+			name, value := strings.ToLower(attr[1]), attr[2]
+			if !containsFold(s.config.AllowedAttributes, name) {
+				continue
+			}
+			if name == "href" || name == "src" {
+				if !s.IsSafeURL(strings.Trim(value, `"'`)) {
+					continue
+				}
+			}
+
+			kept.WriteString(" " + name)
+			if value != "" {
+				kept.WriteString("=" + value)
+			}
+		}
+
+		selfClosing := ""
+		if strings.HasSuffix(strings.TrimSpace(attributes), "/") {
+			selfClosing = " /"
+		}
+
+		rebuilt := "<" + tagName + kept.String() + selfClosing + ">"
+		// Leave untouched tags byte-for-byte so clean content is not reported as modified
+		if strings.EqualFold(strings.Join(strings.Fields(rebuilt), " "), strings.Join(strings.Fields(match), " ")) {
+			return match
+		}
+		return rebuilt
+	})
+}
+
+func containsFold(values []string, target string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, target) {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *XSSService) ValidateInput(input string) bool {
 	threats := s.detectThreats(input)
 	return len(threats) == 0
@@ -0,0 +1,83 @@
+package security
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+)
+
+func newTestXSSService(policies map[string]FieldPolicy) *XSSService {
+	config := DefaultXSSConfig()
+	for field, policy := range policies {
+		config.FieldPolicies[field] = policy
+	}
+	return NewXSSService(config, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestSanitizeJSONFieldPolicies(t *testing.T) {
+	xss := newTestXSSService(map[string]FieldPolicy{"bio_html": FieldPolicySanitize})
+
+	tests := []struct {
+		name     string
+		field    string
+		value    string
+		want     string
+		rejected bool
+	}{
+		{name: "undeclared field keeps ampersands", field: "name", value: "R&D", want: "R&D"},
+		{name: "undeclared field keeps a lone angle bracket", field: "title", value: "a < b & c", want: "a < b & c"},
+		{name: "undeclared field rejects tags", field: "description", value: "<b>bold</b>", rejected: true},
+		{name: "undeclared field rejects script URLs", field: "website", value: "javascript:alert(1)", rejected: true},
+		{name: "password is raw", field: "password", value: "p&ss<w>rd\"", want: "p&ss<w>rd\""},
+		{name: "new password is raw", field: "new_password", value: "<script>", want: "<script>"},
+		{name: "current password is raw", field: "Current_Password", value: "R&D<1>", want: "R&D<1>"},
+		{name: "email rejects threats", field: "email", value: "a<script>@x.test", rejected: true},
+		{name: "declared rich text field is sanitized", field: "bio_html", value: "R&D<script>x</script>", want: "R&amp;D"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, violations := xss.SanitizeJSON(map[string]interface{}{tt.field: tt.value})
+
+			if tt.rejected {
+				if len(violations) != 1 || violations[0].Field != tt.field {
+					t.Fatalf("violations = %+v, want one for %q", violations, tt.field)
+				}
+				return
+			}
+			if len(violations) != 0 {
+				t.Fatalf("violations = %+v, want none", violations)
+			}
+			if value := got.(map[string]interface{})[tt.field]; value != tt.want {
+				t.Errorf("%s = %q, want %q", tt.field, value, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeJSONNestedFields(t *testing.T) {
+	xss := newTestXSSService(nil)
+
+	got, violations := xss.SanitizeJSON(map[string]interface{}{
+		"properties": map[string]interface{}{"R&D budget": "Q&A"},
+		"tags":       []interface{}{"R&D", "ops"},
+	})
+	if len(violations) != 0 {
+		t.Fatalf("violations = %+v, want none", violations)
+	}
+
+	doc := got.(map[string]interface{})
+	if value := doc["properties"].(map[string]interface{})["R&D budget"]; value != "Q&A" {
+		t.Errorf("nested value = %v, want key and value kept verbatim", doc["properties"])
+	}
+	if tags := doc["tags"].([]interface{}); tags[0] != "R&D" {
+		t.Errorf("tags = %v, want R&D kept verbatim", tags)
+	}
+
+	_, violations = xss.SanitizeJSON(map[string]interface{}{
+		"properties": map[string]interface{}{"<img src=x>": "x"},
+	})
+	if len(violations) != 1 {
+		t.Errorf("violations = %+v, want one for a key with markup", violations)
+	}
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+// activityWriteTimeout bounds a background activity insert
+const activityWriteTimeout = 5 * time.Second
+
+// ActivityEvent describes something a user did in a workspace
+type ActivityEvent struct {
+	WorkspaceID int64
+	ActorID     int64
+	Action      repository.ActivityAction
+	TargetType  string
+	TargetID    string
+	Metadata    map[string]interface{}
+}
+
+// ActivityService keeps the per-workspace audit log
+type ActivityService interface {
+	// Record stores the event in the background. Failures are logged and never
+	// affect the caller.
+	Record(event ActivityEvent)
+	ListWorkspaceActivity(ctx context.Context, userID int64, workspaceID int64, limit, offset int) ([]ActivityResponse, error)
+}
+
+type activityService struct {
+	activityRepo  repository.ActivityRepository
+	workspaceRepo repository.WorkspaceRepository
+	userRepo      repository.UserRepository
+	logger        *slog.Logger
+}
+
+func NewActivityService(
+	activityRepo repository.ActivityRepository,
+	workspaceRepo repository.WorkspaceRepository,
+	userRepo repository.UserRepository,
+	logger *slog.Logger,
+) ActivityService {
+	return &activityService{
+		activityRepo:  activityRepo,
+		workspaceRepo: workspaceRepo,
+		userRepo:      userRepo,
+		logger:        logger,
+	}
+}
+
+func (s *activityService) Record(event ActivityEvent) {
+	activity := &repository.WorkspaceActivity{
+		WorkspaceID: event.WorkspaceID,
+		Action:      event.Action,
+		TargetType:  event.TargetType,
+		TargetID:    event.TargetID,
+	}
+	if event.ActorID != 0 {
+		actorID := event.ActorID
+		activity.ActorID = &actorID
+	}
+	if len(event.Metadata) > 0 {
+		metadata, err := json.Marshal(event.Metadata)
+		if err != nil {
+			s.logger.Error("Failed to encode activity metadata", "error", err, "action", event.Action)
+		} else {
+			activity.Metadata = metadata
+		}
+	}
+
+	// Detached from the request so a cancelled request still gets logged
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), activityWriteTimeout)
+		defer cancel()
+
+		if err := s.activityRepo.Create(ctx, activity); err != nil {
+			s.logger.Error("Failed to record workspace activity",
+				"error", err,
+				"workspace_id", activity.WorkspaceID,
+				"action", activity.Action,
+				"target_id", activity.TargetID)
+		}
+	}()
+}
+
+func (s *activityService) ListWorkspaceActivity(ctx context.Context, userID int64, workspaceID int64, limit, offset int) ([]ActivityResponse, error) {
+	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil {
+		s.logger.Error("Failed to get workspace", "error", err, "workspace_id", workspaceID)
+		return nil, NewInternalError("Failed to get workspace")
+	}
+
+	if workspace == nil {
+		return nil, NewNotFoundError("Workspace not found")
+	}
+
+	if workspace.OwnerID != userID {
+		member, err := s.workspaceRepo.GetMember(ctx, workspaceID, userID)
+		if err != nil {
+			s.logger.Error("Failed to get workspace member", "error", err, "workspace_id", workspaceID, "user_id", userID)
+			return nil, NewInternalError("Failed to get workspace members")
+		}
+
+		if member == nil || (member.Role != repository.WorkspaceRoleAdmin && member.Role != repository.WorkspaceRoleOwner) {
+			return nil, NewForbiddenError("Workspace admin access required")
+		}
+	}
+
+	activities, err := s.activityRepo.ListByWorkspace(ctx, workspaceID, limit, offset)
+	if err != nil {
+		s.logger.Error("Failed to list workspace activity", "error", err, "workspace_id", workspaceID)
+		return nil, NewInternalError("Failed to get workspace activity")
+	}
+
+	usernames := make(map[int64]string)
+	responses := make([]ActivityResponse, 0, len(activities))
+	for _, activity := range activities {
+		response := ActivityResponse{
+			ID:         activity.ID,
+			ActorID:    activity.ActorID,
+			Action:     string(activity.Action),
+			TargetType: activity.TargetType,
+			TargetID:   activity.TargetID,
+			Metadata:   activity.Metadata,
+			CreatedAt:  activity.CreatedAt,
+		}
+
+		if activity.ActorID != nil {
+			username, ok := usernames[*activity.ActorID]
+			if !ok {
+				user, err := s.userRepo.GetByID(ctx, *activity.ActorID)
+				if err != nil {
+					s.logger.Error("Failed to get user", "error", err, "user_id", *activity.ActorID)
+				} else if user != nil {
+					username = user.Username
+				}
+				usernames[*activity.ActorID] = username
+			}
+			response.ActorUsername = username
+		}
+
+		responses = append(responses, response)
+	}
+
+	return responses, nil
+}
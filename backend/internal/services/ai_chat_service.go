@@ -3,28 +3,62 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log/slog"
+	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/google/uuid"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/config"
 	"github.com/Srivathsav-max/lumen/backend/internal/repository"
 )
 
 type AIChatService interface {
 	SaveExchange(ctx context.Context, userID int64, chatType string, pageID *string, userContent string, assistantContent string) (string, error)
+	// SaveStreamedExchange is SaveExchange for streamed answers; truncated marks
+	// an answer whose stream ended before the model finished
+	SaveStreamedExchange(ctx context.Context, userID int64, chatType string, pageID *string, userContent string, assistantContent string, truncated bool) (string, error)
 	GetHistory(ctx context.Context, userID int64, chatType string, pageID *string, limit, offset int) ([]repository.AIMessage, error)
+	// RenameConversation sets a title chosen by the user
+	RenameConversation(ctx context.Context, userID int64, conversationID string, title string) (*repository.AIConversation, error)
+	ListConversations(ctx context.Context, userID int64, limit, offset int) ([]*repository.AIConversationSummary, error)
+	DeleteConversation(ctx context.Context, userID int64, conversationID string) error
+	ClearConversations(ctx context.Context, userID int64) (int64, error)
+	PruneConversations(ctx context.Context) (int64, error)
 }
 
+const (
+	maxConversationTitleLength = 80
+	titleGenerationTimeout     = 30 * time.Second
+)
+
 type aiChatService struct {
 	convRepo repository.AIConversationRepository
 	msgRepo  repository.AIMessageRepository
+	ai       AIService
+	config   *config.AIConfig
 	logger   *slog.Logger
 }
 
-func NewAIChatService(convRepo repository.AIConversationRepository, msgRepo repository.AIMessageRepository, logger *slog.Logger) AIChatService {
-	return &aiChatService{convRepo: convRepo, msgRepo: msgRepo, logger: logger}
+func NewAIChatService(convRepo repository.AIConversationRepository, msgRepo repository.AIMessageRepository, ai AIService, cfg *config.AIConfig, logger *slog.Logger) AIChatService {
+	return &aiChatService{convRepo: convRepo, msgRepo: msgRepo, ai: ai, config: cfg, logger: logger}
 }
 
 func (s *aiChatService) SaveExchange(ctx context.Context, userID int64, chatType string, pageID *string, userContent string, assistantContent string) (string, error) {
+	return s.saveExchange(ctx, userID, chatType, pageID, userContent, assistantContent, json.RawMessage("{}"))
+}
+
+func (s *aiChatService) SaveStreamedExchange(ctx context.Context, userID int64, chatType string, pageID *string, userContent string, assistantContent string, truncated bool) (string, error) {
+	metadata, err := json.Marshal(map[string]interface{}{"streamed": true, "truncated": truncated})
+	if err != nil {
+		return "", err
+	}
+	return s.saveExchange(ctx, userID, chatType, pageID, userContent, assistantContent, metadata)
+}
+
+func (s *aiChatService) saveExchange(ctx context.Context, userID int64, chatType string, pageID *string, userContent string, assistantContent string, assistantMetadata json.RawMessage) (string, error) {
 	// Derive title from the prompt
 	var title *string
 	if userContent != "" {
@@ -49,14 +83,156 @@ func (s *aiChatService) SaveExchange(ctx context.Context, userID int64, chatType
 	}
 	// Save assistant message
 	if assistantContent != "" {
-		am := &repository.AIMessage{ConversationID: conv.ID, Role: "assistant", Content: assistantContent, Metadata: json.RawMessage("{}"), CreatedAt: time.Now().UTC()}
+		am := &repository.AIMessage{ConversationID: conv.ID, Role: "assistant", Content: assistantContent, Metadata: assistantMetadata, CreatedAt: time.Now().UTC()}
 		if err := s.msgRepo.CreateMessage(ctx, am); err != nil {
 			return "", err
 		}
+		if s.isFirstReply(ctx, conv.ID) {
+			go s.generateTitle(conv.ID, userContent, assistantContent)
+		}
 	}
 	return conv.ID, nil
 }
 
+// isFirstReply reports whether the conversation holds exactly one assistant message
+func (s *aiChatService) isFirstReply(ctx context.Context, conversationID string) bool {
+	msgs, err := s.msgRepo.ListMessages(ctx, conversationID, 3, 0)
+	if err != nil {
+		return false
+	}
+	replies := 0
+	for _, m := range msgs {
+		if m.Role == "assistant" {
+			replies++
+		}
+	}
+	return replies == 1
+}
+
+// generateTitle asks the model to summarise the opening exchange and replaces
+// the placeholder title derived from the prompt. It runs in the background, so
+// failures are only logged.
+func (s *aiChatService) generateTitle(conversationID string, userContent string, assistantContent string) {
+	if s.ai == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), titleGenerationTimeout)
+	defer cancel()
+
+	prompt := "Write a short title of at most six words for a conversation that opens with the exchange below. " +
+		"Reply with the title only, without quotes or trailing punctuation.\n\n" +
+		"User: " + truncateRunes(userContent, 2000) + "\n\nAssistant: " + truncateRunes(assistantContent, 2000)
+
+	resp, err := s.ai.GenerateContent(ctx, &AISpec{Query: prompt})
+	if err != nil {
+		s.logger.Warn("Failed to generate AI conversation title", "error", err, "conversation_id", conversationID)
+		return
+	}
+
+	title := cleanConversationTitle(resp.Text)
+	if title == "" {
+		return
+	}
+	if err := s.convRepo.UpdateTitle(ctx, conversationID, title); err != nil {
+		s.logger.Warn("Failed to save AI conversation title", "error", err, "conversation_id", conversationID)
+	}
+}
+
+func (s *aiChatService) RenameConversation(ctx context.Context, userID int64, conversationID string, title string) (*repository.AIConversation, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, NewBadRequestError("Title is required")
+	}
+	if utf8.RuneCountInString(title) > maxConversationTitleLength {
+		return nil, NewBadRequestError(fmt.Sprintf("Title must be at most %d characters", maxConversationTitleLength))
+	}
+
+	conv, err := s.getOwnedConversation(ctx, userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.convRepo.UpdateTitle(ctx, conv.ID, title); err != nil {
+		s.logger.Error("Failed to rename AI conversation", "error", err, "conversation_id", conv.ID)
+		return nil, NewInternalError("Failed to rename conversation")
+	}
+
+	conv.Title = &title
+	return conv, nil
+}
+
+func (s *aiChatService) ListConversations(ctx context.Context, userID int64, limit, offset int) ([]*repository.AIConversationSummary, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	convs, err := s.convRepo.ListByUser(ctx, userID, limit, offset)
+	if err != nil {
+		s.logger.Error("Failed to list AI conversations", "error", err, "user_id", userID)
+		return nil, NewInternalError("Failed to list conversations")
+	}
+	return convs, nil
+}
+
+func (s *aiChatService) DeleteConversation(ctx context.Context, userID int64, conversationID string) error {
+	conv, err := s.getOwnedConversation(ctx, userID, conversationID)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := s.convRepo.Delete(ctx, conv.ID)
+	if err != nil {
+		s.logger.Error("Failed to delete AI conversation", "error", err, "conversation_id", conv.ID)
+		return NewInternalError("Failed to delete conversation")
+	}
+	if !deleted {
+		return NewNotFoundError("Conversation")
+	}
+
+	s.logger.Info("AI conversation deleted", "conversation_id", conv.ID, "user_id", userID)
+	return nil
+}
+
+// getOwnedConversation loads a conversation, hiding conversations of other users
+func (s *aiChatService) getOwnedConversation(ctx context.Context, userID int64, conversationID string) (*repository.AIConversation, error) {
+	if _, err := uuid.Parse(conversationID); err != nil {
+		return nil, NewNotFoundError("Conversation")
+	}
+
+	conv, err := s.convRepo.GetByID(ctx, conversationID)
+	if err != nil {
+		s.logger.Error("Failed to get AI conversation", "error", err, "conversation_id", conversationID)
+		return nil, NewInternalError("Failed to get conversation")
+	}
+	if conv == nil || conv.UserID != userID {
+		return nil, NewNotFoundError("Conversation")
+	}
+	return conv, nil
+}
+
+// cleanConversationTitle keeps the first line of a model reply, strips quotes
+// and trailing punctuation and caps the length
+func cleanConversationTitle(text string) string {
+	title := strings.TrimSpace(text)
+	if i := strings.IndexByte(title, '\n'); i >= 0 {
+		title = title[:i]
+	}
+	title = strings.Trim(title, " \t\"'`*#")
+	title = strings.TrimRight(title, ".:;!")
+	return truncateRunes(strings.TrimSpace(title), maxConversationTitleLength)
+}
+
+func truncateRunes(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max])
+}
+
 func (s *aiChatService) GetHistory(ctx context.Context, userID int64, chatType string, pageID *string, limit, offset int) ([]repository.AIMessage, error) {
 	if limit <= 0 {
 		limit = 50
@@ -81,3 +257,63 @@ func (s *aiChatService) GetHistory(ctx context.Context, userID int64, chatType s
 	}
 	return out, nil
 }
+
+// ClearConversations deletes all of the user's conversations and their messages
+func (s *aiChatService) ClearConversations(ctx context.Context, userID int64) (int64, error) {
+	deleted, err := s.convRepo.DeleteByUser(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to clear AI conversations", "error", err, "user_id", userID)
+		return 0, NewInternalError("Failed to clear conversations")
+	}
+	return deleted, nil
+}
+
+// PruneConversations enforces the configured retention rules: conversations
+// idle for longer than the retention period are removed first, then each user
+// is trimmed to their most recent conversations. Messages cascade.
+func (s *aiChatService) PruneConversations(ctx context.Context) (int64, error) {
+	var total int64
+
+	if s.config.ConversationRetentionDays > 0 {
+		cutoff := time.Now().UTC().AddDate(0, 0, -s.config.ConversationRetentionDays)
+		deleted, err := s.convRepo.DeleteInactiveSince(ctx, cutoff)
+		if err != nil {
+			s.logger.Error("Failed to prune inactive AI conversations", "error", err, "cutoff", cutoff)
+			return total, NewInternalError("Failed to prune conversations")
+		}
+		total += deleted
+	}
+
+	if s.config.MaxConversationsPerUser > 0 {
+		deleted, err := s.convRepo.DeleteExceedingPerUser(ctx, s.config.MaxConversationsPerUser)
+		if err != nil {
+			s.logger.Error("Failed to prune excess AI conversations", "error", err, "keep", s.config.MaxConversationsPerUser)
+			return total, NewInternalError("Failed to prune conversations")
+		}
+		total += deleted
+	}
+
+	if total > 0 {
+		s.logger.Info("Pruned AI conversations", "deleted", total)
+	}
+	return total, nil
+}
+
+// StartConversationPruning runs PruneConversations on the given interval until ctx is cancelled
+func StartConversationPruning(ctx context.Context, chat AIChatService, interval time.Duration, logger *slog.Logger) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := chat.PruneConversations(ctx); err != nil {
+					logger.Error("AI conversation pruning failed", "error", err)
+				}
+			}
+		}
+	}()
+}
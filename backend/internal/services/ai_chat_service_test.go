@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/config"
+	"github.com/Srivathsav-max/lumen/backend/internal/constants"
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+// fakeConversationRepo records the pruning calls it receives
+type fakeConversationRepo struct {
+	repository.AIConversationRepository
+	mu      sync.Mutex
+	cutoffs []time.Time
+	keeps   []int
+}
+
+func (r *fakeConversationRepo) DeleteInactiveSince(ctx context.Context, cutoff time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cutoffs = append(r.cutoffs, cutoff)
+	return 2, nil
+}
+
+func (r *fakeConversationRepo) DeleteExceedingPerUser(ctx context.Context, keep int) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keeps = append(r.keeps, keep)
+	return 3, nil
+}
+
+func pruneWith(t *testing.T, cfg *config.AIConfig) (*fakeConversationRepo, int64) {
+	t.Helper()
+	repo := &fakeConversationRepo{}
+	svc := NewAIChatService(repo, nil, nil, cfg, discardLogger())
+	deleted, err := svc.PruneConversations(context.Background())
+	if err != nil {
+		t.Fatalf("PruneConversations() error = %v", err)
+	}
+	return repo, deleted
+}
+
+func TestPruneConversationsIsDisabledByDefault(t *testing.T) {
+	repo, deleted := pruneWith(t, &config.AIConfig{
+		ConversationRetentionDays: constants.DefaultAIConversationRetentionDays,
+		MaxConversationsPerUser:   constants.DefaultAIMaxConversationsPerUser,
+	})
+
+	if deleted != 0 || len(repo.cutoffs) != 0 || len(repo.keeps) != 0 {
+		t.Errorf("default config pruned %d conversations (cutoffs %v, keeps %v), want nothing", deleted, repo.cutoffs, repo.keeps)
+	}
+}
+
+func TestPruneConversationsByAge(t *testing.T) {
+	before := time.Now().UTC()
+	repo, deleted := pruneWith(t, &config.AIConfig{ConversationRetentionDays: 30})
+
+	if len(repo.cutoffs) != 1 || len(repo.keeps) != 0 {
+		t.Fatalf("cutoffs %v, keeps %v; want one age-based prune only", repo.cutoffs, repo.keeps)
+	}
+	want := before.AddDate(0, 0, -30)
+	if got := repo.cutoffs[0]; got.Before(want) || got.Sub(want) > time.Minute {
+		t.Errorf("cutoff = %v, want about %v", got, want)
+	}
+	if deleted != 2 {
+		t.Errorf("deleted = %d, want 2", deleted)
+	}
+}
+
+func TestPruneConversationsByCount(t *testing.T) {
+	repo, deleted := pruneWith(t, &config.AIConfig{MaxConversationsPerUser: 10})
+
+	if len(repo.cutoffs) != 0 || len(repo.keeps) != 1 || repo.keeps[0] != 10 {
+		t.Fatalf("cutoffs %v, keeps %v; want one count-based prune keeping 10", repo.cutoffs, repo.keeps)
+	}
+	if deleted != 3 {
+		t.Errorf("deleted = %d, want 3", deleted)
+	}
+}
+
+func TestPruneConversationsAppliesBothRules(t *testing.T) {
+	repo, deleted := pruneWith(t, &config.AIConfig{ConversationRetentionDays: 7, MaxConversationsPerUser: 5})
+
+	if len(repo.cutoffs) != 1 || len(repo.keeps) != 1 {
+		t.Fatalf("cutoffs %v, keeps %v; want both rules applied", repo.cutoffs, repo.keeps)
+	}
+	if deleted != 5 {
+		t.Errorf("deleted = %d, want 5", deleted)
+	}
+}
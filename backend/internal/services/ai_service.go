@@ -1,18 +1,18 @@
 package services
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	slog "log/slog"
 
-	"strings"
-
 	"github.com/Srivathsav-max/lumen/backend/internal/config"
 	"github.com/Srivathsav-max/lumen/backend/internal/repository"
 )
@@ -29,29 +29,41 @@ type AIResponse struct {
 	Text    string          `json:"text"`
 	Blocks  json.RawMessage `json:"blocks,omitempty"`
 	Actions []AIAction      `json:"actions,omitempty"`
+	Usage   *AITokenUsage   `json:"usage,omitempty"`
 }
 
 type AIService interface {
 	GenerateContent(ctx context.Context, spec *AISpec) (*AIResponse, error)
+	// StreamContent streams a plain-text answer, calling onChunk for each piece
+	// of text as it arrives. It returns everything received so far, even when
+	// the stream ends early with an error.
+	StreamContent(ctx context.Context, spec *AISpec, onChunk func(text string) error) (*AIResponse, error)
+	// Ping checks that the model API is reachable and accepts our key
+	Ping(ctx context.Context) error
 }
 
 type geminiService struct {
-	httpClient *http.Client
-	apiKey     string
-	model      string
-	pageSvc    PageService
-	convRepo   repository.AIConversationRepository
-	msgRepo    repository.AIMessageRepository
-	logger     *slog.Logger
+	httpClient   *http.Client
+	streamClient *http.Client
+	apiKey       string
+	model        string
+	retry        retryPolicy
+	pageSvc      PageService
+	convRepo     repository.AIConversationRepository
+	msgRepo      repository.AIMessageRepository
+	logger       *slog.Logger
 }
 
 func NewAIService(cfg *config.AIConfig, pageSvc PageService, logger *slog.Logger) AIService {
 	return &geminiService{
 		httpClient: &http.Client{Timeout: 45 * time.Second},
-		apiKey:     cfg.GeminiAPIKey,
-		model:      cfg.GeminiModel,
-		pageSvc:    pageSvc,
-		logger:     logger,
+		// Streams stay open for as long as the model is generating
+		streamClient: &http.Client{Timeout: 5 * time.Minute},
+		apiKey:       cfg.GeminiAPIKey,
+		model:        cfg.GeminiModel,
+		retry:        defaultRetryPolicy(cfg.MaxRetries),
+		pageSvc:      pageSvc,
+		logger:       logger,
 	}
 }
 
@@ -84,6 +96,20 @@ type geminiResponse struct {
 			Parts []geminiPart `json:"parts"`
 		} `json:"content"`
 	} `json:"candidates"`
+	UsageMetadata *struct {
+		PromptTokenCount     int64 `json:"promptTokenCount"`
+		CandidatesTokenCount int64 `json:"candidatesTokenCount"`
+	} `json:"usageMetadata,omitempty"`
+}
+
+func (r *geminiResponse) tokenUsage() *AITokenUsage {
+	if r.UsageMetadata == nil {
+		return nil
+	}
+	return &AITokenUsage{
+		PromptTokens:     r.UsageMetadata.PromptTokenCount,
+		CompletionTokens: r.UsageMetadata.CandidatesTokenCount,
+	}
 }
 
 type geminiTool struct {
@@ -148,14 +174,16 @@ func (s *geminiService) GenerateContent(ctx context.Context, spec *AISpec) (*AIR
 	}
 
 	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:generateContent", s.model)
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
-	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("x-goog-api-key", s.apiKey)
-
-	httpResp, err := s.httpClient.Do(httpReq)
+	// Generation has no side effects, so transient failures are safe to retry
+	httpResp, err := doWithRetry(ctx, s.httpClient, s.retry, func(ctx context.Context) (*http.Request, error) {
+		httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+		if err != nil {
+			return nil, fmt.Errorf("create request: %w", err)
+		}
+		httpReq.Header.Set("Content-Type", "application/json")
+		httpReq.Header.Set("x-goog-api-key", s.apiKey)
+		return httpReq, nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("call gemini: %w", err)
 	}
@@ -171,7 +199,7 @@ func (s *geminiService) GenerateContent(ctx context.Context, spec *AISpec) (*AIR
 		return nil, fmt.Errorf("decode gemini: %w", err)
 	}
 
-	aiResp := &AIResponse{}
+	aiResp := &AIResponse{Usage: gResp.tokenUsage()}
 
 	if len(gResp.Candidates) > 0 {
 		for _, p := range gResp.Candidates[0].Content.Parts {
@@ -199,6 +227,111 @@ func (s *geminiService) GenerateContent(ctx context.Context, spec *AISpec) (*AIR
 	return aiResp, nil
 }
 
+// Ping fetches the model's metadata, which costs no tokens
+func (s *geminiService) Ping(ctx context.Context) error {
+	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s", s.model)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("x-goog-api-key", s.apiKey)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("call gemini: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("gemini error: status=%d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *geminiService) StreamContent(ctx context.Context, spec *AISpec, onChunk func(text string) error) (*AIResponse, error) {
+	system := "You are an assistant for a notes app. Answer in clear, concise plain text or simple markdown. Avoid HTML."
+	if spec.Format != "" {
+		system += "\nFormatting hint: " + spec.Format
+	}
+
+	reqBody := geminiRequest{
+		SystemInstruction: &geminiContent{Parts: []geminiPart{{Text: system}}},
+		Contents:          []geminiContent{{Role: "user", Parts: []geminiPart{{Text: spec.Query}}}},
+	}
+
+	payload, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("marshal gemini request: %w", err)
+	}
+
+	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:streamGenerateContent?alt=sse", s.model)
+	// Retrying is only safe before any text has been relayed, which is the
+	// case here since doWithRetry returns as soon as the headers arrive
+	httpResp, err := doWithRetry(ctx, s.streamClient, s.retry, func(ctx context.Context) (*http.Request, error) {
+		httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+		if err != nil {
+			return nil, fmt.Errorf("create request: %w", err)
+		}
+		httpReq.Header.Set("Content-Type", "application/json")
+		httpReq.Header.Set("x-goog-api-key", s.apiKey)
+		return httpReq, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("call gemini: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		body, _ := io.ReadAll(httpResp.Body)
+		return nil, fmt.Errorf("gemini error: status=%d body=%s", httpResp.StatusCode, string(body))
+	}
+
+	resp := &AIResponse{}
+	var text strings.Builder
+	scanner := bufio.NewScanner(httpResp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+
+		var chunk geminiResponse
+		if err := json.Unmarshal([]byte(strings.TrimSpace(strings.TrimPrefix(line, "data:"))), &chunk); err != nil {
+			resp.Text = text.String()
+			return resp, fmt.Errorf("decode gemini stream: %w", err)
+		}
+		// Every chunk carries the running totals, so the last one wins
+		if usage := chunk.tokenUsage(); usage != nil {
+			resp.Usage = usage
+		}
+		if len(chunk.Candidates) == 0 {
+			continue
+		}
+
+		for _, p := range chunk.Candidates[0].Content.Parts {
+			if p.Text == "" {
+				continue
+			}
+			text.WriteString(p.Text)
+			if err := onChunk(p.Text); err != nil {
+				resp.Text = text.String()
+				return resp, err
+			}
+		}
+	}
+	resp.Text = text.String()
+	if err := scanner.Err(); err != nil {
+		if ctx.Err() != nil {
+			return resp, ctx.Err()
+		}
+		return resp, fmt.Errorf("read gemini stream: %w", err)
+	}
+
+	return resp, nil
+}
+
 func (s *geminiService) executeTool(ctx context.Context, spec *AISpec, name string, args map[string]json.RawMessage) (interface{}, error) {
 	switch name {
 	case "insert_editorjs_blocks":
@@ -229,28 +362,26 @@ func (s *geminiService) executeTool(ctx context.Context, spec *AISpec, name stri
 		normalized := make([]map[string]interface{}, 0, len(payload.Blocks))
 		for _, blk := range payload.Blocks {
 			tval, _ := blk["type"].(string)
-			t := strings.ToLower(tval)
+			t := normalizeBlockType(tval)
 			data, _ := blk["data"].(map[string]interface{})
+			if data == nil {
+				data = map[string]interface{}{}
+			}
 			switch t {
-			case "heading", "header":
-				normalized = append(normalized, map[string]interface{}{"type": "heading", "data": data})
-			case "list", "ordered_list", "unordered_list":
+			case "list":
 				if _, ok := data["style"]; !ok {
 					data["style"] = "unordered"
 				}
 				normalized = append(normalized, map[string]interface{}{"type": "list", "data": data})
-			case "blockquote", "quote":
-				normalized = append(normalized, map[string]interface{}{"type": "quote", "data": data})
-			case "code", "codeblock":
-				normalized = append(normalized, map[string]interface{}{"type": "code", "data": data})
-			case "table":
-				normalized = append(normalized, map[string]interface{}{"type": "table", "data": data})
-			case "checklist":
-				normalized = append(normalized, map[string]interface{}{"type": "checklist", "data": data})
-			case "divider", "hr":
+			case "heading":
+				if _, ok := data["level"]; !ok {
+					data["level"] = 2
+				}
+				normalized = append(normalized, map[string]interface{}{"type": "heading", "data": data})
+			case "divider":
 				normalized = append(normalized, map[string]interface{}{"type": "divider", "data": map[string]interface{}{"type": "line"}})
-			case "image":
-				normalized = append(normalized, map[string]interface{}{"type": "image", "data": data})
+			case "quote", "code", "table", "checklist", "image":
+				normalized = append(normalized, map[string]interface{}{"type": t, "data": data})
 			default:
 				normalized = append(normalized, map[string]interface{}{"type": "paragraph", "data": data})
 			}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/config"
+	"github.com/Srivathsav-max/lumen/backend/internal/constants"
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+// AITokenUsage is the token count reported by the model for one request
+type AITokenUsage struct {
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+}
+
+// AIUsageService meters model usage and enforces monthly token quotas by role
+type AIUsageService interface {
+	// CheckQuota returns a quota exceeded error once the user has used up
+	// this month's allowance
+	CheckQuota(ctx context.Context, userID int64) error
+	RecordUsage(ctx context.Context, userID int64, usage *AITokenUsage) error
+	GetUsage(ctx context.Context, userID int64) (*AIUsageResponse, error)
+}
+
+type aiUsageService struct {
+	usageRepo repository.AIUsageRepository
+	roleRepo  repository.RoleRepository
+	quotas    map[string]int64
+	logger    *slog.Logger
+}
+
+func NewAIUsageService(usageRepo repository.AIUsageRepository, roleRepo repository.RoleRepository, cfg *config.AIConfig, logger *slog.Logger) AIUsageService {
+	return &aiUsageService{
+		usageRepo: usageRepo,
+		roleRepo:  roleRepo,
+		quotas:    cfg.MonthlyTokenQuotas,
+		logger:    logger,
+	}
+}
+
+func (s *aiUsageService) CheckQuota(ctx context.Context, userID int64) error {
+	usage, err := s.GetUsage(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !usage.Unlimited && usage.Remaining <= 0 {
+		return NewQuotaExceededError("Monthly AI token", usage.Limit)
+	}
+	return nil
+}
+
+func (s *aiUsageService) RecordUsage(ctx context.Context, userID int64, usage *AITokenUsage) error {
+	if usage == nil {
+		return nil
+	}
+	if err := s.usageRepo.AddUsage(ctx, userID, time.Now().UTC(), usage.PromptTokens, usage.CompletionTokens); err != nil {
+		s.logger.Error("Failed to record AI usage", "error", err, "user_id", userID)
+		return NewInternalError("Failed to record AI usage")
+	}
+	return nil
+}
+
+func (s *aiUsageService) GetUsage(ctx context.Context, userID int64) (*AIUsageResponse, error) {
+	limit, unlimited, err := s.quotaFor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	periodStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	totals, err := s.usageRepo.GetUsageSince(ctx, userID, periodStart)
+	if err != nil {
+		s.logger.Error("Failed to get AI usage", "error", err, "user_id", userID)
+		return nil, NewInternalError("Failed to get AI usage")
+	}
+
+	used := totals.PromptTokens + totals.CompletionTokens
+	resp := &AIUsageResponse{
+		PromptTokens:     totals.PromptTokens,
+		CompletionTokens: totals.CompletionTokens,
+		UsedTokens:       used,
+		RequestCount:     totals.RequestCount,
+		Unlimited:        unlimited,
+		PeriodStart:      periodStart,
+		PeriodEnd:        periodStart.AddDate(0, 1, 0),
+	}
+	if !unlimited {
+		resp.Limit = limit
+		resp.Remaining = limit - used
+		if resp.Remaining < 0 {
+			resp.Remaining = 0
+		}
+	}
+	return resp, nil
+}
+
+// quotaFor returns the most generous quota among the user's roles. Roles
+// without a configured quota are ignored; users with none of the configured
+// roles fall back to the free quota.
+func (s *aiUsageService) quotaFor(ctx context.Context, userID int64) (int64, bool, error) {
+	roles, err := s.roleRepo.GetUserRoles(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to get user roles", "error", err, "user_id", userID)
+		return 0, false, NewInternalError("Failed to get AI usage")
+	}
+
+	var limit int64
+	matched := false
+	for _, role := range roles {
+		quota, ok := s.quotas[role.Name]
+		if !ok {
+			continue
+		}
+		if quota == 0 {
+			return 0, true, nil
+		}
+		matched = true
+		if quota > limit {
+			limit = quota
+		}
+	}
+	if matched {
+		return limit, false, nil
+	}
+
+	quota, ok := s.quotas[constants.RoleFree]
+	if !ok || quota == 0 {
+		return 0, true, nil
+	}
+	return quota, false, nil
+}
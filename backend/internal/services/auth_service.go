@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"log/slog"
+	"net"
 	"strings"
 	"time"
 
@@ -23,6 +24,57 @@ const (
 	TokenTypeRefresh = "refresh"
 )
 
+// maxDeviceInfoLength bounds the device label stored with a refresh token
+const maxDeviceInfoLength = 512
+
+type deviceInfoContextKey struct{}
+
+// deviceInfo describes the client a refresh token is issued to
+type deviceInfo struct {
+	label     string
+	ipAddress string
+}
+
+// WithDeviceInfo attaches the client's User-Agent and IP address to ctx so
+// refresh tokens issued under it record which device the session belongs
+// to. The User-Agent is reduced to a label such as "Firefox on Windows".
+func WithDeviceInfo(ctx context.Context, userAgent, ipAddress string) context.Context {
+	label := describeUserAgent(userAgent)
+	if len(label) > maxDeviceInfoLength {
+		label = label[:maxDeviceInfoLength]
+	}
+	if ip := net.ParseIP(strings.TrimSpace(ipAddress)); ip != nil {
+		ipAddress = ip.String()
+	} else {
+		ipAddress = ""
+	}
+	return context.WithValue(ctx, deviceInfoContextKey{}, deviceInfo{label: label, ipAddress: ipAddress})
+}
+
+// withStoredDeviceInfo attaches an already described device, e.g. the one
+// recorded on a refresh token that is being rotated
+func withStoredDeviceInfo(ctx context.Context, label, ipAddress string) context.Context {
+	return context.WithValue(ctx, deviceInfoContextKey{}, deviceInfo{label: label, ipAddress: ipAddress})
+}
+
+func deviceInfoFromContext(ctx context.Context) deviceInfo {
+	info, _ := ctx.Value(deviceInfoContextKey{}).(deviceInfo)
+	return info
+}
+
+type fingerprintContextKey struct{}
+
+// WithFingerprint attaches the fingerprint cookie presented with a request so
+// ValidateAccessToken can check it against the token
+func WithFingerprint(ctx context.Context, fingerprint string) context.Context {
+	return context.WithValue(ctx, fingerprintContextKey{}, fingerprint)
+}
+
+func fingerprintFromContext(ctx context.Context) string {
+	fingerprint, _ := ctx.Value(fingerprintContextKey{}).(string)
+	return fingerprint
+}
+
 type AuthServiceImpl struct {
 	config               *config.Config
 	userRepo             repository.UserRepository
@@ -30,6 +82,9 @@ type AuthServiceImpl struct {
 	roleRepo             repository.RoleRepository
 	verificationTokenSvc VerificationTokenService
 	emailService         EmailService
+	deviceRepo           repository.UserDeviceRepository
+	notificationPrefs    repository.NotificationPreferenceRepository
+	passwordPolicy       *PasswordPolicy
 	logger               *slog.Logger
 }
 
@@ -40,6 +95,8 @@ func NewAuthService(
 	roleRepo repository.RoleRepository,
 	verificationTokenSvc VerificationTokenService,
 	emailService EmailService,
+	deviceRepo repository.UserDeviceRepository,
+	notificationPrefs repository.NotificationPreferenceRepository,
 	logger *slog.Logger,
 ) AuthService {
 	return &AuthServiceImpl{
@@ -49,11 +106,83 @@ func NewAuthService(
 		roleRepo:             roleRepo,
 		verificationTokenSvc: verificationTokenSvc,
 		emailService:         emailService,
+		deviceRepo:           deviceRepo,
+		notificationPrefs:    notificationPrefs,
+		passwordPolicy:       NewPasswordPolicy(config.PasswordPolicy),
 		logger:               logger,
 	}
 }
 
+// GenerateTokenPair signs the user in on a new session. Sign-ins from a
+// device the user has not used before are reported by email.
 func (s *AuthServiceImpl) GenerateTokenPair(ctx context.Context, userID int64) (*TokenPair, error) {
+	tokenPair, err := s.generateTokenPair(ctx, userID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if device := deviceInfoFromContext(ctx); device.label != "" {
+		go s.recordSignInDevice(userID, device)
+	}
+
+	return tokenPair, nil
+}
+
+// recordSignInDevice remembers the device a user signed in from and emails
+// them when it is new. The first device an account ever uses is recorded
+// silently, since that sign-in is usually the registration itself. Failures
+// are only logged: sign-in must not depend on it.
+func (s *AuthServiceImpl) recordSignInDevice(userID int64, device deviceInfo) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	hadDevices, err := s.deviceRepo.HasAny(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to check user devices", "user_id", userID, "error", err)
+		return
+	}
+
+	isNew, err := s.deviceRepo.Touch(ctx, userID, device.label, device.ipAddress)
+	if err != nil {
+		s.logger.Error("Failed to record user device", "user_id", userID, "error", err)
+		return
+	}
+	if !isNew || !hadDevices {
+		return
+	}
+
+	preferences, err := s.notificationPrefs.Get(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to get notification preferences", "user_id", userID, "error", err)
+		return
+	}
+	if !preferences.EmailNewDeviceLogin {
+		return
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to get user for new device email", "user_id", userID, "error", err)
+		return
+	}
+
+	err = s.emailService.SendNewDeviceLoginEmail(ctx, user.Email, &NewDeviceLoginEmail{
+		Username:    user.Username,
+		DeviceLabel: device.label,
+		IPAddress:   device.ipAddress,
+		SignedInAt:  time.Now(),
+	})
+	if err != nil {
+		s.logger.Error("Failed to send new device email", "user_id", userID, "error", err)
+		return
+	}
+
+	s.logger.Info("New device sign-in email sent", "user_id", userID, "device", device.label)
+}
+
+// generateTokenPair issues a token pair whose refresh token joins the given
+// token family; an empty familyID starts a new family
+func (s *AuthServiceImpl) generateTokenPair(ctx context.Context, userID int64, familyID string) (*TokenPair, error) {
 	s.logger.Info("Generating token pair", "user_id", userID)
 
 	user, err := s.userRepo.GetByID(ctx, userID)
@@ -73,7 +202,17 @@ func (s *AuthServiceImpl) GenerateTokenPair(ctx context.Context, userID int64) (
 		roleNames[i] = role.Name
 	}
 
-	accessToken, _, err := s.generateAccessToken(userID, user.Email, roleNames)
+	var fingerprint, fingerprintHash string
+	if s.fingerprintingEnabled() {
+		fingerprint, err = security.NewFingerprint()
+		if err != nil {
+			s.logger.Error("Failed to generate token fingerprint", "user_id", userID, "error", err)
+			return nil, errors.NewInternalError("Failed to generate token fingerprint").WithCause(err)
+		}
+		fingerprintHash = security.HashFingerprint(fingerprint, s.config.JWT.FingerprintSalt)
+	}
+
+	accessToken, _, err := s.generateAccessToken(userID, user.Email, roleNames, fingerprintHash)
 	if err != nil {
 		s.logger.Error("Failed to generate access token", "user_id", userID, "error", err)
 		return nil, errors.NewInternalError("Failed to generate access token").WithCause(err)
@@ -85,10 +224,13 @@ func (s *AuthServiceImpl) GenerateTokenPair(ctx context.Context, userID int64) (
 		return nil, errors.NewInternalError("Failed to generate refresh token").WithCause(err)
 	}
 
+	device := deviceInfoFromContext(ctx)
 	tokenEntity := &repository.Token{
 		UserID:     userID,
 		Token:      refreshToken,
-		DeviceInfo: "",
+		DeviceInfo: device.label,
+		IPAddress:  device.ipAddress,
+		FamilyID:   familyID,
 		ExpiresAt:  refreshExpiresAt,
 	}
 
@@ -100,9 +242,10 @@ func (s *AuthServiceImpl) GenerateTokenPair(ctx context.Context, userID int64) (
 	tokenPair := &TokenPair{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
-		ExpiresIn:    int64(s.config.JWT.AccessTokenDuration * 60),           // Convert minutes to seconds
+		ExpiresIn:    int64(s.config.JWT.AccessTokenDuration.Seconds()),
 		TokenType:    constants.BearerPrefix[:len(constants.BearerPrefix)-1], // Remove trailing space
 		IssuedAt:     time.Now().UTC(),
+		Fingerprint:  fingerprint,
 	}
 
 	s.logger.Info("Token pair generated successfully", "user_id", userID)
@@ -139,6 +282,11 @@ func (s *AuthServiceImpl) ValidateAccessToken(ctx context.Context, tokenString s
 		return nil, NewTokenExpiredError()
 	}
 
+	if s.fingerprintingEnabled() && !security.VerifyFingerprint(claims.Fingerprint, fingerprintFromContext(ctx), s.config.JWT.FingerprintSalt) {
+		s.logger.Warn("Token fingerprint mismatch", "user_id", claims.UserID, "token_id", claims.TokenID)
+		return nil, NewInvalidTokenError("fingerprint mismatch")
+	}
+
 	tokenClaims := &TokenClaims{
 		UserID:    claims.UserID,
 		Email:     claims.Email,
@@ -161,18 +309,67 @@ func (s *AuthServiceImpl) RefreshTokens(ctx context.Context, refreshToken string
 		return nil, NewInvalidRefreshTokenError()
 	}
 
+	if tokenEntity.RotatedAt != nil {
+		return s.refreshRotatedToken(ctx, tokenEntity)
+	}
+
 	if time.Now().UTC().After(tokenEntity.ExpiresAt) {
 		s.logger.Debug("Refresh token expired", "expires_at", tokenEntity.ExpiresAt)
 		s.tokenRepo.RevokeToken(ctx, refreshToken)
 		return nil, NewTokenExpiredError()
 	}
 
-	if err := s.tokenRepo.RevokeToken(ctx, refreshToken); err != nil {
-		s.logger.Error("Failed to revoke old refresh token", "error", err)
+	rotated, err := s.tokenRepo.MarkRotated(ctx, refreshToken)
+	if err != nil {
+		s.logger.Error("Failed to rotate old refresh token", "error", err)
 		return nil, errors.NewInternalError("Failed to revoke old token").WithCause(err)
 	}
+	if !rotated {
+		// Another request rotated the same token first; re-read it to learn when
+		rotatedEntity, err := s.tokenRepo.GetByToken(ctx, refreshToken)
+		if err != nil || rotatedEntity.RotatedAt == nil {
+			return nil, s.handleRefreshTokenReuse(ctx, tokenEntity)
+		}
+		return s.refreshRotatedToken(ctx, rotatedEntity)
+	}
+
+	return s.issueRefreshedTokens(ctx, tokenEntity)
+}
+
+// refreshRotatedToken handles a refresh token that was already exchanged.
+// Two requests from the same client can race with one token, so the latest
+// rotated token of a family is still honoured for a short grace period.
+// Anything older means a stolen copy is being replayed.
+func (s *AuthServiceImpl) refreshRotatedToken(ctx context.Context, tokenEntity *repository.Token) (*TokenPair, error) {
+	if time.Since(*tokenEntity.RotatedAt) > constants.RefreshTokenReuseGrace {
+		return nil, s.handleRefreshTokenReuse(ctx, tokenEntity)
+	}
+
+	superseded, err := s.tokenRepo.HasRotatedSuccessor(ctx, tokenEntity.FamilyID, tokenEntity.ID)
+	if err != nil {
+		s.logger.Error("Failed to check refresh token family", "family_id", tokenEntity.FamilyID, "error", err)
+		return nil, errors.NewInternalError("Failed to refresh tokens").WithCause(err)
+	}
+	if superseded {
+		return nil, s.handleRefreshTokenReuse(ctx, tokenEntity)
+	}
+
+	if time.Now().UTC().After(tokenEntity.ExpiresAt) {
+		return nil, NewTokenExpiredError()
+	}
+
+	s.logger.Info("Refresh token reused within grace period", "user_id", tokenEntity.UserID, "family_id", tokenEntity.FamilyID)
+	return s.issueRefreshedTokens(ctx, tokenEntity)
+}
+
+// issueRefreshedTokens issues the pair that replaces a rotated refresh token
+func (s *AuthServiceImpl) issueRefreshedTokens(ctx context.Context, tokenEntity *repository.Token) (*TokenPair, error) {
+	// A rotated token stays on the same device when the caller did not say otherwise
+	if deviceInfoFromContext(ctx) == (deviceInfo{}) {
+		ctx = withStoredDeviceInfo(ctx, tokenEntity.DeviceInfo, tokenEntity.IPAddress)
+	}
 
-	newTokenPair, err := s.GenerateTokenPair(ctx, tokenEntity.UserID)
+	newTokenPair, err := s.generateTokenPair(ctx, tokenEntity.UserID, tokenEntity.FamilyID)
 	if err != nil {
 		s.logger.Error("Failed to generate new token pair", "user_id", tokenEntity.UserID, "error", err)
 		return nil, err
@@ -182,6 +379,23 @@ func (s *AuthServiceImpl) RefreshTokens(ctx context.Context, refreshToken string
 	return newTokenPair, nil
 }
 
+// handleRefreshTokenReuse signs the user out everywhere after a rotated
+// refresh token was replayed
+func (s *AuthServiceImpl) handleRefreshTokenReuse(ctx context.Context, tokenEntity *repository.Token) error {
+	s.logger.Warn("Refresh token reuse detected",
+		"user_id", tokenEntity.UserID,
+		"token_id", tokenEntity.ID,
+		"family_id", tokenEntity.FamilyID,
+	)
+
+	if err := s.tokenRepo.RevokeAllUserTokens(ctx, tokenEntity.UserID, TokenTypeRefresh); err != nil {
+		s.logger.Error("Failed to revoke tokens after refresh token reuse", "user_id", tokenEntity.UserID, "error", err)
+		return errors.NewInternalError("Failed to revoke tokens").WithCause(err)
+	}
+
+	return NewTokenReuseDetectedError()
+}
+
 func (s *AuthServiceImpl) RevokeToken(ctx context.Context, token string) error {
 	s.logger.Info("Revoking refresh token")
 
@@ -209,6 +423,10 @@ func (s *AuthServiceImpl) ChangePassword(ctx context.Context, userID int64, req
 		return NewPasswordMismatchError()
 	}
 
+	if err := s.passwordPolicy.ValidatePassword(req.NewPassword); err != nil {
+		return err
+	}
+
 	hashedPassword, err := utils.HashPassword(req.NewPassword)
 	if err != nil {
 		s.logger.Error("Failed to hash new password", "user_id", userID, "error", err)
@@ -239,6 +457,53 @@ func (s *AuthServiceImpl) ChangePassword(ctx context.Context, userID int64, req
 	return nil
 }
 
+// RequestPasswordChangeOTP emails the user a one-time code that
+// ChangePasswordWithOTP requires. Requests are limited per user over a
+// sliding window counted from the stored codes.
+func (s *AuthServiceImpl) RequestPasswordChangeOTP(ctx context.Context, userID int64) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to get user", "user_id", userID, "error", err)
+		return NewUserNotFoundError(fmt.Sprintf("ID: %d", userID))
+	}
+
+	since := time.Now().Add(-constants.PasswordChangeOTPWindow)
+	issued, err := s.verificationTokenSvc.CountIssuedSince(ctx, userID, TokenTypePasswordChange, since)
+	if err != nil {
+		s.logger.Error("Failed to count password change codes", "user_id", userID, "error", err)
+		return err
+	}
+	if issued >= constants.PasswordChangeOTPMaxRequests {
+		s.logger.Warn("Password change code rate limit exceeded", "user_id", userID)
+		return NewRateLimitExceededError(fmt.Sprintf("%d codes per %s", constants.PasswordChangeOTPMaxRequests, constants.PasswordChangeOTPWindow))
+	}
+
+	otp, err := s.verificationTokenSvc.GenerateOTP(ctx, userID, TokenTypePasswordChange, constants.PasswordChangeOTPLength, constants.PasswordChangeOTPTTL)
+	if err != nil {
+		s.logger.Error("Failed to generate password change code", "user_id", userID, "error", err)
+		return err
+	}
+
+	if err := s.emailService.SendPasswordChangeOTPEmail(ctx, user.Email, otp); err != nil {
+		s.logger.Error("Failed to send password change code", "user_id", userID, "error", err)
+		return err
+	}
+
+	s.logger.Info("Password change code sent", "user_id", userID)
+	return nil
+}
+
+// ChangePasswordWithOTP redeems the code before changing the password. The
+// code is spent even when the password change itself is rejected.
+func (s *AuthServiceImpl) ChangePasswordWithOTP(ctx context.Context, userID int64, req *ChangePasswordRequest, otp string) error {
+	if err := s.verificationTokenSvc.ConsumeOTP(ctx, userID, TokenTypePasswordChange, otp); err != nil {
+		s.logger.Debug("Password change code rejected", "user_id", userID, "error", err)
+		return err
+	}
+
+	return s.ChangePassword(ctx, userID, req)
+}
+
 func (s *AuthServiceImpl) InitiatePasswordReset(ctx context.Context, email string) error {
 	s.logger.Info("Initiating password reset", "email", email)
 
@@ -281,9 +546,8 @@ func (s *AuthServiceImpl) ResetPassword(ctx context.Context, req *ResetPasswordR
 	}
 
 	// Validate password strength
-	if len(req.NewPassword) < constants.MinPasswordLength {
-		return errors.NewValidationError("Password too short",
-			fmt.Sprintf("Password must be at least %d characters", constants.MinPasswordLength))
+	if err := s.passwordPolicy.ValidatePassword(req.NewPassword); err != nil {
+		return err
 	}
 
 	// Validate the reset token
@@ -293,6 +557,17 @@ func (s *AuthServiceImpl) ResetPassword(ctx context.Context, req *ResetPasswordR
 		return errors.NewValidationError("Invalid or expired reset token", "")
 	}
 
+	// Consume the token before touching the password, so of two concurrent
+	// resets with the same token only one gets past this point
+	if err := s.verificationTokenSvc.MarkTokenAsUsed(ctx, tokenData.ID); err != nil {
+		if IsValidationError(err) {
+			s.logger.Debug("Password reset token already used", "token_id", tokenData.ID)
+			return errors.NewValidationError("Invalid or expired reset token", "")
+		}
+		s.logger.Error("Failed to mark reset token as used", "token_id", tokenData.ID, "error", err)
+		return errors.NewInternalError("Failed to reset password").WithCause(err)
+	}
+
 	// Get user
 	user, err := s.userRepo.GetByID(ctx, tokenData.UserID)
 	if err != nil {
@@ -314,12 +589,6 @@ func (s *AuthServiceImpl) ResetPassword(ctx context.Context, req *ResetPasswordR
 		return errors.NewInternalError("Failed to update password").WithCause(err)
 	}
 
-	// Mark token as used
-	if err := s.verificationTokenSvc.MarkTokenAsUsed(ctx, tokenData.ID); err != nil {
-		s.logger.Error("Failed to mark reset token as used", "token_id", tokenData.ID, "error", err)
-		// Don't fail the request since password was already updated
-	}
-
 	// Revoke all existing refresh tokens for security
 	if err := s.tokenRepo.RevokeAllUserTokens(ctx, tokenData.UserID, TokenTypeRefresh); err != nil {
 		s.logger.Error("Failed to revoke user tokens after password reset", "user_id", tokenData.UserID, "error", err)
@@ -368,6 +637,68 @@ func (s *AuthServiceImpl) RevokeAllUserTokens(ctx context.Context, userID int64)
 	return nil
 }
 
+// ListSessions returns the user's unexpired refresh tokens, newest first. The
+// session whose token matches currentRefreshToken is flagged as current.
+func (s *AuthServiceImpl) ListSessions(ctx context.Context, userID int64, currentRefreshToken string) ([]SessionInfo, error) {
+	tokens, err := s.tokenRepo.GetByUserID(ctx, userID, TokenTypeRefresh)
+	if err != nil {
+		s.logger.Error("Failed to list user sessions", "user_id", userID, "error", err)
+		return nil, errors.NewInternalError("Failed to list sessions").WithCause(err)
+	}
+
+	now := time.Now().UTC()
+	sessions := make([]SessionInfo, 0, len(tokens))
+	for _, token := range tokens {
+		if now.After(token.ExpiresAt) {
+			continue
+		}
+		sessions = append(sessions, SessionInfo{
+			ID:         token.ID,
+			DeviceInfo: token.DeviceInfo,
+			IPAddress:  token.IPAddress,
+			CreatedAt:  token.CreatedAt,
+			ExpiresAt:  token.ExpiresAt,
+			Current:    currentRefreshToken != "" && token.Token == currentRefreshToken,
+		})
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession revokes a single refresh token belonging to the user
+func (s *AuthServiceImpl) RevokeSession(ctx context.Context, userID int64, sessionID int64) error {
+	s.logger.Info("Revoking session", "user_id", userID, "session_id", sessionID)
+
+	tokens, err := s.tokenRepo.GetByUserID(ctx, userID, TokenTypeRefresh)
+	if err != nil {
+		s.logger.Error("Failed to list user sessions", "user_id", userID, "error", err)
+		return errors.NewInternalError("Failed to revoke session").WithCause(err)
+	}
+
+	// Looking the session up among the user's own tokens keeps users from
+	// revoking each other's sessions by guessing IDs
+	var session *repository.Token
+	for _, token := range tokens {
+		if token.ID == sessionID {
+			session = token
+			break
+		}
+	}
+	if session == nil {
+		return errors.NewNotFoundError("Session")
+	}
+
+	// Rotated ancestors of the session go too, so replaying one of them is
+	// simply rejected instead of being treated as reuse
+	if err := s.tokenRepo.RevokeTokenFamily(ctx, session.FamilyID); err != nil {
+		s.logger.Error("Failed to revoke session", "user_id", userID, "session_id", sessionID, "error", err)
+		return errors.NewInternalError("Failed to revoke session").WithCause(err)
+	}
+
+	s.logger.Info("Session revoked successfully", "user_id", userID, "session_id", sessionID)
+	return nil
+}
+
 func (s *AuthServiceImpl) validateTokenFormat(tokenString string) error {
 	if tokenString == "" {
 		return NewInvalidTokenError("token is empty")
@@ -381,8 +712,14 @@ func (s *AuthServiceImpl) validateTokenFormat(tokenString string) error {
 	return nil
 }
 
-func (s *AuthServiceImpl) generateAccessToken(userID int64, email string, roles []string) (string, time.Time, error) {
-	expiresAt := time.Now().UTC().Add(time.Duration(s.config.JWT.AccessTokenDuration) * time.Minute)
+// fingerprintingEnabled mirrors the security config, which binds tokens to a
+// fingerprint cookie everywhere except development
+func (s *AuthServiceImpl) fingerprintingEnabled() bool {
+	return !s.config.IsDevelopment()
+}
+
+func (s *AuthServiceImpl) generateAccessToken(userID int64, email string, roles []string, fingerprintHash string) (string, time.Time, error) {
+	expiresAt := time.Now().UTC().Add(s.config.JWT.AccessTokenDuration)
 	issuedAt := time.Now().UTC()
 
 	tokenID, err := s.generateSecureToken(16)
@@ -390,7 +727,13 @@ func (s *AuthServiceImpl) generateAccessToken(userID int64, email string, roles
 		return "", time.Time{}, fmt.Errorf("failed to generate token ID: %w", err)
 	}
 
-	sessionID := fmt.Sprintf("session_%d_%d", userID, time.Now().Unix())
+	// Every access token starts a new session, so CSRF tokens bound to the
+	// old one stop working after sign-in, refresh or a change of roles
+	sessionSuffix, err := s.generateSecureToken(16)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to generate session ID: %w", err)
+	}
+	sessionID := "session_" + sessionSuffix
 
 	claims := &security.SecureJWTClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
@@ -407,6 +750,7 @@ func (s *AuthServiceImpl) generateAccessToken(userID int64, email string, roles
 		Roles:       roles,
 		TokenID:     tokenID,
 		TokenType:   TokenTypeAccess,
+		Fingerprint: fingerprintHash,
 		SessionID:   sessionID,
 		DeviceID:    "",
 		LoginTime:   time.Now().Unix(),
@@ -429,7 +773,7 @@ func (s *AuthServiceImpl) generateRefreshToken() (string, time.Time, error) {
 		return "", time.Time{}, err
 	}
 
-	expiresAt := time.Now().UTC().Add(time.Duration(s.config.JWT.RefreshTokenDuration) * time.Hour)
+	expiresAt := time.Now().UTC().Add(s.config.JWT.RefreshTokenDuration)
 	return token, expiresAt, nil
 }
 
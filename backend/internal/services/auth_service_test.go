@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/config"
+	"github.com/Srivathsav-max/lumen/backend/internal/constants"
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+	"github.com/Srivathsav-max/lumen/backend/utils"
+)
+
+type authFixture struct {
+	service AuthService
+	tokens  *fakeTokenRepo
+	userID  int64
+}
+
+func newAuthFixture(t *testing.T) *authFixture {
+	t.Helper()
+	users := newFakeUserRepo()
+	user := &repository.User{Username: "alice", Email: "alice@example.test", EmailVerified: true}
+	if err := users.Create(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{Env: constants.EnvDevelopment},
+		JWT: config.JWTConfig{
+			Secret:               "test-secret-that-is-at-least-32-bytes",
+			AccessTokenDuration:  15 * time.Minute,
+			RefreshTokenDuration: time.Hour,
+		},
+	}
+	tokens := &fakeTokenRepo{}
+	return &authFixture{
+		service: NewAuthService(cfg, users, tokens, newFakeRoleRepo(), nil, nil, nil, nil, discardLogger()),
+		tokens:  tokens,
+		userID:  user.ID,
+	}
+}
+
+// signIn returns a refresh token from a fresh session, without recording the device
+func (f *authFixture) signIn(t *testing.T) string {
+	t.Helper()
+	pair, err := f.service.GenerateTokenPair(context.Background(), f.userID)
+	if err != nil {
+		t.Fatalf("GenerateTokenPair() error = %v", err)
+	}
+	return pair.RefreshToken
+}
+
+func (f *authFixture) refresh(t *testing.T, token string) string {
+	t.Helper()
+	pair, err := f.service.RefreshTokens(context.Background(), token)
+	if err != nil {
+		t.Fatalf("RefreshTokens() error = %v", err)
+	}
+	return pair.RefreshToken
+}
+
+func TestRefreshTokensRotates(t *testing.T) {
+	f := newAuthFixture(t)
+	first := f.signIn(t)
+
+	second := f.refresh(t, first)
+	if second == first {
+		t.Fatal("refresh returned the same refresh token")
+	}
+	f.refresh(t, second)
+
+	if revoked := f.tokens.revokedUsers(); len(revoked) != 0 {
+		t.Errorf("sequential refreshes revoked sessions of %v", revoked)
+	}
+}
+
+func TestConcurrentRefreshesWithOneTokenSucceed(t *testing.T) {
+	f := newAuthFixture(t)
+	token := f.signIn(t)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := f.service.RefreshTokens(context.Background(), token)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("concurrent RefreshTokens() error = %v", err)
+		}
+	}
+	if revoked := f.tokens.revokedUsers(); len(revoked) != 0 {
+		t.Errorf("concurrent refreshes revoked sessions of %v", revoked)
+	}
+}
+
+func TestStolenRefreshTokenReplayedAfterGraceRevokesSessions(t *testing.T) {
+	f := newAuthFixture(t)
+	stolen := f.signIn(t)
+	current := f.refresh(t, stolen)
+	f.tokens.rotatedAgo(stolen, constants.RefreshTokenReuseGrace+time.Second)
+
+	if _, err := f.service.RefreshTokens(context.Background(), stolen); !IsAuthenticationError(err) {
+		t.Fatalf("replayed token: error = %v, want an authentication error", err)
+	}
+	if revoked := f.tokens.revokedUsers(); len(revoked) != 1 || revoked[0] != f.userID {
+		t.Fatalf("revoked users = %v, want [%d]", revoked, f.userID)
+	}
+	if _, err := f.service.RefreshTokens(context.Background(), current); err == nil {
+		t.Error("legitimate token still works after reuse was detected")
+	}
+}
+
+func TestStolenRefreshTokenReplayedAfterSuccessorRotatedRevokesSessions(t *testing.T) {
+	f := newAuthFixture(t)
+	stolen := f.signIn(t)
+	f.refresh(t, f.refresh(t, stolen))
+
+	// Still inside the grace period, but the token is two rotations old
+	if _, err := f.service.RefreshTokens(context.Background(), stolen); !IsAuthenticationError(err) {
+		t.Fatalf("replayed token: error = %v, want an authentication error", err)
+	}
+	if revoked := f.tokens.revokedUsers(); len(revoked) != 1 {
+		t.Errorf("revoked users = %v, want the token owner", revoked)
+	}
+}
+
+func TestTokenPairLifetimesFollowConfig(t *testing.T) {
+	f := newAuthFixture(t)
+	pair, err := f.service.GenerateTokenPair(context.Background(), f.userID)
+	if err != nil {
+		t.Fatalf("GenerateTokenPair() error = %v", err)
+	}
+
+	if pair.ExpiresIn != 15*60 {
+		t.Errorf("ExpiresIn = %d, want %d seconds", pair.ExpiresIn, 15*60)
+	}
+
+	claims := jwt.MapClaims{}
+	if _, _, err := new(jwt.Parser).ParseUnverified(pair.AccessToken, claims); err != nil {
+		t.Fatalf("parse access token: %v", err)
+	}
+	exp, _ := claims["exp"].(float64)
+	iat, _ := claims["iat"].(float64)
+	if int64(exp-iat) != pair.ExpiresIn {
+		t.Errorf("access token lives %d seconds, want expires_in (%d)", int64(exp-iat), pair.ExpiresIn)
+	}
+
+	stored := f.tokens.tokens[pair.RefreshToken]
+	if stored == nil {
+		t.Fatal("refresh token was not stored")
+	}
+	if lifetime := time.Until(stored.ExpiresAt); lifetime < time.Hour-time.Minute || lifetime > time.Hour {
+		t.Errorf("refresh token expires in %s, want about 1h", lifetime)
+	}
+}
+
+func TestResetPasswordConsumesTheTokenOnce(t *testing.T) {
+	ctx := context.Background()
+	users := newFakeUserRepo()
+	user := &repository.User{Username: "alice", Email: "alice@example.test", EmailVerified: true}
+	if err := users.Create(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+
+	verificationTokens := NewVerificationTokenService(newFakeVerificationTokenRepo())
+	token, err := verificationTokens.GenerateToken(ctx, user.ID, TokenTypePasswordReset, 1)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+
+	cfg := &config.Config{Server: config.ServerConfig{Env: constants.EnvDevelopment}}
+	service := NewAuthService(cfg, users, &fakeTokenRepo{}, newFakeRoleRepo(), verificationTokens, newFakeEmailService(), nil, nil, discardLogger())
+
+	passwords := []string{"Correct-Horse-1", "Battery-Staple-2"}
+	errs := make([]error, len(passwords))
+	var wg sync.WaitGroup
+	for i, password := range passwords {
+		wg.Add(1)
+		go func(i int, password string) {
+			defer wg.Done()
+			errs[i] = service.ResetPassword(ctx, &ResetPasswordRequest{Token: token, NewPassword: password, ConfirmPassword: password})
+		}(i, password)
+	}
+	wg.Wait()
+
+	succeeded := -1
+	for i, err := range errs {
+		switch {
+		case err == nil && succeeded < 0:
+			succeeded = i
+		case err == nil:
+			t.Fatal("both concurrent resets with one token succeeded")
+		case !IsValidationError(err):
+			t.Errorf("ResetPassword() error = %v, want a validation error for the losing request", err)
+		}
+	}
+	if succeeded < 0 {
+		t.Fatalf("no reset succeeded: %v", errs)
+	}
+
+	stored, _ := users.GetByID(ctx, user.ID)
+	if !utils.CheckPassword(passwords[succeeded], stored.PasswordHash) {
+		t.Error("stored password is not the one from the successful reset")
+	}
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/errors"
+)
+
+// BatchItemError describes why a single item of a batch operation failed
+type BatchItemError struct {
+	ID        string `json:"id"`
+	ErrorCode string `json:"error_code"`
+	Message   string `json:"message"`
+}
+
+// BatchResult is the response shape for bulk endpoints. A batch never fails
+// as a whole because of a single item: handlers return 200 and the body
+// reports which items succeeded and which failed.
+type BatchResult[T any] struct {
+	Succeeded    []T              `json:"succeeded"`
+	Failed       []BatchItemError `json:"failed"`
+	Total        int              `json:"total"`
+	SuccessCount int              `json:"success_count"`
+}
+
+// NewBatchResult creates an empty result for a batch of the given size
+func NewBatchResult[T any](total int) *BatchResult[T] {
+	return &BatchResult[T]{
+		Succeeded: make([]T, 0, total),
+		Failed:    make([]BatchItemError, 0),
+		Total:     total,
+	}
+}
+
+// AddSuccess records a successfully processed item
+func (r *BatchResult[T]) AddSuccess(item T) {
+	r.Succeeded = append(r.Succeeded, item)
+	r.SuccessCount = len(r.Succeeded)
+}
+
+// AddFailure records a failed item, taking the error code from AppErrors
+func (r *BatchResult[T]) AddFailure(id string, err error) {
+	itemErr := BatchItemError{
+		ID:        id,
+		ErrorCode: string(errors.InternalError),
+		Message:   "Internal server error",
+	}
+
+	if appErr, ok := errors.AsAppError(err); ok {
+		itemErr.ErrorCode = string(appErr.Code)
+		itemErr.Message = appErr.Message
+		if details, ok := appErr.Details.(string); ok && details != "" {
+			itemErr.Message = fmt.Sprintf("%s: %s", appErr.Message, details)
+		}
+	}
+
+	r.Failed = append(r.Failed, itemErr)
+}
+
+// HasFailures reports whether any item in the batch failed
+func (r *BatchResult[T]) HasFailures() bool {
+	return len(r.Failed) > 0
+}
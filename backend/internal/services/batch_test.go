@@ -0,0 +1,76 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	apperrors "github.com/Srivathsav-max/lumen/backend/internal/errors"
+)
+
+func TestBatchResult(t *testing.T) {
+	tests := []struct {
+		name        string
+		succeed     []string
+		fail        map[string]error
+		wantSuccess int
+		wantFailed  int
+	}{
+		{name: "all succeed", succeed: []string{"a", "b", "c"}, wantSuccess: 3},
+		{
+			name:       "all fail",
+			fail:       map[string]error{"a": NewNotFoundError("Page"), "b": NewForbiddenError("Access denied")},
+			wantFailed: 2,
+		},
+		{
+			name:        "mixed",
+			succeed:     []string{"a"},
+			fail:        map[string]error{"b": NewForbiddenError("Access denied")},
+			wantSuccess: 1,
+			wantFailed:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewBatchResult[string](len(tt.succeed) + len(tt.fail))
+			for _, id := range tt.succeed {
+				result.AddSuccess(id)
+			}
+			for id, err := range tt.fail {
+				result.AddFailure(id, err)
+			}
+
+			if result.SuccessCount != tt.wantSuccess || len(result.Succeeded) != tt.wantSuccess {
+				t.Errorf("SuccessCount = %d, Succeeded = %v; want %d", result.SuccessCount, result.Succeeded, tt.wantSuccess)
+			}
+			if len(result.Failed) != tt.wantFailed {
+				t.Errorf("Failed = %v, want %d items", result.Failed, tt.wantFailed)
+			}
+			if result.HasFailures() != (tt.wantFailed > 0) {
+				t.Errorf("HasFailures() = %v", result.HasFailures())
+			}
+			if result.Total != tt.wantSuccess+tt.wantFailed {
+				t.Errorf("Total = %d, want %d", result.Total, tt.wantSuccess+tt.wantFailed)
+			}
+		})
+	}
+}
+
+func TestBatchResultFailureCodes(t *testing.T) {
+	result := NewBatchResult[string](3)
+	result.AddFailure("missing", NewNotFoundError("Page"))
+	result.AddFailure("invalid", apperrors.NewValidationError("Bad request", "title is required"))
+	result.AddFailure("broken", errors.New("connection reset"))
+
+	want := []BatchItemError{
+		{ID: "missing", ErrorCode: string(apperrors.NotFoundError), Message: "Page not found"},
+		{ID: "invalid", ErrorCode: string(apperrors.ValidationError), Message: "Bad request: title is required"},
+		// Errors that are not AppErrors do not leak their text
+		{ID: "broken", ErrorCode: string(apperrors.InternalError), Message: "Internal server error"},
+	}
+	for i, item := range result.Failed {
+		if item != want[i] {
+			t.Errorf("Failed[%d] = %+v, want %+v", i, item, want[i])
+		}
+	}
+}
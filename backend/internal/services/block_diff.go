@@ -0,0 +1,139 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+// blockChangeSet is the minimal set of writes needed to turn the stored blocks
+// of a page into the blocks of an incoming EditorJS document
+type blockChangeSet struct {
+	Create []*repository.Block
+	Update []*repository.Block
+	Delete []string
+	// Order holds the final position of every block on the page. It is only
+	// populated when at least one block moved without its data changing.
+	Order map[string]int
+}
+
+// resolveBlockID maps the client-supplied EditorJS id onto a stable block ID.
+// Blocks loaded from the server carry their UUID as the EditorJS id. Blocks
+// created in the editor get a short random id, which is hashed into a UUID
+// scoped to the page so the same block resolves to the same ID on every save
+// until the editor is reloaded with the server IDs. UUIDs that do not belong
+// to this page are hashed the same way so they can never collide with
+// another page's blocks.
+func resolveBlockID(pageID, clientID string, existingIDs map[string]bool) string {
+	if clientID == "" {
+		return uuid.New().String()
+	}
+	if parsed, err := uuid.Parse(clientID); err == nil && existingIDs[parsed.String()] {
+		return parsed.String()
+	}
+
+	namespace, err := uuid.Parse(pageID)
+	if err != nil {
+		namespace = uuid.NameSpaceOID
+	}
+	return uuid.NewSHA1(namespace, []byte(clientID)).String()
+}
+
+// blockDataEqual compares two JSON documents ignoring insignificant whitespace
+func blockDataEqual(a, b json.RawMessage) bool {
+	var left, right bytes.Buffer
+	if err := json.Compact(&left, a); err != nil {
+		return bytes.Equal(a, b)
+	}
+	if err := json.Compact(&right, b); err != nil {
+		return bytes.Equal(a, b)
+	}
+	return bytes.Equal(left.Bytes(), right.Bytes())
+}
+
+// diffBlocks compares the incoming blocks (already in their final order, with
+// positions assigned) against the existing blocks of the page. Incoming
+// blocks are matched to existing ones by ID; unchanged blocks are left out of
+// the change set entirely.
+func diffBlocks(existing []*repository.Block, incoming []*repository.Block) *blockChangeSet {
+	changes := &blockChangeSet{}
+
+	existingByID := make(map[string]*repository.Block, len(existing))
+	for _, block := range existing {
+		existingByID[block.ID] = block
+	}
+
+	seen := make(map[string]bool, len(incoming))
+	moved := false
+
+	for _, block := range incoming {
+		current, ok := existingByID[block.ID]
+		if !ok {
+			changes.Create = append(changes.Create, block)
+			seen[block.ID] = true
+			continue
+		}
+		seen[block.ID] = true
+
+		if current.BlockType != block.BlockType || !blockDataEqual(current.BlockData, block.BlockData) {
+			block.ParentBlockID = current.ParentBlockID
+			changes.Update = append(changes.Update, block)
+			continue
+		}
+
+		if current.Position != block.Position {
+			moved = true
+		}
+	}
+
+	for _, block := range existing {
+		if !seen[block.ID] {
+			changes.Delete = append(changes.Delete, block.ID)
+		}
+	}
+
+	if moved {
+		changes.Order = make(map[string]int, len(incoming))
+		for _, block := range incoming {
+			changes.Order[block.ID] = block.Position
+		}
+	}
+
+	return changes
+}
+
+const noChangesSummary = "no changes"
+
+// summarizeBlockChanges describes a change set for the version history, e.g.
+// "3 blocks added, 1 block modified". It counts the writes the save made,
+// so blocks are matched by their resolved IDs rather than the EditorJS ids the
+// client sent. Moves alone are not counted.
+func summarizeBlockChanges(changes *blockChangeSet) string {
+	pluralize := func(count int, verb string) string {
+		if count == 1 {
+			return fmt.Sprintf("1 block %s", verb)
+		}
+		return fmt.Sprintf("%d blocks %s", count, verb)
+	}
+
+	var parts []string
+	if added := len(changes.Create); added > 0 {
+		parts = append(parts, pluralize(added, "added"))
+	}
+	if modified := len(changes.Update); modified > 0 {
+		parts = append(parts, pluralize(modified, "modified"))
+	}
+	if removed := len(changes.Delete); removed > 0 {
+		parts = append(parts, pluralize(removed, "removed"))
+	}
+
+	if len(parts) == 0 {
+		return noChangesSummary
+	}
+	return strings.Join(parts, ", ")
+}
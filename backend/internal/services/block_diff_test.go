@@ -0,0 +1,201 @@
+package services
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+const diffTestPageID = "6f1c8a52-93f4-4f0e-9d2e-1b7a64f0c001"
+
+type testEditorBlock struct {
+	id   string
+	text string
+}
+
+// incomingBlocks resolves EditorJS blocks the way SavePageContent does
+func incomingBlocks(existing []*repository.Block, blocks ...testEditorBlock) []*repository.Block {
+	existingIDs := make(map[string]bool, len(existing))
+	for _, block := range existing {
+		existingIDs[block.ID] = true
+	}
+
+	incoming := make([]*repository.Block, len(blocks))
+	for i, block := range blocks {
+		data, _ := json.Marshal(map[string]string{"text": block.text})
+		incoming[i] = &repository.Block{
+			ID:        resolveBlockID(diffTestPageID, block.id, existingIDs),
+			PageID:    diffTestPageID,
+			BlockType: "paragraph",
+			BlockData: data,
+			Position:  i,
+		}
+	}
+	return incoming
+}
+
+// storedBlocks returns the blocks a save of blocks would leave on the page
+func storedBlocks(blocks ...testEditorBlock) []*repository.Block {
+	return incomingBlocks(nil, blocks...)
+}
+
+func TestSummarizeBlockChanges(t *testing.T) {
+	existing := storedBlocks(testEditorBlock{"a1", "one"}, testEditorBlock{"b2", "two"}, testEditorBlock{"c3", "three"})
+
+	tests := []struct {
+		name     string
+		incoming []testEditorBlock
+		want     string
+	}{
+		{
+			name:     "unchanged",
+			incoming: []testEditorBlock{{"a1", "one"}, {"b2", "two"}, {"c3", "three"}},
+			want:     noChangesSummary,
+		},
+		{
+			name:     "added",
+			incoming: []testEditorBlock{{"a1", "one"}, {"b2", "two"}, {"c3", "three"}, {"d4", "four"}, {"e5", "five"}},
+			want:     "2 blocks added",
+		},
+		{
+			name:     "removed",
+			incoming: []testEditorBlock{{"a1", "one"}, {"c3", "three"}},
+			want:     "1 block removed",
+		},
+		{
+			name:     "modified",
+			incoming: []testEditorBlock{{"a1", "one"}, {"b2", "TWO"}, {"c3", "three"}},
+			want:     "1 block modified",
+		},
+		{
+			name:     "moved only",
+			incoming: []testEditorBlock{{"c3", "three"}, {"a1", "one"}, {"b2", "two"}},
+			want:     noChangesSummary,
+		},
+		{
+			name:     "added, modified and removed",
+			incoming: []testEditorBlock{{"a1", "uno"}, {"d4", "four"}},
+			want:     "1 block added, 1 block modified, 2 blocks removed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := diffBlocks(existing, incomingBlocks(existing, tt.incoming...))
+			if got := summarizeBlockChanges(changes); got != tt.want {
+				t.Errorf("summarizeBlockChanges() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarizeBlockChangesMatchesServerIDs(t *testing.T) {
+	// After a reload the editor sends the stored UUIDs instead of the short
+	// ids it generated; the blocks are still the same blocks
+	existing := storedBlocks(testEditorBlock{"a1", "one"}, testEditorBlock{"b2", "two"})
+	reloaded := []testEditorBlock{{existing[0].ID, "one"}, {existing[1].ID, "changed"}}
+
+	changes := diffBlocks(existing, incomingBlocks(existing, reloaded...))
+	if got := summarizeBlockChanges(changes); got != "1 block modified" {
+		t.Errorf("summarizeBlockChanges() = %q, want %q", got, "1 block modified")
+	}
+}
+
+func TestResolveBlockID(t *testing.T) {
+	const storedID = "0b7d7c7e-2f0b-4a43-9a6b-8f4d0f0e0a11"
+	const foreignID = "1c8e8d8f-3a1c-4b54-8b7c-9a5e1a1f1b22"
+	existing := map[string]bool{storedID: true}
+
+	if got := resolveBlockID(diffTestPageID, storedID, existing); got != storedID {
+		t.Errorf("stored block resolved to %s, want its own ID", got)
+	}
+	if got := resolveBlockID(diffTestPageID, strings.ToUpper(storedID), existing); got != storedID {
+		t.Errorf("upper-case stored ID resolved to %s, want %s", got, storedID)
+	}
+
+	first := resolveBlockID(diffTestPageID, "abc123", existing)
+	if again := resolveBlockID(diffTestPageID, "abc123", existing); again != first {
+		t.Errorf("editor id resolved to %s then %s, want a stable ID", first, again)
+	}
+	if other := resolveBlockID("7a2d9b63-04e1-4c8a-8f3b-2c8b75e1d002", "abc123", existing); other == first {
+		t.Error("the same editor id resolved to the same ID on two pages")
+	}
+
+	if got := resolveBlockID(diffTestPageID, foreignID, existing); got == foreignID {
+		t.Error("a UUID from another page was reused as-is")
+	}
+	if a, b := resolveBlockID(diffTestPageID, "", existing), resolveBlockID(diffTestPageID, "", existing); a == b {
+		t.Error("blocks without an id resolved to the same ID")
+	}
+}
+
+func TestBlockDataEqualIgnoresWhitespace(t *testing.T) {
+	if !blockDataEqual(json.RawMessage(`{"text": "a",  "level":1}`), json.RawMessage(`{"text":"a","level":1}`)) {
+		t.Error("documents differing only in whitespace compared unequal")
+	}
+	if blockDataEqual(json.RawMessage(`{"text":"a"}`), json.RawMessage(`{"text":"b"}`)) {
+		t.Error("different documents compared equal")
+	}
+}
+
+func blockIDs(blocks []*repository.Block) []string {
+	ids := make([]string, len(blocks))
+	for i, block := range blocks {
+		ids[i] = block.ID
+	}
+	return ids
+}
+
+func TestDiffBlocks(t *testing.T) {
+	existing := storedBlocks(testEditorBlock{"a1", "one"}, testEditorBlock{"b2", "two"}, testEditorBlock{"c3", "three"})
+	a, b, c := existing[0].ID, existing[1].ID, existing[2].ID
+
+	t.Run("unchanged", func(t *testing.T) {
+		changes := diffBlocks(existing, incomingBlocks(existing, testEditorBlock{a, "one"}, testEditorBlock{b, "two"}, testEditorBlock{c, "three"}))
+		if len(changes.Create)+len(changes.Update)+len(changes.Delete) != 0 || changes.Order != nil {
+			t.Errorf("diffBlocks() = %+v, want no changes", changes)
+		}
+	})
+
+	t.Run("create update delete", func(t *testing.T) {
+		incoming := incomingBlocks(existing, testEditorBlock{a, "one"}, testEditorBlock{c, "changed"}, testEditorBlock{"new1", "four"})
+		changes := diffBlocks(existing, incoming)
+
+		if got := blockIDs(changes.Create); len(got) != 1 || got[0] != incoming[2].ID {
+			t.Errorf("Create = %v, want the new block", got)
+		}
+		if got := blockIDs(changes.Update); len(got) != 1 || got[0] != c {
+			t.Errorf("Update = %v, want [%s]", got, c)
+		}
+		if len(changes.Delete) != 1 || changes.Delete[0] != b {
+			t.Errorf("Delete = %v, want [%s]", changes.Delete, b)
+		}
+		// c moved from 2 to 1 but is rewritten by the update anyway
+		if changes.Order != nil {
+			t.Errorf("Order = %v, want nil when no unchanged block moved", changes.Order)
+		}
+	})
+
+	t.Run("moved", func(t *testing.T) {
+		changes := diffBlocks(existing, incomingBlocks(existing, testEditorBlock{c, "three"}, testEditorBlock{a, "one"}, testEditorBlock{b, "two"}))
+		if len(changes.Create)+len(changes.Update)+len(changes.Delete) != 0 {
+			t.Errorf("diffBlocks() = %+v, want only a reorder", changes)
+		}
+		want := map[string]int{c: 0, a: 1, b: 2}
+		if !reflect.DeepEqual(changes.Order, want) {
+			t.Errorf("Order = %v, want %v", changes.Order, want)
+		}
+	})
+
+	t.Run("update keeps the parent block", func(t *testing.T) {
+		parent := a
+		nested := []*repository.Block{{ID: b, BlockType: "paragraph", BlockData: json.RawMessage(`{"text":"two"}`), ParentBlockID: &parent}}
+		changes := diffBlocks(nested, []*repository.Block{{ID: b, BlockType: "header", BlockData: json.RawMessage(`{"text":"two"}`)}})
+		if len(changes.Update) != 1 || changes.Update[0].ParentBlockID == nil || *changes.Update[0].ParentBlockID != parent {
+			t.Errorf("Update = %+v, want the type change with the parent kept", changes.Update)
+		}
+	})
+}
@@ -0,0 +1,410 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+var (
+	boldTagPattern   = regexp.MustCompile(`(?i)<(?:b|strong)>(.*?)</(?:b|strong)>`)
+	italicTagPattern = regexp.MustCompile(`(?i)<(?:i|em)>(.*?)</(?:i|em)>`)
+	codeTagPattern   = regexp.MustCompile(`(?i)<code[^>]*>(.*?)</code>`)
+	linkTagPattern   = regexp.MustCompile(`(?i)<a[^>]*href="([^"]*)"[^>]*>(.*?)</a>`)
+	lineBreakPattern = regexp.MustCompile(`(?i)<br\s*/?>`)
+)
+
+// inlineHTMLToMarkdown converts the inline markup EditorJS stores in text
+// fields to Markdown and drops any tags it does not understand
+func inlineHTMLToMarkdown(s string) string {
+	s = lineBreakPattern.ReplaceAllString(s, "\n")
+	s = linkTagPattern.ReplaceAllString(s, "[$2]($1)")
+	s = boldTagPattern.ReplaceAllString(s, "**$1**")
+	s = italicTagPattern.ReplaceAllString(s, "*$1*")
+	s = codeTagPattern.ReplaceAllString(s, "`$1`")
+	s = inlineTagPattern.ReplaceAllString(s, "")
+	return strings.TrimSpace(html.UnescapeString(s))
+}
+
+// blocksToMarkdown renders a page title and its blocks, in position order, as Markdown
+func blocksToMarkdown(title string, blocks []*repository.Block) string {
+	sorted := make([]*repository.Block, len(blocks))
+	copy(sorted, blocks)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Position < sorted[j].Position
+	})
+
+	parts := make([]string, 0, len(sorted)+1)
+	if title = strings.TrimSpace(title); title != "" {
+		parts = append(parts, "# "+title)
+	}
+
+	for _, block := range sorted {
+		if md := blockToMarkdown(block.BlockType, block.BlockData); md != "" {
+			parts = append(parts, md)
+		}
+	}
+
+	return strings.Join(parts, "\n\n") + "\n"
+}
+
+// blockToMarkdown converts a single EditorJS block. Unknown block types fall
+// back to their plain data.text.
+func blockToMarkdown(blockType string, blockData json.RawMessage) string {
+	var data map[string]interface{}
+	if err := json.Unmarshal(blockData, &data); err != nil {
+		return ""
+	}
+
+	switch normalizeBlockType(blockType) {
+	case "paragraph":
+		text, _ := data["text"].(string)
+		return inlineHTMLToMarkdown(text)
+	case "heading":
+		text, _ := data["text"].(string)
+		level := 2
+		if l, ok := data["level"].(float64); ok && l >= 1 && l <= 6 {
+			level = int(l)
+		}
+		return strings.Repeat("#", level) + " " + inlineHTMLToMarkdown(text)
+	case "list":
+		style, _ := data["style"].(string)
+		var lines []string
+		writeMarkdownList(&lines, data["items"], style == "ordered", 0)
+		return strings.Join(lines, "\n")
+	case "checklist":
+		items, _ := data["items"].([]interface{})
+		lines := make([]string, 0, len(items))
+		for _, raw := range items {
+			item, _ := raw.(map[string]interface{})
+			text, _ := item["text"].(string)
+			mark := " "
+			if checked, _ := item["checked"].(bool); checked {
+				mark = "x"
+			}
+			lines = append(lines, fmt.Sprintf("- [%s] %s", mark, inlineHTMLToMarkdown(text)))
+		}
+		return strings.Join(lines, "\n")
+	case "quote":
+		text, _ := data["text"].(string)
+		lines := strings.Split(inlineHTMLToMarkdown(text), "\n")
+		if caption, _ := data["caption"].(string); strings.TrimSpace(caption) != "" {
+			lines = append(lines, "", "— "+inlineHTMLToMarkdown(caption))
+		}
+		for i, line := range lines {
+			lines[i] = strings.TrimRight("> "+line, " ")
+		}
+		return strings.Join(lines, "\n")
+	case "code":
+		code, _ := data["code"].(string)
+		language, _ := data["language"].(string)
+		return "```" + language + "\n" + strings.TrimRight(code, "\n") + "\n```"
+	case "table":
+		return tableToMarkdown(data)
+	case "divider", "delimiter":
+		return "---"
+	case "image":
+		caption, _ := data["caption"].(string)
+		url, _ := data["url"].(string)
+		if file, ok := data["file"].(map[string]interface{}); ok && url == "" {
+			url, _ = file["url"].(string)
+		}
+		if url == "" {
+			return stripInlineHTML(caption)
+		}
+		return fmt.Sprintf("![%s](%s)", stripInlineHTML(caption), url)
+	default:
+		text, _ := data["text"].(string)
+		return stripInlineHTML(text)
+	}
+}
+
+// writeMarkdownList appends plain and nested list items with indentation
+func writeMarkdownList(lines *[]string, raw interface{}, ordered bool, depth int) {
+	items, _ := raw.([]interface{})
+	indent := strings.Repeat("   ", depth)
+	for i, item := range items {
+		marker := "-"
+		if ordered {
+			marker = fmt.Sprintf("%d.", i+1)
+		}
+
+		switch v := item.(type) {
+		case string:
+			*lines = append(*lines, fmt.Sprintf("%s%s %s", indent, marker, inlineHTMLToMarkdown(v)))
+		case map[string]interface{}:
+			content, ok := v["content"].(string)
+			if !ok {
+				content, _ = v["text"].(string)
+			}
+			*lines = append(*lines, fmt.Sprintf("%s%s %s", indent, marker, inlineHTMLToMarkdown(content)))
+			writeMarkdownList(lines, v["items"], ordered, depth+1)
+		}
+	}
+}
+
+// tableToMarkdown renders a table block; Markdown always needs a header row,
+// so the first row is used as one even when the table has no headings
+func tableToMarkdown(data map[string]interface{}) string {
+	rows, _ := data["content"].([]interface{})
+	if len(rows) == 0 {
+		return ""
+	}
+
+	cells := make([][]string, 0, len(rows))
+	width := 0
+	for _, raw := range rows {
+		cols, _ := raw.([]interface{})
+		row := make([]string, 0, len(cols))
+		for _, col := range cols {
+			text, _ := col.(string)
+			text = strings.ReplaceAll(inlineHTMLToMarkdown(text), "\n", " ")
+			row = append(row, strings.ReplaceAll(text, "|", `\|`))
+		}
+		if len(row) > width {
+			width = len(row)
+		}
+		cells = append(cells, row)
+	}
+
+	if width == 0 {
+		return ""
+	}
+
+	formatRow := func(row []string) string {
+		padded := make([]string, width)
+		copy(padded, row)
+		return "| " + strings.Join(padded, " | ") + " |"
+	}
+
+	lines := make([]string, 0, len(cells)+1)
+	lines = append(lines, formatRow(cells[0]))
+
+	separator := make([]string, width)
+	for i := range separator {
+		separator[i] = "---"
+	}
+	lines = append(lines, formatRow(separator))
+
+	for _, row := range cells[1:] {
+		lines = append(lines, formatRow(row))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// importBlockBatchSize bounds how many blocks are inserted per BulkCreate call
+const importBlockBatchSize = 500
+
+var (
+	mdHeadingPattern   = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	mdFencePattern     = regexp.MustCompile("^\\s*(```|~~~)\\s*([\\w+-]*)\\s*$")
+	mdChecklistPattern = regexp.MustCompile(`^\s*[-*+]\s+\[([ xX])\]\s+(.*)$`)
+	mdBulletPattern    = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	mdOrderedPattern   = regexp.MustCompile(`^\s*\d+[.)]\s+(.*)$`)
+	mdQuotePattern     = regexp.MustCompile(`^\s*>\s?(.*)$`)
+	mdDividerPattern   = regexp.MustCompile(`^\s*(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+	mdTableRowPattern  = regexp.MustCompile(`^\s*\|.*\|\s*$`)
+	mdTableSepPattern  = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+
+	mdBoldPattern   = regexp.MustCompile(`\*\*(.+?)\*\*|__(.+?)__`)
+	mdItalicPattern = regexp.MustCompile(`\*(.+?)\*|\b_(.+?)_\b`)
+	mdCodePattern   = regexp.MustCompile("`([^`]+)`")
+	mdLinkPattern   = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	safeURLPattern  = regexp.MustCompile(`(?i)^(?:https?:|mailto:|/|#)`)
+)
+
+// editorBlock is an EditorJS block produced by the Markdown importer
+type editorBlock struct {
+	Type string
+	Data map[string]interface{}
+}
+
+// markdownInlineToHTML escapes text and converts inline Markdown to the
+// markup EditorJS uses in text fields
+func markdownInlineToHTML(s string) string {
+	s = html.EscapeString(strings.TrimSpace(s))
+	s = mdCodePattern.ReplaceAllString(s, "<code>$1</code>")
+	s = mdLinkPattern.ReplaceAllStringFunc(s, func(link string) string {
+		m := mdLinkPattern.FindStringSubmatch(link)
+		// Only allow link targets that cannot run script
+		if !safeURLPattern.MatchString(m[2]) {
+			return m[1]
+		}
+		return `<a href="` + m[2] + `">` + m[1] + `</a>`
+	})
+	s = mdBoldPattern.ReplaceAllString(s, "<b>$1$2</b>")
+	s = mdItalicPattern.ReplaceAllString(s, "<i>$1$2</i>")
+	return s
+}
+
+// markdownToBlocks parses a Markdown document into EditorJS blocks. It
+// understands headings, paragraphs, bullet, ordered and task lists, code
+// fences, blockquotes, tables and horizontal rules; anything else becomes a
+// paragraph.
+func markdownToBlocks(markdown string) []editorBlock {
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+	blocks := make([]editorBlock, 0)
+
+	var paragraph []string
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			blocks = append(blocks, editorBlock{
+				Type: "paragraph",
+				Data: map[string]interface{}{"text": markdownInlineToHTML(strings.Join(paragraph, " "))},
+			})
+			paragraph = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if strings.TrimSpace(line) == "" {
+			flushParagraph()
+			continue
+		}
+
+		if m := mdFencePattern.FindStringSubmatch(line); m != nil {
+			flushParagraph()
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), m[1]); i++ {
+				code = append(code, lines[i])
+			}
+			data := map[string]interface{}{"code": strings.Join(code, "\n")}
+			if m[2] != "" {
+				data["language"] = m[2]
+			}
+			blocks = append(blocks, editorBlock{Type: "code", Data: data})
+			continue
+		}
+
+		if m := mdHeadingPattern.FindStringSubmatch(line); m != nil {
+			flushParagraph()
+			// The editor's heading tool supports levels 1 to 3
+			level := len(m[1])
+			if level > 3 {
+				level = 3
+			}
+			blocks = append(blocks, editorBlock{
+				Type: "heading",
+				Data: map[string]interface{}{"text": markdownInlineToHTML(m[2]), "level": level},
+			})
+			continue
+		}
+
+		if mdDividerPattern.MatchString(line) {
+			flushParagraph()
+			blocks = append(blocks, editorBlock{Type: "divider", Data: map[string]interface{}{}})
+			continue
+		}
+
+		if mdQuotePattern.MatchString(line) {
+			flushParagraph()
+			var quote []string
+			for ; i < len(lines); i++ {
+				m := mdQuotePattern.FindStringSubmatch(lines[i])
+				if m == nil {
+					break
+				}
+				quote = append(quote, markdownInlineToHTML(m[1]))
+			}
+			i--
+			blocks = append(blocks, editorBlock{
+				Type: "quote",
+				Data: map[string]interface{}{"text": strings.Join(quote, "<br>"), "caption": ""},
+			})
+			continue
+		}
+
+		if mdChecklistPattern.MatchString(line) {
+			flushParagraph()
+			var items []interface{}
+			for ; i < len(lines); i++ {
+				m := mdChecklistPattern.FindStringSubmatch(lines[i])
+				if m == nil {
+					break
+				}
+				items = append(items, map[string]interface{}{
+					"text":    markdownInlineToHTML(m[2]),
+					"checked": m[1] != " ",
+				})
+			}
+			i--
+			blocks = append(blocks, editorBlock{Type: "checklist", Data: map[string]interface{}{"items": items}})
+			continue
+		}
+
+		if pattern, style := listPatternFor(line); pattern != nil {
+			flushParagraph()
+			var items []interface{}
+			for ; i < len(lines); i++ {
+				if mdChecklistPattern.MatchString(lines[i]) {
+					break
+				}
+				m := pattern.FindStringSubmatch(lines[i])
+				if m == nil {
+					break
+				}
+				items = append(items, markdownInlineToHTML(m[1]))
+			}
+			i--
+			blocks = append(blocks, editorBlock{
+				Type: "list",
+				Data: map[string]interface{}{"style": style, "items": items},
+			})
+			continue
+		}
+
+		if mdTableRowPattern.MatchString(line) && i+1 < len(lines) && mdTableSepPattern.MatchString(lines[i+1]) {
+			flushParagraph()
+			content := []interface{}{splitTableRow(line)}
+			for i += 2; i < len(lines) && mdTableRowPattern.MatchString(lines[i]); i++ {
+				content = append(content, splitTableRow(lines[i]))
+			}
+			i--
+			blocks = append(blocks, editorBlock{
+				Type: "table",
+				Data: map[string]interface{}{"withHeadings": true, "content": content},
+			})
+			continue
+		}
+
+		paragraph = append(paragraph, strings.TrimSpace(line))
+	}
+
+	flushParagraph()
+	return blocks
+}
+
+// listPatternFor returns the pattern and EditorJS style of a list item line
+func listPatternFor(line string) (*regexp.Regexp, string) {
+	switch {
+	case mdBulletPattern.MatchString(line):
+		return mdBulletPattern, "unordered"
+	case mdOrderedPattern.MatchString(line):
+		return mdOrderedPattern, "ordered"
+	default:
+		return nil, ""
+	}
+}
+
+// splitTableRow splits a Markdown table row into its cells
+func splitTableRow(line string) []interface{} {
+	line = strings.TrimSpace(line)
+	line = strings.TrimSuffix(strings.TrimPrefix(line, "|"), "|")
+
+	// Escaped pipes belong to the cell text
+	line = strings.ReplaceAll(line, `\|`, "\x00")
+
+	var cells []interface{}
+	for _, cell := range strings.Split(line, "|") {
+		cells = append(cells, markdownInlineToHTML(strings.ReplaceAll(cell, "\x00", "|")))
+	}
+	return cells
+}
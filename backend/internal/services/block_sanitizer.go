@@ -0,0 +1,122 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/security"
+)
+
+// urlDataFields are block data keys holding a URL rather than rich text, at
+// any depth (e.g. image blocks keep theirs under file.url)
+var urlDataFields = map[string]bool{
+	"url":  true,
+	"link": true,
+	"href": true,
+	"src":  true,
+}
+
+// SanitizeContent runs every text-bearing field of an EditorJS document
+// through the XSS service, keeping allowed formatting tags, and blanks URL
+// fields with a disallowed protocol such as javascript:. It returns the
+// content unchanged, byte for byte, when nothing needed cleaning, along with
+// the IDs (or indexes, for blocks without one) of the blocks it modified.
+// Content is expected to have passed ValidateContent.
+func (r *BlockTypeRegistry) SanitizeContent(content json.RawMessage, xss *security.XSSService) (json.RawMessage, []string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	// Keep numbers as written so a re-encoded document does not drift
+	decoder.UseNumber()
+
+	var document map[string]interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, nil, err
+	}
+
+	blocks, _ := document["blocks"].([]interface{})
+	var modified []string
+	for i, rawBlock := range blocks {
+		block, ok := rawBlock.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		data, ok := block["data"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		blockType, _ := block["type"].(string)
+		rawFields := r.schemas[normalizeBlockType(blockType)].RawFields
+
+		changed := false
+		for key, value := range data {
+			if slices.Contains(rawFields, key) {
+				continue
+			}
+			if cleaned, ok := sanitizeBlockValue(key, value, xss); ok {
+				data[key] = cleaned
+				changed = true
+			}
+		}
+
+		if changed {
+			id, _ := block["id"].(string)
+			if id == "" {
+				id = "#" + strconv.Itoa(i)
+			}
+			modified = append(modified, id)
+		}
+	}
+
+	if len(modified) == 0 {
+		return content, nil, nil
+	}
+
+	// Keep the surviving markup readable instead of \u003c-escaped
+	var sanitized bytes.Buffer
+	encoder := json.NewEncoder(&sanitized)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(document); err != nil {
+		return nil, nil, err
+	}
+	return bytes.TrimRight(sanitized.Bytes(), "\n"), modified, nil
+}
+
+// sanitizeBlockValue returns the cleaned value and true when value, found
+// under key, had to change
+func sanitizeBlockValue(key string, value interface{}, xss *security.XSSService) (interface{}, bool) {
+	switch v := value.(type) {
+	case string:
+		if urlDataFields[strings.ToLower(key)] {
+			if xss.IsSafeURL(v) {
+				return v, false
+			}
+			return "", true
+		}
+		result := xss.SanitizeHTML(v)
+		return result.Sanitized, result.Modified
+	case map[string]interface{}:
+		changed := false
+		for childKey, child := range v {
+			if cleaned, ok := sanitizeBlockValue(childKey, child, xss); ok {
+				v[childKey] = cleaned
+				changed = true
+			}
+		}
+		return v, changed
+	case []interface{}:
+		// List items and table cells inherit the key of their container
+		changed := false
+		for i, child := range v {
+			if cleaned, ok := sanitizeBlockValue(key, child, xss); ok {
+				v[i] = cleaned
+				changed = true
+			}
+		}
+		return v, changed
+	}
+	return value, false
+}
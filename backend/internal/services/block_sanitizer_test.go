@@ -0,0 +1,110 @@
+package services
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/security"
+)
+
+func sanitizeTestContent(t *testing.T, content string) (map[string]interface{}, []string) {
+	t.Helper()
+	registry := NewBlockTypeRegistry(nil, nil)
+	xss := security.NewXSSService(security.DefaultXSSConfig(), discardLogger())
+
+	sanitized, modified, err := registry.SanitizeContent(json.RawMessage(content), xss)
+	if err != nil {
+		t.Fatalf("SanitizeContent() error = %v", err)
+	}
+
+	var document map[string]interface{}
+	if err := json.Unmarshal(sanitized, &document); err != nil {
+		t.Fatalf("SanitizeContent() returned invalid JSON: %v", err)
+	}
+	return document, modified
+}
+
+func blockData(t *testing.T, document map[string]interface{}, index int) map[string]interface{} {
+	t.Helper()
+	blocks := document["blocks"].([]interface{})
+	return blocks[index].(map[string]interface{})["data"].(map[string]interface{})
+}
+
+func TestSanitizeContentRemovesScriptsHandlersAndScriptURLs(t *testing.T) {
+	tests := []struct {
+		name      string
+		block     string
+		field     string
+		want      string
+		forbidden string
+	}{
+		{
+			name:      "script tag",
+			block:     `{"id":"b1","type":"paragraph","data":{"text":"Hi <script>alert(1)</script><b>there</b>"}}`,
+			field:     "text",
+			want:      "Hi <b>there</b>",
+			forbidden: "alert",
+		},
+		{
+			name:      "event handler",
+			block:     `{"id":"b1","type":"paragraph","data":{"text":"<b onclick=\"alert(1)\">bold</b>"}}`,
+			field:     "text",
+			want:      "<b>bold</b>",
+			forbidden: "onclick",
+		},
+		{
+			name:      "event handler on a dropped tag",
+			block:     `{"id":"b1","type":"quote","data":{"text":"<img src=x onerror=alert(1)>quote"}}`,
+			field:     "text",
+			want:      "quote",
+			forbidden: "onerror",
+		},
+		{
+			name:      "javascript URL in markup",
+			block:     `{"id":"b1","type":"paragraph","data":{"text":"<b title=\"x\" href=\"javascript:alert(1)\">link</b>"}}`,
+			field:     "text",
+			want:      `<b title="x">link</b>`,
+			forbidden: "javascript:",
+		},
+		{
+			name:      "javascript URL field",
+			block:     `{"id":"b1","type":"bookmark","data":{"link":"java\tscript:alert(1)"}}`,
+			field:     "link",
+			want:      "",
+			forbidden: "script",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			document, modified := sanitizeTestContent(t, `{"blocks":[`+tt.block+`]}`)
+
+			got, _ := blockData(t, document, 0)[tt.field].(string)
+			if got != tt.want {
+				t.Errorf("%s = %q, want %q", tt.field, got, tt.want)
+			}
+			if strings.Contains(got, tt.forbidden) {
+				t.Errorf("%s still contains %q: %q", tt.field, tt.forbidden, got)
+			}
+			if len(modified) != 1 || modified[0] != "b1" {
+				t.Errorf("modified blocks = %v, want [b1]", modified)
+			}
+		})
+	}
+}
+
+func TestSanitizeContentKeepsFormattingVerbatim(t *testing.T) {
+	content := `{"blocks":[{"id":"b1","type":"paragraph","data":{"text":"<b>R&D</b> costs < 5%"}},` +
+		`{"id":"b2","type":"code","data":{"code":"<script>run()</script>"}}]}`
+
+	registry := NewBlockTypeRegistry(nil, nil)
+	xss := security.NewXSSService(security.DefaultXSSConfig(), discardLogger())
+	sanitized, modified, err := registry.SanitizeContent(json.RawMessage(content), xss)
+	if err != nil {
+		t.Fatalf("SanitizeContent() error = %v", err)
+	}
+	if string(sanitized) != content || len(modified) != 0 {
+		t.Errorf("SanitizeContent() changed clean content: %s (modified %v)", sanitized, modified)
+	}
+}
@@ -0,0 +1,288 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// maxBlockDataSize caps the serialized data of a single block in bytes
+	maxBlockDataSize = 64 * 1024
+	// maxBlocksPerPage caps the number of blocks accepted in one save
+	maxBlocksPerPage = 2000
+)
+
+// Content validation issue codes
+const (
+	IssueInvalidFormat = "invalid_format"
+	IssueMissingType   = "missing_type"
+	IssueUnknownType   = "unknown_type"
+	IssueInvalidData   = "invalid_data"
+	IssueMissingField  = "missing_field"
+	IssueOversizedData = "oversized_data"
+	IssueTooManyBlocks = "too_many_blocks"
+)
+
+// JSON kinds a block data field can be constrained to
+const (
+	fieldString  = "string"
+	fieldNumber  = "number"
+	fieldBoolean = "boolean"
+	fieldArray   = "array"
+	fieldObject  = "object"
+)
+
+// blockSchema describes what the server expects in a block's data
+type blockSchema struct {
+	RequiredFields []string
+	// FieldKinds constrains the JSON kind of fields when they are present
+	FieldKinds map[string]string
+	// FieldRanges bounds numeric fields, inclusive
+	FieldRanges map[string][2]float64
+	// RawFields hold verbatim text, such as source code, that the editor never
+	// renders as HTML and so is stored without sanitization
+	RawFields []string
+}
+
+// defaultBlockSchemas lists the block types the editor can produce
+var defaultBlockSchemas = map[string]blockSchema{
+	"paragraph": {RequiredFields: []string{"text"}, FieldKinds: map[string]string{"text": fieldString}},
+	"heading": {
+		RequiredFields: []string{"text", "level"},
+		FieldKinds:     map[string]string{"text": fieldString, "level": fieldNumber},
+		FieldRanges:    map[string][2]float64{"level": {1, 6}},
+	},
+	"quote":     {RequiredFields: []string{"text"}, FieldKinds: map[string]string{"text": fieldString, "caption": fieldString}},
+	"list":      {RequiredFields: []string{"items"}, FieldKinds: map[string]string{"items": fieldArray, "style": fieldString}},
+	"checklist": {RequiredFields: []string{"items"}, FieldKinds: map[string]string{"items": fieldArray}},
+	"code":      {RequiredFields: []string{"code"}, FieldKinds: map[string]string{"code": fieldString}, RawFields: []string{"code"}},
+	"table":     {RequiredFields: []string{"content"}, FieldKinds: map[string]string{"content": fieldArray, "withHeadings": fieldBoolean}},
+	"image":     {FieldKinds: map[string]string{"file": fieldObject, "caption": fieldString}},
+	"bookmark":  {FieldKinds: map[string]string{"link": fieldString}},
+	"file":      {FieldKinds: map[string]string{"file": fieldObject}},
+	"chart":     {},
+	"divider":   {},
+}
+
+// BlockTypeRegistry is the allowlist of block types accepted on save. New
+// editor blocks are enabled through configuration rather than code; see
+// config.EditorConfig.
+type BlockTypeRegistry struct {
+	schemas map[string]blockSchema
+}
+
+// NewBlockTypeRegistry starts from the built-in block types, adds extra types
+// mapped to their required data fields and removes disabled ones
+func NewBlockTypeRegistry(extra map[string][]string, disabled []string) *BlockTypeRegistry {
+	schemas := make(map[string]blockSchema, len(defaultBlockSchemas)+len(extra))
+	for blockType, schema := range defaultBlockSchemas {
+		schemas[blockType] = schema
+	}
+	for blockType, fields := range extra {
+		blockType = normalizeBlockType(blockType)
+		if _, builtIn := schemas[blockType]; builtIn {
+			continue
+		}
+		schemas[blockType] = blockSchema{RequiredFields: fields}
+	}
+	for _, blockType := range disabled {
+		delete(schemas, normalizeBlockType(blockType))
+	}
+	return &BlockTypeRegistry{schemas: schemas}
+}
+
+// blockTypeAliases maps alternative type names onto registered types
+var blockTypeAliases = map[string]string{
+	"header":         "heading",
+	"blockquote":     "quote",
+	"ordered_list":   "list",
+	"unordered_list": "list",
+	"codeblock":      "code",
+	"hr":             "divider",
+}
+
+// normalizeBlockType lower-cases a block type and resolves known aliases
+func normalizeBlockType(blockType string) string {
+	t := strings.ToLower(strings.TrimSpace(blockType))
+	if alias, ok := blockTypeAliases[t]; ok {
+		return alias
+	}
+	return t
+}
+
+// ValidateContent checks an EditorJS document against the registry and
+// returns every issue found; an empty result means it is valid
+func (r *BlockTypeRegistry) ValidateContent(content json.RawMessage) []ContentValidationIssue {
+	var editorContent struct {
+		Blocks []json.RawMessage `json:"blocks"`
+	}
+
+	if err := json.Unmarshal(content, &editorContent); err != nil {
+		return []ContentValidationIssue{{
+			Code:    IssueInvalidFormat,
+			Message: "Content is not a valid EditorJS document",
+		}}
+	}
+
+	issues := make([]ContentValidationIssue, 0)
+	if len(editorContent.Blocks) > maxBlocksPerPage {
+		issues = append(issues, ContentValidationIssue{
+			Code:    IssueTooManyBlocks,
+			Message: fmt.Sprintf("Content has %d blocks, the maximum is %d", len(editorContent.Blocks), maxBlocksPerPage),
+		})
+	}
+
+	for i, rawBlock := range editorContent.Blocks {
+		issues = append(issues, r.validateBlock(i, rawBlock)...)
+	}
+
+	return issues
+}
+
+func (r *BlockTypeRegistry) validateBlock(index int, rawBlock json.RawMessage) []ContentValidationIssue {
+	blockIndex := index
+	var block struct {
+		ID   string          `json:"id"`
+		Type string          `json:"type"`
+		Data json.RawMessage `json:"data"`
+	}
+
+	if err := json.Unmarshal(rawBlock, &block); err != nil {
+		return []ContentValidationIssue{{
+			BlockIndex: &blockIndex,
+			Code:       IssueInvalidFormat,
+			Message:    "Block must be a JSON object",
+		}}
+	}
+
+	if strings.TrimSpace(block.Type) == "" {
+		return []ContentValidationIssue{{
+			BlockIndex: &blockIndex,
+			BlockID:    block.ID,
+			Field:      "type",
+			Code:       IssueMissingType,
+			Message:    "Block type is required",
+		}}
+	}
+
+	blockType := normalizeBlockType(block.Type)
+	schema, ok := r.schemas[blockType]
+	if !ok {
+		return []ContentValidationIssue{{
+			BlockIndex: &blockIndex,
+			BlockID:    block.ID,
+			Field:      "type",
+			Code:       IssueUnknownType,
+			Message:    fmt.Sprintf("Unknown block type '%s'", block.Type),
+		}}
+	}
+
+	var issues []ContentValidationIssue
+	if len(block.Data) > maxBlockDataSize {
+		issues = append(issues, ContentValidationIssue{
+			BlockIndex: &blockIndex,
+			BlockID:    block.ID,
+			Field:      "data",
+			Code:       IssueOversizedData,
+			Message:    fmt.Sprintf("Block data is %d bytes, the maximum is %d", len(block.Data), maxBlockDataSize),
+		})
+	}
+
+	var data map[string]interface{}
+	if len(block.Data) > 0 && string(block.Data) != "null" {
+		if err := json.Unmarshal(block.Data, &data); err != nil {
+			return append(issues, ContentValidationIssue{
+				BlockIndex: &blockIndex,
+				BlockID:    block.ID,
+				Field:      "data",
+				Code:       IssueInvalidData,
+				Message:    "Block data must be a JSON object",
+			})
+		}
+	}
+
+	for _, field := range schema.RequiredFields {
+		if _, ok := data[field]; !ok {
+			issues = append(issues, ContentValidationIssue{
+				BlockIndex: &blockIndex,
+				BlockID:    block.ID,
+				Field:      "data." + field,
+				Code:       IssueMissingField,
+				Message:    fmt.Sprintf("Field '%s' is required for %s blocks", field, blockType),
+			})
+		}
+	}
+
+	fields := make([]string, 0, len(schema.FieldKinds))
+	for field := range schema.FieldKinds {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		kind := schema.FieldKinds[field]
+		value, ok := data[field]
+		if !ok || value == nil {
+			continue
+		}
+		if jsonKind(value) != kind {
+			issues = append(issues, ContentValidationIssue{
+				BlockIndex: &blockIndex,
+				BlockID:    block.ID,
+				Field:      "data." + field,
+				Code:       IssueInvalidData,
+				Message:    fmt.Sprintf("Field '%s' must be a %s for %s blocks", field, kind, blockType),
+			})
+			continue
+		}
+		if bounds, ok := schema.FieldRanges[field]; ok {
+			if n := value.(float64); n < bounds[0] || n > bounds[1] {
+				issues = append(issues, ContentValidationIssue{
+					BlockIndex: &blockIndex,
+					BlockID:    block.ID,
+					Field:      "data." + field,
+					Code:       IssueInvalidData,
+					Message:    fmt.Sprintf("Field '%s' must be between %g and %g", field, bounds[0], bounds[1]),
+				})
+			}
+		}
+	}
+
+	return issues
+}
+
+// jsonKind names the JSON kind of a value decoded into interface{}
+func jsonKind(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return fieldString
+	case float64:
+		return fieldNumber
+	case bool:
+		return fieldBoolean
+	case []interface{}:
+		return fieldArray
+	case map[string]interface{}:
+		return fieldObject
+	}
+	return ""
+}
+
+// newContentValidationError reports content issues as field-level
+// validation errors, addressing each field by its path in the document
+func newContentValidationError(issues []ContentValidationIssue) error {
+	details := make([]ValidationErrorDetail, 0, len(issues))
+	for _, issue := range issues {
+		field := "content"
+		if issue.BlockIndex != nil {
+			field = fmt.Sprintf("blocks[%d]", *issue.BlockIndex)
+			if issue.Field != "" {
+				field += "." + issue.Field
+			}
+		}
+		details = append(details, ValidationErrorDetail{Field: field, Message: issue.Message})
+	}
+	return NewValidationError(&ValidationError{Errors: details})
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/errors"
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+func issueCodes(issues []ContentValidationIssue) []string {
+	codes := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		codes = append(codes, issue.Code)
+	}
+	return codes
+}
+
+func TestValidateContentReportsIssues(t *testing.T) {
+	registry := NewBlockTypeRegistry(nil, nil)
+
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"valid document", `{"blocks":[{"type":"paragraph","data":{"text":"hi"}},{"type":"header","data":{"text":"T","level":2}},{"type":"divider"}]}`, nil},
+		{"not a document", `[1,2]`, []string{IssueInvalidFormat}},
+		{"block is not an object", `{"blocks":["text"]}`, []string{IssueInvalidFormat}},
+		{"missing type", `{"blocks":[{"data":{"text":"hi"}}]}`, []string{IssueMissingType}},
+		{"unknown type", `{"blocks":[{"type":"marquee","data":{}}]}`, []string{IssueUnknownType}},
+		{"data is not an object", `{"blocks":[{"type":"paragraph","data":"hi"}]}`, []string{IssueInvalidData}},
+		{"missing field", `{"blocks":[{"type":"paragraph","data":{}}]}`, []string{IssueMissingField}},
+		{"wrong kind", `{"blocks":[{"type":"list","data":{"items":"a"}}]}`, []string{IssueInvalidData}},
+		{"out of range", `{"blocks":[{"type":"heading","data":{"text":"T","level":9}}]}`, []string{IssueInvalidData}},
+		{"oversized data", fmt.Sprintf(`{"blocks":[{"type":"paragraph","data":{"text":"%s"}}]}`, strings.Repeat("a", maxBlockDataSize)), []string{IssueOversizedData}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := issueCodes(registry.ValidateContent(json.RawMessage(tt.content)))
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("ValidateContent() codes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateContentLimitsBlockCount(t *testing.T) {
+	blocks := make([]string, maxBlocksPerPage+1)
+	for i := range blocks {
+		blocks[i] = `{"type":"divider"}`
+	}
+	content := `{"blocks":[` + strings.Join(blocks, ",") + `]}`
+
+	issues := NewBlockTypeRegistry(nil, nil).ValidateContent(json.RawMessage(content))
+	if len(issues) != 1 || issues[0].Code != IssueTooManyBlocks {
+		t.Errorf("ValidateContent() = %v, want a single %s issue", issueCodes(issues), IssueTooManyBlocks)
+	}
+}
+
+func TestBlockTypeRegistryConfiguration(t *testing.T) {
+	registry := NewBlockTypeRegistry(map[string][]string{"Callout": {"text"}, "paragraph": nil}, []string{"Chart"})
+
+	tests := []struct {
+		content string
+		want    []string
+	}{
+		{`{"blocks":[{"type":"callout","data":{"text":"note"}}]}`, nil},
+		{`{"blocks":[{"type":"callout","data":{}}]}`, []string{IssueMissingField}},
+		{`{"blocks":[{"type":"chart","data":{}}]}`, []string{IssueUnknownType}},
+		// a built-in type keeps its own schema when listed as an extra
+		{`{"blocks":[{"type":"paragraph","data":{}}]}`, []string{IssueMissingField}},
+	}
+
+	for _, tt := range tests {
+		got := issueCodes(registry.ValidateContent(json.RawMessage(tt.content)))
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("ValidateContent(%s) codes = %v, want %v", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestValidateContentIssuesAddressTheBlock(t *testing.T) {
+	issues := NewBlockTypeRegistry(nil, nil).ValidateContent(json.RawMessage(
+		`{"blocks":[{"type":"paragraph","data":{"text":"ok"}},{"id":"b2","type":"heading","data":{"level":2}}]}`))
+	if len(issues) != 1 {
+		t.Fatalf("ValidateContent() = %v, want one issue", issueCodes(issues))
+	}
+
+	issue := issues[0]
+	if issue.BlockIndex == nil || *issue.BlockIndex != 1 || issue.BlockID != "b2" || issue.Field != "data.text" {
+		t.Errorf("issue = %+v, want block 1 (b2) field data.text", issue)
+	}
+}
+
+func TestPageServiceValidateContent(t *testing.T) {
+	svc := NewPageService(newFakePageRepo(), nil, nil, nil, fakeActivityService{}, nil, nil, NewBlockTypeRegistry(nil, nil), nil, discardLogger())
+	ctx := context.Background()
+
+	result, err := svc.ValidateContent(ctx, &SavePageContentRequest{Content: json.RawMessage(`{"blocks":[{"type":"paragraph","data":{"text":"hi"}}]}`)})
+	if err != nil {
+		t.Fatalf("ValidateContent() error = %v", err)
+	}
+	if !result.Valid || len(result.Issues) != 0 {
+		t.Errorf("ValidateContent() = %+v, want valid", result)
+	}
+
+	result, err = svc.ValidateContent(ctx, &SavePageContentRequest{Content: json.RawMessage(`{"blocks":[{"type":"marquee"}]}`)})
+	if err != nil {
+		t.Fatalf("ValidateContent() error = %v", err)
+	}
+	if result.Valid || len(result.Issues) != 1 {
+		t.Errorf("ValidateContent() = %+v, want one issue", result)
+	}
+
+	if _, err := svc.ValidateContent(ctx, &SavePageContentRequest{}); !IsValidationError(err) {
+		t.Errorf("ValidateContent() without content error = %v, want a validation error", err)
+	}
+}
+
+func TestSavePageContentRejectsInvalidBlocksWithFieldErrors(t *testing.T) {
+	pages := newFakePageRepo(&repository.Page{ID: "page", WorkspaceID: 10, OwnerID: 1})
+	registry := NewBlockTypeRegistry(nil, []string{"chart"})
+	// The block repository is left unimplemented: a rejected save must not write blocks
+	svc := NewPageService(pages, &fakeBlockRepo{}, nil, nil, fakeActivityService{}, nil, nil, registry, nil, discardLogger())
+
+	content := `{"blocks":[
+		{"id":"a","type":"paragraph","data":{"text":"fine"}},
+		{"id":"b","type":"chart","data":{}},
+		{"id":"c","type":"heading","data":{"text":1,"level":2}}
+	]}`
+	_, err := svc.SavePageContent(context.Background(), 1, "page", &SavePageContentRequest{Content: json.RawMessage(content)})
+	if !IsValidationError(err) {
+		t.Fatalf("SavePageContent() error = %v, want a validation error", err)
+	}
+
+	details, ok := err.(*errors.AppError).Details.(*ValidationErrorResponse)
+	if !ok {
+		t.Fatalf("error details = %T, want field errors", err.(*errors.AppError).Details)
+	}
+	fields := make([]string, 0, len(details.Errors))
+	for _, detail := range details.Errors {
+		fields = append(fields, detail.Field)
+	}
+	if want := []string{"blocks[1].type", "blocks[2].data.text"}; strings.Join(fields, ",") != strings.Join(want, ",") {
+		t.Errorf("error fields = %v, want %v", fields, want)
+	}
+}
+
+func TestNewContentValidationErrorForDocumentIssues(t *testing.T) {
+	err := newContentValidationError([]ContentValidationIssue{{Code: IssueInvalidFormat, Message: "bad"}})
+	details := err.(*errors.AppError).Details.(*ValidationErrorResponse)
+	if len(details.Errors) != 1 || details.Errors[0].Field != "content" || details.Errors[0].Message != "bad" {
+		t.Errorf("details = %+v, want one error on content", details.Errors)
+	}
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"encoding/json"
+	"html"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+const (
+	// previewBlockCount is how many leading blocks feed a page preview
+	previewBlockCount = 3
+	// maxPreviewLength caps the preview string in runes
+	maxPreviewLength = 200
+	// maxSnippetLength caps the text of a search snippet in runes
+	maxSnippetLength = 200
+)
+
+var inlineTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// stripInlineHTML removes the inline markup EditorJS stores in text fields
+func stripInlineHTML(s string) string {
+	return strings.TrimSpace(html.UnescapeString(inlineTagPattern.ReplaceAllString(s, "")))
+}
+
+// extractBlockText returns the plain text of an EditorJS block
+func extractBlockText(blockType string, blockData json.RawMessage) string {
+	var data map[string]interface{}
+	if err := json.Unmarshal(blockData, &data); err != nil {
+		return ""
+	}
+
+	switch blockType {
+	case "list", "checklist":
+		return strings.Join(extractListItems(data["items"]), " ")
+	case "code":
+		code, _ := data["code"].(string)
+		return strings.TrimSpace(code)
+	case "table":
+		var cells []string
+		rows, _ := data["content"].([]interface{})
+		for _, row := range rows {
+			cols, _ := row.([]interface{})
+			for _, col := range cols {
+				if text, ok := col.(string); ok && strings.TrimSpace(text) != "" {
+					cells = append(cells, stripInlineHTML(text))
+				}
+			}
+		}
+		return strings.Join(cells, " ")
+	case "image", "embed":
+		caption, _ := data["caption"].(string)
+		return stripInlineHTML(caption)
+	default:
+		text, _ := data["text"].(string)
+		return stripInlineHTML(text)
+	}
+}
+
+// extractListItems flattens plain, checklist and nested list items
+func extractListItems(raw interface{}) []string {
+	items, _ := raw.([]interface{})
+	texts := make([]string, 0, len(items))
+	for _, item := range items {
+		switch v := item.(type) {
+		case string:
+			texts = append(texts, stripInlineHTML(v))
+		case map[string]interface{}:
+			if content, ok := v["content"].(string); ok {
+				texts = append(texts, stripInlineHTML(content))
+			} else if text, ok := v["text"].(string); ok {
+				texts = append(texts, stripInlineHTML(text))
+			}
+			texts = append(texts, extractListItems(v["items"])...)
+		}
+	}
+	return texts
+}
+
+// buildPreview joins the text of the given blocks into a bounded preview string
+func buildPreview(blocks []*repository.Block) string {
+	parts := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		if text := extractBlockText(block.BlockType, block.BlockData); text != "" {
+			parts = append(parts, text)
+		}
+	}
+
+	preview := strings.Join(strings.Fields(strings.Join(parts, " ")), " ")
+	if utf8.RuneCountInString(preview) > maxPreviewLength {
+		runes := []rune(preview)
+		preview = strings.TrimSpace(string(runes[:maxPreviewLength-1])) + "…"
+	}
+
+	return preview
+}
+
+// buildSearchSnippet turns a block search hit into an HTML-safe snippet:
+// block markup is stripped, the text escaped, and only the matched words are
+// wrapped in <mark>. Long text is cut to a window around the first match.
+func buildSearchSnippet(hit *repository.BlockSearchHit) string {
+	text := strings.Join(strings.Fields(extractBlockText(hit.BlockType, hit.Headline)), " ")
+
+	runes := []rune(text)
+	if len(runes) > maxSnippetLength {
+		start := 0
+		if i := strings.Index(text, repository.SearchHighlightStart); i >= 0 {
+			// Keep a little context before the match
+			start = max(0, utf8.RuneCountInString(text[:i])-maxSnippetLength/4)
+		}
+		end := min(len(runes), start+maxSnippetLength)
+
+		text = strings.TrimSpace(string(runes[start:end]))
+		if start > 0 {
+			text = "…" + text
+		}
+		if end < len(runes) {
+			text += "…"
+		}
+	}
+
+	var snippet strings.Builder
+	marked := false
+	for _, r := range text {
+		switch string(r) {
+		case repository.SearchHighlightStart:
+			if !marked {
+				snippet.WriteString("<mark>")
+				marked = true
+			}
+		case repository.SearchHighlightStop:
+			if marked {
+				snippet.WriteString("</mark>")
+				marked = false
+			}
+		default:
+			snippet.WriteString(html.EscapeString(string(r)))
+		}
+	}
+	// A cut may fall inside a match
+	if marked {
+		snippet.WriteString("</mark>")
+	}
+
+	return snippet.String()
+}
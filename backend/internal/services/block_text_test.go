@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+func TestExtractBlockText(t *testing.T) {
+	tests := []struct {
+		blockType string
+		data      string
+		want      string
+	}{
+		{"paragraph", `{"text":"Hello <b>bold</b> &amp; more"}`, "Hello bold & more"},
+		{"header", `{"text":"  Title  ","level":2}`, "Title"},
+		{"list", `{"items":["one",{"content":"two","items":[{"content":"<i>nested</i>"}]}]}`, "one two nested"},
+		{"checklist", `{"items":[{"text":"done","checked":true},{"text":"todo"}]}`, "done todo"},
+		{"code", `{"code":"  x := 1\n"}`, "x := 1"},
+		{"table", `{"content":[["a","<b>b</b>"],["","c"]]}`, "a b c"},
+		{"image", `{"file":{"url":"x"},"caption":"A <i>cat</i>"}`, "A cat"},
+		{"delimiter", `{}`, ""},
+		{"paragraph", `not json`, ""},
+	}
+
+	for _, tt := range tests {
+		if got := extractBlockText(tt.blockType, json.RawMessage(tt.data)); got != tt.want {
+			t.Errorf("extractBlockText(%s, %s) = %q, want %q", tt.blockType, tt.data, got, tt.want)
+		}
+	}
+}
+
+func textBlock(pageID, text string) *repository.Block {
+	data, _ := json.Marshal(map[string]string{"text": text})
+	return &repository.Block{PageID: pageID, BlockType: "paragraph", BlockData: data}
+}
+
+func TestBuildPreview(t *testing.T) {
+	got := buildPreview([]*repository.Block{textBlock("p", "First  line\n"), textBlock("p", ""), textBlock("p", "second")})
+	if got != "First line second" {
+		t.Errorf("buildPreview() = %q, want whitespace collapsed and empty blocks skipped", got)
+	}
+
+	long := buildPreview([]*repository.Block{textBlock("p", strings.Repeat("é", maxPreviewLength+50))})
+	if n := utf8.RuneCountInString(long); n != maxPreviewLength || !strings.HasSuffix(long, "…") {
+		t.Errorf("long preview has %d runes ending %q, want %d ending with an ellipsis", n, long[len(long)-3:], maxPreviewLength)
+	}
+
+	if got := buildPreview(nil); got != "" {
+		t.Errorf("buildPreview(nil) = %q, want empty", got)
+	}
+}
+
+func TestAttachPreviews(t *testing.T) {
+	blocks := &fakeBlockRepo{blocks: []*repository.Block{
+		textBlock("a", "one"), textBlock("a", "two"), textBlock("a", "three"), textBlock("a", "four"),
+	}}
+	svc := NewPageService(newFakePageRepo(), blocks, nil, nil, fakeActivityService{}, nil, nil, nil, nil, discardLogger())
+
+	pages := []PageResponse{{ID: "a"}, {ID: "empty"}}
+	if err := svc.AttachPreviews(context.Background(), pages); err != nil {
+		t.Fatalf("AttachPreviews() error = %v", err)
+	}
+
+	if pages[0].Preview == nil || *pages[0].Preview != "one two three" {
+		t.Errorf("preview of a = %v, want the first %d blocks", pages[0].Preview, previewBlockCount)
+	}
+	if pages[1].Preview == nil || *pages[1].Preview != "" {
+		t.Errorf("preview of an empty page = %v, want an empty string", pages[1].Preview)
+	}
+}
@@ -0,0 +1,432 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+// maxCommentMentions caps how many distinct users a single comment can notify
+const maxCommentMentions = 20
+
+// mentionPattern matches @username where the @ is not part of a word, so
+// email addresses in comment bodies are not treated as mentions
+var mentionPattern = regexp.MustCompile(`(?:^|[^A-Za-z0-9_@.])@([A-Za-z0-9]+)`)
+
+type CommentService interface {
+	CreateComment(ctx context.Context, userID int64, req *CreateCommentRequest) (*CommentResponse, error)
+	GetPageComments(ctx context.Context, userID int64, pageID string) ([]CommentResponse, error)
+	GetBlockComments(ctx context.Context, userID int64, pageID string, blockID string) ([]CommentResponse, error)
+	ReplyToComment(ctx context.Context, userID int64, pageID string, commentID string, req *UpdateCommentRequest) (*CommentResponse, error)
+	UpdateComment(ctx context.Context, userID int64, pageID string, commentID string, req *UpdateCommentRequest) (*CommentResponse, error)
+	ResolveComment(ctx context.Context, userID int64, pageID string, commentID string) (*CommentResponse, error)
+	UnresolveComment(ctx context.Context, userID int64, pageID string, commentID string) (*CommentResponse, error)
+	DeleteComment(ctx context.Context, userID int64, pageID string, commentID string) error
+}
+
+type commentService struct {
+	commentRepo      repository.CommentRepository
+	pageRepo         repository.PageRepository
+	blockRepo        repository.BlockRepository
+	userRepo         repository.UserRepository
+	notificationRepo repository.NotificationRepository
+	logger           *slog.Logger
+}
+
+func NewCommentService(
+	commentRepo repository.CommentRepository,
+	pageRepo repository.PageRepository,
+	blockRepo repository.BlockRepository,
+	userRepo repository.UserRepository,
+	notificationRepo repository.NotificationRepository,
+	logger *slog.Logger,
+) CommentService {
+	return &commentService{
+		commentRepo:      commentRepo,
+		pageRepo:         pageRepo,
+		blockRepo:        blockRepo,
+		userRepo:         userRepo,
+		notificationRepo: notificationRepo,
+		logger:           logger,
+	}
+}
+
+func (s *commentService) CreateComment(ctx context.Context, userID int64, req *CreateCommentRequest) (*CommentResponse, error) {
+	// Validate input
+	if err := validateStruct(req); err != nil {
+		return nil, NewValidationError(err)
+	}
+
+	if err := s.requirePagePermission(ctx, userID, req.PageID, repository.PermissionComment); err != nil {
+		return nil, err
+	}
+
+	if req.BlockID != nil {
+		block, err := s.blockRepo.GetByID(ctx, *req.BlockID)
+		if err != nil {
+			s.logger.Error("Failed to get block", "error", err, "block_id", *req.BlockID)
+			return nil, NewInternalError("Failed to get block")
+		}
+
+		if block == nil || block.PageID != req.PageID {
+			return nil, NewNotFoundError("Block")
+		}
+	}
+
+	if req.ParentCommentID != nil {
+		parent, err := s.getPageComment(ctx, req.PageID, *req.ParentCommentID)
+		if err != nil {
+			return nil, err
+		}
+
+		// Replies stay anchored to the same block as their thread
+		req.BlockID = parent.BlockID
+	}
+
+	comment := &repository.Comment{
+		PageID:          req.PageID,
+		BlockID:         req.BlockID,
+		ParentCommentID: req.ParentCommentID,
+		AuthorID:        userID,
+		Content:         req.Content,
+	}
+
+	if err := s.commentRepo.Create(ctx, comment); err != nil {
+		s.logger.Error("Failed to create comment", "error", err, "page_id", req.PageID, "user_id", userID)
+		return nil, NewInternalError("Failed to create comment")
+	}
+
+	// The comment is already saved, so mention failures are only logged
+	s.notifyMentions(ctx, comment)
+
+	return s.toCommentResponse(ctx, comment, map[int64]*repository.User{}), nil
+}
+
+func (s *commentService) GetPageComments(ctx context.Context, userID int64, pageID string) ([]CommentResponse, error) {
+	if err := s.requirePagePermission(ctx, userID, pageID, repository.PermissionView); err != nil {
+		return nil, err
+	}
+
+	comments, err := s.commentRepo.GetByPageID(ctx, pageID)
+	if err != nil {
+		s.logger.Error("Failed to get comments", "error", err, "page_id", pageID)
+		return nil, NewInternalError("Failed to get comments")
+	}
+
+	return s.buildThreads(ctx, comments), nil
+}
+
+func (s *commentService) GetBlockComments(ctx context.Context, userID int64, pageID string, blockID string) ([]CommentResponse, error) {
+	if err := s.requirePagePermission(ctx, userID, pageID, repository.PermissionView); err != nil {
+		return nil, err
+	}
+
+	comments, err := s.commentRepo.GetByBlockID(ctx, blockID)
+	if err != nil {
+		s.logger.Error("Failed to get block comments", "error", err, "block_id", blockID)
+		return nil, NewInternalError("Failed to get comments")
+	}
+
+	// Block IDs are only meaningful within the page from the URL
+	onPage := make([]*repository.Comment, 0, len(comments))
+	for _, comment := range comments {
+		if comment.PageID == pageID {
+			onPage = append(onPage, comment)
+		}
+	}
+
+	return s.buildThreads(ctx, onPage), nil
+}
+
+func (s *commentService) ReplyToComment(ctx context.Context, userID int64, pageID string, commentID string, req *UpdateCommentRequest) (*CommentResponse, error) {
+	// Validate input
+	if err := validateStruct(req); err != nil {
+		return nil, NewValidationError(err)
+	}
+
+	return s.CreateComment(ctx, userID, &CreateCommentRequest{
+		PageID:          pageID,
+		ParentCommentID: &commentID,
+		Content:         req.Content,
+	})
+}
+
+func (s *commentService) UpdateComment(ctx context.Context, userID int64, pageID string, commentID string, req *UpdateCommentRequest) (*CommentResponse, error) {
+	// Validate input
+	if err := validateStruct(req); err != nil {
+		return nil, NewValidationError(err)
+	}
+
+	if err := s.requirePagePermission(ctx, userID, pageID, repository.PermissionComment); err != nil {
+		return nil, err
+	}
+
+	comment, err := s.getPageComment(ctx, pageID, commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if comment.AuthorID != userID {
+		return nil, NewForbiddenError("Only the author can edit a comment")
+	}
+
+	comment.Content = req.Content
+	if err := s.commentRepo.Update(ctx, comment); err != nil {
+		s.logger.Error("Failed to update comment", "error", err, "comment_id", commentID)
+		return nil, NewInternalError("Failed to update comment")
+	}
+
+	return s.toCommentResponse(ctx, comment, map[int64]*repository.User{}), nil
+}
+
+func (s *commentService) ResolveComment(ctx context.Context, userID int64, pageID string, commentID string) (*CommentResponse, error) {
+	if err := s.requirePagePermission(ctx, userID, pageID, repository.PermissionComment); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.getPageComment(ctx, pageID, commentID); err != nil {
+		return nil, err
+	}
+
+	if err := s.commentRepo.Resolve(ctx, commentID, userID); err != nil {
+		s.logger.Error("Failed to resolve comment", "error", err, "comment_id", commentID)
+		return nil, NewInternalError("Failed to resolve comment")
+	}
+
+	return s.reloadComment(ctx, pageID, commentID)
+}
+
+func (s *commentService) UnresolveComment(ctx context.Context, userID int64, pageID string, commentID string) (*CommentResponse, error) {
+	if err := s.requirePagePermission(ctx, userID, pageID, repository.PermissionComment); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.getPageComment(ctx, pageID, commentID); err != nil {
+		return nil, err
+	}
+
+	if err := s.commentRepo.Unresolve(ctx, commentID); err != nil {
+		s.logger.Error("Failed to unresolve comment", "error", err, "comment_id", commentID)
+		return nil, NewInternalError("Failed to unresolve comment")
+	}
+
+	return s.reloadComment(ctx, pageID, commentID)
+}
+
+func (s *commentService) DeleteComment(ctx context.Context, userID int64, pageID string, commentID string) error {
+	if err := s.requirePagePermission(ctx, userID, pageID, repository.PermissionView); err != nil {
+		return err
+	}
+
+	comment, err := s.getPageComment(ctx, pageID, commentID)
+	if err != nil {
+		return err
+	}
+
+	// Authors can delete their own comments, page admins can delete any
+	if comment.AuthorID != userID {
+		isAdmin, err := s.pageRepo.HasPermission(ctx, pageID, userID, repository.PermissionAdmin)
+		if err != nil {
+			s.logger.Error("Failed to check page permission", "error", err, "page_id", pageID, "user_id", userID)
+			return NewInternalError("Failed to verify page access")
+		}
+
+		if !isAdmin {
+			return NewForbiddenError("Only the author or a page admin can delete a comment")
+		}
+	}
+
+	if err := s.commentRepo.Delete(ctx, commentID); err != nil {
+		s.logger.Error("Failed to delete comment", "error", err, "comment_id", commentID)
+		return NewInternalError("Failed to delete comment")
+	}
+
+	return nil
+}
+
+func (s *commentService) requirePagePermission(ctx context.Context, userID int64, pageID string, level repository.PermissionLevel) error {
+	hasPermission, err := s.pageRepo.HasPermission(ctx, pageID, userID, level)
+	if err != nil {
+		s.logger.Error("Failed to check page permission", "error", err, "page_id", pageID, "user_id", userID)
+		return NewInternalError("Failed to verify page access")
+	}
+
+	if !hasPermission {
+		return NewForbiddenError("Access denied to page comments")
+	}
+
+	return nil
+}
+
+// parseMentions returns the distinct usernames mentioned in a comment body,
+// in order of first appearance
+func parseMentions(content string) []string {
+	seen := make(map[string]bool)
+	var usernames []string
+	for _, match := range mentionPattern.FindAllStringSubmatch(content, -1) {
+		username := match[1]
+		if seen[username] {
+			continue
+		}
+		seen[username] = true
+		usernames = append(usernames, username)
+		if len(usernames) == maxCommentMentions {
+			break
+		}
+	}
+	return usernames
+}
+
+// notifyMentions records the users mentioned in a new comment and notifies
+// the ones who can view the page. Authors are never notified about
+// mentioning themselves.
+func (s *commentService) notifyMentions(ctx context.Context, comment *repository.Comment) {
+	usernames := parseMentions(comment.Content)
+	if len(usernames) == 0 {
+		return
+	}
+
+	var mentioned []int64
+	for _, username := range usernames {
+		user, err := s.userRepo.GetByUsername(ctx, username)
+		if IsNotFoundError(err) {
+			continue
+		}
+		if err != nil {
+			s.logger.Error("Failed to resolve mentioned user", "error", err, "username", username)
+			continue
+		}
+		if user == nil || user.ID == comment.AuthorID {
+			continue
+		}
+		mentioned = append(mentioned, user.ID)
+	}
+
+	if len(mentioned) == 0 {
+		return
+	}
+
+	if err := s.commentRepo.AddMentions(ctx, comment.ID, mentioned); err != nil {
+		s.logger.Error("Failed to record comment mentions", "error", err, "comment_id", comment.ID)
+		return
+	}
+
+	for _, userID := range mentioned {
+		canView, err := s.pageRepo.HasPermission(ctx, comment.PageID, userID, repository.PermissionView)
+		if err != nil {
+			s.logger.Error("Failed to check page permission", "error", err, "page_id", comment.PageID, "user_id", userID)
+			continue
+		}
+		if !canView {
+			continue
+		}
+
+		notification := &repository.Notification{
+			UserID:    userID,
+			Type:      repository.NotificationTypeMention,
+			ActorID:   &comment.AuthorID,
+			PageID:    &comment.PageID,
+			CommentID: &comment.ID,
+		}
+		if err := s.notificationRepo.Create(ctx, notification); err != nil {
+			s.logger.Error("Failed to create mention notification", "error", err, "comment_id", comment.ID, "user_id", userID)
+		}
+	}
+}
+
+// getPageComment loads a comment and makes sure it belongs to the page
+func (s *commentService) getPageComment(ctx context.Context, pageID string, commentID string) (*repository.Comment, error) {
+	comment, err := s.commentRepo.GetByID(ctx, commentID)
+	if err != nil {
+		s.logger.Error("Failed to get comment", "error", err, "comment_id", commentID)
+		return nil, NewInternalError("Failed to get comment")
+	}
+
+	if comment == nil || comment.PageID != pageID {
+		return nil, NewNotFoundError("Comment")
+	}
+
+	return comment, nil
+}
+
+func (s *commentService) reloadComment(ctx context.Context, pageID string, commentID string) (*CommentResponse, error) {
+	comment, err := s.getPageComment(ctx, pageID, commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toCommentResponse(ctx, comment, map[int64]*repository.User{}), nil
+}
+
+// buildThreads nests replies under their parent comments. Replies whose
+// parent is not in the list are returned at the top level.
+func (s *commentService) buildThreads(ctx context.Context, comments []*repository.Comment) []CommentResponse {
+	authors := make(map[int64]*repository.User)
+	present := make(map[string]bool, len(comments))
+	repliesOf := make(map[string][]*repository.Comment)
+	for _, comment := range comments {
+		present[comment.ID] = true
+	}
+
+	var roots []*repository.Comment
+	for _, comment := range comments {
+		if comment.ParentCommentID != nil && present[*comment.ParentCommentID] {
+			repliesOf[*comment.ParentCommentID] = append(repliesOf[*comment.ParentCommentID], comment)
+		} else {
+			roots = append(roots, comment)
+		}
+	}
+
+	var build func(comment *repository.Comment) CommentResponse
+	build = func(comment *repository.Comment) CommentResponse {
+		response := s.toCommentResponse(ctx, comment, authors)
+		for _, reply := range repliesOf[comment.ID] {
+			response.Replies = append(response.Replies, build(reply))
+		}
+		return *response
+	}
+
+	threads := make([]CommentResponse, 0, len(roots))
+	for _, root := range roots {
+		threads = append(threads, build(root))
+	}
+
+	return threads
+}
+
+// toCommentResponse resolves the author through the given cache so listing a
+// thread looks each author up only once
+func (s *commentService) toCommentResponse(ctx context.Context, comment *repository.Comment, authors map[int64]*repository.User) *CommentResponse {
+	response := &CommentResponse{
+		ID:              comment.ID,
+		PageID:          comment.PageID,
+		BlockID:         comment.BlockID,
+		ParentCommentID: comment.ParentCommentID,
+		AuthorID:        comment.AuthorID,
+		Content:         comment.Content,
+		IsResolved:      comment.IsResolved,
+		ResolvedBy:      comment.ResolvedBy,
+		ResolvedAt:      comment.ResolvedAt,
+		CreatedAt:       comment.CreatedAt,
+		UpdatedAt:       comment.UpdatedAt,
+	}
+
+	author, ok := authors[comment.AuthorID]
+	if !ok {
+		var err error
+		author, err = s.userRepo.GetByID(ctx, comment.AuthorID)
+		if err != nil {
+			s.logger.Error("Failed to get comment author", "error", err, "user_id", comment.AuthorID)
+		}
+		authors[comment.AuthorID] = author
+	}
+
+	if author != nil {
+		response.AuthorName = author.Username
+		response.AuthorEmail = author.Email
+	}
+
+	return response
+}
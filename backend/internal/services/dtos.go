@@ -8,7 +8,7 @@ import (
 type RegisterRequest struct {
 	Username  string `json:"username" validate:"required,min=3,max=50,alphanum"`
 	Email     string `json:"email" validate:"required,email,max=255"`
-	Password  string `json:"password" validate:"required,min=8,max=128"`
+	Password  string `json:"password" validate:"required"`
 	FirstName string `json:"first_name" validate:"omitempty,max=100"`
 	LastName  string `json:"last_name" validate:"omitempty,max=100"`
 }
@@ -27,16 +27,25 @@ type UpdateProfileRequest struct {
 
 type ChangePasswordRequest struct {
 	CurrentPassword string `json:"current_password" validate:"required"`
-	NewPassword     string `json:"new_password" validate:"required,min=8,max=128"`
+	NewPassword     string `json:"new_password" validate:"required"`
 	ConfirmPassword string `json:"confirm_password" validate:"required,eqfield=NewPassword"`
 }
 
 type ResetPasswordRequest struct {
 	Token           string `json:"token" validate:"required"`
-	NewPassword     string `json:"new_password" validate:"required,min=8,max=128"`
+	NewPassword     string `json:"new_password" validate:"required"`
 	ConfirmPassword string `json:"confirm_password" validate:"required,eqfield=NewPassword"`
 }
 
+type SessionInfo struct {
+	ID         int64     `json:"id"`
+	DeviceInfo string    `json:"device_info"`
+	IPAddress  string    `json:"ip_address,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
 type UserResponse struct {
 	ID            int64     `json:"id"`
 	Username      string    `json:"username"`
@@ -155,17 +164,32 @@ type CreateWorkspaceRequest struct {
 type UpdateWorkspaceRequest struct {
 	Name        *string `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
 	Description *string `json:"description,omitempty" validate:"omitempty,max=1000"`
+	// DefaultPagePermission is the access members get on pages without an
+	// explicit grant; "none" restricts pages to owners and explicit grants
+	DefaultPagePermission *string `json:"default_page_permission,omitempty" validate:"omitempty,oneof=none view comment edit"`
+}
+
+type UpdatePropertySchemaRequest struct {
+	// Schema is a JSON schema for page properties; null removes it, making
+	// properties free-form again
+	Schema json.RawMessage `json:"schema"`
+}
+
+type PropertySchemaResponse struct {
+	WorkspaceID int64           `json:"workspace_id"`
+	Schema      json.RawMessage `json:"schema"`
 }
 
 type WorkspaceResponse struct {
-	ID          int64     `json:"id"`
-	Name        string    `json:"name"`
-	Description *string   `json:"description,omitempty"`
-	OwnerID     int64     `json:"owner_id"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
-	MemberCount int       `json:"member_count"`
-	Role        string    `json:"role"` // Current user's role
+	ID                    int64     `json:"id"`
+	Name                  string    `json:"name"`
+	Description           *string   `json:"description,omitempty"`
+	OwnerID               int64     `json:"owner_id"`
+	DefaultPagePermission string    `json:"default_page_permission"`
+	CreatedAt             time.Time `json:"created_at"`
+	UpdatedAt             time.Time `json:"updated_at"`
+	MemberCount           int       `json:"member_count"`
+	Role                  string    `json:"role"` // Current user's role
 }
 
 type AddWorkspaceMemberRequest struct {
@@ -173,6 +197,65 @@ type AddWorkspaceMemberRequest struct {
 	Role   string `json:"role" validate:"required,oneof=member admin"`
 }
 
+type InviteWorkspaceMemberRequest struct {
+	Email string `json:"email" validate:"required,email,max=255"`
+	Role  string `json:"role" validate:"required,oneof=member admin"`
+}
+
+type AcceptWorkspaceInvitationRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type WorkspaceInvitationResponse struct {
+	ID          string    `json:"id"`
+	WorkspaceID int64     `json:"workspace_id"`
+	Email       string    `json:"email"`
+	Role        string    `json:"role"`
+	InvitedBy   int64     `json:"invited_by"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	CreatedAt   time.Time `json:"created_at"`
+	// ExistingUser tells whether the email already belongs to an account
+	ExistingUser bool `json:"existing_user"`
+}
+
+type ActivityResponse struct {
+	ID            int64           `json:"id"`
+	ActorID       *int64          `json:"actor_id,omitempty"`
+	ActorUsername string          `json:"actor_username,omitempty"`
+	Action        string          `json:"action"`
+	TargetType    string          `json:"target_type"`
+	TargetID      string          `json:"target_id"`
+	Metadata      json.RawMessage `json:"metadata"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// WorkspaceInvitationEmail carries what the invitation email needs
+type WorkspaceInvitationEmail struct {
+	Token          string
+	InviterName    string
+	WorkspaceName  string
+	Role           string
+	HasAccount     bool
+	ExpirationDays int
+}
+
+// PageAccessEmail describes a page permission change for the affected user
+type PageAccessEmail struct {
+	Granted    bool // false for a revocation
+	PageID     string
+	PageTitle  string
+	ActorName  string
+	Permission string // Empty for revocations
+}
+
+// NewDeviceLoginEmail describes a sign-in from a device the user had not used before
+type NewDeviceLoginEmail struct {
+	Username    string
+	DeviceLabel string
+	IPAddress   string
+	SignedInAt  time.Time
+}
+
 type WorkspaceMemberResponse struct {
 	ID          int64     `json:"id"`
 	UserID      int64     `json:"user_id"`
@@ -194,6 +277,30 @@ type CreatePageRequest struct {
 	CoverURL    *string         `json:"cover_url,omitempty"`
 	IsTemplate  bool            `json:"is_template"`
 	Properties  json.RawMessage `json:"properties,omitempty"`
+	// FailOnDuplicate rejects the create with a conflict instead of warning
+	// when a sibling page with the same title already exists
+	FailOnDuplicate bool `json:"fail_on_duplicate,omitempty"`
+}
+
+// PageTreeNode is a page in the nested sidebar tree
+type PageTreeNode struct {
+	ID          string         `json:"id"`
+	Title       string         `json:"title"`
+	ParentID    *string        `json:"parent_id,omitempty"`
+	Icon        *string        `json:"icon,omitempty"`
+	IsArchived  bool           `json:"is_archived"`
+	IsTemplate  bool           `json:"is_template"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	HasChildren bool           `json:"has_children"` // True even when the children were cut off by max_depth
+	Children    []PageTreeNode `json:"children"`
+}
+
+type ImportMarkdownRequest struct {
+	WorkspaceID int64   `json:"workspace_id" validate:"required"`
+	ParentID    *string `json:"parent_id,omitempty"`
+	// Title defaults to the document's leading level-1 heading
+	Title    string `json:"title,omitempty" validate:"omitempty,max=500"`
+	Markdown string `json:"markdown" validate:"required,max=1048576"`
 }
 
 type UpdatePageRequest struct {
@@ -218,9 +325,117 @@ type PageResponse struct {
 	CreatedAt    time.Time       `json:"created_at"`
 	UpdatedAt    time.Time       `json:"updated_at"`
 	LastEditedBy *int64          `json:"last_edited_by,omitempty"`
+	DeletedAt    *time.Time      `json:"deleted_at,omitempty"` // Only set for pages in the trash
 	Permission   string          `json:"permission"` // Current user's permission level
-	ChildrenCount int            `json:"children_count"`
+	ChildrenCount int            `json:"children_count"` // Live, unarchived child pages
 	Blocks       []BlockResponse `json:"blocks,omitempty"`
+	Preview      *string         `json:"preview,omitempty"` // Only set when requested with ?preview=true
+	SearchHit    *SearchHit      `json:"search_hit,omitempty"` // Only set on text search results
+	Warnings     []string        `json:"warnings,omitempty"`
+	// ContentSanitized is set by SavePageContent when unsafe markup was removed
+	ContentSanitized bool `json:"content_sanitized,omitempty"`
+}
+
+type CreateViewerTokenRequest struct {
+	TTLSeconds int `json:"ttl_seconds,omitempty" validate:"omitempty,min=60,max=86400"`
+}
+
+type ViewerTokenResponse struct {
+	Token     string    `json:"token"`
+	PageID    string    `json:"page_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ShareLinkRequest configures a public read-only link. Without ExpiresAt the
+// link stays valid until revoked; with a Password viewers must supply it.
+type ShareLinkRequest struct {
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Password  string     `json:"password,omitempty" validate:"omitempty,min=4,max=72"`
+}
+
+type ShareLinkResponse struct {
+	ID          string     `json:"id"`
+	Token       string     `json:"token,omitempty"` // Only returned when the link is created
+	HasPassword bool       `json:"has_password"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	Expired     bool       `json:"expired"`
+	CreatedBy   *int64     `json:"created_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// SharedPageResponse is what a share link exposes: the page's content
+// without any internal IDs
+type SharedPageResponse struct {
+	Title     string                `json:"title"`
+	Icon      *string               `json:"icon,omitempty"`
+	CoverURL  *string               `json:"cover_url,omitempty"`
+	UpdatedAt time.Time             `json:"updated_at"`
+	Blocks    []SharedBlockResponse `json:"blocks"`
+}
+
+type SharedBlockResponse struct {
+	BlockType string          `json:"block_type"`
+	BlockData json.RawMessage `json:"block_data"`
+	Position  int             `json:"position"`
+}
+
+type MovePageRequest struct {
+	// NewParentID is the page to move under; nil moves the page to the workspace root
+	NewParentID *string `json:"new_parent_id,omitempty"`
+	// NewWorkspaceID moves the page and its descendants to another workspace
+	NewWorkspaceID *int64 `json:"new_workspace_id,omitempty"`
+}
+
+type DuplicatePageRequest struct {
+	// Recursive also duplicates every child page beneath the page
+	Recursive bool `json:"recursive"`
+}
+
+type InstantiateTemplateRequest struct {
+	// WorkspaceID defaults to the template's workspace
+	WorkspaceID int64   `json:"workspace_id,omitempty"`
+	ParentID    *string `json:"parent_id,omitempty"`
+	// Title defaults to the template's title with placeholders filled in
+	Title string `json:"title,omitempty" validate:"omitempty,max=500"`
+	// Variables adds to or overrides the built-in {{date}}, {{time}},
+	// {{datetime}}, {{weekday}} and {{title}} placeholders
+	Variables map[string]string `json:"variables,omitempty" validate:"omitempty,max=50,dive,keys,max=64,endkeys,max=1000"`
+}
+
+// ArchivePageRequest and RestorePageRequest apply to the page's descendants
+// too unless Cascade is explicitly false
+type ArchivePageRequest struct {
+	Cascade *bool `json:"cascade,omitempty"`
+}
+
+type RestorePageRequest struct {
+	Cascade *bool `json:"cascade,omitempty"`
+}
+
+type BulkPagesRequest struct {
+	PageIDs []string `json:"page_ids" validate:"required,min=1,max=100,dive,required"`
+	// IncludeDescendants also archives every page beneath the listed ones.
+	// Bulk delete always trashes descendants, like a single delete does.
+	// Either way a page is skipped if the user cannot change all of them
+	IncludeDescendants bool `json:"include_descendants,omitempty"`
+}
+
+// BulkPagesResponse lists the IDs of the requested pages that were changed
+// and, for the others, why they were skipped
+type BulkPagesResponse struct {
+	BatchResult[string]
+	// Affected counts every page changed, including descendants
+	Affected int64 `json:"affected"`
+}
+
+type RepairOrphanedPagesRequest struct {
+	// ParentID is the page the orphans are moved under; nil moves them to the root
+	ParentID *string `json:"parent_id,omitempty"`
+}
+
+type RepairOrphanedPagesResponse struct {
+	RepairedCount int64   `json:"repaired_count"`
+	ParentID      *string `json:"parent_id,omitempty"`
 }
 
 type CreateBlockRequest struct {
@@ -271,6 +486,19 @@ type SavePageContentRequest struct {
 	Content json.RawMessage `json:"content" validate:"required"` // EditorJS format
 }
 
+type ContentValidationIssue struct {
+	BlockIndex *int   `json:"block_index,omitempty"`
+	BlockID    string `json:"block_id,omitempty"`
+	Field      string `json:"field,omitempty"`
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+}
+
+type ValidateContentResponse struct {
+	Valid  bool                     `json:"valid"`
+	Issues []ContentValidationIssue `json:"issues"`
+}
+
 type CreateCommentRequest struct {
 	PageID          string  `json:"page_id" validate:"required"`
 	BlockID         *string `json:"block_id,omitempty"`
@@ -299,6 +527,43 @@ type CommentResponse struct {
 	Replies         []CommentResponse `json:"replies,omitempty"`
 }
 
+type NotificationResponse struct {
+	ID        string     `json:"id"`
+	Type      string     `json:"type"`
+	ActorID   *int64     `json:"actor_id,omitempty"`
+	ActorName string     `json:"actor_name,omitempty"`
+	PageID    *string    `json:"page_id,omitempty"`
+	CommentID *string    `json:"comment_id,omitempty"`
+	IsRead    bool       `json:"is_read"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type NotificationPreferencesResponse struct {
+	EmailPageAccessGranted bool `json:"email_page_access_granted"`
+	EmailPageAccessRevoked bool `json:"email_page_access_revoked"`
+	EmailNewDeviceLogin    bool `json:"email_new_device_login"`
+}
+
+// UpdateNotificationPreferencesRequest leaves switches it omits unchanged
+type UpdateNotificationPreferencesRequest struct {
+	EmailPageAccessGranted *bool `json:"email_page_access_granted,omitempty"`
+	EmailPageAccessRevoked *bool `json:"email_page_access_revoked,omitempty"`
+	EmailNewDeviceLogin    *bool `json:"email_new_device_login,omitempty"`
+}
+
+type AIUsageResponse struct {
+	PromptTokens     int64     `json:"prompt_tokens"`
+	CompletionTokens int64     `json:"completion_tokens"`
+	UsedTokens       int64     `json:"used_tokens"`
+	RequestCount     int64     `json:"request_count"`
+	Unlimited        bool      `json:"unlimited"`
+	Limit            int64     `json:"limit"`
+	Remaining        int64     `json:"remaining"`
+	PeriodStart      time.Time `json:"period_start"`
+	PeriodEnd        time.Time `json:"period_end"`
+}
+
 type GrantPagePermissionRequest struct {
 	UserID     int64  `json:"user_id" validate:"required"`
 	Permission string `json:"permission" validate:"required,oneof=view comment edit admin"`
@@ -316,6 +581,21 @@ type PagePermissionResponse struct {
 	UpdatedAt  time.Time `json:"updated_at"`
 }
 
+// Access sources reported by the effective access list
+const (
+	AccessSourceOwner            = "owner"
+	AccessSourceExplicit         = "explicit"
+	AccessSourceWorkspaceDefault = "workspace_default"
+)
+
+type EffectiveAccessResponse struct {
+	UserID     int64  `json:"user_id"`
+	Username   string `json:"username"`
+	Email      string `json:"email"`
+	Permission string `json:"permission"`
+	Source     string `json:"source"`
+}
+
 type PageVersionResponse struct {
 	ID            string          `json:"id"`
 	PageID        string          `json:"page_id"`
@@ -327,14 +607,72 @@ type PageVersionResponse struct {
 	CreatedAt     time.Time       `json:"created_at"`
 }
 
+const (
+	SearchPaginationOffset = "offset"
+	SearchPaginationCursor = "cursor"
+)
+
+// SearchPagesRequest searches a workspace by title text and filters. With an
+// empty query the filtered pages are listed most recently updated first.
 type SearchPagesRequest struct {
 	WorkspaceID int64  `json:"workspace_id" validate:"required"`
-	Query       string `json:"query" validate:"required,min=1"`
+	Query       string `json:"query"`
 	Limit       int    `json:"limit" validate:"min=1,max=100"`
 	Offset      int    `json:"offset" validate:"min=0"`
+	// Archived pages are left out unless IncludeArchived is set
+	IncludeArchived bool       `json:"include_archived,omitempty"`
+	IsTemplate      *bool      `json:"is_template,omitempty"`
+	OwnerID         *int64     `json:"owner_id,omitempty"`
+	UpdatedAfter    *time.Time `json:"updated_after,omitempty"`  // Inclusive
+	UpdatedBefore   *time.Time `json:"updated_before,omitempty"` // Exclusive
+	// Pagination selects "offset" (default, ranked by relevance) or "cursor"
+	// (ordered by last update). Cursor mode ignores Offset and reads Cursor
+	Pagination string `json:"pagination,omitempty" validate:"omitempty,oneof=offset cursor"`
+	Cursor     string `json:"cursor,omitempty"`
+}
+
+// SearchHit points at the block that best matches a text search, for
+// jumping straight to it. Snippet is HTML-escaped text around the match with
+// the matched words wrapped in <mark>, so it is safe to render as HTML.
+type SearchHit struct {
+	BlockID string `json:"block_id"`
+	Snippet string `json:"snippet"`
 }
 
 type SearchPagesResponse struct {
+	Pages      []PageResponse `json:"pages"`
+	Total      int64          `json:"total"`
+	Limit      int            `json:"limit"`
+	Offset     int            `json:"offset"`
+	NextCursor string         `json:"next_cursor,omitempty"` // Only set in cursor mode
+}
+
+// PropertyFilterRequest matches pages on one top-level property. Value is any
+// JSON value for eq and neq, a scalar for contains (array membership, or
+// case-insensitive substring for strings), a number or an ISO 8601 date for
+// gt, gte, lt and lte, and is ignored for exists.
+type PropertyFilterRequest struct {
+	Property string          `json:"property" validate:"required,max=255"`
+	Operator string          `json:"operator" validate:"required,oneof=eq neq contains gt gte lt lte exists"`
+	Value    json.RawMessage `json:"value,omitempty"`
+}
+
+type PropertySortRequest struct {
+	Property  string `json:"property" validate:"required,max=255"`
+	Direction string `json:"direction,omitempty" validate:"omitempty,oneof=asc desc"` // Defaults to asc
+}
+
+// QueryPagesRequest filters a workspace's live pages by their properties.
+// Filters are combined with AND; without a sort the most recently updated
+// pages come first.
+type QueryPagesRequest struct {
+	Filters []PropertyFilterRequest `json:"filters,omitempty" validate:"max=20,dive"`
+	Sort    *PropertySortRequest    `json:"sort,omitempty"`
+	Limit   int                     `json:"limit" validate:"min=0,max=100"` // Defaults to 50
+	Offset  int                     `json:"offset" validate:"min=0"`
+}
+
+type QueryPagesResponse struct {
 	Pages  []PageResponse `json:"pages"`
 	Total  int64          `json:"total"`
 	Limit  int            `json:"limit"`
@@ -74,6 +74,34 @@ type WelcomeEmailData struct {
 	DashboardURL string
 }
 
+type WorkspaceInvitationEmailData struct {
+	EmailData
+	InviterName    string
+	WorkspaceName  string
+	Role           string
+	AcceptLink     string
+	HasAccount     bool
+	ExpirationDays int
+}
+
+type PageAccessEmailData struct {
+	EmailData
+	Granted    bool
+	ActorName  string
+	PageTitle  string
+	Permission string
+	PageLink   string
+}
+
+type NewDeviceLoginEmailData struct {
+	EmailData
+	Username    string
+	DeviceLabel string
+	IPAddress   string
+	SignInTime  string
+	SecurityURL string
+}
+
 type PasswordChangeEmailData struct {
 	EmailData
 	Username   string
@@ -82,6 +110,12 @@ type PasswordChangeEmailData struct {
 	SupportURL string
 }
 
+type PasswordChangeOTPEmailData struct {
+	EmailData
+	Title string
+	OTP   string
+}
+
 func NewEmailService(
 	config *config.EmailConfig,
 	userRepo repository.UserRepository,
@@ -121,10 +155,19 @@ func (s *EmailServiceImpl) SendVerificationEmail(ctx context.Context, userID int
 		return errors.NewInternalError("Failed to generate verification token").WithCause(err)
 	}
 
+	// Earlier links stop working once a new one is sent
+	if err := s.tokenRepo.DeleteUserTokensByType(ctx, userID, string(TokenTypeEmailVerification)); err != nil {
+		s.logger.Error("Failed to delete previous verification tokens",
+			"user_id", userID,
+			"error", err)
+		return errors.NewInternalError("Failed to generate verification token").WithCause(err)
+	}
+
+	// Only the hash is stored, matching VerificationTokenService.ValidateToken
 	token := &repository.VerificationToken{
 		UserID:    userID,
-		Token:     tokenString,
-		TokenType: "email_verification",
+		Token:     hashVerificationToken(tokenString),
+		TokenType: string(TokenTypeEmailVerification),
 		ExpiresAt: time.Now().Add(24 * time.Hour),
 		CreatedAt: time.Now(),
 		IsUsed:    false,
@@ -201,6 +244,21 @@ func (s *EmailServiceImpl) SendPasswordChangeNotification(ctx context.Context, u
 	return s.sendEmailWithRetry(ctx, []string{email}, "Password Changed Successfully", "password_change_otp.html", data, 3)
 }
 
+func (s *EmailServiceImpl) SendPasswordChangeOTPEmail(ctx context.Context, email, otp string) error {
+	data := PasswordChangeOTPEmailData{
+		EmailData: EmailData{
+			AppName:      "Lumen",
+			BaseURL:      s.getBaseURL(),
+			SupportEmail: s.config.FromEmail,
+			Year:         time.Now().Year(),
+		},
+		Title: "Password Change Verification",
+		OTP:   otp,
+	}
+
+	return s.sendEmailWithRetry(ctx, []string{email}, "Your Password Change Code", "password_change_otp.html", data, 3)
+}
+
 func (s *EmailServiceImpl) SendWelcomeEmail(ctx context.Context, userID int64, email, username string) error {
 	data := WelcomeEmailData{
 		EmailData: EmailData{
@@ -217,6 +275,72 @@ func (s *EmailServiceImpl) SendWelcomeEmail(ctx context.Context, userID int64, e
 	return s.sendEmailWithRetry(ctx, []string{email}, "Welcome to Lumen", "welcome.html", data, 3)
 }
 
+func (s *EmailServiceImpl) SendWorkspaceInvitationEmail(ctx context.Context, email string, invitation *WorkspaceInvitationEmail) error {
+	data := WorkspaceInvitationEmailData{
+		EmailData: EmailData{
+			AppName:      "Lumen",
+			BaseURL:      s.getBaseURL(),
+			SupportEmail: s.config.FromEmail,
+			Year:         time.Now().Year(),
+		},
+		InviterName:    invitation.InviterName,
+		WorkspaceName:  invitation.WorkspaceName,
+		Role:           invitation.Role,
+		AcceptLink:     fmt.Sprintf("%s/invitations/accept?token=%s", s.getBaseURL(), invitation.Token),
+		HasAccount:     invitation.HasAccount,
+		ExpirationDays: invitation.ExpirationDays,
+	}
+
+	subject := fmt.Sprintf("%s invited you to %s", invitation.InviterName, invitation.WorkspaceName)
+	return s.sendEmailWithRetry(ctx, []string{email}, subject, "workspace_invitation.html", data, 3)
+}
+
+func (s *EmailServiceImpl) SendPageAccessEmail(ctx context.Context, email string, notice *PageAccessEmail) error {
+	pageTitle := notice.PageTitle
+	if pageTitle == "" {
+		pageTitle = "Untitled"
+	}
+
+	data := PageAccessEmailData{
+		EmailData: EmailData{
+			AppName:      "Lumen",
+			BaseURL:      s.getBaseURL(),
+			SupportEmail: s.config.FromEmail,
+			Year:         time.Now().Year(),
+		},
+		Granted:    notice.Granted,
+		ActorName:  notice.ActorName,
+		PageTitle:  pageTitle,
+		Permission: notice.Permission,
+		PageLink:   fmt.Sprintf("%s/dashboard/notes?page=%s", s.getBaseURL(), notice.PageID),
+	}
+
+	subject := fmt.Sprintf("%s shared \"%s\" with you", notice.ActorName, pageTitle)
+	if !notice.Granted {
+		subject = fmt.Sprintf("Your access to \"%s\" was removed", pageTitle)
+	}
+	return s.sendEmailWithRetry(ctx, []string{email}, subject, "page_access.html", data, 3)
+}
+
+func (s *EmailServiceImpl) SendNewDeviceLoginEmail(ctx context.Context, email string, notice *NewDeviceLoginEmail) error {
+	data := NewDeviceLoginEmailData{
+		EmailData: EmailData{
+			AppName:      "Lumen",
+			BaseURL:      s.getBaseURL(),
+			SupportEmail: s.config.FromEmail,
+			Year:         time.Now().Year(),
+		},
+		Username:    notice.Username,
+		DeviceLabel: notice.DeviceLabel,
+		IPAddress:   notice.IPAddress,
+		SignInTime:  notice.SignedInAt.UTC().Format("January 2, 2006 at 3:04 PM MST"),
+		SecurityURL: fmt.Sprintf("%s/dashboard/settings", s.getBaseURL()),
+	}
+
+	subject := fmt.Sprintf("New sign-in from %s", notice.DeviceLabel)
+	return s.sendEmailWithRetry(ctx, []string{email}, subject, "new_device_login.html", data, 3)
+}
+
 func (s *EmailServiceImpl) RenderTemplate(templateName string, data interface{}) (string, error) {
 	template, exists := s.templates[templateName]
 	if !exists {
@@ -306,6 +430,9 @@ func (s *EmailServiceImpl) sendEmail(ctx context.Context, to []string, subject,
 
 	auth := smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)
 
+	// Subjects can carry user-chosen names, so keep them on one header line
+	subject = strings.NewReplacer("\r", " ", "\n", " ").Replace(subject)
+
 	from := fmt.Sprintf("%s <%s>", s.config.FromName, s.config.FromEmail)
 	headers := map[string]string{
 		"From":         from,
@@ -337,7 +464,11 @@ func (s *EmailServiceImpl) loadTemplates() error {
 	templateFiles := []string{
 		"verification.html",
 		"password_reset.html",
+		"password_change_otp.html",
 		"welcome.html",
+		"workspace_invitation.html",
+		"page_access.html",
+		"new_device_login.html",
 	}
 
 	for _, filename := range templateFiles {
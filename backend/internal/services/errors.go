@@ -44,6 +44,10 @@ func NewTokenRevokedError() *errors.AppError {
 	return errors.NewAuthenticationError("Token has been revoked")
 }
 
+func NewTokenReuseDetectedError() *errors.AppError {
+	return errors.NewAuthenticationError("Refresh token reuse detected, all sessions have been signed out")
+}
+
 func NewInvalidRefreshTokenError() *errors.AppError {
 	return errors.NewAuthenticationError("Invalid refresh token")
 }
@@ -198,7 +202,7 @@ func NewQuotaExceededError(quotaType string, limit int64) *errors.AppError {
 		errors.ValidationError,
 		"Quota exceeded",
 		fmt.Sprintf("%s quota of %d exceeded", quotaType, limit),
-		http.StatusPaymentRequired,
+		http.StatusTooManyRequests,
 	)
 }
 
@@ -0,0 +1,860 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/errors"
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+// The fakes below embed the repository interface they stand in for, so a
+// test only has to implement the methods the code under test calls. Anything
+// else panics on the nil embedded value, which flags an unexpected call.
+// Missing rows are reported the way the postgres repositories report them.
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+type fakeUserRepo struct {
+	repository.UserRepository
+	mu     sync.Mutex
+	nextID int64
+	users  map[int64]*repository.User
+}
+
+func newFakeUserRepo() *fakeUserRepo {
+	return &fakeUserRepo{users: make(map[int64]*repository.User)}
+}
+
+func (r *fakeUserRepo) Create(ctx context.Context, user *repository.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	user.ID = r.nextID
+	stored := *user
+	r.users[user.ID] = &stored
+	return nil
+}
+
+func (r *fakeUserRepo) GetByID(ctx context.Context, id int64) (*repository.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[id]
+	if !ok {
+		return nil, errors.NewNotFoundError("users")
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (r *fakeUserRepo) GetByEmail(ctx context.Context, email string) (*repository.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, user := range r.users {
+		if user.Email == email {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, errors.NewNotFoundError("users")
+}
+
+func (r *fakeUserRepo) Update(ctx context.Context, user *repository.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := *user
+	r.users[user.ID] = &stored
+	return nil
+}
+
+func (r *fakeUserRepo) ExistsByEmail(ctx context.Context, email string) (bool, error) {
+	user, _ := r.GetByEmail(ctx, email)
+	return user != nil, nil
+}
+
+func (r *fakeUserRepo) ExistsByUsername(ctx context.Context, username string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, user := range r.users {
+		if user.Username == username {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type fakeRoleRepo struct {
+	repository.RoleRepository
+	mu        sync.Mutex
+	roles     map[string]*repository.Role
+	userRoles map[int64][]string
+}
+
+func newFakeRoleRepo(names ...string) *fakeRoleRepo {
+	r := &fakeRoleRepo{
+		roles:     make(map[string]*repository.Role),
+		userRoles: make(map[int64][]string),
+	}
+	for i, name := range names {
+		r.roles[name] = &repository.Role{ID: int64(i + 1), Name: name}
+	}
+	return r
+}
+
+func (r *fakeRoleRepo) GetByName(ctx context.Context, name string) (*repository.Role, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	role, ok := r.roles[name]
+	if !ok {
+		return nil, errors.NewNotFoundError("roles")
+	}
+	return role, nil
+}
+
+func (r *fakeRoleRepo) AssignRoleToUser(ctx context.Context, userID, roleID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, role := range r.roles {
+		if role.ID == roleID {
+			r.userRoles[userID] = append(r.userRoles[userID], role.Name)
+		}
+	}
+	return nil
+}
+
+func (r *fakeRoleRepo) GetUserRoles(ctx context.Context, userID int64) ([]*repository.Role, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var roles []*repository.Role
+	for _, name := range r.userRoles[userID] {
+		roles = append(roles, r.roles[name])
+	}
+	return roles, nil
+}
+
+func (r *fakeRoleRepo) rolesOf(userID int64) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.userRoles[userID]...)
+}
+
+type fakeVerificationTokenRepo struct {
+	repository.VerificationTokenRepository
+	mu       sync.Mutex
+	nextID   int64
+	tokens   map[int64]*repository.VerificationToken
+	failures map[int64]int
+}
+
+func newFakeVerificationTokenRepo() *fakeVerificationTokenRepo {
+	return &fakeVerificationTokenRepo{
+		tokens:   make(map[int64]*repository.VerificationToken),
+		failures: make(map[int64]int),
+	}
+}
+
+func (r *fakeVerificationTokenRepo) Create(ctx context.Context, token interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t := token.(*repository.VerificationToken)
+	r.nextID++
+	t.ID = r.nextID
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now()
+	}
+	stored := *t
+	r.tokens[t.ID] = &stored
+	return nil
+}
+
+func (r *fakeVerificationTokenRepo) GetByToken(ctx context.Context, tokenString, tokenType string) (interface{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range r.tokens {
+		if t.Token == tokenString && t.TokenType == tokenType {
+			copied := *t
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeVerificationTokenRepo) GetByUserID(ctx context.Context, userID int64, tokenType string) (interface{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var latest *repository.VerificationToken
+	for _, t := range r.tokens {
+		if t.UserID == userID && t.TokenType == tokenType && !t.IsUsed && (latest == nil || t.ID > latest.ID) {
+			latest = t
+		}
+	}
+	if latest == nil {
+		return nil, errors.NewNotFoundError("verification_tokens")
+	}
+	copied := *latest
+	return &copied, nil
+}
+
+func (r *fakeVerificationTokenRepo) MarkAsUsed(ctx context.Context, tokenID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tokens[tokenID]
+	if !ok || t.IsUsed {
+		return errors.NewNotFoundError("verification_tokens")
+	}
+	t.IsUsed = true
+	return nil
+}
+
+func (r *fakeVerificationTokenRepo) DeleteUserTokensByType(ctx context.Context, userID int64, tokenType string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, t := range r.tokens {
+		if t.UserID == userID && t.TokenType == tokenType {
+			delete(r.tokens, id)
+		}
+	}
+	return nil
+}
+
+func (r *fakeVerificationTokenRepo) InvalidateUserTokensByType(ctx context.Context, userID int64, tokenType string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range r.tokens {
+		if t.UserID == userID && t.TokenType == tokenType {
+			t.IsUsed = true
+		}
+	}
+	return nil
+}
+
+func (r *fakeVerificationTokenRepo) RecordFailedAttempt(ctx context.Context, tokenID int64, maxAttempts int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tokens[tokenID]
+	if !ok || t.IsUsed {
+		return 0, errors.NewNotFoundError("verification_tokens")
+	}
+	r.failures[tokenID]++
+	if r.failures[tokenID] >= maxAttempts {
+		t.IsUsed = true
+	}
+	return r.failures[tokenID], nil
+}
+
+// expire moves every token's expiry into the past
+func (r *fakeVerificationTokenRepo) expire() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range r.tokens {
+		t.ExpiresAt = time.Now().Add(-time.Minute)
+	}
+}
+
+func (r *fakeVerificationTokenRepo) CountCreatedSince(ctx context.Context, userID int64, tokenType string, since time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	count := 0
+	for _, t := range r.tokens {
+		if t.UserID == userID && t.TokenType == tokenType && !t.CreatedAt.Before(since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// fakeEmailService records verification emails instead of sending them
+type fakeEmailService struct {
+	EmailService
+	verification chan int64
+}
+
+func newFakeEmailService() *fakeEmailService {
+	return &fakeEmailService{verification: make(chan int64, 16)}
+}
+
+func (s *fakeEmailService) SendVerificationEmail(ctx context.Context, userID int64, email string) error {
+	s.verification <- userID
+	return nil
+}
+
+func (s *fakeEmailService) SendPasswordChangeNotification(ctx context.Context, userID int64, email string) error {
+	return nil
+}
+
+type fakeWorkspaceRepo struct {
+	repository.WorkspaceRepository
+	mu         sync.Mutex
+	workspaces map[int64]*repository.Workspace
+	members    []*repository.WorkspaceMember
+	schemas    map[int64]json.RawMessage
+}
+
+func newFakeWorkspaceRepo(workspaces ...*repository.Workspace) *fakeWorkspaceRepo {
+	r := &fakeWorkspaceRepo{workspaces: make(map[int64]*repository.Workspace)}
+	for _, workspace := range workspaces {
+		r.workspaces[workspace.ID] = workspace
+	}
+	return r
+}
+
+func (r *fakeWorkspaceRepo) GetByID(ctx context.Context, id int64) (*repository.Workspace, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.workspaces[id], nil
+}
+
+func (r *fakeWorkspaceRepo) AddMember(ctx context.Context, member *repository.WorkspaceMember) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.members = append(r.members, member)
+	return nil
+}
+
+func (r *fakeWorkspaceRepo) GetMember(ctx context.Context, workspaceID, userID int64) (*repository.WorkspaceMember, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, member := range r.members {
+		if member.WorkspaceID == workspaceID && member.UserID == userID {
+			return member, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeWorkspaceRepo) HasAccess(ctx context.Context, workspaceID, userID int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if workspace, ok := r.workspaces[workspaceID]; ok && workspace.OwnerID == userID {
+		return true, nil
+	}
+	for _, member := range r.members {
+		if member.WorkspaceID == workspaceID && member.UserID == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetPropertySchema returns the schema set in schemas, if any
+func (r *fakeWorkspaceRepo) GetPropertySchema(ctx context.Context, workspaceID int64) (json.RawMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.schemas[workspaceID], nil
+}
+
+// RemoveMemberAndTransferPages only drops the membership; page effects are
+// covered by the postgres repository tests
+func (r *fakeWorkspaceRepo) RemoveMemberAndTransferPages(ctx context.Context, workspaceID, userID, newOwnerID int64) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	kept := r.members[:0]
+	for _, member := range r.members {
+		if member.WorkspaceID != workspaceID || member.UserID != userID {
+			kept = append(kept, member)
+		}
+	}
+	r.members = kept
+	return 0, nil
+}
+
+func (r *fakeWorkspaceRepo) GetMembers(ctx context.Context, workspaceID int64) ([]*repository.WorkspaceMember, error) {
+	return r.membersOf(workspaceID), nil
+}
+
+func (r *fakeWorkspaceRepo) membersOf(workspaceID int64) []*repository.WorkspaceMember {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var members []*repository.WorkspaceMember
+	for _, member := range r.members {
+		if member.WorkspaceID == workspaceID {
+			members = append(members, member)
+		}
+	}
+	return members
+}
+
+type fakeIdentityRepo struct {
+	repository.UserIdentityRepository
+	mu         sync.Mutex
+	identities []*repository.UserIdentity
+}
+
+func (r *fakeIdentityRepo) Create(ctx context.Context, identity *repository.UserIdentity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.identities = append(r.identities, identity)
+	return nil
+}
+
+func (r *fakeIdentityRepo) GetByProviderSubject(ctx context.Context, provider, subject string) (*repository.UserIdentity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, identity := range r.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return nil, nil
+}
+
+// fakeTokenRepo stores refresh tokens and records which users had all their
+// tokens revoked
+type fakeTokenRepo struct {
+	repository.TokenRepository
+	mu      sync.Mutex
+	nextID  int64
+	tokens  map[string]*repository.Token
+	revoked []int64
+}
+
+func (r *fakeTokenRepo) Create(ctx context.Context, token *repository.Token) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.tokens == nil {
+		r.tokens = make(map[string]*repository.Token)
+	}
+	r.nextID++
+	token.ID = r.nextID
+	if token.FamilyID == "" {
+		token.FamilyID = fmt.Sprintf("family-%d", token.ID)
+	}
+	stored := *token
+	r.tokens[token.Token] = &stored
+	return nil
+}
+
+func (r *fakeTokenRepo) GetByToken(ctx context.Context, tokenString string) (*repository.Token, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token, ok := r.tokens[tokenString]
+	if !ok {
+		return nil, errors.NewNotFoundError("token")
+	}
+	copied := *token
+	return &copied, nil
+}
+
+func (r *fakeTokenRepo) MarkRotated(ctx context.Context, tokenString string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token, ok := r.tokens[tokenString]
+	if !ok || token.RotatedAt != nil {
+		return false, nil
+	}
+	now := time.Now().UTC()
+	token.RotatedAt = &now
+	return true, nil
+}
+
+func (r *fakeTokenRepo) HasRotatedSuccessor(ctx context.Context, familyID string, tokenID int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, token := range r.tokens {
+		if token.FamilyID == familyID && token.ID > tokenID && token.RotatedAt != nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeTokenRepo) RevokeAllUserTokens(ctx context.Context, userID int64, tokenType string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, token := range r.tokens {
+		if token.UserID == userID {
+			delete(r.tokens, key)
+		}
+	}
+	r.revoked = append(r.revoked, userID)
+	return nil
+}
+
+// rotatedAgo backdates a token's rotation
+func (r *fakeTokenRepo) rotatedAgo(tokenString string, d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rotatedAt := time.Now().UTC().Add(-d)
+	r.tokens[tokenString].RotatedAt = &rotatedAt
+}
+
+func (r *fakeTokenRepo) revokedUsers() []int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]int64(nil), r.revoked...)
+}
+
+type fakeActivityService struct {
+	ActivityService
+}
+
+func (fakeActivityService) Record(event ActivityEvent) {}
+
+// fakePageRepo keeps live pages in memory. Permissions follow the postgres
+// precedence without workspace defaults: owners have every level, other users
+// only what they were granted.
+type fakePageRepo struct {
+	repository.PageRepository
+	mu          sync.Mutex
+	pages       map[string]*repository.Page
+	permissions map[string]map[int64]repository.PermissionLevel
+	archived    []string
+	restored    []string
+	deleted     []string
+	searches    int
+}
+
+func newFakePageRepo(pages ...*repository.Page) *fakePageRepo {
+	r := &fakePageRepo{
+		pages:       make(map[string]*repository.Page),
+		permissions: make(map[string]map[int64]repository.PermissionLevel),
+	}
+	for _, page := range pages {
+		r.pages[page.ID] = page
+	}
+	return r
+}
+
+// ListPermissions returns grants ordered by user so results are stable
+func (r *fakePageRepo) ListPermissions(ctx context.Context, pageID string) ([]*repository.PagePermission, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	permissions := make([]*repository.PagePermission, 0, len(r.permissions[pageID]))
+	for userID, level := range r.permissions[pageID] {
+		permissions = append(permissions, &repository.PagePermission{PageID: pageID, UserID: userID, Permission: level})
+	}
+	sort.Slice(permissions, func(i, j int) bool { return permissions[i].UserID < permissions[j].UserID })
+	return permissions, nil
+}
+
+// GetTree returns the live pages userID owns or was granted, parents first.
+// It ignores the depth limit and the workspace default permission.
+func (r *fakePageRepo) GetTree(ctx context.Context, workspaceID int64, userID int64, maxDepth int, includeArchived, includeTemplates bool) ([]*repository.PageTreeEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var entries []*repository.PageTreeEntry
+	for _, page := range r.pages {
+		if page.WorkspaceID != workspaceID || page.DeletedAt != nil {
+			continue
+		}
+		if _, granted := r.permissions[page.ID][userID]; page.OwnerID != userID && !granted {
+			continue
+		}
+		depth := 1
+		for parent := page.ParentID; parent != nil && r.pages[*parent] != nil; parent = r.pages[*parent].ParentID {
+			depth++
+		}
+		entries = append(entries, &repository.PageTreeEntry{Page: *page, Depth: depth})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Depth != entries[j].Depth {
+			return entries[i].Depth < entries[j].Depth
+		}
+		return entries[i].ID < entries[j].ID
+	})
+	return entries, nil
+}
+
+func (r *fakePageRepo) grant(pageID string, userID int64, level repository.PermissionLevel) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.permissions[pageID] == nil {
+		r.permissions[pageID] = make(map[int64]repository.PermissionLevel)
+	}
+	r.permissions[pageID][userID] = level
+}
+
+var fakePermissionRank = map[repository.PermissionLevel]int{
+	repository.PermissionView:    1,
+	repository.PermissionComment: 2,
+	repository.PermissionEdit:    3,
+	repository.PermissionAdmin:   4,
+}
+
+func (r *fakePageRepo) Create(ctx context.Context, page *repository.Page) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if page.ID == "" {
+		page.ID = fmt.Sprintf("page-%d", len(r.pages)+1)
+	}
+	stored := *page
+	r.pages[page.ID] = &stored
+	return nil
+}
+
+func (r *fakePageRepo) GetByID(ctx context.Context, id string) (*repository.Page, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	page, ok := r.pages[id]
+	if !ok || page.DeletedAt != nil {
+		return nil, nil
+	}
+	copied := *page
+	return &copied, nil
+}
+
+func (r *fakePageRepo) HasPermission(ctx context.Context, pageID string, userID int64, requiredLevel repository.PermissionLevel) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	page, ok := r.pages[pageID]
+	if !ok || page.DeletedAt != nil {
+		return false, nil
+	}
+	if page.OwnerID == userID {
+		return true, nil
+	}
+	level, ok := r.permissions[pageID][userID]
+	return ok && fakePermissionRank[level] >= fakePermissionRank[requiredLevel], nil
+}
+
+func (r *fakePageRepo) Archive(ctx context.Context, id string, cascade bool, archivedBy int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.archived = append(r.archived, id)
+	return nil
+}
+
+func (r *fakePageRepo) Restore(ctx context.Context, id string, cascade bool, restoredBy int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.restored = append(r.restored, id)
+	return nil
+}
+
+// SearchCount counts pages whose title contains the query, ignoring filters
+func (r *fakePageRepo) SearchCount(ctx context.Context, workspaceID int64, userID int64, query string, filters repository.SearchFilters) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var total int64
+	for _, page := range r.pages {
+		if page.WorkspaceID == workspaceID && page.DeletedAt == nil && strings.Contains(strings.ToLower(page.Title), strings.ToLower(query)) {
+			total++
+		}
+	}
+	return total, nil
+}
+
+// Search only records the call; tests use it to check the page query is skipped
+func (r *fakePageRepo) Search(ctx context.Context, workspaceID int64, userID int64, query string, filters repository.SearchFilters, limit, offset int) ([]*repository.Page, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.searches++
+	return nil, nil
+}
+
+func (r *fakePageRepo) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deleted = append(r.deleted, id)
+	return nil
+}
+
+// ListTrash mirrors the postgres filter: trashed pages the user owns or holds
+// an explicit admin grant on
+func (r *fakePageRepo) ListTrash(ctx context.Context, workspaceID int64, userID int64) ([]*repository.Page, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var pages []*repository.Page
+	for _, page := range r.pages {
+		if page.WorkspaceID != workspaceID || page.DeletedAt == nil {
+			continue
+		}
+		if page.OwnerID == userID || r.permissions[page.ID][userID] == repository.PermissionAdmin {
+			copied := *page
+			pages = append(pages, &copied)
+		}
+	}
+	return pages, nil
+}
+
+func (r *fakePageRepo) BulkArchive(ctx context.Context, ids []string, includeDescendants bool, archivedBy int64) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.archived = append(r.archived, ids...)
+	return int64(len(ids)), nil
+}
+
+func (r *fakePageRepo) BulkDelete(ctx context.Context, ids []string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deleted = append(r.deleted, ids...)
+	return int64(len(ids)), nil
+}
+
+func (r *fakePageRepo) GetDescendantIDs(ctx context.Context, ids []string) (map[string][]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	descendants := make(map[string][]string, len(ids))
+	for _, rootID := range ids {
+		visited := map[string]bool{rootID: true}
+		frontier := []string{rootID}
+		for len(frontier) > 0 {
+			parentID := frontier[0]
+			frontier = frontier[1:]
+			for _, page := range r.pages {
+				if page.ParentID != nil && *page.ParentID == parentID && page.DeletedAt == nil && !visited[page.ID] {
+					visited[page.ID] = true
+					descendants[rootID] = append(descendants[rootID], page.ID)
+					frontier = append(frontier, page.ID)
+				}
+			}
+		}
+	}
+	return descendants, nil
+}
+
+func (r *fakePageRepo) GetUserPermissionLevels(ctx context.Context, userID int64, pageIDs []string) (map[string]repository.PermissionLevel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	levels := make(map[string]repository.PermissionLevel, len(pageIDs))
+	for _, id := range pageIDs {
+		page, ok := r.pages[id]
+		if !ok || page.DeletedAt != nil {
+			continue
+		}
+		if page.OwnerID == userID {
+			levels[id] = repository.PermissionAdmin
+		} else if level, ok := r.permissions[id][userID]; ok {
+			levels[id] = level
+		}
+	}
+	return levels, nil
+}
+
+// Move rejects cycles the way the postgres repository does
+func (r *fakePageRepo) Move(ctx context.Context, id string, newParentID *string, newWorkspaceID int64, movedBy int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	page, ok := r.pages[id]
+	if !ok || page.DeletedAt != nil {
+		return errors.NewNotFoundError("page")
+	}
+	if newParentID != nil {
+		visited := make(map[string]bool)
+		for ancestorID := newParentID; ancestorID != nil && !visited[*ancestorID]; {
+			if *ancestorID == id {
+				return errors.NewValidationError("Invalid page move", "would create a page cycle")
+			}
+			visited[*ancestorID] = true
+			ancestor, ok := r.pages[*ancestorID]
+			if !ok {
+				break
+			}
+			ancestorID = ancestor.ParentID
+		}
+	}
+	page.ParentID = newParentID
+	page.WorkspaceID = newWorkspaceID
+	return nil
+}
+
+func (r *fakePageRepo) CountChildren(ctx context.Context, parentIDs []string) (map[string]int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	counts := make(map[string]int, len(parentIDs))
+	for _, page := range r.pages {
+		if page.ParentID != nil && page.DeletedAt == nil && !page.IsArchived {
+			counts[*page.ParentID]++
+		}
+	}
+	return counts, nil
+}
+
+func (r *fakePageRepo) HasSiblingWithTitle(ctx context.Context, workspaceID int64, parentID *string, title string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	normalized := strings.ToLower(strings.TrimSpace(title))
+	for _, page := range r.pages {
+		sameParent := (page.ParentID == nil && parentID == nil) ||
+			(page.ParentID != nil && parentID != nil && *page.ParentID == *parentID)
+		if page.WorkspaceID == workspaceID && sameParent && !page.IsArchived && page.DeletedAt == nil &&
+			strings.ToLower(strings.TrimSpace(page.Title)) == normalized {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetOrphanedPages lists live pages whose parent row no longer exists
+func (r *fakePageRepo) GetOrphanedPages(ctx context.Context, workspaceID int64) ([]*repository.Page, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var orphans []*repository.Page
+	for _, page := range r.pages {
+		if page.WorkspaceID != workspaceID || page.DeletedAt != nil || page.ParentID == nil {
+			continue
+		}
+		if _, ok := r.pages[*page.ParentID]; !ok {
+			copied := *page
+			orphans = append(orphans, &copied)
+		}
+	}
+	return orphans, nil
+}
+
+func (r *fakePageRepo) ReparentOrphanedPages(ctx context.Context, workspaceID int64, newParentID *string, repairedBy int64) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var count int64
+	for _, page := range r.pages {
+		if page.WorkspaceID != workspaceID || page.DeletedAt != nil || page.ParentID == nil {
+			continue
+		}
+		if _, ok := r.pages[*page.ParentID]; !ok {
+			page.ParentID = newParentID
+			count++
+		}
+	}
+	return count, nil
+}
+
+// fakeBlockRepo keeps blocks in memory in insertion order
+type fakeBlockRepo struct {
+	repository.BlockRepository
+	mu     sync.Mutex
+	blocks []*repository.Block
+}
+
+func (r *fakeBlockRepo) GetByPageID(ctx context.Context, pageID string) ([]*repository.Block, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var blocks []*repository.Block
+	for _, block := range r.blocks {
+		if block.PageID == pageID {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks, nil
+}
+
+func (r *fakeBlockRepo) BulkCreate(ctx context.Context, blocks []*repository.Block) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.blocks = append(r.blocks, blocks...)
+	return nil
+}
+
+func (r *fakeBlockRepo) GetLeadingBlocks(ctx context.Context, pageIDs []string, limit int) (map[string][]*repository.Block, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	wanted := make(map[string]bool, len(pageIDs))
+	for _, id := range pageIDs {
+		wanted[id] = true
+	}
+	result := make(map[string][]*repository.Block)
+	for _, block := range r.blocks {
+		if wanted[block.PageID] && block.ParentBlockID == nil && len(result[block.PageID]) < limit {
+			result[block.PageID] = append(result[block.PageID], block)
+		}
+	}
+	return result, nil
+}
@@ -15,8 +15,11 @@ type UserService interface {
 	GetByID(ctx context.Context, userID int64) (*UserResponse, error)
 	GetByEmail(ctx context.Context, email string) (*UserResponse, error)
 
-	VerifyEmail(ctx context.Context, userID int64) error
+	VerifyEmail(ctx context.Context, token string) error
+	RequestEmailVerification(ctx context.Context, email string) error
 	IsEmailVerified(ctx context.Context, userID int64) (bool, error)
+
+	ApplyOnboardingRules(ctx context.Context, userID int64, email string)
 }
 
 type AuthService interface {
@@ -26,18 +29,27 @@ type AuthService interface {
 	RevokeToken(ctx context.Context, token string) error
 
 	ChangePassword(ctx context.Context, userID int64, req *ChangePasswordRequest) error
+	RequestPasswordChangeOTP(ctx context.Context, userID int64) error
+	ChangePasswordWithOTP(ctx context.Context, userID int64, req *ChangePasswordRequest, otp string) error
 	InitiatePasswordReset(ctx context.Context, email string) error
 	ResetPassword(ctx context.Context, req *ResetPasswordRequest) error
 
 	InvalidateAllSessions(ctx context.Context, userID int64) error
 	RevokeAllUserTokens(ctx context.Context, userID int64) error
+
+	ListSessions(ctx context.Context, userID int64, currentRefreshToken string) ([]SessionInfo, error)
+	RevokeSession(ctx context.Context, userID int64, sessionID int64) error
 }
 
 type EmailService interface {
 	SendVerificationEmail(ctx context.Context, userID int64, email string) error
 	SendPasswordResetEmail(ctx context.Context, userID int64, email string, resetToken string) error
 	SendPasswordChangeNotification(ctx context.Context, userID int64, email string) error
+	SendPasswordChangeOTPEmail(ctx context.Context, email, otp string) error
 	SendWelcomeEmail(ctx context.Context, userID int64, email, username string) error
+	SendWorkspaceInvitationEmail(ctx context.Context, email string, invitation *WorkspaceInvitationEmail) error
+	SendPageAccessEmail(ctx context.Context, email string, notice *PageAccessEmail) error
+	SendNewDeviceLoginEmail(ctx context.Context, email string, notice *NewDeviceLoginEmail) error
 
 	RenderTemplate(templateName string, data interface{}) (string, error)
 
@@ -81,6 +93,9 @@ type TokenPair struct {
 	ExpiresIn    int64     `json:"expires_in"`
 	TokenType    string    `json:"token_type"`
 	IssuedAt     time.Time `json:"issued_at"`
+	// Fingerprint is the raw value for the fingerprint cookie; empty when
+	// fingerprinting is disabled
+	Fingerprint string `json:"-"`
 }
 
 type TokenClaims struct {
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+type NotificationService interface {
+	GetNotifications(ctx context.Context, userID int64, unreadOnly bool, limit, offset int) ([]NotificationResponse, error)
+	MarkNotificationRead(ctx context.Context, userID int64, notificationID string) error
+
+	GetPreferences(ctx context.Context, userID int64) (*NotificationPreferencesResponse, error)
+	UpdatePreferences(ctx context.Context, userID int64, req *UpdateNotificationPreferencesRequest) (*NotificationPreferencesResponse, error)
+}
+
+type notificationService struct {
+	notificationRepo repository.NotificationRepository
+	preferenceRepo   repository.NotificationPreferenceRepository
+	userRepo         repository.UserRepository
+	logger           *slog.Logger
+}
+
+func NewNotificationService(
+	notificationRepo repository.NotificationRepository,
+	preferenceRepo repository.NotificationPreferenceRepository,
+	userRepo repository.UserRepository,
+	logger *slog.Logger,
+) NotificationService {
+	return &notificationService{
+		notificationRepo: notificationRepo,
+		preferenceRepo:   preferenceRepo,
+		userRepo:         userRepo,
+		logger:           logger,
+	}
+}
+
+func (s *notificationService) GetNotifications(ctx context.Context, userID int64, unreadOnly bool, limit, offset int) ([]NotificationResponse, error) {
+	notifications, err := s.notificationRepo.ListByUser(ctx, userID, unreadOnly, limit, offset)
+	if err != nil {
+		s.logger.Error("Failed to get notifications", "error", err, "user_id", userID)
+		return nil, NewInternalError("Failed to get notifications")
+	}
+
+	actorNames := make(map[int64]string)
+	responses := make([]NotificationResponse, 0, len(notifications))
+	for _, notification := range notifications {
+		response := NotificationResponse{
+			ID:        notification.ID,
+			Type:      notification.Type,
+			ActorID:   notification.ActorID,
+			PageID:    notification.PageID,
+			CommentID: notification.CommentID,
+			IsRead:    notification.IsRead,
+			ReadAt:    notification.ReadAt,
+			CreatedAt: notification.CreatedAt,
+		}
+
+		if notification.ActorID != nil {
+			name, ok := actorNames[*notification.ActorID]
+			if !ok {
+				actor, err := s.userRepo.GetByID(ctx, *notification.ActorID)
+				if err != nil {
+					s.logger.Error("Failed to get notification actor", "error", err, "user_id", *notification.ActorID)
+				} else if actor != nil {
+					name = actor.Username
+				}
+				actorNames[*notification.ActorID] = name
+			}
+			response.ActorName = name
+		}
+
+		responses = append(responses, response)
+	}
+
+	return responses, nil
+}
+
+func (s *notificationService) MarkNotificationRead(ctx context.Context, userID int64, notificationID string) error {
+	if _, err := uuid.Parse(notificationID); err != nil {
+		return NewNotFoundError("Notification")
+	}
+
+	updated, err := s.notificationRepo.MarkRead(ctx, notificationID, userID)
+	if err != nil {
+		s.logger.Error("Failed to mark notification read", "error", err, "notification_id", notificationID, "user_id", userID)
+		return NewInternalError("Failed to update notification")
+	}
+
+	if !updated {
+		return NewNotFoundError("Notification")
+	}
+
+	return nil
+}
+
+func (s *notificationService) GetPreferences(ctx context.Context, userID int64) (*NotificationPreferencesResponse, error) {
+	preferences, err := s.preferenceRepo.Get(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to get notification preferences", "error", err, "user_id", userID)
+		return nil, NewInternalError("Failed to get notification preferences")
+	}
+
+	return toNotificationPreferencesResponse(preferences), nil
+}
+
+// UpdatePreferences changes only the switches set in the request
+func (s *notificationService) UpdatePreferences(ctx context.Context, userID int64, req *UpdateNotificationPreferencesRequest) (*NotificationPreferencesResponse, error) {
+	preferences, err := s.preferenceRepo.Get(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to get notification preferences", "error", err, "user_id", userID)
+		return nil, NewInternalError("Failed to update notification preferences")
+	}
+
+	if req.EmailPageAccessGranted != nil {
+		preferences.EmailPageAccessGranted = *req.EmailPageAccessGranted
+	}
+	if req.EmailPageAccessRevoked != nil {
+		preferences.EmailPageAccessRevoked = *req.EmailPageAccessRevoked
+	}
+	if req.EmailNewDeviceLogin != nil {
+		preferences.EmailNewDeviceLogin = *req.EmailNewDeviceLogin
+	}
+
+	if err := s.preferenceRepo.Upsert(ctx, preferences); err != nil {
+		s.logger.Error("Failed to save notification preferences", "error", err, "user_id", userID)
+		return nil, NewInternalError("Failed to update notification preferences")
+	}
+
+	return toNotificationPreferencesResponse(preferences), nil
+}
+
+func toNotificationPreferencesResponse(preferences *repository.NotificationPreferences) *NotificationPreferencesResponse {
+	return &NotificationPreferencesResponse{
+		EmailPageAccessGranted: preferences.EmailPageAccessGranted,
+		EmailPageAccessRevoked: preferences.EmailPageAccessRevoked,
+		EmailNewDeviceLogin:    preferences.EmailNewDeviceLogin,
+	}
+}
@@ -0,0 +1,373 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/config"
+	"github.com/Srivathsav-max/lumen/backend/internal/constants"
+	"github.com/Srivathsav-max/lumen/backend/internal/errors"
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+	"github.com/Srivathsav-max/lumen/backend/utils"
+)
+
+const (
+	OAuthProviderGoogle = "google"
+
+	googleAuthURL     = "https://accounts.google.com/o/oauth2/v2/auth"
+	googleTokenURL    = "https://oauth2.googleapis.com/token"
+	googleUserInfoURL = "https://openidconnect.googleapis.com/v1/userinfo"
+)
+
+type OAuthService interface {
+	GoogleEnabled() bool
+	GoogleAuthURL(state string) string
+	// SuccessRedirectURL is where the browser goes after the callback
+	SuccessRedirectURL() string
+	// LoginWithGoogle exchanges an authorization code for the Google account
+	// and returns the linked user, creating one on first login
+	LoginWithGoogle(ctx context.Context, code string) (*UserResponse, error)
+}
+
+type googleUserInfo struct {
+	Subject       string `json:"sub"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	GivenName     string `json:"given_name"`
+	FamilyName    string `json:"family_name"`
+}
+
+type oauthService struct {
+	google       config.GoogleOAuthConfig
+	userRepo     repository.UserRepository
+	roleRepo     repository.RoleRepository
+	identityRepo repository.UserIdentityRepository
+	tokenRepo    repository.TokenRepository
+	userService  UserService
+	httpClient   *http.Client
+	logger       *slog.Logger
+}
+
+func NewOAuthService(
+	google config.GoogleOAuthConfig,
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+	identityRepo repository.UserIdentityRepository,
+	tokenRepo repository.TokenRepository,
+	userService UserService,
+	logger *slog.Logger,
+) OAuthService {
+	return &oauthService{
+		google:       google,
+		userRepo:     userRepo,
+		roleRepo:     roleRepo,
+		identityRepo: identityRepo,
+		tokenRepo:    tokenRepo,
+		userService:  userService,
+		httpClient:   &http.Client{Timeout: 15 * time.Second},
+		logger:       logger,
+	}
+}
+
+func (s *oauthService) GoogleEnabled() bool {
+	return s.google.Enabled()
+}
+
+func (s *oauthService) SuccessRedirectURL() string {
+	return s.google.SuccessRedirectURL
+}
+
+func (s *oauthService) GoogleAuthURL(state string) string {
+	params := url.Values{
+		"client_id":     {s.google.ClientID},
+		"redirect_uri":  {s.google.RedirectURL},
+		"response_type": {"code"},
+		"scope":         {"openid email profile"},
+		"state":         {state},
+		"prompt":        {"select_account"},
+	}
+	return googleAuthURL + "?" + params.Encode()
+}
+
+func (s *oauthService) LoginWithGoogle(ctx context.Context, code string) (*UserResponse, error) {
+	if !s.GoogleEnabled() {
+		return nil, errors.NewNotFoundError("Google login")
+	}
+
+	if code == "" {
+		return nil, errors.NewValidationError("Authorization code is required", "")
+	}
+
+	accessToken, err := s.exchangeGoogleCode(ctx, code)
+	if err != nil {
+		s.logger.Error("Failed to exchange Google authorization code", "error", err)
+		return nil, errors.NewAuthenticationError("Google sign-in failed")
+	}
+
+	info, err := s.fetchGoogleUserInfo(ctx, accessToken)
+	if err != nil {
+		s.logger.Error("Failed to fetch Google user info", "error", err)
+		return nil, errors.NewAuthenticationError("Google sign-in failed")
+	}
+
+	userID, err := s.resolveUser(ctx, OAuthProviderGoogle, info)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.userService.GetByID(ctx, userID)
+}
+
+// resolveUser finds the user linked to the external account. Unlinked
+// accounts are linked to the user with the same verified email, or to a
+// newly created user when there is none.
+func (s *oauthService) resolveUser(ctx context.Context, provider string, info *googleUserInfo) (int64, error) {
+	identity, err := s.identityRepo.GetByProviderSubject(ctx, provider, info.Subject)
+	if err != nil {
+		s.logger.Error("Failed to look up user identity", "provider", provider, "error", err)
+		return 0, errors.NewInternalError("Failed to sign in").WithCause(err)
+	}
+	if identity != nil {
+		return identity.UserID, nil
+	}
+
+	// Linking by email is only safe when the provider vouches for the address
+	if !info.EmailVerified || info.Email == "" {
+		return 0, errors.NewAuthenticationError("Google account email is not verified")
+	}
+
+	email := info.Email
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil && !IsNotFoundError(err) {
+		s.logger.Error("Failed to look up user by email", "email", email, "error", err)
+		return 0, errors.NewInternalError("Failed to sign in").WithCause(err)
+	}
+
+	if user == nil {
+		user, err = s.createOAuthUser(ctx, email, info)
+		if err != nil {
+			return 0, err
+		}
+	} else if !user.EmailVerified {
+		if err := s.claimUnverifiedUser(ctx, user); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := s.identityRepo.Create(ctx, &repository.UserIdentity{
+		UserID:   user.ID,
+		Provider: provider,
+		Subject:  info.Subject,
+		Email:    email,
+	}); err != nil {
+		s.logger.Error("Failed to link user identity", "user_id", user.ID, "provider", provider, "error", err)
+		return 0, errors.NewInternalError("Failed to sign in").WithCause(err)
+	}
+
+	s.logger.Info("External identity linked", "user_id", user.ID, "provider", provider)
+	return user.ID, nil
+}
+
+// claimUnverifiedUser hands an account whose email was never verified to the
+// owner of that address. Whoever registered it may not be that person, so the
+// password they chose stops working and their sessions are revoked.
+func (s *oauthService) claimUnverifiedUser(ctx context.Context, user *repository.User) error {
+	passwordHash, err := unusablePasswordHash()
+	if err != nil {
+		return errors.NewInternalError("Failed to sign in").WithCause(err)
+	}
+
+	user.PasswordHash = passwordHash
+	user.EmailVerified = true
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		s.logger.Error("Failed to claim unverified user", "user_id", user.ID, "error", err)
+		return errors.NewInternalError("Failed to sign in").WithCause(err)
+	}
+
+	if err := s.tokenRepo.RevokeAllUserTokens(ctx, user.ID, TokenTypeRefresh); err != nil {
+		s.logger.Error("Failed to revoke sessions of claimed user", "user_id", user.ID, "error", err)
+		return errors.NewInternalError("Failed to sign in").WithCause(err)
+	}
+
+	s.userService.ApplyOnboardingRules(ctx, user.ID, user.Email)
+
+	s.logger.Warn("Unverified account claimed through Google sign-in", "user_id", user.ID)
+	return nil
+}
+
+func (s *oauthService) createOAuthUser(ctx context.Context, email string, info *googleUserInfo) (*repository.User, error) {
+	username, err := s.availableUsername(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	passwordHash, err := unusablePasswordHash()
+	if err != nil {
+		return nil, errors.NewInternalError("Failed to create user").WithCause(err)
+	}
+
+	user := &repository.User{
+		Username:      username,
+		Email:         email,
+		PasswordHash:  passwordHash,
+		FirstName:     info.GivenName,
+		LastName:      info.FamilyName,
+		EmailVerified: true,
+	}
+
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		s.logger.Error("Failed to create user from Google account", "email", email, "error", err)
+		return nil, errors.NewInternalError("Failed to create user").WithCause(err)
+	}
+
+	freeRole, err := s.roleRepo.GetByName(ctx, constants.RoleFree)
+	if err != nil {
+		s.logger.Error("Failed to find default role", "role", constants.RoleFree, "error", err)
+	} else if err := s.roleRepo.AssignRoleToUser(ctx, user.ID, freeRole.ID); err != nil {
+		s.logger.Error("Failed to assign default role", "user_id", user.ID, "error", err)
+	}
+
+	// Google has verified the address, so its domain rule can apply right away
+	s.userService.ApplyOnboardingRules(ctx, user.ID, email)
+
+	s.logger.Info("User registered via Google", "user_id", user.ID, "email", email)
+	return user, nil
+}
+
+// unusablePasswordHash hashes a random password nobody knows. The account has
+// no usable password until the user sets one through a password reset.
+func unusablePasswordHash() (string, error) {
+	randomPassword, err := randomHex(32)
+	if err != nil {
+		return "", err
+	}
+	return utils.HashPassword(randomPassword)
+}
+
+// availableUsername derives an alphanumeric username from the email's local
+// part, adding a random suffix when it is already taken
+func (s *oauthService) availableUsername(ctx context.Context, email string) (string, error) {
+	var base strings.Builder
+	for _, r := range strings.SplitN(email, "@", 2)[0] {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			base.WriteRune(r)
+		}
+	}
+
+	username := base.String()
+	if len(username) > constants.MaxUsernameLength-6 {
+		username = username[:constants.MaxUsernameLength-6]
+	}
+	for len(username) < constants.MinUsernameLength {
+		username += "user"
+	}
+
+	candidate := username
+	for attempt := 0; attempt < 5; attempt++ {
+		exists, err := s.userRepo.ExistsByUsername(ctx, candidate)
+		if err != nil {
+			return "", errors.NewInternalError("Failed to create user").WithCause(err)
+		}
+		if !exists {
+			return candidate, nil
+		}
+
+		suffix, err := randomHex(3)
+		if err != nil {
+			return "", errors.NewInternalError("Failed to create user").WithCause(err)
+		}
+		candidate = username + suffix
+	}
+
+	return "", errors.NewConflictError("Could not choose a username", "Please register with a username instead")
+}
+
+func (s *oauthService) exchangeGoogleCode(ctx context.Context, code string) (string, error) {
+	form := url.Values{
+		"code":          {code},
+		"client_id":     {s.google.ClientID},
+		"client_secret": {s.google.ClientSecret},
+		"redirect_uri":  {s.google.RedirectURL},
+		"grant_type":    {"authorization_code"},
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, googleTokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	httpResp, err := s.httpClient.Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("call google token endpoint: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(httpResp.Body, 1<<20))
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		return "", fmt.Errorf("google token error: status=%d body=%s", httpResp.StatusCode, string(body))
+	}
+
+	var tokenResp struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return "", fmt.Errorf("decode google token response: %w", err)
+	}
+	if tokenResp.AccessToken == "" {
+		return "", fmt.Errorf("google token response has no access token")
+	}
+
+	return tokenResp.AccessToken, nil
+}
+
+func (s *oauthService) fetchGoogleUserInfo(ctx context.Context, accessToken string) (*googleUserInfo, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, googleUserInfoURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+accessToken)
+
+	httpResp, err := s.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("call google userinfo endpoint: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(httpResp.Body, 1<<20))
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		return nil, fmt.Errorf("google userinfo error: status=%d body=%s", httpResp.StatusCode, string(body))
+	}
+
+	var info googleUserInfo
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, fmt.Errorf("decode google userinfo: %w", err)
+	}
+	if info.Subject == "" {
+		return nil, fmt.Errorf("google userinfo has no subject")
+	}
+
+	return &info, nil
+}
+
+// NewOAuthState returns a random value for the OAuth state parameter
+func NewOAuthState() (string, error) {
+	return randomHex(16)
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
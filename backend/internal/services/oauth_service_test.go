@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/config"
+	"github.com/Srivathsav-max/lumen/backend/internal/constants"
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+type oauthFixture struct {
+	*userServiceFixture
+	service    *oauthService
+	identities *fakeIdentityRepo
+	tokens     *fakeTokenRepo
+}
+
+func newOAuthFixture() *oauthFixture {
+	f := &oauthFixture{
+		userServiceFixture: newUserServiceFixture(acmeRule),
+		identities:         &fakeIdentityRepo{},
+		tokens:             &fakeTokenRepo{},
+	}
+	f.service = NewOAuthService(
+		config.GoogleOAuthConfig{},
+		f.users,
+		f.roles,
+		f.identities,
+		f.tokens,
+		f.userServiceFixture.service,
+		discardLogger(),
+	).(*oauthService)
+	return f
+}
+
+func (f *oauthFixture) existingUser(t *testing.T, email string, verified bool) *repository.User {
+	t.Helper()
+	user := &repository.User{Username: "existing", Email: email, PasswordHash: "chosen-by-registrant", EmailVerified: verified}
+	if err := f.users.Create(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func TestOAuthClaimsUnverifiedAccount(t *testing.T) {
+	f := newOAuthFixture()
+	existing := f.existingUser(t, "victim@acme.test", false)
+
+	userID, err := f.service.resolveUser(context.Background(), OAuthProviderGoogle, &googleUserInfo{
+		Subject: "google-1", Email: "victim@acme.test", EmailVerified: true,
+	})
+	if err != nil {
+		t.Fatalf("resolveUser() error = %v", err)
+	}
+	if userID != existing.ID {
+		t.Fatalf("resolveUser() = %d, want the existing user %d", userID, existing.ID)
+	}
+
+	claimed, _ := f.users.GetByID(context.Background(), existing.ID)
+	if !claimed.EmailVerified {
+		t.Error("claimed account is not marked verified")
+	}
+	if claimed.PasswordHash == "chosen-by-registrant" {
+		t.Error("claimed account kept the registrant's password")
+	}
+	if revoked := f.tokens.revokedUsers(); !slices.Equal(revoked, []int64{existing.ID}) {
+		t.Errorf("revoked sessions of %v, want [%d]", revoked, existing.ID)
+	}
+	if roles := f.roles.rolesOf(existing.ID); !slices.Contains(roles, constants.RoleDeveloper) {
+		t.Errorf("roles = %v, want the domain rule applied", roles)
+	}
+}
+
+func TestOAuthLinksVerifiedAccountUnchanged(t *testing.T) {
+	f := newOAuthFixture()
+	existing := f.existingUser(t, "owner@example.test", true)
+
+	userID, err := f.service.resolveUser(context.Background(), OAuthProviderGoogle, &googleUserInfo{
+		Subject: "google-2", Email: "owner@example.test", EmailVerified: true,
+	})
+	if err != nil || userID != existing.ID {
+		t.Fatalf("resolveUser() = %d, %v; want %d", userID, err, existing.ID)
+	}
+
+	linked, _ := f.users.GetByID(context.Background(), existing.ID)
+	if linked.PasswordHash != "chosen-by-registrant" {
+		t.Error("linking a verified account changed its password")
+	}
+	if revoked := f.tokens.revokedUsers(); len(revoked) != 0 {
+		t.Errorf("revoked sessions of %v, want none", revoked)
+	}
+}
+
+func TestOAuthRefusesUnverifiedProviderEmail(t *testing.T) {
+	f := newOAuthFixture()
+	existing := f.existingUser(t, "victim@example.test", false)
+
+	if _, err := f.service.resolveUser(context.Background(), OAuthProviderGoogle, &googleUserInfo{
+		Subject: "google-3", Email: "victim@example.test", EmailVerified: false,
+	}); err == nil {
+		t.Fatal("resolveUser() linked an account the provider has not verified")
+	}
+
+	stored, _ := f.users.GetByID(context.Background(), existing.ID)
+	if stored.EmailVerified || stored.PasswordHash != "chosen-by-registrant" {
+		t.Error("refused sign-in still changed the account")
+	}
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+func TestGetEffectiveAccess(t *testing.T) {
+	const ownerID, editorID, memberID, viewerID int64 = 1, 2, 3, 4
+
+	tests := []struct {
+		policy repository.PermissionLevel
+		want   []string
+	}{
+		{repository.PermissionNone, []string{"1:admin:owner", "2:edit:explicit", "4:view:explicit"}},
+		{repository.PermissionComment, []string{"1:admin:owner", "2:edit:explicit", "4:view:explicit", "3:comment:workspace_default"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			ctx := context.Background()
+			users := newFakeUserRepo()
+			for _, name := range []string{"owner", "editor", "member", "viewer"} {
+				users.Create(ctx, &repository.User{Username: name, Email: name + "@example.test"})
+			}
+
+			workspaces := newFakeWorkspaceRepo(&repository.Workspace{ID: 10, OwnerID: ownerID, DefaultPagePermission: tt.policy})
+			for _, id := range []int64{ownerID, editorID, memberID} {
+				workspaces.AddMember(ctx, &repository.WorkspaceMember{WorkspaceID: 10, UserID: id, Role: repository.WorkspaceRoleMember})
+			}
+
+			pages := newFakePageRepo(&repository.Page{ID: "page", WorkspaceID: 10, OwnerID: ownerID})
+			pages.grant("page", ownerID, repository.PermissionView)
+			pages.grant("page", editorID, repository.PermissionEdit)
+			pages.grant("page", viewerID, repository.PermissionView)
+
+			svc := NewPageService(pages, nil, workspaces, users, fakeActivityService{}, nil, nil, nil, nil, discardLogger())
+
+			access, err := svc.GetEffectiveAccess(ctx, ownerID, "page")
+			if err != nil {
+				t.Fatalf("GetEffectiveAccess() error = %v", err)
+			}
+
+			got := make([]string, 0, len(access))
+			for _, entry := range access {
+				got = append(got, fmt.Sprintf("%d:%s:%s", entry.UserID, entry.Permission, entry.Source))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetEffectiveAccess() = %v, want %v", got, tt.want)
+			}
+
+			if _, err := svc.GetEffectiveAccess(ctx, editorID, "page"); !IsAuthorizationError(err) {
+				t.Errorf("GetEffectiveAccess() by an editor error = %v, want forbidden", err)
+			}
+		})
+	}
+}
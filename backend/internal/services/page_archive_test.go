@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+// newCascadeTestPages is a page the user owns with one child they can edit
+// and one grandchild they cannot
+func newCascadeTestPages() *fakePageRepo {
+	pages := newFakePageRepo(
+		&repository.Page{ID: "root", OwnerID: bulkTestUser},
+		&repository.Page{ID: "child", OwnerID: 2, ParentID: stringPtr("root")},
+		&repository.Page{ID: "locked", OwnerID: 2, ParentID: stringPtr("child")},
+	)
+	pages.grant("child", bulkTestUser, repository.PermissionEdit)
+	pages.grant("locked", bulkTestUser, repository.PermissionView)
+	return pages
+}
+
+func TestArchiveAndRestoreRefuseToCascadeOverLockedPages(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("archive", func(t *testing.T) {
+		pages := newCascadeTestPages()
+		svc := newBulkTestService(pages)
+
+		if err := svc.ArchivePage(ctx, bulkTestUser, "root", true); !IsAuthorizationError(err) {
+			t.Errorf("cascading ArchivePage() error = %v, want forbidden", err)
+		}
+		if len(pages.archived) != 0 {
+			t.Fatalf("archived %v after a refused cascade", pages.archived)
+		}
+
+		if err := svc.ArchivePage(ctx, bulkTestUser, "root", false); err != nil {
+			t.Errorf("ArchivePage() without cascade error = %v", err)
+		}
+		pages.grant("locked", bulkTestUser, repository.PermissionEdit)
+		if err := svc.ArchivePage(ctx, bulkTestUser, "root", true); err != nil {
+			t.Errorf("cascading ArchivePage() over editable pages error = %v", err)
+		}
+		if len(pages.archived) != 2 {
+			t.Errorf("archived %v, want root twice", pages.archived)
+		}
+	})
+
+	t.Run("restore", func(t *testing.T) {
+		pages := newCascadeTestPages()
+		svc := newBulkTestService(pages)
+
+		if err := svc.RestorePage(ctx, bulkTestUser, "root", true); !IsAuthorizationError(err) {
+			t.Errorf("cascading RestorePage() error = %v, want forbidden", err)
+		}
+		if len(pages.restored) != 0 {
+			t.Fatalf("restored %v after a refused cascade", pages.restored)
+		}
+
+		if err := svc.RestorePage(ctx, bulkTestUser, "root", false); err != nil {
+			t.Errorf("RestorePage() without cascade error = %v", err)
+		}
+		if err := svc.RestorePage(ctx, bulkTestUser, "child", true); !IsAuthorizationError(err) {
+			t.Errorf("cascading RestorePage() of the child error = %v, want forbidden", err)
+		}
+		if len(pages.restored) != 1 {
+			t.Errorf("restored %v, want only root", pages.restored)
+		}
+	})
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	apperrors "github.com/Srivathsav-max/lumen/backend/internal/errors"
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+const bulkTestUser int64 = 1
+
+func newBulkTestService(pages *fakePageRepo) PageService {
+	return NewPageService(pages, nil, nil, nil, fakeActivityService{}, nil, nil, nil, nil, discardLogger())
+}
+
+func newBulkTestPages() *fakePageRepo {
+	pages := newFakePageRepo(
+		&repository.Page{ID: "own", OwnerID: bulkTestUser},
+		&repository.Page{ID: "shared", OwnerID: 2},
+		&repository.Page{ID: "private", OwnerID: 2},
+	)
+	pages.grant("shared", bulkTestUser, repository.PermissionEdit)
+	return pages
+}
+
+func failedIDs(result BatchResult[string]) map[string]string {
+	codes := make(map[string]string, len(result.Failed))
+	for _, item := range result.Failed {
+		codes[item.ID] = item.ErrorCode
+	}
+	return codes
+}
+
+func TestBulkArchivePagesReportsEachPage(t *testing.T) {
+	tests := []struct {
+		name        string
+		pageIDs     []string
+		wantApplied []string
+		wantFailed  map[string]string
+	}{
+		{
+			name:        "all succeed",
+			pageIDs:     []string{"own", "shared", "own"},
+			wantApplied: []string{"own", "shared"},
+			wantFailed:  map[string]string{},
+		},
+		{
+			name:       "all fail",
+			pageIDs:    []string{"private", "missing"},
+			wantFailed: map[string]string{"private": string(apperrors.AuthorizationError), "missing": string(apperrors.NotFoundError)},
+		},
+		{
+			name:        "mixed",
+			pageIDs:     []string{"own", "private"},
+			wantApplied: []string{"own"},
+			wantFailed:  map[string]string{"private": string(apperrors.AuthorizationError)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pages := newBulkTestPages()
+			response, err := newBulkTestService(pages).BulkArchivePages(context.Background(), bulkTestUser, tt.pageIDs, false)
+			if err != nil {
+				t.Fatalf("BulkArchivePages() error = %v", err)
+			}
+
+			if !slices.Equal(response.Succeeded, tt.wantApplied) || !slices.Equal(pages.archived, tt.wantApplied) {
+				t.Errorf("succeeded %v and archived %v, want %v", response.Succeeded, pages.archived, tt.wantApplied)
+			}
+			if got := failedIDs(response.BatchResult); len(got) != len(tt.wantFailed) {
+				t.Errorf("failed = %v, want %v", got, tt.wantFailed)
+			} else {
+				for id, code := range tt.wantFailed {
+					if got[id] != code {
+						t.Errorf("failed[%s] = %q, want %q", id, got[id], code)
+					}
+				}
+			}
+			if response.Total != len(tt.wantApplied)+len(tt.wantFailed) {
+				t.Errorf("Total = %d, want one per unique page", response.Total)
+			}
+			if response.Affected != int64(len(tt.wantApplied)) {
+				t.Errorf("Affected = %d, want %d", response.Affected, len(tt.wantApplied))
+			}
+		})
+	}
+}
+
+func TestBulkDeletePagesRequiresAdmin(t *testing.T) {
+	pages := newBulkTestPages()
+	response, err := newBulkTestService(pages).BulkDeletePages(context.Background(), bulkTestUser, []string{"own", "shared"})
+	if err != nil {
+		t.Fatalf("BulkDeletePages() error = %v", err)
+	}
+
+	if !slices.Equal(pages.deleted, []string{"own"}) {
+		t.Errorf("deleted %v, want only the page the user owns", pages.deleted)
+	}
+	if codes := failedIDs(response.BatchResult); codes["shared"] != string(apperrors.AuthorizationError) {
+		t.Errorf("failed = %v, want shared denied", codes)
+	}
+}
+
+// newBulkTestTree builds root > child > grandchild, where the user owns the
+// root and child but the grandchild belongs to someone else
+func newBulkTestTree(grandchildLevel repository.PermissionLevel) *fakePageRepo {
+	root, child := "root", "child"
+	pages := newFakePageRepo(
+		&repository.Page{ID: root, OwnerID: bulkTestUser},
+		&repository.Page{ID: child, OwnerID: bulkTestUser, ParentID: &root},
+		&repository.Page{ID: "grandchild", OwnerID: 2, ParentID: &child},
+	)
+	if grandchildLevel != "" {
+		pages.grant("grandchild", bulkTestUser, grandchildLevel)
+	}
+	return pages
+}
+
+func TestBulkCascadeChecksEveryDescendant(t *testing.T) {
+	tests := []struct {
+		name            string
+		grandchildLevel repository.PermissionLevel
+		delete          bool
+		cascade         bool
+		wantApplied     bool
+	}{
+		{name: "archive without cascade ignores descendants", cascade: false, wantApplied: true},
+		{name: "archive cascade denied by an inaccessible grandchild", cascade: true, wantApplied: false},
+		{name: "archive cascade denied by a view-only grandchild", grandchildLevel: repository.PermissionView, cascade: true, wantApplied: false},
+		{name: "archive cascade allowed with edit on the grandchild", grandchildLevel: repository.PermissionEdit, cascade: true, wantApplied: true},
+		{name: "delete denied with edit on the grandchild", grandchildLevel: repository.PermissionEdit, delete: true, wantApplied: false},
+		{name: "delete allowed with admin on the grandchild", grandchildLevel: repository.PermissionAdmin, delete: true, wantApplied: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pages := newBulkTestTree(tt.grandchildLevel)
+			service := newBulkTestService(pages)
+
+			var response *BulkPagesResponse
+			var err error
+			if tt.delete {
+				response, err = service.BulkDeletePages(context.Background(), bulkTestUser, []string{"root"})
+			} else {
+				response, err = service.BulkArchivePages(context.Background(), bulkTestUser, []string{"root"}, tt.cascade)
+			}
+			if err != nil {
+				t.Fatalf("bulk operation error = %v", err)
+			}
+
+			applied := append(pages.archived, pages.deleted...)
+			if got := len(applied) == 1; got != tt.wantApplied {
+				t.Errorf("root applied = %v (%v), want %v", got, applied, tt.wantApplied)
+			}
+			if !tt.wantApplied && failedIDs(response.BatchResult)["root"] != string(apperrors.AuthorizationError) {
+				t.Errorf("failed = %+v, want root denied", response.Failed)
+			}
+		})
+	}
+}
+
+func TestBulkCascadeSkipsOnlyRestrictedSubtrees(t *testing.T) {
+	pages := newBulkTestTree("")
+	pages.pages["other"] = &repository.Page{ID: "other", OwnerID: bulkTestUser}
+
+	response, err := newBulkTestService(pages).BulkDeletePages(context.Background(), bulkTestUser, []string{"root", "other", "child"})
+	if err != nil {
+		t.Fatalf("BulkDeletePages() error = %v", err)
+	}
+	if !slices.Equal(pages.deleted, []string{"other"}) {
+		t.Errorf("deleted %v, want only the unrestricted page", pages.deleted)
+	}
+	if len(response.Failed) != 2 {
+		t.Errorf("failed = %+v, want root and child skipped", response.Failed)
+	}
+}
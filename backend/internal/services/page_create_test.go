@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+func TestCreatePageDuplicateSiblingTitle(t *testing.T) {
+	const userID int64 = 1
+	parent := "parent"
+
+	tests := []struct {
+		name            string
+		title           string
+		parentID        *string
+		failOnDuplicate bool
+		wantWarning     bool
+		wantConflict    bool
+	}{
+		{name: "unique title", title: "Roadmap"},
+		{name: "same title and parent", title: "  meeting NOTES ", wantWarning: true},
+		{name: "same title under another parent", title: "Meeting notes", parentID: &parent},
+		{name: "archived sibling", title: "Old plan"},
+		{name: "duplicate rejected on request", title: "Meeting notes", failOnDuplicate: true, wantConflict: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pages := newFakePageRepo(
+				&repository.Page{ID: parent, Title: "Parent", WorkspaceID: 10, OwnerID: userID},
+				&repository.Page{ID: "notes", Title: "Meeting notes", WorkspaceID: 10, OwnerID: userID},
+				&repository.Page{ID: "old", Title: "Old plan", WorkspaceID: 10, OwnerID: userID, IsArchived: true},
+			)
+			svc := newPageTestService(pages, newFakeWorkspaceRepo(&repository.Workspace{ID: 10, OwnerID: userID}))
+
+			page, err := svc.CreatePage(context.Background(), userID, &CreatePageRequest{
+				Title:           tt.title,
+				WorkspaceID:     10,
+				ParentID:        tt.parentID,
+				FailOnDuplicate: tt.failOnDuplicate,
+			})
+
+			if tt.wantConflict {
+				if !IsConflictError(err) {
+					t.Fatalf("CreatePage() error = %v, want a conflict", err)
+				}
+				if len(pages.pages) != 3 {
+					t.Error("rejected page was still created")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreatePage() error = %v", err)
+			}
+			if got := len(page.Warnings) > 0; got != tt.wantWarning {
+				t.Errorf("warnings = %v, want a warning: %v", page.Warnings, tt.wantWarning)
+			}
+		})
+	}
+}
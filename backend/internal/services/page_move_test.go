@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+const moveTestUser int64 = 1
+
+func newPageTestService(pages *fakePageRepo, workspaces *fakeWorkspaceRepo) PageService {
+	return NewPageService(pages, nil, workspaces, nil, fakeActivityService{}, nil, nil, nil, nil, discardLogger())
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestMovePageRejectsCycles(t *testing.T) {
+	pages := newFakePageRepo(
+		&repository.Page{ID: "root", WorkspaceID: 10, OwnerID: moveTestUser},
+		&repository.Page{ID: "child", WorkspaceID: 10, OwnerID: moveTestUser, ParentID: stringPtr("root")},
+		&repository.Page{ID: "grandchild", WorkspaceID: 10, OwnerID: moveTestUser, ParentID: stringPtr("child")},
+	)
+	svc := newPageTestService(pages, newFakeWorkspaceRepo(&repository.Workspace{ID: 10, OwnerID: moveTestUser}))
+
+	for _, parentID := range []string{"root", "grandchild"} {
+		_, err := svc.MovePage(context.Background(), moveTestUser, "root", &MovePageRequest{NewParentID: stringPtr(parentID)})
+		if !IsValidationError(err) {
+			t.Errorf("moving root under %s: error = %v, want a bad request", parentID, err)
+		}
+	}
+
+	if _, err := svc.MovePage(context.Background(), moveTestUser, "grandchild", &MovePageRequest{}); err != nil {
+		t.Fatalf("moving grandchild to the top level: %v", err)
+	}
+	if _, err := svc.MovePage(context.Background(), moveTestUser, "root", &MovePageRequest{NewParentID: stringPtr("grandchild")}); err != nil {
+		t.Errorf("moving root under a detached page: %v", err)
+	}
+}
+
+func TestMovePageAcrossWorkspacesNeedsEditRights(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    repository.PermissionLevel
+		role      repository.WorkspaceRole
+		member    bool
+		wantAllow bool
+	}{
+		{name: "non-member", policy: repository.PermissionEdit},
+		{name: "member of view-only workspace", policy: repository.PermissionView, role: repository.WorkspaceRoleMember, member: true},
+		{name: "member of editable workspace", policy: repository.PermissionEdit, role: repository.WorkspaceRoleMember, member: true, wantAllow: true},
+		{name: "admin of view-only workspace", policy: repository.PermissionView, role: repository.WorkspaceRoleAdmin, member: true, wantAllow: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pages := newFakePageRepo(&repository.Page{ID: "page", WorkspaceID: 10, OwnerID: moveTestUser})
+			workspaces := newFakeWorkspaceRepo(
+				&repository.Workspace{ID: 10, OwnerID: moveTestUser},
+				&repository.Workspace{ID: 20, OwnerID: 2, DefaultPagePermission: tt.policy},
+			)
+			if tt.member {
+				workspaces.AddMember(context.Background(), &repository.WorkspaceMember{WorkspaceID: 20, UserID: moveTestUser, Role: tt.role})
+			}
+			svc := newPageTestService(pages, workspaces)
+
+			target := int64(20)
+			_, err := svc.MovePage(context.Background(), moveTestUser, "page", &MovePageRequest{NewWorkspaceID: &target})
+
+			if tt.wantAllow {
+				if err != nil {
+					t.Fatalf("MovePage() error = %v", err)
+				}
+				if page, _ := pages.GetByID(context.Background(), "page"); page.WorkspaceID != target {
+					t.Errorf("page is in workspace %d, want %d", page.WorkspaceID, target)
+				}
+				return
+			}
+			if !IsAuthorizationError(err) {
+				t.Fatalf("MovePage() error = %v, want forbidden", err)
+			}
+			if page, _ := pages.GetByID(context.Background(), "page"); page.WorkspaceID != 10 {
+				t.Errorf("forbidden move changed the workspace to %d", page.WorkspaceID)
+			}
+		})
+	}
+}
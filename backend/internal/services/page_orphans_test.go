@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+const orphanTestAdmin int64 = 1
+
+// newOrphanTestPages builds a workspace with one orphan subtree (orphan ->
+// orphan-child), a healthy root, a page in another workspace and a corrupt
+// two-page parent cycle
+func newOrphanTestPages() *fakePageRepo {
+	return newFakePageRepo(
+		&repository.Page{ID: "root", WorkspaceID: 10, OwnerID: orphanTestAdmin},
+		&repository.Page{ID: "orphan", WorkspaceID: 10, OwnerID: orphanTestAdmin, ParentID: stringPtr("deleted")},
+		&repository.Page{ID: "orphan-child", WorkspaceID: 10, OwnerID: orphanTestAdmin, ParentID: stringPtr("orphan")},
+		&repository.Page{ID: "elsewhere", WorkspaceID: 20, OwnerID: orphanTestAdmin},
+		&repository.Page{ID: "loop-a", WorkspaceID: 10, OwnerID: orphanTestAdmin, ParentID: stringPtr("loop-b")},
+		&repository.Page{ID: "loop-b", WorkspaceID: 10, OwnerID: orphanTestAdmin, ParentID: stringPtr("loop-a")},
+	)
+}
+
+func newOrphanTestWorkspaces() *fakeWorkspaceRepo {
+	workspaces := newFakeWorkspaceRepo(&repository.Workspace{ID: 10, OwnerID: orphanTestAdmin})
+	workspaces.AddMember(context.Background(), &repository.WorkspaceMember{WorkspaceID: 10, UserID: 2, Role: repository.WorkspaceRoleMember})
+	return workspaces
+}
+
+func TestGetOrphanedPages(t *testing.T) {
+	svc := newPageTestService(newOrphanTestPages(), newOrphanTestWorkspaces())
+
+	orphans, err := svc.GetOrphanedPages(context.Background(), orphanTestAdmin, 10)
+	if err != nil {
+		t.Fatalf("GetOrphanedPages() error = %v", err)
+	}
+	if len(orphans) != 1 || orphans[0].ID != "orphan" || orphans[0].ChildrenCount != 1 {
+		t.Errorf("GetOrphanedPages() = %+v, want only the orphan with its one child", orphans)
+	}
+
+	if _, err := svc.GetOrphanedPages(context.Background(), 2, 10); !IsAuthorizationError(err) {
+		t.Errorf("GetOrphanedPages() as a plain member: error = %v, want forbidden", err)
+	}
+}
+
+func TestRepairOrphanedPagesValidatesTarget(t *testing.T) {
+	tests := []struct {
+		name     string
+		parentID string
+		check    func(error) bool
+	}{
+		{name: "missing target", parentID: "nope", check: IsNotFoundError},
+		{name: "target in another workspace", parentID: "elsewhere", check: IsValidationError},
+		{name: "target inside the orphaned subtree", parentID: "orphan-child", check: IsValidationError},
+		{name: "target inside a parent cycle", parentID: "loop-a", check: IsValidationError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pages := newOrphanTestPages()
+			svc := newPageTestService(pages, newOrphanTestWorkspaces())
+
+			_, err := svc.RepairOrphanedPages(context.Background(), orphanTestAdmin, 10, &RepairOrphanedPagesRequest{ParentID: stringPtr(tt.parentID)})
+			if !tt.check(err) {
+				t.Fatalf("RepairOrphanedPages() error = %v", err)
+			}
+			if orphan, _ := pages.GetByID(context.Background(), "orphan"); *orphan.ParentID != "deleted" {
+				t.Error("rejected repair still reparented the orphan")
+			}
+		})
+	}
+}
+
+func TestRepairOrphanedPages(t *testing.T) {
+	pages := newOrphanTestPages()
+	svc := newPageTestService(pages, newOrphanTestWorkspaces())
+
+	if _, err := svc.RepairOrphanedPages(context.Background(), 2, 10, &RepairOrphanedPagesRequest{}); !IsAuthorizationError(err) {
+		t.Errorf("repair as a plain member: error = %v, want forbidden", err)
+	}
+
+	resp, err := svc.RepairOrphanedPages(context.Background(), orphanTestAdmin, 10, &RepairOrphanedPagesRequest{ParentID: stringPtr("root")})
+	if err != nil {
+		t.Fatalf("RepairOrphanedPages() error = %v", err)
+	}
+	if resp.RepairedCount != 1 {
+		t.Errorf("RepairedCount = %d, want 1", resp.RepairedCount)
+	}
+	if orphan, _ := pages.GetByID(context.Background(), "orphan"); orphan.ParentID == nil || *orphan.ParentID != "root" {
+		t.Errorf("orphan parent = %v, want root", orphan.ParentID)
+	}
+}
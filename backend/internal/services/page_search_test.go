@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+const searchTestUser int64 = 1
+
+func newSearchTestService() (PageService, *fakePageRepo) {
+	pages := newFakePageRepo(
+		&repository.Page{ID: "roadmap", Title: "Roadmap", WorkspaceID: 10, OwnerID: searchTestUser},
+		&repository.Page{ID: "retro", Title: "Roadmap retro", WorkspaceID: 10, OwnerID: searchTestUser},
+	)
+	return newPageTestService(pages, newFakeWorkspaceRepo(&repository.Workspace{ID: 10, OwnerID: searchTestUser})), pages
+}
+
+func TestSearchPagesWithNoMatches(t *testing.T) {
+	svc, pages := newSearchTestService()
+
+	result, err := svc.SearchPages(context.Background(), searchTestUser, &SearchPagesRequest{WorkspaceID: 10, Query: "budget", Limit: 20})
+	if err != nil {
+		t.Fatalf("SearchPages() error = %v", err)
+	}
+
+	if result.Total != 0 {
+		t.Errorf("Total = %d, want 0", result.Total)
+	}
+	if result.Pages == nil || len(result.Pages) != 0 {
+		t.Errorf("Pages = %#v, want an empty, non-nil slice", result.Pages)
+	}
+	if encoded, _ := json.Marshal(result.Pages); string(encoded) != "[]" {
+		t.Errorf("Pages encodes as %s, want []", encoded)
+	}
+	if pages.searches != 0 {
+		t.Errorf("ran %d page searches for an empty result, want 0", pages.searches)
+	}
+}
+
+func TestSearchPagesWithOffsetPastTheEnd(t *testing.T) {
+	svc, pages := newSearchTestService()
+
+	result, err := svc.SearchPages(context.Background(), searchTestUser, &SearchPagesRequest{WorkspaceID: 10, Query: "roadmap", Limit: 20, Offset: 40})
+	if err != nil {
+		t.Fatalf("SearchPages() error = %v", err)
+	}
+
+	if result.Total != 2 {
+		t.Errorf("Total = %d, want 2", result.Total)
+	}
+	if result.Pages == nil || len(result.Pages) != 0 {
+		t.Errorf("Pages = %#v, want an empty, non-nil slice", result.Pages)
+	}
+	if result.Offset != 40 || result.Limit != 20 {
+		t.Errorf("Offset, Limit = %d, %d, want the requested 40, 20", result.Offset, result.Limit)
+	}
+	if pages.searches != 0 {
+		t.Errorf("ran %d page searches past the end, want 0", pages.searches)
+	}
+}
@@ -5,37 +5,86 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/errors"
 	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+	"github.com/Srivathsav-max/lumen/backend/internal/security"
 )
 
 type PageService interface {
 	CreatePage(ctx context.Context, userID int64, req *CreatePageRequest) (*PageResponse, error)
 	GetPage(ctx context.Context, userID int64, pageID string) (*PageResponse, error)
 	GetPageWithBlocks(ctx context.Context, userID int64, pageID string) (*PageResponse, error)
-	GetWorkspacePages(ctx context.Context, userID int64, workspaceID int64, includeArchived bool) ([]PageResponse, error)
-	GetChildPages(ctx context.Context, userID int64, parentPageID string, includeArchived bool) ([]PageResponse, error)
-	GetRootPages(ctx context.Context, userID int64, workspaceID int64, includeArchived bool) ([]PageResponse, error)
+	GetPageForViewer(ctx context.Context, pageID string) (*PageResponse, error)
+	// Page listings leave out templates unless includeTemplates is set
+	GetWorkspacePages(ctx context.Context, userID int64, workspaceID int64, includeArchived, includeTemplates bool) ([]PageResponse, error)
+	// GetWorkspaceTemplates lists the workspace's templates the user can view
+	GetWorkspaceTemplates(ctx context.Context, userID int64, workspaceID int64) ([]PageResponse, error)
+	GetChildPages(ctx context.Context, userID int64, parentPageID string, includeArchived, includeTemplates bool) ([]PageResponse, error)
+	GetRootPages(ctx context.Context, userID int64, workspaceID int64, includeArchived, includeTemplates bool) ([]PageResponse, error)
+	GetPageAncestors(ctx context.Context, userID int64, pageID string) ([]PageResponse, error)
+	GetPageTree(ctx context.Context, userID int64, workspaceID int64, maxDepth int, includeArchived, includeTemplates bool) ([]PageTreeNode, error)
+	ExportPageMarkdown(ctx context.Context, userID int64, pageID string) (string, error)
+	AttachPreviews(ctx context.Context, pages []PageResponse) error
 	UpdatePage(ctx context.Context, userID int64, pageID string, req *UpdatePageRequest) (*PageResponse, error)
 	SavePageContent(ctx context.Context, userID int64, pageID string, req *SavePageContentRequest) (*PageResponse, error)
+	MovePage(ctx context.Context, userID int64, pageID string, req *MovePageRequest) (*PageResponse, error)
+	DuplicatePage(ctx context.Context, userID int64, pageID string, req *DuplicatePageRequest) (*PageResponse, error)
+	// CreateFromTemplate copies a template's blocks and properties into a new
+	// regular page, filling in {{placeholders}} in the title and block text
+	CreateFromTemplate(ctx context.Context, userID int64, templatePageID string, req *InstantiateTemplateRequest) (*PageResponse, error)
+	ImportMarkdown(ctx context.Context, userID int64, req *ImportMarkdownRequest) (*PageResponse, error)
+	ValidateContent(ctx context.Context, req *SavePageContentRequest) (*ValidateContentResponse, error)
+	ReorderBlocks(ctx context.Context, userID int64, pageID string, order map[string]int) ([]BlockResponse, error)
 	DeletePage(ctx context.Context, userID int64, pageID string) error
-	ArchivePage(ctx context.Context, userID int64, pageID string) error
-	RestorePage(ctx context.Context, userID int64, pageID string) error
+	ListTrash(ctx context.Context, userID int64, workspaceID int64) ([]PageResponse, error)
+	RestoreFromTrash(ctx context.Context, userID int64, pageID string) error
+	PurgePage(ctx context.Context, userID int64, pageID string) error
+	PurgeExpiredTrash(ctx context.Context, retention time.Duration) (int64, error)
+	// With cascade, ArchivePage also archives descendants and RestorePage
+	// restores them, leaving pages that were archived on their own archived
+	ArchivePage(ctx context.Context, userID int64, pageID string, cascade bool) error
+	// BulkArchivePages and BulkDeletePages skip pages the user cannot change,
+	// apply the rest atomically and report the outcome per page ID
+	BulkArchivePages(ctx context.Context, userID int64, pageIDs []string, includeDescendants bool) (*BulkPagesResponse, error)
+	BulkDeletePages(ctx context.Context, userID int64, pageIDs []string) (*BulkPagesResponse, error)
+	RestorePage(ctx context.Context, userID int64, pageID string, cascade bool) error
 	SearchPages(ctx context.Context, userID int64, req *SearchPagesRequest) (*SearchPagesResponse, error)
+	// QueryPages filters the workspace's live pages by property values,
+	// returning only pages the user can view
+	QueryPages(ctx context.Context, userID int64, workspaceID int64, req *QueryPagesRequest) (*QueryPagesResponse, error)
 	GetRecentPages(ctx context.Context, userID int64, limit int) ([]PageResponse, error)
+	// ListRecentPages and ListWorkspacePages return one cursor page plus the
+	// cursor for the next one, which is empty once the list is exhausted
+	ListRecentPages(ctx context.Context, userID int64, cursor string, limit int) ([]PageResponse, string, error)
+	ListWorkspacePages(ctx context.Context, userID int64, workspaceID int64, includeArchived, includeTemplates bool, cursor string, limit int) ([]PageResponse, string, error)
+	ToggleFavorite(ctx context.Context, userID int64, pageID string, favorite bool) error
+	GetFavoritePages(ctx context.Context, userID int64) ([]PageResponse, error)
+	GetEffectiveAccess(ctx context.Context, userID int64, pageID string) ([]EffectiveAccessResponse, error)
+	GetOrphanedPages(ctx context.Context, userID int64, workspaceID int64) ([]PageResponse, error)
+	RepairOrphanedPages(ctx context.Context, userID int64, workspaceID int64, req *RepairOrphanedPagesRequest) (*RepairOrphanedPagesResponse, error)
 	GetPageVersions(ctx context.Context, userID int64, pageID string, limit, offset int) ([]PageVersionResponse, error)
 	GetPageVersion(ctx context.Context, userID int64, pageID string, versionNumber int) (*PageVersionResponse, error)
 	GrantPermission(ctx context.Context, userID int64, pageID string, req *GrantPagePermissionRequest) (*PagePermissionResponse, error)
 	RevokePermission(ctx context.Context, userID int64, pageID string, targetUserID int64) error
-	GetPagePermissions(ctx context.Context, userID int64, pageID string) ([]PagePermissionResponse, error)
+	GetPagePermissions(ctx context.Context, userID int64, pageID string, limit, offset int) ([]PagePermissionResponse, error)
 }
 
 type pageService struct {
-	pageRepo      repository.PageRepository
-	blockRepo     repository.BlockRepository
-	workspaceRepo repository.WorkspaceRepository
-	userRepo      repository.UserRepository
-	logger        *slog.Logger
+	pageRepo          repository.PageRepository
+	blockRepo         repository.BlockRepository
+	workspaceRepo     repository.WorkspaceRepository
+	userRepo          repository.UserRepository
+	activity          ActivityService
+	emailService      EmailService
+	notificationPrefs repository.NotificationPreferenceRepository
+	blockTypes        *BlockTypeRegistry
+	xss               *security.XSSService
+	logger            *slog.Logger
 }
 
 func NewPageService(
@@ -43,14 +92,24 @@ func NewPageService(
 	blockRepo repository.BlockRepository,
 	workspaceRepo repository.WorkspaceRepository,
 	userRepo repository.UserRepository,
+	activity ActivityService,
+	emailService EmailService,
+	notificationPrefs repository.NotificationPreferenceRepository,
+	blockTypes *BlockTypeRegistry,
+	xss *security.XSSService,
 	logger *slog.Logger,
 ) PageService {
 	return &pageService{
-		pageRepo:      pageRepo,
-		blockRepo:     blockRepo,
-		workspaceRepo: workspaceRepo,
-		userRepo:      userRepo,
-		logger:        logger,
+		pageRepo:          pageRepo,
+		blockRepo:         blockRepo,
+		workspaceRepo:     workspaceRepo,
+		userRepo:          userRepo,
+		activity:          activity,
+		emailService:      emailService,
+		notificationPrefs: notificationPrefs,
+		blockTypes:        blockTypes,
+		xss:               xss,
+		logger:            logger,
 	}
 }
 
@@ -104,12 +163,35 @@ func (s *pageService) CreatePage(ctx context.Context, userID int64, req *CreateP
 		page.Properties = json.RawMessage("{}")
 	}
 
+	if err := s.validateProperties(ctx, req.WorkspaceID, page.Properties); err != nil {
+		return nil, err
+	}
+
+	// Warn about (or reject) a sibling with the same title
+	var warnings []string
+	duplicate, err := s.pageRepo.HasSiblingWithTitle(ctx, req.WorkspaceID, req.ParentID, page.Title)
+	if err != nil {
+		s.logger.Error("Failed to check duplicate page title", "error", err, "workspace_id", req.WorkspaceID, "user_id", userID)
+		return nil, NewInternalError("Failed to create page")
+	}
+
+	if duplicate {
+		if req.FailOnDuplicate {
+			return nil, NewConflictError("A page with this title already exists in this location")
+		}
+		warnings = append(warnings, "A page with this title already exists in this location")
+	}
+
 	if err := s.pageRepo.Create(ctx, page); err != nil {
 		s.logger.Error("Failed to create page", "error", err, "workspace_id", req.WorkspaceID, "user_id", userID)
 		return nil, NewInternalError("Failed to create page")
 	}
 
-	return s.toPageResponse(page, repository.PermissionAdmin, 0), nil
+	s.recordPageActivity(page, userID, repository.ActivityPageCreated, nil)
+
+	response := s.toPageResponse(page, repository.PermissionAdmin, 0)
+	response.Warnings = warnings
+	return response, nil
 }
 
 func (s *pageService) GetPage(ctx context.Context, userID int64, pageID string) (*PageResponse, error) {
@@ -142,13 +224,13 @@ func (s *pageService) GetPage(ctx context.Context, userID int64, pageID string)
 	}
 
 	// Get children count
-	children, err := s.pageRepo.GetByParentID(ctx, pageID, false)
+	childCounts, err := s.pageRepo.CountChildren(ctx, []string{pageID})
 	if err != nil {
-		s.logger.Error("Failed to get child pages", "error", err, "page_id", pageID)
+		s.logger.Error("Failed to count child pages", "error", err, "page_id", pageID)
 		return nil, NewInternalError("Failed to get child pages")
 	}
 
-	return s.toPageResponse(page, permission, len(children)), nil
+	return s.toPageResponse(page, permission, childCounts[pageID]), nil
 }
 
 func (s *pageService) GetPageWithBlocks(ctx context.Context, userID int64, pageID string) (*PageResponse, error) {
@@ -175,7 +257,35 @@ func (s *pageService) GetPageWithBlocks(ctx context.Context, userID int64, pageI
 	return pageResponse, nil
 }
 
-func (s *pageService) GetWorkspacePages(ctx context.Context, userID int64, workspaceID int64, includeArchived bool) ([]PageResponse, error) {
+// GetPageForViewer returns a page and its blocks read-only without a user
+// session. Callers must have already validated a viewer token for pageID.
+func (s *pageService) GetPageForViewer(ctx context.Context, pageID string) (*PageResponse, error) {
+	page, err := s.pageRepo.GetByID(ctx, pageID)
+	if err != nil {
+		s.logger.Error("Failed to get page", "error", err, "page_id", pageID)
+		return nil, NewInternalError("Failed to get page")
+	}
+
+	if page == nil || page.IsArchived {
+		return nil, NewNotFoundError("Page not found")
+	}
+
+	blocks, err := s.blockRepo.GetByPageID(ctx, pageID)
+	if err != nil {
+		s.logger.Error("Failed to get page blocks", "error", err, "page_id", pageID)
+		return nil, NewInternalError("Failed to get page blocks")
+	}
+
+	response := s.toPageResponse(page, repository.PermissionView, 0)
+	response.Blocks = make([]BlockResponse, len(blocks))
+	for i, block := range blocks {
+		response.Blocks[i] = s.toBlockResponse(block)
+	}
+
+	return response, nil
+}
+
+func (s *pageService) GetWorkspacePages(ctx context.Context, userID int64, workspaceID int64, includeArchived, includeTemplates bool) ([]PageResponse, error) {
 	// Check workspace access
 	hasAccess, err := s.workspaceRepo.HasAccess(ctx, workspaceID, userID)
 	if err != nil {
@@ -187,46 +297,16 @@ func (s *pageService) GetWorkspacePages(ctx context.Context, userID int64, works
 		return nil, NewForbiddenError("Access denied to workspace")
 	}
 
-	pages, err := s.pageRepo.GetByWorkspaceID(ctx, workspaceID, includeArchived)
+	pages, err := s.pageRepo.GetByWorkspaceID(ctx, workspaceID, includeArchived, includeTemplates)
 	if err != nil {
 		s.logger.Error("Failed to get workspace pages", "error", err, "workspace_id", workspaceID)
 		return nil, NewInternalError("Failed to get pages")
 	}
 
-	responses := make([]PageResponse, 0, len(pages))
-	for _, page := range pages {
-		// Check if user has permission to view this page
-		hasPermission, err := s.pageRepo.HasPermission(ctx, page.ID, userID, repository.PermissionView)
-		if err != nil {
-			s.logger.Error("Failed to check page permission", "error", err, "page_id", page.ID, "user_id", userID)
-			continue
-		}
-
-		if !hasPermission {
-			continue
-		}
-
-		// Get user's permission level
-		permission, err := s.getUserPermissionLevel(ctx, userID, page.ID)
-		if err != nil {
-			s.logger.Error("Failed to get user permission level", "error", err, "page_id", page.ID, "user_id", userID)
-			continue
-		}
-
-		// Get children count
-		children, err := s.pageRepo.GetByParentID(ctx, page.ID, false)
-		if err != nil {
-			s.logger.Error("Failed to get child pages", "error", err, "page_id", page.ID)
-			continue
-		}
-
-		responses = append(responses, *s.toPageResponse(page, permission, len(children)))
-	}
-
-	return responses, nil
+	return s.toVisiblePageResponses(ctx, userID, pages)
 }
 
-func (s *pageService) GetChildPages(ctx context.Context, userID int64, parentPageID string, includeArchived bool) ([]PageResponse, error) {
+func (s *pageService) GetChildPages(ctx context.Context, userID int64, parentPageID string, includeArchived, includeTemplates bool) ([]PageResponse, error) {
 	// Check permission to parent page
 	hasPermission, err := s.pageRepo.HasPermission(ctx, parentPageID, userID, repository.PermissionView)
 	if err != nil {
@@ -238,46 +318,37 @@ func (s *pageService) GetChildPages(ctx context.Context, userID int64, parentPag
 		return nil, NewForbiddenError("Access denied to parent page")
 	}
 
-	pages, err := s.pageRepo.GetByParentID(ctx, parentPageID, includeArchived)
+	pages, err := s.pageRepo.GetByParentID(ctx, parentPageID, includeArchived, includeTemplates)
 	if err != nil {
 		s.logger.Error("Failed to get child pages", "error", err, "parent_id", parentPageID)
 		return nil, NewInternalError("Failed to get child pages")
 	}
 
-	responses := make([]PageResponse, 0, len(pages))
-	for _, page := range pages {
-		// Check if user has permission to view this page
-		hasPermission, err := s.pageRepo.HasPermission(ctx, page.ID, userID, repository.PermissionView)
-		if err != nil {
-			s.logger.Error("Failed to check page permission", "error", err, "page_id", page.ID, "user_id", userID)
-			continue
-		}
-
-		if !hasPermission {
-			continue
-		}
+	return s.toVisiblePageResponses(ctx, userID, pages)
+}
 
-		// Get user's permission level
-		permission, err := s.getUserPermissionLevel(ctx, userID, page.ID)
-		if err != nil {
-			s.logger.Error("Failed to get user permission level", "error", err, "page_id", page.ID, "user_id", userID)
-			continue
-		}
+func (s *pageService) GetWorkspaceTemplates(ctx context.Context, userID int64, workspaceID int64) ([]PageResponse, error) {
+	// Check workspace access
+	hasAccess, err := s.workspaceRepo.HasAccess(ctx, workspaceID, userID)
+	if err != nil {
+		s.logger.Error("Failed to check workspace access", "error", err, "workspace_id", workspaceID, "user_id", userID)
+		return nil, NewInternalError("Failed to verify workspace access")
+	}
 
-		// Get children count
-		children, err := s.pageRepo.GetByParentID(ctx, page.ID, false)
-		if err != nil {
-			s.logger.Error("Failed to get child pages", "error", err, "page_id", page.ID)
-			continue
-		}
+	if !hasAccess {
+		return nil, NewForbiddenError("Access denied to workspace")
+	}
 
-		responses = append(responses, *s.toPageResponse(page, permission, len(children)))
+	pages, err := s.pageRepo.GetTemplates(ctx, workspaceID)
+	if err != nil {
+		s.logger.Error("Failed to get templates", "error", err, "workspace_id", workspaceID)
+		return nil, NewInternalError("Failed to get templates")
 	}
 
-	return responses, nil
+	return s.toVisiblePageResponses(ctx, userID, pages)
 }
 
-func (s *pageService) GetRootPages(ctx context.Context, userID int64, workspaceID int64, includeArchived bool) ([]PageResponse, error) {
+func (s *pageService) GetRootPages(ctx context.Context, userID int64, workspaceID int64, includeArchived, includeTemplates bool) ([]PageResponse, error) {
 	// Check workspace access
 	hasAccess, err := s.workspaceRepo.HasAccess(ctx, workspaceID, userID)
 	if err != nil {
@@ -289,43 +360,234 @@ func (s *pageService) GetRootPages(ctx context.Context, userID int64, workspaceI
 		return nil, NewForbiddenError("Access denied to workspace")
 	}
 
-	pages, err := s.pageRepo.GetRootPages(ctx, workspaceID, includeArchived)
+	pages, err := s.pageRepo.GetRootPages(ctx, workspaceID, includeArchived, includeTemplates)
 	if err != nil {
 		s.logger.Error("Failed to get root pages", "error", err, "workspace_id", workspaceID)
 		return nil, NewInternalError("Failed to get root pages")
 	}
 
-	responses := make([]PageResponse, 0, len(pages))
-	for _, page := range pages {
-		// Check if user has permission to view this page
-		hasPermission, err := s.pageRepo.HasPermission(ctx, page.ID, userID, repository.PermissionView)
-		if err != nil {
-			s.logger.Error("Failed to check page permission", "error", err, "page_id", page.ID, "user_id", userID)
-			continue
-		}
+	return s.toVisiblePageResponses(ctx, userID, pages)
+}
 
-		if !hasPermission {
+// GetPageAncestors returns the breadcrumb trail from the workspace root to the
+// page. Ancestors the user cannot view are left out.
+func (s *pageService) GetPageAncestors(ctx context.Context, userID int64, pageID string) ([]PageResponse, error) {
+	// Check permission
+	hasPermission, err := s.pageRepo.HasPermission(ctx, pageID, userID, repository.PermissionView)
+	if err != nil {
+		s.logger.Error("Failed to check page permission", "error", err, "page_id", pageID, "user_id", userID)
+		return nil, NewInternalError("Failed to verify page access")
+	}
+
+	if !hasPermission {
+		return nil, NewForbiddenError("Access denied to page")
+	}
+
+	ancestors, err := s.pageRepo.GetAncestors(ctx, pageID)
+	if err != nil {
+		s.logger.Error("Failed to get page ancestors", "error", err, "page_id", pageID)
+		return nil, NewInternalError("Failed to get page ancestors")
+	}
+
+	if len(ancestors) == 0 {
+		return nil, NewNotFoundError("Page not found")
+	}
+
+	pageIDs := make([]string, len(ancestors))
+	for i, page := range ancestors {
+		pageIDs[i] = page.ID
+	}
+
+	levels, err := s.pageRepo.GetUserPermissionLevels(ctx, userID, pageIDs)
+	if err != nil {
+		s.logger.Error("Failed to get user permission levels", "error", err, "page_id", pageID, "user_id", userID)
+		return nil, NewInternalError("Failed to get permission level")
+	}
+
+	childCounts, err := s.pageRepo.CountChildren(ctx, pageIDs)
+	if err != nil {
+		s.logger.Error("Failed to count child pages", "error", err, "page_id", pageID)
+		return nil, NewInternalError("Failed to get child pages")
+	}
+
+	responses := make([]PageResponse, 0, len(ancestors))
+	for _, page := range ancestors {
+		// Ancestors without access are skipped
+		permission, ok := levels[page.ID]
+		if !ok {
 			continue
 		}
 
-		// Get user's permission level
-		permission, err := s.getUserPermissionLevel(ctx, userID, page.ID)
-		if err != nil {
-			s.logger.Error("Failed to get user permission level", "error", err, "page_id", page.ID, "user_id", userID)
-			continue
+		responses = append(responses, *s.toPageResponse(page, permission, childCounts[page.ID]))
+	}
+
+	return responses, nil
+}
+
+const (
+	// DefaultPageTreeDepth is used when the caller does not pass max_depth
+	DefaultPageTreeDepth = 3
+	// MaxPageTreeDepth bounds the size of a single tree response
+	MaxPageTreeDepth = 10
+)
+
+// GetPageTree returns the workspace pages the user can view as a nested
+// tree. A visible page whose parent is hidden from the user is shown at the
+// top level, so pages shared with the user stay reachable.
+func (s *pageService) GetPageTree(ctx context.Context, userID int64, workspaceID int64, maxDepth int, includeArchived, includeTemplates bool) ([]PageTreeNode, error) {
+	// Check workspace access
+	hasAccess, err := s.workspaceRepo.HasAccess(ctx, workspaceID, userID)
+	if err != nil {
+		s.logger.Error("Failed to check workspace access", "error", err, "workspace_id", workspaceID, "user_id", userID)
+		return nil, NewInternalError("Failed to verify workspace access")
+	}
+
+	if !hasAccess {
+		return nil, NewForbiddenError("Access denied to workspace")
+	}
+
+	if maxDepth <= 0 {
+		maxDepth = DefaultPageTreeDepth
+	}
+	if maxDepth > MaxPageTreeDepth {
+		return nil, NewBadRequestError(fmt.Sprintf("max_depth cannot exceed %d", MaxPageTreeDepth))
+	}
+
+	entries, err := s.pageRepo.GetTree(ctx, workspaceID, userID, maxDepth, includeArchived, includeTemplates)
+	if err != nil {
+		s.logger.Error("Failed to get page tree", "error", err, "workspace_id", workspaceID)
+		return nil, NewInternalError("Failed to get page tree")
+	}
+
+	returned := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		returned[entry.ID] = true
+	}
+
+	// Entries arrive parents first, so children can be grouped before nesting
+	childrenOf := make(map[string][]*repository.PageTreeEntry)
+	var roots []*repository.PageTreeEntry
+	for _, entry := range entries {
+		if entry.ParentID == nil || !returned[*entry.ParentID] {
+			roots = append(roots, entry)
+		} else {
+			childrenOf[*entry.ParentID] = append(childrenOf[*entry.ParentID], entry)
 		}
+	}
 
-		// Get children count
-		children, err := s.pageRepo.GetByParentID(ctx, page.ID, false)
-		if err != nil {
-			s.logger.Error("Failed to get child pages", "error", err, "page_id", page.ID)
-			continue
+	var build func(entry *repository.PageTreeEntry) PageTreeNode
+	build = func(entry *repository.PageTreeEntry) PageTreeNode {
+		node := PageTreeNode{
+			ID:          entry.ID,
+			Title:       entry.Title,
+			ParentID:    entry.ParentID,
+			Icon:        entry.Icon,
+			IsArchived:  entry.IsArchived,
+			IsTemplate:  entry.IsTemplate,
+			UpdatedAt:   entry.UpdatedAt,
+			HasChildren: entry.HasChildren,
+			Children:    make([]PageTreeNode, 0, len(childrenOf[entry.ID])),
+		}
+		for _, child := range childrenOf[entry.ID] {
+			node.Children = append(node.Children, build(child))
 		}
+		return node
+	}
 
-		responses = append(responses, *s.toPageResponse(page, permission, len(children)))
+	tree := make([]PageTreeNode, 0, len(roots))
+	for _, root := range roots {
+		tree = append(tree, build(root))
 	}
 
-	return responses, nil
+	return tree, nil
+}
+
+// ExportPageMarkdown renders the page title and blocks as a Markdown document
+func (s *pageService) ExportPageMarkdown(ctx context.Context, userID int64, pageID string) (string, error) {
+	// Check permission
+	hasPermission, err := s.pageRepo.HasPermission(ctx, pageID, userID, repository.PermissionView)
+	if err != nil {
+		s.logger.Error("Failed to check page permission", "error", err, "page_id", pageID, "user_id", userID)
+		return "", NewInternalError("Failed to verify page access")
+	}
+
+	if !hasPermission {
+		return "", NewForbiddenError("Access denied to page")
+	}
+
+	page, err := s.pageRepo.GetByID(ctx, pageID)
+	if err != nil {
+		s.logger.Error("Failed to get page", "error", err, "page_id", pageID)
+		return "", NewInternalError("Failed to get page")
+	}
+
+	if page == nil {
+		return "", NewNotFoundError("Page not found")
+	}
+
+	blocks, err := s.blockRepo.GetByPageID(ctx, pageID)
+	if err != nil {
+		s.logger.Error("Failed to get page blocks", "error", err, "page_id", pageID)
+		return "", NewInternalError("Failed to get page blocks")
+	}
+
+	return blocksToMarkdown(page.Title, blocks), nil
+}
+
+// AttachPreviews fills in a short text preview for pages already returned by a
+// list call, so it performs no permission checks of its own
+func (s *pageService) AttachPreviews(ctx context.Context, pages []PageResponse) error {
+	if len(pages) == 0 {
+		return nil
+	}
+
+	pageIDs := make([]string, 0, len(pages))
+	for _, page := range pages {
+		pageIDs = append(pageIDs, page.ID)
+	}
+
+	blocksByPage, err := s.blockRepo.GetLeadingBlocks(ctx, pageIDs, previewBlockCount)
+	if err != nil {
+		s.logger.Error("Failed to get page preview blocks", "error", err, "page_count", len(pageIDs))
+		return NewInternalError("Failed to get page previews")
+	}
+
+	for i := range pages {
+		preview := buildPreview(blocksByPage[pages[i].ID])
+		pages[i].Preview = &preview
+	}
+
+	return nil
+}
+
+// attachSearchHits points each text search result at its best matching
+// block. Pages matched only by title get no hit.
+func (s *pageService) attachSearchHits(ctx context.Context, query string, pages []PageResponse) error {
+	if query == "" || len(pages) == 0 {
+		return nil
+	}
+
+	pageIDs := make([]string, 0, len(pages))
+	for _, page := range pages {
+		pageIDs = append(pageIDs, page.ID)
+	}
+
+	hits, err := s.blockRepo.SearchHits(ctx, pageIDs, query)
+	if err != nil {
+		s.logger.Error("Failed to get block search hits", "error", err, "page_count", len(pageIDs))
+		return NewInternalError("Failed to search pages")
+	}
+
+	for i := range pages {
+		if hit, ok := hits[pages[i].ID]; ok {
+			pages[i].SearchHit = &SearchHit{
+				BlockID: hit.BlockID,
+				Snippet: buildSearchSnippet(hit),
+			}
+		}
+	}
+
+	return nil
 }
 
 func (s *pageService) UpdatePage(ctx context.Context, userID int64, pageID string, req *UpdatePageRequest) (*PageResponse, error) {
@@ -370,6 +632,9 @@ func (s *pageService) UpdatePage(ctx context.Context, userID int64, pageID strin
 		page.IsTemplate = *req.IsTemplate
 	}
 	if req.Properties != nil {
+		if err := s.validateProperties(ctx, page.WorkspaceID, req.Properties); err != nil {
+			return nil, err
+		}
 		page.Properties = req.Properties
 	}
 
@@ -380,6 +645,8 @@ func (s *pageService) UpdatePage(ctx context.Context, userID int64, pageID strin
 		return nil, NewInternalError("Failed to update page")
 	}
 
+	s.recordPageActivity(page, userID, repository.ActivityPageUpdated, nil)
+
 	// Get user's permission level
 	permission, err := s.getUserPermissionLevel(ctx, userID, pageID)
 	if err != nil {
@@ -388,13 +655,13 @@ func (s *pageService) UpdatePage(ctx context.Context, userID int64, pageID strin
 	}
 
 	// Get children count
-	children, err := s.pageRepo.GetByParentID(ctx, pageID, false)
+	childCounts, err := s.pageRepo.CountChildren(ctx, []string{pageID})
 	if err != nil {
-		s.logger.Error("Failed to get child pages", "error", err, "page_id", pageID)
+		s.logger.Error("Failed to count child pages", "error", err, "page_id", pageID)
 		return nil, NewInternalError("Failed to get child pages")
 	}
 
-	return s.toPageResponse(page, permission, len(children)), nil
+	return s.toPageResponse(page, permission, childCounts[pageID]), nil
 }
 
 func (s *pageService) SavePageContent(ctx context.Context, userID int64, pageID string, req *SavePageContentRequest) (*PageResponse, error) {
@@ -405,6 +672,24 @@ func (s *pageService) SavePageContent(ctx context.Context, userID int64, pageID
 
 	s.logger.Info("SavePageContent called", "page_id", pageID, "user_id", userID, "has_title", req.Title != nil)
 
+	// Reject the whole save up front so a bad block never leaves the page half written
+	if issues := s.blockTypes.ValidateContent(req.Content); len(issues) > 0 {
+		s.logger.Warn("Rejected invalid page content", "page_id", pageID, "user_id", userID, "issues", len(issues))
+		return nil, newContentValidationError(issues)
+	}
+
+	// Sanitize before anything is stored, so blocks and the version history
+	// only ever hold the cleaned content
+	content, sanitizedBlocks, err := s.blockTypes.SanitizeContent(req.Content, s.xss)
+	if err != nil {
+		s.logger.Error("Failed to sanitize content", "error", err, "page_id", pageID)
+		return nil, NewBadRequestError("Invalid content format")
+	}
+	if len(sanitizedBlocks) > 0 {
+		s.logger.Warn("Removed unsafe markup from page content",
+			"page_id", pageID, "user_id", userID, "blocks", sanitizedBlocks)
+	}
+
 	// Check permission
 	hasPermission, err := s.pageRepo.HasPermission(ctx, pageID, userID, repository.PermissionEdit)
 	if err != nil {
@@ -449,17 +734,13 @@ func (s *pageService) SavePageContent(ctx context.Context, userID int64, pageID
 		Version string                   `json:"version"`
 	}
 
-	if err := json.Unmarshal(req.Content, &editorContent); err != nil {
-		s.logger.Error("Failed to unmarshal content", "error", err, "page_id", pageID, "content_length", len(req.Content))
+	if err := json.Unmarshal(content, &editorContent); err != nil {
+		s.logger.Error("Failed to unmarshal content", "error", err, "page_id", pageID, "content_length", len(content))
 		return nil, NewBadRequestError("Invalid content format")
 	}
 
 	s.logger.Info("Parsed EditorJS content", "page_id", pageID, "blocks_count", len(editorContent.Blocks))
 
-	// Simplified approach: Delete all existing blocks and recreate them
-	// This is more reliable than trying to diff and update
-	
-	// Delete all existing blocks first
 	existingBlocks, err := s.blockRepo.GetByPageID(ctx, pageID)
 	if err != nil {
 		s.logger.Error("Failed to get existing blocks", "error", err, "page_id", pageID)
@@ -468,62 +749,83 @@ func (s *pageService) SavePageContent(ctx context.Context, userID int64, pageID
 
 	s.logger.Info("Found existing blocks", "page_id", pageID, "existing_count", len(existingBlocks))
 
-	if len(existingBlocks) > 0 {
-		var blockIDsToDelete []string
-		for _, block := range existingBlocks {
-			blockIDsToDelete = append(blockIDsToDelete, block.ID)
-		}
-		
-		s.logger.Info("Deleting existing blocks", "page_id", pageID, "count", len(blockIDsToDelete))
-		if err := s.blockRepo.BulkDelete(ctx, blockIDsToDelete); err != nil {
-			s.logger.Error("Failed to delete existing blocks", "error", err, "page_id", pageID)
-			return nil, NewInternalError("Failed to delete existing blocks")
-		}
-		s.logger.Info("Existing blocks deleted successfully", "page_id", pageID)
+	existingIDs := make(map[string]bool, len(existingBlocks))
+	for _, block := range existingBlocks {
+		existingIDs[block.ID] = true
 	}
 
-	// Create new blocks from EditorJS content
-	if len(editorContent.Blocks) > 0 {
-		var blocksToCreate []*repository.Block
+	// Build the desired block list, keeping block IDs stable across saves
+	incomingBlocks := make([]*repository.Block, 0, len(editorContent.Blocks))
+	usedIDs := make(map[string]bool, len(editorContent.Blocks))
 
-		for i, blockData := range editorContent.Blocks {
-			blockType, ok := blockData["type"].(string)
-			if !ok {
-				s.logger.Warn("Skipping block with missing type", "page_id", pageID, "block_index", i)
-				continue
-			}
+	for i, blockData := range editorContent.Blocks {
+		blockType, ok := blockData["type"].(string)
+		if !ok {
+			s.logger.Warn("Skipping block with missing type", "page_id", pageID, "block_index", i)
+			continue
+		}
 
-			data, ok := blockData["data"]
-			if !ok {
-				data = map[string]interface{}{}
-			}
+		data, ok := blockData["data"]
+		if !ok {
+			data = map[string]interface{}{}
+		}
 
-			blockDataJSON, err := json.Marshal(data)
-			if err != nil {
-				s.logger.Error("Failed to marshal block data", "error", err, "page_id", pageID, "block_index", i)
-				continue
-			}
+		blockDataJSON, err := json.Marshal(data)
+		if err != nil {
+			s.logger.Error("Failed to marshal block data", "error", err, "page_id", pageID, "block_index", i)
+			continue
+		}
 
-			newBlock := &repository.Block{
-				PageID:       pageID,
-				BlockType:    blockType,
-				BlockData:    json.RawMessage(blockDataJSON),
-				Position:     i,
-				CreatedBy:    userID,
-				LastEditedBy: &userID,
-			}
+		clientID, _ := blockData["id"].(string)
+		blockID := resolveBlockID(pageID, clientID, existingIDs)
+		if usedIDs[blockID] {
+			// Duplicated client ids (e.g. copy/paste) become separate blocks
+			blockID = uuid.New().String()
+		}
+		usedIDs[blockID] = true
+
+		incomingBlocks = append(incomingBlocks, &repository.Block{
+			ID:           blockID,
+			PageID:       pageID,
+			BlockType:    blockType,
+			BlockData:    json.RawMessage(blockDataJSON),
+			Position:     i,
+			CreatedBy:    userID,
+			LastEditedBy: &userID,
+		})
+	}
+
+	changes := diffBlocks(existingBlocks, incomingBlocks)
+	s.logger.Info("Computed block changes", "page_id", pageID,
+		"created", len(changes.Create), "updated", len(changes.Update),
+		"deleted", len(changes.Delete), "reordered", len(changes.Order) > 0)
+
+	if len(changes.Delete) > 0 {
+		if err := s.blockRepo.BulkDelete(ctx, changes.Delete); err != nil {
+			s.logger.Error("Failed to delete removed blocks", "error", err, "page_id", pageID)
+			return nil, NewInternalError("Failed to delete removed blocks")
+		}
+	}
 
-			blocksToCreate = append(blocksToCreate, newBlock)
+	if len(changes.Create) > 0 {
+		if err := s.blockRepo.BulkCreate(ctx, changes.Create); err != nil {
+			s.logger.Error("Failed to create blocks", "error", err, "page_id", pageID)
+			return nil, NewInternalError("Failed to create blocks")
 		}
+	}
 
-		s.logger.Info("Creating new blocks", "page_id", pageID, "count", len(blocksToCreate))
-		
-		if len(blocksToCreate) > 0 {
-			if err := s.blockRepo.BulkCreate(ctx, blocksToCreate); err != nil {
-				s.logger.Error("Failed to create blocks", "error", err, "page_id", pageID)
-				return nil, NewInternalError("Failed to create blocks")
-			}
-			s.logger.Info("New blocks created successfully", "page_id", pageID, "count", len(blocksToCreate))
+	if len(changes.Update) > 0 {
+		if err := s.blockRepo.BulkUpdate(ctx, changes.Update); err != nil {
+			s.logger.Error("Failed to update blocks", "error", err, "page_id", pageID)
+			return nil, NewInternalError("Failed to update blocks")
+		}
+	}
+
+	// Position-only changes are applied last, once the page holds exactly the incoming blocks
+	if len(changes.Order) > 0 {
+		if err := s.blockRepo.ReorderBlocks(ctx, pageID, changes.Order); err != nil {
+			s.logger.Error("Failed to reorder blocks", "error", err, "page_id", pageID)
+			return nil, NewInternalError("Failed to reorder blocks")
 		}
 	}
 
@@ -533,16 +835,28 @@ func (s *pageService) SavePageContent(ctx context.Context, userID int64, pageID
 		
 		// Simple version numbering - just increment
 		versionNumber := 1
+		titleChanged := false
 		versions, err := s.pageRepo.GetVersions(ctx, pageID, 1, 0)
 		if err == nil && len(versions) > 0 {
 			versionNumber = versions[0].VersionNumber + 1
+			titleChanged = req.Title != nil && (versions[0].Title == nil || *versions[0].Title != *req.Title)
+		}
+
+		summary := summarizeBlockChanges(changes)
+		if titleChanged {
+			if summary == noChangesSummary {
+				summary = "title changed"
+			} else {
+				summary += ", title changed"
+			}
 		}
 
 		version := &repository.PageVersion{
 			PageID:        pageID,
 			VersionNumber: versionNumber,
 			Title:         req.Title,
-			Content:       req.Content,
+			Content:       content,
+			ChangeSummary: &summary,
 			CreatedBy:     userID,
 		}
 
@@ -555,30 +869,769 @@ func (s *pageService) SavePageContent(ctx context.Context, userID int64, pageID
 	}
 
 	s.logger.Info("SavePageContent completed successfully", "page_id", pageID)
-	return s.GetPageWithBlocks(ctx, userID, pageID)
+	response, err := s.GetPageWithBlocks(ctx, userID, pageID)
+	if err != nil {
+		return nil, err
+	}
+	response.ContentSanitized = len(sanitizedBlocks) > 0
+	return response, nil
 }
 
-func (s *pageService) DeletePage(ctx context.Context, userID int64, pageID string) error {
+func (s *pageService) MovePage(ctx context.Context, userID int64, pageID string, req *MovePageRequest) (*PageResponse, error) {
 	// Check permission
-	hasPermission, err := s.pageRepo.HasPermission(ctx, pageID, userID, repository.PermissionAdmin)
+	hasPermission, err := s.pageRepo.HasPermission(ctx, pageID, userID, repository.PermissionEdit)
 	if err != nil {
 		s.logger.Error("Failed to check page permission", "error", err, "page_id", pageID, "user_id", userID)
-		return NewInternalError("Failed to verify page access")
+		return nil, NewInternalError("Failed to verify page access")
 	}
 
 	if !hasPermission {
-		return NewForbiddenError("Access denied to delete page")
+		return nil, NewForbiddenError("Access denied to move page")
 	}
 
-	if err := s.pageRepo.Delete(ctx, pageID); err != nil {
-		s.logger.Error("Failed to delete page", "error", err, "page_id", pageID)
-		return NewInternalError("Failed to delete page")
+	page, err := s.pageRepo.GetByID(ctx, pageID)
+	if err != nil {
+		s.logger.Error("Failed to get page", "error", err, "page_id", pageID)
+		return nil, NewInternalError("Failed to get page")
 	}
 
-	return nil
+	if page == nil {
+		return nil, NewNotFoundError("Page not found")
+	}
+
+	targetWorkspaceID := page.WorkspaceID
+	if req.NewWorkspaceID != nil {
+		targetWorkspaceID = *req.NewWorkspaceID
+	}
+
+	if req.NewParentID != nil {
+		parent, err := s.pageRepo.GetByID(ctx, *req.NewParentID)
+		if err != nil {
+			s.logger.Error("Failed to get parent page", "error", err, "page_id", *req.NewParentID)
+			return nil, NewInternalError("Failed to get parent page")
+		}
+
+		if parent == nil {
+			return nil, NewNotFoundError("Parent page not found")
+		}
+
+		// Without an explicit workspace the page follows its new parent
+		if req.NewWorkspaceID == nil {
+			targetWorkspaceID = parent.WorkspaceID
+		} else if parent.WorkspaceID != targetWorkspaceID {
+			return nil, NewBadRequestError("Parent page belongs to a different workspace")
+		}
+
+		hasPermission, err := s.pageRepo.HasPermission(ctx, parent.ID, userID, repository.PermissionEdit)
+		if err != nil {
+			s.logger.Error("Failed to check page permission", "error", err, "page_id", parent.ID, "user_id", userID)
+			return nil, NewInternalError("Failed to verify parent page access")
+		}
+
+		if !hasPermission {
+			return nil, NewForbiddenError("Access denied to move pages under the target parent")
+		}
+	}
+
+	if targetWorkspaceID != page.WorkspaceID {
+		if err := s.requireWorkspaceEditor(ctx, userID, targetWorkspaceID); err != nil {
+			return nil, err
+		}
+	}
+
+	// The repository checks for a cycle while holding locks on the new parent chain
+	if err := s.pageRepo.Move(ctx, pageID, req.NewParentID, targetWorkspaceID, userID); err != nil {
+		if IsValidationError(err) {
+			return nil, NewBadRequestError("would create a page cycle")
+		}
+		if IsNotFoundError(err) {
+			return nil, NewNotFoundError("Page not found")
+		}
+		s.logger.Error("Failed to move page", "error", err, "page_id", pageID)
+		return nil, NewInternalError("Failed to move page")
+	}
+
+	return s.GetPage(ctx, userID, pageID)
+}
+
+func (s *pageService) DuplicatePage(ctx context.Context, userID int64, pageID string, req *DuplicatePageRequest) (*PageResponse, error) {
+	// Check permission
+	hasPermission, err := s.pageRepo.HasPermission(ctx, pageID, userID, repository.PermissionView)
+	if err != nil {
+		s.logger.Error("Failed to check page permission", "error", err, "page_id", pageID, "user_id", userID)
+		return nil, NewInternalError("Failed to verify page access")
+	}
+
+	if !hasPermission {
+		return nil, NewForbiddenError("Access denied to page")
+	}
+
+	source, err := s.pageRepo.GetByID(ctx, pageID)
+	if err != nil {
+		s.logger.Error("Failed to get page", "error", err, "page_id", pageID)
+		return nil, NewInternalError("Failed to get page")
+	}
+
+	if source == nil {
+		return nil, NewNotFoundError("Page not found")
+	}
+
+	// The copy is created next to the original
+	if source.ParentID != nil {
+		hasPermission, err := s.pageRepo.HasPermission(ctx, *source.ParentID, userID, repository.PermissionEdit)
+		if err != nil {
+			s.logger.Error("Failed to check parent page permission", "error", err, "page_id", *source.ParentID, "user_id", userID)
+			return nil, NewInternalError("Failed to verify parent page access")
+		}
+
+		if !hasPermission {
+			return nil, NewForbiddenError("Access denied to parent page")
+		}
+	} else {
+		hasAccess, err := s.workspaceRepo.HasAccess(ctx, source.WorkspaceID, userID)
+		if err != nil {
+			s.logger.Error("Failed to check workspace access", "error", err, "workspace_id", source.WorkspaceID, "user_id", userID)
+			return nil, NewInternalError("Failed to verify workspace access")
+		}
+
+		if !hasAccess {
+			return nil, NewForbiddenError("Access denied to workspace")
+		}
+	}
+
+	duplicate, childCount, err := s.duplicatePageTree(ctx, userID, source, source.ParentID, source.Title+" (Copy)", req.Recursive)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Page duplicated", "source_page_id", pageID, "page_id", duplicate.ID, "recursive", req.Recursive)
+	return s.toPageResponse(duplicate, repository.PermissionAdmin, childCount), nil
+}
+
+func (s *pageService) CreateFromTemplate(ctx context.Context, userID int64, templatePageID string, req *InstantiateTemplateRequest) (*PageResponse, error) {
+	// Validate input
+	if err := validateStruct(req); err != nil {
+		return nil, NewValidationError(err)
+	}
+
+	// Check permission
+	hasPermission, err := s.pageRepo.HasPermission(ctx, templatePageID, userID, repository.PermissionView)
+	if err != nil {
+		s.logger.Error("Failed to check page permission", "error", err, "page_id", templatePageID, "user_id", userID)
+		return nil, NewInternalError("Failed to verify page access")
+	}
+
+	if !hasPermission {
+		return nil, NewForbiddenError("Access denied to template")
+	}
+
+	template, err := s.pageRepo.GetByID(ctx, templatePageID)
+	if err != nil {
+		s.logger.Error("Failed to get page", "error", err, "page_id", templatePageID)
+		return nil, NewInternalError("Failed to get template")
+	}
+
+	if template == nil {
+		return nil, NewNotFoundError("Template not found")
+	}
+
+	if !template.IsTemplate {
+		return nil, NewBadRequestError("Page is not a template")
+	}
+
+	workspaceID := req.WorkspaceID
+	if workspaceID == 0 {
+		workspaceID = template.WorkspaceID
+	}
+
+	// Check workspace access
+	hasAccess, err := s.workspaceRepo.HasAccess(ctx, workspaceID, userID)
+	if err != nil {
+		s.logger.Error("Failed to check workspace access", "error", err, "workspace_id", workspaceID, "user_id", userID)
+		return nil, NewInternalError("Failed to verify workspace access")
+	}
+
+	if !hasAccess {
+		return nil, NewForbiddenError("Access denied to workspace")
+	}
+
+	if req.ParentID != nil {
+		parent, err := s.pageRepo.GetByID(ctx, *req.ParentID)
+		if err != nil {
+			s.logger.Error("Failed to get parent page", "error", err, "page_id", *req.ParentID)
+			return nil, NewInternalError("Failed to get parent page")
+		}
+
+		if parent == nil || parent.WorkspaceID != workspaceID {
+			return nil, NewBadRequestError("Parent page not found in workspace")
+		}
+
+		hasPermission, err := s.pageRepo.HasPermission(ctx, *req.ParentID, userID, repository.PermissionEdit)
+		if err != nil {
+			s.logger.Error("Failed to check parent page permission", "error", err, "page_id", *req.ParentID, "user_id", userID)
+			return nil, NewInternalError("Failed to verify parent page access")
+		}
+
+		if !hasPermission {
+			return nil, NewForbiddenError("Access denied to parent page")
+		}
+	}
+
+	vars := templateVariables(time.Now(), req.Variables)
+	title := req.Title
+	if title == "" {
+		title = substituteTemplateText(template.Title, vars)
+	}
+	if _, ok := req.Variables["title"]; !ok {
+		vars["title"] = title
+	}
+
+	page := &repository.Page{
+		Title:        title,
+		WorkspaceID:  workspaceID,
+		OwnerID:      userID,
+		ParentID:     req.ParentID,
+		Icon:         template.Icon,
+		CoverURL:     template.CoverURL,
+		IsTemplate:   false,
+		Properties:   template.Properties,
+		LastEditedBy: &userID,
+	}
+
+	if len(page.Properties) == 0 {
+		page.Properties = json.RawMessage("{}")
+	}
+
+	// The template may come from a workspace with a different schema
+	if err := s.validateProperties(ctx, workspaceID, page.Properties); err != nil {
+		return nil, err
+	}
+
+	blocks, err := s.blockRepo.GetByPageID(ctx, template.ID)
+	if err != nil {
+		s.logger.Error("Failed to get blocks", "error", err, "page_id", template.ID)
+		return nil, NewInternalError("Failed to get template blocks")
+	}
+
+	copies := copyBlocks(blocks, "", userID)
+	for _, block := range copies {
+		data, err := substituteTemplateBlockData(block.BlockData, vars)
+		if err != nil {
+			// Leave malformed data as it was rather than failing the copy
+			s.logger.Warn("Failed to fill template placeholders", "error", err, "template_id", template.ID, "block_id", block.ID)
+			continue
+		}
+		block.BlockData = data
+	}
+
+	// Variables are user input, so the filled-in blocks are checked like a save
+	if err := s.prepareBlocks(copies); err != nil {
+		return nil, err
+	}
+
+	if err := s.pageRepo.Create(ctx, page); err != nil {
+		s.logger.Error("Failed to create page from template", "error", err, "template_id", template.ID)
+		return nil, NewInternalError("Failed to create page from template")
+	}
+
+	for _, block := range copies {
+		block.PageID = page.ID
+	}
+
+	if err := s.blockRepo.BulkCreate(ctx, copies); err != nil {
+		s.logger.Error("Failed to copy template blocks", "error", err, "template_id", template.ID, "page_id", page.ID)
+		return nil, NewInternalError("Failed to copy template blocks")
+	}
+
+	s.recordPageActivity(page, userID, repository.ActivityPageCreated, map[string]interface{}{"template_id": template.ID})
+
+	s.logger.Info("Page created from template", "template_id", template.ID, "page_id", page.ID, "blocks", len(copies))
+	return s.toPageResponse(page, repository.PermissionAdmin, 0), nil
+}
+
+// duplicatePageTree copies a page and its blocks under parentID and, when
+// recursive, every child page the user can view. The copies are owned by the
+// user and carry no explicit page permissions. It returns the new page and
+// the number of child pages copied directly beneath it.
+func (s *pageService) duplicatePageTree(ctx context.Context, userID int64, source *repository.Page, parentID *string, title string, recursive bool) (*repository.Page, int, error) {
+	page := &repository.Page{
+		Title:        title,
+		WorkspaceID:  source.WorkspaceID,
+		OwnerID:      userID,
+		ParentID:     parentID,
+		Icon:         source.Icon,
+		CoverURL:     source.CoverURL,
+		IsTemplate:   source.IsTemplate,
+		Properties:   source.Properties,
+		LastEditedBy: &userID,
+	}
+
+	// The schema may have changed since the source was last saved
+	if err := s.validateProperties(ctx, page.WorkspaceID, page.Properties); err != nil {
+		return nil, 0, err
+	}
+
+	if err := s.pageRepo.Create(ctx, page); err != nil {
+		s.logger.Error("Failed to create page copy", "error", err, "source_page_id", source.ID)
+		return nil, 0, NewInternalError("Failed to duplicate page")
+	}
+
+	blocks, err := s.blockRepo.GetByPageID(ctx, source.ID)
+	if err != nil {
+		s.logger.Error("Failed to get blocks", "error", err, "page_id", source.ID)
+		return nil, 0, NewInternalError("Failed to get page blocks")
+	}
+
+	if err := s.blockRepo.BulkCreate(ctx, copyBlocks(blocks, page.ID, userID)); err != nil {
+		s.logger.Error("Failed to copy blocks", "error", err, "source_page_id", source.ID, "page_id", page.ID)
+		return nil, 0, NewInternalError("Failed to duplicate page blocks")
+	}
+
+	if !recursive {
+		return page, 0, nil
+	}
+
+	children, err := s.pageRepo.GetByParentID(ctx, source.ID, false, true)
+	if err != nil {
+		s.logger.Error("Failed to get child pages", "error", err, "page_id", source.ID)
+		return nil, 0, NewInternalError("Failed to get child pages")
+	}
+
+	copied := 0
+	for _, child := range children {
+		hasPermission, err := s.pageRepo.HasPermission(ctx, child.ID, userID, repository.PermissionView)
+		if err != nil {
+			s.logger.Error("Failed to check page permission", "error", err, "page_id", child.ID, "user_id", userID)
+			return nil, 0, NewInternalError("Failed to verify page access")
+		}
+
+		if !hasPermission {
+			continue
+		}
+
+		if _, _, err := s.duplicatePageTree(ctx, userID, child, &page.ID, child.Title, true); err != nil {
+			return nil, 0, err
+		}
+		copied++
+	}
+
+	return page, copied, nil
+}
+
+// copyBlocks clones blocks onto another page with fresh IDs, keeping their
+// positions and nesting. Parents are emitted before their children so the
+// parent_block_id foreign key is satisfied on insert.
+func copyBlocks(blocks []*repository.Block, pageID string, userID int64) []*repository.Block {
+	newIDs := make(map[string]string, len(blocks))
+	for _, block := range blocks {
+		newIDs[block.ID] = uuid.New().String()
+	}
+
+	copies := make([]*repository.Block, 0, len(blocks))
+	emitted := make(map[string]bool, len(blocks))
+	emit := func(block *repository.Block, parentID *string) {
+		copies = append(copies, &repository.Block{
+			ID:            newIDs[block.ID],
+			PageID:        pageID,
+			BlockType:     block.BlockType,
+			BlockData:     block.BlockData,
+			Position:      block.Position,
+			ParentBlockID: parentID,
+			CreatedBy:     userID,
+			LastEditedBy:  &userID,
+		})
+		emitted[block.ID] = true
+	}
+
+	for len(copies) < len(blocks) {
+		progressed := false
+		for _, block := range blocks {
+			if emitted[block.ID] {
+				continue
+			}
+
+			var parentID *string
+			if block.ParentBlockID != nil {
+				if newParentID, ok := newIDs[*block.ParentBlockID]; ok {
+					if !emitted[*block.ParentBlockID] {
+						continue
+					}
+					parentID = &newParentID
+				}
+			}
+
+			emit(block, parentID)
+			progressed = true
+		}
+
+		// Nesting cycles cannot be ordered; flatten whatever is left
+		if !progressed {
+			for _, block := range blocks {
+				if !emitted[block.ID] {
+					emit(block, nil)
+				}
+			}
+		}
+	}
+
+	return copies
+}
+
+// prepareBlocks puts blocks that did not come from the editor through the
+// same validation and sanitizing as a content save, replacing each block's
+// data with its cleaned form
+func (s *pageService) prepareBlocks(blocks []*repository.Block) error {
+	type documentBlock struct {
+		ID   string          `json:"id,omitempty"`
+		Type string          `json:"type"`
+		Data json.RawMessage `json:"data"`
+	}
+
+	var document struct {
+		Blocks []documentBlock `json:"blocks"`
+	}
+	document.Blocks = make([]documentBlock, 0, len(blocks))
+	for _, block := range blocks {
+		data := block.BlockData
+		if len(data) == 0 {
+			data = json.RawMessage("{}")
+		}
+		document.Blocks = append(document.Blocks, documentBlock{ID: block.ID, Type: block.BlockType, Data: data})
+	}
+
+	content, err := json.Marshal(document)
+	if err != nil {
+		s.logger.Error("Failed to encode blocks", "error", err)
+		return NewInternalError("Failed to check block content")
+	}
+
+	if issues := s.blockTypes.ValidateContent(content); len(issues) > 0 {
+		return newContentValidationError(issues)
+	}
+
+	content, sanitizedBlocks, err := s.blockTypes.SanitizeContent(content, s.xss)
+	if err != nil {
+		s.logger.Error("Failed to sanitize blocks", "error", err)
+		return NewInternalError("Failed to check block content")
+	}
+	if len(sanitizedBlocks) == 0 {
+		return nil
+	}
+	s.logger.Warn("Removed unsafe markup from blocks", "blocks", sanitizedBlocks)
+
+	var cleaned struct {
+		Blocks []documentBlock `json:"blocks"`
+	}
+	if err := json.Unmarshal(content, &cleaned); err != nil || len(cleaned.Blocks) != len(blocks) {
+		s.logger.Error("Failed to decode sanitized blocks", "error", err)
+		return NewInternalError("Failed to check block content")
+	}
+	for i, block := range blocks {
+		block.BlockData = cleaned.Blocks[i].Data
+	}
+	return nil
+}
+
+// ImportMarkdown creates a new page whose blocks are parsed from a Markdown document
+func (s *pageService) ImportMarkdown(ctx context.Context, userID int64, req *ImportMarkdownRequest) (*PageResponse, error) {
+	// Validate input
+	if err := validateStruct(req); err != nil {
+		return nil, NewValidationError(err)
+	}
+
+	parsed := markdownToBlocks(req.Markdown)
+
+	title := strings.TrimSpace(req.Title)
+	if title == "" && len(parsed) > 0 && parsed[0].Type == "heading" && parsed[0].Data["level"] == 1 {
+		text, _ := parsed[0].Data["text"].(string)
+		title = stripInlineHTML(text)
+		parsed = parsed[1:]
+	}
+
+	if len(parsed) > maxBlocksPerPage {
+		return nil, NewBadRequestError(fmt.Sprintf("Document has %d blocks, the maximum is %d", len(parsed), maxBlocksPerPage))
+	}
+
+	blocks := make([]*repository.Block, 0, len(parsed))
+	for i, block := range parsed {
+		blockDataJSON, err := json.Marshal(block.Data)
+		if err != nil {
+			s.logger.Error("Failed to marshal block data", "error", err, "block_index", i)
+			continue
+		}
+
+		blocks = append(blocks, &repository.Block{
+			BlockType:    block.Type,
+			BlockData:    json.RawMessage(blockDataJSON),
+			Position:     i,
+			CreatedBy:    userID,
+			LastEditedBy: &userID,
+		})
+	}
+
+	// Markdown may carry raw HTML, so the blocks are checked like a save
+	// before the page exists
+	if err := s.prepareBlocks(blocks); err != nil {
+		return nil, err
+	}
+
+	// CreatePage performs the workspace and parent access checks
+	page, err := s.CreatePage(ctx, userID, &CreatePageRequest{
+		Title:       title,
+		WorkspaceID: req.WorkspaceID,
+		ParentID:    req.ParentID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, block := range blocks {
+		block.PageID = page.ID
+	}
+
+	for start := 0; start < len(blocks); start += importBlockBatchSize {
+		end := start + importBlockBatchSize
+		if end > len(blocks) {
+			end = len(blocks)
+		}
+
+		if err := s.blockRepo.BulkCreate(ctx, blocks[start:end]); err != nil {
+			s.logger.Error("Failed to create imported blocks", "error", err, "page_id", page.ID)
+
+			// Don't leave a half-imported page behind
+			if err := s.pageRepo.Delete(ctx, page.ID); err == nil {
+				_ = s.pageRepo.Purge(ctx, page.ID)
+			}
+			return nil, NewInternalError("Failed to import page content")
+		}
+	}
+
+	s.logger.Info("Markdown imported", "page_id", page.ID, "blocks", len(blocks), "user_id", userID)
+	return s.GetPageWithBlocks(ctx, userID, page.ID)
+}
+
+// ReorderBlocks updates block positions without rewriting block content. The
+// order must cover exactly the blocks currently on the page.
+func (s *pageService) ReorderBlocks(ctx context.Context, userID int64, pageID string, order map[string]int) ([]BlockResponse, error) {
+	// Check permission
+	hasPermission, err := s.pageRepo.HasPermission(ctx, pageID, userID, repository.PermissionEdit)
+	if err != nil {
+		s.logger.Error("Failed to check page permission", "error", err, "page_id", pageID, "user_id", userID)
+		return nil, NewInternalError("Failed to verify page access")
+	}
+
+	if !hasPermission {
+		return nil, NewForbiddenError("Access denied to edit page")
+	}
+
+	if len(order) == 0 {
+		return nil, NewBadRequestError("Block order is required")
+	}
+
+	existing, err := s.blockRepo.GetByPageID(ctx, pageID)
+	if err != nil {
+		s.logger.Error("Failed to get blocks", "error", err, "page_id", pageID)
+		return nil, NewInternalError("Failed to get page blocks")
+	}
+
+	onPage := make(map[string]bool, len(existing))
+	for _, block := range existing {
+		onPage[block.ID] = true
+	}
+
+	for id := range order {
+		if !onPage[id] {
+			return nil, NewBadRequestError(fmt.Sprintf("Block %s does not belong to this page", id))
+		}
+	}
+
+	if len(order) != len(existing) {
+		return nil, NewBadRequestError("Block order must include every block on the page")
+	}
+
+	if err := s.blockRepo.ReorderBlocks(ctx, pageID, order); err != nil {
+		if appErr, ok := errors.AsAppError(err); ok {
+			return nil, appErr
+		}
+		s.logger.Error("Failed to reorder blocks", "error", err, "page_id", pageID)
+		return nil, NewInternalError("Failed to reorder blocks")
+	}
+
+	blocks, err := s.blockRepo.GetByPageID(ctx, pageID)
+	if err != nil {
+		s.logger.Error("Failed to get blocks", "error", err, "page_id", pageID)
+		return nil, NewInternalError("Failed to get page blocks")
+	}
+
+	responses := make([]BlockResponse, 0, len(blocks))
+	for _, block := range blocks {
+		responses = append(responses, s.toBlockResponse(block))
+	}
+
+	return responses, nil
+}
+
+// ValidateContent checks an EditorJS payload against the block registry without persisting it
+func (s *pageService) ValidateContent(ctx context.Context, req *SavePageContentRequest) (*ValidateContentResponse, error) {
+	// Validate input
+	if err := validateStruct(req); err != nil {
+		return nil, NewValidationError(err)
+	}
+
+	issues := s.blockTypes.ValidateContent(req.Content)
+
+	return &ValidateContentResponse{
+		Valid:  len(issues) == 0,
+		Issues: issues,
+	}, nil
+}
+
+func (s *pageService) DeletePage(ctx context.Context, userID int64, pageID string) error {
+	// Check permission
+	hasPermission, err := s.pageRepo.HasPermission(ctx, pageID, userID, repository.PermissionAdmin)
+	if err != nil {
+		s.logger.Error("Failed to check page permission", "error", err, "page_id", pageID, "user_id", userID)
+		return NewInternalError("Failed to verify page access")
+	}
+
+	if !hasPermission {
+		return NewForbiddenError("Access denied to delete page")
+	}
+
+	// The whole subtree goes to the trash with the page
+	if err := s.requireSubtreeAccess(ctx, userID, pageID, repository.PermissionAdmin, "Access denied to delete page"); err != nil {
+		return err
+	}
+
+	page, err := s.pageRepo.GetByID(ctx, pageID)
+	if err != nil {
+		s.logger.Error("Failed to get page", "error", err, "page_id", pageID)
+		return NewInternalError("Failed to get page")
+	}
+
+	if err := s.pageRepo.Delete(ctx, pageID); err != nil {
+		s.logger.Error("Failed to delete page", "error", err, "page_id", pageID)
+		return NewInternalError("Failed to delete page")
+	}
+
+	s.recordPageActivity(page, userID, repository.ActivityPageDeleted, nil)
+
+	return nil
+}
+
+func (s *pageService) ListTrash(ctx context.Context, userID int64, workspaceID int64) ([]PageResponse, error) {
+	// Check workspace access
+	hasAccess, err := s.workspaceRepo.HasAccess(ctx, workspaceID, userID)
+	if err != nil {
+		s.logger.Error("Failed to check workspace access", "error", err, "workspace_id", workspaceID, "user_id", userID)
+		return nil, NewInternalError("Failed to verify workspace access")
+	}
+
+	if !hasAccess {
+		return nil, NewForbiddenError("Access denied to workspace")
+	}
+
+	// Only pages the user could have deleted are listed
+	pages, err := s.pageRepo.ListTrash(ctx, workspaceID, userID)
+	if err != nil {
+		s.logger.Error("Failed to list trash", "error", err, "workspace_id", workspaceID, "user_id", userID)
+		return nil, NewInternalError("Failed to list trash")
+	}
+
+	responses := make([]PageResponse, 0, len(pages))
+	for _, page := range pages {
+		responses = append(responses, *s.toPageResponse(page, repository.PermissionAdmin, 0))
+	}
+
+	return responses, nil
+}
+
+func (s *pageService) RestoreFromTrash(ctx context.Context, userID int64, pageID string) error {
+	if _, err := s.getTrashedPageForAdmin(ctx, userID, pageID); err != nil {
+		return err
+	}
+
+	if err := s.pageRepo.RestoreFromTrash(ctx, pageID, userID); err != nil {
+		s.logger.Error("Failed to restore page from trash", "error", err, "page_id", pageID)
+		return NewInternalError("Failed to restore page from trash")
+	}
+
+	return nil
+}
+
+func (s *pageService) PurgePage(ctx context.Context, userID int64, pageID string) error {
+	if _, err := s.getTrashedPageForAdmin(ctx, userID, pageID); err != nil {
+		return err
+	}
+
+	if err := s.pageRepo.Purge(ctx, pageID); err != nil {
+		s.logger.Error("Failed to purge page", "error", err, "page_id", pageID)
+		return NewInternalError("Failed to purge page")
+	}
+
+	s.logger.Info("Page purged", "page_id", pageID, "user_id", userID)
+	return nil
+}
+
+// PurgeExpiredTrash permanently deletes pages that have been in the trash longer than retention
+func (s *pageService) PurgeExpiredTrash(ctx context.Context, retention time.Duration) (int64, error) {
+	purged, err := s.pageRepo.PurgeExpired(ctx, time.Now().UTC().Add(-retention))
+	if err != nil {
+		s.logger.Error("Failed to purge expired trash", "error", err)
+		return 0, NewInternalError("Failed to purge expired trash")
+	}
+
+	if purged > 0 {
+		s.logger.Info("Purged expired trashed pages", "deleted", purged)
+	}
+	return purged, nil
+}
+
+// getTrashedPageForAdmin loads a page from the trash, requiring admin access to it
+func (s *pageService) getTrashedPageForAdmin(ctx context.Context, userID int64, pageID string) (*repository.Page, error) {
+	page, err := s.pageRepo.GetTrashedByID(ctx, pageID)
+	if err != nil {
+		s.logger.Error("Failed to get trashed page", "error", err, "page_id", pageID)
+		return nil, NewInternalError("Failed to get page")
+	}
+
+	if page == nil {
+		return nil, NewNotFoundError("Trashed page")
+	}
+
+	hasPermission, err := s.pageRepo.HasTrashPermission(ctx, pageID, userID, repository.PermissionAdmin)
+	if err != nil {
+		s.logger.Error("Failed to check page permission", "error", err, "page_id", pageID, "user_id", userID)
+		return nil, NewInternalError("Failed to verify page access")
+	}
+
+	if !hasPermission {
+		return nil, NewForbiddenError("Access denied to trashed page")
+	}
+
+	return page, nil
+}
+
+// StartTrashPurge runs PurgeExpiredTrash on the given interval until ctx is cancelled
+func StartTrashPurge(ctx context.Context, pages PageService, interval, retention time.Duration, logger *slog.Logger) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := pages.PurgeExpiredTrash(ctx, retention); err != nil {
+					logger.Error("Trash purge failed", "error", err)
+				}
+			}
+		}
+	}()
 }
 
-func (s *pageService) ArchivePage(ctx context.Context, userID int64, pageID string) error {
+func (s *pageService) ArchivePage(ctx context.Context, userID int64, pageID string, cascade bool) error {
 	// Check permission
 	hasPermission, err := s.pageRepo.HasPermission(ctx, pageID, userID, repository.PermissionEdit)
 	if err != nil {
@@ -590,15 +1643,210 @@ func (s *pageService) ArchivePage(ctx context.Context, userID int64, pageID stri
 		return NewForbiddenError("Access denied to archive page")
 	}
 
-	if err := s.pageRepo.Archive(ctx, pageID, userID); err != nil {
+	if cascade {
+		if err := s.requireSubtreeAccess(ctx, userID, pageID, repository.PermissionEdit, "Access denied to archive page"); err != nil {
+			return err
+		}
+	}
+
+	page, err := s.pageRepo.GetByID(ctx, pageID)
+	if err != nil {
+		s.logger.Error("Failed to get page", "error", err, "page_id", pageID)
+		return NewInternalError("Failed to get page")
+	}
+
+	if err := s.pageRepo.Archive(ctx, pageID, cascade, userID); err != nil {
 		s.logger.Error("Failed to archive page", "error", err, "page_id", pageID)
 		return NewInternalError("Failed to archive page")
 	}
 
+	s.recordPageActivity(page, userID, repository.ActivityPageArchived, map[string]interface{}{"cascade": cascade})
+
+	return nil
+}
+
+func (s *pageService) BulkArchivePages(ctx context.Context, userID int64, pageIDs []string, includeDescendants bool) (*BulkPagesResponse, error) {
+	if err := validateStruct(&BulkPagesRequest{PageIDs: pageIDs}); err != nil {
+		return nil, NewValidationError(err)
+	}
+
+	pages, result, err := s.resolveBulkTargets(ctx, userID, pageIDs, repository.PermissionEdit, includeDescendants, "Access denied to archive page")
+	if err != nil {
+		return nil, err
+	}
+
+	var affected int64
+	if len(pages) > 0 {
+		affected, err = s.pageRepo.BulkArchive(ctx, bulkPageIDs(pages), includeDescendants, userID)
+		if err != nil {
+			s.logger.Error("Failed to bulk archive pages", "error", err, "user_id", userID, "count", len(pages))
+			return nil, NewInternalError("Failed to archive pages")
+		}
+
+		for _, page := range pages {
+			s.recordPageActivity(page, userID, repository.ActivityPageArchived, map[string]interface{}{
+				"bulk":                true,
+				"include_descendants": includeDescendants,
+			})
+		}
+	}
+
+	if result.HasFailures() {
+		s.logger.Info("Bulk archive skipped pages", "user_id", userID, "skipped", len(result.Failed))
+	}
+
+	return &BulkPagesResponse{BatchResult: *result, Affected: affected}, nil
+}
+
+func (s *pageService) BulkDeletePages(ctx context.Context, userID int64, pageIDs []string) (*BulkPagesResponse, error) {
+	if err := validateStruct(&BulkPagesRequest{PageIDs: pageIDs}); err != nil {
+		return nil, NewValidationError(err)
+	}
+
+	pages, result, err := s.resolveBulkTargets(ctx, userID, pageIDs, repository.PermissionAdmin, true, "Access denied to delete page")
+	if err != nil {
+		return nil, err
+	}
+
+	var affected int64
+	if len(pages) > 0 {
+		affected, err = s.pageRepo.BulkDelete(ctx, bulkPageIDs(pages))
+		if err != nil {
+			s.logger.Error("Failed to bulk delete pages", "error", err, "user_id", userID, "count", len(pages))
+			return nil, NewInternalError("Failed to delete pages")
+		}
+
+		for _, page := range pages {
+			s.recordPageActivity(page, userID, repository.ActivityPageDeleted, map[string]interface{}{"bulk": true})
+		}
+	}
+
+	if result.HasFailures() {
+		s.logger.Info("Bulk delete skipped pages", "user_id", userID, "skipped", len(result.Failed))
+	}
+
+	return &BulkPagesResponse{BatchResult: *result, Affected: affected}, nil
+}
+
+// resolveBulkTargets checks each requested page in order, returning the
+// pages the user may change at requiredLevel and a batch result covering
+// every unique ID. With cascade, the change reaches every descendant too, so
+// a page is skipped unless the user holds requiredLevel on its whole subtree.
+// The returned pages are recorded as succeeded up front; the caller fails the
+// whole request if applying them does not succeed.
+func (s *pageService) resolveBulkTargets(ctx context.Context, userID int64, pageIDs []string, requiredLevel repository.PermissionLevel, cascade bool, deniedMessage string) ([]*repository.Page, *BatchResult[string], error) {
+	seen := make(map[string]bool, len(pageIDs))
+	candidates := make([]*repository.Page, 0, len(pageIDs))
+	result := NewBatchResult[string](len(pageIDs))
+
+	for _, pageID := range pageIDs {
+		if seen[pageID] {
+			continue
+		}
+		seen[pageID] = true
+
+		page, err := s.pageRepo.GetByID(ctx, pageID)
+		if err != nil {
+			s.logger.Error("Failed to get page", "error", err, "page_id", pageID)
+			return nil, nil, NewInternalError("Failed to get page")
+		}
+		if page == nil {
+			result.AddFailure(pageID, NewNotFoundError("Page"))
+			continue
+		}
+
+		hasPermission, err := s.pageRepo.HasPermission(ctx, pageID, userID, requiredLevel)
+		if err != nil {
+			s.logger.Error("Failed to check page permission", "error", err, "page_id", pageID, "user_id", userID)
+			return nil, nil, NewInternalError("Failed to verify page access")
+		}
+		if !hasPermission {
+			result.AddFailure(pageID, NewForbiddenError(deniedMessage))
+			continue
+		}
+
+		candidates = append(candidates, page)
+	}
+	result.Total = len(seen)
+
+	restricted := map[string]bool{}
+	if cascade && len(candidates) > 0 {
+		var err error
+		restricted, err = s.restrictedSubtrees(ctx, userID, bulkPageIDs(candidates), requiredLevel)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	pages := make([]*repository.Page, 0, len(candidates))
+	for _, page := range candidates {
+		if restricted[page.ID] {
+			result.AddFailure(page.ID, NewForbiddenError(deniedMessage+": it contains pages you cannot change"))
+			continue
+		}
+		pages = append(pages, page)
+		result.AddSuccess(page.ID)
+	}
+
+	return pages, result, nil
+}
+
+// restrictedSubtrees returns the listed pages with a descendant on which the
+// user lacks requiredLevel
+func (s *pageService) restrictedSubtrees(ctx context.Context, userID int64, pageIDs []string, requiredLevel repository.PermissionLevel) (map[string]bool, error) {
+	descendants, err := s.pageRepo.GetDescendantIDs(ctx, pageIDs)
+	if err != nil {
+		s.logger.Error("Failed to get page descendants", "error", err, "user_id", userID)
+		return nil, NewInternalError("Failed to verify page access")
+	}
+
+	var descendantIDs []string
+	for _, ids := range descendants {
+		descendantIDs = append(descendantIDs, ids...)
+	}
+	levels, err := s.pageRepo.GetUserPermissionLevels(ctx, userID, descendantIDs)
+	if err != nil {
+		s.logger.Error("Failed to get user permission levels", "error", err, "user_id", userID)
+		return nil, NewInternalError("Failed to verify page access")
+	}
+
+	restricted := make(map[string]bool)
+	for rootID, ids := range descendants {
+		for _, id := range ids {
+			if !levels[id].Includes(requiredLevel) {
+				restricted[rootID] = true
+				break
+			}
+		}
+	}
+	return restricted, nil
+}
+
+// requireSubtreeAccess applies the bulk rule to a single page: a change that
+// reaches the descendants is refused unless the user holds requiredLevel on
+// every one of them
+func (s *pageService) requireSubtreeAccess(ctx context.Context, userID int64, pageID string, requiredLevel repository.PermissionLevel, deniedMessage string) error {
+	restricted, err := s.restrictedSubtrees(ctx, userID, []string{pageID}, requiredLevel)
+	if err != nil {
+		return err
+	}
+
+	if restricted[pageID] {
+		return NewForbiddenError(deniedMessage + ": it contains pages you cannot change")
+	}
+
 	return nil
 }
 
-func (s *pageService) RestorePage(ctx context.Context, userID int64, pageID string) error {
+func bulkPageIDs(pages []*repository.Page) []string {
+	ids := make([]string, len(pages))
+	for i, page := range pages {
+		ids[i] = page.ID
+	}
+	return ids
+}
+
+func (s *pageService) RestorePage(ctx context.Context, userID int64, pageID string, cascade bool) error {
 	// Check permission
 	hasPermission, err := s.pageRepo.HasPermission(ctx, pageID, userID, repository.PermissionEdit)
 	if err != nil {
@@ -610,7 +1858,13 @@ func (s *pageService) RestorePage(ctx context.Context, userID int64, pageID stri
 		return NewForbiddenError("Access denied to restore page")
 	}
 
-	if err := s.pageRepo.Restore(ctx, pageID, userID); err != nil {
+	if cascade {
+		if err := s.requireSubtreeAccess(ctx, userID, pageID, repository.PermissionEdit, "Access denied to restore page"); err != nil {
+			return err
+		}
+	}
+
+	if err := s.pageRepo.Restore(ctx, pageID, cascade, userID); err != nil {
 		s.logger.Error("Failed to restore page", "error", err, "page_id", pageID)
 		return NewInternalError("Failed to restore page")
 	}
@@ -624,99 +1878,296 @@ func (s *pageService) SearchPages(ctx context.Context, userID int64, req *Search
 		return nil, NewValidationError(err)
 	}
 
+	if req.UpdatedAfter != nil && req.UpdatedBefore != nil && !req.UpdatedAfter.Before(*req.UpdatedBefore) {
+		return nil, NewBadRequestError("updated_after must be before updated_before")
+	}
+
+	// A blank query is a filter-only search rather than one that matches nothing
+	req.Query = strings.TrimSpace(req.Query)
+	filters := repository.SearchFilters{
+		IncludeArchived: req.IncludeArchived,
+		IsTemplate:      req.IsTemplate,
+		OwnerID:         req.OwnerID,
+		UpdatedAfter:    req.UpdatedAfter,
+		UpdatedBefore:   req.UpdatedBefore,
+	}
+
+	// Check workspace access
+	hasAccess, err := s.workspaceRepo.HasAccess(ctx, req.WorkspaceID, userID)
+	if err != nil {
+		s.logger.Error("Failed to check workspace access", "error", err, "workspace_id", req.WorkspaceID, "user_id", userID)
+		return nil, NewInternalError("Failed to verify workspace access")
+	}
+
+	if !hasAccess {
+		return nil, NewForbiddenError("Access denied to workspace")
+	}
+
+	// Search only returns pages the user can view, so the count matches the result set
+	total, err := s.pageRepo.SearchCount(ctx, req.WorkspaceID, userID, req.Query, filters)
+	if err != nil {
+		s.logger.Error("Failed to count search results", "error", err, "workspace_id", req.WorkspaceID, "query", req.Query)
+		return nil, NewInternalError("Failed to search pages")
+	}
+
+	responses := make([]PageResponse, 0, req.Limit)
+	offsetPastEnd := req.Pagination != SearchPaginationCursor && int64(req.Offset) >= total
+	if total == 0 || offsetPastEnd {
+		return &SearchPagesResponse{
+			Pages:  responses,
+			Total:  total,
+			Limit:  req.Limit,
+			Offset: req.Offset,
+		}, nil
+	}
+
+	if req.Pagination == SearchPaginationCursor {
+		after, err := decodePageCursor(req.Cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		pages, err := s.pageRepo.SearchAfter(ctx, req.WorkspaceID, userID, req.Query, filters, after, req.Limit+1)
+		if err != nil {
+			s.logger.Error("Failed to search pages", "error", err, "workspace_id", req.WorkspaceID, "query", req.Query)
+			return nil, NewInternalError("Failed to search pages")
+		}
+
+		pages, nextCursor := trimToCursorPage(pages, req.Limit)
+		responses, err = s.toVisiblePageResponses(ctx, userID, pages)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.attachSearchHits(ctx, req.Query, responses); err != nil {
+			return nil, err
+		}
+
+		return &SearchPagesResponse{
+			Pages:      responses,
+			Total:      total,
+			Limit:      req.Limit,
+			NextCursor: nextCursor,
+		}, nil
+	}
+
+	pages, err := s.pageRepo.Search(ctx, req.WorkspaceID, userID, req.Query, filters, req.Limit, req.Offset)
+	if err != nil {
+		s.logger.Error("Failed to search pages", "error", err, "workspace_id", req.WorkspaceID, "query", req.Query)
+		return nil, NewInternalError("Failed to search pages")
+	}
+
+	responses, err = s.toVisiblePageResponses(ctx, userID, pages)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.attachSearchHits(ctx, req.Query, responses); err != nil {
+		return nil, err
+	}
+
+	return &SearchPagesResponse{
+		Pages:  responses,
+		Total:  total,
+		Limit:  req.Limit,
+		Offset: req.Offset,
+	}, nil
+}
+
+func (s *pageService) QueryPages(ctx context.Context, userID int64, workspaceID int64, req *QueryPagesRequest) (*QueryPagesResponse, error) {
+	// Validate input
+	if err := validateStruct(req); err != nil {
+		return nil, NewValidationError(err)
+	}
+
+	filters, err := toPropertyFilters(req.Filters)
+	if err != nil {
+		return nil, err
+	}
+
+	var sort *repository.PropertySort
+	if req.Sort != nil {
+		sort = &repository.PropertySort{Key: req.Sort.Property, Descending: req.Sort.Direction == "desc"}
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = 50
+	}
+
+	// Check workspace access
+	hasAccess, err := s.workspaceRepo.HasAccess(ctx, workspaceID, userID)
+	if err != nil {
+		s.logger.Error("Failed to check workspace access", "error", err, "workspace_id", workspaceID, "user_id", userID)
+		return nil, NewInternalError("Failed to verify workspace access")
+	}
+
+	if !hasAccess {
+		return nil, NewForbiddenError("Access denied to workspace")
+	}
+
+	// The query only returns pages the user can view, so the count matches the result set
+	total, err := s.pageRepo.CountByProperties(ctx, workspaceID, userID, filters)
+	if err != nil {
+		s.logger.Error("Failed to count pages by properties", "error", err, "workspace_id", workspaceID)
+		return nil, NewInternalError("Failed to query pages")
+	}
+
+	responses := make([]PageResponse, 0, limit)
+	if int64(req.Offset) < total {
+		pages, err := s.pageRepo.QueryByProperties(ctx, workspaceID, userID, filters, sort, limit, req.Offset)
+		if err != nil {
+			s.logger.Error("Failed to query pages by properties", "error", err, "workspace_id", workspaceID)
+			return nil, NewInternalError("Failed to query pages")
+		}
+
+		responses, err = s.toVisiblePageResponses(ctx, userID, pages)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &QueryPagesResponse{
+		Pages:  responses,
+		Total:  total,
+		Limit:  limit,
+		Offset: req.Offset,
+	}, nil
+}
+
+// ToggleFavorite stars (favorite=true) or unstars a page for the user
+func (s *pageService) ToggleFavorite(ctx context.Context, userID int64, pageID string, favorite bool) error {
+	if !favorite {
+		// Unstarring is always allowed so users can clean up pages they lost access to
+		if err := s.pageRepo.RemoveFavorite(ctx, userID, pageID); err != nil {
+			s.logger.Error("Failed to remove favorite", "error", err, "page_id", pageID, "user_id", userID)
+			return NewInternalError("Failed to remove favorite")
+		}
+		return nil
+	}
+
+	// Check permission
+	hasPermission, err := s.pageRepo.HasPermission(ctx, pageID, userID, repository.PermissionView)
+	if err != nil {
+		s.logger.Error("Failed to check page permission", "error", err, "page_id", pageID, "user_id", userID)
+		return NewInternalError("Failed to verify page access")
+	}
+
+	if !hasPermission {
+		return NewForbiddenError("Access denied to page")
+	}
+
+	page, err := s.pageRepo.GetByID(ctx, pageID)
+	if err != nil {
+		s.logger.Error("Failed to get page", "error", err, "page_id", pageID)
+		return NewInternalError("Failed to get page")
+	}
+
+	if page == nil {
+		return NewNotFoundError("Page not found")
+	}
+
+	if err := s.pageRepo.AddFavorite(ctx, userID, pageID); err != nil {
+		s.logger.Error("Failed to add favorite", "error", err, "page_id", pageID, "user_id", userID)
+		return NewInternalError("Failed to add favorite")
+	}
+
+	return nil
+}
+
+// GetFavoritePages lists the user's starred pages they can still view
+func (s *pageService) GetFavoritePages(ctx context.Context, userID int64) ([]PageResponse, error) {
+	pages, err := s.pageRepo.ListFavorites(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to list favorites", "error", err, "user_id", userID)
+		return nil, NewInternalError("Failed to get favorite pages")
+	}
+
+	// Drops pages the user has lost access to
+	return s.toVisiblePageResponses(ctx, userID, pages)
+}
+
+func (s *pageService) GetRecentPages(ctx context.Context, userID int64, limit int) ([]PageResponse, error) {
+	pages, err := s.pageRepo.GetRecentPages(ctx, userID, limit)
+	if err != nil {
+		s.logger.Error("Failed to get recent pages", "error", err, "user_id", userID)
+		return nil, NewInternalError("Failed to get recent pages")
+	}
+
+	return s.toVisiblePageResponses(ctx, userID, pages)
+}
+
+func (s *pageService) ListRecentPages(ctx context.Context, userID int64, cursor string, limit int) ([]PageResponse, string, error) {
+	after, err := decodePageCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	pages, err := s.pageRepo.GetRecentPagesAfter(ctx, userID, after, limit+1)
+	if err != nil {
+		s.logger.Error("Failed to get recent pages", "error", err, "user_id", userID)
+		return nil, "", NewInternalError("Failed to get recent pages")
+	}
+
+	pages, nextCursor := trimToCursorPage(pages, limit)
+	responses, err := s.toVisiblePageResponses(ctx, userID, pages)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return responses, nextCursor, nil
+}
+
+func (s *pageService) ListWorkspacePages(ctx context.Context, userID int64, workspaceID int64, includeArchived, includeTemplates bool, cursor string, limit int) ([]PageResponse, string, error) {
+	after, err := decodePageCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
 	// Check workspace access
-	hasAccess, err := s.workspaceRepo.HasAccess(ctx, req.WorkspaceID, userID)
+	hasAccess, err := s.workspaceRepo.HasAccess(ctx, workspaceID, userID)
 	if err != nil {
-		s.logger.Error("Failed to check workspace access", "error", err, "workspace_id", req.WorkspaceID, "user_id", userID)
-		return nil, NewInternalError("Failed to verify workspace access")
+		s.logger.Error("Failed to check workspace access", "error", err, "workspace_id", workspaceID, "user_id", userID)
+		return nil, "", NewInternalError("Failed to verify workspace access")
 	}
 
 	if !hasAccess {
-		return nil, NewForbiddenError("Access denied to workspace")
+		return nil, "", NewForbiddenError("Access denied to workspace")
 	}
 
-	pages, err := s.pageRepo.Search(ctx, req.WorkspaceID, req.Query, req.Limit, req.Offset)
+	pages, err := s.pageRepo.GetByWorkspaceIDAfter(ctx, workspaceID, userID, includeArchived, includeTemplates, after, limit+1)
 	if err != nil {
-		s.logger.Error("Failed to search pages", "error", err, "workspace_id", req.WorkspaceID, "query", req.Query)
-		return nil, NewInternalError("Failed to search pages")
+		s.logger.Error("Failed to get workspace pages", "error", err, "workspace_id", workspaceID)
+		return nil, "", NewInternalError("Failed to get pages")
 	}
 
-	responses := make([]PageResponse, 0, len(pages))
-	for _, page := range pages {
-		// Check if user has permission to view this page
-		hasPermission, err := s.pageRepo.HasPermission(ctx, page.ID, userID, repository.PermissionView)
-		if err != nil {
-			s.logger.Error("Failed to check page permission", "error", err, "page_id", page.ID, "user_id", userID)
-			continue
-		}
-
-		if !hasPermission {
-			continue
-		}
-
-		// Get user's permission level
-		permission, err := s.getUserPermissionLevel(ctx, userID, page.ID)
-		if err != nil {
-			s.logger.Error("Failed to get user permission level", "error", err, "page_id", page.ID, "user_id", userID)
-			continue
-		}
-
-		// Get children count
-		children, err := s.pageRepo.GetByParentID(ctx, page.ID, false)
-		if err != nil {
-			s.logger.Error("Failed to get child pages", "error", err, "page_id", page.ID)
-			continue
-		}
-
-		responses = append(responses, *s.toPageResponse(page, permission, len(children)))
+	pages, nextCursor := trimToCursorPage(pages, limit)
+	responses, err := s.toVisiblePageResponses(ctx, userID, pages)
+	if err != nil {
+		return nil, "", err
 	}
 
-	return &SearchPagesResponse{
-		Pages:  responses,
-		Total:  int64(len(responses)), // This is approximate since we filter after search
-		Limit:  req.Limit,
-		Offset: req.Offset,
-	}, nil
+	return responses, nextCursor, nil
 }
 
-func (s *pageService) GetRecentPages(ctx context.Context, userID int64, limit int) ([]PageResponse, error) {
-	pages, err := s.pageRepo.GetRecentPages(ctx, userID, limit)
+func decodePageCursor(token string) (*repository.Cursor, error) {
+	cursor, err := repository.DecodeCursor(token)
 	if err != nil {
-		s.logger.Error("Failed to get recent pages", "error", err, "user_id", userID)
-		return nil, NewInternalError("Failed to get recent pages")
+		return nil, NewBadRequestError("Invalid cursor")
 	}
+	return cursor, nil
+}
 
-	responses := make([]PageResponse, 0, len(pages))
-	for _, page := range pages {
-		// Check if user has permission to view this page
-		hasPermission, err := s.pageRepo.HasPermission(ctx, page.ID, userID, repository.PermissionView)
-		if err != nil {
-			s.logger.Error("Failed to check page permission", "error", err, "page_id", page.ID, "user_id", userID)
-			continue
-		}
-
-		if !hasPermission {
-			continue
-		}
-
-		// Get user's permission level
-		permission, err := s.getUserPermissionLevel(ctx, userID, page.ID)
-		if err != nil {
-			s.logger.Error("Failed to get user permission level", "error", err, "page_id", page.ID, "user_id", userID)
-			continue
-		}
-
-		// Get children count
-		children, err := s.pageRepo.GetByParentID(ctx, page.ID, false)
-		if err != nil {
-			s.logger.Error("Failed to get child pages", "error", err, "page_id", page.ID)
-			continue
-		}
-
-		responses = append(responses, *s.toPageResponse(page, permission, len(children)))
+// trimToCursorPage drops the extra row fetched to detect a following page and
+// returns the cursor for it. The cursor follows the last fetched row rather
+// than the last visible one, so pages hidden by permissions are not re-read;
+// a page of results may therefore hold fewer than limit items.
+func trimToCursorPage(pages []*repository.Page, limit int) ([]*repository.Page, string) {
+	if len(pages) <= limit {
+		return pages, ""
 	}
-
-	return responses, nil
+	pages = pages[:limit]
+	return pages, repository.CursorAfter(pages[len(pages)-1]).Encode()
 }
 
 func (s *pageService) GetPageVersions(ctx context.Context, userID int64, pageID string, limit, offset int) ([]PageVersionResponse, error) {
@@ -825,6 +2276,16 @@ func (s *pageService) GrantPermission(ctx context.Context, userID int64, pageID
 		return nil, NewInternalError("Failed to grant permission")
 	}
 
+	if page, err := s.pageRepo.GetByID(ctx, pageID); err != nil {
+		s.logger.Error("Failed to get page", "error", err, "page_id", pageID)
+	} else {
+		s.recordPageActivity(page, userID, repository.ActivityPermissionGranted, map[string]interface{}{
+			"user_id":    req.UserID,
+			"permission": permissionLevel,
+		})
+		s.notifyPageAccess(page, userID, targetUser, permissionLevel)
+	}
+
 	return s.toPagePermissionResponse(permission, targetUser), nil
 }
 
@@ -851,15 +2312,36 @@ func (s *pageService) RevokePermission(ctx context.Context, userID int64, pageID
 		return NewBadRequestError("Cannot revoke permission from page owner")
 	}
 
+	// Only an explicit permission is worth telling the user about
+	existing, err := s.pageRepo.GetUserPermission(ctx, pageID, targetUserID)
+	if err != nil {
+		s.logger.Error("Failed to get page permission", "error", err, "page_id", pageID, "user_id", targetUserID)
+		return NewInternalError("Failed to revoke permission")
+	}
+
 	if err := s.pageRepo.RevokePermission(ctx, pageID, targetUserID); err != nil {
 		s.logger.Error("Failed to revoke page permission", "error", err, "page_id", pageID, "user_id", targetUserID)
 		return NewInternalError("Failed to revoke permission")
 	}
 
+	s.recordPageActivity(page, userID, repository.ActivityPermissionRevoked, map[string]interface{}{
+		"user_id": targetUserID,
+	})
+
+	if existing == nil {
+		return nil
+	}
+
+	if targetUser, err := s.userRepo.GetByID(ctx, targetUserID); err != nil {
+		s.logger.Error("Failed to get user", "error", err, "user_id", targetUserID)
+	} else if targetUser != nil {
+		s.notifyPageAccess(page, userID, targetUser, "")
+	}
+
 	return nil
 }
 
-func (s *pageService) GetPagePermissions(ctx context.Context, userID int64, pageID string) ([]PagePermissionResponse, error) {
+func (s *pageService) GetPagePermissions(ctx context.Context, userID int64, pageID string, limit, offset int) ([]PagePermissionResponse, error) {
 	// Check if user has admin permission
 	hasPermission, err := s.pageRepo.HasPermission(ctx, pageID, userID, repository.PermissionAdmin)
 	if err != nil {
@@ -871,7 +2353,7 @@ func (s *pageService) GetPagePermissions(ctx context.Context, userID int64, page
 		return nil, NewForbiddenError("Insufficient permissions to view permissions")
 	}
 
-	permissions, err := s.pageRepo.ListPermissions(ctx, pageID)
+	permissions, err := s.pageRepo.ListPermissionsPaginated(ctx, pageID, limit, offset)
 	if err != nil {
 		s.logger.Error("Failed to get page permissions", "error", err, "page_id", pageID)
 		return nil, NewInternalError("Failed to get permissions")
@@ -895,6 +2377,245 @@ func (s *pageService) GetPagePermissions(ctx context.Context, userID int64, page
 	return responses, nil
 }
 
+// GetEffectiveAccess lists everyone who can view the page, resolved the same
+// way HasPermission does: page owner, then explicit grants, then the
+// workspace's default page permission for members
+func (s *pageService) GetEffectiveAccess(ctx context.Context, userID int64, pageID string) ([]EffectiveAccessResponse, error) {
+	// Check if user has admin permission
+	hasPermission, err := s.pageRepo.HasPermission(ctx, pageID, userID, repository.PermissionAdmin)
+	if err != nil {
+		s.logger.Error("Failed to check page permission", "error", err, "page_id", pageID, "user_id", userID)
+		return nil, NewInternalError("Failed to verify page access")
+	}
+
+	if !hasPermission {
+		return nil, NewForbiddenError("Insufficient permissions to view page access")
+	}
+
+	page, err := s.pageRepo.GetByID(ctx, pageID)
+	if err != nil {
+		s.logger.Error("Failed to get page", "error", err, "page_id", pageID)
+		return nil, NewInternalError("Failed to get page")
+	}
+
+	if page == nil {
+		return nil, NewNotFoundError("Page not found")
+	}
+
+	permissions, err := s.pageRepo.ListPermissions(ctx, pageID)
+	if err != nil {
+		s.logger.Error("Failed to get page permissions", "error", err, "page_id", pageID)
+		return nil, NewInternalError("Failed to get permissions")
+	}
+
+	workspace, err := s.workspaceRepo.GetByID(ctx, page.WorkspaceID)
+	if err != nil {
+		s.logger.Error("Failed to get workspace", "error", err, "workspace_id", page.WorkspaceID)
+		return nil, NewInternalError("Failed to get workspace")
+	}
+
+	var members []*repository.WorkspaceMember
+	if workspace != nil && workspace.DefaultPagePermission != repository.PermissionNone {
+		members, err = s.workspaceRepo.GetMembers(ctx, page.WorkspaceID)
+		if err != nil {
+			s.logger.Error("Failed to get workspace members", "error", err, "workspace_id", page.WorkspaceID)
+			return nil, NewInternalError("Failed to get workspace members")
+		}
+	}
+
+	// Resolve in precedence order; the first source that applies to a user wins
+	type grant struct {
+		userID     int64
+		permission repository.PermissionLevel
+		source     string
+	}
+	grants := []grant{{userID: page.OwnerID, permission: repository.PermissionAdmin, source: AccessSourceOwner}}
+	for _, permission := range permissions {
+		grants = append(grants, grant{userID: permission.UserID, permission: permission.Permission, source: AccessSourceExplicit})
+	}
+	for _, member := range members {
+		grants = append(grants, grant{userID: member.UserID, permission: workspace.DefaultPagePermission, source: AccessSourceWorkspaceDefault})
+	}
+
+	seen := make(map[int64]bool, len(grants))
+	responses := make([]EffectiveAccessResponse, 0, len(grants))
+	for _, g := range grants {
+		if seen[g.userID] {
+			continue
+		}
+		seen[g.userID] = true
+
+		user, err := s.userRepo.GetByID(ctx, g.userID)
+		if err != nil {
+			s.logger.Error("Failed to get user", "error", err, "user_id", g.userID)
+			continue
+		}
+
+		if user == nil {
+			continue
+		}
+
+		responses = append(responses, EffectiveAccessResponse{
+			UserID:     user.ID,
+			Username:   user.Username,
+			Email:      user.Email,
+			Permission: string(g.permission),
+			Source:     g.source,
+		})
+	}
+
+	return responses, nil
+}
+
+func (s *pageService) GetOrphanedPages(ctx context.Context, userID int64, workspaceID int64) ([]PageResponse, error) {
+	// Check workspace admin access
+	if err := s.requireWorkspaceAdmin(ctx, userID, workspaceID); err != nil {
+		return nil, err
+	}
+
+	pages, err := s.pageRepo.GetOrphanedPages(ctx, workspaceID)
+	if err != nil {
+		s.logger.Error("Failed to get orphaned pages", "error", err, "workspace_id", workspaceID)
+		return nil, NewInternalError("Failed to get orphaned pages")
+	}
+
+	pageIDs := make([]string, len(pages))
+	for i, page := range pages {
+		pageIDs[i] = page.ID
+	}
+
+	childCounts, err := s.pageRepo.CountChildren(ctx, pageIDs)
+	if err != nil {
+		s.logger.Error("Failed to count child pages", "error", err, "workspace_id", workspaceID)
+		return nil, NewInternalError("Failed to get child pages")
+	}
+
+	responses := make([]PageResponse, 0, len(pages))
+	for _, page := range pages {
+		responses = append(responses, *s.toPageResponse(page, repository.PermissionAdmin, childCounts[page.ID]))
+	}
+
+	return responses, nil
+}
+
+func (s *pageService) RepairOrphanedPages(ctx context.Context, userID int64, workspaceID int64, req *RepairOrphanedPagesRequest) (*RepairOrphanedPagesResponse, error) {
+	// Check workspace admin access
+	if err := s.requireWorkspaceAdmin(ctx, userID, workspaceID); err != nil {
+		return nil, err
+	}
+
+	if req.ParentID != nil {
+		// The new parent must be a reachable page in the same workspace, otherwise
+		// the orphans could end up under one of their own descendants. The
+		// visited set stops the walk on a corrupt parent_id cycle.
+		current := *req.ParentID
+		visited := make(map[string]bool)
+		for {
+			if visited[current] {
+				return nil, NewBadRequestError("Target parent page is inside a page cycle")
+			}
+			visited[current] = true
+
+			page, err := s.pageRepo.GetByID(ctx, current)
+			if err != nil {
+				s.logger.Error("Failed to get page", "error", err, "page_id", current)
+				return nil, NewInternalError("Failed to verify target parent page")
+			}
+
+			if page == nil {
+				if current == *req.ParentID {
+					return nil, NewNotFoundError("Parent page not found")
+				}
+				return nil, NewBadRequestError("Target parent page is itself inside an orphaned subtree")
+			}
+
+			if page.WorkspaceID != workspaceID {
+				return nil, NewBadRequestError("Target parent page belongs to a different workspace")
+			}
+
+			if page.ParentID == nil {
+				break
+			}
+			current = *page.ParentID
+		}
+	}
+
+	count, err := s.pageRepo.ReparentOrphanedPages(ctx, workspaceID, req.ParentID, userID)
+	if err != nil {
+		s.logger.Error("Failed to repair orphaned pages", "error", err, "workspace_id", workspaceID)
+		return nil, NewInternalError("Failed to repair orphaned pages")
+	}
+
+	return &RepairOrphanedPagesResponse{
+		RepairedCount: count,
+		ParentID:      req.ParentID,
+	}, nil
+}
+
+// requireWorkspaceAdmin returns a forbidden error unless the user owns or administers the workspace
+func (s *pageService) requireWorkspaceAdmin(ctx context.Context, userID int64, workspaceID int64) error {
+	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil {
+		s.logger.Error("Failed to get workspace", "error", err, "workspace_id", workspaceID)
+		return NewInternalError("Failed to get workspace")
+	}
+
+	if workspace == nil {
+		return NewNotFoundError("Workspace not found")
+	}
+
+	if workspace.OwnerID == userID {
+		return nil
+	}
+
+	member, err := s.workspaceRepo.GetMember(ctx, workspaceID, userID)
+	if err != nil {
+		s.logger.Error("Failed to get workspace member", "error", err, "workspace_id", workspaceID, "user_id", userID)
+		return NewInternalError("Failed to get workspace members")
+	}
+
+	if member != nil && (member.Role == repository.WorkspaceRoleAdmin || member.Role == repository.WorkspaceRoleOwner) {
+		return nil
+	}
+
+	return NewForbiddenError("Workspace admin access required")
+}
+
+// requireWorkspaceEditor returns a forbidden error unless the user administers
+// the workspace or is a member whose default page permission allows editing
+func (s *pageService) requireWorkspaceEditor(ctx context.Context, userID int64, workspaceID int64) error {
+	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil {
+		s.logger.Error("Failed to get workspace", "error", err, "workspace_id", workspaceID)
+		return NewInternalError("Failed to get workspace")
+	}
+
+	if workspace == nil {
+		return NewNotFoundError("Workspace not found")
+	}
+
+	if workspace.OwnerID == userID {
+		return nil
+	}
+
+	member, err := s.workspaceRepo.GetMember(ctx, workspaceID, userID)
+	if err != nil {
+		s.logger.Error("Failed to get workspace member", "error", err, "workspace_id", workspaceID, "user_id", userID)
+		return NewInternalError("Failed to get workspace members")
+	}
+
+	if member == nil {
+		return NewForbiddenError("Access denied to target workspace")
+	}
+
+	if member.Role == repository.WorkspaceRoleAdmin || member.Role == repository.WorkspaceRoleOwner ||
+		workspace.DefaultPagePermission.Includes(repository.PermissionEdit) {
+		return nil
+	}
+
+	return NewForbiddenError("Edit access to the target workspace required")
+}
+
 func (s *pageService) getUserPermissionLevel(ctx context.Context, userID int64, pageID string) (repository.PermissionLevel, error) {
 	// Check if user is page owner
 	page, err := s.pageRepo.GetByID(ctx, pageID)
@@ -916,19 +2637,152 @@ func (s *pageService) getUserPermissionLevel(ctx context.Context, userID int64,
 		return permission.Permission, nil
 	}
 
-	// Default view permission for workspace members
+	// Fall back to the workspace default for members
 	hasAccess, err := s.workspaceRepo.HasAccess(ctx, page.WorkspaceID, userID)
 	if err != nil {
 		return "", err
 	}
 
 	if hasAccess {
-		return repository.PermissionView, nil
+		workspace, err := s.workspaceRepo.GetByID(ctx, page.WorkspaceID)
+		if err != nil {
+			return "", err
+		}
+		if workspace != nil && workspace.DefaultPagePermission != repository.PermissionNone {
+			return workspace.DefaultPagePermission, nil
+		}
 	}
 
 	return "", NewForbiddenError("No access to page")
 }
 
+// validateProperties checks page properties against the workspace's property
+// schema. Workspaces without a schema accept any properties document.
+func (s *pageService) validateProperties(ctx context.Context, workspaceID int64, properties json.RawMessage) error {
+	rawSchema, err := s.workspaceRepo.GetPropertySchema(ctx, workspaceID)
+	if err != nil {
+		s.logger.Error("Failed to get workspace property schema", "error", err, "workspace_id", workspaceID)
+		return NewInternalError("Failed to validate page properties")
+	}
+
+	if rawSchema == nil {
+		return nil
+	}
+
+	schema, err := parsePropertySchema(rawSchema)
+	if err != nil {
+		// Schemas are checked when saved, so this only happens if the column was edited by hand
+		s.logger.Error("Stored workspace property schema is invalid", "error", err, "workspace_id", workspaceID)
+		return NewInternalError("Failed to validate page properties")
+	}
+
+	if details := schema.Validate(properties); len(details) > 0 {
+		return NewValidationError(&ValidationError{Errors: details})
+	}
+	return nil
+}
+
+// pageAccessEmailTimeout bounds a detached page access email, retries included
+const pageAccessEmailTimeout = 30 * time.Second
+
+// notifyPageAccess emails the target of a permission change when their
+// notification preferences allow it; an empty level means access was
+// revoked. Delivery is detached from the request and failures are only
+// logged, so they never fail the change itself.
+func (s *pageService) notifyPageAccess(page *repository.Page, actorID int64, target *repository.User, level repository.PermissionLevel) {
+	if target.ID == actorID {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), pageAccessEmailTimeout)
+		defer cancel()
+
+		granted := level != ""
+		preferences, err := s.notificationPrefs.Get(ctx, target.ID)
+		if err != nil {
+			s.logger.Error("Failed to get notification preferences", "error", err, "user_id", target.ID)
+			return
+		}
+		if (granted && !preferences.EmailPageAccessGranted) || (!granted && !preferences.EmailPageAccessRevoked) {
+			return
+		}
+
+		actorName := "Someone"
+		if actor, err := s.userRepo.GetByID(ctx, actorID); err != nil {
+			s.logger.Error("Failed to get user", "error", err, "user_id", actorID)
+		} else if actor != nil {
+			actorName = strings.TrimSpace(actor.FirstName + " " + actor.LastName)
+			if actorName == "" {
+				actorName = actor.Username
+			}
+		}
+
+		if err := s.emailService.SendPageAccessEmail(ctx, target.Email, &PageAccessEmail{
+			Granted:    granted,
+			PageID:     page.ID,
+			PageTitle:  page.Title,
+			ActorName:  actorName,
+			Permission: string(level),
+		}); err != nil {
+			s.logger.Error("Failed to send page access email", "error", err, "page_id", page.ID, "user_id", target.ID)
+		}
+	}()
+}
+
+// recordPageActivity adds a page event to the workspace activity log
+func (s *pageService) recordPageActivity(page *repository.Page, actorID int64, action repository.ActivityAction, metadata map[string]interface{}) {
+	if page == nil {
+		return
+	}
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadata["title"] = page.Title
+
+	s.activity.Record(ActivityEvent{
+		WorkspaceID: page.WorkspaceID,
+		ActorID:     actorID,
+		Action:      action,
+		TargetType:  repository.ActivityTargetPage,
+		TargetID:    page.ID,
+		Metadata:    metadata,
+	})
+}
+
+// toVisiblePageResponses converts a page list into responses with a fixed
+// number of queries, dropping pages the user cannot view
+func (s *pageService) toVisiblePageResponses(ctx context.Context, userID int64, pages []*repository.Page) ([]PageResponse, error) {
+	pageIDs := make([]string, len(pages))
+	for i, page := range pages {
+		pageIDs[i] = page.ID
+	}
+
+	levels, err := s.pageRepo.GetUserPermissionLevels(ctx, userID, pageIDs)
+	if err != nil {
+		s.logger.Error("Failed to get user permission levels", "error", err, "user_id", userID)
+		return nil, NewInternalError("Failed to get permission level")
+	}
+
+	childCounts, err := s.pageRepo.CountChildren(ctx, pageIDs)
+	if err != nil {
+		s.logger.Error("Failed to count child pages", "error", err, "user_id", userID)
+		return nil, NewInternalError("Failed to get child pages")
+	}
+
+	responses := make([]PageResponse, 0, len(pages))
+	for _, page := range pages {
+		permission, ok := levels[page.ID]
+		if !ok {
+			continue
+		}
+
+		responses = append(responses, *s.toPageResponse(page, permission, childCounts[page.ID]))
+	}
+
+	return responses, nil
+}
+
 func (s *pageService) toPageResponse(page *repository.Page, permission repository.PermissionLevel, childrenCount int) *PageResponse {
 	return &PageResponse{
 		ID:            page.ID,
@@ -944,6 +2798,7 @@ func (s *pageService) toPageResponse(page *repository.Page, permission repositor
 		CreatedAt:     page.CreatedAt,
 		UpdatedAt:     page.UpdatedAt,
 		LastEditedBy:  page.LastEditedBy,
+		DeletedAt:     page.DeletedAt,
 		Permission:    string(permission),
 		ChildrenCount: childrenCount,
 	}
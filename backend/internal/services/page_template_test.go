@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+	"github.com/Srivathsav-max/lumen/backend/internal/security"
+)
+
+const templateTestUser int64 = 1
+
+// newContentTestService wires the block registry and XSS service so
+// content checks run as they do in production
+func newContentTestService(pages *fakePageRepo, blocks *fakeBlockRepo, workspaces *fakeWorkspaceRepo) PageService {
+	xss := security.NewXSSService(security.DefaultXSSConfig(), discardLogger())
+	return NewPageService(pages, blocks, workspaces, nil, fakeActivityService{}, nil, nil, NewBlockTypeRegistry(nil, nil), xss, discardLogger())
+}
+
+func TestCreateFromTemplateValidatesPropertiesForTheTargetWorkspace(t *testing.T) {
+	pages := newFakePageRepo(&repository.Page{
+		ID:          "template",
+		WorkspaceID: 10,
+		OwnerID:     templateTestUser,
+		IsTemplate:  true,
+		Properties:  json.RawMessage(`{"status":"someday"}`),
+	})
+	workspaces := newFakeWorkspaceRepo(
+		&repository.Workspace{ID: 10, OwnerID: templateTestUser},
+		&repository.Workspace{ID: 20, OwnerID: templateTestUser},
+	)
+	workspaces.schemas = map[int64]json.RawMessage{
+		20: json.RawMessage(`{"type":"object","properties":{"status":{"type":"string","enum":["todo","done"]}}}`),
+	}
+	svc := newContentTestService(pages, &fakeBlockRepo{}, workspaces)
+
+	_, err := svc.CreateFromTemplate(context.Background(), templateTestUser, "template", &InstantiateTemplateRequest{WorkspaceID: 20})
+	if !IsValidationError(err) {
+		t.Fatalf("CreateFromTemplate() into a workspace whose schema rejects the properties: error = %v, want a validation error", err)
+	}
+	if len(pages.pages) != 1 {
+		t.Error("page was created despite invalid properties")
+	}
+
+	if _, err := svc.CreateFromTemplate(context.Background(), templateTestUser, "template", &InstantiateTemplateRequest{}); err != nil {
+		t.Fatalf("CreateFromTemplate() into the template's own workspace: error = %v", err)
+	}
+}
+
+func TestCreateFromTemplateSanitizesFilledInBlocks(t *testing.T) {
+	pages := newFakePageRepo(&repository.Page{ID: "template", WorkspaceID: 10, OwnerID: templateTestUser, IsTemplate: true})
+	blocks := &fakeBlockRepo{blocks: []*repository.Block{{
+		ID:        "heading",
+		PageID:    "template",
+		BlockType: "paragraph",
+		BlockData: json.RawMessage(`{"text":"Hello {{name}}"}`),
+	}}}
+	svc := newContentTestService(pages, blocks, newFakeWorkspaceRepo(&repository.Workspace{ID: 10, OwnerID: templateTestUser}))
+
+	page, err := svc.CreateFromTemplate(context.Background(), templateTestUser, "template", &InstantiateTemplateRequest{
+		Variables: map[string]string{"name": `<img src=x onerror="alert(1)">`},
+	})
+	if err != nil {
+		t.Fatalf("CreateFromTemplate() error = %v", err)
+	}
+
+	copies, _ := blocks.GetByPageID(context.Background(), page.ID)
+	if len(copies) != 1 {
+		t.Fatalf("copied %d blocks, want 1", len(copies))
+	}
+	if strings.Contains(string(copies[0].BlockData), "<img") {
+		t.Errorf("copied block data = %s, want the injected markup escaped", copies[0].BlockData)
+	}
+}
+
+func TestCreateFromTemplateRejectsInvalidBlocks(t *testing.T) {
+	pages := newFakePageRepo(&repository.Page{ID: "template", WorkspaceID: 10, OwnerID: templateTestUser, IsTemplate: true})
+	blocks := &fakeBlockRepo{blocks: []*repository.Block{{
+		ID:        "legacy",
+		PageID:    "template",
+		BlockType: "marquee",
+		BlockData: json.RawMessage(`{}`),
+	}}}
+	svc := newContentTestService(pages, blocks, newFakeWorkspaceRepo(&repository.Workspace{ID: 10, OwnerID: templateTestUser}))
+
+	_, err := svc.CreateFromTemplate(context.Background(), templateTestUser, "template", &InstantiateTemplateRequest{})
+	if !IsValidationError(err) {
+		t.Fatalf("CreateFromTemplate() with an unknown block type: error = %v, want a validation error", err)
+	}
+	if len(pages.pages) != 1 || len(blocks.blocks) != 1 {
+		t.Error("template with invalid blocks was still instantiated")
+	}
+}
+
+func TestDuplicatePageValidatesPropertiesAgainstTheCurrentSchema(t *testing.T) {
+	pages := newFakePageRepo(&repository.Page{
+		ID:          "source",
+		WorkspaceID: 10,
+		OwnerID:     templateTestUser,
+		Properties:  json.RawMessage(`{"status":"someday"}`),
+	})
+	workspaces := newFakeWorkspaceRepo(&repository.Workspace{ID: 10, OwnerID: templateTestUser})
+	svc := newContentTestService(pages, &fakeBlockRepo{}, workspaces)
+
+	if _, err := svc.DuplicatePage(context.Background(), templateTestUser, "source", &DuplicatePageRequest{}); err != nil {
+		t.Fatalf("DuplicatePage() without a schema: error = %v", err)
+	}
+
+	workspaces.schemas = map[int64]json.RawMessage{
+		10: json.RawMessage(`{"type":"object","properties":{"status":{"type":"string","enum":["todo","done"]}}}`),
+	}
+	_, err := svc.DuplicatePage(context.Background(), templateTestUser, "source", &DuplicatePageRequest{})
+	if !IsValidationError(err) {
+		t.Fatalf("DuplicatePage() with properties the schema rejects: error = %v, want a validation error", err)
+	}
+	if len(pages.pages) != 2 {
+		t.Errorf("workspace has %d pages, want only the source and the first copy", len(pages.pages))
+	}
+}
+
+func TestImportMarkdownChecksBlocksBeforeCreatingThePage(t *testing.T) {
+	pages := newFakePageRepo()
+	blocks := &fakeBlockRepo{}
+	svc := newContentTestService(pages, blocks, newFakeWorkspaceRepo(&repository.Workspace{ID: 10, OwnerID: templateTestUser}))
+
+	_, err := svc.ImportMarkdown(context.Background(), templateTestUser, &ImportMarkdownRequest{
+		WorkspaceID: 10,
+		Title:       "Import",
+		Markdown:    strings.Repeat("a", maxBlockDataSize+1),
+	})
+	if !IsValidationError(err) {
+		t.Fatalf("ImportMarkdown() with an oversized block: error = %v, want a validation error", err)
+	}
+	if len(pages.pages) != 0 || len(blocks.blocks) != 0 {
+		t.Error("page or blocks were created for a rejected import")
+	}
+
+	if _, err := svc.ImportMarkdown(context.Background(), templateTestUser, &ImportMarkdownRequest{
+		WorkspaceID: 10,
+		Markdown:    "# Notes\n\nSome **bold** text",
+	}); err != nil {
+		t.Fatalf("ImportMarkdown() error = %v", err)
+	}
+	if len(blocks.blocks) != 1 || blocks.blocks[0].PageID == "" {
+		t.Errorf("imported blocks = %+v, want one paragraph on the new page", blocks.blocks)
+	}
+}
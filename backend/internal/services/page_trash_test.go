@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+func TestDeletePageRefusesSubtreesWithPagesTheUserCannotDelete(t *testing.T) {
+	ctx := context.Background()
+	pages := newFakePageRepo(
+		&repository.Page{ID: "root", OwnerID: bulkTestUser},
+		&repository.Page{ID: "child", OwnerID: 2, ParentID: stringPtr("root")},
+	)
+	pages.grant("child", bulkTestUser, repository.PermissionEdit)
+	svc := newBulkTestService(pages)
+
+	if err := svc.DeletePage(ctx, bulkTestUser, "root"); !IsAuthorizationError(err) {
+		t.Errorf("DeletePage() over a child the user cannot delete: error = %v, want forbidden", err)
+	}
+	if len(pages.deleted) != 0 {
+		t.Fatalf("deleted %v after a refused delete", pages.deleted)
+	}
+
+	pages.grant("child", bulkTestUser, repository.PermissionAdmin)
+	if err := svc.DeletePage(ctx, bulkTestUser, "root"); err != nil {
+		t.Fatalf("DeletePage() error = %v", err)
+	}
+	if len(pages.deleted) != 1 || pages.deleted[0] != "root" {
+		t.Errorf("deleted %v, want [root]", pages.deleted)
+	}
+}
+
+func TestListTrashReturnsPagesFromTheFilteredQuery(t *testing.T) {
+	ctx := context.Background()
+	trashedAt := time.Now()
+	pages := newFakePageRepo(
+		&repository.Page{ID: "own", WorkspaceID: 10, OwnerID: bulkTestUser, DeletedAt: &trashedAt},
+		&repository.Page{ID: "granted", WorkspaceID: 10, OwnerID: 2, DeletedAt: &trashedAt},
+		&repository.Page{ID: "others", WorkspaceID: 10, OwnerID: 2, DeletedAt: &trashedAt},
+		&repository.Page{ID: "live", WorkspaceID: 10, OwnerID: bulkTestUser},
+	)
+	pages.grant("granted", bulkTestUser, repository.PermissionAdmin)
+	svc := newPageTestService(pages, newFakeWorkspaceRepo(&repository.Workspace{ID: 10, OwnerID: bulkTestUser}))
+
+	trash, err := svc.ListTrash(ctx, bulkTestUser, 10)
+	if err != nil {
+		t.Fatalf("ListTrash() error = %v", err)
+	}
+
+	got := make(map[string]bool, len(trash))
+	for _, page := range trash {
+		got[page.ID] = true
+	}
+	if len(got) != 2 || !got["own"] || !got["granted"] {
+		t.Errorf("ListTrash() = %v, want own and granted", got)
+	}
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+func TestGetPageTreeShowsOnlyVisiblePages(t *testing.T) {
+	const ownerID, memberID int64 = 1, 2
+	ctx := context.Background()
+
+	pages := newFakePageRepo(
+		&repository.Page{ID: "hidden", WorkspaceID: 10, OwnerID: ownerID},
+		&repository.Page{ID: "hidden-child", WorkspaceID: 10, OwnerID: ownerID, ParentID: stringPtr("hidden")},
+		&repository.Page{ID: "shared-child", WorkspaceID: 10, OwnerID: ownerID, ParentID: stringPtr("hidden")},
+		&repository.Page{ID: "own", WorkspaceID: 10, OwnerID: memberID},
+		&repository.Page{ID: "own-child", WorkspaceID: 10, OwnerID: memberID, ParentID: stringPtr("own")},
+	)
+	pages.grant("shared-child", memberID, repository.PermissionView)
+
+	workspaces := newFakeWorkspaceRepo(&repository.Workspace{ID: 10, OwnerID: ownerID, DefaultPagePermission: repository.PermissionNone})
+	workspaces.AddMember(ctx, &repository.WorkspaceMember{WorkspaceID: 10, UserID: memberID, Role: repository.WorkspaceRoleMember})
+	svc := newPageTestService(pages, workspaces)
+
+	tree, err := svc.GetPageTree(ctx, memberID, 10, 0, false, false)
+	if err != nil {
+		t.Fatalf("GetPageTree() error = %v", err)
+	}
+
+	// The shared page is lifted to the top level because its parent is hidden
+	got := make(map[string][]string)
+	for _, node := range tree {
+		children := []string{}
+		for _, child := range node.Children {
+			children = append(children, child.ID)
+		}
+		got[node.ID] = children
+	}
+	if len(got) != 2 || len(got["own"]) != 1 || got["own"][0] != "own-child" || len(got["shared-child"]) != 0 {
+		t.Errorf("GetPageTree() roots = %v, want own (with own-child) and shared-child", got)
+	}
+
+	if _, err := svc.GetPageTree(ctx, 3, 10, 0, false, false); !IsAuthorizationError(err) {
+		t.Errorf("GetPageTree() by a non-member error = %v, want forbidden", err)
+	}
+}
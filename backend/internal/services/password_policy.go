@@ -0,0 +1,100 @@
+package services
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/config"
+	"github.com/Srivathsav-max/lumen/backend/internal/constants"
+)
+
+//go:embed common_passwords.txt
+var commonPasswordList string
+
+// commonPasswords is the lowercase denylist loaded from common_passwords.txt
+var commonPasswords = func() map[string]bool {
+	passwords := make(map[string]bool)
+	for _, line := range strings.Split(commonPasswordList, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			passwords[strings.ToLower(line)] = true
+		}
+	}
+	return passwords
+}()
+
+// PasswordPolicy checks new passwords against the configured strength rules
+type PasswordPolicy struct {
+	cfg config.PasswordPolicyConfig
+}
+
+func NewPasswordPolicy(cfg config.PasswordPolicyConfig) *PasswordPolicy {
+	return &PasswordPolicy{cfg: cfg}
+}
+
+// ValidatePassword returns a validation error listing every rule the password
+// breaks, or nil when it satisfies the policy
+func (p *PasswordPolicy) ValidatePassword(password string) error {
+	var failed []ValidationErrorDetail
+	fail := func(message string) {
+		failed = append(failed, ValidationErrorDetail{Field: "password", Message: message})
+	}
+
+	if len([]rune(password)) < p.cfg.MinLength {
+		fail(fmt.Sprintf("Must be at least %d characters long", p.cfg.MinLength))
+	}
+	// bcrypt cannot hash more than 72 bytes, so the cap counts bytes and
+	// applies even when no maximum is configured
+	maxBytes := constants.MaxPasswordLength
+	if p.cfg.MaxLength > 0 && p.cfg.MaxLength < maxBytes {
+		maxBytes = p.cfg.MaxLength
+	}
+	if len(password) > maxBytes {
+		fail(fmt.Sprintf("Must be no more than %d bytes long", maxBytes))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+
+	if p.cfg.RequireUpper && !hasUpper {
+		fail("Must contain an uppercase letter")
+	}
+	if p.cfg.RequireLower && !hasLower {
+		fail("Must contain a lowercase letter")
+	}
+	if p.cfg.RequireDigit && !hasDigit {
+		fail("Must contain a digit")
+	}
+	if p.cfg.RequireSymbol && !hasSymbol {
+		fail("Must contain a symbol")
+	}
+	if p.cfg.RejectCommon && commonPasswords[strings.ToLower(password)] {
+		fail("Is too common")
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+
+	messages := make([]string, len(failed))
+	for i, detail := range failed {
+		messages[i] = detail.Message
+	}
+
+	return NewWeakPasswordError(strings.Join(messages, "; ")).WithDetails(&ValidationErrorResponse{
+		Message: "Password does not meet security requirements",
+		Errors:  failed,
+	})
+}
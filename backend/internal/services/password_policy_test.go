@@ -0,0 +1,53 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/config"
+	"github.com/Srivathsav-max/lumen/backend/utils"
+)
+
+func TestPasswordPolicyCapsLengthInBytes(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxLength int
+		password  string
+		wantOK    bool
+	}{
+		{name: "72 ASCII bytes", maxLength: 72, password: strings.Repeat("a", 72), wantOK: true},
+		{name: "73 ASCII bytes", maxLength: 72, password: strings.Repeat("a", 73)},
+		// 25 three-byte runes is 75 bytes though only 25 characters
+		{name: "multi-byte over the cap", maxLength: 72, password: strings.Repeat("€", 25)},
+		{name: "multi-byte under the cap", maxLength: 72, password: strings.Repeat("€", 24), wantOK: true},
+		{name: "configured above bcrypt's limit", maxLength: 128, password: strings.Repeat("a", 100)},
+		{name: "no configured maximum", password: strings.Repeat("a", 73)},
+		{name: "configured below bcrypt's limit", maxLength: 16, password: strings.Repeat("a", 17)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := NewPasswordPolicy(config.PasswordPolicyConfig{MinLength: 8, MaxLength: tt.maxLength})
+			err := policy.ValidatePassword(tt.password)
+			if (err == nil) != tt.wantOK {
+				t.Fatalf("ValidatePassword(%d bytes) error = %v, want ok %v", len(tt.password), err, tt.wantOK)
+			}
+			if tt.wantOK {
+				if _, err := utils.HashPassword(tt.password); err != nil {
+					t.Errorf("accepted password cannot be hashed: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestPasswordPolicyMinimumCountsCharacters(t *testing.T) {
+	policy := NewPasswordPolicy(config.PasswordPolicyConfig{MinLength: 8, MaxLength: 72})
+
+	if err := policy.ValidatePassword(strings.Repeat("€", 8)); err != nil {
+		t.Errorf("8 multi-byte characters rejected: %v", err)
+	}
+	if err := policy.ValidatePassword(strings.Repeat("€", 7)); err == nil {
+		t.Error("7 characters accepted")
+	}
+}
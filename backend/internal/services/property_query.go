@@ -0,0 +1,75 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+// propertyQueryDateLayouts are the date formats accepted by range filters,
+// matching the date and date-time property schema formats
+var propertyQueryDateLayouts = []string{time.RFC3339, "2006-01-02"}
+
+// toPropertyFilters checks each filter's value against its operator and
+// converts it into the form the repository binds
+func toPropertyFilters(requests []PropertyFilterRequest) ([]repository.PropertyFilter, error) {
+	filters := make([]repository.PropertyFilter, 0, len(requests))
+	var details []ValidationErrorDetail
+
+	for i, req := range requests {
+		field := fmt.Sprintf("filters[%d].value", i)
+		filter := repository.PropertyFilter{Key: req.Property, Op: req.Operator}
+
+		var value interface{}
+		hasValue := len(req.Value) > 0 && json.Unmarshal(req.Value, &value) == nil
+
+		switch req.Operator {
+		case repository.PropertyOpExists:
+		case repository.PropertyOpEquals, repository.PropertyOpNotEquals:
+			if !hasValue {
+				details = append(details, ValidationErrorDetail{Field: field, Message: "Value is required"})
+				continue
+			}
+			filter.Value = req.Value
+		case repository.PropertyOpContains:
+			if kind := jsonKind(value); !hasValue || value == nil || kind == fieldArray || kind == fieldObject {
+				details = append(details, ValidationErrorDetail{Field: field, Message: "Value must be a string, number or boolean"})
+				continue
+			}
+			filter.Value = req.Value
+		default:
+			switch v := value.(type) {
+			case float64:
+				filter.Value = v
+			case string:
+				parsed, ok := parsePropertyQueryDate(v)
+				if !ok {
+					details = append(details, ValidationErrorDetail{Field: field, Message: "Value must be a number or an ISO 8601 date"})
+					continue
+				}
+				filter.Value = parsed
+			default:
+				details = append(details, ValidationErrorDetail{Field: field, Message: "Value must be a number or an ISO 8601 date"})
+				continue
+			}
+		}
+
+		filters = append(filters, filter)
+	}
+
+	if len(details) > 0 {
+		return nil, NewValidationError(&ValidationError{Errors: details})
+	}
+	return filters, nil
+}
+
+func parsePropertyQueryDate(value string) (time.Time, bool) {
+	for _, layout := range propertyQueryDateLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,243 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"time"
+	"unicode/utf8"
+)
+
+// maxPropertySchemaSize caps a stored workspace property schema in bytes
+const maxPropertySchemaSize = 64 * 1024
+
+// propertySchema is the subset of JSON Schema supported for page properties:
+// type, properties, required, additionalProperties, items, enum, minimum,
+// maximum, minLength, maxLength, pattern, format (date, date-time, email,
+// uri), minItems and maxItems. Other keywords are rejected when the schema
+// is saved rather than silently ignored.
+type propertySchema struct {
+	Schema      string `json:"$schema,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	Type                 string                     `json:"type,omitempty"`
+	Properties           map[string]*propertySchema `json:"properties,omitempty"`
+	Required             []string                   `json:"required,omitempty"`
+	AdditionalProperties *bool                      `json:"additionalProperties,omitempty"`
+	Items                *propertySchema            `json:"items,omitempty"`
+	Enum                 []interface{}              `json:"enum,omitempty"`
+	Minimum              *float64                   `json:"minimum,omitempty"`
+	Maximum              *float64                   `json:"maximum,omitempty"`
+	MinLength            *int                       `json:"minLength,omitempty"`
+	MaxLength            *int                       `json:"maxLength,omitempty"`
+	Pattern              string                     `json:"pattern,omitempty"`
+	Format               string                     `json:"format,omitempty"`
+	MinItems             *int                       `json:"minItems,omitempty"`
+	MaxItems             *int                       `json:"maxItems,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+var propertySchemaTypes = map[string]bool{
+	"string": true, "number": true, "integer": true, "boolean": true,
+	"array": true, "object": true, "null": true,
+}
+
+var propertySchemaFormats = map[string]bool{
+	"date": true, "date-time": true, "email": true, "uri": true,
+}
+
+// parsePropertySchema decodes and checks a workspace property schema. The
+// top level must describe an object, since page properties are one.
+func parsePropertySchema(raw json.RawMessage) (*propertySchema, error) {
+	if len(raw) > maxPropertySchemaSize {
+		return nil, fmt.Errorf("schema is %d bytes, the maximum is %d", len(raw), maxPropertySchemaSize)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+
+	var schema propertySchema
+	if err := decoder.Decode(&schema); err != nil {
+		return nil, fmt.Errorf("unsupported or malformed schema: %w", err)
+	}
+
+	if schema.Type == "" {
+		schema.Type = "object"
+	}
+	if schema.Type != "object" {
+		return nil, fmt.Errorf("schema type must be object, got %q", schema.Type)
+	}
+
+	if err := schema.compile("schema"); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+// compile checks keyword values and precompiles patterns, recursively
+func (s *propertySchema) compile(path string) error {
+	if s.Type != "" && !propertySchemaTypes[s.Type] {
+		return fmt.Errorf("%s: unsupported type %q", path, s.Type)
+	}
+	if s.Format != "" && !propertySchemaFormats[s.Format] {
+		return fmt.Errorf("%s: unsupported format %q", path, s.Format)
+	}
+	if s.Pattern != "" {
+		pattern, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("%s: invalid pattern: %w", path, err)
+		}
+		s.pattern = pattern
+	}
+
+	for name, property := range s.Properties {
+		if property == nil {
+			return fmt.Errorf("%s.properties.%s: schema must be an object", path, name)
+		}
+		if err := property.compile(path + ".properties." + name); err != nil {
+			return err
+		}
+	}
+
+	if s.Items != nil {
+		if err := s.Items.compile(path + ".items"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate checks a properties document and returns one detail per problem,
+// addressing fields as e.g. properties.tags[2]
+func (s *propertySchema) Validate(document json.RawMessage) []ValidationErrorDetail {
+	var value interface{}
+	if len(document) == 0 {
+		value = map[string]interface{}{}
+	} else if err := json.Unmarshal(document, &value); err != nil {
+		return []ValidationErrorDetail{{Field: "properties", Message: "Properties must be valid JSON"}}
+	}
+
+	var details []ValidationErrorDetail
+	s.validateValue(value, "properties", &details)
+	return details
+}
+
+func (s *propertySchema) validateValue(value interface{}, path string, details *[]ValidationErrorDetail) {
+	fail := func(format string, args ...interface{}) {
+		*details = append(*details, ValidationErrorDetail{Field: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if s.Type != "" && !matchesSchemaType(value, s.Type) {
+		fail("Must be of type %s", s.Type)
+		return
+	}
+
+	if len(s.Enum) > 0 {
+		found := false
+		for _, allowed := range s.Enum {
+			if reflect.DeepEqual(allowed, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("Must be one of %v", s.Enum)
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		length := utf8.RuneCountInString(v)
+		if s.MinLength != nil && length < *s.MinLength {
+			fail("Must be at least %d characters", *s.MinLength)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			fail("Must be at most %d characters", *s.MaxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			fail("Must match pattern %s", s.Pattern)
+		}
+		if s.Format != "" && !matchesSchemaFormat(v, s.Format) {
+			fail("Must be a valid %s", s.Format)
+		}
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			fail("Must be at least %g", *s.Minimum)
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			fail("Must be at most %g", *s.Maximum)
+		}
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			fail("Must have at least %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			fail("Must have at most %d items", *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validateValue(item, fmt.Sprintf("%s[%d]", path, i), details)
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				*details = append(*details, ValidationErrorDetail{Field: path + "." + name, Message: "Is required"})
+			}
+		}
+
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			property, ok := s.Properties[key]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					*details = append(*details, ValidationErrorDetail{Field: path + "." + key, Message: "Is not an allowed property"})
+				}
+				continue
+			}
+			property.validateValue(v[key], path+"."+key, details)
+		}
+	}
+}
+
+func matchesSchemaType(value interface{}, schemaType string) bool {
+	switch schemaType {
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	case "null":
+		return value == nil
+	}
+	return jsonKind(value) == schemaType
+}
+
+func matchesSchemaFormat(value, format string) bool {
+	switch format {
+	case "date":
+		_, err := time.Parse("2006-01-02", value)
+		return err == nil
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	case "email":
+		address, err := mail.ParseAddress(value)
+		return err == nil && address.Address == value
+	case "uri":
+		parsed, err := url.Parse(value)
+		return err == nil && parsed.Scheme != ""
+	}
+	return true
+}
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+	"github.com/Srivathsav-max/lumen/backend/utils"
+)
+
+// ShareLinkService manages public read-only links to pages, for sharing with
+// people who have no account
+type ShareLinkService interface {
+	CreateShareLink(ctx context.Context, userID int64, pageID string, req *ShareLinkRequest) (*ShareLinkResponse, error)
+	ListShareLinks(ctx context.Context, userID int64, pageID string) ([]ShareLinkResponse, error)
+	RevokeShareLink(ctx context.Context, userID int64, pageID, linkID string) error
+	// GetSharedPage resolves a token without a user session. Unknown, revoked
+	// and expired tokens all report not found.
+	GetSharedPage(ctx context.Context, token, password string) (*SharedPageResponse, error)
+}
+
+type shareLinkService struct {
+	shareLinkRepo repository.PageShareLinkRepository
+	pageRepo      repository.PageRepository
+	blockRepo     repository.BlockRepository
+	logger        *slog.Logger
+}
+
+func NewShareLinkService(
+	shareLinkRepo repository.PageShareLinkRepository,
+	pageRepo repository.PageRepository,
+	blockRepo repository.BlockRepository,
+	logger *slog.Logger,
+) ShareLinkService {
+	return &shareLinkService{
+		shareLinkRepo: shareLinkRepo,
+		pageRepo:      pageRepo,
+		blockRepo:     blockRepo,
+		logger:        logger,
+	}
+}
+
+func (s *shareLinkService) CreateShareLink(ctx context.Context, userID int64, pageID string, req *ShareLinkRequest) (*ShareLinkResponse, error) {
+	// Validate input
+	if err := validateStruct(req); err != nil {
+		return nil, NewValidationError(err)
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, NewBadRequestError("Share link expiry must be in the future")
+	}
+
+	if err := s.requireAdmin(ctx, userID, pageID); err != nil {
+		return nil, err
+	}
+
+	page, err := s.pageRepo.GetByID(ctx, pageID)
+	if err != nil {
+		s.logger.Error("Failed to get page", "error", err, "page_id", pageID)
+		return nil, NewInternalError("Failed to get page")
+	}
+
+	if page == nil || page.IsArchived {
+		return nil, NewNotFoundError("Page not found")
+	}
+
+	// The token is random rather than derived from the page, so it reveals nothing about it
+	token, err := randomHex(32)
+	if err != nil {
+		s.logger.Error("Failed to generate share link token", "error", err)
+		return nil, NewInternalError("Failed to create share link")
+	}
+
+	createdBy := userID
+	link := &repository.PageShareLink{
+		PageID:    pageID,
+		TokenHash: hashVerificationToken(token),
+		CreatedBy: &createdBy,
+		ExpiresAt: req.ExpiresAt,
+	}
+
+	if req.Password != "" {
+		passwordHash, err := utils.HashPassword(req.Password)
+		if err != nil {
+			s.logger.Error("Failed to hash share link password", "error", err)
+			return nil, NewInternalError("Failed to create share link")
+		}
+		link.PasswordHash = &passwordHash
+	}
+
+	if err := s.shareLinkRepo.Create(ctx, link); err != nil {
+		s.logger.Error("Failed to create share link", "error", err, "page_id", pageID)
+		return nil, NewInternalError("Failed to create share link")
+	}
+
+	response := toShareLinkResponse(link)
+	response.Token = token
+	return response, nil
+}
+
+func (s *shareLinkService) ListShareLinks(ctx context.Context, userID int64, pageID string) ([]ShareLinkResponse, error) {
+	if err := s.requireAdmin(ctx, userID, pageID); err != nil {
+		return nil, err
+	}
+
+	links, err := s.shareLinkRepo.ListByPage(ctx, pageID)
+	if err != nil {
+		s.logger.Error("Failed to list share links", "error", err, "page_id", pageID)
+		return nil, NewInternalError("Failed to list share links")
+	}
+
+	responses := make([]ShareLinkResponse, 0, len(links))
+	for _, link := range links {
+		responses = append(responses, *toShareLinkResponse(link))
+	}
+	return responses, nil
+}
+
+func (s *shareLinkService) RevokeShareLink(ctx context.Context, userID int64, pageID, linkID string) error {
+	if _, err := uuid.Parse(linkID); err != nil {
+		return NewNotFoundError("Share link")
+	}
+
+	if err := s.requireAdmin(ctx, userID, pageID); err != nil {
+		return err
+	}
+
+	revoked, err := s.shareLinkRepo.Revoke(ctx, pageID, linkID)
+	if err != nil {
+		s.logger.Error("Failed to revoke share link", "error", err, "page_id", pageID, "share_link_id", linkID)
+		return NewInternalError("Failed to revoke share link")
+	}
+
+	if !revoked {
+		return NewNotFoundError("Share link")
+	}
+
+	s.logger.Info("Share link revoked", "page_id", pageID, "share_link_id", linkID, "user_id", userID)
+	return nil
+}
+
+func (s *shareLinkService) GetSharedPage(ctx context.Context, token, password string) (*SharedPageResponse, error) {
+	if token == "" {
+		return nil, NewNotFoundError("Shared page")
+	}
+
+	link, err := s.shareLinkRepo.GetByTokenHash(ctx, hashVerificationToken(token))
+	if err != nil {
+		s.logger.Error("Failed to get share link", "error", err)
+		return nil, NewInternalError("Failed to get shared page")
+	}
+
+	if link == nil || shareLinkExpired(link) {
+		return nil, NewNotFoundError("Shared page")
+	}
+
+	if link.PasswordHash != nil {
+		if password == "" {
+			return nil, NewUnauthorizedError("This shared page requires a password")
+		}
+		if !utils.CheckPassword(password, *link.PasswordHash) {
+			return nil, NewUnauthorizedError("Incorrect password")
+		}
+	}
+
+	page, err := s.pageRepo.GetByID(ctx, link.PageID)
+	if err != nil {
+		s.logger.Error("Failed to get page", "error", err, "page_id", link.PageID)
+		return nil, NewInternalError("Failed to get shared page")
+	}
+
+	if page == nil || page.IsArchived {
+		return nil, NewNotFoundError("Shared page")
+	}
+
+	blocks, err := s.blockRepo.GetByPageID(ctx, page.ID)
+	if err != nil {
+		s.logger.Error("Failed to get page blocks", "error", err, "page_id", page.ID)
+		return nil, NewInternalError("Failed to get shared page")
+	}
+
+	response := &SharedPageResponse{
+		Title:     page.Title,
+		Icon:      page.Icon,
+		CoverURL:  page.CoverURL,
+		UpdatedAt: page.UpdatedAt,
+		Blocks:    make([]SharedBlockResponse, len(blocks)),
+	}
+	for i, block := range blocks {
+		response.Blocks[i] = SharedBlockResponse{
+			BlockType: block.BlockType,
+			BlockData: block.BlockData,
+			Position:  block.Position,
+		}
+	}
+
+	return response, nil
+}
+
+func (s *shareLinkService) requireAdmin(ctx context.Context, userID int64, pageID string) error {
+	hasPermission, err := s.pageRepo.HasPermission(ctx, pageID, userID, repository.PermissionAdmin)
+	if err != nil {
+		s.logger.Error("Failed to check page permission", "error", err, "page_id", pageID, "user_id", userID)
+		return NewInternalError("Failed to verify page access")
+	}
+
+	if !hasPermission {
+		return NewForbiddenError("Admin access required to manage share links")
+	}
+	return nil
+}
+
+func shareLinkExpired(link *repository.PageShareLink) bool {
+	return link.ExpiresAt != nil && !time.Now().Before(*link.ExpiresAt)
+}
+
+func toShareLinkResponse(link *repository.PageShareLink) *ShareLinkResponse {
+	return &ShareLinkResponse{
+		ID:          link.ID,
+		HasPassword: link.PasswordHash != nil,
+		ExpiresAt:   link.ExpiresAt,
+		Expired:     shareLinkExpired(link),
+		CreatedBy:   link.CreatedBy,
+		CreatedAt:   link.CreatedAt,
+	}
+}
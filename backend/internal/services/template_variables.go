@@ -0,0 +1,86 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"html"
+	"regexp"
+	"time"
+)
+
+// templateVariablePattern matches {{name}} placeholders, allowing spaces
+// inside the braces
+var templateVariablePattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// templateVariables returns the built-in placeholders available to every
+// template, overridden by any caller-supplied ones. Dates are in UTC.
+func templateVariables(now time.Time, custom map[string]string) map[string]string {
+	now = now.UTC()
+	vars := map[string]string{
+		"date":     now.Format("2006-01-02"),
+		"time":     now.Format("15:04"),
+		"datetime": now.Format("2006-01-02 15:04"),
+		"weekday":  now.Format("Monday"),
+	}
+	for name, value := range custom {
+		vars[name] = value
+	}
+	return vars
+}
+
+// substituteTemplateText replaces known placeholders in plain text. Unknown
+// placeholders are left as written so typos stay visible.
+func substituteTemplateText(text string, vars map[string]string) string {
+	return templateVariablePattern.ReplaceAllStringFunc(text, func(match string) string {
+		name := templateVariablePattern.FindStringSubmatch(match)[1]
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		return match
+	})
+}
+
+// substituteTemplateBlockData replaces placeholders in every string of a
+// block's data. Values are HTML-escaped because block text is rich text.
+func substituteTemplateBlockData(data json.RawMessage, vars map[string]string) (json.RawMessage, error) {
+	if !templateVariablePattern.Match(data) {
+		return data, nil
+	}
+
+	escaped := make(map[string]string, len(vars))
+	for name, value := range vars {
+		escaped[name] = html.EscapeString(value)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	var substituted bytes.Buffer
+	encoder := json.NewEncoder(&substituted)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(substituteTemplateValue(value, escaped)); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(substituted.Bytes(), "\n"), nil
+}
+
+func substituteTemplateValue(value interface{}, vars map[string]string) interface{} {
+	switch v := value.(type) {
+	case string:
+		return substituteTemplateText(v, vars)
+	case map[string]interface{}:
+		for key, child := range v {
+			v[key] = substituteTemplateValue(child, vars)
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = substituteTemplateValue(child, vars)
+		}
+	}
+	return value
+}
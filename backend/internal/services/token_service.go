@@ -36,7 +36,7 @@ func (s *TokenService) GenerateTokenPair(ctx context.Context, userID int64) (*To
 		return nil, errors.NewInternalError("failed to generate refresh token").WithCause(err)
 	}
 
-	expiresAt := time.Now().Add(time.Duration(s.config.JWT.RefreshTokenDuration) * time.Hour)
+	expiresAt := time.Now().Add(s.config.JWT.RefreshTokenDuration)
 	err = s.tokenRepo.StoreRefreshToken(ctx, userID, refreshToken, expiresAt)
 	if err != nil {
 		return nil, errors.NewDatabaseError("failed to store refresh token", err)
@@ -45,7 +45,7 @@ func (s *TokenService) GenerateTokenPair(ctx context.Context, userID int64) (*To
 	return &TokenPair{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
-		ExpiresIn:    int64(s.config.JWT.AccessTokenDuration * 60), // convert minutes to seconds
+		ExpiresIn:    int64(s.config.JWT.AccessTokenDuration.Seconds()),
 		TokenType:    "Bearer",
 		IssuedAt:     time.Now(),
 	}, nil
@@ -141,7 +141,7 @@ func (s *TokenService) RevokeToken(ctx context.Context, token string) error {
 
 func (s *TokenService) generateAccessToken(userID int64) (string, error) {
 	now := time.Now()
-	expiresAt := now.Add(time.Duration(s.config.JWT.AccessTokenDuration) * time.Minute)
+	expiresAt := now.Add(s.config.JWT.AccessTokenDuration)
 
 	tokenID, err := s.generateRandomString(16)
 	if err != nil {
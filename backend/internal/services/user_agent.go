@@ -0,0 +1,79 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+)
+
+// userAgentRule maps a User-Agent substring to a friendly name. Rules are
+// checked in order, so more specific tokens must come first (Edge and Opera
+// also announce themselves as Chrome, and Chrome as Safari).
+type userAgentRule struct {
+	token string
+	name  string
+}
+
+var browserRules = []userAgentRule{
+	{"Edg/", "Edge"},
+	{"EdgA/", "Edge"},
+	{"EdgiOS/", "Edge"},
+	{"OPR/", "Opera"},
+	{"SamsungBrowser/", "Samsung Internet"},
+	{"Firefox/", "Firefox"},
+	{"FxiOS/", "Firefox"},
+	{"CriOS/", "Chrome"},
+	{"Chrome/", "Chrome"},
+	{"Safari/", "Safari"},
+}
+
+var osRules = []userAgentRule{
+	{"iPhone", "iOS"},
+	{"iPad", "iPadOS"},
+	{"iPod", "iOS"},
+	{"Android", "Android"},
+	{"CrOS", "ChromeOS"},
+	{"Windows", "Windows"},
+	{"Macintosh", "macOS"},
+	{"Mac OS X", "macOS"},
+	{"Linux", "Linux"},
+}
+
+// productTokenPattern matches the leading product of non-browser clients,
+// e.g. curl/8.4.0 or PostmanRuntime/7.36.0
+var productTokenPattern = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9._-]{0,63})/`)
+
+// describeUserAgent turns a User-Agent header into a short label such as
+// "Chrome on macOS" for session listings. Clients that are not browsers are
+// named by their product token; an empty header yields an empty label.
+func describeUserAgent(userAgent string) string {
+	userAgent = strings.TrimSpace(userAgent)
+	if userAgent == "" {
+		return ""
+	}
+
+	browser := matchUserAgentRule(userAgent, browserRules)
+	os := matchUserAgentRule(userAgent, osRules)
+
+	switch {
+	case browser != "" && os != "":
+		return browser + " on " + os
+	case browser != "":
+		return browser
+	case os != "":
+		return os
+	}
+
+	if match := productTokenPattern.FindStringSubmatch(userAgent); match != nil && match[1] != "Mozilla" {
+		return match[1]
+	}
+	return "Unknown device"
+}
+
+func matchUserAgentRule(userAgent string, rules []userAgentRule) string {
+	for _, rule := range rules {
+		if strings.Contains(userAgent, rule.token) {
+			return rule.name
+		}
+	}
+	return ""
+}
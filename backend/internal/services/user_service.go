@@ -7,31 +7,45 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Srivathsav-max/lumen/backend/internal/config"
 	"github.com/Srivathsav-max/lumen/backend/internal/constants"
+	"github.com/Srivathsav-max/lumen/backend/internal/errors"
 	"github.com/Srivathsav-max/lumen/backend/internal/repository"
 	"github.com/Srivathsav-max/lumen/backend/utils"
 )
 
 type UserServiceImpl struct {
-	userRepo     repository.UserRepository
-	roleRepo     repository.RoleRepository
-	emailService EmailService
-	logger       *slog.Logger
-	validator    *Validator
+	userRepo             repository.UserRepository
+	roleRepo             repository.RoleRepository
+	workspaceRepo        repository.WorkspaceRepository
+	emailService         EmailService
+	verificationTokenSvc VerificationTokenService
+	onboarding           *config.OnboardingConfig
+	passwordPolicy       *PasswordPolicy
+	logger               *slog.Logger
+	validator            *Validator
 }
 
 func NewUserService(
 	userRepo repository.UserRepository,
 	roleRepo repository.RoleRepository,
+	workspaceRepo repository.WorkspaceRepository,
 	emailService EmailService,
+	verificationTokenSvc VerificationTokenService,
+	onboarding *config.OnboardingConfig,
+	passwordPolicy *PasswordPolicy,
 	logger *slog.Logger,
 ) UserService {
 	return &UserServiceImpl{
-		userRepo:     userRepo,
-		roleRepo:     roleRepo,
-		emailService: emailService,
-		logger:       logger,
-		validator:    NewValidator(),
+		userRepo:             userRepo,
+		roleRepo:             roleRepo,
+		workspaceRepo:        workspaceRepo,
+		emailService:         emailService,
+		verificationTokenSvc: verificationTokenSvc,
+		onboarding:           onboarding,
+		passwordPolicy:       passwordPolicy,
+		logger:               logger,
+		validator:            NewValidator(),
 	}
 }
 
@@ -45,6 +59,10 @@ func (s *UserServiceImpl) Register(ctx context.Context, req *RegisterRequest) (*
 		return nil, err
 	}
 
+	if err := s.passwordPolicy.ValidatePassword(req.Password); err != nil {
+		return nil, err
+	}
+
 	exists, err := s.userRepo.ExistsByEmail(ctx, req.Email)
 	if err != nil {
 		s.logger.Error("Failed to check if user exists by email",
@@ -96,6 +114,8 @@ func (s *UserServiceImpl) Register(ctx context.Context, req *RegisterRequest) (*
 		return nil, NewServiceUnavailableError("user creation", err)
 	}
 
+	// Email domain rules wait for VerifyEmail: until then nothing proves the
+	// user controls the address
 	if err := s.assignDefaultRole(ctx, user.ID); err != nil {
 		s.logger.Error("Failed to assign default role to user",
 			"user_id", user.ID,
@@ -104,6 +124,8 @@ func (s *UserServiceImpl) Register(ctx context.Context, req *RegisterRequest) (*
 		)
 	}
 
+	go s.sendVerificationEmail(user.ID, user.Email)
+
 	s.logger.Info("User registered successfully",
 		"user_id", user.ID,
 		"email", req.Email,
@@ -308,7 +330,20 @@ func (s *UserServiceImpl) GetByEmail(ctx context.Context, email string) (*UserRe
 	return s.mapUserToResponseWithContext(ctx, user), nil
 }
 
-func (s *UserServiceImpl) VerifyEmail(ctx context.Context, userID int64) error {
+// VerifyEmail redeems an email verification token. Email domain onboarding
+// rules are applied here, once the user has shown they own the address.
+func (s *UserServiceImpl) VerifyEmail(ctx context.Context, token string) error {
+	if token == "" {
+		return errors.NewValidationError("Verification token is required", "")
+	}
+
+	tokenData, err := s.verificationTokenSvc.ValidateToken(ctx, token, TokenTypeEmailVerification)
+	if err != nil {
+		s.logger.Debug("Invalid email verification token", "error", err)
+		return errors.NewValidationError("Invalid or expired verification token", "")
+	}
+	userID := tokenData.UserID
+
 	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -321,6 +356,15 @@ func (s *UserServiceImpl) VerifyEmail(ctx context.Context, userID int64) error {
 		return NewServiceUnavailableError("email verification", err)
 	}
 
+	if err := s.verificationTokenSvc.MarkTokenAsUsed(ctx, tokenData.ID); err != nil {
+		s.logger.Error("Failed to mark verification token as used",
+			"user_id", userID,
+			"token_id", tokenData.ID,
+			"error", err,
+		)
+		return NewServiceUnavailableError("email verification", err)
+	}
+
 	if user.EmailVerified {
 		return nil
 	}
@@ -336,6 +380,8 @@ func (s *UserServiceImpl) VerifyEmail(ctx context.Context, userID int64) error {
 		return NewServiceUnavailableError("email verification", err)
 	}
 
+	s.ApplyOnboardingRules(ctx, user.ID, user.Email)
+
 	s.logger.Info("User email verified successfully",
 		"user_id", userID,
 		"email", user.Email,
@@ -373,6 +419,127 @@ func (s *UserServiceImpl) assignDefaultRole(ctx context.Context, userID int64) e
 	return s.roleRepo.AssignRoleToUser(ctx, userID, freeRole.ID)
 }
 
+// RequestEmailVerification emails a new verification link. It reports
+// success for unknown and already verified addresses so it cannot be used to
+// probe for accounts.
+func (s *UserServiceImpl) RequestEmailVerification(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		if err == sql.ErrNoRows || IsNotFoundError(err) {
+			return nil
+		}
+		s.logger.Error("Failed to get user for verification email",
+			"email", email,
+			"error", err,
+		)
+		return NewServiceUnavailableError("email verification", err)
+	}
+	if user == nil || user.EmailVerified {
+		return nil
+	}
+
+	if err := s.emailService.SendVerificationEmail(ctx, user.ID, user.Email); err != nil {
+		s.logger.Error("Failed to send verification email",
+			"user_id", user.ID,
+			"error", err,
+		)
+	}
+	return nil
+}
+
+func (s *UserServiceImpl) sendVerificationEmail(userID int64, email string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := s.emailService.SendVerificationEmail(ctx, userID, email); err != nil {
+		s.logger.Error("Failed to send verification email",
+			"user_id", userID,
+			"error", err,
+		)
+	}
+}
+
+// ApplyOnboardingRules applies the email domain rule matching email, if any.
+// Callers must only pass addresses the user has proven they own.
+func (s *UserServiceImpl) ApplyOnboardingRules(ctx context.Context, userID int64, email string) {
+	if rule := s.onboardingRule(email); rule != nil {
+		s.applyDomainRule(ctx, userID, rule)
+	}
+}
+
+func (s *UserServiceImpl) onboardingRule(email string) *config.EmailDomainRule {
+	if s.onboarding == nil {
+		return nil
+	}
+	return s.onboarding.RuleForEmail(email)
+}
+
+// applyDomainRule adds the role and workspace membership configured for the
+// user's email domain on top of the default role. Failures are only logged so
+// verification itself still succeeds.
+func (s *UserServiceImpl) applyDomainRule(ctx context.Context, userID int64, rule *config.EmailDomainRule) {
+	role, err := s.roleRepo.GetByName(ctx, rule.Role)
+	if err != nil {
+		s.logger.Error("Failed to find role for email domain rule",
+			"user_id", userID,
+			"domain", rule.Domain,
+			"role", rule.Role,
+			"error", err,
+		)
+	} else if err := s.roleRepo.AssignRoleToUser(ctx, userID, role.ID); err != nil {
+		s.logger.Error("Failed to assign domain role to user",
+			"user_id", userID,
+			"domain", rule.Domain,
+			"role", rule.Role,
+			"error", err,
+		)
+	}
+
+	if rule.WorkspaceID == 0 {
+		return
+	}
+
+	workspace, err := s.workspaceRepo.GetByID(ctx, rule.WorkspaceID)
+	if err != nil || workspace == nil {
+		s.logger.Error("Failed to find workspace for email domain rule",
+			"user_id", userID,
+			"domain", rule.Domain,
+			"workspace_id", rule.WorkspaceID,
+			"error", err,
+		)
+		return
+	}
+
+	memberRole := repository.WorkspaceRoleMember
+	if rule.WorkspaceRole == string(repository.WorkspaceRoleAdmin) {
+		memberRole = repository.WorkspaceRoleAdmin
+	}
+
+	member := &repository.WorkspaceMember{
+		WorkspaceID: workspace.ID,
+		UserID:      userID,
+		Role:        memberRole,
+		AddedBy:     workspace.OwnerID,
+	}
+
+	if err := s.workspaceRepo.AddMember(ctx, member); err != nil {
+		s.logger.Error("Failed to add user to workspace for email domain rule",
+			"user_id", userID,
+			"domain", rule.Domain,
+			"workspace_id", rule.WorkspaceID,
+			"error", err,
+		)
+		return
+	}
+
+	s.logger.Info("Applied email domain onboarding rule",
+		"user_id", userID,
+		"domain", rule.Domain,
+		"role", rule.Role,
+		"workspace_id", rule.WorkspaceID,
+	)
+}
+
 func (s *UserServiceImpl) mapUserToResponse(user *repository.User) *UserResponse {
 	return s.mapUserToResponseWithContext(context.Background(), user)
 }
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/config"
+	"github.com/Srivathsav-max/lumen/backend/internal/constants"
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+type userServiceFixture struct {
+	service    *UserServiceImpl
+	users      *fakeUserRepo
+	roles      *fakeRoleRepo
+	workspaces *fakeWorkspaceRepo
+	email      *fakeEmailService
+	tokens     VerificationTokenService
+}
+
+func newUserServiceFixture(rules ...config.EmailDomainRule) *userServiceFixture {
+	f := &userServiceFixture{
+		users:      newFakeUserRepo(),
+		roles:      newFakeRoleRepo(constants.RoleFree, constants.RoleDeveloper),
+		workspaces: newFakeWorkspaceRepo(&repository.Workspace{ID: 7, Name: "Acme", OwnerID: 99}),
+		email:      newFakeEmailService(),
+		tokens:     NewVerificationTokenService(newFakeVerificationTokenRepo()),
+	}
+	f.service = NewUserService(
+		f.users,
+		f.roles,
+		f.workspaces,
+		f.email,
+		f.tokens,
+		&config.OnboardingConfig{DomainRules: rules},
+		NewPasswordPolicy(config.PasswordPolicyConfig{MinLength: 8, MaxLength: 72}),
+		discardLogger(),
+	).(*UserServiceImpl)
+	return f
+}
+
+// register signs a user up and waits for the verification email it triggers
+func (f *userServiceFixture) register(t *testing.T, username, email string) *UserResponse {
+	t.Helper()
+	user, err := f.service.Register(context.Background(), &RegisterRequest{
+		Username: username,
+		Email:    email,
+		Password: "correct horse battery",
+	})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	select {
+	case userID := <-f.email.verification:
+		if userID != user.ID {
+			t.Fatalf("verification email sent for user %d, want %d", userID, user.ID)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Register() did not send a verification email")
+	}
+	return user
+}
+
+func (f *userServiceFixture) verificationToken(t *testing.T, userID int64) string {
+	t.Helper()
+	token, err := f.tokens.GenerateToken(context.Background(), userID, TokenTypeEmailVerification, 24)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	return token
+}
+
+var acmeRule = config.EmailDomainRule{
+	Domain:        "acme.test",
+	Role:          constants.RoleDeveloper,
+	WorkspaceID:   7,
+	WorkspaceRole: "member",
+}
+
+func TestDomainRuleAppliesOnlyAfterVerification(t *testing.T) {
+	f := newUserServiceFixture(acmeRule)
+	user := f.register(t, "alice", "alice@ACME.test")
+
+	if roles := f.roles.rolesOf(user.ID); !slices.Equal(roles, []string{constants.RoleFree}) {
+		t.Fatalf("roles before verification = %v, want only %q", roles, constants.RoleFree)
+	}
+	if members := f.workspaces.membersOf(7); len(members) != 0 {
+		t.Fatalf("user joined the workspace before verifying their email")
+	}
+
+	if err := f.service.VerifyEmail(context.Background(), f.verificationToken(t, user.ID)); err != nil {
+		t.Fatalf("VerifyEmail() error = %v", err)
+	}
+
+	if roles := f.roles.rolesOf(user.ID); !slices.Contains(roles, constants.RoleDeveloper) {
+		t.Errorf("roles after verification = %v, want %q", roles, constants.RoleDeveloper)
+	}
+	members := f.workspaces.membersOf(7)
+	if len(members) != 1 || members[0].UserID != user.ID || members[0].Role != repository.WorkspaceRoleMember {
+		t.Errorf("workspace members after verification = %+v, want user %d as member", members, user.ID)
+	}
+
+	stored, _ := f.users.GetByID(context.Background(), user.ID)
+	if !stored.EmailVerified {
+		t.Error("email is not marked verified")
+	}
+}
+
+func TestDomainRuleIgnoredForOtherDomains(t *testing.T) {
+	f := newUserServiceFixture(acmeRule)
+	user := f.register(t, "bob", "bob@acme.test.example")
+
+	if err := f.service.VerifyEmail(context.Background(), f.verificationToken(t, user.ID)); err != nil {
+		t.Fatalf("VerifyEmail() error = %v", err)
+	}
+
+	if roles := f.roles.rolesOf(user.ID); !slices.Equal(roles, []string{constants.RoleFree}) {
+		t.Errorf("roles = %v, want only %q", roles, constants.RoleFree)
+	}
+	if members := f.workspaces.membersOf(7); len(members) != 0 {
+		t.Errorf("workspace members = %+v, want none", members)
+	}
+}
+
+func TestVerifyEmailRejectsBadAndReusedTokens(t *testing.T) {
+	f := newUserServiceFixture(acmeRule)
+	user := f.register(t, "carol", "carol@acme.test")
+
+	if err := f.service.VerifyEmail(context.Background(), "not-a-token"); err == nil {
+		t.Fatal("VerifyEmail() accepted an unknown token")
+	}
+
+	token := f.verificationToken(t, user.ID)
+	if err := f.service.VerifyEmail(context.Background(), token); err != nil {
+		t.Fatalf("VerifyEmail() error = %v", err)
+	}
+	if err := f.service.VerifyEmail(context.Background(), token); err == nil {
+		t.Error("VerifyEmail() accepted a token twice")
+	}
+	if members := f.workspaces.membersOf(7); len(members) != 1 {
+		t.Errorf("workspace members = %d, want the rule applied once", len(members))
+	}
+}
@@ -3,9 +3,15 @@ package services
 import (
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
 	"time"
 
+	"github.com/Srivathsav-max/lumen/backend/internal/constants"
 	"github.com/Srivathsav-max/lumen/backend/internal/errors"
 	"github.com/Srivathsav-max/lumen/backend/internal/repository"
 )
@@ -24,6 +30,10 @@ type VerificationTokenService interface {
 	MarkTokenAsUsed(ctx context.Context, tokenID int64) error
 	DeleteExpiredTokens(ctx context.Context) error
 	DeleteUserTokensByType(ctx context.Context, userID int64, tokenType TokenType) error
+
+	GenerateOTP(ctx context.Context, userID int64, tokenType TokenType, length int, ttl time.Duration) (string, error)
+	ConsumeOTP(ctx context.Context, userID int64, tokenType TokenType, code string) error
+	CountIssuedSince(ctx context.Context, userID int64, tokenType TokenType, since time.Time) (int, error)
 }
 
 type VerificationTokenData struct {
@@ -59,12 +69,12 @@ func (s *VerificationTokenServiceImpl) GenerateToken(ctx context.Context, userID
 	}
 	tokenString := base64.URLEncoding.EncodeToString(tokenBytes)
 
-	tokenData := &VerificationTokenData{
+	// Only the hash is stored, so a leaked table cannot be used to redeem tokens
+	tokenData := &repository.VerificationToken{
 		UserID:    userID,
-		Token:     tokenString,
-		Type:      tokenType,
+		Token:     hashVerificationToken(tokenString),
+		TokenType: string(tokenType),
 		ExpiresAt: time.Now().Add(time.Duration(expiresInHours) * time.Hour),
-		CreatedAt: time.Now(),
 	}
 
 	err = s.repo.Create(ctx, tokenData)
@@ -76,7 +86,7 @@ func (s *VerificationTokenServiceImpl) GenerateToken(ctx context.Context, userID
 }
 
 func (s *VerificationTokenServiceImpl) ValidateToken(ctx context.Context, token string, tokenType TokenType) (*VerificationTokenData, error) {
-	tokenDataInterface, err := s.repo.GetByToken(ctx, token, string(tokenType))
+	tokenDataInterface, err := s.repo.GetByToken(ctx, hashVerificationToken(token), string(tokenType))
 	if err != nil {
 		return nil, errors.NewDatabaseError("failed to retrieve token", err)
 	}
@@ -101,7 +111,7 @@ func (s *VerificationTokenServiceImpl) ValidateToken(ctx context.Context, token
 	result := &VerificationTokenData{
 		ID:        tokenData.ID,
 		UserID:    tokenData.UserID,
-		Token:     tokenData.Token,
+		Token:     token,
 		Type:      TokenType(tokenData.TokenType),
 		ExpiresAt: tokenData.ExpiresAt,
 		CreatedAt: tokenData.CreatedAt,
@@ -113,6 +123,10 @@ func (s *VerificationTokenServiceImpl) ValidateToken(ctx context.Context, token
 func (s *VerificationTokenServiceImpl) MarkTokenAsUsed(ctx context.Context, tokenID int64) error {
 	err := s.repo.MarkAsUsed(ctx, tokenID)
 	if err != nil {
+		// Not found means the token was already used, possibly by a concurrent request
+		if IsNotFoundError(err) {
+			return errors.NewValidationError("token has already been used", "")
+		}
 		return errors.NewDatabaseError("failed to mark token as used", err)
 	}
 	return nil
@@ -133,3 +147,106 @@ func (s *VerificationTokenServiceImpl) DeleteUserTokensByType(ctx context.Contex
 	}
 	return nil
 }
+
+// GenerateOTP issues a numeric code bound to the user. Outstanding codes of the
+// same type are invalidated rather than deleted so they keep counting towards
+// CountIssuedSince.
+func (s *VerificationTokenServiceImpl) GenerateOTP(ctx context.Context, userID int64, tokenType TokenType, length int, ttl time.Duration) (string, error) {
+	if err := s.repo.InvalidateUserTokensByType(ctx, userID, string(tokenType)); err != nil {
+		return "", errors.NewDatabaseError("failed to invalidate existing codes", err)
+	}
+
+	code, err := randomDigits(length)
+	if err != nil {
+		return "", errors.NewInternalError("failed to generate one-time code").WithCause(err)
+	}
+
+	tokenData := &repository.VerificationToken{
+		UserID:    userID,
+		Token:     hashOTP(userID, code),
+		TokenType: string(tokenType),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+
+	if err := s.repo.Create(ctx, tokenData); err != nil {
+		return "", errors.NewDatabaseError("failed to create one-time code", err)
+	}
+
+	return code, nil
+}
+
+// ConsumeOTP checks code against the user's latest outstanding code and marks
+// it as used, so each code can be redeemed once. After
+// constants.OTPMaxFailedAttempts wrong guesses the code stops working.
+func (s *VerificationTokenServiceImpl) ConsumeOTP(ctx context.Context, userID int64, tokenType TokenType, code string) error {
+	tokenDataInterface, err := s.repo.GetByUserID(ctx, userID, string(tokenType))
+	if err != nil {
+		if IsNotFoundError(err) {
+			return errors.NewValidationError("Invalid or expired code", "Please request a new code")
+		}
+		return errors.NewDatabaseError("failed to retrieve one-time code", err)
+	}
+
+	tokenData, ok := tokenDataInterface.(*repository.VerificationToken)
+	if !ok {
+		return errors.NewInternalError("invalid token data type")
+	}
+
+	if time.Now().After(tokenData.ExpiresAt) {
+		return errors.NewValidationError("Invalid or expired code", "Please request a new code")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(tokenData.Token), []byte(hashOTP(userID, code))) != 1 {
+		attempts, err := s.repo.RecordFailedAttempt(ctx, tokenData.ID, constants.OTPMaxFailedAttempts)
+		if err != nil && !IsNotFoundError(err) {
+			return errors.NewDatabaseError("failed to record failed attempt", err)
+		}
+		// Not found means the code was used or invalidated meanwhile
+		if err != nil || attempts >= constants.OTPMaxFailedAttempts {
+			return errors.NewValidationError("Too many incorrect attempts", "Please request a new code")
+		}
+		return errors.NewValidationError("Invalid code", "Please try again")
+	}
+
+	// Fails when a concurrent request redeemed the code first
+	if err := s.repo.MarkAsUsed(ctx, tokenData.ID); err != nil {
+		if IsNotFoundError(err) {
+			return errors.NewValidationError("Invalid or expired code", "Please request a new code")
+		}
+		return errors.NewDatabaseError("failed to mark one-time code as used", err)
+	}
+
+	return nil
+}
+
+func (s *VerificationTokenServiceImpl) CountIssuedSince(ctx context.Context, userID int64, tokenType TokenType, since time.Time) (int, error) {
+	count, err := s.repo.CountCreatedSince(ctx, userID, string(tokenType), since)
+	if err != nil {
+		return 0, errors.NewDatabaseError("failed to count issued tokens", err)
+	}
+	return count, nil
+}
+
+func randomDigits(length int) (string, error) {
+	digits := make([]byte, length)
+	for i := range digits {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		digits[i] = byte('0' + n.Int64())
+	}
+	return string(digits), nil
+}
+
+// hashOTP salts the code with the user ID; short numeric codes would
+// otherwise collide across users and be trivial to reverse from the hash alone
+func hashOTP(userID int64, code string) string {
+	return hashVerificationToken(fmt.Sprintf("%d:%s", userID, code))
+}
+
+// hashVerificationToken returns the value stored in place of the raw token
+func hashVerificationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
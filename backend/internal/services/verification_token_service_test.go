@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/constants"
+)
+
+func issueTestOTP(t *testing.T, service VerificationTokenService) string {
+	t.Helper()
+	code, err := service.GenerateOTP(context.Background(), 1, TokenTypePasswordChange, 6, 10*time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateOTP() error = %v", err)
+	}
+	return code
+}
+
+// wrongCode returns a code of the same length that is not code
+func wrongCode(code string) string {
+	if code == "000000" {
+		return "111111"
+	}
+	return "000000"
+}
+
+func TestConsumeOTPAcceptsCodeOnce(t *testing.T) {
+	service := NewVerificationTokenService(newFakeVerificationTokenRepo())
+	code := issueTestOTP(t, service)
+
+	if err := service.ConsumeOTP(context.Background(), 1, TokenTypePasswordChange, code); err != nil {
+		t.Fatalf("ConsumeOTP() error = %v", err)
+	}
+	if err := service.ConsumeOTP(context.Background(), 1, TokenTypePasswordChange, code); err == nil {
+		t.Error("ConsumeOTP() accepted a code twice")
+	}
+}
+
+func TestConsumeOTPRejectsExpiredCode(t *testing.T) {
+	repo := newFakeVerificationTokenRepo()
+	service := NewVerificationTokenService(repo)
+	code := issueTestOTP(t, service)
+	repo.expire()
+
+	if err := service.ConsumeOTP(context.Background(), 1, TokenTypePasswordChange, code); err == nil {
+		t.Error("ConsumeOTP() accepted an expired code")
+	}
+}
+
+func TestConsumeOTPRejectsCodeOfAnotherUser(t *testing.T) {
+	service := NewVerificationTokenService(newFakeVerificationTokenRepo())
+	code := issueTestOTP(t, service)
+
+	if err := service.ConsumeOTP(context.Background(), 2, TokenTypePasswordChange, code); err == nil {
+		t.Error("ConsumeOTP() accepted another user's code")
+	}
+}
+
+func TestConsumeOTPInvalidatesCodeAfterFailedAttempts(t *testing.T) {
+	service := NewVerificationTokenService(newFakeVerificationTokenRepo())
+	code := issueTestOTP(t, service)
+
+	for i := 1; i < constants.OTPMaxFailedAttempts; i++ {
+		if err := service.ConsumeOTP(context.Background(), 1, TokenTypePasswordChange, wrongCode(code)); err == nil {
+			t.Fatalf("ConsumeOTP() accepted a wrong code on attempt %d", i)
+		}
+	}
+
+	// One guess left: the right code still works
+	if err := service.ConsumeOTP(context.Background(), 1, TokenTypePasswordChange, code); err != nil {
+		t.Fatalf("ConsumeOTP() error = %v after %d wrong guesses", err, constants.OTPMaxFailedAttempts-1)
+	}
+
+	code = issueTestOTP(t, service)
+	for i := 0; i < constants.OTPMaxFailedAttempts; i++ {
+		_ = service.ConsumeOTP(context.Background(), 1, TokenTypePasswordChange, wrongCode(code))
+	}
+	if err := service.ConsumeOTP(context.Background(), 1, TokenTypePasswordChange, code); err == nil {
+		t.Errorf("ConsumeOTP() accepted the right code after %d wrong guesses", constants.OTPMaxFailedAttempts)
+	}
+}
+
+func TestGenerateTokenStoresOnlyTheHash(t *testing.T) {
+	repo := newFakeVerificationTokenRepo()
+	service := NewVerificationTokenService(repo)
+	ctx := context.Background()
+
+	token, err := service.GenerateToken(ctx, 1, TokenTypePasswordReset, 1)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+
+	if len(repo.tokens) != 1 {
+		t.Fatalf("repository holds %d tokens, want 1", len(repo.tokens))
+	}
+	for _, stored := range repo.tokens {
+		if stored.Token == token || stored.Token != hashVerificationToken(token) {
+			t.Errorf("stored token = %q, want the hash of the issued token", stored.Token)
+		}
+		if stored.UserID != 1 || stored.TokenType != string(TokenTypePasswordReset) {
+			t.Errorf("stored token = %+v, want user 1 and type %s", stored, TokenTypePasswordReset)
+		}
+	}
+
+	data, err := service.ValidateToken(ctx, token, TokenTypePasswordReset)
+	if err != nil {
+		t.Fatalf("ValidateToken() error = %v", err)
+	}
+	if data.UserID != 1 || data.Token != token {
+		t.Errorf("ValidateToken() = %+v, want user 1 and the raw token", data)
+	}
+
+	if _, err := service.ValidateToken(ctx, hashVerificationToken(token), TokenTypePasswordReset); err == nil {
+		t.Error("ValidateToken() accepted the stored hash as a token")
+	}
+	if _, err := service.ValidateToken(ctx, token, TokenTypeEmailVerification); err == nil {
+		t.Error("ValidateToken() accepted a token of another type")
+	}
+}
+
+func TestValidateTokenRejectsExpiredUsedAndReplacedTokens(t *testing.T) {
+	repo := newFakeVerificationTokenRepo()
+	service := NewVerificationTokenService(repo)
+	ctx := context.Background()
+
+	first, _ := service.GenerateToken(ctx, 1, TokenTypePasswordReset, 1)
+	second, err := service.GenerateToken(ctx, 1, TokenTypePasswordReset, 1)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	if _, err := service.ValidateToken(ctx, first, TokenTypePasswordReset); err == nil {
+		t.Error("ValidateToken() accepted a token replaced by a newer one")
+	}
+
+	data, err := service.ValidateToken(ctx, second, TokenTypePasswordReset)
+	if err != nil {
+		t.Fatalf("ValidateToken() error = %v", err)
+	}
+	if err := service.MarkTokenAsUsed(ctx, data.ID); err != nil {
+		t.Fatalf("MarkTokenAsUsed() error = %v", err)
+	}
+	if _, err := service.ValidateToken(ctx, second, TokenTypePasswordReset); err == nil {
+		t.Error("ValidateToken() accepted a used token")
+	}
+
+	expired, _ := service.GenerateToken(ctx, 2, TokenTypeEmailVerification, 1)
+	for _, stored := range repo.tokens {
+		if stored.UserID == 2 {
+			stored.ExpiresAt = time.Now().Add(-time.Minute)
+		}
+	}
+	if _, err := service.ValidateToken(ctx, expired, TokenTypeEmailVerification); err == nil {
+		t.Error("ValidateToken() accepted an expired token")
+	}
+}
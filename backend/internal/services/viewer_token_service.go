@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+const (
+	// DefaultViewerTokenTTL is used when the caller does not request a lifetime
+	DefaultViewerTokenTTL = time.Hour
+	// MinViewerTokenTTL keeps embed tokens from expiring before they are used
+	MinViewerTokenTTL = time.Minute
+	// MaxViewerTokenTTL bounds how long an embed token may stay valid
+	MaxViewerTokenTTL = 24 * time.Hour
+
+	viewerTokenAudience = "lumen-page-viewer"
+)
+
+// ViewerTokenService issues short-lived, read-only tokens scoped to a single
+// page so it can be embedded without a user session
+type ViewerTokenService interface {
+	CreateViewerToken(ctx context.Context, userID int64, pageID string, ttl time.Duration) (*ViewerTokenResponse, error)
+	ValidateViewerToken(ctx context.Context, tokenString string, pageID string) error
+}
+
+type viewerTokenClaims struct {
+	jwt.RegisteredClaims
+	PageID string `json:"page_id"`
+}
+
+type viewerTokenService struct {
+	pageRepo repository.PageRepository
+	secret   []byte
+	logger   *slog.Logger
+}
+
+// NewViewerTokenService derives its signing key from the JWT secret so viewer
+// tokens can never be accepted as access tokens and vice versa
+func NewViewerTokenService(pageRepo repository.PageRepository, jwtSecret string, logger *slog.Logger) ViewerTokenService {
+	return &viewerTokenService{
+		pageRepo: pageRepo,
+		secret:   []byte(jwtSecret + ":" + viewerTokenAudience),
+		logger:   logger,
+	}
+}
+
+func (s *viewerTokenService) CreateViewerToken(ctx context.Context, userID int64, pageID string, ttl time.Duration) (*ViewerTokenResponse, error) {
+	// Check permission
+	hasPermission, err := s.pageRepo.HasPermission(ctx, pageID, userID, repository.PermissionAdmin)
+	if err != nil {
+		s.logger.Error("Failed to check page permission", "error", err, "page_id", pageID, "user_id", userID)
+		return nil, NewInternalError("Failed to verify page access")
+	}
+
+	if !hasPermission {
+		return nil, NewForbiddenError("Admin access required to create viewer tokens")
+	}
+
+	if ttl == 0 {
+		ttl = DefaultViewerTokenTTL
+	}
+	if ttl < MinViewerTokenTTL {
+		return nil, NewBadRequestError(fmt.Sprintf("Viewer token lifetime must be at least %s", MinViewerTokenTTL))
+	}
+	if ttl > MaxViewerTokenTTL {
+		return nil, NewBadRequestError(fmt.Sprintf("Viewer token lifetime cannot exceed %s", MaxViewerTokenTTL))
+	}
+
+	now := time.Now().UTC()
+	expiresAt := now.Add(ttl)
+	claims := &viewerTokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   fmt.Sprintf("%d", userID),
+			Issuer:    "lumen-backend",
+			Audience:  []string{viewerTokenAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+		PageID: pageID,
+	}
+
+	tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+	if err != nil {
+		s.logger.Error("Failed to sign viewer token", "error", err, "page_id", pageID)
+		return nil, NewInternalError("Failed to create viewer token")
+	}
+
+	s.logger.Info("Viewer token created", "page_id", pageID, "user_id", userID, "expires_at", expiresAt)
+
+	return &ViewerTokenResponse{
+		Token:     tokenString,
+		PageID:    pageID,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func (s *viewerTokenService) ValidateViewerToken(ctx context.Context, tokenString string, pageID string) error {
+	if tokenString == "" {
+		return NewUnauthorizedError("Viewer token is required")
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, &viewerTokenClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return s.secret, nil
+	})
+	if err != nil {
+		s.logger.Debug("Viewer token validation failed", "error", err)
+		return NewUnauthorizedError("Invalid or expired viewer token")
+	}
+
+	claims, ok := token.Claims.(*viewerTokenClaims)
+	if !ok || !token.Valid || !claims.VerifyAudience(viewerTokenAudience, true) {
+		return NewUnauthorizedError("Invalid or expired viewer token")
+	}
+
+	if claims.PageID != pageID {
+		return NewForbiddenError("Viewer token is not valid for this page")
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+func newViewerTokenTestService() ViewerTokenService {
+	pages := newFakePageRepo(
+		&repository.Page{ID: "page-1", OwnerID: 1},
+		&repository.Page{ID: "page-2", OwnerID: 1},
+	)
+	pages.grant("page-1", 2, repository.PermissionEdit)
+	return NewViewerTokenService(pages, "test-secret", discardLogger())
+}
+
+func TestCreateViewerTokenLifetime(t *testing.T) {
+	svc := newViewerTokenTestService()
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "default", ttl: 0, want: DefaultViewerTokenTTL},
+		{name: "minimum", ttl: MinViewerTokenTTL, want: MinViewerTokenTTL},
+		{name: "maximum", ttl: MaxViewerTokenTTL, want: MaxViewerTokenTTL},
+		{name: "below minimum", ttl: 30 * time.Second, wantErr: true},
+		{name: "negative", ttl: -time.Minute, wantErr: true},
+		{name: "above maximum", ttl: MaxViewerTokenTTL + time.Second, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := time.Now()
+			token, err := svc.CreateViewerToken(ctx, 1, "page-1", tt.ttl)
+			if tt.wantErr {
+				if !IsValidationError(err) {
+					t.Errorf("CreateViewerToken(%s) error = %v, want a bad request", tt.ttl, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateViewerToken(%s) error = %v", tt.ttl, err)
+			}
+			if lifetime := token.ExpiresAt.Sub(before); lifetime < tt.want-time.Second || lifetime > tt.want+time.Second {
+				t.Errorf("token lifetime = %s, want %s", lifetime, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateViewerTokenRequiresAdmin(t *testing.T) {
+	svc := newViewerTokenTestService()
+
+	if _, err := svc.CreateViewerToken(context.Background(), 2, "page-1", 0); !IsAuthorizationError(err) {
+		t.Errorf("CreateViewerToken() by an editor error = %v, want forbidden", err)
+	}
+}
+
+func TestValidateViewerToken(t *testing.T) {
+	svc := newViewerTokenTestService()
+	ctx := context.Background()
+
+	token, err := svc.CreateViewerToken(ctx, 1, "page-1", 0)
+	if err != nil {
+		t.Fatalf("CreateViewerToken() error = %v", err)
+	}
+
+	if err := svc.ValidateViewerToken(ctx, token.Token, "page-1"); err != nil {
+		t.Errorf("ValidateViewerToken() error = %v", err)
+	}
+	if err := svc.ValidateViewerToken(ctx, token.Token, "page-2"); !IsAuthorizationError(err) {
+		t.Errorf("ValidateViewerToken() for another page error = %v, want forbidden", err)
+	}
+	if err := svc.ValidateViewerToken(ctx, "", "page-1"); !IsAuthenticationError(err) {
+		t.Errorf("ValidateViewerToken() without a token error = %v, want unauthorized", err)
+	}
+
+	other := NewViewerTokenService(newFakePageRepo(), "other-secret", discardLogger())
+	if err := other.ValidateViewerToken(ctx, token.Token, "page-1"); !IsAuthenticationError(err) {
+		t.Errorf("ValidateViewerToken() with another secret error = %v, want unauthorized", err)
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Srivathsav-max/lumen/backend/internal/repository"
 )
@@ -16,27 +19,45 @@ type WorkspaceService interface {
 	DeleteWorkspace(ctx context.Context, userID int64, workspaceID int64) error
 	AddMember(ctx context.Context, userID int64, workspaceID int64, req *AddWorkspaceMemberRequest) (*WorkspaceMemberResponse, error)
 	RemoveMember(ctx context.Context, userID int64, workspaceID int64, memberUserID int64) error
-	GetMembers(ctx context.Context, userID int64, workspaceID int64) ([]WorkspaceMemberResponse, error)
+	LeaveWorkspace(ctx context.Context, userID int64, workspaceID int64) error
+	GetMembers(ctx context.Context, userID int64, workspaceID int64, limit, offset int) ([]WorkspaceMemberResponse, error)
 	UpdateMemberRole(ctx context.Context, userID int64, workspaceID int64, memberUserID int64, role string) error
+	InviteMember(ctx context.Context, userID int64, workspaceID int64, req *InviteWorkspaceMemberRequest) (*WorkspaceInvitationResponse, error)
+	AcceptInvitation(ctx context.Context, userID int64, req *AcceptWorkspaceInvitationRequest) (*WorkspaceResponse, error)
 	HasAccess(ctx context.Context, userID int64, workspaceID int64) (bool, error)
 	GetUserRole(ctx context.Context, userID int64, workspaceID int64) (repository.WorkspaceRole, error)
+	// GetPropertySchema returns a null schema when page properties are free-form
+	GetPropertySchema(ctx context.Context, userID int64, workspaceID int64) (*PropertySchemaResponse, error)
+	UpdatePropertySchema(ctx context.Context, userID int64, workspaceID int64, req *UpdatePropertySchemaRequest) (*PropertySchemaResponse, error)
 }
 
+// workspaceInvitationTTL is how long an emailed invitation stays valid
+const workspaceInvitationTTL = 7 * 24 * time.Hour
+
 type workspaceService struct {
-	workspaceRepo repository.WorkspaceRepository
-	userRepo      repository.UserRepository
-	logger        *slog.Logger
+	workspaceRepo  repository.WorkspaceRepository
+	userRepo       repository.UserRepository
+	invitationRepo repository.WorkspaceInvitationRepository
+	emailService   EmailService
+	activity       ActivityService
+	logger         *slog.Logger
 }
 
 func NewWorkspaceService(
 	workspaceRepo repository.WorkspaceRepository,
 	userRepo repository.UserRepository,
+	invitationRepo repository.WorkspaceInvitationRepository,
+	emailService EmailService,
+	activity ActivityService,
 	logger *slog.Logger,
 ) WorkspaceService {
 	return &workspaceService{
-		workspaceRepo: workspaceRepo,
-		userRepo:      userRepo,
-		logger:        logger,
+		workspaceRepo:  workspaceRepo,
+		userRepo:       userRepo,
+		invitationRepo: invitationRepo,
+		emailService:   emailService,
+		activity:       activity,
+		logger:         logger,
 	}
 }
 
@@ -90,13 +111,13 @@ func (s *workspaceService) GetWorkspace(ctx context.Context, userID int64, works
 	}
 
 	// Get member count
-	members, err := s.workspaceRepo.GetMembers(ctx, workspaceID)
+	memberCount, err := s.workspaceRepo.CountMembers(ctx, workspaceID)
 	if err != nil {
-		s.logger.Error("Failed to get workspace members", "error", err, "workspace_id", workspaceID)
+		s.logger.Error("Failed to count workspace members", "error", err, "workspace_id", workspaceID)
 		return nil, NewInternalError("Failed to get workspace members")
 	}
 
-	return s.toWorkspaceResponse(workspace, role, len(members)), nil
+	return s.toWorkspaceResponse(workspace, role, memberCount), nil
 }
 
 func (s *workspaceService) GetUserWorkspaces(ctx context.Context, userID int64) ([]WorkspaceResponse, error) {
@@ -116,13 +137,13 @@ func (s *workspaceService) GetUserWorkspaces(ctx context.Context, userID int64)
 		}
 
 		// Get member count
-		members, err := s.workspaceRepo.GetMembers(ctx, workspace.ID)
+		memberCount, err := s.workspaceRepo.CountMembers(ctx, workspace.ID)
 		if err != nil {
-			s.logger.Error("Failed to get workspace members", "error", err, "workspace_id", workspace.ID)
+			s.logger.Error("Failed to count workspace members", "error", err, "workspace_id", workspace.ID)
 			continue
 		}
 
-		responses = append(responses, *s.toWorkspaceResponse(workspace, role, len(members)))
+		responses = append(responses, *s.toWorkspaceResponse(workspace, role, memberCount))
 	}
 
 	return responses, nil
@@ -162,6 +183,9 @@ func (s *workspaceService) UpdateWorkspace(ctx context.Context, userID int64, wo
 	if req.Description != nil {
 		workspace.Description = req.Description
 	}
+	if req.DefaultPagePermission != nil {
+		workspace.DefaultPagePermission = repository.PermissionLevel(*req.DefaultPagePermission)
+	}
 
 	if err := s.workspaceRepo.Update(ctx, workspace); err != nil {
 		s.logger.Error("Failed to update workspace", "error", err, "workspace_id", workspaceID)
@@ -169,13 +193,64 @@ func (s *workspaceService) UpdateWorkspace(ctx context.Context, userID int64, wo
 	}
 
 	// Get member count
-	members, err := s.workspaceRepo.GetMembers(ctx, workspaceID)
+	memberCount, err := s.workspaceRepo.CountMembers(ctx, workspaceID)
 	if err != nil {
-		s.logger.Error("Failed to get workspace members", "error", err, "workspace_id", workspaceID)
+		s.logger.Error("Failed to count workspace members", "error", err, "workspace_id", workspaceID)
 		return nil, NewInternalError("Failed to get workspace members")
 	}
 
-	return s.toWorkspaceResponse(workspace, role, len(members)), nil
+	return s.toWorkspaceResponse(workspace, role, memberCount), nil
+}
+
+func (s *workspaceService) GetPropertySchema(ctx context.Context, userID int64, workspaceID int64) (*PropertySchemaResponse, error) {
+	hasAccess, err := s.workspaceRepo.HasAccess(ctx, workspaceID, userID)
+	if err != nil {
+		s.logger.Error("Failed to check workspace access", "error", err, "workspace_id", workspaceID, "user_id", userID)
+		return nil, NewInternalError("Failed to verify workspace access")
+	}
+
+	if !hasAccess {
+		return nil, NewForbiddenError("Access denied to workspace")
+	}
+
+	schema, err := s.workspaceRepo.GetPropertySchema(ctx, workspaceID)
+	if err != nil {
+		s.logger.Error("Failed to get workspace property schema", "error", err, "workspace_id", workspaceID)
+		return nil, NewInternalError("Failed to get property schema")
+	}
+
+	return &PropertySchemaResponse{WorkspaceID: workspaceID, Schema: schema}, nil
+}
+
+func (s *workspaceService) UpdatePropertySchema(ctx context.Context, userID int64, workspaceID int64, req *UpdatePropertySchemaRequest) (*PropertySchemaResponse, error) {
+	// Check if user is admin or owner
+	role, err := s.GetUserRole(ctx, userID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if role != repository.WorkspaceRoleOwner && role != repository.WorkspaceRoleAdmin {
+		return nil, NewForbiddenError("Insufficient permissions to update workspace")
+	}
+
+	schema := req.Schema
+	if len(schema) == 0 || string(schema) == "null" {
+		schema = nil
+	} else if _, err := parsePropertySchema(schema); err != nil {
+		return nil, NewValidationError(&ValidationError{Errors: []ValidationErrorDetail{
+			{Field: "schema", Message: err.Error()},
+		}})
+	}
+
+	// Existing pages are not revalidated; they are checked the next time their properties change
+	if err := s.workspaceRepo.SetPropertySchema(ctx, workspaceID, schema); err != nil {
+		s.logger.Error("Failed to update workspace property schema", "error", err, "workspace_id", workspaceID)
+		return nil, NewInternalError("Failed to update property schema")
+	}
+
+	s.logger.Info("Workspace property schema updated", "workspace_id", workspaceID, "user_id", userID)
+
+	return &PropertySchemaResponse{WorkspaceID: workspaceID, Schema: schema}, nil
 }
 
 func (s *workspaceService) DeleteWorkspace(ctx context.Context, userID int64, workspaceID int64) error {
@@ -229,6 +304,18 @@ func (s *workspaceService) AddMember(ctx context.Context, userID int64, workspac
 		return nil, NewNotFoundError("User not found")
 	}
 
+	// Check if target user is already a member
+	existing, err := s.workspaceRepo.GetMember(ctx, workspaceID, req.UserID)
+	if err != nil {
+		s.logger.Error("Failed to get workspace member", "error", err, "workspace_id", workspaceID, "user_id", req.UserID)
+		return nil, NewInternalError("Failed to verify membership")
+	}
+
+	if existing != nil {
+		return nil, NewConflictError("User is already a member of this workspace").
+			WithDetails(s.toWorkspaceMemberResponse(existing, targetUser))
+	}
+
 	// Parse role
 	var memberRole repository.WorkspaceRole
 	switch req.Role {
@@ -252,6 +339,15 @@ func (s *workspaceService) AddMember(ctx context.Context, userID int64, workspac
 		return nil, NewInternalError("Failed to add member")
 	}
 
+	s.activity.Record(ActivityEvent{
+		WorkspaceID: workspaceID,
+		ActorID:     userID,
+		Action:      repository.ActivityMemberAdded,
+		TargetType:  repository.ActivityTargetUser,
+		TargetID:    strconv.FormatInt(req.UserID, 10),
+		Metadata:    map[string]interface{}{"role": memberRole},
+	})
+
 	return s.toWorkspaceMemberResponse(member, targetUser), nil
 }
 
@@ -282,10 +378,65 @@ func (s *workspaceService) RemoveMember(ctx context.Context, userID int64, works
 		return NewInternalError("Failed to remove member")
 	}
 
+	s.activity.Record(ActivityEvent{
+		WorkspaceID: workspaceID,
+		ActorID:     userID,
+		Action:      repository.ActivityMemberRemoved,
+		TargetType:  repository.ActivityTargetUser,
+		TargetID:    strconv.FormatInt(memberUserID, 10),
+	})
+
+	return nil
+}
+
+// LeaveWorkspace removes the caller's own membership. Pages the caller owns in
+// the workspace are handed over to the workspace owner so they stay reachable.
+func (s *workspaceService) LeaveWorkspace(ctx context.Context, userID int64, workspaceID int64) error {
+	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil {
+		s.logger.Error("Failed to get workspace", "error", err, "workspace_id", workspaceID)
+		return NewInternalError("Failed to get workspace")
+	}
+
+	if workspace == nil {
+		return NewNotFoundError("Workspace not found")
+	}
+
+	if workspace.OwnerID == userID {
+		return NewBadRequestError("Workspace owner cannot leave; transfer ownership or delete the workspace instead")
+	}
+
+	member, err := s.workspaceRepo.GetMember(ctx, workspaceID, userID)
+	if err != nil {
+		s.logger.Error("Failed to get workspace member", "error", err, "workspace_id", workspaceID, "user_id", userID)
+		return NewInternalError("Failed to verify membership")
+	}
+
+	if member == nil {
+		return NewForbiddenError("User is not a member of this workspace")
+	}
+
+	// The owner takes over the departing member's pages
+	transferred, err := s.workspaceRepo.RemoveMemberAndTransferPages(ctx, workspaceID, userID, workspace.OwnerID)
+	if err != nil {
+		s.logger.Error("Failed to remove workspace member", "error", err, "workspace_id", workspaceID, "user_id", userID)
+		return NewInternalError("Failed to leave workspace")
+	}
+
+	s.activity.Record(ActivityEvent{
+		WorkspaceID: workspaceID,
+		ActorID:     userID,
+		Action:      repository.ActivityMemberRemoved,
+		TargetType:  repository.ActivityTargetUser,
+		TargetID:    strconv.FormatInt(userID, 10),
+		Metadata:    map[string]interface{}{"left": true, "pages_transferred": transferred},
+	})
+
+	s.logger.Info("User left workspace", "workspace_id", workspaceID, "user_id", userID, "pages_transferred", transferred)
 	return nil
 }
 
-func (s *workspaceService) GetMembers(ctx context.Context, userID int64, workspaceID int64) ([]WorkspaceMemberResponse, error) {
+func (s *workspaceService) GetMembers(ctx context.Context, userID int64, workspaceID int64, limit, offset int) ([]WorkspaceMemberResponse, error) {
 	// Check if user has access to workspace
 	hasAccess, err := s.workspaceRepo.HasAccess(ctx, workspaceID, userID)
 	if err != nil {
@@ -297,7 +448,7 @@ func (s *workspaceService) GetMembers(ctx context.Context, userID int64, workspa
 		return nil, NewForbiddenError("Access denied to workspace")
 	}
 
-	members, err := s.workspaceRepo.GetMembers(ctx, workspaceID)
+	members, err := s.workspaceRepo.GetMembersPaginated(ctx, workspaceID, limit, offset)
 	if err != nil {
 		s.logger.Error("Failed to get workspace members", "error", err, "workspace_id", workspaceID)
 		return nil, NewInternalError("Failed to get members")
@@ -362,6 +513,201 @@ func (s *workspaceService) UpdateMemberRole(ctx context.Context, userID int64, w
 	return nil
 }
 
+// InviteMember emails an invitation to join the workspace. The address does not
+// need to belong to an account yet; the invitee signs up with it and then
+// accepts. Inviting the same address again replaces the pending invitation.
+func (s *workspaceService) InviteMember(ctx context.Context, userID int64, workspaceID int64, req *InviteWorkspaceMemberRequest) (*WorkspaceInvitationResponse, error) {
+	// Validate input
+	if err := validateStruct(req); err != nil {
+		return nil, NewValidationError(err)
+	}
+
+	// Check if user is admin or owner
+	role, err := s.GetUserRole(ctx, userID, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if role != repository.WorkspaceRoleOwner && role != repository.WorkspaceRoleAdmin {
+		return nil, NewForbiddenError("Insufficient permissions to invite members")
+	}
+
+	workspace, err := s.workspaceRepo.GetByID(ctx, workspaceID)
+	if err != nil {
+		s.logger.Error("Failed to get workspace", "error", err, "workspace_id", workspaceID)
+		return nil, NewInternalError("Failed to get workspace")
+	}
+
+	inviter, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to get user", "error", err, "user_id", userID)
+		return nil, NewInternalError("Failed to get user")
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+
+	// Existing accounts that are already members need no invitation
+	existingUser, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil && !IsNotFoundError(err) {
+		s.logger.Error("Failed to get user by email", "error", err, "email", email)
+		return nil, NewInternalError("Failed to verify user")
+	}
+
+	if existingUser != nil {
+		if existingUser.ID == workspace.OwnerID {
+			return nil, NewConflictError("User is already a member of this workspace")
+		}
+
+		member, err := s.workspaceRepo.GetMember(ctx, workspaceID, existingUser.ID)
+		if err != nil {
+			s.logger.Error("Failed to get workspace member", "error", err, "workspace_id", workspaceID, "user_id", existingUser.ID)
+			return nil, NewInternalError("Failed to verify membership")
+		}
+		if member != nil {
+			return nil, NewConflictError("User is already a member of this workspace")
+		}
+	}
+
+	token, err := randomHex(32)
+	if err != nil {
+		s.logger.Error("Failed to generate invitation token", "error", err)
+		return nil, NewInternalError("Failed to create invitation")
+	}
+
+	if err := s.invitationRepo.DeletePending(ctx, workspaceID, email); err != nil {
+		s.logger.Error("Failed to replace pending invitations", "error", err, "workspace_id", workspaceID)
+		return nil, NewInternalError("Failed to create invitation")
+	}
+
+	invitation := &repository.WorkspaceInvitation{
+		WorkspaceID: workspaceID,
+		Email:       email,
+		Role:        repository.WorkspaceRole(req.Role),
+		TokenHash:   hashVerificationToken(token),
+		InvitedBy:   userID,
+		ExpiresAt:   time.Now().UTC().Add(workspaceInvitationTTL),
+	}
+
+	if err := s.invitationRepo.Create(ctx, invitation); err != nil {
+		s.logger.Error("Failed to create workspace invitation", "error", err, "workspace_id", workspaceID)
+		return nil, NewInternalError("Failed to create invitation")
+	}
+
+	inviterName := strings.TrimSpace(inviter.FirstName + " " + inviter.LastName)
+	if inviterName == "" {
+		inviterName = inviter.Username
+	}
+
+	if err := s.emailService.SendWorkspaceInvitationEmail(ctx, email, &WorkspaceInvitationEmail{
+		Token:          token,
+		InviterName:    inviterName,
+		WorkspaceName:  workspace.Name,
+		Role:           req.Role,
+		HasAccount:     existingUser != nil,
+		ExpirationDays: int(workspaceInvitationTTL.Hours() / 24),
+	}); err != nil {
+		s.logger.Error("Failed to send workspace invitation email", "error", err, "workspace_id", workspaceID, "invitation_id", invitation.ID)
+		return nil, NewInternalError("Failed to send invitation email")
+	}
+
+	s.logger.Info("Workspace invitation sent", "workspace_id", workspaceID, "invitation_id", invitation.ID, "invited_by", userID)
+
+	return &WorkspaceInvitationResponse{
+		ID:           invitation.ID,
+		WorkspaceID:  invitation.WorkspaceID,
+		Email:        invitation.Email,
+		Role:         string(invitation.Role),
+		InvitedBy:    invitation.InvitedBy,
+		ExpiresAt:    invitation.ExpiresAt,
+		CreatedAt:    invitation.CreatedAt,
+		ExistingUser: existingUser != nil,
+	}, nil
+}
+
+// AcceptInvitation adds the caller to the invitation's workspace. The caller's
+// account email must match the invited address.
+func (s *workspaceService) AcceptInvitation(ctx context.Context, userID int64, req *AcceptWorkspaceInvitationRequest) (*WorkspaceResponse, error) {
+	// Validate input
+	if err := validateStruct(req); err != nil {
+		return nil, NewValidationError(err)
+	}
+
+	invitation, err := s.invitationRepo.GetByTokenHash(ctx, hashVerificationToken(req.Token))
+	if err != nil {
+		s.logger.Error("Failed to get workspace invitation", "error", err)
+		return nil, NewInternalError("Failed to get invitation")
+	}
+
+	if invitation == nil || invitation.AcceptedAt != nil || time.Now().After(invitation.ExpiresAt) {
+		return nil, NewBadRequestError("Invitation is invalid or has expired")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to get user", "error", err, "user_id", userID)
+		return nil, NewInternalError("Failed to get user")
+	}
+
+	if !strings.EqualFold(user.Email, invitation.Email) {
+		return nil, NewForbiddenError("This invitation was sent to a different email address")
+	}
+
+	workspace, err := s.workspaceRepo.GetByID(ctx, invitation.WorkspaceID)
+	if err != nil {
+		s.logger.Error("Failed to get workspace", "error", err, "workspace_id", invitation.WorkspaceID)
+		return nil, NewInternalError("Failed to get workspace")
+	}
+
+	if workspace == nil {
+		return nil, NewNotFoundError("Workspace not found")
+	}
+
+	accepted, err := s.invitationRepo.MarkAccepted(ctx, invitation.ID, userID)
+	if err != nil {
+		s.logger.Error("Failed to accept workspace invitation", "error", err, "invitation_id", invitation.ID)
+		return nil, NewInternalError("Failed to accept invitation")
+	}
+
+	if !accepted {
+		return nil, NewBadRequestError("Invitation is invalid or has expired")
+	}
+
+	if workspace.OwnerID != userID {
+		existing, err := s.workspaceRepo.GetMember(ctx, workspace.ID, userID)
+		if err != nil {
+			s.logger.Error("Failed to get workspace member", "error", err, "workspace_id", workspace.ID, "user_id", userID)
+			return nil, NewInternalError("Failed to verify membership")
+		}
+
+		if existing == nil {
+			member := &repository.WorkspaceMember{
+				WorkspaceID: workspace.ID,
+				UserID:      userID,
+				Role:        invitation.Role,
+				AddedBy:     invitation.InvitedBy,
+			}
+
+			if err := s.workspaceRepo.AddMember(ctx, member); err != nil {
+				s.logger.Error("Failed to add workspace member", "error", err, "workspace_id", workspace.ID, "user_id", userID)
+				return nil, NewInternalError("Failed to add member")
+			}
+
+			s.activity.Record(ActivityEvent{
+				WorkspaceID: workspace.ID,
+				ActorID:     userID,
+				Action:      repository.ActivityMemberAdded,
+				TargetType:  repository.ActivityTargetUser,
+				TargetID:    strconv.FormatInt(userID, 10),
+				Metadata:    map[string]interface{}{"role": invitation.Role, "invitation_id": invitation.ID},
+			})
+		}
+	}
+
+	s.logger.Info("Workspace invitation accepted", "workspace_id", workspace.ID, "invitation_id", invitation.ID, "user_id", userID)
+
+	return s.GetWorkspace(ctx, userID, workspace.ID)
+}
+
 func (s *workspaceService) HasAccess(ctx context.Context, userID int64, workspaceID int64) (bool, error) {
 	return s.workspaceRepo.HasAccess(ctx, workspaceID, userID)
 }
@@ -382,17 +728,14 @@ func (s *workspaceService) GetUserRole(ctx context.Context, userID int64, worksp
 		return repository.WorkspaceRoleOwner, nil
 	}
 
-	// Get members to find user's role
-	members, err := s.workspaceRepo.GetMembers(ctx, workspaceID)
+	member, err := s.workspaceRepo.GetMember(ctx, workspaceID, userID)
 	if err != nil {
-		s.logger.Error("Failed to get workspace members", "error", err, "workspace_id", workspaceID)
+		s.logger.Error("Failed to get workspace member", "error", err, "workspace_id", workspaceID, "user_id", userID)
 		return "", NewInternalError("Failed to get workspace members")
 	}
 
-	for _, member := range members {
-		if member.UserID == userID {
-			return member.Role, nil
-		}
+	if member != nil {
+		return member.Role, nil
 	}
 
 	return "", NewForbiddenError("User is not a member of this workspace")
@@ -400,14 +743,15 @@ func (s *workspaceService) GetUserRole(ctx context.Context, userID int64, worksp
 
 func (s *workspaceService) toWorkspaceResponse(workspace *repository.Workspace, role repository.WorkspaceRole, memberCount int) *WorkspaceResponse {
 	return &WorkspaceResponse{
-		ID:          workspace.ID,
-		Name:        workspace.Name,
-		Description: workspace.Description,
-		OwnerID:     workspace.OwnerID,
-		CreatedAt:   workspace.CreatedAt,
-		UpdatedAt:   workspace.UpdatedAt,
-		MemberCount: memberCount,
-		Role:        string(role),
+		ID:                    workspace.ID,
+		Name:                  workspace.Name,
+		Description:           workspace.Description,
+		OwnerID:               workspace.OwnerID,
+		CreatedAt:             workspace.CreatedAt,
+		UpdatedAt:             workspace.UpdatedAt,
+		MemberCount:           memberCount,
+		Role:                  string(role),
+		DefaultPagePermission: string(workspace.DefaultPagePermission),
 	}
 }
 
@@ -424,4 +768,4 @@ func (s *workspaceService) toWorkspaceMemberResponse(member *repository.Workspac
 		CreatedAt: member.CreatedAt,
 		UpdatedAt: member.UpdatedAt,
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Srivathsav-max/lumen/backend/internal/errors"
+	"github.com/Srivathsav-max/lumen/backend/internal/repository"
+)
+
+func TestLeaveWorkspace(t *testing.T) {
+	const ownerID, memberID, outsiderID int64 = 1, 2, 3
+	ctx := context.Background()
+
+	workspaces := newFakeWorkspaceRepo(&repository.Workspace{ID: 10, OwnerID: ownerID})
+	workspaces.AddMember(ctx, &repository.WorkspaceMember{WorkspaceID: 10, UserID: ownerID, Role: repository.WorkspaceRoleOwner})
+	workspaces.AddMember(ctx, &repository.WorkspaceMember{WorkspaceID: 10, UserID: memberID, Role: repository.WorkspaceRoleMember})
+	svc := NewWorkspaceService(workspaces, nil, nil, nil, fakeActivityService{}, discardLogger())
+
+	if err := svc.LeaveWorkspace(ctx, ownerID, 10); !IsValidationError(err) {
+		t.Errorf("owner leaving: error = %v, want a bad request", err)
+	}
+	if err := svc.LeaveWorkspace(ctx, outsiderID, 10); !IsAuthorizationError(err) {
+		t.Errorf("non-member leaving: error = %v, want forbidden", err)
+	}
+	if err := svc.LeaveWorkspace(ctx, memberID, 99); !IsNotFoundError(err) {
+		t.Errorf("leaving a missing workspace: error = %v, want not found", err)
+	}
+
+	if err := svc.LeaveWorkspace(ctx, memberID, 10); err != nil {
+		t.Fatalf("member leaving: %v", err)
+	}
+	for _, member := range workspaces.membersOf(10) {
+		if member.UserID == memberID {
+			t.Fatal("member is still in the workspace after leaving")
+		}
+	}
+}
+
+func TestAddMemberRejectsExistingMember(t *testing.T) {
+	ctx := context.Background()
+	users := newFakeUserRepo()
+	owner := &repository.User{Username: "owner", Email: "owner@example.test"}
+	member := &repository.User{Username: "bob", Email: "bob@example.test"}
+	users.Create(ctx, owner)
+	users.Create(ctx, member)
+
+	workspaces := newFakeWorkspaceRepo(&repository.Workspace{ID: 10, OwnerID: owner.ID})
+	workspaces.AddMember(ctx, &repository.WorkspaceMember{ID: 7, WorkspaceID: 10, UserID: member.ID, Role: repository.WorkspaceRoleAdmin, AddedBy: owner.ID})
+	svc := NewWorkspaceService(workspaces, users, nil, nil, fakeActivityService{}, discardLogger())
+
+	_, err := svc.AddMember(ctx, owner.ID, 10, &AddWorkspaceMemberRequest{UserID: member.ID, Role: "member"})
+	if !IsConflictError(err) {
+		t.Fatalf("AddMember() of an existing member error = %v, want a conflict", err)
+	}
+
+	existing, ok := err.(*errors.AppError).Details.(*WorkspaceMemberResponse)
+	if !ok {
+		t.Fatalf("conflict details = %T, want the existing member", err.(*errors.AppError).Details)
+	}
+	if existing.ID != 7 || existing.Username != "bob" || existing.Role != string(repository.WorkspaceRoleAdmin) {
+		t.Errorf("conflict details = %+v, want the existing admin membership", existing)
+	}
+	if n := len(workspaces.membersOf(10)); n != 1 {
+		t.Errorf("workspace has %d members after the conflict, want 1", n)
+	}
+}
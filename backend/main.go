@@ -1,14 +1,19 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"net/http"
+	"time"
 
 	"github.com/Srivathsav-max/lumen/backend/config"
 	"github.com/Srivathsav-max/lumen/backend/db"
 	internalConfig "github.com/Srivathsav-max/lumen/backend/internal/config"
+	"github.com/Srivathsav-max/lumen/backend/internal/constants"
 	"github.com/Srivathsav-max/lumen/backend/internal/container"
 	"github.com/Srivathsav-max/lumen/backend/internal/router"
+	"github.com/Srivathsav-max/lumen/backend/internal/services"
 	"github.com/joho/godotenv"
 )
 
@@ -81,9 +86,27 @@ func main() {
 		log.Fatalf("Failed to run migrations: %v", err)
 	}
 
+	if cfg.AI.ConversationRetentionDays > 0 || cfg.AI.MaxConversationsPerUser > 0 {
+		pruneInterval := time.Duration(cfg.AI.ConversationPruneIntervalMin) * time.Minute
+		services.StartConversationPruning(context.Background(), appContainer.AIChatService, pruneInterval, appContainer.GetLogger())
+	}
+
+	trashPurgeInterval := time.Duration(constants.DefaultTrashPurgeIntervalMin) * time.Minute
+	trashRetention := time.Duration(constants.DefaultTrashRetentionDays) * 24 * time.Hour
+	services.StartTrashPurge(context.Background(), appContainer.GetPageService(), trashPurgeInterval, trashRetention, appContainer.GetLogger())
+
 	appRouter := router.NewRouter(appContainer)
 	ginEngine := appRouter.SetupRoutes()
 
+	if metricsHandler := appRouter.MetricsHandler(); metricsHandler != nil && cfg.Metrics.Addr != "" {
+		go func() {
+			appContainer.GetLogger().Info("Metrics server starting", "address", cfg.Metrics.Addr)
+			if err := http.ListenAndServe(cfg.Metrics.Addr, metricsHandler); err != nil {
+				appContainer.GetLogger().Error("Metrics server stopped", "error", err)
+			}
+		}()
+	}
+
 	serverAddr := fmt.Sprintf(":%d", cfg.Server.Port)
 	appContainer.GetLogger().Info("Server starting", "address", serverAddr)
 
@@ -1,21 +1,11 @@
 package utils
 
 import (
-	"errors"
 	"fmt"
-	"math/rand"
-	"time"
 
-	"github.com/golang-jwt/jwt/v4"
 	"golang.org/x/crypto/bcrypt"
 )
 
-// Claims represents the JWT claims
-type Claims struct {
-	UserID int64 `json:"user_id"`
-	jwt.RegisteredClaims
-}
-
 // HashPassword creates a bcrypt hash of the password
 func HashPassword(password string) (string, error) {
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
@@ -30,72 +20,3 @@ func CheckPassword(password, hash string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
 }
-
-// GenerateToken generates a JWT token for a user
-func GenerateToken(userID int64, secret string, expirationHours int) (string, error) {
-	// Set expiration time
-	expirationTime := time.Now().Add(time.Duration(expirationHours) * time.Hour)
-	
-	// Create claims
-	claims := &Claims{
-		UserID: userID,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-		},
-	}
-	
-	// Create token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	
-	// Sign token with secret key
-	tokenString, err := token.SignedString([]byte(secret))
-	if err != nil {
-		return "", fmt.Errorf("failed to sign token: %w", err)
-	}
-	
-	return tokenString, nil
-}
-
-// ValidateToken validates a JWT token
-func ValidateToken(tokenString, secret string) (*Claims, error) {
-	// Parse token
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// Validate signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(secret), nil
-	})
-	
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse token: %w", err)
-	}
-	
-	// Extract claims
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-		return claims, nil
-	}
-	
-	return nil, errors.New("invalid token")
-}
-
-// GenerateOTP generates a random OTP of specified length
-func GenerateOTP(length int) string {
-	// Initialize random number generator with current time as seed
-	rand.Seed(time.Now().UnixNano())
-	
-	// Define the characters to use for OTP
-	const digits = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
-	
-	// Create a byte slice to hold the OTP
-	otp := make([]byte, length)
-	
-	// Fill the OTP with random digits
-	for i := 0; i < length; i++ {
-		otp[i] = digits[rand.Intn(len(digits))]
-	}
-	
-	return string(otp)
-}